@@ -0,0 +1,36 @@
+package v1alpha1
+
+import "slices"
+
+// MaxDryRunExecutables is the maximum number of executables reported for each container in the impact of a dry run.
+const MaxDryRunExecutables = 50
+
+// DryRunImpact reports the executions of a container that the declared mode of a policy in dry run
+// would have blocked.
+type DryRunImpact struct {
+	// count is the number of executions that would have been blocked.
+	Count int64 `json:"count,omitempty"`
+	// executables lists the sorted executables that would have been blocked (max MaxDryRunExecutables).
+	// +optional
+	Executables []string `json:"executables,omitempty"`
+}
+
+// AddDryRunImpact adds the impact of the dry run reported by a node for a container: the counts are summed
+// and the executables merged, up to MaxDryRunExecutables.
+func (s *WorkloadPolicyStatus) AddDryRunImpact(containerName string, count int64, executables []string) {
+	if s.DryRunImpact == nil {
+		s.DryRunImpact = make(map[string]DryRunImpact)
+	}
+	impact := s.DryRunImpact[containerName]
+	impact.Count += count
+	for _, exe := range executables {
+		if len(impact.Executables) >= MaxDryRunExecutables {
+			break
+		}
+		if !slices.Contains(impact.Executables, exe) {
+			impact.Executables = append(impact.Executables, exe)
+		}
+	}
+	slices.Sort(impact.Executables)
+	s.DryRunImpact[containerName] = impact
+}
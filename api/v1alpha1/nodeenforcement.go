@@ -0,0 +1,10 @@
+package v1alpha1
+
+// UnknownEnforcerVersion is the version reported for the agents which don't report their own version.
+const UnknownEnforcerVersion = "unknown"
+
+// NodeEnforcement represents the agent enforcing a policy on a node.
+type NodeEnforcement struct {
+	// enforcerVersion is the version of the agent which applied the policy on the node.
+	EnforcerVersion string `json:"enforcerVersion,omitempty"`
+}
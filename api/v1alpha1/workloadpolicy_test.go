@@ -1,6 +1,7 @@
 package v1alpha1_test
 
 import (
+	"slices"
 	"strconv"
 	"testing"
 
@@ -54,3 +55,83 @@ func TestAddTransitioningNode(t *testing.T) {
 	require.Equal(t, v1alpha1.MaxTransitioningNodes+12, wp.Status.TransitioningNodes)
 	require.Contains(t, wp.Status.NodesTransitioning, v1alpha1.TruncationNodeString)
 }
+
+func TestAddEnforcingNode(t *testing.T) {
+	wp := &v1alpha1.WorkloadPolicy{
+		Status: v1alpha1.WorkloadPolicyStatus{},
+	}
+
+	for i := range v1alpha1.MaxEnforcingNodes + 5 {
+		wp.Status.AddEnforcingNode(strconv.Itoa(i), "v1.2.0")
+	}
+	wp.Status.AddEnforcingNode("old", "")
+
+	// now we should have just MaxEnforcingNodes
+	require.Len(t, wp.Status.EnforcedBy, v1alpha1.MaxEnforcingNodes)
+	require.Contains(t, wp.Status.EnforcedBy, v1alpha1.TruncationNodeString)
+	require.Equal(t, v1alpha1.NodeEnforcement{EnforcerVersion: "v1.2.0"}, wp.Status.EnforcedBy["0"])
+	// but the versions should count every node
+	require.Equal(t, map[string]int{
+		"v1.2.0":                        v1alpha1.MaxEnforcingNodes + 5,
+		v1alpha1.UnknownEnforcerVersion: 1,
+	}, wp.Status.EnforcerVersions)
+}
+
+func TestWorkloadPolicyEffectiveMode(t *testing.T) {
+	wp := &v1alpha1.WorkloadPolicy{
+		Spec: v1alpha1.WorkloadPolicySpec{Mode: "protect"},
+	}
+	require.False(t, wp.IsPaused())
+	require.Equal(t, "protect", wp.EffectiveMode())
+
+	wp.Annotations = map[string]string{v1alpha1.PausedAnnotationKey: "true"}
+	require.True(t, wp.IsPaused())
+	require.Equal(t, "monitor", wp.EffectiveMode())
+	require.Equal(t, "protect", wp.Spec.Mode)
+
+	wp.Annotations[v1alpha1.PausedAnnotationKey] = "false"
+	require.False(t, wp.IsPaused())
+	require.Equal(t, "protect", wp.EffectiveMode())
+
+	wp.Annotations = map[string]string{v1alpha1.DryRunAnnotationKey: "true"}
+	require.True(t, wp.IsDryRun())
+	require.Equal(t, "monitor", wp.EffectiveMode())
+}
+
+func TestWorkloadPolicyEffectiveContainerMode(t *testing.T) {
+	wp := &v1alpha1.WorkloadPolicy{
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"app":     {},
+				"sidecar": {Mode: "monitor"},
+			},
+		},
+	}
+	require.Equal(t, "protect", wp.EffectiveContainerMode("app"))
+	require.Equal(t, "monitor", wp.EffectiveContainerMode("sidecar"))
+	require.Equal(t, "protect", wp.EffectiveContainerMode("unknown"))
+
+	wp.Spec.Mode = "monitor"
+	wp.Spec.RulesByContainer["app"].Mode = "protect"
+	require.Equal(t, "protect", wp.EffectiveContainerMode("app"))
+
+	wp.Annotations = map[string]string{v1alpha1.PausedAnnotationKey: "true"}
+	require.Equal(t, "monitor", wp.EffectiveContainerMode("app"))
+}
+
+func TestAddDryRunImpact(t *testing.T) {
+	wp := &v1alpha1.WorkloadPolicy{
+		Status: v1alpha1.WorkloadPolicyStatus{},
+	}
+
+	for i := range v1alpha1.MaxDryRunExecutables + 5 {
+		wp.Status.AddDryRunImpact("main", 2, []string{"/bin/exe-" + strconv.Itoa(i), "/bin/sh"})
+	}
+
+	// now we should have just MaxDryRunExecutables
+	require.Len(t, wp.Status.DryRunImpact["main"].Executables, v1alpha1.MaxDryRunExecutables)
+	require.True(t, slices.IsSorted(wp.Status.DryRunImpact["main"].Executables))
+	// but the count should reflect every execution
+	require.Equal(t, int64(2*(v1alpha1.MaxDryRunExecutables+5)), wp.Status.DryRunImpact["main"].Count)
+}
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"slices"
 
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -13,11 +14,38 @@ const (
 	// workloads that are already protected by an existing policy.
 	PromotedFromLabelKey = "workloadpolicy.security.rancher.io/promoted-from"
 
+	// PausedAnnotationKey pauses the enforcement of a WorkloadPolicy when set to "true":
+	// the agents apply the policy in monitor mode until the annotation is removed.
+	// Unlike editing spec.mode, it doesn't make the spec drift from the one declared
+	// in the source of truth (e.g. a GitOps repository).
+	PausedAnnotationKey = "workloadpolicy.security.rancher.io/paused"
+
+	// DryRunAnnotationKey applies a WorkloadPolicy in monitor mode when set to "true", like PausedAnnotationKey,
+	// and makes the agents report the executions its declared mode would have blocked. It is meant to assess
+	// the impact of a policy before its first enforcement: the annotation is removed to go live.
+	DryRunAnnotationKey = "workloadpolicy.security.rancher.io/dry-run"
+
+	// RelaxOnTerminationAnnotationKey applies a WorkloadPolicy in monitor mode to its terminating pods when set
+	// to "true", so that their preStop hooks and cleanup scripts are not blocked. It is honored by the agents
+	// configured to relax the policies annotated with it.
+	RelaxOnTerminationAnnotationKey = "workloadpolicy.security.rancher.io/relax-on-termination"
+
+	// ApprovedByAnnotationKey records who approved the WorkloadPolicyProposal a WorkloadPolicy is promoted from.
+	// The controller webhook sets it on the proposal to the user authenticated by the API server when
+	// ApprovalLabelKey is set, and it is copied to the WorkloadPolicy on promotion, so that the approval can be
+	// audited once the proposal is deleted.
+	ApprovedByAnnotationKey = "workloadpolicy.security.rancher.io/approved-by"
+
+	// ApprovedAtAnnotationKey records when the proposal was approved, in RFC 3339 format.
+	ApprovedAtAnnotationKey = "workloadpolicy.security.rancher.io/approved-at"
+
 	// MaxNodesWithIssues is the maximum number of nodes with issues to report.
 	// we don't want to overwhelm the user with too much information.
 	MaxNodesWithIssues = 20
 	// MaxTransitioningNodes is the maximum number of nodes transitioning to report.
 	MaxTransitioningNodes = 20
+	// MaxEnforcingNodes is the maximum number of nodes reported with the agent enforcing the policy.
+	MaxEnforcingNodes = 20
 )
 
 // Phase represents the current phase of the workload policy.
@@ -37,29 +65,245 @@ const (
 )
 
 type WorkloadPolicyExecutables struct {
-	// allowed defines a list of executables that are allowed to run
+	// allowed defines a list of executables that are allowed to run.
+	// Paths are absolute paths inside the container filesystem (e.g. /usr/bin/sleep),
+	// not paths on the host.
+	// An entry ending with `*` (e.g. /opt/app/bin/*) allows every executable whose path starts with the part
+	// before `*`, in any subdirectory. The part before `*` is limited to 248 bytes and `*` is only supported at
+	// the end. The global deny list of the agent applies to the executables allowed by such an entry too.
 	// +kubebuilder:validation:items:Pattern=`^/.*$`
 	// +optional
 	Allowed []string `json:"allowed,omitempty"`
+
+	// profiles references curated profiles bundled with the enforcer, whose executables are
+	// allowed on top of the allowed list. They are expanded by the agent when the policy is applied.
+	// +kubebuilder:validation:MaxItems=8
+	// +optional
+	Profiles []ExecutableProfileReference `json:"profiles,omitempty"`
+
+	// temporary defines executables that are allowed until their expiration, for temporary exceptions
+	// (e.g. a migration tool). Expired entries are removed from the allowed list by the agent.
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	Temporary []TemporaryExecutable `json:"temporary,omitempty"`
+
+	// approvedCommands restricts executables to complete command lines: an executable appearing in
+	// approvedCommands can only run with the exact arguments of one of its approved commands, the other
+	// executables are not affected. The executables of the approved commands are allowed too.
+	// The check is best effort: the arguments are read from the memory of the calling process, which
+	// another thread of that process can rewrite while the exec is checked.
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	ApprovedCommands []ApprovedCommand `json:"approvedCommands,omitempty"`
+
+	// imageScoped allows executables only in the containers running a given image, e.g. while the old and
+	// the new versions of a workload coexist during a rolling upgrade. A container gets the executables of
+	// the first entry matching its image on top of the other ones, the entries are ignored when none matches.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	ImageScoped []ImageScopedExecutables `json:"imageScoped,omitempty"`
+}
+
+// WorkloadPolicyFiles restricts the files the processes of a container can open.
+// The entries are absolute paths inside the container filesystem. An entry ending with `*` (e.g. /etc/*)
+// matches every file whose path starts with the part before `*`, in any subdirectory. The entries,
+// without their trailing `*`, are limited to 247 bytes. The most specific entry matching a file applies,
+// along with the less specific ones.
+type WorkloadPolicyFiles struct {
+	// allowedRead lists the files the container can open for reading.
+	// When it is set, opening any other file for reading is a violation.
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:Pattern=`^/.*$`
+	// +optional
+	AllowedRead []string `json:"allowedRead,omitempty"`
+
+	// allowedWrite lists the files the container can open for writing.
+	// When it is set, opening any other file for writing is a violation.
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:Pattern=`^/.*$`
+	// +optional
+	AllowedWrite []string `json:"allowedWrite,omitempty"`
+
+	// deniedWrite lists the files the container can never open for writing, even if they are allowed
+	// by allowedWrite (e.g. /etc/* while /etc/app/cache/* is allowed).
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:Pattern=`^/.*$`
+	// +optional
+	DeniedWrite []string `json:"deniedWrite,omitempty"`
+}
+
+// WorkloadPolicyNetwork restricts the network connections of the processes of a container.
+type WorkloadPolicyNetwork struct {
+	// allowedEgress lists the destinations the container can connect to, as `CIDR:port` or `IP:port`
+	// (e.g. 10.96.0.10:53, 10.0.0.0/8:443 or [2001:db8::/32]:443). When it is set, connecting to any
+	// other IPv4 or IPv6 destination is a violation. The host names are not supported: the connections
+	// are checked against the address they are made to, after the name resolution.
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	AllowedEgress []string `json:"allowedEgress,omitempty"`
+}
+
+// ImageScopedExecutables are executables allowed only in the containers running a given image.
+type ImageScopedExecutables struct {
+	// image selects the containers: a digest (e.g. sha256:4f0c...) matches the digest of the image, a reference
+	// with a `/` or a `:` (e.g. registry.example.com/app:v2.1.0) matches the image reference, with or without
+	// its digest, and any other value (e.g. v2.1.0) matches the tag of the image.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=512
+	// +required
+	Image string `json:"image"`
+
+	// allowed defines the executables allowed in the containers running the image.
+	// +kubebuilder:validation:items:Pattern=`^/.*$`
+	// +optional
+	Allowed []string `json:"allowed,omitempty"`
+}
+
+// ApprovedCommand is a complete command line allowed to run.
+type ApprovedCommand struct {
+	// path of the executable, an absolute path inside the container filesystem.
+	// +kubebuilder:validation:Pattern=`^/.*$`
+	// +required
+	Path string `json:"path"`
+
+	// args are the arguments following the program name (argv[0]), which is not part of the command
+	// since the caller can set it freely. They are compared byte by byte, with no shell parsing.
+	// +kubebuilder:validation:MaxItems=32
+	// +kubebuilder:validation:items:MaxLength=256
+	// +optional
+	Args []string `json:"args,omitempty"`
+}
+
+// TemporaryExecutable is an executable allowed until a given time.
+type TemporaryExecutable struct {
+	// path of the executable, an absolute path inside the container filesystem.
+	// +kubebuilder:validation:Pattern=`^/.*$`
+	// +required
+	Path string `json:"path"`
+
+	// expiresAt is the time after which the executable is no longer allowed.
+	// +required
+	ExpiresAt metav1.Time `json:"expiresAt"`
+}
+
+// ExecutableProfileReference references a versioned profile of allowed executables bundled with the enforcer.
+type ExecutableProfileReference struct {
+	// name of the profile (e.g. nginx).
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Name string `json:"name"`
+
+	// version of the profile. The executables of a version never change, so upgrading the enforcer
+	// doesn't change the allowed list of the policies referencing it.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Version string `json:"version"`
 }
 
 type WorkloadPolicyRules struct {
 	// executables defines a security policy for executables.
 	// +optional
 	Executables WorkloadPolicyExecutables `json:"executables,omitempty"`
+
+	// files restricts the files the container can open, on top of the executables it can run.
+	// Nothing is restricted when it is empty.
+	// +optional
+	Files WorkloadPolicyFiles `json:"files,omitempty"`
+
+	// network restricts the destinations the container can connect to.
+	// Nothing is restricted when it is empty.
+	// +optional
+	Network WorkloadPolicyNetwork `json:"network,omitempty"`
+
+	// listeningPorts scopes the rules to the processes listening on one of these TCP ports
+	// (e.g. only the process serving the web traffic).
+	// The scope is not enforced yet: the rules still apply to every process of the container
+	// and the violations are tagged with whether the process was listening on one of the ports.
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:Minimum=1
+	// +kubebuilder:validation:items:Maximum=65535
+	// +optional
+	ListeningPorts []int32 `json:"listeningPorts,omitempty"`
+
+	// mode overrides the mode of the policy for the container, e.g. to keep a sidecar in "monitor" mode
+	// while the main container is in "protect" mode. The mode of the policy applies when it is empty.
+	// Pausing the policy or running it in dry run switches the container to "monitor" mode too.
+	// +kubebuilder:validation:Enum=monitor;protect;audit
+	// +optional
+	Mode string `json:"mode,omitempty"`
 }
 
 type WorkloadPolicySpec struct {
 	// mode defines the execution mode of this policy. Can be set to
-	// either "protect" or "monitor". In "protect" mode, the policy
+	// "protect", "monitor" or "audit". In "protect" mode, the policy
 	// blocks and reports violations, while in "monitor" mode,
-	// it only reports violations.
-	// +kubebuilder:validation:Enum=monitor;protect
+	// it only reports violations. In "audit" mode, nothing is blocked
+	// and every execution is reported, allowed ones included.
+	// +kubebuilder:validation:Enum=monitor;protect;audit
 	// +kubebuilder:validation:Required
 	Mode string `json:"mode,omitempty"`
 
 	// rulesByContainer specifies for each container the list of rules to apply.
 	RulesByContainer map[string]*WorkloadPolicyRules `json:"rulesByContainer,omitempty"`
+
+	// blockSuidExec reports the execution of setuid/setgid binaries as a violation,
+	// even if they are in the allowed list. In "protect" mode, the execution is blocked.
+	// +optional
+	BlockSuidExec bool `json:"blockSuidExec,omitempty"`
+
+	// blockScripts reports the execution of scripts, i.e. files starting with a "#!" shebang line,
+	// as a violation, even if they are in the allowed list. In "protect" mode, the execution is blocked.
+	// +optional
+	BlockScripts bool `json:"blockScripts,omitempty"`
+
+	// blockUnlinkedExec reports the execution of files with no name left in the filesystem, i.e. deleted binaries
+	// and anonymous memory files created with memfd_create(2), and of the files whose path cannot be resolved,
+	// as a violation, even if their path is in the allowed list. In "protect" mode, the execution is blocked.
+	// +optional
+	BlockUnlinkedExec bool `json:"blockUnlinkedExec,omitempty"`
+
+	// caseInsensitiveMatching compares the executed paths with the allowed executables ignoring the ASCII case.
+	// Linux paths are case-sensitive: every case variant of an allowed path becomes allowed too,
+	// so enable it only for workloads that really need it.
+	// +optional
+	CaseInsensitiveMatching bool `json:"caseInsensitiveMatching,omitempty"`
+
+	// maxDistinctExecutables limits the number of distinct executables each container may run,
+	// on top of the allowed list. Every new distinct executable beyond the limit is reported as a violation
+	// and, in "protect" mode, blocked. The count of a container is only reset when the container restarts.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxDistinctExecutables int32 `json:"maxDistinctExecutables,omitempty"`
+
+	// allowedFilesystems restricts the executions to the files stored on one of these filesystem types,
+	// e.g. to stop the binaries dropped on a tmpfs mount. The execution of a file stored on another filesystem type
+	// is reported as a violation, even if its path is in the allowed list. In "protect" mode, the execution is blocked.
+	// The files of a container image are usually on overlay, and ext4 also matches ext2 and ext3.
+	// When unset, the executables may reside on any filesystem.
+	// +kubebuilder:validation:MaxItems=8
+	// +kubebuilder:validation:items:Enum=btrfs;ext4;f2fs;fuse;nfs;overlay;ramfs;squashfs;tmpfs;xfs;zfs
+	// +optional
+	AllowedFilesystems []string `json:"allowedFilesystems,omitempty"`
+
+	// canaryPercent enforces the declared mode only on the given percentage of the matching pods,
+	// the other pods run in "monitor" mode. On each node, the pods of a workload are ranked by their UID and
+	// the percentage of them is selected, rounded up but never all of them: a workload with a single pod on a node
+	// is not enforced there until the rollout is over. Raising the percentage only adds pods to the selection.
+	// When unset, every matching pod is enforced.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	CanaryPercent int32 `json:"canaryPercent,omitempty"`
+
+	// allowedServiceAccounts restricts the policy to the pods running with one of these service accounts
+	// of the policy namespace, so that a pod cannot inherit the policy of another team by setting the policy label.
+	// The agent refuses to enforce the policy on the other pods and reports it with an event on the pod.
+	// The service account is learned from the API server: a container whose pod service account is not known yet
+	// waits for it briefly and is then prevented from starting, unless agent.nriFailopen is set.
+	// A policy is never enforced on the pods of other namespaces. When unset, every pod of the namespace may use it.
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	AllowedServiceAccounts []string `json:"allowedServiceAccounts,omitempty"`
 }
 
 const MaxViolationRecords = 100
@@ -80,6 +324,39 @@ type ViolationRecord struct {
 	Action string `json:"action"`
 }
 
+// MaxSummaryExecutables is the maximum number of executables reported in a ViolationSummary.
+const MaxSummaryExecutables = 10
+
+// ExecutableViolationSummary aggregates the violations of a single executable.
+type ExecutableViolationSummary struct {
+	// executablePath is the path of the unauthorized executable.
+	ExecutablePath string `json:"executablePath"`
+	// count is the number of violations of the executable.
+	Count int64 `json:"count"`
+	// blockedCount is the number of executions blocked in "protect" mode.
+	// +optional
+	BlockedCount int64 `json:"blockedCount,omitempty"`
+	// firstSeen is when the first violation of the executable occurred.
+	FirstSeen metav1.Time `json:"firstSeen"`
+	// lastSeen is when the last violation of the executable occurred.
+	LastSeen metav1.Time `json:"lastSeen"`
+}
+
+// ViolationSummary aggregates the violations of a policy over a reporting window.
+type ViolationSummary struct {
+	// windowStart is when the reporting window started.
+	WindowStart metav1.Time `json:"windowStart"`
+	// windowEnd is when the reporting window ended.
+	WindowEnd metav1.Time `json:"windowEnd"`
+	// totalCount is the number of violations in the window,
+	// including those of executables not listed in topExecutables.
+	TotalCount int64 `json:"totalCount"`
+	// topExecutables lists the executables with the most violations in the window,
+	// sorted by count (max MaxSummaryExecutables).
+	// +optional
+	TopExecutables []ExecutableViolationSummary `json:"topExecutables,omitempty"`
+}
+
 type WorkloadPolicyStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// nodesWithIssues contains the status of each node with issues.
@@ -94,6 +371,14 @@ type WorkloadPolicyStatus struct {
 	TransitioningNodes int `json:"transitioningNodes,omitempty"`
 	// nodesTransitioning contains the names of the nodes that are transitioning.
 	NodesTransitioning []string `json:"nodesTransitioning,omitempty"`
+	// enforcedBy contains, for each node where the policy is applied, the agent which applied it
+	// (max MaxEnforcingNodes nodes).
+	// +optional
+	EnforcedBy map[string]NodeEnforcement `json:"enforcedBy,omitempty"`
+	// enforcerVersions is the number of nodes where the policy is applied by each version of the agent.
+	// More than one version means that the agents are being upgraded.
+	// +optional
+	EnforcerVersions map[string]int `json:"enforcerVersions,omitempty"`
 	// phase indicates the current phase of the workload policy.
 	Phase Phase `json:"phase,omitempty"`
 	// violationCount is the total number of violation records,
@@ -109,6 +394,26 @@ type WorkloadPolicyStatus struct {
 	// Oldest entries are dropped when the limit is reached.
 	// +optional
 	Violations []ViolationRecord `json:"violations,omitempty"`
+	// lastViolationTime is when the most recent violation of the policy happened.
+	// It is updated at most once per status sync interval of the controller.
+	// +optional
+	LastViolationTime *metav1.Time `json:"lastViolationTime,omitempty"`
+	// lastViolationPath is the executable of the most recent violation of the policy.
+	// +optional
+	LastViolationPath string `json:"lastViolationPath,omitempty"`
+	// violationSummary is the summary of the violations of the last completed reporting window.
+	// It is only reported when the periodic summary is enabled in the controller.
+	// +optional
+	ViolationSummary *ViolationSummary `json:"violationSummary,omitempty"`
+	// unobservedExecutables lists, for each container, the allowed executables that have never been run
+	// on any node during the observation period. They are likely typos or dead entries.
+	// It is only reported when the observation is enabled in the controller.
+	// +optional
+	UnobservedExecutables map[string][]string `json:"unobservedExecutables,omitempty"`
+	// dryRunImpact reports, for each container, the executions the declared mode would have blocked on all the
+	// nodes since the start of the dry run. It is only reported while the policy is in dry run.
+	// +optional
+	DryRunImpact map[string]DryRunImpact `json:"dryRunImpact,omitempty"`
 }
 
 func (s *WorkloadPolicyStatus) AddNodeIssue(nodeName string, issue NodeIssue) {
@@ -130,6 +435,29 @@ func (s *WorkloadPolicyStatus) AddNodeIssue(nodeName string, issue NodeIssue) {
 	}
 }
 
+func (s *WorkloadPolicyStatus) AddEnforcingNode(nodeName string, enforcerVersion string) {
+	if enforcerVersion == "" {
+		enforcerVersion = UnknownEnforcerVersion
+	}
+
+	// we always count the version
+	if s.EnforcerVersions == nil {
+		s.EnforcerVersions = make(map[string]int)
+	}
+	s.EnforcerVersions[enforcerVersion]++
+
+	if s.EnforcedBy == nil {
+		s.EnforcedBy = make(map[string]NodeEnforcement, MaxEnforcingNodes)
+	}
+
+	// we store up to MaxEnforcingNodes-1, the last element will be a marker of max reached
+	if len(s.EnforcedBy) < MaxEnforcingNodes-1 {
+		s.EnforcedBy[nodeName] = NodeEnforcement{EnforcerVersion: enforcerVersion}
+	} else if len(s.EnforcedBy) == MaxEnforcingNodes-1 {
+		s.EnforcedBy[TruncationNodeString] = NodeEnforcement{}
+	}
+}
+
 func (s *WorkloadPolicyStatus) SortTransitioningNodes() {
 	if len(s.NodesTransitioning) == 0 {
 		return
@@ -185,6 +513,35 @@ func (wp *WorkloadPolicy) NamespacedName() string {
 	return wp.Namespace + "/" + wp.Name
 }
 
+// IsPaused returns true if the enforcement of the policy is paused by the PausedAnnotationKey annotation.
+func (wp *WorkloadPolicy) IsPaused() bool {
+	return wp.Annotations[PausedAnnotationKey] == "true"
+}
+
+// IsDryRun returns true if the policy is applied in dry run by the DryRunAnnotationKey annotation.
+func (wp *WorkloadPolicy) IsDryRun() bool {
+	return wp.Annotations[DryRunAnnotationKey] == "true"
+}
+
+// EffectiveMode returns the mode the agents should apply: the declared mode, or monitor while the policy is paused
+// or in dry run.
+func (wp *WorkloadPolicy) EffectiveMode() string {
+	if wp.IsPaused() || wp.IsDryRun() {
+		return policymode.MonitorString
+	}
+	return wp.Spec.Mode
+}
+
+// EffectiveContainerMode returns the mode the agents should apply to a container: the mode of its rules
+// when it is set, the effective mode of the policy otherwise, see EffectiveMode.
+func (wp *WorkloadPolicy) EffectiveContainerMode(containerName string) string {
+	rules := wp.Spec.RulesByContainer[containerName]
+	if rules == nil || rules.Mode == "" || wp.IsPaused() || wp.IsDryRun() {
+		return wp.EffectiveMode()
+	}
+	return rules.Mode
+}
+
 // +kubebuilder:object:root=true
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
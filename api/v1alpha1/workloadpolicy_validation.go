@@ -0,0 +1,280 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// MaxExecutablePathLength is the maximum length of an executable path the agents can write into the BPF maps.
+const MaxExecutablePathLength = 4096
+
+// MaxExecutablePathLengthPre5_11 is the maximum length of an executable path the agents can write into the BPF maps
+// on the nodes running a kernel older than 5.11.
+const MaxExecutablePathLengthPre5_11 = 512
+
+// MaxExecutablePrefixLength is the maximum length of the prefix of an allowed entry ending with `*`,
+// the BPF map matching the prefixes has much shorter keys than the ones of the exact paths.
+const MaxExecutablePrefixLength = 248
+
+// MaxFilePathLength is the maximum length of a file entry without its trailing `*`,
+// the files are matched by the same kind of BPF map as the prefixes of the executables.
+const MaxFilePathLength = 247
+
+// ValidationError is an issue found in a WorkloadPolicy by ValidateWorkloadPolicy.
+type ValidationError struct {
+	// Field is the path of the invalid field, e.g. spec.rulesByContainer[app].executables.allowed[0].
+	Field string
+	// Message describes the issue.
+	Message string
+	// Warning is true when the policy is still valid, e.g. for a duplicate executable.
+	Warning bool
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidateWorkloadPolicy checks the rules of the spec that the CRD validation cannot express.
+// It is the single source of truth of the validity of a policy, shared by the webhook and the tools linting
+// the policies. The returned errors are *ValidationError: the policy is valid if they are all warnings.
+func ValidateWorkloadPolicy(spec *WorkloadPolicySpec) []error {
+	var errs []error
+	specPath := field.NewPath("spec")
+
+	if !isValidMode(spec.Mode) {
+		errs = append(errs, &ValidationError{
+			Field:   specPath.Child("mode").String(),
+			Message: unknownModeMessage(spec.Mode),
+		})
+	}
+
+	rulesPath := specPath.Child("rulesByContainer")
+	if len(spec.RulesByContainer) == 0 {
+		errs = append(errs, &ValidationError{
+			Field:   rulesPath.String(),
+			Message: "at least one container must have rules",
+		})
+	}
+	for _, containerName := range slices.Sorted(maps.Keys(spec.RulesByContainer)) {
+		errs = append(errs, validateRules(rulesPath.Key(containerName), spec.RulesByContainer[containerName])...)
+	}
+
+	for i, serviceAccount := range spec.AllowedServiceAccounts {
+		if msgs := validation.IsDNS1123Subdomain(serviceAccount); len(msgs) > 0 {
+			errs = append(errs, &ValidationError{
+				Field:   specPath.Child("allowedServiceAccounts").Index(i).String(),
+				Message: fmt.Sprintf("%q is not a valid service account name: %s", serviceAccount, msgs[0]),
+			})
+		}
+	}
+	return errs
+}
+
+func isValidMode(mode string) bool {
+	return mode == policymode.MonitorString || mode == policymode.ProtectString || mode == policymode.AuditString
+}
+
+func unknownModeMessage(mode string) string {
+	return fmt.Sprintf("unknown mode %q, it must be %q, %q or %q",
+		mode, policymode.MonitorString, policymode.ProtectString, policymode.AuditString)
+}
+
+// validateRules checks the rules of a container.
+func validateRules(rulesPath *field.Path, rules *WorkloadPolicyRules) []error {
+	if rules == nil || isEmptyExecutables(&rules.Executables) {
+		return []error{&ValidationError{
+			Field:   rulesPath.String(),
+			Message: "the container has no allowed executable",
+		}}
+	}
+
+	var errs []error
+	if rules.Mode != "" && !isValidMode(rules.Mode) {
+		errs = append(errs, &ValidationError{
+			Field:   rulesPath.Child("mode").String(),
+			Message: unknownModeMessage(rules.Mode),
+		})
+	}
+	executablesPath := rulesPath.Child("executables")
+	errs = append(errs, validatePaths(executablesPath.Child("allowed"), rules.Executables.Allowed)...)
+	for i, temporary := range rules.Executables.Temporary {
+		temporaryPath := executablesPath.Child("temporary").Index(i)
+		errs = append(errs, validatePath(temporaryPath.Child("path"), temporary.Path)...)
+	}
+	for i, command := range rules.Executables.ApprovedCommands {
+		commandPath := executablesPath.Child("approvedCommands").Index(i)
+		errs = append(errs, validatePath(commandPath.Child("path"), command.Path)...)
+	}
+	for i, scoped := range rules.Executables.ImageScoped {
+		scopedPath := executablesPath.Child("imageScoped").Index(i)
+		errs = append(errs, validatePaths(scopedPath.Child("allowed"), scoped.Allowed)...)
+	}
+	filesPath := rulesPath.Child("files")
+	errs = append(errs, validateFiles(filesPath.Child("allowedRead"), rules.Files.AllowedRead)...)
+	errs = append(errs, validateFiles(filesPath.Child("allowedWrite"), rules.Files.AllowedWrite)...)
+	errs = append(errs, validateFiles(filesPath.Child("deniedWrite"), rules.Files.DeniedWrite)...)
+	for i, entry := range rules.Network.AllowedEgress {
+		if _, _, err := ParseEgressDestination(entry); err != nil {
+			errs = append(errs, &ValidationError{
+				Field:   rulesPath.Child("network", "allowedEgress").Index(i).String(),
+				Message: err.Error(),
+			})
+		}
+	}
+	return errs
+}
+
+// ParseEgressDestination parses an entry of network.allowedEgress into the allowed addresses and port.
+// An IP address is returned as a prefix containing only itself and an IPv4-mapped IPv6 address as an IPv4 one.
+func ParseEgressDestination(entry string) (netip.Prefix, uint16, error) {
+	host, portValue, err := net.SplitHostPort(entry)
+	if err != nil {
+		return netip.Prefix{}, 0, fmt.Errorf("%q is not of the form CIDR:port or IP:port", entry)
+	}
+	port, err := strconv.ParseUint(portValue, 10, 16)
+	if err != nil || port == 0 {
+		return netip.Prefix{}, 0, fmt.Errorf("%q has an invalid port, it must be between 1 and 65535", entry)
+	}
+	if strings.Contains(host, "/") {
+		prefix, prefixErr := netip.ParsePrefix(host)
+		if prefixErr != nil {
+			return netip.Prefix{}, 0, fmt.Errorf("%q is not a valid CIDR", host)
+		}
+		return prefix.Masked(), uint16(port), nil
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || addr.Zone() != "" {
+		return netip.Prefix{}, 0, fmt.Errorf("%q is not an IP address or a CIDR, the host names are not supported", host)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), uint16(port), nil
+}
+
+// validateFiles checks a list of file entries.
+func validateFiles(listPath *field.Path, files []string) []error {
+	var errs []error
+	for i, file := range files {
+		fieldPath := listPath.Index(i)
+		if !strings.HasPrefix(file, "/") {
+			errs = append(errs, &ValidationError{
+				Field:   fieldPath.String(),
+				Message: fmt.Sprintf("%q is not an absolute path", file),
+			})
+		}
+		if hasParentSegment(file) {
+			errs = append(errs, &ValidationError{
+				Field:   fieldPath.String(),
+				Message: fmt.Sprintf("%q contains a `..` segment, the files are matched by their resolved path", file),
+			})
+		}
+		path := strings.TrimSuffix(file, "*")
+		if strings.Contains(path, "*") {
+			errs = append(errs, &ValidationError{
+				Field:   fieldPath.String(),
+				Message: fmt.Sprintf("%q has a `*` before its end, only a trailing `*` is supported", file),
+			})
+		}
+		if len(path) > MaxFilePathLength {
+			errs = append(errs, &ValidationError{
+				Field:   fieldPath.String(),
+				Message: fmt.Sprintf("the path is longer than %d bytes", MaxFilePathLength),
+			})
+		}
+	}
+	return errs
+}
+
+// isEmptyExecutables returns true when the executables allow nothing.
+func isEmptyExecutables(executables *WorkloadPolicyExecutables) bool {
+	return len(executables.Allowed) == 0 &&
+		len(executables.Profiles) == 0 &&
+		len(executables.Temporary) == 0 &&
+		len(executables.ApprovedCommands) == 0 &&
+		len(executables.ImageScoped) == 0
+}
+
+// validatePaths checks a list of executable paths, the duplicates are reported as warnings.
+func validatePaths(listPath *field.Path, paths []string) []error {
+	var errs []error
+	seen := make(map[string]int, len(paths))
+	for i, path := range paths {
+		errs = append(errs, validatePath(listPath.Index(i), path)...)
+		errs = append(errs, validateWildcard(listPath.Index(i), path)...)
+		if first, ok := seen[path]; ok {
+			errs = append(errs, &ValidationError{
+				Field:   listPath.Index(i).String(),
+				Message: fmt.Sprintf("duplicate of %s", listPath.Index(first)),
+				Warning: true,
+			})
+			continue
+		}
+		seen[path] = i
+	}
+	return errs
+}
+
+// validatePath checks an executable path.
+func validatePath(fieldPath *field.Path, path string) []error {
+	var errs []error
+	if !strings.HasPrefix(path, "/") {
+		errs = append(errs, &ValidationError{
+			Field:   fieldPath.String(),
+			Message: fmt.Sprintf("%q is not an absolute path", path),
+		})
+	}
+	if hasParentSegment(path) {
+		errs = append(errs, &ValidationError{
+			Field:   fieldPath.String(),
+			Message: fmt.Sprintf("%q contains a `..` segment, the executables are matched by their resolved path", path),
+		})
+	}
+	switch {
+	case len(path) > MaxExecutablePathLength:
+		errs = append(errs, &ValidationError{
+			Field:   fieldPath.String(),
+			Message: fmt.Sprintf("the path is longer than %d bytes", MaxExecutablePathLength),
+		})
+	case len(path) > MaxExecutablePathLengthPre5_11 && !strings.HasSuffix(path, "*"):
+		// the kernel of the nodes is not known here, the agents reject the path on the older kernels.
+		errs = append(errs, &ValidationError{
+			Field: fieldPath.String(),
+			Message: fmt.Sprintf("the path is longer than %d bytes, it cannot be enforced on the nodes "+
+				"running a kernel older than 5.11", MaxExecutablePathLengthPre5_11),
+			Warning: true,
+		})
+	}
+	return errs
+}
+
+// hasParentSegment returns true when the path contains a `..` segment, which never matches
+// the paths resolved by the kernel.
+func hasParentSegment(path string) bool {
+	return slices.Contains(strings.Split(path, "/"), "..")
+}
+
+// validateWildcard checks the `*` of an allowed executable, which is only supported at the end of the path.
+func validateWildcard(fieldPath *field.Path, path string) []error {
+	prefix, found := strings.CutSuffix(path, "*")
+	if strings.Contains(prefix, "*") {
+		return []error{&ValidationError{
+			Field:   fieldPath.String(),
+			Message: fmt.Sprintf("%q has a `*` before its end, only a trailing `*` is supported", path),
+		}}
+	}
+	if found && len(prefix) > MaxExecutablePrefixLength {
+		return []error{&ValidationError{
+			Field:   fieldPath.String(),
+			Message: fmt.Sprintf("the prefix before `*` is longer than %d bytes", MaxExecutablePrefixLength),
+		}}
+	}
+	return nil
+}
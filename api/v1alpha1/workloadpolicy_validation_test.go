@@ -0,0 +1,350 @@
+package v1alpha1_test
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestParseEgressDestination(t *testing.T) {
+	tests := []struct {
+		entry  string
+		prefix netip.Prefix
+		port   uint16
+	}{
+		{entry: "10.96.0.10:53", prefix: netip.MustParsePrefix("10.96.0.10/32"), port: 53},
+		{entry: "10.1.2.3/8:443", prefix: netip.MustParsePrefix("10.0.0.0/8"), port: 443},
+		{entry: "[2001:db8::1]:8443", prefix: netip.MustParsePrefix("2001:db8::1/128"), port: 8443},
+		{entry: "[2001:db8::/32]:443", prefix: netip.MustParsePrefix("2001:db8::/32"), port: 443},
+		{entry: "[::ffff:10.0.0.1]:80", prefix: netip.MustParsePrefix("10.0.0.1/32"), port: 80},
+	}
+	for _, tt := range tests {
+		prefix, port, err := v1alpha1.ParseEgressDestination(tt.entry)
+		require.NoError(t, err, tt.entry)
+		require.Equal(t, tt.prefix, prefix, tt.entry)
+		require.Equal(t, tt.port, port, tt.entry)
+	}
+}
+
+func TestValidateWorkloadPolicy(t *testing.T) {
+	withRules := func(rules *v1alpha1.WorkloadPolicyRules) *v1alpha1.WorkloadPolicySpec {
+		return &v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{"app": rules},
+		}
+	}
+	withAllowed := func(allowed ...string) *v1alpha1.WorkloadPolicySpec {
+		return withRules(&v1alpha1.WorkloadPolicyRules{
+			Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: allowed},
+		})
+	}
+
+	tests := []struct {
+		name     string
+		spec     *v1alpha1.WorkloadPolicySpec
+		expected []v1alpha1.ValidationError
+	}{
+		{
+			name: "valid protect policy",
+			spec: withAllowed("/usr/bin/sleep", "/bin/sh"),
+		},
+		{
+			name: "valid monitor policy with a profile only",
+			spec: &v1alpha1.WorkloadPolicySpec{
+				Mode: "monitor",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"app": {Executables: v1alpha1.WorkloadPolicyExecutables{
+						Profiles: []v1alpha1.ExecutableProfileReference{{Name: "nginx", Version: "1"}},
+					}},
+				},
+			},
+		},
+		{
+			name: "unknown mode",
+			spec: &v1alpha1.WorkloadPolicySpec{
+				Mode: "block",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}}},
+				},
+			},
+			expected: []v1alpha1.ValidationError{
+				{Field: "spec.mode", Message: `unknown mode "block", it must be "monitor", "protect" or "audit"`},
+			},
+		},
+		{
+			name: "container mode override",
+			spec: &v1alpha1.WorkloadPolicySpec{
+				Mode: "protect",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}}},
+					"sidecar": {
+						Mode:        "monitor",
+						Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}},
+					},
+					"worker": {
+						Mode:        "audit",
+						Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}},
+					},
+					"debug": {
+						Mode:        "block",
+						Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}},
+					},
+				},
+			},
+			expected: []v1alpha1.ValidationError{
+				{
+					Field:   "spec.rulesByContainer[debug].mode",
+					Message: `unknown mode "block", it must be "monitor", "protect" or "audit"`,
+				},
+			},
+		},
+		{
+			name: "files",
+			spec: &v1alpha1.WorkloadPolicySpec{
+				Mode: "protect",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"app": {
+						Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}},
+						Files: v1alpha1.WorkloadPolicyFiles{
+							AllowedRead:  []string{"/etc/*", "/app/config.yaml", "/var/*/log"},
+							AllowedWrite: []string{"tmp/*"},
+							DeniedWrite:  []string{"/" + strings.Repeat("a", v1alpha1.MaxFilePathLength) + "*"},
+						},
+					},
+				},
+			},
+			expected: []v1alpha1.ValidationError{
+				{
+					Field:   "spec.rulesByContainer[app].files.allowedRead[2]",
+					Message: "\"/var/*/log\" has a `*` before its end, only a trailing `*` is supported",
+				},
+				{
+					Field:   "spec.rulesByContainer[app].files.allowedWrite[0]",
+					Message: `"tmp/*" is not an absolute path`,
+				},
+				{
+					Field:   "spec.rulesByContainer[app].files.deniedWrite[0]",
+					Message: "the path is longer than 247 bytes",
+				},
+			},
+		},
+		{
+			name: "network",
+			spec: &v1alpha1.WorkloadPolicySpec{
+				Mode: "protect",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"app": {
+						Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}},
+						Network: v1alpha1.WorkloadPolicyNetwork{
+							AllowedEgress: []string{
+								"10.96.0.10:53",
+								"[2001:db8::/32]:443",
+								"example.com:443",
+								"10.0.0.0/8:0",
+								"10.0.0.0/33:443",
+								"10.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: []v1alpha1.ValidationError{
+				{
+					Field:   "spec.rulesByContainer[app].network.allowedEgress[2]",
+					Message: `"example.com" is not an IP address or a CIDR, the host names are not supported`,
+				},
+				{
+					Field:   "spec.rulesByContainer[app].network.allowedEgress[3]",
+					Message: `"10.0.0.0/8:0" has an invalid port, it must be between 1 and 65535`,
+				},
+				{
+					Field:   "spec.rulesByContainer[app].network.allowedEgress[4]",
+					Message: `"10.0.0.0/33" is not a valid CIDR`,
+				},
+				{
+					Field:   "spec.rulesByContainer[app].network.allowedEgress[5]",
+					Message: `"10.0.0.1" is not of the form CIDR:port or IP:port`,
+				},
+			},
+		},
+		{
+			name: "no container",
+			spec: &v1alpha1.WorkloadPolicySpec{Mode: "protect"},
+			expected: []v1alpha1.ValidationError{
+				{Field: "spec.rulesByContainer", Message: "at least one container must have rules"},
+			},
+		},
+		{
+			name: "container without rules",
+			spec: withRules(nil),
+			expected: []v1alpha1.ValidationError{
+				{Field: "spec.rulesByContainer[app]", Message: "the container has no allowed executable"},
+			},
+		},
+		{
+			name: "container without executables",
+			spec: withRules(&v1alpha1.WorkloadPolicyRules{ListeningPorts: []int32{8080}}),
+			expected: []v1alpha1.ValidationError{
+				{Field: "spec.rulesByContainer[app]", Message: "the container has no allowed executable"},
+			},
+		},
+		{
+			name: "relative allowed path",
+			spec: withAllowed("/bin/sh", "bin/sleep"),
+			expected: []v1alpha1.ValidationError{
+				{
+					Field:   "spec.rulesByContainer[app].executables.allowed[1]",
+					Message: `"bin/sleep" is not an absolute path`,
+				},
+			},
+		},
+		{
+			name: "relative paths of the other executables",
+			spec: withRules(&v1alpha1.WorkloadPolicyRules{
+				Executables: v1alpha1.WorkloadPolicyExecutables{
+					Temporary:        []v1alpha1.TemporaryExecutable{{Path: "migrate"}},
+					ApprovedCommands: []v1alpha1.ApprovedCommand{{Path: "curl"}},
+					ImageScoped:      []v1alpha1.ImageScopedExecutables{{Image: "v2", Allowed: []string{"app"}}},
+				},
+			}),
+			expected: []v1alpha1.ValidationError{
+				{
+					Field:   "spec.rulesByContainer[app].executables.temporary[0].path",
+					Message: `"migrate" is not an absolute path`,
+				},
+				{
+					Field:   "spec.rulesByContainer[app].executables.approvedCommands[0].path",
+					Message: `"curl" is not an absolute path`,
+				},
+				{
+					Field:   "spec.rulesByContainer[app].executables.imageScoped[0].allowed[0]",
+					Message: `"app" is not an absolute path`,
+				},
+			},
+		},
+		{
+			name: "path too long",
+			spec: withAllowed("/" + strings.Repeat("a", v1alpha1.MaxExecutablePathLength)),
+			expected: []v1alpha1.ValidationError{
+				{
+					Field:   "spec.rulesByContainer[app].executables.allowed[0]",
+					Message: "the path is longer than 4096 bytes",
+				},
+			},
+		},
+		{
+			name: "path at the length limit",
+			spec: withAllowed(
+				"/"+strings.Repeat("a", v1alpha1.MaxExecutablePathLengthPre5_11-1),
+				"/"+strings.Repeat("b", v1alpha1.MaxExecutablePathLength-1),
+			),
+			expected: []v1alpha1.ValidationError{
+				{
+					Field: "spec.rulesByContainer[app].executables.allowed[1]",
+					Message: "the path is longer than 512 bytes, it cannot be enforced on the nodes " +
+						"running a kernel older than 5.11",
+					Warning: true,
+				},
+			},
+		},
+		{
+			name: "parent segments",
+			spec: withRules(&v1alpha1.WorkloadPolicyRules{
+				Executables: v1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/usr/bin/../bin/sh", "/opt/..app/run", "/opt/app/.."},
+				},
+				Files: v1alpha1.WorkloadPolicyFiles{AllowedRead: []string{"/etc/../var/*"}},
+			}),
+			expected: []v1alpha1.ValidationError{
+				{
+					Field:   "spec.rulesByContainer[app].executables.allowed[0]",
+					Message: "\"/usr/bin/../bin/sh\" contains a `..` segment, the executables are matched by their resolved path",
+				},
+				{
+					Field:   "spec.rulesByContainer[app].executables.allowed[2]",
+					Message: "\"/opt/app/..\" contains a `..` segment, the executables are matched by their resolved path",
+				},
+				{
+					Field:   "spec.rulesByContainer[app].files.allowedRead[0]",
+					Message: "\"/etc/../var/*\" contains a `..` segment, the files are matched by their resolved path",
+				},
+			},
+		},
+		{
+			name: "allowed prefixes",
+			spec: withAllowed(
+				"/opt/app/bin/*",
+				"/usr/lib/jvm*",
+				"/"+strings.Repeat("a", v1alpha1.MaxExecutablePrefixLength-1)+"*",
+			),
+		},
+		{
+			name: "invalid wildcards",
+			spec: withAllowed("/usr/lib/jvm/*/bin/java", "/"+strings.Repeat("a", v1alpha1.MaxExecutablePrefixLength)+"*"),
+			expected: []v1alpha1.ValidationError{
+				{
+					Field:   "spec.rulesByContainer[app].executables.allowed[0]",
+					Message: "\"/usr/lib/jvm/*/bin/java\" has a `*` before its end, only a trailing `*` is supported",
+				},
+				{
+					Field:   "spec.rulesByContainer[app].executables.allowed[1]",
+					Message: "the prefix before `*` is longer than 248 bytes",
+				},
+			},
+		},
+		{
+			name: "duplicate allowed path",
+			spec: withAllowed("/bin/sh", "/usr/bin/sleep", "/bin/sh"),
+			expected: []v1alpha1.ValidationError{
+				{
+					Field:   "spec.rulesByContainer[app].executables.allowed[2]",
+					Message: "duplicate of spec.rulesByContainer[app].executables.allowed[0]",
+					Warning: true,
+				},
+			},
+		},
+		{
+			name: "valid service accounts",
+			spec: &v1alpha1.WorkloadPolicySpec{
+				Mode: "protect",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}}},
+				},
+				AllowedServiceAccounts: []string{"default", "team-a.builder"},
+			},
+		},
+		{
+			name: "invalid service account",
+			spec: &v1alpha1.WorkloadPolicySpec{
+				Mode: "protect",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}}},
+				},
+				AllowedServiceAccounts: []string{"default", "Team_A"},
+			},
+			expected: []v1alpha1.ValidationError{
+				{
+					Field: "spec.allowedServiceAccounts[1]",
+					Message: `"Team_A" is not a valid service account name: ` +
+						validation.IsDNS1123Subdomain("Team_A")[0],
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []v1alpha1.ValidationError
+			for _, err := range v1alpha1.ValidateWorkloadPolicy(tt.spec) {
+				var validationErr *v1alpha1.ValidationError
+				require.True(t, errors.As(err, &validationErr))
+				got = append(got, *validationErr)
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -88,7 +88,7 @@ func TestWorkloadPolicyProposalAddProcess(t *testing.T) {
 			},
 			expectedContainers: 1,
 			expectedAllowedPerContainer: map[string][]string{
-				"container1": {"/bin/sh", "/bin/bash"},
+				"container1": {"/bin/bash", "/bin/sh"},
 			},
 		},
 		{
@@ -102,6 +102,18 @@ func TestWorkloadPolicyProposalAddProcess(t *testing.T) {
 				"container1": {"/bin/sh"},
 			},
 		},
+		{
+			name: "keeps the executables sorted",
+			calls: []addProcessCall{
+				{"container1", "/usr/bin/sleep"},
+				{"container1", "/bin/sh"},
+				{"container1", "/usr/bin/env"},
+			},
+			expectedContainers: 1,
+			expectedAllowedPerContainer: map[string][]string{
+				"container1": {"/bin/sh", "/usr/bin/env", "/usr/bin/sleep"},
+			},
+		},
 		{
 			name: "handles multiple containers independently",
 			calls: []addProcessCall{
@@ -124,8 +136,72 @@ func TestWorkloadPolicyProposalAddProcess(t *testing.T) {
 			}
 			require.Len(t, p.Spec.RulesByContainer, tc.expectedContainers)
 			for container, executables := range tc.expectedAllowedPerContainer {
-				require.ElementsMatch(t, executables, p.Spec.RulesByContainer[container].Executables.Allowed)
+				require.Equal(t, executables, p.Spec.RulesByContainer[container].Executables.Allowed)
+				for _, executable := range executables {
+					require.True(t, p.HasProcess(container, executable))
+				}
 			}
+			require.False(t, p.HasProcess("container1", "/bin/never-run"))
+			require.False(t, p.HasProcess("unknown", "/bin/sh"))
+		})
+	}
+}
+
+func TestDiffRulesByContainer(t *testing.T) {
+	rules := func(executables ...string) *v1alpha1.WorkloadPolicyRules {
+		return &v1alpha1.WorkloadPolicyRules{
+			Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: executables},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		proposed map[string]*v1alpha1.WorkloadPolicyRules
+		enforced map[string]*v1alpha1.WorkloadPolicyRules
+		expected map[string]v1alpha1.ContainerRulesDiff
+	}{
+		{
+			name:     "unchanged containers are not reported",
+			proposed: map[string]*v1alpha1.WorkloadPolicyRules{"app": rules("/bin/sleep", "/bin/cat")},
+			enforced: map[string]*v1alpha1.WorkloadPolicyRules{"app": rules("/bin/cat", "/bin/sleep")},
+			expected: map[string]v1alpha1.ContainerRulesDiff{},
+		},
+		{
+			name:     "additions and removals",
+			proposed: map[string]*v1alpha1.WorkloadPolicyRules{"app": rules("/bin/sleep", "/bin/ls", "/bin/cat")},
+			enforced: map[string]*v1alpha1.WorkloadPolicyRules{"app": rules("/bin/sleep", "/bin/sh")},
+			expected: map[string]v1alpha1.ContainerRulesDiff{
+				"app": {Added: []string{"/bin/cat", "/bin/ls"}, Removed: []string{"/bin/sh"}},
+			},
+		},
+		{
+			name: "containers only in one of the two",
+			proposed: map[string]*v1alpha1.WorkloadPolicyRules{
+				"app":     rules("/bin/sleep"),
+				"sidecar": rules("/bin/envoy"),
+			},
+			enforced: map[string]*v1alpha1.WorkloadPolicyRules{
+				"app":  rules("/bin/sleep"),
+				"init": rules("/bin/sh"),
+			},
+			expected: map[string]v1alpha1.ContainerRulesDiff{
+				"sidecar": {Added: []string{"/bin/envoy"}},
+				"init":    {Removed: []string{"/bin/sh"}},
+			},
+		},
+		{
+			name:     "nil rules",
+			proposed: map[string]*v1alpha1.WorkloadPolicyRules{"app": nil},
+			enforced: map[string]*v1alpha1.WorkloadPolicyRules{"app": rules("/bin/sleep")},
+			expected: map[string]v1alpha1.ContainerRulesDiff{
+				"app": {Removed: []string{"/bin/sleep"}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, v1alpha1.DiffRulesByContainer(tc.proposed, tc.enforced))
 		})
 	}
 }
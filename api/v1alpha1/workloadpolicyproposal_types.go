@@ -22,6 +22,24 @@ type WorkloadPolicyProposalSpec struct {
 	RulesByContainer map[string]*WorkloadPolicyRules `json:"rulesByContainer,omitempty"`
 }
 
+// ContainerRulesDiff describes how the proposal changes the allowed executables of a container.
+type ContainerRulesDiff struct {
+	// added contains the executables allowed by the proposal but not by the enforced policy.
+	Added []string `json:"added,omitempty"`
+	// removed contains the executables allowed by the enforced policy but not by the proposal.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// WorkloadPolicyProposalStatus defines the observed state of WorkloadPolicyProposal.
+type WorkloadPolicyProposalStatus struct {
+	// policyGeneration is the generation of the WorkloadPolicy the diff has been computed against.
+	// It is not set when no WorkloadPolicy with the same name exists.
+	PolicyGeneration int64 `json:"policyGeneration,omitempty"`
+	// diffByContainer contains, for each container with changes, the difference
+	// between the proposal and the WorkloadPolicy with the same name.
+	DiffByContainer map[string]ContainerRulesDiff `json:"diffByContainer,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:categories={rancher-security},singular="workloadpolicyproposal",path="workloadpolicyproposals",scope="Namespaced",shortName={wpp}
@@ -33,7 +51,8 @@ type WorkloadPolicyProposal struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec WorkloadPolicyProposalSpec `json:"spec,omitempty"`
+	Spec   WorkloadPolicyProposalSpec   `json:"spec,omitempty"`
+	Status WorkloadPolicyProposalStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -72,6 +91,14 @@ func (p *WorkloadPolicyProposal) IsFull() bool {
 	return p.getExecutablesLength() >= PolicyProposalMaxExecutables
 }
 
+// HasProcess returns true when the executable is already allowed for the container.
+func (p *WorkloadPolicyProposal) HasProcess(containerName string, executable string) bool {
+	return slices.Contains(allowedExecutables(p.Spec.RulesByContainer, containerName), executable)
+}
+
+// AddProcess adds the executable to the ones allowed for the container, unless it is already there.
+// The allowed executables are kept sorted, so that the proposal learned by several replicas of a workload
+// doesn't depend on the order their executions were reported in.
 func (p *WorkloadPolicyProposal) AddProcess(containerName string, executable string) {
 	if p.Spec.RulesByContainer == nil {
 		p.Spec.RulesByContainer = make(map[string]*WorkloadPolicyRules)
@@ -91,7 +118,10 @@ func (p *WorkloadPolicyProposal) AddProcess(containerName string, executable str
 		return
 	}
 
+	// the proposals learned before the executables were sorted are sorted on their next addition.
 	rules.Executables.Allowed = append(rules.Executables.Allowed, executable)
+	slices.Sort(rules.Executables.Allowed)
+	rules.Executables.Allowed = slices.Compact(rules.Executables.Allowed)
 }
 
 func (p *WorkloadPolicyProposal) AddPartialOwnerReferenceDetails(workloadKind string, workload string) {
@@ -103,6 +133,53 @@ func (p *WorkloadPolicyProposal) AddPartialOwnerReferenceDetails(workloadKind st
 	}
 }
 
+// allowedExecutables returns the executables allowed for the container, nil if the container has no rules.
+func allowedExecutables(rulesByContainer map[string]*WorkloadPolicyRules, containerName string) []string {
+	rules := rulesByContainer[containerName]
+	if rules == nil {
+		return nil
+	}
+	return rules.Executables.Allowed
+}
+
+// DiffRulesByContainer returns, for each container with changes, the executables added and removed by
+// the proposed rules compared to the enforced ones. Containers without changes are not reported.
+func DiffRulesByContainer(proposed, enforced map[string]*WorkloadPolicyRules) map[string]ContainerRulesDiff {
+	containers := make(map[string]struct{}, len(proposed)+len(enforced))
+	for containerName := range proposed {
+		containers[containerName] = struct{}{}
+	}
+	for containerName := range enforced {
+		containers[containerName] = struct{}{}
+	}
+
+	diffs := make(map[string]ContainerRulesDiff)
+	for containerName := range containers {
+		proposedExecutables := allowedExecutables(proposed, containerName)
+		enforcedExecutables := allowedExecutables(enforced, containerName)
+
+		var diff ContainerRulesDiff
+		for _, executable := range proposedExecutables {
+			if !slices.Contains(enforcedExecutables, executable) && !slices.Contains(diff.Added, executable) {
+				diff.Added = append(diff.Added, executable)
+			}
+		}
+		for _, executable := range enforcedExecutables {
+			if !slices.Contains(proposedExecutables, executable) && !slices.Contains(diff.Removed, executable) {
+				diff.Removed = append(diff.Removed, executable)
+			}
+		}
+		if len(diff.Added) == 0 && len(diff.Removed) == 0 {
+			continue
+		}
+		// keep a stable order to avoid useless status updates.
+		slices.Sort(diff.Added)
+		slices.Sort(diff.Removed)
+		diffs[containerName] = diff
+	}
+	return diffs
+}
+
 func (p *WorkloadPolicyProposalSpec) IntoWorkloadPolicySpec() WorkloadPolicySpec {
 	// enforcement mode to "monitor" by default.
 	return WorkloadPolicySpec{
@@ -8,6 +8,138 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovedCommand) DeepCopyInto(out *ApprovedCommand) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovedCommand.
+func (in *ApprovedCommand) DeepCopy() *ApprovedCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovedCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerRulesDiff) DeepCopyInto(out *ContainerRulesDiff) {
+	*out = *in
+	if in.Added != nil {
+		in, out := &in.Added, &out.Added
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Removed != nil {
+		in, out := &in.Removed, &out.Removed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRulesDiff.
+func (in *ContainerRulesDiff) DeepCopy() *ContainerRulesDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerRulesDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunImpact) DeepCopyInto(out *DryRunImpact) {
+	*out = *in
+	if in.Executables != nil {
+		in, out := &in.Executables, &out.Executables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunImpact.
+func (in *DryRunImpact) DeepCopy() *DryRunImpact {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunImpact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutableProfileReference) DeepCopyInto(out *ExecutableProfileReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutableProfileReference.
+func (in *ExecutableProfileReference) DeepCopy() *ExecutableProfileReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutableProfileReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutableViolationSummary) DeepCopyInto(out *ExecutableViolationSummary) {
+	*out = *in
+	in.FirstSeen.DeepCopyInto(&out.FirstSeen)
+	in.LastSeen.DeepCopyInto(&out.LastSeen)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutableViolationSummary.
+func (in *ExecutableViolationSummary) DeepCopy() *ExecutableViolationSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutableViolationSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageScopedExecutables) DeepCopyInto(out *ImageScopedExecutables) {
+	*out = *in
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageScopedExecutables.
+func (in *ImageScopedExecutables) DeepCopy() *ImageScopedExecutables {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageScopedExecutables)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeEnforcement) DeepCopyInto(out *NodeEnforcement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeEnforcement.
+func (in *NodeEnforcement) DeepCopy() *NodeEnforcement {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeEnforcement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeIssue) DeepCopyInto(out *NodeIssue) {
 	*out = *in
@@ -23,6 +155,22 @@ func (in *NodeIssue) DeepCopy() *NodeIssue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemporaryExecutable) DeepCopyInto(out *TemporaryExecutable) {
+	*out = *in
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemporaryExecutable.
+func (in *TemporaryExecutable) DeepCopy() *TemporaryExecutable {
+	if in == nil {
+		return nil
+	}
+	out := new(TemporaryExecutable)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ViolationRecord) DeepCopyInto(out *ViolationRecord) {
 	*out = *in
@@ -39,6 +187,30 @@ func (in *ViolationRecord) DeepCopy() *ViolationRecord {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ViolationSummary) DeepCopyInto(out *ViolationSummary) {
+	*out = *in
+	in.WindowStart.DeepCopyInto(&out.WindowStart)
+	in.WindowEnd.DeepCopyInto(&out.WindowEnd)
+	if in.TopExecutables != nil {
+		in, out := &in.TopExecutables, &out.TopExecutables
+		*out = make([]ExecutableViolationSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ViolationSummary.
+func (in *ViolationSummary) DeepCopy() *ViolationSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ViolationSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadPolicy) DeepCopyInto(out *WorkloadPolicy) {
 	*out = *in
@@ -74,6 +246,32 @@ func (in *WorkloadPolicyExecutables) DeepCopyInto(out *WorkloadPolicyExecutables
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]ExecutableProfileReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Temporary != nil {
+		in, out := &in.Temporary, &out.Temporary
+		*out = make([]TemporaryExecutable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ApprovedCommands != nil {
+		in, out := &in.ApprovedCommands, &out.ApprovedCommands
+		*out = make([]ApprovedCommand, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageScoped != nil {
+		in, out := &in.ImageScoped, &out.ImageScoped
+		*out = make([]ImageScopedExecutables, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyExecutables.
@@ -86,6 +284,36 @@ func (in *WorkloadPolicyExecutables) DeepCopy() *WorkloadPolicyExecutables {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadPolicyFiles) DeepCopyInto(out *WorkloadPolicyFiles) {
+	*out = *in
+	if in.AllowedRead != nil {
+		in, out := &in.AllowedRead, &out.AllowedRead
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedWrite != nil {
+		in, out := &in.AllowedWrite, &out.AllowedWrite
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedWrite != nil {
+		in, out := &in.DeniedWrite, &out.DeniedWrite
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyFiles.
+func (in *WorkloadPolicyFiles) DeepCopy() *WorkloadPolicyFiles {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadPolicyFiles)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadPolicyList) DeepCopyInto(out *WorkloadPolicyList) {
 	*out = *in
@@ -118,12 +346,33 @@ func (in *WorkloadPolicyList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadPolicyNetwork) DeepCopyInto(out *WorkloadPolicyNetwork) {
+	*out = *in
+	if in.AllowedEgress != nil {
+		in, out := &in.AllowedEgress, &out.AllowedEgress
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyNetwork.
+func (in *WorkloadPolicyNetwork) DeepCopy() *WorkloadPolicyNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadPolicyNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadPolicyProposal) DeepCopyInto(out *WorkloadPolicyProposal) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyProposal.
@@ -207,10 +456,39 @@ func (in *WorkloadPolicyProposalSpec) DeepCopy() *WorkloadPolicyProposalSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadPolicyProposalStatus) DeepCopyInto(out *WorkloadPolicyProposalStatus) {
+	*out = *in
+	if in.DiffByContainer != nil {
+		in, out := &in.DiffByContainer, &out.DiffByContainer
+		*out = make(map[string]ContainerRulesDiff, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyProposalStatus.
+func (in *WorkloadPolicyProposalStatus) DeepCopy() *WorkloadPolicyProposalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadPolicyProposalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadPolicyRules) DeepCopyInto(out *WorkloadPolicyRules) {
 	*out = *in
 	in.Executables.DeepCopyInto(&out.Executables)
+	in.Files.DeepCopyInto(&out.Files)
+	in.Network.DeepCopyInto(&out.Network)
+	if in.ListeningPorts != nil {
+		in, out := &in.ListeningPorts, &out.ListeningPorts
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyRules.
@@ -242,6 +520,16 @@ func (in *WorkloadPolicySpec) DeepCopyInto(out *WorkloadPolicySpec) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.AllowedFilesystems != nil {
+		in, out := &in.AllowedFilesystems, &out.AllowedFilesystems
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedServiceAccounts != nil {
+		in, out := &in.AllowedServiceAccounts, &out.AllowedServiceAccounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicySpec.
@@ -269,6 +557,20 @@ func (in *WorkloadPolicyStatus) DeepCopyInto(out *WorkloadPolicyStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.EnforcedBy != nil {
+		in, out := &in.EnforcedBy, &out.EnforcedBy
+		*out = make(map[string]NodeEnforcement, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EnforcerVersions != nil {
+		in, out := &in.EnforcerVersions, &out.EnforcerVersions
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Violations != nil {
 		in, out := &in.Violations, &out.Violations
 		*out = make([]ViolationRecord, len(*in))
@@ -276,6 +578,38 @@ func (in *WorkloadPolicyStatus) DeepCopyInto(out *WorkloadPolicyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastViolationTime != nil {
+		in, out := &in.LastViolationTime, &out.LastViolationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ViolationSummary != nil {
+		in, out := &in.ViolationSummary, &out.ViolationSummary
+		*out = new(ViolationSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UnobservedExecutables != nil {
+		in, out := &in.UnobservedExecutables, &out.UnobservedExecutables
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.DryRunImpact != nil {
+		in, out := &in.DryRunImpact, &out.DryRunImpact
+		*out = make(map[string]DryRunImpact, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyStatus.
@@ -5,16 +5,61 @@
 
 package v1alpha1
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ApprovedCommand) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ApprovedCommand"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ContainerRulesDiff) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ContainerRulesDiff"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in DryRunImpact) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.DryRunImpact"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ExecutableProfileReference) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableProfileReference"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ExecutableViolationSummary) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableViolationSummary"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ImageScopedExecutables) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ImageScopedExecutables"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in NodeEnforcement) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.NodeEnforcement"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in NodeIssue) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.NodeIssue"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in TemporaryExecutable) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.TemporaryExecutable"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in ViolationRecord) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ViolationRecord"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ViolationSummary) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ViolationSummary"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in WorkloadPolicy) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicy"
@@ -25,11 +70,21 @@ func (in WorkloadPolicyExecutables) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyExecutables"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in WorkloadPolicyFiles) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyFiles"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in WorkloadPolicyList) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyList"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in WorkloadPolicyNetwork) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyNetwork"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in WorkloadPolicyProposal) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposal"
@@ -45,6 +100,11 @@ func (in WorkloadPolicyProposalSpec) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalSpec"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in WorkloadPolicyProposalStatus) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalStatus"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in WorkloadPolicyRules) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyRules"
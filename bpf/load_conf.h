@@ -6,7 +6,8 @@ struct load_conf {
 	__u32 cgrpv1_subsys_idx; /* tracked cgroupv1 subsystem state index*/
 	__u8 debug_mode;         /* Enable debug mode */
 	__u8 learning_enabled;   /* Enable learning */
-	__u8 pad[2];
+	__u8 observe_only;       /* Never block an exec, whatever the mode of the policy */
+	__u8 pad[1];
 };  // All fields aligned so no 'packed' attribute.
 
 const volatile struct load_conf load_time_config = {0};
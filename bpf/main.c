@@ -4,6 +4,7 @@
 #include <bpf/bpf_helpers.h>
 #include <bpf/bpf_core_read.h>
 #include <bpf/bpf_tracing.h>
+#include <bpf/bpf_endian.h>
 #include "load_conf.h"
 #include "debug.h"
 #include "helpers.h"
@@ -363,11 +364,31 @@ struct {
 	__uint(max_entries, BUF_DIM);
 } ringbuf_execve SEC(".maps");
 
+#define VIOLATION_REASON_EXEC_NOT_ALLOWED 1
+#define VIOLATION_REASON_SUID_EXEC 2
+#define VIOLATION_REASON_SCRIPT_EXEC 3
+#define VIOLATION_REASON_EXEC_LIMIT 4
+#define VIOLATION_REASON_UNLINKED_EXEC 5
+#define VIOLATION_REASON_COMMAND_NOT_APPROVED 6
+#define VIOLATION_REASON_FS_NOT_ALLOWED 7
+#define VIOLATION_REASON_FILE_READ_NOT_ALLOWED 8
+#define VIOLATION_REASON_FILE_WRITE_NOT_ALLOWED 9
+#define VIOLATION_REASON_FILE_WRITE_DENIED 10
+#define VIOLATION_REASON_EGRESS_NOT_ALLOWED 11
+
+// When changing the header fields remember to update the output size in the `bpf_ringbuf_output`
+// calls and the `bpfEventHeader` in userspace.
 struct process_evt {
 	u64 cg_tracker_id;
 	u16 path_len;
 	u8 mode;  // enforce or protect, todo!: this information is not needed by the learning event so
 	          // we can also decide to split the event structures
+	u8 reason;      // VIOLATION_REASON_*, 0 for learning events and traced allowed execs
+	u16 file_mode;  // mode bits of the executed file, 0 for learning events
+	u16 args_len;   // length of the arguments following the path, see append_command_args,
+	                // or of the destination for VIOLATION_REASON_EGRESS_NOT_ALLOWED, see struct egress_dest
+	u32 tgid;       // pid of the process calling exec, in the initial pid namespace
+	u32 fs_magic;   // magic of the filesystem of the executed file, only for VIOLATION_REASON_FS_NOT_ALLOWED
 	// MAX_PATH_LEN for the final path +
 	// MAX_PATH_LEN for storing the progressive path +
 	// MAX_PATH_LEN of empty space for padding when we do the string map lookups
@@ -386,9 +407,7 @@ struct {
 // Force emitting struct event into the ELF.
 const struct process_evt *unused_process_evt __attribute__((unused));
 
-static __always_inline u32 populate_evt_with_path(struct process_evt *evt,
-                                                  struct linux_binprm *bprm) {
-	struct file *file = bprm->file;
+static __always_inline u32 populate_evt_with_file_path(struct process_evt *evt, struct file *file) {
 	if(file == NULL) {
 		emit_log_event(LOG_MISSING_FILE_STRUCT);
 		return 0;
@@ -408,6 +427,11 @@ static __always_inline u32 populate_evt_with_path(struct process_evt *evt,
 	return current_offset;
 }
 
+static __always_inline u32 populate_evt_with_path(struct process_evt *evt,
+                                                  struct linux_binprm *bprm) {
+	return populate_evt_with_file_path(evt, bprm->file);
+}
+
 static __always_inline struct process_evt *get_process_evt() {
 	int zero = 0;
 	struct process_evt *evt =
@@ -416,6 +440,10 @@ static __always_inline struct process_evt *get_process_evt() {
 		emit_log_event_1(LOG_FAIL_TO_LOOKUP_EVT_MAP, (u32)(bpf_get_smp_processor_id()));
 		return NULL;
 	}
+	// the storage is reused by every event, only the violations of approved commands carry arguments
+	// and only the violations of the allowed filesystems carry a filesystem magic.
+	evt->args_len = 0;
+	evt->fs_magic = 0;
 	return evt;
 }
 
@@ -443,8 +471,597 @@ struct {
 
 #define POLICY_MODE_MONITOR 1
 #define POLICY_MODE_PROTECT 2
+// Never blocks, like monitor, but the allowed execs are reported too.
+#define POLICY_MODE_AUDIT 3
 #define EPERM 1
 
+// Returns the mode actually enforced for a policy in `mode`: in observe-only mode every policy
+// in protect mode is enforced in monitor mode, so no exec is ever blocked.
+static __always_inline __u8 enforced_mode(__u8 mode) {
+	if(load_time_config.observe_only && mode == POLICY_MODE_PROTECT) {
+		return POLICY_MODE_MONITOR;
+	}
+	return mode;
+}
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, POLICY_MAP_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, __u64);  /* Key is the policy id */
+	__type(value, __u8); /* POLICY_FLAG_* bitmask */
+} policy_flags_map SEC(".maps");
+
+#define TRACE_CGROUPS_MAX_ENTRIES 1024
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, TRACE_CGROUPS_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, __u64);  /* Key is the tracker cgroup id */
+	__type(value, __u8); /* unused, the presence of the key enables the trace */
+} trace_cgroups_map SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, POLICY_MAP_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, __u64);   /* Key is the policy id */
+	__type(value, __u32); /* maximum number of distinct executables of each cgroup */
+} policy_exec_limit_map SEC(".maps");
+
+// Keep in sync with `maxPolicyFsTypes` in userspace.
+#define POLICY_FS_TYPES_MAX 8
+
+struct policy_fs_types {
+	__u32 count;
+	__u32 magics[POLICY_FS_TYPES_MAX];  // superblock magics of the allowed filesystems
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, POLICY_MAP_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, __u64); /* Key is the policy id */
+	__type(value, struct policy_fs_types);
+} policy_fs_types_map SEC(".maps");
+
+// The allowed prefixes of the policies, e.g. `/opt/app/bin/` for the entry `/opt/app/bin/*`.
+// The keys of an LPM trie are limited to 256 bytes of data, the policy id included, so the prefixes
+// are limited to POLICY_PREFIX_MAX_LEN bytes. Keep in sync with `MaxPrefixLength` in userspace.
+#define POLICY_PREFIX_MAX_LEN 248
+#define POLICY_PREFIX_MAX_ENTRIES 65536
+
+struct policy_prefix_key {
+	__u32 prefixlen;  // in bits, of the policy id followed by the prefix
+	__u64 policy_id;
+	__u8 path[POLICY_PREFIX_MAX_LEN];
+} __attribute__((packed));
+
+struct {
+	__uint(type, BPF_MAP_TYPE_LPM_TRIE);
+	__uint(max_entries, POLICY_PREFIX_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, struct policy_prefix_key);
+	__type(value, __u8); /* POLICY_VALUE_* */
+} policy_prefix_map SEC(".maps");
+
+// The file rules of the policies, with the same keys as the allowed prefixes. An exact path is stored
+// with its NUL terminator, so that it matches only itself, and the entry `/etc/*` is stored as `/etc/`.
+// Each entry carries the POLICY_FILE_* bits of the less specific entries matching it too, so the longest
+// match is enough. Keep in sync with `policyFileKey` in userspace.
+#define POLICY_FILE_MAX_ENTRIES 65536
+
+#define POLICY_FILE_READ_ALLOWED (1 << 0)
+#define POLICY_FILE_WRITE_ALLOWED (1 << 1)
+#define POLICY_FILE_WRITE_DENIED (1 << 2)
+
+struct {
+	__uint(type, BPF_MAP_TYPE_LPM_TRIE);
+	__uint(max_entries, POLICY_FILE_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, struct policy_prefix_key);
+	__type(value, __u8); /* POLICY_FILE_* bitmask */
+} policy_file_map SEC(".maps");
+
+// The allowed egress destinations of the policies. The key is the policy id, the destination port and the
+// address, an IPv4 address being stored as an IPv4-mapped IPv6 address, so that a CIDR is a prefix of the key.
+// Keep in sync with `policyEgressKey` in userspace.
+#define POLICY_EGRESS_MAX_ENTRIES 65536
+
+struct policy_egress_key {
+	__u32 prefixlen;  // in bits, of the policy id, the port and the address
+	__u64 policy_id;
+	__u16 port;  // host byte order
+	__u8 addr[16];
+} __attribute__((packed));
+
+struct {
+	__uint(type, BPF_MAP_TYPE_LPM_TRIE);
+	__uint(max_entries, POLICY_EGRESS_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, struct policy_egress_key);
+	__type(value, __u8); /* unused, the presence of the key allows the destination */
+} policy_egress_map SEC(".maps");
+
+// The key is too large for the stack, it is built in a per-cpu storage.
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__uint(max_entries, 1);
+	__type(key, int);
+	__type(value, struct policy_prefix_key);
+} prefix_key_storage_map SEC(".maps");
+
+// The executables denied in every policy, whatever its allow list: the global deny list is checked before the
+// exact and prefix matches, so an allowed prefix cannot allow a denied executable. The key is the kind of the
+// entry followed by the full path or the name of the executable, with its NUL terminator so that it matches only
+// itself. The entries are stored folded to lowercase too, with GLOBAL_DENY_FOLDED, for the case insensitive
+// policies. Keep in sync with `globalDenyKey` in userspace.
+#define GLOBAL_DENY_MAX_LEN 252
+#define GLOBAL_DENY_MAX_ENTRIES 16384
+
+#define GLOBAL_DENY_PATH 0
+#define GLOBAL_DENY_NAME 1
+#define GLOBAL_DENY_FOLDED (1 << 1)
+
+struct global_deny_key {
+	__u32 prefixlen;  // in bits, of the kind and the value
+	__u32 kind;       // GLOBAL_DENY_PATH or GLOBAL_DENY_NAME, with GLOBAL_DENY_FOLDED
+	__u8 value[GLOBAL_DENY_MAX_LEN];
+} __attribute__((packed));
+
+struct {
+	__uint(type, BPF_MAP_TYPE_LPM_TRIE);
+	__uint(max_entries, GLOBAL_DENY_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, struct global_deny_key);
+	__type(value, __u8); /* unused, the presence of the key denies the executable */
+} global_deny_map SEC(".maps");
+
+// The key is too large for the stack, it is built in a per-cpu storage.
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__uint(max_entries, 1);
+	__type(key, int);
+	__type(value, struct global_deny_key);
+} global_deny_key_storage_map SEC(".maps");
+
+struct distinct_exec_key {
+	__u64 cg_tracker_id;
+	__u64 path_hash;
+};
+
+// The executables already run by each cgroup. The entries of the removed cgroups are never
+// deleted explicitly, the LRU evicts them.
+#define DISTINCT_EXECS_MAX_ENTRIES 262144
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__uint(max_entries, DISTINCT_EXECS_MAX_ENTRIES);
+	__type(key, struct distinct_exec_key);
+	__type(value, __u8); /* unused */
+} distinct_execs_map SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, CGROUP_TO_POLICY_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, __u64);   /* Key is the tracker cgroup id */
+	__type(value, __u32); /* number of distinct executables run by the cgroup */
+} distinct_exec_count_map SEC(".maps");
+
+#define POLICY_FLAG_BLOCK_SUID_EXEC (1 << 0)
+#define POLICY_FLAG_CASE_INSENSITIVE (1 << 1)
+#define POLICY_FLAG_BLOCK_SCRIPTS (1 << 2)
+#define POLICY_FLAG_BLOCK_UNLINKED_EXEC (1 << 3)
+#define POLICY_FLAG_RESTRICT_READ (1 << 4)
+#define POLICY_FLAG_RESTRICT_WRITE (1 << 5)
+#define POLICY_FLAG_DENY_WRITE (1 << 6)
+#define POLICY_FLAGS_FILES (POLICY_FLAG_RESTRICT_READ | POLICY_FLAG_RESTRICT_WRITE | POLICY_FLAG_DENY_WRITE)
+#define POLICY_FLAG_RESTRICT_EGRESS (1 << 7)
+
+// Values of the entries of the policy string maps.
+#define POLICY_VALUE_ALLOWED 1
+// The allowed executable has been run at least once, the userspace reports the ones never run.
+#define POLICY_VALUE_SEEN 2
+
+#ifndef S_ISUID
+#define S_ISUID 0004000
+#endif
+#ifndef S_ISGID
+#define S_ISGID 0002000
+#endif
+#ifndef S_IXGRP
+#define S_IXGRP 0000010
+#endif
+
+// A setgid bit without group execute permission means mandatory locking, not setgid execution.
+static __always_inline bool is_setid_exec(u16 file_mode) {
+	return (file_mode & S_ISUID) || ((file_mode & S_ISGID) && (file_mode & S_IXGRP));
+}
+
+// The kernel runs a file through the script loader when it starts with `#!`, see fs/binfmt_script.c.
+// `bprm->buf` holds the first bytes of the file only once `prepare_binprm` has been called.
+static __always_inline bool is_script(struct linux_binprm *bprm) {
+	return bprm->buf[0] == '#' && bprm->buf[1] == '!';
+}
+
+// Returns true if the file being executed has no name left in the filesystem: a deleted binary still
+// reachable through an open file descriptor, or an anonymous file created with memfd_create(2).
+// The dentry of a memfd file is a pseudo dentry which is never hashed, so it doesn't look unlinked:
+// we rely on the link count of the inode, which the kernel clears for these files.
+static __always_inline bool is_unlinked_exec(struct linux_binprm *bprm) {
+	struct file *file = BPF_CORE_READ(bprm, file);
+	if(file == NULL) {
+		return false;
+	}
+	if(BPF_CORE_READ(file, f_inode, __i_nlink) == 0) {
+		return true;
+	}
+	return d_unlinked(BPF_CORE_READ(file, f_path.dentry));
+}
+
+// Returns true if the policy restricts the executions to some filesystem types and the file being executed
+// is stored on another one. The magic of its filesystem is then recorded in the event.
+static __always_inline bool fs_not_allowed(struct process_evt *evt,
+                                           struct linux_binprm *bprm,
+                                           __u64 *policy_id) {
+	struct policy_fs_types *allowed = bpf_map_lookup_elem(&policy_fs_types_map, policy_id);
+	if(!allowed) {
+		return false;
+	}
+	__u32 magic = (__u32)BPF_CORE_READ(bprm, file, f_inode, i_sb, s_magic);
+	for(int i = 0; i < POLICY_FS_TYPES_MAX; i++) {
+		if(i >= allowed->count) {
+			break;
+		}
+		if(allowed->magics[i] == magic) {
+			return false;
+		}
+	}
+	evt->fs_magic = magic;
+	return true;
+}
+
+// Looks up the path stored at `offset` in the allowed prefixes of the policy. Only the first
+// POLICY_PREFIX_MAX_LEN bytes of the path are compared, which is enough since no prefix is longer.
+static __always_inline __u8 *match_policy_prefix(struct process_evt *evt, u32 offset, __u64 *policy_id) {
+	int zero = 0;
+	struct policy_prefix_key *key = bpf_map_lookup_elem(&prefix_key_storage_map, &zero);
+	if(!key) {
+		return NULL;
+	}
+	u32 len = evt->path_len;
+	if(len > POLICY_PREFIX_MAX_LEN) {
+		len = POLICY_PREFIX_MAX_LEN;
+	}
+	// the bytes following the path are not compared, the prefix length stops before them.
+	if(bpf_probe_read_kernel(key->path, POLICY_PREFIX_MAX_LEN, &evt->path[SAFE_PATH_ACCESS(offset)]) != 0) {
+		return NULL;
+	}
+	key->policy_id = *policy_id;
+	key->prefixlen = (sizeof(key->policy_id) + len) * 8;
+	return bpf_map_lookup_elem(&policy_prefix_map, key);
+}
+
+// Looks up `len` bytes stored at `offset`, followed by their NUL terminator, in the global deny list.
+static __always_inline bool lookup_global_deny(struct process_evt *evt, u32 offset, u32 len, __u32 kind) {
+	int zero = 0;
+	struct global_deny_key *key = bpf_map_lookup_elem(&global_deny_key_storage_map, &zero);
+	if(!key) {
+		return false;
+	}
+	// no entry is longer than the key, so a longer value cannot match any entry: truncating it is enough.
+	len += 1;
+	if(len > GLOBAL_DENY_MAX_LEN) {
+		len = GLOBAL_DENY_MAX_LEN;
+	}
+	if(bpf_probe_read_kernel(key->value, GLOBAL_DENY_MAX_LEN, &evt->path[SAFE_PATH_ACCESS(offset)]) != 0) {
+		return false;
+	}
+	key->kind = kind;
+	key->prefixlen = (sizeof(key->kind) + len) * 8;
+	return bpf_map_lookup_elem(&global_deny_map, key) != NULL;
+}
+
+// Returns true if the executable whose path is stored at `offset` is in the global deny list, by its path or by
+// its name. The name is the last component of the path, as long as the name of the dentry of the file.
+// With `folded` the path has been folded to lowercase and is compared with the folded entries.
+static __always_inline bool globally_denied(struct process_evt *evt,
+                                            u32 offset,
+                                            struct linux_binprm *bprm,
+                                            bool folded) {
+	__u32 folded_kind = folded ? GLOBAL_DENY_FOLDED : 0;
+	if(lookup_global_deny(evt, offset, evt->path_len, GLOBAL_DENY_PATH | folded_kind)) {
+		return true;
+	}
+	u32 name_len = BPF_CORE_READ(bprm, file, f_path.dentry, d_name.len);
+	if(name_len == 0 || name_len >= evt->path_len) {
+		return false;
+	}
+	return lookup_global_deny(evt, offset + evt->path_len - name_len, name_len, GLOBAL_DENY_NAME | folded_kind);
+}
+
+// Looks up the path stored at `offset` in the file rules of the policy and returns their POLICY_FILE_* bits,
+// 0 when no entry matches. The NUL terminator of the path is part of the key, so that the exact entries
+// match too. Only the first POLICY_PREFIX_MAX_LEN bytes of the path are compared.
+static __always_inline __u8 match_policy_file(struct process_evt *evt, u32 offset, __u64 *policy_id) {
+	int zero = 0;
+	struct policy_prefix_key *key = bpf_map_lookup_elem(&prefix_key_storage_map, &zero);
+	if(!key) {
+		return 0;
+	}
+	u32 len = evt->path_len + 1;
+	if(len > POLICY_PREFIX_MAX_LEN) {
+		len = POLICY_PREFIX_MAX_LEN;
+	}
+	if(bpf_probe_read_kernel(key->path, POLICY_PREFIX_MAX_LEN, &evt->path[SAFE_PATH_ACCESS(offset)]) != 0) {
+		return 0;
+	}
+	key->policy_id = *policy_id;
+	key->prefixlen = (sizeof(key->policy_id) + len) * 8;
+	__u8 *bits = bpf_map_lookup_elem(&policy_file_map, key);
+	return bits ? *bits : 0;
+}
+
+// Copies the resolved path stored at `offset` into the first segment of the buffer.
+// please note: in the first segment of the path we will already have the path written by
+// the previous program execution, what we are doing here is to overwrite the path with the new
+// content. Example:
+// - previous: `/usr/bin/nginx-controller\0`
+// - new one:  `/usr/bin/cat\0x-controller\0`
+// we need the +1 because we want to copy also the `\0` terminator
+static __always_inline long copy_path_to_first_segment(struct process_evt *evt, u32 offset) {
+	return bpf_probe_read_kernel(evt->path,
+	                             SAFE_PATH_LEN(evt->path_len + 1),
+	                             &evt->path[SAFE_PATH_ACCESS(offset)]);
+}
+
+#define FNV_OFFSET_BASIS 14695981039346656037ULL
+#define FNV_PRIME 1099511628211ULL
+
+// FNV-1a hash of the path stored at `offset`.
+static __always_inline u64 hash_path(struct process_evt *evt, u32 offset) {
+	u64 hash = FNV_OFFSET_BASIS;
+	for(int i = 0; i < MAX_PATH_LEN; i++) {
+		if(i >= evt->path_len) {
+			break;
+		}
+		hash ^= (u8)evt->path[SAFE_PATH_ACCESS(offset + i)];
+		hash *= FNV_PRIME;
+	}
+	return hash;
+}
+
+// Returns true if the executable stored at `offset` is a new distinct executable of the cgroup beyond
+// the limit of its policy. The executables are recorded so that each of them is counted only once,
+// except the ones blocked in protect mode: every attempt to run them is reported.
+static __always_inline bool exceeds_exec_limit(struct process_evt *evt,
+                                               u32 offset,
+                                               __u64 cg_tracker_id,
+                                               __u64 *policy_id) {
+	__u32 *limit = bpf_map_lookup_elem(&policy_exec_limit_map, policy_id);
+	if(!limit) {
+		return false;
+	}
+
+	struct distinct_exec_key key = {
+	        .cg_tracker_id = cg_tracker_id,
+	        .path_hash = hash_path(evt, offset),
+	};
+	if(bpf_map_lookup_elem(&distinct_execs_map, &key)) {
+		return false;
+	}
+
+	__u32 *count = bpf_map_lookup_elem(&distinct_exec_count_map, &cg_tracker_id);
+	if(!count) {
+		__u32 zero = 0;
+		bpf_map_update_elem(&distinct_exec_count_map, &cg_tracker_id, &zero, BPF_NOEXIST);
+		count = bpf_map_lookup_elem(&distinct_exec_count_map, &cg_tracker_id);
+		if(!count) {
+			return false;
+		}
+	}
+
+	bool exceeded = *count >= *limit;
+	__u8 *mode = bpf_map_lookup_elem(&policy_mode_map, policy_id);
+	if(exceeded && mode && enforced_mode(*mode) == POLICY_MODE_PROTECT) {
+		return true;
+	}
+	__u8 seen = 1;
+	if(bpf_map_update_elem(&distinct_execs_map, &key, &seen, BPF_NOEXIST) == 0) {
+		__sync_fetch_and_add(count, 1);
+	}
+	return exceeded;
+}
+
+/////////////////////////
+// Approved commands
+/////////////////////////
+
+// Only the commands with at most MAX_CMD_ARGS arguments of at most MAX_CMD_ARG_LEN bytes each can be
+// approved, the userspace rejects the longer ones.
+#define MAX_CMD_ARGS 32
+#define MAX_CMD_ARG_LEN 256
+#define CMD_ARG_BUF_LEN 512
+
+// The LSM hooks only see the arguments once copied into the memory of the new program, which is not the
+// current mm yet, so the user pointer to argv is recorded at the entry of the exec syscalls, by pid_tgid.
+// The approved commands check is best effort: see hash_command_args.
+#define EXEC_ARGV_MAX_ENTRIES 16384
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__uint(max_entries, EXEC_ARGV_MAX_ENTRIES);
+	__type(key, __u64);   /* pid_tgid of the task calling exec */
+	__type(value, __u64); /* user pointer to argv */
+} exec_argv_map SEC(".maps");
+
+SEC("tracepoint/syscalls/sys_enter_execve")
+int record_execve_argv(struct trace_event_raw_sys_enter *ctx) {
+	__u64 pid_tgid = bpf_get_current_pid_tgid();
+	__u64 argv = ctx->args[1];
+	bpf_map_update_elem(&exec_argv_map, &pid_tgid, &argv, BPF_ANY);
+	return 0;
+}
+
+SEC("tracepoint/syscalls/sys_enter_execveat")
+int record_execveat_argv(struct trace_event_raw_sys_enter *ctx) {
+	__u64 pid_tgid = bpf_get_current_pid_tgid();
+	__u64 argv = ctx->args[2];
+	bpf_map_update_elem(&exec_argv_map, &pid_tgid, &argv, BPF_ANY);
+	return 0;
+}
+
+struct policy_cmd_key {
+	__u64 policy_id;
+	__u64 hash;  // hash of the path (POLICY_CMD_RESTRICTED) or of the whole command (POLICY_CMD_APPROVED)
+};
+
+// The executable can only run the approved commands of the policy.
+#define POLICY_CMD_RESTRICTED (1 << 0)
+// The command is approved.
+#define POLICY_CMD_APPROVED (1 << 1)
+
+#define POLICY_CMD_MAX_ENTRIES 65536
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, POLICY_CMD_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, struct policy_cmd_key);
+	__type(value, __u8); /* POLICY_CMD_* bitmask */
+} policy_cmd_map SEC(".maps");
+
+struct cmd_arg_buf {
+	char arg[CMD_ARG_BUF_LEN];
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__uint(max_entries, 1);
+	__type(key, int);
+	__type(value, struct cmd_arg_buf);
+} cmd_arg_storage_map SEC(".maps");
+
+// Reads the user pointer to the argument `i` of the exec in progress.
+static __always_inline const char *exec_arg(__u64 argv, int i) {
+	const char *arg = NULL;
+	if(bpf_probe_read_user(&arg, sizeof(arg), (void *)(argv + i * sizeof(arg))) != 0) {
+		return NULL;
+	}
+	return arg;
+}
+
+// Extends `hash`, the hash of the executed path, with the arguments following argv[0]: each argument is
+// hashed as a NUL byte followed by its bytes. argv[0] is left out since the caller can set it freely.
+// Returns 0 when the command cannot be hashed, e.g. it has too many or too long arguments.
+// The arguments are read again from the memory of the calling process, not from the copy the new program
+// runs with: another thread of the caller can rewrite them after the kernel copied them, so a command that
+// is not approved can pass the check. It catches the unexpected invocations, it doesn't contain a process
+// that is already compromised.
+static __always_inline u64 hash_command_args(u64 hash, struct linux_binprm *bprm) {
+	__u64 pid_tgid = bpf_get_current_pid_tgid();
+	__u64 *argv = bpf_map_lookup_elem(&exec_argv_map, &pid_tgid);
+	if(!argv) {
+		return 0;
+	}
+	int argc = BPF_CORE_READ(bprm, argc);
+	if(argc > MAX_CMD_ARGS + 1) {
+		return 0;
+	}
+	int zero = 0;
+	struct cmd_arg_buf *buf = bpf_map_lookup_elem(&cmd_arg_storage_map, &zero);
+	if(!buf) {
+		return 0;
+	}
+	for(int i = 1; i < MAX_CMD_ARGS + 1; i++) {
+		if(i >= argc) {
+			break;
+		}
+		const char *arg = exec_arg(*argv, i);
+		if(!arg) {
+			return 0;
+		}
+		long len = bpf_probe_read_user_str(buf->arg, sizeof(buf->arg), arg);
+		// len includes the NUL terminator.
+		if(len <= 0 || len - 1 > MAX_CMD_ARG_LEN) {
+			return 0;
+		}
+		hash *= FNV_PRIME;  // the NUL separator: xor with 0 leaves the hash unchanged
+		for(int j = 0; j < MAX_CMD_ARG_LEN; j++) {
+			if(j >= len - 1) {
+				break;
+			}
+			hash ^= (u8)buf->arg[j & (CMD_ARG_BUF_LEN - 1)];
+			hash *= FNV_PRIME;
+		}
+	}
+	return hash;
+}
+
+// Returns true if the policy restricts the executable stored at `offset` to its approved commands
+// and the command line being executed is not one of them.
+static __always_inline bool command_not_approved(struct process_evt *evt,
+                                                 u32 offset,
+                                                 struct linux_binprm *bprm,
+                                                 __u64 *policy_id) {
+	struct policy_cmd_key key = {
+	        .policy_id = *policy_id,
+	        .hash = hash_path(evt, offset),
+	};
+	__u8 *value = bpf_map_lookup_elem(&policy_cmd_map, &key);
+	if(!value || !(*value & POLICY_CMD_RESTRICTED)) {
+		return false;
+	}
+	key.hash = hash_command_args(key.hash, bprm);
+	if(key.hash == 0) {
+		return true;
+	}
+	value = bpf_map_lookup_elem(&policy_cmd_map, &key);
+	return !value || !(*value & POLICY_CMD_APPROVED);
+}
+
+// Appends the arguments following argv[0] to the path in the first segment of the buffer, each one
+// followed by its NUL terminator, so that the violation reports the attempted command. The arguments
+// longer than MAX_CMD_ARG_LEN are truncated, the ones that don't fit in the segment are left out.
+static __always_inline void append_command_args(struct process_evt *evt, struct linux_binprm *bprm) {
+	__u64 pid_tgid = bpf_get_current_pid_tgid();
+	__u64 *argv = bpf_map_lookup_elem(&exec_argv_map, &pid_tgid);
+	if(!argv) {
+		return;
+	}
+	int argc = BPF_CORE_READ(bprm, argc);
+	u32 start = evt->path_len + 1;
+	u32 off = start;
+	for(int i = 1; i < MAX_CMD_ARGS + 1; i++) {
+		if(i >= argc || off > MAX_PATH_LEN - CMD_ARG_BUF_LEN) {
+			break;
+		}
+		const char *arg = exec_arg(*argv, i);
+		if(!arg) {
+			break;
+		}
+		long len = bpf_probe_read_user_str(&evt->path[SAFE_PATH_LEN(off)], MAX_CMD_ARG_LEN + 1, arg);
+		if(len <= 0) {
+			break;
+		}
+		off += len;
+	}
+	evt->args_len = off - start;
+}
+
+// Folds the ASCII uppercase letters of the path stored at `offset` to lowercase.
+// Only ASCII is folded: userspace applies the very same conversion to the allowed executables.
+static __always_inline void lowercase_path(struct process_evt *evt, u32 offset) {
+	for(int i = 0; i < MAX_PATH_LEN; i++) {
+		if(i >= evt->path_len) {
+			break;
+		}
+		u32 idx = SAFE_PATH_ACCESS(offset + i);
+		char c = evt->path[idx];
+		if(c >= 'A' && c <= 'Z') {
+			evt->path[idx] = c + ('a' - 'A');
+		}
+	}
+}
+
 static __always_inline u16 string_padded_len(u16 len) {
 	u16 padded_len = len;
 
@@ -494,6 +1111,32 @@ static __always_inline int string_map_index(u16 padded_len) {
 	}
 }
 
+// Reports the exec of a file whose path cannot be resolved under a policy blocking the unlinked files,
+// and returns the verdict of the policy.
+static __always_inline int report_unresolved_exec(struct process_evt *evt,
+                                                  struct linux_binprm *bprm,
+                                                  __u64 *policy_id) {
+	__u8 *mode = bpf_map_lookup_elem(&policy_mode_map, policy_id);
+	if(!mode) {
+		emit_log_event_1(LOG_POLICY_MODE_MISSING, *policy_id);
+		return 0;
+	}
+	evt->mode = enforced_mode(*mode);
+	evt->reason = VIOLATION_REASON_UNLINKED_EXEC;
+	evt->file_mode = BPF_CORE_READ(bprm, file, f_inode, i_mode);
+	evt->path_len = 0;
+	evt->path[0] = '\0';
+
+	if(bpf_ringbuf_output(&ringbuf_monitoring, evt, 32, 0) != 0) {
+		emit_log_event_2(LOG_DROP_VIOLATION, *policy_id, evt->mode);
+	}
+
+	if(evt->mode != POLICY_MODE_PROTECT) {
+		return 0;
+	}
+	return -EPERM;
+}
+
 SEC("fmod_ret/security_bprm_creds_for_exec")
 int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 	__u64 cg_tracker_id = get_tracker_id_from_curr_task();
@@ -524,6 +1167,9 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 		}
 		levt->cg_tracker_id = cg_tracker_id;
 		levt->mode = 0;
+		levt->reason = 0;
+		levt->file_mode = 0;
+		levt->tgid = bpf_get_current_pid_tgid() >> 32;
 
 		u32 loffset = populate_evt_with_path(levt, bprm);
 		if(loffset == 0) {
@@ -543,7 +1189,7 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 		           levt->path,
 		           levt->cg_tracker_id);
 
-		lerr = bpf_ringbuf_output(&ringbuf_execve, levt, 19 + SAFE_PATH_LEN(levt->path_len), 0);
+		lerr = bpf_ringbuf_output(&ringbuf_execve, levt, 32 + SAFE_PATH_LEN(levt->path_len), 0);
 		if(lerr != 0) {
 			emit_log_event(LOG_DROP_EXEC_EVENT);
 		}
@@ -556,9 +1202,18 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 	}
 
 	evt->cg_tracker_id = cg_tracker_id;
+	evt->tgid = bpf_get_current_pid_tgid() >> 32;
+
+	__u8 *flags = bpf_map_lookup_elem(&policy_flags_map, policy_id);
+	bool block_unlinked = flags && (*flags & POLICY_FLAG_BLOCK_UNLINKED_EXEC);
 
 	u32 current_offset = populate_evt_with_path(evt, bprm);
 	if(current_offset == 0) {
+		if(block_unlinked) {
+			// We cannot tell what is executed, which is what the policy forbids for the unlinked
+			// files: the exec is reported, with an empty path, as the exec of an unlinked file.
+			return report_unresolved_exec(evt, bprm, policy_id);
+		}
 		return 0;
 	}
 
@@ -576,9 +1231,23 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 		}
 	}
 
+	bool case_insensitive = flags && (*flags & POLICY_FLAG_CASE_INSENSITIVE);
+	if(case_insensitive) {
+		// The case of the path is folded in place before the comparison, so we save the original
+		// path in the first segment right now: the event must report the path as it was resolved.
+		if(copy_path_to_first_segment(evt, current_offset) != 0) {
+			emit_log_event(LOG_FAIL_TO_COPY_EXEC_PATH);
+			return 0;
+		}
+		lowercase_path(evt, current_offset);
+	}
+
+	// The executables of the global deny list are never allowed, whatever the allow list of the policy.
+	bool denied = globally_denied(evt, current_offset, bprm, case_insensitive);
+
 	int padded_len = string_padded_len(evt->path_len);
 	int index = string_map_index(padded_len);
-	void *string_map = get_policy_string_map(index, policy_id);
+	void *string_map = denied ? NULL : get_policy_string_map(index, policy_id);
 	// if `string_map` is NULL it means that the userspace never populated a map for this path
 	// length. This is an optimization userspace side and expected behavior. We should consider
 	// the missing map as a not allowed event.
@@ -594,30 +1263,75 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 		// current_offset points here
 		match = bpf_map_lookup_elem(string_map, &evt->path[SAFE_PATH_ACCESS(current_offset)]);
 	}
+	if(match == NULL && !denied) {
+		match = match_policy_prefix(evt, current_offset, policy_id);
+	}
+	// The entry is only written the first time, to avoid dirtying the cache line at each exec.
+	if(match != NULL && *match != POLICY_VALUE_SEEN) {
+		*match = POLICY_VALUE_SEEN;
+	}
 
-	if(match != NULL) {
-		// We have this binary in the list so we do nothing
+	// The mode bits are read from the inode of the file being executed, the same inode
+	// the kernel will use to apply the setuid/setgid credentials.
+	u16 file_mode = BPF_CORE_READ(bprm, file, f_inode, i_mode);
+	bool block_setid =
+	        flags && (*flags & POLICY_FLAG_BLOCK_SUID_EXEC) && is_setid_exec(file_mode);
+	bool unlinked = block_unlinked && is_unlinked_exec(bprm);
+	bool fs_denied = !unlinked && fs_not_allowed(evt, bprm, policy_id);
+	bool cmd_denied = match != NULL && !block_setid && !unlinked && !fs_denied &&
+	                  command_not_approved(evt, current_offset, bprm, policy_id);
+
+	// Only the allowed executables are counted, the other ones are violations anyway.
+	bool exec_limit =
+	        match != NULL && !block_setid && !unlinked && !fs_denied && !cmd_denied &&
+	        exceeds_exec_limit(evt, current_offset, cg_tracker_id, policy_id);
+
+	if(match != NULL && !block_setid && !unlinked && !fs_denied && !cmd_denied && !exec_limit) {
+		// We have this binary in the list so we do nothing, unless the container is traced or its policy
+		// is in audit mode: in that case the allowed exec is reported too, with no violation reason.
+		__u8 *allowed_mode = bpf_map_lookup_elem(&policy_mode_map, policy_id);
+		bool audited = allowed_mode && *allowed_mode == POLICY_MODE_AUDIT;
+		if(!audited && !bpf_map_lookup_elem(&trace_cgroups_map, &cg_tracker_id)) {
+			return 0;
+		}
+		if(!case_insensitive && copy_path_to_first_segment(evt, current_offset) != 0) {
+			emit_log_event(LOG_FAIL_TO_COPY_EXEC_PATH);
+			return 0;
+		}
+		evt->mode = allowed_mode ? enforced_mode(*allowed_mode) : 0;
+		evt->reason = 0;
+		evt->file_mode = file_mode;
+		if(bpf_ringbuf_output(&ringbuf_monitoring, evt, 32 + SAFE_PATH_LEN(evt->path_len), 0) != 0) {
+			emit_log_event_2(LOG_DROP_VIOLATION, *policy_id, evt->mode);
+		}
 		return 0;
 	}
+	if(unlinked) {
+		evt->reason = VIOLATION_REASON_UNLINKED_EXEC;
+	} else if(fs_denied) {
+		evt->reason = VIOLATION_REASON_FS_NOT_ALLOWED;
+	} else if(block_setid) {
+		evt->reason = VIOLATION_REASON_SUID_EXEC;
+	} else if(cmd_denied) {
+		evt->reason = VIOLATION_REASON_COMMAND_NOT_APPROVED;
+	} else if(exec_limit) {
+		evt->reason = VIOLATION_REASON_EXEC_LIMIT;
+	} else {
+		evt->reason = VIOLATION_REASON_EXEC_NOT_ALLOWED;
+	}
+	evt->file_mode = file_mode;
 
 	///////////////////////////////
 	// We send the event
 	///////////////////////////////
 
-	// here we are copying the resolved path into the first segment of the buffer.
-	// please note: in the first segment of the path we will already have the path written by
-	// the previous program execution, what we are doing here is to overwrite the path with the new
-	// content. Example:
-	// - previous: `/usr/bin/nginx-controller\0`
-	// - new one:  `/usr/bin/cat\0x-controller\0`
-	// we need the +1 because we want to copy also the `\0` terminator
-	long err = bpf_probe_read_kernel(evt->path,
-	                                 SAFE_PATH_LEN(evt->path_len + 1),
-	                                 &evt->path[SAFE_PATH_ACCESS(current_offset)]);
-	if(err != 0) {
+	if(!case_insensitive && copy_path_to_first_segment(evt, current_offset) != 0) {
 		emit_log_event(LOG_FAIL_TO_COPY_EXEC_PATH);
 		return 0;
 	}
+	if(cmd_denied) {
+		append_command_args(evt, bprm);
+	}
 
 	// We check if we are in monitoring or enforcing mode for this policy
 	__u8 *mode = bpf_map_lookup_elem(&policy_mode_map, policy_id);
@@ -626,10 +1340,15 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 		emit_log_event_1(LOG_POLICY_MODE_MISSING, *policy_id);
 		return 0;
 	}
-	bpf_printk("Mode %d for policy id %d", *mode, *policy_id);
-	evt->mode = *mode;
+	evt->mode = enforced_mode(*mode);
+	bpf_printk("Mode %d for policy id %d", evt->mode, *policy_id);
 
-	err = bpf_ringbuf_output(&ringbuf_monitoring, evt, 19 + SAFE_PATH_LEN(evt->path_len), 0);
+	u32 evt_len = evt->path_len;
+	if(evt->args_len > 0) {
+		// the arguments follow the NUL terminator of the path.
+		evt_len += 1 + evt->args_len;
+	}
+	long err = bpf_ringbuf_output(&ringbuf_monitoring, evt, 32 + SAFE_PATH_LEN(evt_len), 0);
 	if(err != 0) {
 		emit_log_event_2(LOG_DROP_VIOLATION, *policy_id, evt->mode);
 	}
@@ -637,9 +1356,291 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 	bpf_printk("sent enforce event, path: %s, cg_tracker_id: %d", evt->path, evt->cg_tracker_id);
 	bpf_printk("mode: %d", evt->mode);
 
-	if(*mode == POLICY_MODE_MONITOR) {
+	if(evt->mode != POLICY_MODE_PROTECT) {
 		return 0;
 	}
 	// We are in enforcing mode
 	return -EPERM;
 }
+
+// `security_bprm_creds_for_exec` runs before the kernel reads the file, so it cannot tell a script from
+// a binary. `security_bprm_check` runs for each file of the binfmt chain (e.g. the script and then its
+// interpreter) after its first bytes have been read, so we use it to detect the scripts.
+SEC("fmod_ret/security_bprm_check")
+int BPF_PROG(enforce_script_exec, struct linux_binprm *bprm) {
+	__u64 cg_tracker_id = get_tracker_id_from_curr_task();
+	if(cg_tracker_id == 0) {
+		return 0;
+	}
+
+	__u64 *policy_id = bpf_map_lookup_elem(&cg_to_policy_map, &cg_tracker_id);
+	if(!policy_id) {
+		return 0;
+	}
+
+	__u8 *flags = bpf_map_lookup_elem(&policy_flags_map, policy_id);
+	if(!flags || !(*flags & POLICY_FLAG_BLOCK_SCRIPTS) || !is_script(bprm)) {
+		return 0;
+	}
+
+	__u8 *mode = bpf_map_lookup_elem(&policy_mode_map, policy_id);
+	if(!mode) {
+		emit_log_event_1(LOG_POLICY_MODE_MISSING, *policy_id);
+		return 0;
+	}
+
+	struct process_evt *evt = get_process_evt();
+	if(!evt) {
+		return 0;
+	}
+
+	evt->cg_tracker_id = cg_tracker_id;
+	evt->tgid = bpf_get_current_pid_tgid() >> 32;
+	evt->mode = enforced_mode(*mode);
+	evt->reason = VIOLATION_REASON_SCRIPT_EXEC;
+	evt->file_mode = BPF_CORE_READ(bprm, file, f_inode, i_mode);
+
+	u32 current_offset = populate_evt_with_path(evt, bprm);
+	if(current_offset == 0) {
+		return 0;
+	}
+
+	if(copy_path_to_first_segment(evt, current_offset) != 0) {
+		emit_log_event(LOG_FAIL_TO_COPY_EXEC_PATH);
+		return 0;
+	}
+
+	long err = bpf_ringbuf_output(&ringbuf_monitoring, evt, 32 + SAFE_PATH_LEN(evt->path_len), 0);
+	if(err != 0) {
+		emit_log_event_2(LOG_DROP_VIOLATION, *policy_id, evt->mode);
+	}
+
+	bpf_printk("sent script event, path: %s, cg_tracker_id: %d", evt->path, evt->cg_tracker_id);
+
+	if(evt->mode != POLICY_MODE_PROTECT) {
+		return 0;
+	}
+	return -EPERM;
+}
+
+#ifndef FMODE_READ
+#define FMODE_READ 0x1
+#endif
+#ifndef FMODE_WRITE
+#define FMODE_WRITE 0x2
+#endif
+#ifndef FMODE_EXEC
+#define FMODE_EXEC 0x20
+#endif
+
+// Returns the violation reason of opening a file with the POLICY_FILE_* bits `bits` under the policy
+// flags `flags`, 0 when the open is allowed. A denied write takes precedence over the other rules.
+static __always_inline u8 file_open_violation(__u8 flags, __u8 bits, bool read, bool write) {
+	if(write && (flags & POLICY_FLAG_DENY_WRITE) && (bits & POLICY_FILE_WRITE_DENIED)) {
+		return VIOLATION_REASON_FILE_WRITE_DENIED;
+	}
+	if(write && (flags & POLICY_FLAG_RESTRICT_WRITE) && !(bits & POLICY_FILE_WRITE_ALLOWED)) {
+		return VIOLATION_REASON_FILE_WRITE_NOT_ALLOWED;
+	}
+	if(read && (flags & POLICY_FLAG_RESTRICT_READ) && !(bits & POLICY_FILE_READ_ALLOWED)) {
+		return VIOLATION_REASON_FILE_READ_NOT_ALLOWED;
+	}
+	return 0;
+}
+
+// `security_file_open` runs for every file opened by the processes, after the path has been resolved,
+// so we use it to enforce the file rules. The files opened by exec are left to the executables rules.
+SEC("fmod_ret/security_file_open")
+int BPF_PROG(enforce_file_open, struct file *file) {
+	__u64 cg_tracker_id = get_tracker_id_from_curr_task();
+	if(cg_tracker_id == 0) {
+		return 0;
+	}
+
+	__u64 *policy_id = bpf_map_lookup_elem(&cg_to_policy_map, &cg_tracker_id);
+	if(!policy_id) {
+		return 0;
+	}
+
+	// the policies without file rules are the common case, they don't pay for the path resolution.
+	__u8 *flags = bpf_map_lookup_elem(&policy_flags_map, policy_id);
+	if(!flags || !(*flags & POLICY_FLAGS_FILES)) {
+		return 0;
+	}
+
+	u32 f_mode = BPF_CORE_READ(file, f_mode);
+	if(f_mode & FMODE_EXEC) {
+		return 0;
+	}
+	bool read = f_mode & FMODE_READ;
+	bool write = f_mode & FMODE_WRITE;
+	if(!read && !write) {
+		return 0;
+	}
+
+	struct process_evt *evt = get_process_evt();
+	if(!evt) {
+		return 0;
+	}
+
+	u32 current_offset = populate_evt_with_file_path(evt, file);
+	if(current_offset == 0) {
+		return 0;
+	}
+
+	u8 reason = file_open_violation(*flags, match_policy_file(evt, current_offset, policy_id), read, write);
+	if(reason == 0) {
+		return 0;
+	}
+
+	__u8 *mode = bpf_map_lookup_elem(&policy_mode_map, policy_id);
+	if(!mode) {
+		emit_log_event_1(LOG_POLICY_MODE_MISSING, *policy_id);
+		return 0;
+	}
+
+	evt->cg_tracker_id = cg_tracker_id;
+	evt->tgid = bpf_get_current_pid_tgid() >> 32;
+	evt->mode = enforced_mode(*mode);
+	evt->reason = reason;
+	evt->file_mode = BPF_CORE_READ(file, f_inode, i_mode);
+
+	if(copy_path_to_first_segment(evt, current_offset) != 0) {
+		emit_log_event(LOG_FAIL_TO_COPY_EXEC_PATH);
+		return 0;
+	}
+
+	long err = bpf_ringbuf_output(&ringbuf_monitoring, evt, 32 + SAFE_PATH_LEN(evt->path_len), 0);
+	if(err != 0) {
+		emit_log_event_2(LOG_DROP_VIOLATION, *policy_id, evt->mode);
+	}
+
+	if(evt->mode != POLICY_MODE_PROTECT) {
+		return 0;
+	}
+	return -EPERM;
+}
+
+#ifndef AF_INET
+#define AF_INET 2
+#endif
+#ifndef AF_INET6
+#define AF_INET6 10
+#endif
+
+// The destination of a connection, appended to the violation events after the path of the executable.
+// Keep in sync with `egressDestination` in userspace.
+struct egress_dest {
+	__u8 addr[16];  // IPv6, or IPv4-mapped IPv6 for IPv4
+	__u16 port;     // host byte order
+} __attribute__((packed));
+
+// Fills the destination of the connection from the socket address, returns false for the families other than
+// IPv4 and IPv6, e.g. the unix sockets, which are never restricted.
+static __always_inline bool read_egress_dest(struct egress_dest *dest, struct sockaddr *address, int addrlen) {
+	u16 family = BPF_CORE_READ(address, sa_family);
+	if(family == AF_INET) {
+		if(addrlen < (int)sizeof(struct sockaddr_in)) {
+			return false;
+		}
+		struct sockaddr_in *sin = (struct sockaddr_in *)address;
+		dest->port = bpf_ntohs(BPF_CORE_READ(sin, sin_port));
+		__builtin_memset(dest->addr, 0, 10);
+		dest->addr[10] = 0xff;
+		dest->addr[11] = 0xff;
+		__u32 addr = BPF_CORE_READ(sin, sin_addr.s_addr);
+		__builtin_memcpy(&dest->addr[12], &addr, sizeof(addr));
+		return true;
+	}
+	if(family == AF_INET6) {
+		if(addrlen < (int)__builtin_offsetof(struct sockaddr_in6, sin6_scope_id)) {
+			return false;
+		}
+		struct sockaddr_in6 *sin6 = (struct sockaddr_in6 *)address;
+		dest->port = bpf_ntohs(BPF_CORE_READ(sin6, sin6_port));
+		return bpf_probe_read_kernel(dest->addr, sizeof(dest->addr), &sin6->sin6_addr) == 0;
+	}
+	return false;
+}
+
+// `security_socket_connect` runs for every connect, before the connection is attempted,
+// so we use it to enforce the allowed egress destinations.
+SEC("fmod_ret/security_socket_connect")
+int BPF_PROG(enforce_socket_connect, struct socket *sock, struct sockaddr *address, int addrlen) {
+	__u64 cg_tracker_id = get_tracker_id_from_curr_task();
+	if(cg_tracker_id == 0) {
+		return 0;
+	}
+
+	__u64 *policy_id = bpf_map_lookup_elem(&cg_to_policy_map, &cg_tracker_id);
+	if(!policy_id) {
+		return 0;
+	}
+
+	__u8 *flags = bpf_map_lookup_elem(&policy_flags_map, policy_id);
+	if(!flags || !(*flags & POLICY_FLAG_RESTRICT_EGRESS)) {
+		return 0;
+	}
+
+	struct egress_dest dest = {};
+	if(!read_egress_dest(&dest, address, addrlen)) {
+		return 0;
+	}
+
+	struct policy_egress_key key = {
+	        .prefixlen = (sizeof(key.policy_id) + sizeof(key.port) + sizeof(key.addr)) * 8,
+	        .policy_id = *policy_id,
+	        .port = dest.port,
+	};
+	__builtin_memcpy(key.addr, dest.addr, sizeof(key.addr));
+	if(bpf_map_lookup_elem(&policy_egress_map, &key)) {
+		return 0;
+	}
+
+	__u8 *mode = bpf_map_lookup_elem(&policy_mode_map, policy_id);
+	if(!mode) {
+		emit_log_event_1(LOG_POLICY_MODE_MISSING, *policy_id);
+		return 0;
+	}
+
+	struct process_evt *evt = get_process_evt();
+	if(!evt) {
+		return 0;
+	}
+
+	// the event carries the executable of the process connecting, followed by the destination.
+	struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+	u32 current_offset = populate_evt_with_file_path(evt, BPF_CORE_READ(task, mm, exe_file));
+	if(current_offset == 0) {
+		return 0;
+	}
+
+	evt->cg_tracker_id = cg_tracker_id;
+	evt->tgid = bpf_get_current_pid_tgid() >> 32;
+	evt->mode = enforced_mode(*mode);
+	evt->reason = VIOLATION_REASON_EGRESS_NOT_ALLOWED;
+	evt->file_mode = 0;
+
+	if(copy_path_to_first_segment(evt, current_offset) != 0) {
+		emit_log_event(LOG_FAIL_TO_COPY_EXEC_PATH);
+		return 0;
+	}
+
+	// the per-CPU event is reused: without room for the destination, no stale arguments must be reported.
+	evt->args_len = 0;
+	u32 evt_len = evt->path_len;
+	if(evt->path_len + 1 + sizeof(dest) < MAX_PATH_LEN &&
+	   bpf_probe_read_kernel(&evt->path[SAFE_PATH_LEN(evt->path_len + 1)], sizeof(dest), &dest) == 0) {
+		evt->args_len = sizeof(dest);
+		evt_len += 1 + evt->args_len;
+	}
+	long err = bpf_ringbuf_output(&ringbuf_monitoring, evt, 32 + SAFE_PATH_LEN(evt_len), 0);
+	if(err != 0) {
+		emit_log_event_2(LOG_DROP_VIOLATION, *policy_id, evt->mode);
+	}
+
+	if(evt->mode != POLICY_MODE_PROTECT) {
+		return 0;
+	}
+	return -EPERM;
+}
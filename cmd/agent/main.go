@@ -11,34 +11,64 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/avast/retry-go/v4"
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/denylist"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/devpolicy"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/eventhandler"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/events"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/eventscraper"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/freezewindow"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/grpcexporter"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/heartbeat"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/metricslog"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/nri"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/podreadinesshandler"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/policybindings"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/portscope"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/profiles"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/loglevel"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/workloadpolicyhandler"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/rancher-sandbox/runtime-enforcer/internal/violationbuf"
 	otellog "go.opentelemetry.io/otel/log"
+	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 )
 
+// version is set at build time via ldflags, it is reported in the status of the applied policies.
+var version = "dev"
+
+// openMetricsPath serves the metrics in the OpenMetrics format, the only one exposing the exemplars.
+// The default /metrics endpoint of the controller-runtime metrics server doesn't negotiate it.
+const openMetricsPath = "/metrics/openmetrics"
+
+// defaultMapUpdateBurst allows applying a policy with a few containers without waiting on the map update rate limit.
+const defaultMapUpdateBurst = 100
+
 type Config struct {
 	learningNamespaceSelector string
 	nriSocketPath             string
@@ -51,7 +81,47 @@ type Config struct {
 	otlpCACert                string
 	otlpClientCert            string
 	otlpClientKey             string
+	clusterName               string
+	clusterRegion             string
 	nodeName                  string
+	forceMonitorMode          bool
+	observeOnly               bool
+	enforceAfterReadiness     bool
+	excludedNamespaces        string
+	alwaysAllowedExecutables  string
+	lifecycleExecutables      string
+	globalDenyListFile        string
+	violationPodLabels        string
+	violationSeverities       string
+	violationEventInterval    time.Duration
+	metricsExemplars          bool
+	metricsLogInterval        time.Duration
+	selfTest                  bool
+	violationHistorySize      int
+	debugBindAddress          string
+	policyBatchInterval       time.Duration
+	policyFreezeWindows       string
+	stalePolicyCleanupPeriod  time.Duration
+	coverageMetricsInterval   time.Duration
+	nriReconnectBaseDelay     time.Duration
+	nriReconnectMaxDelay      time.Duration
+	nriReconnectMaxAttempts   uint
+	unresolvedCgroupStrategy  string
+	unresolvedCgroupLogLevel  string
+	unresolvedCgroupTimeout   time.Duration
+	mapUpdateRateLimit        float64
+	mapUpdateBurst            int
+	policyMapFullAction       string
+	namespacePolicyIDQuota    int
+	lockHoldWarnThreshold     time.Duration
+	devPolicyFiles            string
+	cgroupV1ControllerIdx     int
+	cgroupMountPoint          string
+	heartbeatInterval         time.Duration
+	terminationRelaxation     string
+	checkServiceAccounts      bool
+	policyBindingsInterval    time.Duration
+	policyBindingsNamespace   string
 	violationLogger           otellog.Logger
 }
 
@@ -59,6 +129,13 @@ func (c Config) learningEnabled() bool {
 	return strings.TrimSpace(c.learningNamespaceSelector) != ""
 }
 
+// watchPods returns true when the agent follows the pods of the node: their readiness, the start of their
+// containers for the lifecycle executables, their termination, or their service account.
+func (c Config) watchPods() bool {
+	return c.enforceAfterReadiness || strings.TrimSpace(c.lifecycleExecutables) != "" ||
+		c.terminationRelaxation != string(resolver.TerminationRelaxationNone) || c.checkServiceAccounts
+}
+
 func newControllerManager(config Config) (manager.Manager, error) {
 	scheme := runtime.NewScheme()
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
@@ -67,6 +144,34 @@ func newControllerManager(config Config) (manager.Manager, error) {
 		Scheme:                 scheme,
 		HealthProbeBindAddress: config.probeAddr,
 	}
+	if config.metricsExemplars {
+		controllerOptions.Metrics = metricsserver.Options{
+			ExtraHandlers: map[string]http.Handler{
+				openMetricsPath: promhttp.HandlerFor(
+					ctrlmetrics.Registry,
+					promhttp.HandlerOpts{EnableOpenMetrics: true},
+				),
+			},
+		}
+	}
+	if config.watchPods() {
+		if config.nodeName == "" {
+			return nil, errors.New(
+				"the node name is required to enforce policies after the pod readiness or the container start")
+		}
+		// we only need to watch the pods running on this node.
+		controllerOptions.Cache = cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}: {Field: fields.OneTermEqualSelector("spec.nodeName", config.nodeName)},
+			},
+		}
+	}
+	if config.policyBindingsInterval > 0 {
+		// the agent reads only the ConfigMap of its node, caching every ConfigMap of the cluster is not needed.
+		controllerOptions.Client = client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.ConfigMap{}}},
+		}
+	}
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), controllerOptions)
 	if err != nil {
 		return nil, fmt.Errorf("unable to start manager: %w", err)
@@ -91,12 +196,279 @@ func setupGRPCExporter(
 	return nil
 }
 
+// setupHeartbeat emits a heartbeat event of the node every interval through the event export pipeline.
+func setupHeartbeat(
+	ctrlMgr manager.Manager,
+	logger *slog.Logger,
+	config Config,
+	r *resolver.Resolver,
+) error {
+	if config.heartbeatInterval < 0 {
+		return fmt.Errorf("invalid heartbeat interval %v: it must not be negative", config.heartbeatInterval)
+	}
+	if config.heartbeatInterval == 0 {
+		return nil
+	}
+	if config.violationLogger == nil {
+		logger.Warn("the heartbeat is disabled: no OTLP endpoint is configured")
+		return nil
+	}
+
+	info := heartbeat.Info{
+		NodeName:        config.nodeName,
+		EnforcerVersion: config.grpcConf.EnforcerVersion,
+	}
+	// The cgroup info has already been detected by the BPF manager.
+	if cgInfo, err := cgroups.GetCgroupInfo(); err == nil {
+		info.CgroupVersion = cgInfo.CgroupFsMagicString()
+	}
+	emitter := heartbeat.NewEmitter(config.violationLogger, info, r.PolicyCount)
+	if err := ctrlMgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return emitter.Run(ctx, config.heartbeatInterval)
+	})); err != nil {
+		return fmt.Errorf("failed to add heartbeat to controller manager: %w", err)
+	}
+	return nil
+}
+
+// setupPolicyBindings publishes the containers each policy is enforced on into the ConfigMap of the node.
+func setupPolicyBindings(
+	ctrlMgr manager.Manager,
+	logger *slog.Logger,
+	config Config,
+	r *resolver.Resolver,
+) error {
+	if config.policyBindingsInterval < 0 {
+		return fmt.Errorf("invalid policy bindings interval %v: it must not be negative", config.policyBindingsInterval)
+	}
+	if config.policyBindingsInterval == 0 {
+		return nil
+	}
+	if config.nodeName == "" || config.policyBindingsNamespace == "" {
+		return errors.New("the node name and the namespace are required to publish the policy bindings")
+	}
+
+	publisher := policybindings.NewPublisher(
+		ctrlMgr.GetClient(),
+		logger.With("component", "policy-bindings"),
+		r.PolicyBindings,
+		config.policyBindingsNamespace,
+		config.nodeName,
+	)
+	if err := ctrlMgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return publisher.Run(ctx, config.policyBindingsInterval)
+	})); err != nil {
+		return fmt.Errorf("failed to add policy bindings publisher to controller manager: %w", err)
+	}
+	return nil
+}
+
+// setupDebugServer exposes the debug endpoints of the agent, it is disabled when no bind address is provided.
+// The endpoints are not authenticated, so they are only served on a loopback address.
+func setupDebugServer(
+	ctrlMgr manager.Manager,
+	logger *slog.Logger,
+	bindAddress string,
+	violationHistory *violationbuf.History,
+	r *resolver.Resolver,
+	bpfManager *bpf.Manager,
+) error {
+	if bindAddress == "" {
+		return nil
+	}
+	bindAddress, err := parseDebugBindAddress(bindAddress)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	if violationHistory != nil {
+		mux.Handle("GET /debug/violations", violationHistory)
+	}
+	mux.HandleFunc("POST /debug/rebuild-bpf-maps", func(w http.ResponseWriter, req *http.Request) {
+		logger.WarnContext(req.Context(), "rebuilding BPF maps on operator request")
+		if err := r.RebuildBPFMaps(bpfManager); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /debug/executable-conflicts", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.ExecutableConflicts()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("GET /debug/cgroup", func(w http.ResponseWriter, req *http.Request) {
+		check, err := r.CheckCgroupPath(req.URL.Query().Get("path"))
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, resolver.ErrInvalidCgroupPath) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(check); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("GET /debug/bpf-policy", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		dumps, err := r.DumpPodBPF(query.Get("namespace"), query.Get("pod"), bpfManager)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, resolver.ErrPodNotFound) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(dumps); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("POST /debug/trace", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		duration := resolver.DefaultTraceDuration
+		if value := query.Get("duration"); value != "" {
+			var err error
+			if duration, err = time.ParseDuration(value); err != nil {
+				http.Error(w, fmt.Sprintf("invalid duration: %s", err), http.StatusBadRequest)
+				return
+			}
+		}
+		expiresAt, err := r.StartTrace(query.Get("namespace"), query.Get("pod"), query.Get("container"), duration)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "exec decisions are traced until %s\n", expiresAt.Format(time.RFC3339))
+	})
+	mux.HandleFunc("DELETE /debug/trace", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		if err := r.StopTrace(query.Get("namespace"), query.Get("pod"), query.Get("container")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := &http.Server{
+		Addr:              bindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	err = ctrlMgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+				logger.Error("failed to shutdown debug server", "error", shutdownErr)
+			}
+		}()
+		logger.InfoContext(ctx, "starting debug server", "address", bindAddress)
+		if serveErr := server.ListenAndServe(); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			return fmt.Errorf("debug server failed: %w", serveErr)
+		}
+		return nil
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to add debug server to controller manager: %w", err)
+	}
+	return nil
+}
+
+// setupSelfTest runs the BPF self-test once the BPF manager is started and reports its outcome
+// through the readiness probe, so that an agent that cannot enforce never becomes ready.
+func setupSelfTest(ctrlMgr manager.Manager, logger *slog.Logger, bpfManager *bpf.Manager) error {
+	// The agent binary is the only executable we can rely on in the image.
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get the agent executable for the self-test: %w", err)
+	}
+
+	var result atomic.Pointer[error]
+	pending := errors.New("self-test not completed yet")
+	result.Store(&pending)
+
+	err = ctrlMgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		// If the exec is wrongly allowed, `-h` makes the agent print its usage and exit.
+		testErr := bpfManager.SelfTest(ctx, binary, "-h")
+		if testErr != nil {
+			logger.ErrorContext(ctx, "enforcement self-test failed", "error", testErr)
+		}
+		result.Store(&testErr)
+		return nil
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to add self-test to controller manager: %w", err)
+	}
+	if err = ctrlMgr.AddReadyzCheck("bpf self-test", func(_ *http.Request) error {
+		return *result.Load()
+	}); err != nil {
+		return fmt.Errorf("failed to add self-test readiness probe: %w", err)
+	}
+	return nil
+}
+
+func setupPodReadinessHandler(
+	ctrlMgr manager.Manager,
+	logger *slog.Logger,
+	resolver *resolver.Resolver,
+) error {
+	podHandler := podreadinesshandler.NewPodReadinessHandler(
+		ctrlMgr.GetClient(),
+		logger,
+		resolver,
+		ctrlMgr.GetEventRecorder("runtime-enforcer-agent"),
+	)
+	if err := podHandler.SetupWithManager(ctrlMgr); err != nil {
+		return fmt.Errorf("unable to set up Pod readiness handler: %w", err)
+	}
+	return nil
+}
+
+func setupDevPolicyWatcher(
+	ctx context.Context,
+	ctrlMgr manager.Manager,
+	logger *slog.Logger,
+	resolver *resolver.Resolver,
+	spec string,
+) error {
+	bindings, err := devpolicy.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("invalid dev policy files: %w", err)
+	}
+	if len(bindings) == 0 {
+		return nil
+	}
+	logger.WarnContext(ctx, "developer mode: the allowed executables of some policies are read from local files",
+		"files", spec)
+	resolver.SetDevMode(true)
+	watcher := devpolicy.NewWatcher(logger, bindings, resolver.SetDevAllowedExecutables)
+	if err = ctrlMgr.Add(manager.RunnableFunc(watcher.Run)); err != nil {
+		return fmt.Errorf("failed to add dev policy watcher to controller manager: %w", err)
+	}
+	return nil
+}
+
 func setupWorkloadPolicyHandler(
 	ctrlMgr manager.Manager,
 	logger *slog.Logger,
 	resolver *resolver.Resolver,
+	batchInterval time.Duration,
+	freezeSchedule *freezewindow.Schedule,
 ) error {
-	wpHandler := workloadpolicyhandler.NewWorkloadPolicyHandler(ctrlMgr.GetClient(), logger, resolver)
+	wpHandler := workloadpolicyhandler.NewWorkloadPolicyHandler(
+		ctrlMgr.GetClient(),
+		logger,
+		resolver,
+		workloadpolicyhandler.WithBatchInterval(batchInterval),
+		workloadpolicyhandler.WithFreezeSchedule(freezeSchedule),
+	)
 	err := wpHandler.SetupWithManager(ctrlMgr)
 	if err != nil {
 		return fmt.Errorf("unable to set up WorkloadPolicy handler: %w", err)
@@ -209,13 +581,41 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 	//////////////////////
 	// Create BPF manager
 	//////////////////////
-	bpfManager, err := bpf.NewManager(logger, config.learningEnabled())
+	var bpfOpts []bpf.ManagerOption
+	if config.observeOnly {
+		if config.selfTest {
+			return errors.New("the self-test cannot be enabled in observe-only mode: no exec is ever blocked")
+		}
+		logger.WarnContext(ctx, "OBSERVE-ONLY MODE: no exec will ever be blocked, whatever the mode of the policies")
+		bpfOpts = append(bpfOpts, bpf.WithObserveOnly())
+	}
+	if config.cgroupV1ControllerIdx >= cgroups.CgroupSubsysCount {
+		return fmt.Errorf("invalid cgroupv1 controller index %d: it must be lower than %d",
+			config.cgroupV1ControllerIdx, cgroups.CgroupSubsysCount)
+	}
+	if config.cgroupV1ControllerIdx >= 0 {
+		logger.InfoContext(ctx, "using the cgroupv1 controller set explicitly", "index", config.cgroupV1ControllerIdx)
+		cgroups.SetCgroupV1ControllerIdx(uint32(config.cgroupV1ControllerIdx)) //nolint:gosec // checked above
+	}
+	if config.cgroupMountPoint != "" {
+		logger.InfoContext(ctx, "using the cgroup mount point set explicitly", "path", config.cgroupMountPoint)
+		cgroups.SetCgroupMountPoint(config.cgroupMountPoint)
+	}
+	if err = bpf.RegisterMetrics(ctrlmetrics.Registry); err != nil {
+		return fmt.Errorf("failed to register BPF metrics: %w", err)
+	}
+	bpfManager, err := bpf.NewManager(logger, config.learningEnabled(), bpfOpts...)
 	if err != nil {
 		return fmt.Errorf("cannot create BPF manager: %w", err)
 	}
 	if err = ctrlMgr.Add(bpfManager); err != nil {
 		return fmt.Errorf("failed to add BPF manager to controller manager: %w", err)
 	}
+	if config.selfTest {
+		if err = setupSelfTest(ctrlMgr, logger, bpfManager); err != nil {
+			return err
+		}
+	}
 
 	//////////////////////
 	// Create Learning Reconciler if learning is enabled
@@ -228,18 +628,151 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 	//////////////////////
 	// Create the resolver
 	//////////////////////
+	if err = resolver.RegisterMetrics(ctrlmetrics.Registry); err != nil {
+		return fmt.Errorf("failed to register resolver metrics: %w", err)
+	}
+	mapFullAction, err := resolver.ParseMapFullAction(config.policyMapFullAction)
+	if err != nil {
+		return err
+	}
+	terminationRelaxation, err := resolver.ParseTerminationRelaxation(config.terminationRelaxation)
+	if err != nil {
+		return err
+	}
 	resolver, err := resolver.NewResolver(
 		logger,
 		bpfManager.GetCgroupTrackerUpdateFunc(),
 		bpfManager.GetCgroupPolicyUpdateFunc(),
 		bpfManager.GetPolicyUpdateBinariesFunc(),
 		bpfManager.GetPolicyModeUpdateFunc(),
+		bpfManager.GetPolicyFlagsUpdateFunc(),
+		bpfManager.GetPolicyExecLimitUpdateFunc(),
+		bpfManager.GetPolicyFsTypesUpdateFunc(),
+		bpfManager.GetPolicyCommandsUpdateFunc(),
+		bpfManager.GetPolicyFilesUpdateFunc(),
+		bpfManager.GetPolicyEgressUpdateFunc(),
+		bpfManager.GetGlobalDenyUpdateFunc(),
+		bpfManager.GetPolicyIDsListFunc(),
+		bpfManager.GetTraceUpdateFunc(),
+		bpfManager.GetPolicySeenValuesFunc(),
+		bpfManager.GetCgroupPolicyLookupFunc(),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create resolver: %w", err)
 	}
+	bpfManager.SetWorkloadContextFunc(resolver.GetWorkloadContext)
+	if config.forceMonitorMode || config.observeOnly {
+		// In observe-only mode the BPF programs never block, forcing the monitor mode
+		// makes the agent report the policies as they are actually enforced.
+		logger.WarnContext(ctx, "monitor mode is forced: no policy will be enforced in protect mode")
+		resolver.SetForceMonitorMode(true)
+	}
+	if excluded := parseCommaSeparatedList(config.excludedNamespaces); len(excluded) > 0 {
+		logger.InfoContext(ctx, "policies are never applied in excluded namespaces", "namespaces", excluded)
+		resolver.SetExcludedNamespaces(excluded)
+	}
+	alwaysAllowed, err := parseExecutablePaths("always-allowed", config.alwaysAllowedExecutables)
+	if err != nil {
+		return err
+	}
+	if len(alwaysAllowed) > 0 {
+		logger.InfoContext(ctx, "executables always allowed in every policy", "executables", alwaysAllowed)
+		resolver.SetAlwaysAllowedExecutables(alwaysAllowed)
+	}
+	lifecycle, err := parseExecutablePaths("lifecycle", config.lifecycleExecutables)
+	if err != nil {
+		return err
+	}
+	if len(lifecycle) > 0 {
+		logger.InfoContext(ctx, "executables allowed in the containers until they are started",
+			"executables", lifecycle)
+		resolver.SetLifecycleExecutables(lifecycle)
+	}
+	if config.globalDenyListFile != "" {
+		logger.InfoContext(ctx, "executables of the global deny list are denied in every policy",
+			"file", config.globalDenyListFile)
+		// The deny list is loaded before the policies are applied, then reloaded each time the file changes.
+		var entries []string
+		if entries, err = denylist.Load(config.globalDenyListFile); err != nil {
+			return err
+		}
+		if err = resolver.SetGlobalDenyList(entries); err != nil {
+			return err
+		}
+		watcher := denylist.NewWatcher(logger, config.globalDenyListFile, resolver.SetGlobalDenyList)
+		if err = ctrlMgr.Add(manager.RunnableFunc(watcher.Run)); err != nil {
+			return fmt.Errorf("failed to add deny list watcher to controller manager: %w", err)
+		}
+	}
+	if config.mapUpdateRateLimit < 0 {
+		return fmt.Errorf("invalid map update rate limit %v: it must not be negative", config.mapUpdateRateLimit)
+	}
+	if config.mapUpdateRateLimit > 0 {
+		if config.mapUpdateBurst < 1 {
+			return fmt.Errorf("invalid map update burst %d: it must be at least 1", config.mapUpdateBurst)
+		}
+		logger.InfoContext(ctx, "writes into the BPF maps are rate limited",
+			"limit", config.mapUpdateRateLimit, "burst", config.mapUpdateBurst)
+		resolver.SetMapUpdateRateLimit(rate.Limit(config.mapUpdateRateLimit), config.mapUpdateBurst)
+	}
+	executableProfiles, err := profiles.Bundled()
+	if err != nil {
+		return fmt.Errorf("failed to load the bundled executable profiles: %w", err)
+	}
+	resolver.SetExecutableProfiles(executableProfiles)
+	resolver.SetMapFullAction(mapFullAction)
+	resolver.SetTerminationRelaxation(terminationRelaxation)
+	resolver.SetServiceAccountChecks(config.watchPods())
+	resolver.SetPolicyMapCapacity(bpfManager.GetPolicyMapCapacity())
+	if config.namespacePolicyIDQuota < 0 {
+		return fmt.Errorf("invalid namespace policy ID quota %d: it must not be negative", config.namespacePolicyIDQuota)
+	}
+	resolver.SetNamespacePolicyIDQuota(config.namespacePolicyIDQuota)
+	resolver.SetLockHoldWarnThreshold(config.lockHoldWarnThreshold)
+	if err = setupDevPolicyWatcher(ctx, ctrlMgr, logger, resolver, config.devPolicyFiles); err != nil {
+		return err
+	}
+
+	if err = ctrlMgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return resolver.RunStalePolicyIDsCleanup(ctx, config.stalePolicyCleanupPeriod)
+	})); err != nil {
+		return fmt.Errorf("failed to add stale policy IDs cleanup to controller manager: %w", err)
+	}
+
+	if config.coverageMetricsInterval > 0 {
+		if err = ctrlMgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return resolver.RunCoverageMetrics(ctx, config.coverageMetricsInterval)
+		})); err != nil {
+			return fmt.Errorf("failed to add coverage metrics to controller manager: %w", err)
+		}
+	}
+
+	if err = ctrlMgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return resolver.RunPolicyModeRepair(ctx, bpfManager.GetPolicyModeMissingChannel())
+	})); err != nil {
+		return fmt.Errorf("failed to add policy mode repair to controller manager: %w", err)
+	}
 
-	if err = setupWorkloadPolicyHandler(ctrlMgr, logger, resolver); err != nil {
+	if config.enforceAfterReadiness {
+		resolver.SetEnforceAfterReadiness(true)
+	}
+	if config.watchPods() {
+		if err = setupPodReadinessHandler(ctrlMgr, logger, resolver); err != nil {
+			return err
+		}
+	}
+
+	freezeSchedule, err := freezewindow.Parse(config.policyFreezeWindows)
+	if err != nil {
+		return fmt.Errorf("invalid policy freeze windows: %w", err)
+	}
+	if freezeSchedule != nil {
+		logger.InfoContext(ctx, "policy updates are deferred during the freeze windows",
+			"windows", config.policyFreezeWindows)
+	}
+	if err = setupWorkloadPolicyHandler(
+		ctrlMgr, logger, resolver, config.policyBatchInterval, freezeSchedule,
+	); err != nil {
 		return err
 	}
 
@@ -249,6 +782,8 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 		config.nriPluginIdx,
 		logger,
 		resolver,
+		nri.WithReconnectBackoff(config.nriReconnectBaseDelay, config.nriReconnectMaxDelay),
+		nri.WithReconnectMaxAttempts(config.nriReconnectMaxAttempts),
 	)
 
 	if err != nil {
@@ -268,15 +803,72 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 	// Create the violation buffer
 	//////////////////////
 	violationBuffer := violationbuf.NewBuffer()
+	var violationHistory *violationbuf.History
+	if config.violationHistorySize > 0 {
+		violationHistory = violationbuf.NewHistory(config.violationHistorySize)
+	}
 
 	//////////////////////
 	// Create the scraper
 	//////////////////////
+	if err = eventscraper.RegisterMetrics(ctrlmetrics.Registry); err != nil {
+		return fmt.Errorf("failed to register event scraper metrics: %w", err)
+	}
+	if config.metricsLogInterval < 0 {
+		return fmt.Errorf("invalid metrics log interval %v: it must not be negative", config.metricsLogInterval)
+	}
+	if config.metricsLogInterval > 0 {
+		snapshotter := metricslog.NewSnapshotter(logger, ctrlmetrics.Registry, metricslog.DefaultCounters)
+		if err = ctrlMgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return snapshotter.Run(ctx, config.metricsLogInterval)
+		})); err != nil {
+			return fmt.Errorf("failed to add metrics snapshot logger to controller manager: %w", err)
+		}
+	}
 	var scraperOpts []eventscraper.Option
 	if config.violationLogger != nil {
 		scraperOpts = append(scraperOpts, eventscraper.WithViolationLogger(config.violationLogger, config.nodeName))
 	}
 	scraperOpts = append(scraperOpts, eventscraper.WithViolationBuffer(violationBuffer, config.nodeName))
+	// The agent runs in the host pid namespace, so the pids reported by BPF can be resolved in its /proc.
+	scraperOpts = append(scraperOpts, eventscraper.WithPortClassifier(portscope.NewClassifier("/proc")))
+	// The cgroup info has already been detected by the BPF manager.
+	if cgInfo, cgErr := cgroups.GetCgroupInfo(); cgErr == nil {
+		scraperOpts = append(scraperOpts, eventscraper.WithCgroupInfo(cgInfo.CgroupFsMagicString(), cgroups.GetCgroupDriver))
+	}
+	if violationHistory != nil {
+		scraperOpts = append(scraperOpts, eventscraper.WithViolationHistory(violationHistory))
+	}
+	if config.metricsExemplars {
+		scraperOpts = append(scraperOpts, eventscraper.WithViolationExemplars())
+	}
+	unresolvedCgroupConfig, err := parseUnresolvedCgroupConfig(config)
+	if err != nil {
+		return err
+	}
+	scraperOpts = append(scraperOpts, eventscraper.WithUnresolvedCgroupConfig(unresolvedCgroupConfig))
+	violationPodLabels, err := parseViolationPodLabels(config.violationPodLabels)
+	if err != nil {
+		return err
+	}
+	if len(violationPodLabels) > 0 {
+		logger.InfoContext(ctx, "pod labels added to the violation events", "labels", violationPodLabels)
+		scraperOpts = append(scraperOpts, eventscraper.WithPodLabelAttributes(violationPodLabels))
+	}
+	severityMapping, err := eventscraper.ParseSeverityMapping(config.violationSeverities)
+	if err != nil {
+		return err
+	}
+	if len(severityMapping) > 0 {
+		scraperOpts = append(scraperOpts, eventscraper.WithSeverityMapping(severityMapping))
+	}
+	if config.violationEventInterval < 0 {
+		return fmt.Errorf("invalid violation event interval %v: it must not be negative", config.violationEventInterval)
+	}
+	if config.violationEventInterval > 0 {
+		scraperOpts = append(scraperOpts, eventscraper.WithViolationEvents(
+			ctrlMgr.GetEventRecorder("runtime-enforcer-agent"), config.violationEventInterval))
+	}
 	evtScraper := eventscraper.NewEventScraper(
 		bpfManager.GetLearningChannel(),
 		bpfManager.GetMonitoringChannel(),
@@ -296,6 +888,20 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 		return err
 	}
 
+	if err = setupDebugServer(
+		ctrlMgr, logger, config.debugBindAddress, violationHistory, resolver, bpfManager,
+	); err != nil {
+		return err
+	}
+
+	if err = setupHeartbeat(ctrlMgr, logger, config, resolver); err != nil {
+		return err
+	}
+
+	if err = setupPolicyBindings(ctrlMgr, logger, config, resolver); err != nil {
+		return err
+	}
+
 	logger.InfoContext(ctx, "starting manager")
 	if err = ctrlMgr.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start manager: %w", err)
@@ -326,6 +932,81 @@ func parseLearningNamespaceSelector(s string) (labels.Selector, error) {
 	return selector, nil
 }
 
+// parseCommaSeparatedList parses a comma-separated list, ignoring the empty entries.
+func parseCommaSeparatedList(s string) []string {
+	var items []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseExecutablePaths parses a comma-separated list of executables, e.g. the always-allowed ones,
+// each one must be an absolute path.
+func parseExecutablePaths(kind, s string) ([]string, error) {
+	executables := parseCommaSeparatedList(s)
+	for _, exe := range executables {
+		if !strings.HasPrefix(exe, "/") {
+			return nil, fmt.Errorf("%s executable %q must be an absolute path", kind, exe)
+		}
+	}
+	return executables, nil
+}
+
+// parseViolationPodLabels parses the comma-separated list of pod labels added to the violation events.
+func parseViolationPodLabels(s string) ([]string, error) {
+	keys := parseCommaSeparatedList(s)
+	for _, key := range keys {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid violation pod label %q: %s", key, strings.Join(errs, ", "))
+		}
+	}
+	return keys, nil
+}
+
+// parseDebugBindAddress validates the address of the debug endpoint, which is not authenticated and can alter
+// the BPF maps: it must be a loopback address, an empty host binds to 127.0.0.1.
+func parseDebugBindAddress(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid debug bind address %q: %w", address, err)
+	}
+	switch host {
+	case "":
+		host = "127.0.0.1"
+	case "localhost":
+	default:
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return "", fmt.Errorf("invalid debug bind address %q: the host must be a loopback address", address)
+		}
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// parseUnresolvedCgroupConfig parses how the events whose cgroup is not associated with any pod are handled.
+func parseUnresolvedCgroupConfig(config Config) (eventscraper.UnresolvedCgroupConfig, error) {
+	strategy, err := eventscraper.ParseUnresolvedCgroupStrategy(config.unresolvedCgroupStrategy)
+	if err != nil {
+		return eventscraper.UnresolvedCgroupConfig{}, err
+	}
+	var level slog.Level
+	if err = level.UnmarshalText([]byte(config.unresolvedCgroupLogLevel)); err != nil {
+		return eventscraper.UnresolvedCgroupConfig{}, fmt.Errorf(
+			"invalid unresolved-cgroup-log-level %q: %w", config.unresolvedCgroupLogLevel, err)
+	}
+	if config.unresolvedCgroupTimeout <= 0 {
+		return eventscraper.UnresolvedCgroupConfig{}, fmt.Errorf(
+			"unresolved-cgroup-retry-timeout must be positive, got %s", config.unresolvedCgroupTimeout)
+	}
+	return eventscraper.UnresolvedCgroupConfig{
+		Strategy:     strategy,
+		LogLevel:     level,
+		RetryTimeout: config.unresolvedCgroupTimeout,
+	}, nil
+}
+
 func parseFlags() Config {
 	var config Config
 	// If we receive something different from "", it should be a valid json
@@ -335,8 +1016,15 @@ func parseFlags() Config {
 		"",
 		"Namespace selector for learning. Accepts a JSON LabelSelector",
 	)
-	flag.StringVar(&config.nriSocketPath, "nri-socket-path", "/var/run/nri/nri.sock", "NRI socket path")
+	flag.StringVar(&config.nriSocketPath, "nri-socket-path", "",
+		"NRI socket path. When empty, the first socket found in the known NRI socket locations is used")
 	flag.StringVar(&config.nriPluginIdx, "nri-plugin-index", "00", "NRI plugin index")
+	flag.DurationVar(&config.nriReconnectBaseDelay, "nri-reconnect-base-delay", time.Second,
+		"Initial delay before reconnecting to NRI, doubled at each failed attempt")
+	flag.DurationVar(&config.nriReconnectMaxDelay, "nri-reconnect-max-delay", time.Minute,
+		"Maximum delay between NRI reconnection attempts")
+	flag.UintVar(&config.nriReconnectMaxAttempts, "nri-reconnect-max-attempts", 0,
+		"Number of failed NRI connection attempts before the agent exits with an error (0 = retry forever)")
 	flag.StringVar(&config.probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.IntVar(&config.grpcConf.Port, "grpc-port", 50051, "gRPC server port")
 	flag.BoolVar(&config.grpcConf.MTLSEnabled, "grpc-mtls-enabled", true,
@@ -375,9 +1063,100 @@ func parseFlags() Config {
 	)
 	flag.StringVar(&config.nodeName, "node-name", os.Getenv("NODE_NAME"),
 		"Node name for violation reporting (defaults to NODE_NAME env var)")
+	flag.StringVar(&config.clusterName, "cluster-name", "",
+		"Name of the cluster attached to every exported violation event (empty = omitted)")
+	flag.StringVar(&config.clusterRegion, "cluster-region", "",
+		"Region of the cluster attached to every exported violation event (empty = omitted)")
 	flag.StringVar(&config.otlpProtocol, "otlp-protocol", os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"),
 		"OTLP protocol (defaults to OTEL_EXPORTER_OTLP_PROTOCOL env var)")
+	flag.BoolVar(&config.forceMonitorMode, "force-monitor-mode", false,
+		"Force the policies in protect mode into monitor mode, the policies in audit mode are left untouched")
+	flag.BoolVar(&config.observeOnly, "observe-only", false,
+		"Load the BPF programs so that no exec is ever blocked, whatever the mode of the policies (e.g. for trials)")
+	flag.BoolVar(&config.enforceAfterReadiness, "enforce-after-readiness", false,
+		"Keep the containers of a pod in monitor mode until the pod is Ready for the first time")
+	flag.StringVar(&config.excludedNamespaces, "excluded-namespaces", "",
+		"Comma-separated list of namespaces where no policy is applied, even to pods with the policy label")
+	flag.StringVar(&config.alwaysAllowedExecutables, "always-allowed-executables", "",
+		"Comma-separated list of executables allowed in every policy, regardless of its rules (empty = disabled)")
+	flag.StringVar(&config.lifecycleExecutables, "lifecycle-executables", "",
+		"Comma-separated list of executables allowed in the containers until the kubelet reports them running, "+
+			"e.g. the binaries of their postStart hooks (empty = disabled)")
+	flag.StringVar(&config.globalDenyListFile, "global-deny-list-file", "",
+		"File listing the executables denied in every policy, over its allow list, reloaded when it changes "+
+			"(empty = disabled)")
+	flag.StringVar(&config.violationPodLabels, "violation-pod-labels", "",
+		"Comma-separated list of pod labels added to the violation events as k8s.pod.label.<key> attributes")
+	flag.StringVar(&config.violationSeverities, "violation-severities", "",
+		"Comma-separated list of rule=severity items overriding the severity of the violation events by rule type, "+
+			"e.g. blockScripts=error,executables.allowed:/usr/bin/curl=info")
+	flag.DurationVar(&config.violationEventInterval, "violation-event-interval", 0,
+		"Minimum interval between the Kubernetes events recording the violations of a pod, the violations in between "+
+			"are not recorded (0 = disabled)")
+	flag.BoolVar(&config.metricsExemplars, "metrics-exemplars", false,
+		"Attach the violation event IDs as exemplars to the violation counter, served in the OpenMetrics format on "+
+			openMetricsPath)
+	flag.DurationVar(&config.metricsLogInterval, "metrics-log-interval", 0,
+		"Interval between the logs of a snapshot of the key counters, for clusters without Prometheus (0 = disabled)")
+	flag.BoolVar(&config.selfTest, "self-test", false,
+		"Verify on startup that a denied exec is actually blocked, the agent is not ready until it succeeds")
+	flag.IntVar(&config.violationHistorySize, "violation-history-size", violationbuf.DefaultHistorySize,
+		"Number of recent violations retained in memory for the debug endpoint (0 = disabled)")
+	flag.StringVar(&config.debugBindAddress, "debug-bind-address", "",
+		"The loopback address the debug endpoint binds to, e.g. ':8082' for 127.0.0.1:8082, "+
+			"reachable with kubectl port-forward (empty = disabled)")
+	flag.DurationVar(&config.policyBatchInterval, "policy-batch-interval", 0,
+		"Debounce interval used to apply WorkloadPolicy changes together (0 = disabled)")
+	flag.StringVar(&config.policyFreezeWindows, "policy-freeze-windows", "",
+		"Semicolon-separated list of UTC windows, e.g. 'Mon-Fri 08:00-18:00', "+
+			"during which the updates of the applied WorkloadPolicies are deferred (empty = disabled)")
+	flag.DurationVar(&config.stalePolicyCleanupPeriod, "stale-policy-cleanup-interval", 10*time.Minute,
+		"Interval between the removals of the BPF policy IDs no longer referenced by any policy (0 = only at startup)")
+	flag.DurationVar(&config.coverageMetricsInterval, "coverage-metrics-interval", 30*time.Second,
+		"Interval between the updates of the metrics of the pods covered by a policy (0 = disabled)")
+	flag.StringVar(&config.unresolvedCgroupStrategy, "unresolved-cgroup-strategy",
+		string(eventscraper.UnresolvedCgroupLog),
+		"How to handle the events whose cgroup is not associated with any pod. One of: drop|log|retry")
+	flag.StringVar(&config.unresolvedCgroupLogLevel, "unresolved-cgroup-log-level", "error",
+		"Level of the logs of the events whose cgroup is not associated with any pod")
+	flag.DurationVar(&config.unresolvedCgroupTimeout, "unresolved-cgroup-retry-timeout",
+		eventscraper.DefaultUnresolvedCgroupRetryTimeout,
+		"Time an unresolved event is retried for with the retry strategy")
+	flag.Float64Var(&config.mapUpdateRateLimit, "map-update-rate-limit", 0,
+		"Maximum number of writes per second into the BPF maps, the writes beyond it are delayed (0 = disabled)")
+	flag.IntVar(&config.mapUpdateBurst, "map-update-burst", defaultMapUpdateBurst,
+		"Number of writes into the BPF maps allowed at once above the map update rate limit")
+	flag.StringVar(&config.policyMapFullAction, "policy-map-full-action", string(resolver.MapFullReject),
+		"How to handle a policy that doesn't fit in the BPF maps. One of: reject|evict-lru")
+	flag.IntVar(&config.namespacePolicyIDQuota, "namespace-policy-id-quota", 0,
+		"Maximum number of policy IDs the policies of a namespace can use in the BPF maps (0 = unlimited)")
+	flag.DurationVar(&config.lockHoldWarnThreshold, "lock-hold-warn-threshold", time.Second,
+		"Log a warning when an operation holds the policy state lock longer than this (0 = disabled)")
+	flag.StringVar(&config.devPolicyFiles, "dev-policy-files", "",
+		"Development only: comma-separated list of namespace/policy/container=path items, the allowed executables "+
+			"of each container are read from the file and applied again whenever it changes (empty = disabled)")
+	flag.IntVar(&config.cgroupV1ControllerIdx, "cgroupv1-controller-index", -1,
+		"Index under /proc/cgroups of the cgroupv1 controller used to resolve the cgroups, for the hosts where "+
+			"the detection of the memory controller fails (-1 = detected)")
+	flag.StringVar(&config.cgroupMountPoint, "cgroup-mount-point", "",
+		"Path of the cgroup mount point of the host, for the hosts where it is not visible under "+
+			"/proc/1/root/sys/fs/cgroup (empty = /proc/1/root/sys/fs/cgroup)")
+	flag.DurationVar(&config.heartbeatInterval, "heartbeat-interval", 0,
+		"Interval between the heartbeat events of the node exported to the OTLP endpoint (0 = disabled)")
+	flag.StringVar(&config.terminationRelaxation, "termination-relaxation", string(resolver.TerminationRelaxationNone),
+		"Policies enforced in monitor mode on the terminating pods, so that their preStop hooks are not blocked. "+
+			"One of: none|all|annotated, annotated selects the policies with the "+
+			securityv1alpha1.RelaxOnTerminationAnnotationKey+"=true annotation")
+	flag.DurationVar(&config.policyBindingsInterval, "policy-bindings-interval", 0,
+		"Interval between the updates of the ConfigMap listing the containers of the node each policy is enforced on, "+
+			"the ConfigMap is written only when they changed (0 = disabled)")
+	flag.StringVar(&config.policyBindingsNamespace, "policy-bindings-namespace", os.Getenv("POD_NAMESPACE"),
+		"Namespace of the ConfigMap of the policy bindings")
+	flag.BoolVar(&config.checkServiceAccounts, "check-service-accounts", false,
+		"Watch the service accounts of the pods of the node, required to enforce the policies restricted "+
+			"with allowedServiceAccounts. They are also watched when another option follows the pods")
 	flag.Parse()
+	config.grpcConf.EnforcerVersion = version
 	return config
 }
 
@@ -397,6 +1176,7 @@ func main() {
 	slogger := slog.New(slogHandler).With("component", "agent")
 	slog.SetDefault(slogger)
 	ctrl.SetLogger(logr.FromSlogHandler(slogger.Handler()))
+	slogger.InfoContext(ctx, "starting agent", "version", version)
 
 	var eventShutdown func(context.Context) error
 	if config.otlpEndpoint != "" {
@@ -408,6 +1188,7 @@ func main() {
 			config.otlpClientCert,
 			config.otlpClientKey,
 			config.otlpProtocol,
+			events.ClusterInfo{Name: config.clusterName, Region: config.clusterRegion},
 		)
 		if err != nil {
 			slogger.ErrorContext(ctx, "failed to initiate violation event pipeline", "error", err)
@@ -76,6 +76,15 @@ func parseFlags() Config {
 		"wp-status-reconciler-update-interval",
 		0,
 		"The interval at which the workload policy status reconciler updates the status of WorkloadPolicy resources.")
+	flag.DurationVar(&config.wpStatusSyncConfig.ViolationSummaryInterval,
+		"wp-status-reconciler-violation-summary-interval",
+		0,
+		"The length of the window summarized in the violationSummary status of WorkloadPolicy resources (0 = disabled).")
+	flag.DurationVar(&config.wpStatusSyncConfig.UnobservedExecutablesPeriod,
+		"wp-status-reconciler-unobserved-executables-period",
+		0,
+		"How long an allowed executable must not run on any node to be reported in the unobservedExecutables "+
+			"status of WorkloadPolicy resources (0 = disabled).")
 	flag.StringVar(&config.wpStatusSyncConfig.AgentPoolConf.LabelSelectorString,
 		"wp-status-reconciler-agent-label-selector",
 		grpcexporter.DefaultAgentLabelSelectorString,
@@ -0,0 +1,435 @@
+// Package apparmor converts the exec rules of AppArmor profiles into WorkloadPolicy allow lists.
+//
+// Only a subset of the profile language is supported:
+//   - profile blocks (`profile NAME [ATTACHMENT] {`, `/path/to/binary {`) and their nested
+//     child profiles and hats, whose rules are merged into the same allow list since they
+//     confine processes of the same container;
+//   - file rules with an exec permission (`ix`, `px`, `Px`, `cx`, `Cx`, `ux`, `Ux` and their
+//     fallback variants), in both the `PATH PERMS,` and `PERMS PATH,` forms;
+//   - `deny` file rules, which remove the paths they match from the allow list. A prefix entry cannot
+//     exclude a path, so a prefix covering a denied path is reported as skipped instead of being allowed;
+//   - variables defined in the same file (`@{name} = value ...`) and `{a,b}` alternations,
+//     which are expanded into exact paths;
+//   - rules ending with `/*` or `/**`, which are converted into the `/dir/*` prefix entries of
+//     WorkloadPolicy. Those entries match the subdirectories too, so `/dir/*` is converted into an
+//     entry allowing a little more than the rule.
+//
+// WorkloadPolicy only matches exact paths and prefixes, so rules containing any other glob (`*`, `**`, `?`,
+// `[...]`) after the expansion, as well as includes and undefined variables, are reported as skipped.
+package apparmor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// maxExpandedPaths bounds the number of paths a single rule can expand into.
+	maxExpandedPaths = 256
+	// filePermissions are the characters allowed in the permissions of a file rule.
+	filePermissions = "rwaklmixpucPUC"
+)
+
+// Options describes the WorkloadPolicy generated from a profile.
+type Options struct {
+	// Profile selects the top-level profile to convert. It can be empty if the file contains a single profile.
+	Profile string
+	// Name and Namespace of the generated policy.
+	Name      string
+	Namespace string
+	// ContainerName is the container the profile was applied to.
+	ContainerName string
+	// Mode of the generated policy.
+	Mode string
+}
+
+// SkippedRule is a rule of the profile that cannot be represented in the policy.
+type SkippedRule struct {
+	Line   int
+	Rule   string
+	Reason string
+}
+
+// Result contains the generated policy and the rules that have been skipped.
+type Result struct {
+	Policy  *v1alpha1.WorkloadPolicy
+	Skipped []SkippedRule
+}
+
+//nolint:gochecknoglobals // read-only list of qualifiers.
+var ruleQualifiers = []string{"audit", "allow", "deny", "owner", "file"}
+
+type profile struct {
+	name    string
+	allowed []pathRule
+	denied  []pathRule
+	skipped []SkippedRule
+}
+
+// pathRule is a path, or a `/dir/*` prefix, of an exec rule.
+type pathRule struct {
+	path string
+	line int
+	rule string
+}
+
+// covers returns true if the path or prefix of the rule matches path.
+func (r pathRule) covers(path string) bool {
+	prefix, isPrefix := strings.CutSuffix(r.path, "*")
+	return r.path == path || (isPrefix && strings.HasPrefix(path, prefix))
+}
+
+type parser struct {
+	variables map[string][]string
+	profiles  []*profile
+	// current is the top-level profile being parsed, nil outside of profiles.
+	current *profile
+	depth   int
+}
+
+// Convert parses the profile read from r and returns the WorkloadPolicy allowing its executables.
+func Convert(r io.Reader, opts Options) (*Result, error) {
+	p := &parser{variables: make(map[string][]string)}
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if err := p.parseLine(lineNum, scanner.Text()); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+	if p.depth != 0 {
+		return nil, errors.New("unterminated profile block")
+	}
+
+	prof, err := p.selectProfile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, skipped := prof.allowList()
+
+	return &Result{
+		Policy: &v1alpha1.WorkloadPolicy{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.GroupVersion.String(),
+				Kind:       "WorkloadPolicy",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      opts.Name,
+				Namespace: opts.Namespace,
+			},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode: opts.Mode,
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					opts.ContainerName: {
+						Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: allowed},
+					},
+				},
+			},
+		},
+		Skipped: skipped,
+	}, nil
+}
+
+// allowList returns the paths allowed by the profile once its deny rules are applied.
+// A prefix covering a denied path cannot exclude it, so the prefix is skipped rather than allowing
+// an executable denied by the profile.
+func (prof *profile) allowList() ([]string, []SkippedRule) {
+	skipped := slices.Clone(prof.skipped)
+	allowed := make([]string, 0, len(prof.allowed))
+	for _, rule := range prof.allowed {
+		if slices.ContainsFunc(prof.denied, func(denied pathRule) bool { return denied.covers(rule.path) }) {
+			continue
+		}
+		idx := slices.IndexFunc(prof.denied, func(denied pathRule) bool { return rule.covers(denied.path) })
+		if idx >= 0 {
+			denied := prof.denied[idx]
+			skipped = append(skipped, SkippedRule{
+				Line:   rule.line,
+				Rule:   rule.rule,
+				Reason: fmt.Sprintf("%s cannot exclude %s denied at line %d", rule.path, denied.path, denied.line),
+			})
+			continue
+		}
+		allowed = append(allowed, rule.path)
+	}
+	slices.Sort(allowed)
+	slices.SortStableFunc(skipped, func(a, b SkippedRule) int { return a.Line - b.Line })
+	return slices.Compact(allowed), skipped
+}
+
+func (p *parser) selectProfile(name string) (*profile, error) {
+	if len(p.profiles) == 0 {
+		return nil, errors.New("no profile found")
+	}
+	if name == "" {
+		if len(p.profiles) > 1 {
+			names := make([]string, 0, len(p.profiles))
+			for _, prof := range p.profiles {
+				names = append(names, prof.name)
+			}
+			return nil, fmt.Errorf("multiple profiles found, select one of: %s", strings.Join(names, ", "))
+		}
+		return p.profiles[0], nil
+	}
+	for _, prof := range p.profiles {
+		if prof.name == name {
+			return prof, nil
+		}
+	}
+	return nil, fmt.Errorf("profile %q not found", name)
+}
+
+func (p *parser) parseLine(lineNum int, line string) error {
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "":
+		return nil
+	case strings.HasPrefix(line, "#include") || strings.HasPrefix(line, "include "):
+		// includes outside of profiles usually pull tunables, only report the ones that can add rules.
+		p.skip(lineNum, line, "includes are not resolved")
+		return nil
+	case strings.HasPrefix(line, "#"):
+		return nil
+	}
+	if idx := strings.Index(line, " #"); idx >= 0 {
+		line = strings.TrimSpace(line[:idx])
+	}
+
+	switch {
+	case strings.HasPrefix(line, "@{") && strings.Contains(line, "="):
+		return p.parseVariable(line)
+	case strings.HasSuffix(line, "{"):
+		return p.openBlock(lineNum, strings.TrimSpace(strings.TrimSuffix(line, "{")))
+	case line == "}":
+		if p.depth == 0 {
+			return errors.New("unexpected '}'")
+		}
+		p.depth--
+		if p.depth == 0 {
+			p.current = nil
+		}
+		return nil
+	}
+
+	if p.current == nil {
+		// rules outside of profiles (e.g. `abi <abi/3.0>,`) don't concern executables.
+		return nil
+	}
+	for _, rule := range splitAlternatives(line) {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			p.parseRule(lineNum, rule)
+		}
+	}
+	return nil
+}
+
+func (p *parser) skip(lineNum int, rule, reason string) {
+	if p.current != nil {
+		p.current.skipped = append(p.current.skipped, SkippedRule{Line: lineNum, Rule: rule, Reason: reason})
+	}
+}
+
+func (p *parser) parseVariable(line string) error {
+	name, values, _ := strings.Cut(line, "=")
+	name = strings.TrimSpace(name)
+	appendValues := strings.HasSuffix(name, "+")
+	name = strings.TrimSpace(strings.TrimSuffix(name, "+"))
+	if !strings.HasPrefix(name, "@{") || !strings.HasSuffix(name, "}") {
+		return fmt.Errorf("invalid variable definition %q", line)
+	}
+	fields := strings.Fields(values)
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, `"`)
+	}
+	if appendValues {
+		p.variables[name] = append(p.variables[name], fields...)
+	} else {
+		p.variables[name] = fields
+	}
+	return nil
+}
+
+func (p *parser) openBlock(lineNum int, header string) error {
+	fields := slices.DeleteFunc(strings.Fields(header), func(field string) bool {
+		return strings.HasPrefix(field, "flags=") || strings.HasPrefix(field, "(")
+	})
+	var name, attachment string
+	switch {
+	case len(fields) == 0:
+		return fmt.Errorf("invalid block %q", header)
+	case fields[0] == "profile" || fields[0] == "hat":
+		if len(fields) < 2 {
+			return fmt.Errorf("missing profile name in %q", header)
+		}
+		name = fields[1]
+		if len(fields) > 2 {
+			attachment = fields[2]
+		}
+	case strings.HasPrefix(fields[0], "/") || strings.HasPrefix(fields[0], "@{"):
+		name = fields[0]
+		attachment = fields[0]
+	default:
+		// hats (`^name {`) and conditional blocks don't attach to an executable.
+		name = fields[0]
+	}
+
+	if p.depth == 0 {
+		p.current = &profile{name: name}
+		p.profiles = append(p.profiles, p.current)
+	}
+	p.depth++
+	if attachment != "" {
+		// the executable the profile is attached to is executed itself.
+		p.addPaths(lineNum, header, attachment, false)
+	}
+	return nil
+}
+
+func (p *parser) parseRule(lineNum int, rule string) {
+	fields := strings.Fields(rule)
+	deny := false
+	for len(fields) > 0 && slices.Contains(ruleQualifiers, fields[0]) {
+		deny = deny || fields[0] == "deny"
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		// not a file rule (e.g. `capability,`, `network,`).
+		return
+	}
+
+	path, perms := fields[0], fields[1]
+	if !isPath(path) {
+		path, perms = perms, path
+	}
+	if !isPath(path) || !isFilePermissions(perms) || !strings.Contains(perms, "x") {
+		return
+	}
+	p.addPaths(lineNum, rule, path, deny)
+}
+
+func (p *parser) addPaths(lineNum int, rule, path string, deny bool) {
+	paths, err := p.expand(strings.Trim(path, `"`))
+	if err != nil {
+		p.skip(lineNum, rule, err.Error())
+		return
+	}
+	for _, expanded := range paths {
+		// `/dir/*` and `/dir/**` become the `/dir/*` prefix entry.
+		if strings.HasSuffix(expanded, "/**") {
+			expanded = strings.TrimSuffix(expanded, "*")
+		}
+		isPrefix := strings.HasSuffix(expanded, "/*") && !strings.ContainsAny(strings.TrimSuffix(expanded, "*"), "*?[")
+		switch {
+		case !isPrefix && strings.ContainsAny(expanded, "*?["):
+			p.skip(lineNum, rule, "globs are only supported at the end of a directory, as in /dir/*")
+			return
+		case strings.HasSuffix(expanded, "/"):
+			// directories can't be executed.
+			continue
+		}
+		entry := pathRule{path: expanded, line: lineNum, rule: rule}
+		if deny {
+			p.current.denied = append(p.current.denied, entry)
+		} else {
+			p.current.allowed = append(p.current.allowed, entry)
+		}
+	}
+}
+
+// expand replaces the variables and the alternations of path with all their values.
+func (p *parser) expand(path string) ([]string, error) {
+	if start := strings.Index(path, "@{"); start >= 0 {
+		end := strings.Index(path[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated variable in %q", path)
+		}
+		name := path[start : start+end+1]
+		values, ok := p.variables[name]
+		if !ok {
+			return nil, fmt.Errorf("undefined variable %s", name)
+		}
+		return p.expandWith(path[:start], values, path[start+end+1:])
+	}
+	if start := strings.Index(path, "{"); start >= 0 {
+		end := matchingBrace(path, start)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated alternation in %q", path)
+		}
+		return p.expandWith(path[:start], splitAlternatives(path[start+1:end]), path[end+1:])
+	}
+	return []string{path}, nil
+}
+
+func (p *parser) expandWith(prefix string, values []string, suffix string) ([]string, error) {
+	var paths []string
+	for _, value := range values {
+		expanded, err := p.expand(prefix + value + suffix)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, expanded...)
+		if len(paths) > maxExpandedPaths {
+			return nil, fmt.Errorf("rule expands to more than %d paths", maxExpandedPaths)
+		}
+	}
+	return paths, nil
+}
+
+// matchingBrace returns the index of the brace closing the one at start, -1 if there is none.
+func matchingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitAlternatives splits s on the commas that are not inside an alternation,
+// it is used both for the content of alternations and for the rules of a line.
+func splitAlternatives(s string) []string {
+	var alternatives []string
+	depth, last := 0, 0
+	for i := range len(s) {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alternatives = append(alternatives, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(alternatives, s[last:])
+}
+
+func isPath(s string) bool {
+	s = strings.TrimPrefix(s, `"`)
+	return strings.HasPrefix(s, "/") || strings.HasPrefix(s, "@{")
+}
+
+func isFilePermissions(s string) bool {
+	return s != "" && strings.Trim(s, filePermissions) == ""
+}
@@ -0,0 +1,152 @@
+package apparmor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+const nginxProfile = `
+#include <tunables/global>
+
+@{nginx_bins} = /usr/sbin/nginx /usr/local/sbin/nginx
+
+profile nginx-app /usr/sbin/nginx flags=(attach_disconnected,mediate_deleted) {
+  #include <abstractions/base>
+
+  capability net_bind_service,
+  network inet tcp,
+
+  /etc/nginx/** r,
+  /var/log/nginx/* w,
+  @{nginx_bins} ix,
+  /usr/bin/{cat,ls} rix, # used by the entrypoint
+  ix /bin/sh,
+  audit deny /usr/bin/ls x,
+  /usr/lib/nginx/modules/*.so mr,
+  /usr/bin/* ix,
+  owner @{HOME}/bin/tool ix,
+  /opt/app/** ix,
+  /opt/*/bin/tool ix,
+  deny /usr/local/sbin/** x,
+
+  profile helper /usr/bin/helper {
+    /usr/bin/env Px -> nginx-app,
+  }
+}
+`
+
+func TestConvert(t *testing.T) {
+	res, err := Convert(strings.NewReader(nginxProfile), Options{
+		Name:          "nginx",
+		Namespace:     "web",
+		ContainerName: "nginx",
+		Mode:          "monitor",
+	})
+	require.NoError(t, err)
+
+	policy := res.Policy
+	require.Equal(t, "security.rancher.io/v1alpha1", policy.APIVersion)
+	require.Equal(t, "WorkloadPolicy", policy.Kind)
+	require.Equal(t, "nginx", policy.Name)
+	require.Equal(t, "web", policy.Namespace)
+	require.Equal(t, "monitor", policy.Spec.Mode)
+	require.Equal(t, map[string]*v1alpha1.WorkloadPolicyRules{
+		"nginx": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{
+			"/bin/sh",
+			"/opt/app/*",
+			"/usr/bin/cat",
+			"/usr/bin/env",
+			"/usr/bin/helper",
+			"/usr/sbin/nginx",
+		}}},
+	}, policy.Spec.RulesByContainer)
+
+	require.Equal(t, []SkippedRule{
+		{Line: 7, Rule: "#include <abstractions/base>", Reason: "includes are not resolved"},
+		{Line: 19, Rule: "/usr/bin/* ix", Reason: "/usr/bin/* cannot exclude /usr/bin/ls denied at line 17"},
+		{Line: 20, Rule: "owner @{HOME}/bin/tool ix", Reason: "undefined variable @{HOME}"},
+		{Line: 22, Rule: "/opt/*/bin/tool ix", Reason: "globs are only supported at the end of a directory, as in /dir/*"},
+	}, res.Skipped)
+}
+
+func TestConvertSelectProfile(t *testing.T) {
+	const profiles = `
+/usr/bin/first {
+  /usr/bin/true ix,
+}
+
+profile second {
+  /usr/bin/false ix,
+}
+`
+	_, err := Convert(strings.NewReader(profiles), Options{ContainerName: "app"})
+	require.ErrorContains(t, err, "multiple profiles found, select one of: /usr/bin/first, second")
+
+	res, err := Convert(strings.NewReader(profiles), Options{Profile: "second", ContainerName: "app"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/usr/bin/false"}, res.Policy.Spec.RulesByContainer["app"].Executables.Allowed)
+	require.Empty(t, res.Skipped)
+
+	_, err = Convert(strings.NewReader(profiles), Options{Profile: "third", ContainerName: "app"})
+	require.ErrorContains(t, err, `profile "third" not found`)
+}
+
+func TestConvertDenyUnderPrefix(t *testing.T) {
+	const profile = `
+profile app {
+  /usr/bin/* ix,
+  deny /usr/bin/sudo x,
+  /usr/local/bin/** ix,
+  /usr/sbin/tool ix,
+  deny /usr/sbin/* x,
+}
+`
+	res, err := Convert(strings.NewReader(profile), Options{ContainerName: "app"})
+	require.NoError(t, err)
+	// the prefix would allow the denied executable, it is skipped, while the paths under a denied prefix are removed.
+	require.Equal(t, []string{"/usr/local/bin/*"}, res.Policy.Spec.RulesByContainer["app"].Executables.Allowed)
+	require.Equal(t, []SkippedRule{
+		{Line: 3, Rule: "/usr/bin/* ix", Reason: "/usr/bin/* cannot exclude /usr/bin/sudo denied at line 4"},
+	}, res.Skipped)
+}
+
+func TestConvertInvalidProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		err     string
+	}{
+		{name: "empty", profile: "# nothing here\n", err: "no profile found"},
+		{name: "unterminated", profile: "profile app {\n  /usr/bin/true ix,\n", err: "unterminated profile block"},
+		{name: "unexpected brace", profile: "}\n", err: "line 1: unexpected '}'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Convert(strings.NewReader(tt.profile), Options{ContainerName: "app"})
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	p := &parser{variables: map[string][]string{
+		"@{bins}": {"/usr/bin", "/bin"},
+	}}
+
+	paths, err := p.expand("@{bins}/{ba,da,}sh")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"/usr/bin/bash", "/usr/bin/dash", "/usr/bin/sh",
+		"/bin/bash", "/bin/dash", "/bin/sh",
+	}, paths)
+
+	paths, err = p.expand("/usr/bin/{python3{,.12},pip}")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/usr/bin/python3", "/usr/bin/python3.12", "/usr/bin/pip"}, paths)
+
+	_, err = p.expand("/usr/bin/{a,b")
+	require.ErrorContains(t, err, "unterminated alternation")
+}
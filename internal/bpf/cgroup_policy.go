@@ -138,8 +138,33 @@ func (m *Manager) updateCgroupPolicy(targetPolID uint64, cgroupIDs []uint64, op
 	case RemovePolicy:
 		return removePolicyFromCgroups(cgToPol, targetPolID)
 	case RemoveCgroups:
-		return removeCgroups(cgToPol, targetPolID, cgroupIDs)
+		// the distinct executables run by the removed containers are not counted anymore.
+		return errors.Join(removeCgroups(cgToPol, targetPolID, cgroupIDs), m.resetDistinctExecCounts(cgroupIDs))
 	default:
 		panic("unknown operation")
 	}
 }
+
+// lookupCgroupPolicy returns the policy ID the cgroup is attached to, found is false when it is not in the map.
+func (m *Manager) lookupCgroupPolicy(cgID uint64) (uint64, bool, error) {
+	cgToPol := m.objs.CgToPolicyMap
+	if cgToPol == nil {
+		return 0, false, errors.New("cgroup to policy map is nil")
+	}
+	var polID uint64
+	if err := cgToPol.Lookup(&cgID, &polID); err != nil {
+		if errors.Is(err, ebpf.ErrKeyNotExist) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to lookup cgroup %d: %w", cgID, err)
+	}
+	return polID, true, nil
+}
+
+// GetCgroupPolicyLookupFunc exposes a function used to read the policy ID a cgroup is attached to in the BPF map.
+func (m *Manager) GetCgroupPolicyLookupFunc() func(cgID uint64) (uint64, bool, error) {
+	return func(cgID uint64) (uint64, bool, error) {
+		polID, found, err := m.lookupCgroupPolicy(cgID)
+		return polID, found, m.handleErrOnShutdown(err)
+	}
+}
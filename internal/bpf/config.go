@@ -7,13 +7,14 @@ import (
 	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
 )
 
-func getLoadTimeConfig(logger *slog.Logger, enableLearning bool) (*bpfLoadConf, error) {
+func getLoadTimeConfig(logger *slog.Logger, enableLearning, observeOnly bool) (*bpfLoadConf, error) {
 	cgInfo, err := cgroups.GetCgroupInfo()
 	if err != nil {
 		return nil, fmt.Errorf("cannot get cgroup info: %w", err)
 	}
 
 	logger.Info("cgroup info detected",
+		"mode", cgInfo.CgroupMode().String(),
 		"fs_magic", cgInfo.CgroupFsMagicString(),
 		"v1_subsys_idx", cgInfo.CgroupV1SubsysIdx(),
 		"resolution_path", cgInfo.CgroupResolutionPrefix(),
@@ -23,12 +24,17 @@ func getLoadTimeConfig(logger *slog.Logger, enableLearning bool) (*bpfLoadConf,
 	if enableLearning {
 		learningEnabled = 1
 	}
+	var observeOnlyEnabled uint8
+	if observeOnly {
+		observeOnlyEnabled = 1
+	}
 
 	config := &bpfLoadConf{
 		CgrpFsMagic:     cgInfo.CgroupFsMagic(),
 		Cgrpv1SubsysIdx: cgInfo.CgroupV1SubsysIdx(),
 		DebugMode:       0, // disable debug mode for now
 		LearningEnabled: learningEnabled,
+		ObserveOnly:     observeOnlyEnabled,
 	}
 
 	logger.Info("bpf load config",
@@ -36,6 +42,7 @@ func getLoadTimeConfig(logger *slog.Logger, enableLearning bool) (*bpfLoadConf,
 		"v1_subsys_idx", config.Cgrpv1SubsysIdx,
 		"debug_mode", config.DebugMode,
 		"learning_enabled", config.LearningEnabled,
+		"observe_only", config.ObserveOnly,
 	)
 	return config, nil
 }
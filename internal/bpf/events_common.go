@@ -6,7 +6,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"net/netip"
+	"strings"
 
+	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/link"
 	"github.com/cilium/ebpf/ringbuf"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
@@ -31,10 +34,10 @@ func (mod mode) String() string {
 }
 
 func (m *Manager) setupEventConsumer(ctx context.Context, mod mode) error {
-	var progLink link.Link
+	var progLinks []link.Link
 	defer func() {
 		m.logger.InfoContext(ctx, "stopped consumer", "mode", mod.String())
-		if progLink != nil {
+		for _, progLink := range progLinks {
 			if err := progLink.Close(); err != nil {
 				m.logger.ErrorContext(ctx, "closing program link", "error", err, "mode", mod.String())
 			}
@@ -47,12 +50,28 @@ func (m *Manager) setupEventConsumer(ctx context.Context, mod mode) error {
 		outChan = m.monitoringEventChan
 		buf = m.objs.RingbufMonitoring
 
-		var err error
-		progLink, err = link.AttachTracing(link.TracingOptions{
-			Program: m.objs.EnforceCgroupPolicy,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to attach %s prog: %w", m.objs.EnforceCgroupPolicy.String(), err)
+		for _, prog := range []*ebpf.Program{
+			m.objs.EnforceCgroupPolicy, m.objs.EnforceScriptExec, m.objs.EnforceFileOpen,
+			m.objs.EnforceSocketConnect,
+		} {
+			progLink, err := link.AttachTracing(link.TracingOptions{
+				Program: prog,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to attach %s prog: %w", prog.String(), err)
+			}
+			progLinks = append(progLinks, progLink)
+		}
+		// the arguments of the execs are recorded for the policies with approved commands.
+		for name, prog := range map[string]*ebpf.Program{
+			"sys_enter_execve":   m.objs.RecordExecveArgv,
+			"sys_enter_execveat": m.objs.RecordExecveatArgv,
+		} {
+			progLink, err := link.Tracepoint("syscalls", name, prog, nil)
+			if err != nil {
+				return fmt.Errorf("failed to attach %s prog: %w", prog.String(), err)
+			}
+			progLinks = append(progLinks, progLink)
 		}
 	}
 
@@ -75,6 +94,7 @@ func (m *Manager) processRingbufEvents(ctx context.Context, rd *ringbuf.Reader,
 		}
 	}()
 
+	var sequence uint64
 	for {
 		record, err := rd.Read()
 		if err != nil {
@@ -106,15 +126,42 @@ func (m *Manager) processRingbufEvents(ctx context.Context, rd *ringbuf.Reader,
 			continue
 		}
 
+		// the arguments follow the NUL terminator of the path, each one terminated by a NUL byte.
+		// The egress violations carry the destination of the connection instead.
+		var args []string
+		var destination netip.AddrPort
+		if header.ArgsLen > 0 {
+			argsBytes := make([]byte, 1+int(header.ArgsLen))
+			if _, err = buf.Read(argsBytes); err != nil {
+				m.logger.ErrorContext(ctx, "reading args bytes", "error", err)
+				continue
+			}
+			if ViolationReason(header.Reason) == ViolationReasonEgressNotAllowed {
+				if destination, err = parseEgressDestination(argsBytes[1:]); err != nil {
+					m.logger.ErrorContext(ctx, "parsing egress destination", "error", err)
+				}
+			} else {
+				args = strings.Split(strings.TrimSuffix(string(argsBytes[1:]), "\x00"), "\x00")
+			}
+		}
+
 		modeString := ""
 		// 0 is the value we receive in learning mode, meaning "not set".
 		if header.Mode != 0 {
 			modeString = policymode.FromUint8(header.Mode).String()
 		}
+		sequence++
 		out <- ProcessEvent{
 			CgTrackerID: header.CgTrackerID,
 			Mode:        modeString,
 			ExePath:     string(pathBytes),
+			Reason:      ViolationReason(header.Reason),
+			FileMode:    header.FileMode,
+			Tgid:        header.Tgid,
+			Args:        args,
+			FsMagic:     header.FsMagic,
+			Destination: destination,
+			Sequence:    sequence,
 		}
 	}
 }
@@ -0,0 +1,112 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cilium/ebpf"
+)
+
+// MaxGlobalDenyLength is the maximum length of an entry of the global deny list, mirroring GLOBAL_DENY_MAX_LEN of
+// the BPF program minus the NUL terminator stored with the entry.
+const MaxGlobalDenyLength = 251
+
+const (
+	globalDenyPath   uint32 = 0
+	globalDenyName   uint32 = 1
+	globalDenyFolded uint32 = 1 << 1
+)
+
+// globalDenyKindBits is the length in bits of the kind leading the keys of the global deny map.
+const globalDenyKindBits = 32
+
+// globalDenyKey mirrors `struct global_deny_key` of the BPF program.
+type globalDenyKey struct {
+	PrefixLen uint32
+	Kind      uint32
+	Value     [MaxGlobalDenyLength + 1]byte
+}
+
+// newGlobalDenyKey returns the key of an entry of the global deny list: an entry starting with `/` denies that
+// path, any other entry denies the executables with that name in any directory. With folded the entry is folded
+// with FoldPathCase, to be matched by the policies ignoring the case.
+func newGlobalDenyKey(entry string, folded bool) (globalDenyKey, error) {
+	key := globalDenyKey{Kind: globalDenyName}
+	if strings.HasPrefix(entry, "/") {
+		key.Kind = globalDenyPath
+	}
+	if len(entry) > MaxGlobalDenyLength {
+		return key, fmt.Errorf("global deny list entry %s is longer than %d bytes", entry, MaxGlobalDenyLength)
+	}
+	if folded {
+		key.Kind |= globalDenyFolded
+		entry = FoldPathCase(entry)
+	}
+	copy(key.Value[:], entry)
+	// the NUL terminator is part of the prefix, so that the entry matches only itself.
+	key.PrefixLen = uint32(globalDenyKindBits + 8*(len(entry)+1)) //nolint:gosec // the entry length is bounded above
+	return key, nil
+}
+
+// entry returns the entry of the global deny list stored in the key.
+func (k globalDenyKey) entry() string {
+	return string(k.Value[:(int(k.PrefixLen)-globalDenyKindBits)/8-1])
+}
+
+// globalDenyKeys returns the keys of the global deny map.
+func (m *Manager) globalDenyKeys() ([]globalDenyKey, error) {
+	var keys []globalDenyKey
+	var key, next globalDenyKey
+	err := m.objs.GlobalDenyMap.NextKey(nil, &next)
+	for err == nil {
+		keys = append(keys, next)
+		key = next
+		err = m.objs.GlobalDenyMap.NextKey(&key, &next)
+	}
+	if !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return nil, fmt.Errorf("failed to iterate map %s: %w", m.objs.GlobalDenyMap.String(), err)
+	}
+	return keys, nil
+}
+
+// replaceGlobalDenyList writes the new entries before deleting the stale ones,
+// so that the executables denied by both the old and the new list are never allowed meanwhile.
+func (m *Manager) replaceGlobalDenyList(entries []string) error {
+	keys := make(map[globalDenyKey]struct{}, 2*len(entries))
+	for _, entry := range entries {
+		for _, folded := range []bool{false, true} {
+			key, err := newGlobalDenyKey(entry, folded)
+			if err != nil {
+				return err
+			}
+			if err = m.objs.GlobalDenyMap.Update(&key, uint8(1), ebpf.UpdateAny); err != nil {
+				return fmt.Errorf("failed to update map %s with entry %s: %w",
+					m.objs.GlobalDenyMap.String(), entry, wrapMapFullErr(err))
+			}
+			keys[key] = struct{}{}
+		}
+	}
+	current, err := m.globalDenyKeys()
+	if err != nil {
+		return err
+	}
+	for _, key := range current {
+		if _, ok := keys[key]; ok {
+			continue
+		}
+		if err = m.objs.GlobalDenyMap.Delete(&key); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf("failed to delete entry %s from map %s: %w",
+				key.entry(), m.objs.GlobalDenyMap.String(), err)
+		}
+	}
+	return nil
+}
+
+// GetGlobalDenyUpdateFunc returns the function replacing the global deny list, the executables denied in every
+// policy whatever its allow list.
+func (m *Manager) GetGlobalDenyUpdateFunc() func(entries []string) error {
+	return func(entries []string) error {
+		return m.handleErrOnShutdown(m.replaceGlobalDenyList(entries))
+	}
+}
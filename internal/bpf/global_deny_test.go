@@ -0,0 +1,28 @@
+package bpf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGlobalDenyKey(t *testing.T) {
+	key, err := newGlobalDenyKey("/usr/bin/NC", false)
+	require.NoError(t, err)
+	require.Equal(t, globalDenyPath, key.Kind)
+	require.Equal(t, uint32(32+8*len("/usr/bin/NC\x00")), key.PrefixLen)
+	require.Equal(t, "/usr/bin/NC", key.entry())
+
+	key, err = newGlobalDenyKey("XMRig", true)
+	require.NoError(t, err)
+	require.Equal(t, globalDenyName|globalDenyFolded, key.Kind)
+	require.Equal(t, "xmrig", key.entry())
+
+	key, err = newGlobalDenyKey("/"+strings.Repeat("a", MaxGlobalDenyLength-1), false)
+	require.NoError(t, err)
+	require.Len(t, key.entry(), MaxGlobalDenyLength)
+
+	_, err = newGlobalDenyKey("/"+strings.Repeat("a", MaxGlobalDenyLength), false)
+	require.ErrorContains(t, err, "longer than 251 bytes")
+}
@@ -19,6 +19,10 @@ const (
 	suppressionMsg           = "logs suppressed by rate limiting"
 	policyModeMissingMessage = "policy mode missing"
 
+	// logReaderBaseDelay and logReaderMaxDelay bound the delay before reopening a failed reader of the logs.
+	logReaderBaseDelay = 100 * time.Millisecond
+	logReaderMaxDelay  = 30 * time.Second
+
 	// Log keys.
 	msgLogKey             = "msg"
 	cpuLogKey             = "cpu"
@@ -30,8 +34,43 @@ const (
 	modeLogKey            = "mode"
 	suppressedCountLogKey = "count"
 	suppressedLogTypeKey  = "log_type"
+	namespaceLogKey       = "namespace"
+	podLogKey             = "pod"
+	containerLogKey       = "container"
+	policyLogKey          = "policy"
+	// the policy ID resolved from the tracker ID, it can differ from the one reported by BPF in policy_id.
+	resolvedPolicyIDLogKey = "resolved_policy_id"
 )
 
+// WorkloadContext is the workload a cgroup tracker ID belongs to.
+type WorkloadContext struct {
+	Namespace string
+	Pod       string
+	Container string
+	// Policy and PolicyID are empty when no policy is enforced on the container.
+	Policy   string
+	PolicyID uint64
+}
+
+// WorkloadContextFunc resolves a cgroup tracker ID to its workload, it returns false if the ID is unknown.
+type WorkloadContextFunc func(cgTrackerID uint64) (WorkloadContext, bool)
+
+type workloadAttrsKey struct{}
+
+// withWorkloadAttrs stores in ctx the attributes of the workload that originated a log event,
+// so that they are only added to the logs of the event itself.
+func withWorkloadAttrs(ctx context.Context, wc WorkloadContext) context.Context {
+	attrs := []any{
+		namespaceLogKey, wc.Namespace,
+		podLogKey, wc.Pod,
+		containerLogKey, wc.Container,
+	}
+	if wc.Policy != "" {
+		attrs = append(attrs, policyLogKey, wc.Policy, resolvedPolicyIDLogKey, wc.PolicyID)
+	}
+	return context.WithValue(ctx, workloadAttrsKey{}, attrs)
+}
+
 type logRateLimiter struct {
 	limiter    *rate.Limiter
 	suppressed int64
@@ -56,6 +95,7 @@ func (l *logRateLimiter) logEvent(ctx context.Context,
 	additionalArgs ...any) {
 	if !l.limiter.Allow() {
 		l.suppressed++
+		logsSuppressedTotal.WithLabelValues(msg).Inc()
 		return
 	}
 
@@ -95,6 +135,9 @@ func logEvent(
 		commLogKey, getComm(evt),
 		cgroupTrackerIDLogKey, evt.CgTrackerId,
 	}
+	if workloadAttrs, ok := ctx.Value(workloadAttrsKey{}).([]any); ok {
+		attrs = append(attrs, workloadAttrs...)
+	}
 	attrs = append(attrs, additionalArgs...)
 	logger.Log(ctx, level, msg, attrs...)
 }
@@ -114,6 +157,7 @@ func logEventMsg(ctx context.Context, logger *slog.Logger, evt *bpfLogEvt) {
 	case bpfLogEventCodeLOG_FAIL_TO_COPY_EXEC_PATH:
 		logEvent(ctx, logger, evt, "failed to copy exec path", slog.LevelError)
 	case bpfLogEventCodeLOG_DROP_EXEC_EVENT:
+		droppedEventsTotal.WithLabelValues(droppedEventExec).Inc()
 		dropExecLimiter.logEvent(ctx, logger, evt, "dropped exec event", slog.LevelWarn)
 	case bpfLogEventCodeLOG_PATH_LEN_TOO_LONG:
 		logEvent(ctx, logger, evt, "path length too long", slog.LevelWarn)
@@ -124,6 +168,7 @@ func logEventMsg(ctx context.Context, logger *slog.Logger, evt *bpfLogEvt) {
 	case bpfLogEventCodeLOG_DROP_VIOLATION:
 		// arg1 is the policy ID
 		// arg2 is the mode
+		droppedEventsTotal.WithLabelValues(droppedEventViolation).Inc()
 		dropViolationLimiter.logEvent(ctx, logger, evt, "dropped violation event", slog.LevelWarn,
 			policyIDLogKey, evt.Arg1,
 			modeLogKey, evt.Arg2)
@@ -136,29 +181,76 @@ func logEventMsg(ctx context.Context, logger *slog.Logger, evt *bpfLogEvt) {
 	}
 }
 
+// logRecordReader reads the records of the ring buffer of the BPF logs, it is implemented by *ringbuf.Reader.
+type logRecordReader interface {
+	Read() (ringbuf.Record, error)
+	Close() error
+}
+
+// openLogReader opens the reader of the ring buffer of the BPF logs.
+func (m *Manager) openLogReader() (logRecordReader, error) {
+	if m.logReaderOpener != nil {
+		return m.logReaderOpener()
+	}
+	return ringbuf.NewReader(m.objs.RingbufLogs)
+}
+
+// loggerStart delivers the logs of the BPF programs until ctx is done.
+// The delivery is supervised: when the reader can't be opened or fails, the error is logged and the reader is
+// reopened with an exponential backoff, from logReaderBaseDelay to logReaderMaxDelay. The logs are not needed
+// to enforce the policies, so a failure never stops the manager.
 func (m *Manager) loggerStart(ctx context.Context) error {
-	buf := m.objs.RingbufLogs
-	rd, err := ringbuf.NewReader(buf)
+	delay := logReaderBaseDelay
+	for {
+		delivered, err := m.deliverLogs(ctx)
+		if err == nil {
+			return nil
+		}
+		if delivered {
+			// the reader worked for a while, this is a new failure.
+			delay = logReaderBaseDelay
+		}
+		m.logger.ErrorContext(ctx, "BPF log delivery interrupted, reopening the ringbuf reader",
+			"error", err,
+			"delay", delay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, logReaderMaxDelay)
+	}
+}
+
+// deliverLogs reads the logs of the BPF programs until ctx is done, then it returns a nil error, or until the
+// reader fails. It reports whether at least one log has been delivered.
+func (m *Manager) deliverLogs(ctx context.Context) (bool, error) {
+	rd, err := m.openLogReader()
 	if err != nil {
-		return fmt.Errorf("opening %s ringbuf reader: %w", buf.String(), err)
+		return false, fmt.Errorf("opening logs ringbuf reader: %w", err)
 	}
 
+	stop := make(chan struct{})
+	defer close(stop)
 	go func() {
-		<-ctx.Done()
-		if err = rd.Close(); err != nil {
-			m.logger.ErrorContext(ctx, "closing ringbuf reader", "error", err)
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
+		if closeErr := rd.Close(); closeErr != nil {
+			m.logger.ErrorContext(ctx, "closing ringbuf reader", "error", closeErr)
 		}
 	}()
 
-	var record ringbuf.Record
+	delivered := false
 	for {
-		record, err = rd.Read()
-		if err != nil {
-			if errors.Is(err, ringbuf.ErrClosed) {
+		record, readErr := rd.Read()
+		if readErr != nil {
+			if errors.Is(readErr, ringbuf.ErrClosed) && ctx.Err() != nil {
 				m.logger.InfoContext(ctx, "ringbuf reader closed")
-				return nil
+				return delivered, nil
 			}
-			return fmt.Errorf("reading from reader: %w", err)
+			return delivered, fmt.Errorf("reading from reader: %w", readErr)
 		}
 
 		buf := bytes.NewBuffer(record.RawSample)
@@ -167,6 +259,28 @@ func (m *Manager) loggerStart(ctx context.Context) error {
 			m.logger.ErrorContext(ctx, "parsing ringbuf event", "error", err)
 			continue
 		}
-		logEventMsg(ctx, m.logger, &evt)
+		delivered = true
+		logEventMsg(m.withWorkloadContext(ctx, &evt), m.logger, &evt)
+		if evt.Code == bpfLogEventCodeLOG_POLICY_MODE_MISSING {
+			// arg1 is the policy ID
+			m.notifyPolicyModeMissing(evt.Arg1)
+		}
+	}
+}
+
+// SetWorkloadContextFunc sets the function used to add the workload context to the log events
+// of the BPF programs. It must be called before Start.
+func (m *Manager) SetWorkloadContextFunc(f WorkloadContextFunc) {
+	m.workloadContextFunc = f
+}
+
+func (m *Manager) withWorkloadContext(ctx context.Context, evt *bpfLogEvt) context.Context {
+	if m.workloadContextFunc == nil || evt.CgTrackerId == 0 {
+		return ctx
+	}
+	wc, ok := m.workloadContextFunc(evt.CgTrackerId)
+	if !ok {
+		return ctx
 	}
+	return withWorkloadAttrs(ctx, wc)
 }
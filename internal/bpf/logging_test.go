@@ -1,14 +1,21 @@
 package bpf
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/cilium/ebpf/ringbuf"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/time/rate"
 )
@@ -78,6 +85,8 @@ func TestLogRateLimiter(t *testing.T) {
 	for range 100 {
 		rateLimiter.logEvent(t.Context(), logger, &bpfLogEvt{}, exampleMsg, slog.LevelInfo)
 	}
+	// the first event is logged, the others are counted as suppressed.
+	require.InDelta(t, 99, promtestutil.ToFloat64(logsSuppressedTotal.WithLabelValues(exampleMsg)), 0)
 
 	// We wait until there is a new token available
 	require.Eventually(t, func() bool {
@@ -133,4 +142,150 @@ func TestLogMissingPolicyMode(t *testing.T) {
 		policyIDLogKey:        strconv.FormatUint(mockPolicyID, 10),
 		cgroupTrackerIDLogKey: strconv.FormatUint(runner.cgInfo.id, 10),
 	})
+
+	// the policy ID is notified so that the resolver can repair its mode.
+	select {
+	case policyID := <-runner.manager.GetPolicyModeMissingChannel():
+		require.Equal(t, mockPolicyID, policyID)
+	case <-time.After(time.Second):
+		require.Fail(t, "the missing policy mode has not been notified")
+	}
+}
+
+func TestLogWorkloadContext(t *testing.T) {
+	memoryWriter := &memoryWriter{}
+	logger := slog.New(slog.NewJSONHandler(memoryWriter, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	})).With("component", "logging_test")
+
+	m := &Manager{logger: logger}
+	m.SetWorkloadContextFunc(func(cgTrackerID uint64) (WorkloadContext, bool) {
+		if cgTrackerID != 42 {
+			return WorkloadContext{}, false
+		}
+		return WorkloadContext{
+			Namespace: "default",
+			Pod:       "pod1",
+			Container: "main",
+			Policy:    "example",
+			PolicyID:  7,
+		}, true
+	})
+
+	known := &bpfLogEvt{Code: bpfLogEventCodeLOG_FAIL_TO_RESOLVE_PATH, CgTrackerId: 42}
+	logEventMsg(m.withWorkloadContext(t.Context(), known), logger, known)
+	memoryWriter.assertHasLogWithFields(t, map[string]string{
+		msgLogKey:              "failed to resolve path",
+		cgroupTrackerIDLogKey:  "42",
+		namespaceLogKey:        "default",
+		podLogKey:              "pod1",
+		containerLogKey:        "main",
+		policyLogKey:           "example",
+		resolvedPolicyIDLogKey: "7",
+	})
+
+	// the events of unknown tracker IDs are logged without any workload context.
+	unknown := &bpfLogEvt{Code: bpfLogEventCodeLOG_EMPTY_PATH, CgTrackerId: 43}
+	logEventMsg(m.withWorkloadContext(t.Context(), unknown), logger, unknown)
+	memoryWriter.assertHasLogWithFields(t, map[string]string{
+		msgLogKey:             "empty path detected",
+		cgroupTrackerIDLogKey: "43",
+	})
+	memoryWriter.mu.Lock()
+	defer memoryWriter.mu.Unlock()
+	require.False(t, memoryWriter.hasLogWithFields(map[string]string{
+		cgroupTrackerIDLogKey: "43",
+		podLogKey:             "pod1",
+	}))
+}
+
+// fakeLogReader returns its records, then its error if any, otherwise it blocks until it is closed.
+type fakeLogReader struct {
+	records   []ringbuf.Record
+	err       error
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newFakeLogReader(t *testing.T, err error, events ...bpfLogEvt) *fakeLogReader {
+	rd := &fakeLogReader{err: err, closed: make(chan struct{})}
+	for _, evt := range events {
+		var buf bytes.Buffer
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, &evt))
+		rd.records = append(rd.records, ringbuf.Record{RawSample: buf.Bytes()})
+	}
+	return rd
+}
+
+func (r *fakeLogReader) Read() (ringbuf.Record, error) {
+	if len(r.records) > 0 {
+		record := r.records[0]
+		r.records = r.records[1:]
+		return record, nil
+	}
+	if r.err != nil {
+		return ringbuf.Record{}, r.err
+	}
+	<-r.closed
+	return ringbuf.Record{}, ringbuf.ErrClosed
+}
+
+func (r *fakeLogReader) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+	return nil
+}
+
+func TestLoggerRecoversFromReaderErrors(t *testing.T) {
+	memoryWriter := &memoryWriter{}
+	logger := slog.New(slog.NewJSONHandler(memoryWriter, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	})).With("component", "logging_test")
+
+	// the first reader fails after a log, reopening it fails once, then the next reader works.
+	readers := []func() (logRecordReader, error){
+		func() (logRecordReader, error) {
+			return newFakeLogReader(t, errors.New("transient read error"),
+				bpfLogEvt{Code: bpfLogEventCodeLOG_EMPTY_PATH, CgTrackerId: 1}), nil
+		},
+		func() (logRecordReader, error) {
+			return nil, errors.New("transient open error")
+		},
+		func() (logRecordReader, error) {
+			return newFakeLogReader(t, nil, bpfLogEvt{Code: bpfLogEventCodeLOG_EMPTY_PATH, CgTrackerId: 2}), nil
+		},
+	}
+	var opened atomic.Int32
+	m := &Manager{logger: logger}
+	m.logReaderOpener = func() (logRecordReader, error) {
+		return readers[opened.Add(1)-1]()
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() {
+		done <- m.loggerStart(ctx)
+	}()
+
+	memoryWriter.assertHasLogWithFields(t, map[string]string{
+		msgLogKey:             "empty path detected",
+		cgroupTrackerIDLogKey: "1",
+	})
+	memoryWriter.assertHasLogWithFields(t, map[string]string{
+		msgLogKey: "BPF log delivery interrupted, reopening the ringbuf reader",
+		"error":   "reading from reader: transient read error",
+	})
+	// the delivery resumes with the reader reopened after the failures.
+	memoryWriter.assertHasLogWithFields(t, map[string]string{
+		msgLogKey:             "empty path detected",
+		cgroupTrackerIDLogKey: "2",
+	})
+	require.Equal(t, int32(3), opened.Load())
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "the logger has not stopped")
+	}
 }
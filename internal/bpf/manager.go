@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/netip"
 	"os"
 	"sync"
 	"sync/atomic"
@@ -32,19 +33,77 @@ const (
 	// 100 should be enough to avoid blocking in normal conditions, let's monitor this later.
 	learningEventChanSize = 100
 	monitorEventChanSize  = 100
+	// policyModeMissingChanSize bounds the pending notifications, the others are dropped
+	// since the same policy ID is usually reported by every exec until it is repaired.
+	policyModeMissingChanSize = 16
+)
+
+// ViolationReason mirrors the VIOLATION_REASON_* values of the BPF program.
+type ViolationReason uint8
+
+const (
+	// ViolationReasonNone is used for learning events and for the allowed execs of traced containers.
+	ViolationReasonNone ViolationReason = iota
+	// ViolationReasonExecNotAllowed is used when the executable is not in the allow list.
+	ViolationReasonExecNotAllowed
+	// ViolationReasonSuidExec is used when a setuid/setgid executable is run under a policy blocking them.
+	ViolationReasonSuidExec
+	// ViolationReasonScriptExec is used when a script is run under a policy blocking them.
+	ViolationReasonScriptExec
+	// ViolationReasonExecLimit is used when a container runs more distinct executables than allowed by its policy.
+	ViolationReasonExecLimit
+	// ViolationReasonUnlinkedExec is used when a file with no name left in the filesystem is run under a policy
+	// blocking them.
+	ViolationReasonUnlinkedExec
+	// ViolationReasonCommandNotApproved is used when an executable restricted to its approved commands is run
+	// with other arguments.
+	ViolationReasonCommandNotApproved
+	// ViolationReasonFsNotAllowed is used when the executable is stored on a filesystem type not allowed
+	// by the policy.
+	ViolationReasonFsNotAllowed
+	// ViolationReasonFileReadNotAllowed is used when a file not allowed for reading by the policy is opened
+	// for reading.
+	ViolationReasonFileReadNotAllowed
+	// ViolationReasonFileWriteNotAllowed is used when a file not allowed for writing by the policy is opened
+	// for writing.
+	ViolationReasonFileWriteNotAllowed
+	// ViolationReasonFileWriteDenied is used when a file denied for writing by the policy is opened for writing.
+	ViolationReasonFileWriteDenied
+	// ViolationReasonEgressNotAllowed is used when a process connects to a destination not allowed by the policy.
+	ViolationReasonEgressNotAllowed
 )
 
 // ProcessEvent represents an event coming from BPF programs, for now used for learning and monitoring.
 type ProcessEvent struct {
 	CgTrackerID uint64
-	ExePath     string
-	Mode        string
+	// ExePath is the path of the executable, or of the opened file for the violations of the file rules.
+	ExePath string
+	Mode    string
+	Reason  ViolationReason
+	// FileMode contains the mode bits of the executable, only for monitoring events.
+	FileMode uint16
+	// Tgid is the pid of the process calling exec, as seen from the host pid namespace.
+	Tgid uint32
+	// Args contains the arguments following argv[0] of the attempted command,
+	// only for ViolationReasonCommandNotApproved.
+	Args []string
+	// FsMagic is the superblock magic of the filesystem of the executable, only for ViolationReasonFsNotAllowed.
+	FsMagic uint32
+	// Destination is the address the process connected to, only for ViolationReasonEgressNotAllowed.
+	Destination netip.AddrPort
+	// Sequence numbers the events read from the ring buffer, to tell apart identical events.
+	Sequence uint64
 }
 
 type bpfEventHeader struct {
 	CgTrackerID uint64
 	PathLen     uint16
 	Mode        uint8
+	Reason      uint8
+	FileMode    uint16
+	ArgsLen     uint16
+	Tgid        uint32
+	FsMagic     uint32
 }
 
 type Manager struct {
@@ -53,6 +112,9 @@ type Manager struct {
 	policyStringMaps []*ebpf.Map
 	isShuttingDown   atomic.Bool
 
+	// programSpecs contains the specs of the loaded programs by name, for the startup summary.
+	programSpecs map[string]*ebpf.ProgramSpec
+
 	// Learning
 	enableLearning    bool
 	learningEventChan chan ProcessEvent
@@ -60,6 +122,14 @@ type Manager struct {
 	// Monitoring
 	monitoringEventChan chan ProcessEvent
 
+	// policyModeMissingChan receives the policy IDs associated with a cgroup but without a mode.
+	policyModeMissingChan chan uint64
+
+	// workloadContextFunc adds the workload context to the log events, it is nil when unset.
+	workloadContextFunc WorkloadContextFunc
+	// logReaderOpener replaces the reader of the ring buffer of the logs in tests, it is nil otherwise.
+	logReaderOpener func() (logRecordReader, error)
+
 	// Kernel version check cache
 	kernelCheckOnce sync.Once
 	isPre5_9        bool
@@ -127,7 +197,26 @@ func loadEbpfObjects(spec *ebpf.CollectionSpec, level ebpf.LogLevel) (*bpfObject
 	return nil, fmt.Errorf("verifier error: %s. Dump: %s", err.Error(), fmt.Sprintf("%+v", verr))
 }
 
-func NewManager(logger *slog.Logger, enableLearning bool) (*Manager, error) {
+type managerOptions struct {
+	observeOnly bool
+}
+
+type ManagerOption func(*managerOptions)
+
+// WithObserveOnly loads the BPF programs so that no exec is ever blocked, whatever the mode of the policies:
+// every policy is enforced in monitor mode. Unlike the mode of the policies, it cannot be changed at runtime.
+func WithObserveOnly() ManagerOption {
+	return func(o *managerOptions) {
+		o.observeOnly = true
+	}
+}
+
+func NewManager(logger *slog.Logger, enableLearning bool, opts ...ManagerOption) (*Manager, error) {
+	var options managerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	if err := rlimit.RemoveMemlock(); err != nil {
 		return nil, fmt.Errorf("failed to remove memlock: %w", err)
 	}
@@ -145,7 +234,7 @@ func NewManager(logger *slog.Logger, enableLearning bool) (*Manager, error) {
 		return nil, fmt.Errorf("failed to load BPF spec: %w", err)
 	}
 
-	conf, err := getLoadTimeConfig(logger, enableLearning)
+	conf, err := getLoadTimeConfig(logger, enableLearning, options.observeOnly)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get load time config: %w", err)
 	}
@@ -186,11 +275,13 @@ func NewManager(logger *slog.Logger, enableLearning bool) (*Manager, error) {
 	logger.Info("eBPF prog and maps loaded successfully")
 
 	return &Manager{
-		logger:              newLogger,
-		objs:                objs,
-		enableLearning:      enableLearning,
-		learningEventChan:   make(chan ProcessEvent, learningEventChanSize),
-		monitoringEventChan: make(chan ProcessEvent, monitorEventChanSize),
+		logger:                newLogger,
+		objs:                  objs,
+		programSpecs:          spec.Programs,
+		enableLearning:        enableLearning,
+		learningEventChan:     make(chan ProcessEvent, learningEventChanSize),
+		monitoringEventChan:   make(chan ProcessEvent, monitorEventChanSize),
+		policyModeMissingChan: make(chan uint64, policyModeMissingChanSize),
 		policyStringMaps: []*ebpf.Map{
 			objs.PolStrMaps0,
 			objs.PolStrMaps1,
@@ -235,6 +326,11 @@ func (m *Manager) Start(ctx context.Context) error {
 	}()
 
 	m.logger.InfoContext(ctx, "Starting BPF Manager...")
+	if summary, err := m.Summary(); err != nil {
+		m.logger.WarnContext(ctx, "failed to summarize the BPF programs and maps", "error", err)
+	} else {
+		m.logger.InfoContext(ctx, "BPF startup summary", "summary", summary)
+	}
 	g, ctx := errgroup.WithContext(ctx)
 
 	// Logging
@@ -3,11 +3,14 @@ package bpf
 import (
 	"fmt"
 	"io"
+	"net"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/rancher-sandbox/runtime-enforcer/internal/testutil"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sys/unix"
@@ -41,19 +44,15 @@ func TestLearning(t *testing.T) {
 	}))
 }
 
-func TestMemfdBinaryLearning(t *testing.T) {
-	runner, err := newCgroupRunner(t)
-	require.NoError(t, err, "Failed to create cgroup runner")
-	defer runner.close()
-
-	// Create memfd
-	name := "memfd_test"
+// newMemfdBinary copies /usr/bin/true into a memfd named name and returns its file descriptor,
+// which is closed at the end of the test.
+func newMemfdBinary(t *testing.T, name string) int {
 	memfd, err := unix.MemfdCreate(name, unix.MFD_CLOEXEC)
 	require.NoError(t, err, "Failed to create memfd")
 
 	memFile := os.NewFile(uintptr(memfd), name)
 	require.NotNil(t, memFile, "Failed to create memfd file")
-	defer memFile.Close()
+	t.Cleanup(func() { memFile.Close() })
 
 	// copy the content of an existing binary inside the memfd
 	srcFile, err := os.Open("/usr/bin/true")
@@ -62,6 +61,16 @@ func TestMemfdBinaryLearning(t *testing.T) {
 
 	_, err = io.Copy(memFile, srcFile)
 	require.NoError(t, err, "Failed to copy data to memfd")
+	return memfd
+}
+
+func TestMemfdBinaryLearning(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	name := "memfd_test"
+	memfd := newMemfdBinary(t, name)
 
 	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
 		command:         fmt.Sprintf("/proc/self/fd/%d", memfd),
@@ -164,6 +173,36 @@ func TestMonitorProtectMode(t *testing.T) {
 	}))
 }
 
+func TestObserveOnly(t *testing.T) {
+	runner, err := newCgroupRunnerWithLogger(t, testutil.NewTestLogger(t), WithObserveOnly())
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{"/usr/bin/true"})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+	err = runner.manager.GetPolicyFlagsUpdateFunc()(mockPolicyID, PolicyFlagBlockScripts, UpdateFlags)
+	require.NoError(t, err, "Failed to set policy flags")
+
+	// The violations are still reported, in monitor mode, but nothing is blocked even in protect mode.
+	t.Log("Trying not allowed binary in protect mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/who",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+	}))
+
+	t.Log("Trying a script in protect mode with blocked scripts")
+	tmpPath, remove, err := generateScriptWithLen(64)
+	require.NoError(t, err, "Failed to generate temporary script")
+	defer remove()
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         tmpPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+	}))
+}
+
 func TestMultiplePolicies(t *testing.T) {
 	runner, err := newCgroupRunner(t)
 	require.NoError(t, err, "Failed to create cgroup runner")
@@ -260,3 +299,770 @@ func TestManagerShutdown(t *testing.T) {
 
 	require.NoError(t, err, "bpf manager should return nil after shutdown")
 }
+
+func TestBlockSuidExec(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	// Create a setuid copy of `true`.
+	content, err := os.ReadFile("/usr/bin/true")
+	require.NoError(t, err)
+	suidPath := filepath.Join(t.TempDir(), "suid-true")
+	require.NoError(t, os.WriteFile(suidPath, content, 0o755))
+	require.NoError(t, os.Chmod(suidPath, 0o755|os.ModeSetuid))
+
+	mockPolicyID := uint64(42)
+	// the setuid binary is in the allow list.
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Monitor, []string{"/usr/bin/true", suidPath})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	t.Log("Trying setuid binary without the flag")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         suidPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	err = runner.manager.GetPolicyFlagsUpdateFunc()(mockPolicyID, PolicyFlagBlockSuidExec, UpdateFlags)
+	require.NoError(t, err, "Failed to set policy flags")
+
+	t.Log("Trying setuid binary with the flag in monitor mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         suidPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+	}))
+
+	t.Log("Trying a regular allowed binary with the flag")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	err = runner.manager.GetPolicyModeUpdateFunc()(mockPolicyID, policymode.Protect, UpdateMode)
+	require.NoError(t, err, "Failed to set policy to protect")
+
+	t.Log("Trying setuid binary with the flag in protect mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         suidPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+}
+
+func TestCaseInsensitiveMatching(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	// Create copies of `true` whose names differ only by the case.
+	content, err := os.ReadFile("/usr/bin/true")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	mixedPath := filepath.Join(dir, "Mixed-True")
+	require.NoError(t, os.WriteFile(mixedPath, content, 0o755))
+	otherPath := filepath.Join(dir, "OTHER-TRUE")
+	require.NoError(t, os.WriteFile(otherPath, content, 0o755))
+
+	mockPolicyID := uint64(42)
+	// userspace writes the allowed executables already folded.
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{FoldPathCase(mixedPath)})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	t.Log("Trying mixed case binary without the flag")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         mixedPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	err = runner.manager.GetPolicyFlagsUpdateFunc()(mockPolicyID, PolicyFlagCaseInsensitive, UpdateFlags)
+	require.NoError(t, err, "Failed to set policy flags")
+
+	t.Log("Trying mixed case binary with the flag")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         mixedPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	// The event must report the path with its original case.
+	t.Log("Trying not allowed binary with the flag")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         otherPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+}
+
+func TestBlockScripts(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	scriptPath := filepath.Join(t.TempDir(), "script.sh")
+	require.NoError(t, os.WriteFile(scriptPath, []byte("#!/usr/bin/true\n"), 0o755))
+
+	mockPolicyID := uint64(42)
+	// both the script and its interpreter are in the allow list.
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Monitor, []string{"/usr/bin/true", scriptPath})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	t.Log("Trying script without the flag")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         scriptPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	err = runner.manager.GetPolicyFlagsUpdateFunc()(mockPolicyID, PolicyFlagBlockScripts, UpdateFlags)
+	require.NoError(t, err, "Failed to set policy flags")
+
+	t.Log("Trying script with the flag in monitor mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         scriptPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+	}))
+
+	err = runner.manager.GetPolicyModeUpdateFunc()(mockPolicyID, policymode.Protect, UpdateMode)
+	require.NoError(t, err, "Failed to set policy to protect")
+
+	t.Log("Trying the interpreter binary directly with the flag in protect mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	t.Log("Trying script with the flag in protect mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         scriptPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+}
+
+func TestBlockUnlinkedExec(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	name := "memfd_unlinked"
+	memfd := newMemfdBinary(t, name)
+	memfdCommand := fmt.Sprintf("/proc/self/fd/%d", memfd)
+	memfdPath := fmt.Sprintf("/memfd:%s", name)
+
+	// a copy of /usr/bin/true, deleted once opened, is still reachable through its file descriptor.
+	deletedPath := filepath.Join(t.TempDir(), "deleted")
+	data, err := os.ReadFile("/usr/bin/true")
+	require.NoError(t, err, "Failed to read source file")
+	require.NoError(t, os.WriteFile(deletedPath, data, 0o755))
+	deletedFile, err := os.Open(deletedPath)
+	require.NoError(t, err, "Failed to open the binary to delete")
+	defer deletedFile.Close()
+	require.NoError(t, os.Remove(deletedPath))
+	deletedCommand := fmt.Sprintf("/proc/self/fd/%d", deletedFile.Fd())
+	// the kernel marks the path of the deleted files the same way as `d_path`.
+	deletedEventPath := deletedPath + " (deleted)"
+
+	mockPolicyID := uint64(42)
+	// the unlinked files are in the allow list, so that only the flag reports them.
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Monitor,
+		[]string{"/usr/bin/true", memfdPath, deletedEventPath})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	t.Log("Trying memfd binary without the flag")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         memfdCommand,
+		expectedPath:    memfdPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	err = runner.manager.GetPolicyFlagsUpdateFunc()(mockPolicyID, PolicyFlagBlockUnlinkedExec, UpdateFlags)
+	require.NoError(t, err, "Failed to set policy flags")
+
+	t.Log("Trying memfd binary with the flag in monitor mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         memfdCommand,
+		expectedPath:    memfdPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+	}))
+
+	err = runner.manager.GetPolicyModeUpdateFunc()(mockPolicyID, policymode.Protect, UpdateMode)
+	require.NoError(t, err, "Failed to set policy to protect")
+
+	t.Log("Trying a linked binary with the flag in protect mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	t.Log("Trying memfd binary with the flag in protect mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         memfdCommand,
+		expectedPath:    memfdPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	t.Log("Trying deleted binary with the flag in protect mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         deletedCommand,
+		expectedPath:    deletedEventPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+}
+
+func TestTraceAllowedExec(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{"/usr/bin/true"})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	t.Log("Trying allowed binary without trace")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	err = runner.manager.GetTraceUpdateFunc()(runner.cgInfo.id, StartTrace)
+	require.NoError(t, err, "Failed to start trace")
+
+	t.Log("Trying allowed binary with trace")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+	}))
+
+	t.Log("Trying not allowed binary with trace")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/who",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	err = runner.manager.GetTraceUpdateFunc()(runner.cgInfo.id, StopTrace)
+	require.NoError(t, err, "Failed to stop trace")
+
+	t.Log("Trying allowed binary after the trace is stopped")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+}
+
+func TestExecLimit(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(
+		mockPolicyID, policymode.Protect, []string{"/usr/bin/true", "/usr/bin/false", "/usr/bin/who"},
+	)
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	err = runner.manager.GetPolicyExecLimitUpdateFunc()(mockPolicyID, 2, UpdateExecLimit)
+	require.NoError(t, err, "Failed to set policy exec limit")
+
+	t.Log("Trying allowed binaries within the limit")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/false",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	t.Log("Trying an already counted binary at the limit")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	t.Log("Trying a new allowed binary beyond the limit in protect mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/who",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+	// blocked binaries are not counted, every attempt is reported.
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/who",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	err = runner.manager.GetPolicyModeUpdateFunc()(mockPolicyID, policymode.Monitor, UpdateMode)
+	require.NoError(t, err, "Failed to set policy to monitor")
+
+	t.Log("Trying a new allowed binary beyond the limit in monitor mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/who",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+	}))
+	// in monitor mode the binary is counted, so it is reported only once.
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/who",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+}
+
+func TestAllowedFilesystems(t *testing.T) {
+	var allowedFs unix.Statfs_t
+	require.NoError(t, unix.Statfs("/usr/bin/true", &allowedFs))
+	if allowedFs.Type == unix.TMPFS_MAGIC {
+		t.Skip("/usr/bin is already on a tmpfs")
+	}
+	// the copy of the binary is stored on a tmpfs, a filesystem type not allowed by the policy.
+	tmpfsDir := t.TempDir()
+	require.NoError(t, unix.Mount("tmpfs", tmpfsDir, "tmpfs", 0, ""))
+	defer unix.Unmount(tmpfsDir, 0)
+	otherPath := filepath.Join(tmpfsDir, "true")
+	src, err := os.Open("/usr/bin/true")
+	require.NoError(t, err)
+	defer src.Close()
+	dst, err := os.OpenFile(otherPath, os.O_CREATE|os.O_WRONLY, 0755)
+	require.NoError(t, err)
+	_, err = io.Copy(dst, src)
+	require.NoError(t, err)
+	require.NoError(t, dst.Close())
+
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(
+		mockPolicyID, policymode.Protect, []string{"/usr/bin/true", otherPath},
+	)
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	t.Log("Trying a binary of another filesystem with no restriction")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         otherPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	err = runner.manager.GetPolicyFsTypesUpdateFunc()(mockPolicyID, []uint32{uint32(allowedFs.Type)}, UpdateFsTypes)
+	require.NoError(t, err, "Failed to set policy filesystem types")
+
+	t.Log("Trying a binary of an allowed filesystem")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	t.Log("Trying a binary of a filesystem not allowed in protect mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         otherPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	err = runner.manager.GetPolicyModeUpdateFunc()(mockPolicyID, policymode.Monitor, UpdateMode)
+	require.NoError(t, err, "Failed to set policy to monitor")
+
+	t.Log("Trying a binary of a filesystem not allowed in monitor mode")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         otherPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+	}))
+
+	err = runner.manager.GetPolicyFsTypesUpdateFunc()(mockPolicyID, nil, DeleteFsTypes)
+	require.NoError(t, err, "Failed to remove policy filesystem types")
+
+	t.Log("Trying a binary of another filesystem once the restriction is removed")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         otherPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+}
+
+func TestAllowedPrefixes(t *testing.T) {
+	otherDir := t.TempDir()
+	otherPath := filepath.Join(otherDir, "true")
+	content, err := os.ReadFile("/usr/bin/true")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(otherPath, content, 0755))
+
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{"/usr/bin/*"})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	t.Log("Trying a binary matching the allowed prefix")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	t.Log("Trying a binary out of the allowed prefix")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         otherPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	seen, err := runner.manager.GetPolicySeenValuesFunc()(mockPolicyID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/usr/bin/*"}, seen)
+
+	err = runner.manager.GetPolicyUpdateBinariesFunc()(
+		mockPolicyID, []string{otherDir + "/*"}, ReplaceValuesInPolicy,
+	)
+	require.NoError(t, err, "Failed to replace policy values")
+
+	t.Log("Trying a binary matching the new prefix")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         otherPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	t.Log("Trying a binary of the replaced prefix")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	err = runner.manager.GetPolicyUpdateBinariesFunc()(mockPolicyID, nil, RemoveValuesFromPolicy)
+	require.NoError(t, err, "Failed to remove policy values")
+	keys, err := runner.manager.policyPrefixKeys(func(key policyPrefixKey) bool { return key.PolicyID == mockPolicyID })
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+func TestGlobalDenyList(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{"/usr/bin/*"})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	err = runner.manager.GetGlobalDenyUpdateFunc()([]string{"true"})
+	require.NoError(t, err, "Failed to set the global deny list")
+
+	t.Log("Trying a denied name matching the allowed prefix")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	err = runner.manager.GetPolicyUpdateBinariesFunc()(
+		mockPolicyID, []string{"/usr/bin/true"}, ReplaceValuesInPolicy,
+	)
+	require.NoError(t, err, "Failed to replace policy values")
+	err = runner.manager.GetGlobalDenyUpdateFunc()([]string{"/usr/bin/true"})
+	require.NoError(t, err, "Failed to set the global deny list")
+
+	t.Log("Trying a denied path in the allowed executables")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	err = runner.manager.GetGlobalDenyUpdateFunc()(nil)
+	require.NoError(t, err, "Failed to clear the global deny list")
+	keys, err := runner.manager.globalDenyKeys()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	t.Log("Trying the allowed binary once the global deny list is empty")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+}
+
+func TestApprovedCommands(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{"/usr/bin/true"})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	err = runner.manager.GetPolicyCommandsUpdateFunc()(mockPolicyID, []Command{
+		{Path: "/usr/bin/true", Args: []string{"--approved", "a b"}},
+	}, ReplaceCommands)
+	require.NoError(t, err, "Failed to set policy approved commands")
+
+	t.Log("Trying the approved command")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		args:            []string{"--approved", "a b"},
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+
+	t.Log("Trying variants of the approved command")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		args:            []string{"--approved", "a", "b"},
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+		expectedArgs:    []string{"--approved", "a", "b"},
+	}))
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}))
+
+	t.Log("Trying a variant once the commands are removed")
+	err = runner.manager.GetPolicyCommandsUpdateFunc()(mockPolicyID, nil, DeleteCommands)
+	require.NoError(t, err, "Failed to delete policy approved commands")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		args:            []string{"--variant"},
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+}
+
+func TestPolicySeenValues(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(
+		mockPolicyID, policymode.Protect, []string{"/usr/bin/true", "/usr/bin/false"},
+	)
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	seen, err := runner.manager.GetPolicySeenValuesFunc()(mockPolicyID)
+	require.NoError(t, err)
+	require.Empty(t, seen, "no allowed binary has been run yet")
+
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+	seen, err = runner.manager.GetPolicySeenValuesFunc()(mockPolicyID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/usr/bin/true"}, seen)
+
+	// replacing the values of the policy resets them.
+	err = runner.manager.GetPolicyUpdateBinariesFunc()(
+		mockPolicyID, []string{"/usr/bin/true", "/usr/bin/false"}, ReplaceValuesInPolicy)
+	require.NoError(t, err)
+	seen, err = runner.manager.GetPolicySeenValuesFunc()(mockPolicyID)
+	require.NoError(t, err)
+	require.Empty(t, seen)
+}
+
+func TestDumpPolicyMaps(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(
+		mockPolicyID, policymode.Protect, []string{"/usr/bin/true", "/opt/app/*", "/usr/bin/false"},
+	)
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	binaries, err := runner.manager.DumpPolicyBinaries(mockPolicyID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/opt/app/*", "/usr/bin/false", "/usr/bin/true"}, binaries)
+
+	// the executables that have been run are dumped too.
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/true",
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}))
+	binaries, err = runner.manager.DumpPolicyBinaries(mockPolicyID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/opt/app/*", "/usr/bin/false", "/usr/bin/true"}, binaries)
+
+	binaries, err = runner.manager.DumpPolicyBinaries(mockPolicyID + 1)
+	require.NoError(t, err)
+	require.Empty(t, binaries)
+
+	cgroups, err := runner.manager.DumpCgroupToPolicy()
+	require.NoError(t, err)
+	require.Equal(t, map[uint64]uint64{runner.cgInfo.id: mockPolicyID}, cgroups)
+}
+
+func TestSummary(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{"/usr/bin/true"})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	summary, err := runner.manager.Summary()
+	require.NoError(t, err)
+	require.NotEmpty(t, summary.KernelVersion)
+	require.NotEmpty(t, summary.CgroupResolutionPrefix)
+
+	programs := make(map[string]ProgramSummary)
+	for _, prog := range summary.Programs {
+		programs[prog.Name] = prog
+	}
+	require.Contains(t, programs, "enforce_cgroup_policy")
+	require.Equal(t, "security_bprm_creds_for_exec", programs["enforce_cgroup_policy"].AttachTo)
+	require.Contains(t, programs, "enforce_script_exec")
+	require.Contains(t, programs, "tg_cgtracker_cgroup_mkdir")
+
+	maps := make(map[string]MapSummary)
+	for _, m := range summary.Maps {
+		maps[m.Name] = m
+	}
+	for _, name := range []string{"ringbuf_execve", "ringbuf_monitoring", "ringbuf_logs"} {
+		require.Contains(t, maps, name)
+		require.Equal(t, "RingBuf", maps[name].Type)
+		require.Equal(t, -1, maps[name].Entries)
+	}
+	// the policy of the runner cgroup is counted.
+	require.Equal(t, 1, maps["policy_mode_map"].Entries)
+	require.Equal(t, 1, maps["cg_to_policy_map"].Entries)
+}
+
+func TestFileRules(t *testing.T) {
+	deniedDir := t.TempDir()
+	deniedPath := filepath.Join(deniedDir, "denied")
+
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{"/usr/bin/touch"})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	err = runner.manager.GetPolicyFilesUpdateFunc()(
+		mockPolicyID, Files{DeniedWrite: []string{deniedDir + "/*"}}, ReplaceFiles,
+	)
+	require.NoError(t, err, "Failed to set policy files")
+	err = runner.manager.GetPolicyFlagsUpdateFunc()(mockPolicyID, PolicyFlagDenyWrite, UpdateFlags)
+	require.NoError(t, err, "Failed to set policy flags")
+
+	t.Log("Writing a denied file in protect mode")
+	require.Error(t, runner.cgInfo.RunInCgroup("/usr/bin/touch", []string{deniedPath}))
+	require.NoError(t, runner.manager.findEventInChannel(monitoringChannel, runner.cgInfo.id, deniedPath, nil))
+	require.NoFileExists(t, deniedPath)
+
+	err = runner.manager.GetPolicyModeUpdateFunc()(mockPolicyID, policymode.Monitor, UpdateMode)
+	require.NoError(t, err, "Failed to set policy to monitor")
+
+	t.Log("Writing a denied file in monitor mode")
+	require.NoError(t, runner.cgInfo.RunInCgroup("/usr/bin/touch", []string{deniedPath}))
+	require.NoError(t, runner.manager.findEventInChannel(monitoringChannel, runner.cgInfo.id, deniedPath, nil))
+	require.FileExists(t, deniedPath)
+
+	err = runner.manager.GetPolicyFilesUpdateFunc()(mockPolicyID, Files{}, DeleteFiles)
+	require.NoError(t, err, "Failed to remove policy files")
+	err = runner.manager.GetPolicyFlagsUpdateFunc()(mockPolicyID, 0, UpdateFlags)
+	require.NoError(t, err, "Failed to reset policy flags")
+
+	t.Log("Writing the file once the rules are removed")
+	require.NoError(t, runner.cgInfo.RunInCgroup("/usr/bin/touch", []string{deniedPath}))
+	require.Error(t, runner.manager.findEventInChannel(monitoringChannel, runner.cgInfo.id, deniedPath, nil))
+}
+
+func TestEgressRules(t *testing.T) {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to listen")
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+	connect := []string{"-c", fmt.Sprintf("exec 3<>/dev/tcp/127.0.0.1/%d", port)}
+
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	mockPolicyID := uint64(42)
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{"/usr/bin/bash"})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+
+	// only another port of the listener address is allowed.
+	egress := Egress{Allowed: []EgressRule{{Prefix: netip.MustParsePrefix("127.0.0.0/8"), Port: uint16(port + 1)}}}
+	err = runner.manager.GetPolicyEgressUpdateFunc()(mockPolicyID, egress, ReplaceEgress)
+	require.NoError(t, err, "Failed to set policy egress")
+	err = runner.manager.GetPolicyFlagsUpdateFunc()(mockPolicyID, egress.Flags(), UpdateFlags)
+	require.NoError(t, err, "Failed to set policy flags")
+
+	t.Log("Connecting to a destination not allowed in protect mode")
+	require.Error(t, runner.cgInfo.RunInCgroup("/usr/bin/bash", connect))
+	require.NoError(t, runner.manager.findEventInChannel(monitoringChannel, runner.cgInfo.id, "/usr/bin/bash", nil))
+
+	err = runner.manager.GetPolicyModeUpdateFunc()(mockPolicyID, policymode.Monitor, UpdateMode)
+	require.NoError(t, err, "Failed to set policy to monitor")
+
+	t.Log("Connecting to a destination not allowed in monitor mode")
+	require.NoError(t, runner.cgInfo.RunInCgroup("/usr/bin/bash", connect))
+	require.NoError(t, runner.manager.findEventInChannel(monitoringChannel, runner.cgInfo.id, "/usr/bin/bash", nil))
+
+	egress.Allowed = append(egress.Allowed, EgressRule{
+		Prefix: netip.MustParsePrefix("127.0.0.1/32"), Port: uint16(port),
+	})
+	err = runner.manager.GetPolicyEgressUpdateFunc()(mockPolicyID, egress, ReplaceEgress)
+	require.NoError(t, err, "Failed to set policy egress")
+
+	t.Log("Connecting to an allowed destination")
+	require.NoError(t, runner.cgInfo.RunInCgroup("/usr/bin/bash", connect))
+	require.Error(t, runner.manager.findEventInChannel(monitoringChannel, runner.cgInfo.id, "/usr/bin/bash", nil))
+
+	err = runner.manager.GetPolicyEgressUpdateFunc()(mockPolicyID, Egress{}, DeleteEgress)
+	require.NoError(t, err, "Failed to remove policy egress")
+	keys, err := runner.manager.policyEgressKeys(func(key policyEgressKey) bool { return key.PolicyID == mockPolicyID })
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
@@ -0,0 +1,38 @@
+package bpf
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	droppedEventExec      = "exec"
+	droppedEventViolation = "violation"
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var droppedEventsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "runtime_enforcer_bpf_dropped_events_total",
+		Help: "Number of events the BPF programs failed to send to the agent, by kind.",
+	},
+	[]string{"kind"},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var logsSuppressedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "runtime_enforcer_logs_suppressed_total",
+		Help: "Number of logs of the BPF programs suppressed by the rate limiting, by log type.",
+	},
+	[]string{"log_type"},
+)
+
+// RegisterMetrics registers the BPF metrics in the given registry.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{droppedEventsTotal, logsSuppressedTotal} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
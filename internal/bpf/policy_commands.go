@@ -0,0 +1,167 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+)
+
+// The limits of the approved commands, mirroring MAX_CMD_ARGS and MAX_CMD_ARG_LEN of the BPF program:
+// the longer commands are never approved.
+const (
+	MaxCommandArgs   = 32
+	MaxCommandArgLen = 256
+)
+
+const (
+	fnvOffsetBasis = 14695981039346656037
+	fnvPrime       = 1099511628211
+)
+
+// Values of the entries of the policy commands map, mirroring the POLICY_CMD_* bitmask of the BPF program.
+const (
+	policyCmdRestricted uint8 = 1 << iota
+	policyCmdApproved
+)
+
+// Command is a complete command line approved for a policy.
+type Command struct {
+	// Path of the executable, folded with FoldPathCase when the policy matches the paths ignoring the case.
+	Path string
+	// Args are the arguments following argv[0], which is left out since the caller can set it freely.
+	Args []string
+}
+
+// policyCmdKey mirrors `struct policy_cmd_key` of the BPF program.
+type policyCmdKey struct {
+	PolicyID uint64
+	Hash     uint64
+}
+
+func fnvAppend(hash uint64, data string) uint64 {
+	for i := range len(data) {
+		hash ^= uint64(data[i])
+		hash *= fnvPrime
+	}
+	return hash
+}
+
+// pathHash returns the FNV-1a hash of path, computed like `hash_path` in the BPF program.
+func pathHash(path string) uint64 {
+	return fnvAppend(fnvOffsetBasis, path)
+}
+
+// CommandHash returns the hash identifying a command line, computed like the BPF program does for the
+// executed commands: FNV-1a over the path followed, for each argument, by a NUL byte and the argument.
+func CommandHash(cmd Command) uint64 {
+	hash := pathHash(cmd.Path)
+	for _, arg := range cmd.Args {
+		hash = fnvAppend(hash*fnvPrime, arg)
+	}
+	return hash
+}
+
+type PolicyCommandsOperation uint8
+
+const (
+	_ PolicyCommandsOperation = iota
+	ReplaceCommands
+	DeleteCommands
+)
+
+// policyCommandEntries returns the entries of the policy commands map for the given commands:
+// the paths restricted to their approved commands and the hashes of the approved commands.
+func policyCommandEntries(policyID uint64, commands []Command) map[policyCmdKey]uint8 {
+	entries := make(map[policyCmdKey]uint8, 2*len(commands))
+	for _, cmd := range commands {
+		entries[policyCmdKey{PolicyID: policyID, Hash: pathHash(cmd.Path)}] |= policyCmdRestricted
+		entries[policyCmdKey{PolicyID: policyID, Hash: CommandHash(cmd)}] |= policyCmdApproved
+	}
+	return entries
+}
+
+// policyCommandKeys returns the keys of the policy commands map matching keep.
+func (m *Manager) policyCommandKeys(keep func(policyCmdKey) bool) ([]policyCmdKey, error) {
+	var keys []policyCmdKey
+	var key, next policyCmdKey
+	err := m.objs.PolicyCmdMap.NextKey(nil, &next)
+	for err == nil {
+		if keep(next) {
+			keys = append(keys, next)
+		}
+		key = next
+		err = m.objs.PolicyCmdMap.NextKey(&key, &next)
+	}
+	if !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return nil, fmt.Errorf("failed to iterate map %s: %w", m.objs.PolicyCmdMap.String(), err)
+	}
+	return keys, nil
+}
+
+func (m *Manager) deletePolicyCommandKeys(keys []policyCmdKey) error {
+	for _, key := range keys {
+		if err := m.objs.PolicyCmdMap.Delete(&key); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf(
+				"failed to delete policy (id=%d) command %x from map %s: %w",
+				key.PolicyID,
+				key.Hash,
+				m.objs.PolicyCmdMap.String(),
+				err,
+			)
+		}
+	}
+	return nil
+}
+
+// replacePolicyCommands writes the new entries before deleting the stale ones,
+// so that the restricted executables are never left unrestricted meanwhile.
+func (m *Manager) replacePolicyCommands(policyID uint64, commands []Command) error {
+	entries := policyCommandEntries(policyID, commands)
+	for key, value := range entries {
+		if err := m.objs.PolicyCmdMap.Update(&key, value, ebpf.UpdateAny); err != nil {
+			return fmt.Errorf(
+				"failed to update policy (id=%d) in map %s with command %x: %w",
+				policyID,
+				m.objs.PolicyCmdMap.String(),
+				key.Hash,
+				wrapMapFullErr(err),
+			)
+		}
+	}
+	stale, err := m.policyCommandKeys(func(key policyCmdKey) bool {
+		_, ok := entries[key]
+		return key.PolicyID == policyID && !ok
+	})
+	if err != nil {
+		return err
+	}
+	return m.deletePolicyCommandKeys(stale)
+}
+
+func (m *Manager) deletePolicyCommands(policyID uint64) error {
+	keys, err := m.policyCommandKeys(func(key policyCmdKey) bool { return key.PolicyID == policyID })
+	if err != nil {
+		return err
+	}
+	return m.deletePolicyCommandKeys(keys)
+}
+
+// GetPolicyCommandsUpdateFunc returns the function setting the approved commands of a policy.
+// The executables of the approved commands can only run one of them, with no commands nothing is restricted.
+func (m *Manager) GetPolicyCommandsUpdateFunc() func(
+	policyID uint64,
+	commands []Command,
+	op PolicyCommandsOperation,
+) error {
+	return func(policyID uint64, commands []Command, op PolicyCommandsOperation) error {
+		switch op {
+		case ReplaceCommands:
+			return m.handleErrOnShutdown(m.replacePolicyCommands(policyID, commands))
+		case DeleteCommands:
+			return m.handleErrOnShutdown(m.deletePolicyCommands(policyID))
+		default:
+			panic("unhandled policy commands operation")
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package bpf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandHash(t *testing.T) {
+	// FNV-1a of "/bin/sh", "\x00-c" and "\x00ls" hashed as a single stream.
+	require.Equal(t, fnvAppend(fnvOffsetBasis, "/bin/sh\x00-c\x00ls"),
+		CommandHash(Command{Path: "/bin/sh", Args: []string{"-c", "ls"}}))
+	require.Equal(t, pathHash("/bin/sh"), CommandHash(Command{Path: "/bin/sh"}))
+
+	// the separators tell apart the arguments split differently.
+	require.NotEqual(t,
+		CommandHash(Command{Path: "/bin/sh", Args: []string{"-c", "ls"}}),
+		CommandHash(Command{Path: "/bin/sh", Args: []string{"-cls"}}))
+	require.NotEqual(t,
+		CommandHash(Command{Path: "/bin/sh", Args: []string{"-c ls"}}),
+		CommandHash(Command{Path: "/bin/sh", Args: []string{"-c", "ls"}}))
+	// an empty argument is part of the command.
+	require.NotEqual(t,
+		CommandHash(Command{Path: "/bin/sh"}),
+		CommandHash(Command{Path: "/bin/sh", Args: []string{""}}))
+}
+
+func TestPolicyCommandEntries(t *testing.T) {
+	ls := Command{Path: "/bin/sh", Args: []string{"-c", "ls"}}
+	id := Command{Path: "/bin/sh", Args: []string{"-c", "id"}}
+	require.Equal(t, map[policyCmdKey]uint8{
+		{PolicyID: 7, Hash: pathHash("/bin/sh")}: policyCmdRestricted,
+		{PolicyID: 7, Hash: CommandHash(ls)}:     policyCmdApproved,
+		{PolicyID: 7, Hash: CommandHash(id)}:     policyCmdApproved,
+	}, policyCommandEntries(7, []Command{ls, id}))
+}
@@ -0,0 +1,45 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// The dumps read the BPF maps without any lock: they can run concurrently with the updates of the resolver,
+// in which case they return a mix of the entries before and after the update.
+
+// DumpPolicyBinaries returns the allowed executables of the policy as programmed in the BPF maps, sorted,
+// whether they have been run or not. The prefixes are returned with their PrefixWildcard.
+func (m *Manager) DumpPolicyBinaries(policyID uint64) ([]string, error) {
+	all := func(uint8) bool { return true }
+	values, err := m.listPolicyValues(policyID, all)
+	if err != nil {
+		return nil, m.handleErrOnShutdown(err)
+	}
+	prefixes, err := m.listPolicyPrefixes(policyID, all)
+	if err != nil {
+		return nil, m.handleErrOnShutdown(err)
+	}
+	values = append(values, prefixes...)
+	slices.Sort(values)
+	return values, nil
+}
+
+// DumpCgroupToPolicy returns the policy ID each cgroup is attached to in the BPF map.
+func (m *Manager) DumpCgroupToPolicy() (map[uint64]uint64, error) {
+	cgToPol := m.objs.CgToPolicyMap
+	if cgToPol == nil {
+		return nil, errors.New("cgroup to policy map is nil")
+	}
+	dump := make(map[uint64]uint64)
+	var cgID, polID uint64
+	iter := cgToPol.Iterate()
+	for iter.Next(&cgID, &polID) {
+		dump[cgID] = polID
+	}
+	if err := iter.Err(); err != nil {
+		return nil, m.handleErrOnShutdown(fmt.Errorf("failed to iterate map %s: %w", cgToPol.String(), err))
+	}
+	return dump, nil
+}
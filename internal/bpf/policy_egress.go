@@ -0,0 +1,190 @@
+package bpf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/cilium/ebpf"
+)
+
+// policyEgressKeyBits is the length in bits of the policy ID and the port leading the keys of the egress map.
+const policyEgressKeyBits = policyIDBits + 16
+
+// ipv4MappedBits is the length in bits of the prefix of the IPv4-mapped IPv6 addresses.
+const ipv4MappedBits = 96
+
+// EgressRule allows the connections to the addresses of Prefix on Port.
+type EgressRule struct {
+	Prefix netip.Prefix
+	Port   uint16
+}
+
+// Egress are the network rules of a policy.
+type Egress struct {
+	Allowed []EgressRule
+}
+
+// Flags returns the policy flags enabling the egress rules in the BPF program.
+func (e Egress) Flags() PolicyFlags {
+	if len(e.Allowed) > 0 {
+		return PolicyFlagRestrictEgress
+	}
+	return 0
+}
+
+type PolicyEgressOperation uint8
+
+const (
+	_ PolicyEgressOperation = iota
+	ReplaceEgress
+	DeleteEgress
+)
+
+// policyEgressKey mirrors `struct policy_egress_key` of the BPF program.
+type policyEgressKey struct {
+	PrefixLen uint32
+	PolicyID  uint64
+	Port      uint16
+	Addr      [16]byte
+}
+
+// newPolicyEgressKey returns the key of the rule, the IPv4 addresses are stored as IPv4-mapped IPv6 addresses
+// like the BPF program does for the IPv4 destinations.
+func newPolicyEgressKey(policyID uint64, rule EgressRule) policyEgressKey {
+	prefix := rule.Prefix.Masked()
+	bits := prefix.Bits()
+	if prefix.Addr().Is4() {
+		bits += ipv4MappedBits
+	}
+	return policyEgressKey{
+		PrefixLen: uint32(policyEgressKeyBits + bits), //nolint:gosec // the prefix length is bounded above
+		PolicyID:  policyID,
+		Port:      rule.Port,
+		Addr:      prefix.Addr().As16(),
+	}
+}
+
+// rule returns the allowed destination stored in the key.
+func (k policyEgressKey) rule() EgressRule {
+	addr := netip.AddrFrom16(k.Addr)
+	bits := int(k.PrefixLen) - policyEgressKeyBits
+	if addr.Is4In6() && bits >= ipv4MappedBits {
+		addr = addr.Unmap()
+		bits -= ipv4MappedBits
+	}
+	return EgressRule{Prefix: netip.PrefixFrom(addr, bits), Port: k.Port}
+}
+
+func (r EgressRule) String() string {
+	if r.Prefix.IsSingleIP() {
+		return netip.AddrPortFrom(r.Prefix.Addr(), r.Port).String()
+	}
+	if r.Prefix.Addr().Is6() {
+		return fmt.Sprintf("[%s]:%d", r.Prefix, r.Port)
+	}
+	return fmt.Sprintf("%s:%d", r.Prefix, r.Port)
+}
+
+// egressDestination mirrors `struct egress_dest` of the BPF program, it follows the path of the executable
+// in the events of ViolationReasonEgressNotAllowed.
+type egressDestination struct {
+	Addr [16]byte
+	Port uint16
+}
+
+// parseEgressDestination decodes the destination appended to an event, the IPv4-mapped addresses are unmapped.
+func parseEgressDestination(raw []byte) (netip.AddrPort, error) {
+	var dest egressDestination
+	if len(raw) < binary.Size(dest) {
+		return netip.AddrPort{}, fmt.Errorf("destination of %d bytes is too short", len(raw))
+	}
+	if _, err := binary.Decode(raw, binary.NativeEndian, &dest); err != nil {
+		return netip.AddrPort{}, fmt.Errorf("decoding destination: %w", err)
+	}
+	return netip.AddrPortFrom(netip.AddrFrom16(dest.Addr).Unmap(), dest.Port), nil
+}
+
+// policyEgressKeys returns the keys of the policy egress map matching keep.
+func (m *Manager) policyEgressKeys(keep func(policyEgressKey) bool) ([]policyEgressKey, error) {
+	var keys []policyEgressKey
+	var key, next policyEgressKey
+	err := m.objs.PolicyEgressMap.NextKey(nil, &next)
+	for err == nil {
+		if keep(next) {
+			keys = append(keys, next)
+		}
+		key = next
+		err = m.objs.PolicyEgressMap.NextKey(&key, &next)
+	}
+	if !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return nil, fmt.Errorf("failed to iterate map %s: %w", m.objs.PolicyEgressMap.String(), err)
+	}
+	return keys, nil
+}
+
+func (m *Manager) deletePolicyEgressKeys(keys []policyEgressKey) error {
+	for _, key := range keys {
+		if err := m.objs.PolicyEgressMap.Delete(&key); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf(
+				"failed to delete policy (id=%d) egress %s from map %s: %w",
+				key.PolicyID,
+				key.rule(),
+				m.objs.PolicyEgressMap.String(),
+				err,
+			)
+		}
+	}
+	return nil
+}
+
+// replacePolicyEgress writes the new rules before deleting the stale ones,
+// so that the destinations allowed by both the old and the new rules are never denied meanwhile.
+func (m *Manager) replacePolicyEgress(policyID uint64, egress Egress) error {
+	written := make(map[policyEgressKey]struct{}, len(egress.Allowed))
+	for _, rule := range egress.Allowed {
+		key := newPolicyEgressKey(policyID, rule)
+		if err := m.objs.PolicyEgressMap.Update(&key, uint8(1), ebpf.UpdateAny); err != nil {
+			return fmt.Errorf(
+				"failed to update policy (id=%d) in map %s with egress %s: %w",
+				policyID,
+				m.objs.PolicyEgressMap.String(),
+				rule,
+				wrapMapFullErr(err),
+			)
+		}
+		written[key] = struct{}{}
+	}
+	stale, err := m.policyEgressKeys(func(key policyEgressKey) bool {
+		_, ok := written[key]
+		return key.PolicyID == policyID && !ok
+	})
+	if err != nil {
+		return err
+	}
+	return m.deletePolicyEgressKeys(stale)
+}
+
+func (m *Manager) deletePolicyEgress(policyID uint64) error {
+	keys, err := m.policyEgressKeys(func(key policyEgressKey) bool { return key.PolicyID == policyID })
+	if err != nil {
+		return err
+	}
+	return m.deletePolicyEgressKeys(keys)
+}
+
+// GetPolicyEgressUpdateFunc returns the function setting the egress rules of a policy.
+// The rules are only enforced when the flags of the policy enable them, see Egress.Flags.
+func (m *Manager) GetPolicyEgressUpdateFunc() func(policyID uint64, egress Egress, op PolicyEgressOperation) error {
+	return func(policyID uint64, egress Egress, op PolicyEgressOperation) error {
+		switch op {
+		case ReplaceEgress:
+			return m.handleErrOnShutdown(m.replacePolicyEgress(policyID, egress))
+		case DeleteEgress:
+			return m.handleErrOnShutdown(m.deletePolicyEgress(policyID))
+		default:
+			panic("unhandled policy egress operation")
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package bpf
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPolicyEgressKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      EgressRule
+		prefixLen uint32
+		str       string
+	}{
+		{
+			name:      "IPv4 CIDR",
+			rule:      EgressRule{Prefix: netip.MustParsePrefix("10.0.0.0/8"), Port: 443},
+			prefixLen: 64 + 16 + 96 + 8,
+			str:       "10.0.0.0/8:443",
+		},
+		{
+			name:      "IPv4 address",
+			rule:      EgressRule{Prefix: netip.MustParsePrefix("10.1.2.3/32"), Port: 53},
+			prefixLen: 64 + 16 + 128,
+			str:       "10.1.2.3:53",
+		},
+		{
+			name:      "IPv6 CIDR",
+			rule:      EgressRule{Prefix: netip.MustParsePrefix("fd00::/16"), Port: 8080},
+			prefixLen: 64 + 16 + 16,
+			str:       "[fd00::/16]:8080",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := newPolicyEgressKey(7, tt.rule)
+			require.Equal(t, uint64(7), key.PolicyID)
+			require.Equal(t, tt.rule.Port, key.Port)
+			require.Equal(t, tt.prefixLen, key.PrefixLen)
+			require.Equal(t, tt.rule, key.rule())
+			require.Equal(t, tt.str, tt.rule.String())
+		})
+	}
+}
+
+func TestEgressFlags(t *testing.T) {
+	require.Equal(t, PolicyFlags(0), Egress{}.Flags())
+	rule := EgressRule{Prefix: netip.MustParsePrefix("10.0.0.0/8"), Port: 443}
+	require.Equal(t, PolicyFlagRestrictEgress, Egress{Allowed: []EgressRule{rule}}.Flags())
+}
+
+func TestParseEgressDestination(t *testing.T) {
+	dest := egressDestination{Addr: netip.MustParseAddr("::ffff:10.1.2.3").As16(), Port: 443}
+	raw, err := binary.Append(nil, binary.NativeEndian, dest)
+	require.NoError(t, err)
+	addrPort, err := parseEgressDestination(raw)
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddrPort("10.1.2.3:443"), addrPort)
+
+	_, err = parseEgressDestination(raw[:4])
+	require.ErrorContains(t, err, "too short")
+}
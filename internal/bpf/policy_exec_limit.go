@@ -0,0 +1,78 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+)
+
+type PolicyExecLimitOperation uint8
+
+const (
+	_ PolicyExecLimitOperation = iota
+	UpdateExecLimit
+	DeleteExecLimit
+)
+
+func (m *Manager) updatePolicyExecLimit(policyID uint64, limit uint32) error {
+	if err := m.objs.PolicyExecLimitMap.Update(&policyID, limit, ebpf.UpdateAny); err != nil {
+		return fmt.Errorf(
+			"failed to update policy (id=%d) in map %s with exec limit %d: %w",
+			policyID,
+			m.objs.PolicyExecLimitMap.String(),
+			limit,
+			err,
+		)
+	}
+	return nil
+}
+
+func (m *Manager) deletePolicyExecLimit(policyID uint64) error {
+	if err := m.objs.PolicyExecLimitMap.Delete(&policyID); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return fmt.Errorf(
+			"failed to delete policy (id=%d) from map %s: %w",
+			policyID,
+			m.objs.PolicyExecLimitMap.String(),
+			err,
+		)
+	}
+	return nil
+}
+
+// resetDistinctExecCounts forgets the number of distinct executables run by the given cgroups.
+// The executables themselves are evicted from the LRU map by the BPF program.
+func (m *Manager) resetDistinctExecCounts(cgroupIDs []uint64) error {
+	var multiErr error
+	for _, cgID := range cgroupIDs {
+		if err := m.objs.DistinctExecCountMap.Delete(&cgID); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			multiErr = errors.Join(
+				multiErr,
+				fmt.Errorf("failed to remove cgroup %d from distinct exec count map: %w", cgID, err),
+			)
+		}
+	}
+	return multiErr
+}
+
+// GetPolicyExecLimitUpdateFunc returns the function setting the maximum number of distinct executables
+// each cgroup of a policy can run. A zero limit removes it.
+func (m *Manager) GetPolicyExecLimitUpdateFunc() func(
+	policyID uint64,
+	limit uint32,
+	op PolicyExecLimitOperation,
+) error {
+	return func(policyID uint64, limit uint32, op PolicyExecLimitOperation) error {
+		switch op {
+		case UpdateExecLimit:
+			if limit == 0 {
+				return m.handleErrOnShutdown(m.deletePolicyExecLimit(policyID))
+			}
+			return m.handleErrOnShutdown(m.updatePolicyExecLimit(policyID, limit))
+		case DeleteExecLimit:
+			return m.handleErrOnShutdown(m.deletePolicyExecLimit(policyID))
+		default:
+			panic("unhandled policy exec limit operation")
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cilium/ebpf"
+)
+
+// Values of the entries of the policy file map, mirroring the POLICY_FILE_* bitmask of the BPF program.
+const (
+	policyFileReadAllowed uint8 = 1 << iota
+	policyFileWriteAllowed
+	policyFileWriteDenied
+)
+
+// Files are the file rules of a policy. The entries are absolute paths, an entry ending with PrefixWildcard
+// matches every path starting with the part before it.
+type Files struct {
+	AllowedRead  []string
+	AllowedWrite []string
+	DeniedWrite  []string
+}
+
+// Flags returns the policy flags enabling the file rules in the BPF program.
+func (f Files) Flags() PolicyFlags {
+	var flags PolicyFlags
+	if len(f.AllowedRead) > 0 {
+		flags |= PolicyFlagRestrictRead
+	}
+	if len(f.AllowedWrite) > 0 {
+		flags |= PolicyFlagRestrictWrite
+	}
+	if len(f.DeniedWrite) > 0 {
+		flags |= PolicyFlagDenyWrite
+	}
+	return flags
+}
+
+type PolicyFilesOperation uint8
+
+const (
+	_ PolicyFilesOperation = iota
+	ReplaceFiles
+	DeleteFiles
+)
+
+// policyFileEntries returns the paths stored in the policy file map with their POLICY_FILE_* bits.
+// The exact paths keep their NUL terminator, so that they match only themselves, and the entries ending with
+// PrefixWildcard lose it. Each entry gets the bits of the less specific entries matching it, so that the BPF
+// program only needs the longest match.
+func policyFileEntries(files Files) map[string]uint8 {
+	entries := make(map[string]uint8)
+	prefixes := make(map[string]struct{})
+	add := func(values []string, bit uint8) {
+		for _, v := range values {
+			if prefix, ok := strings.CutSuffix(v, PrefixWildcard); ok {
+				entries[prefix] |= bit
+				prefixes[prefix] = struct{}{}
+				continue
+			}
+			entries[v+"\x00"] |= bit
+		}
+	}
+	add(files.AllowedRead, policyFileReadAllowed)
+	add(files.AllowedWrite, policyFileWriteAllowed)
+	add(files.DeniedWrite, policyFileWriteDenied)
+
+	folded := make(map[string]uint8, len(entries))
+	for path, bits := range entries {
+		for prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) {
+				bits |= entries[prefix]
+			}
+		}
+		folded[path] = bits
+	}
+	return folded
+}
+
+// policyFileKeys returns the keys of the policy file map matching keep.
+func (m *Manager) policyFileKeys(keep func(policyPrefixKey) bool) ([]policyPrefixKey, error) {
+	var keys []policyPrefixKey
+	var key, next policyPrefixKey
+	err := m.objs.PolicyFileMap.NextKey(nil, &next)
+	for err == nil {
+		if keep(next) {
+			keys = append(keys, next)
+		}
+		key = next
+		err = m.objs.PolicyFileMap.NextKey(&key, &next)
+	}
+	if !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return nil, fmt.Errorf("failed to iterate map %s: %w", m.objs.PolicyFileMap.String(), err)
+	}
+	return keys, nil
+}
+
+func (m *Manager) deletePolicyFileKeys(keys []policyPrefixKey) error {
+	for _, key := range keys {
+		if err := m.objs.PolicyFileMap.Delete(&key); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf(
+				"failed to delete policy (id=%d) file %q from map %s: %w",
+				key.PolicyID,
+				key.prefix(),
+				m.objs.PolicyFileMap.String(),
+				err,
+			)
+		}
+	}
+	return nil
+}
+
+// replacePolicyFiles writes the new entries before deleting the stale ones,
+// so that the files allowed by both the old and the new rules are never denied meanwhile.
+func (m *Manager) replacePolicyFiles(policyID uint64, files Files) error {
+	entries := policyFileEntries(files)
+	written := make(map[policyPrefixKey]struct{}, len(entries))
+	for path, bits := range entries {
+		key, err := newPolicyPrefixKey(policyID, path)
+		if err != nil {
+			return err
+		}
+		if err = m.objs.PolicyFileMap.Update(&key, bits, ebpf.UpdateAny); err != nil {
+			return fmt.Errorf(
+				"failed to update policy (id=%d) in map %s with file %q: %w",
+				policyID,
+				m.objs.PolicyFileMap.String(),
+				path,
+				wrapMapFullErr(err),
+			)
+		}
+		written[key] = struct{}{}
+	}
+	stale, err := m.policyFileKeys(func(key policyPrefixKey) bool {
+		_, ok := written[key]
+		return key.PolicyID == policyID && !ok
+	})
+	if err != nil {
+		return err
+	}
+	return m.deletePolicyFileKeys(stale)
+}
+
+func (m *Manager) deletePolicyFiles(policyID uint64) error {
+	keys, err := m.policyFileKeys(func(key policyPrefixKey) bool { return key.PolicyID == policyID })
+	if err != nil {
+		return err
+	}
+	return m.deletePolicyFileKeys(keys)
+}
+
+// GetPolicyFilesUpdateFunc returns the function setting the file rules of a policy.
+// The rules are only enforced for the lists enabled by the flags of the policy, see Files.Flags.
+func (m *Manager) GetPolicyFilesUpdateFunc() func(policyID uint64, files Files, op PolicyFilesOperation) error {
+	return func(policyID uint64, files Files, op PolicyFilesOperation) error {
+		switch op {
+		case ReplaceFiles:
+			return m.handleErrOnShutdown(m.replacePolicyFiles(policyID, files))
+		case DeleteFiles:
+			return m.handleErrOnShutdown(m.deletePolicyFiles(policyID))
+		default:
+			panic("unhandled policy files operation")
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package bpf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyFileEntries(t *testing.T) {
+	entries := policyFileEntries(Files{
+		AllowedRead:  []string{"/etc/*", "/app/config.yaml"},
+		AllowedWrite: []string{"/etc/app/cache/*", "/tmp/*"},
+		DeniedWrite:  []string{"/etc/*", "/tmp/lock"},
+	})
+	require.Equal(t, map[string]uint8{
+		"/etc/":                policyFileReadAllowed | policyFileWriteDenied,
+		"/app/config.yaml\x00": policyFileReadAllowed,
+		// the denied writes of /etc/* are carried by the more specific entry.
+		"/etc/app/cache/": policyFileReadAllowed | policyFileWriteAllowed | policyFileWriteDenied,
+		"/tmp/":           policyFileWriteAllowed,
+		"/tmp/lock\x00":   policyFileWriteAllowed | policyFileWriteDenied,
+	}, entries)
+}
+
+func TestFilesFlags(t *testing.T) {
+	require.Equal(t, PolicyFlags(0), Files{}.Flags())
+	require.Equal(t, PolicyFlagRestrictRead|PolicyFlagDenyWrite, Files{
+		AllowedRead: []string{"/etc/*"},
+		DeniedWrite: []string{"/etc/*"},
+	}.Flags())
+}
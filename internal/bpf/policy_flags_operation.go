@@ -0,0 +1,91 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cilium/ebpf"
+)
+
+// PolicyFlags mirrors the POLICY_FLAG_* bitmask of the BPF program.
+type PolicyFlags uint8
+
+const (
+	// PolicyFlagBlockSuidExec reports/blocks the execution of setuid/setgid binaries.
+	PolicyFlagBlockSuidExec PolicyFlags = 1 << iota
+	// PolicyFlagCaseInsensitive folds the ASCII case of the executed path before the comparison.
+	// The allowed executables must be written lowercased, see FoldPathCase.
+	PolicyFlagCaseInsensitive
+	// PolicyFlagBlockScripts reports/blocks the execution of scripts, detected from their shebang.
+	PolicyFlagBlockScripts
+	// PolicyFlagBlockUnlinkedExec reports/blocks the execution of files with no name left in the filesystem,
+	// e.g. deleted binaries and memfd files, and of the files whose path cannot be resolved.
+	PolicyFlagBlockUnlinkedExec
+	// PolicyFlagRestrictRead reports/blocks the files opened for reading which are not allowed by the file rules.
+	PolicyFlagRestrictRead
+	// PolicyFlagRestrictWrite reports/blocks the files opened for writing which are not allowed by the file rules.
+	PolicyFlagRestrictWrite
+	// PolicyFlagDenyWrite reports/blocks the files opened for writing which are denied by the file rules.
+	PolicyFlagDenyWrite
+	// PolicyFlagRestrictEgress reports/blocks the connections to the destinations not allowed by the egress rules.
+	PolicyFlagRestrictEgress
+)
+
+// FoldPathCase lowercases the ASCII letters of path, mirroring the folding applied by the BPF
+// program to the executed path when PolicyFlagCaseInsensitive is set.
+// Non-ASCII characters are left untouched on both sides.
+func FoldPathCase(path string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, path)
+}
+
+type PolicyFlagsOperation uint8
+
+const (
+	_ PolicyFlagsOperation = iota
+	UpdateFlags
+	DeleteFlags
+)
+
+func (m *Manager) updatePolicyFlags(policyID uint64, flags PolicyFlags) error {
+	if err := m.objs.PolicyFlagsMap.Update(&policyID, uint8(flags), ebpf.UpdateAny); err != nil {
+		return fmt.Errorf(
+			"failed to update policy (id=%d) in map %s with flags %d: %w",
+			policyID,
+			m.objs.PolicyFlagsMap.String(),
+			flags,
+			err,
+		)
+	}
+	return nil
+}
+
+func (m *Manager) deletePolicyFlags(policyID uint64) error {
+	if err := m.objs.PolicyFlagsMap.Delete(&policyID); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return fmt.Errorf(
+			"failed to delete policy (id=%d) from map %s: %w",
+			policyID,
+			m.objs.PolicyFlagsMap.String(),
+			err,
+		)
+	}
+	return nil
+}
+
+func (m *Manager) GetPolicyFlagsUpdateFunc() func(policyID uint64, flags PolicyFlags, op PolicyFlagsOperation) error {
+	return func(policyID uint64, flags PolicyFlags, op PolicyFlagsOperation) error {
+		switch op {
+		case UpdateFlags:
+			return m.handleErrOnShutdown(m.updatePolicyFlags(policyID, flags))
+		case DeleteFlags:
+			return m.handleErrOnShutdown(m.deletePolicyFlags(policyID))
+		default:
+			panic("unhandled policy flags operation")
+		}
+	}
+}
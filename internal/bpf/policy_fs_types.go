@@ -0,0 +1,113 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+)
+
+// maxPolicyFsTypes is the maximum number of filesystem types allowed by a policy, see POLICY_FS_TYPES_MAX.
+const maxPolicyFsTypes = 8
+
+// policyFsTypes mirrors `struct policy_fs_types` of the BPF program.
+type policyFsTypes struct {
+	Count  uint32
+	Magics [maxPolicyFsTypes]uint32
+}
+
+type PolicyFsTypesOperation uint8
+
+const (
+	_ PolicyFsTypesOperation = iota
+	UpdateFsTypes
+	DeleteFsTypes
+)
+
+// filesystemMagics maps the filesystem types a policy can allow to the magic of their superblock, see statfs(2).
+// ext2 and ext3 share the magic of ext4.
+//
+//nolint:gochecknoglobals // read-only table of the filesystem magics.
+var filesystemMagics = map[string]uint32{
+	"btrfs":    0x9123683e,
+	"ext4":     0xef53,
+	"f2fs":     0xf2f52010,
+	"fuse":     0x65735546,
+	"nfs":      0x6969,
+	"overlay":  0x794c7630,
+	"ramfs":    0x858458f6,
+	"squashfs": 0x73717368,
+	"tmpfs":    0x01021994,
+	"xfs":      0x58465342,
+	"zfs":      0x2fc12fc1,
+}
+
+// FilesystemMagic returns the superblock magic of the filesystem type, false if the type is unknown.
+func FilesystemMagic(fsType string) (uint32, bool) {
+	magic, ok := filesystemMagics[fsType]
+	return magic, ok
+}
+
+// FilesystemName returns the filesystem type of a superblock magic,
+// or the magic in hexadecimal when it is not one of the known filesystem types.
+func FilesystemName(magic uint32) string {
+	for name, m := range filesystemMagics {
+		if m == magic {
+			return name
+		}
+	}
+	return fmt.Sprintf("%#x", magic)
+}
+
+func (m *Manager) updatePolicyFsTypes(policyID uint64, magics []uint32) error {
+	if len(magics) > maxPolicyFsTypes {
+		return fmt.Errorf("policy (id=%d) allows %d filesystem types, the maximum is %d",
+			policyID, len(magics), maxPolicyFsTypes)
+	}
+	value := policyFsTypes{Count: uint32(len(magics))}
+	copy(value.Magics[:], magics)
+	if err := m.objs.PolicyFsTypesMap.Update(&policyID, &value, ebpf.UpdateAny); err != nil {
+		return fmt.Errorf(
+			"failed to update policy (id=%d) in map %s with filesystem magics %#x: %w",
+			policyID,
+			m.objs.PolicyFsTypesMap.String(),
+			magics,
+			err,
+		)
+	}
+	return nil
+}
+
+func (m *Manager) deletePolicyFsTypes(policyID uint64) error {
+	if err := m.objs.PolicyFsTypesMap.Delete(&policyID); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return fmt.Errorf(
+			"failed to delete policy (id=%d) from map %s: %w",
+			policyID,
+			m.objs.PolicyFsTypesMap.String(),
+			err,
+		)
+	}
+	return nil
+}
+
+// GetPolicyFsTypesUpdateFunc returns the function setting the superblock magics of the filesystems
+// the executables of a policy must be stored on. No magic removes the restriction.
+func (m *Manager) GetPolicyFsTypesUpdateFunc() func(
+	policyID uint64,
+	magics []uint32,
+	op PolicyFsTypesOperation,
+) error {
+	return func(policyID uint64, magics []uint32, op PolicyFsTypesOperation) error {
+		switch op {
+		case UpdateFsTypes:
+			if len(magics) == 0 {
+				return m.handleErrOnShutdown(m.deletePolicyFsTypes(policyID))
+			}
+			return m.handleErrOnShutdown(m.updatePolicyFsTypes(policyID, magics))
+		case DeleteFsTypes:
+			return m.handleErrOnShutdown(m.deletePolicyFsTypes(policyID))
+		default:
+			panic("unhandled policy filesystem types operation")
+		}
+	}
+}
@@ -53,3 +53,43 @@ func (m *Manager) GetPolicyModeUpdateFunc() func(policyID uint64, mode policymod
 		}
 	}
 }
+
+// listPolicyIDs returns the policy IDs with an entry in the mode map.
+// The ID reserved to the self-test is never returned, it is not managed by the resolver.
+func (m *Manager) listPolicyIDs() ([]uint64, error) {
+	var ids []uint64
+	var policyID uint64
+	var mode uint8
+	iter := m.objs.PolicyModeMap.Iterate()
+	for iter.Next(&policyID, &mode) {
+		if policyID != selfTestPolicyID {
+			ids = append(ids, policyID)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate map %s: %w", m.objs.PolicyModeMap.String(), err)
+	}
+	return ids, nil
+}
+
+// GetPolicyIDsListFunc exposes a function used to list the policy IDs stored in the mode map.
+func (m *Manager) GetPolicyIDsListFunc() func() ([]uint64, error) {
+	return func() ([]uint64, error) {
+		ids, err := m.listPolicyIDs()
+		return ids, m.handleErrOnShutdown(err)
+	}
+}
+
+// GetPolicyModeMissingChannel returns the channel receiving the policy IDs that are associated with a cgroup
+// but have no mode, so that the events of their cgroups are dropped by the BPF program.
+func (m *Manager) GetPolicyModeMissingChannel() <-chan uint64 {
+	return m.policyModeMissingChan
+}
+
+// notifyPolicyModeMissing never blocks the log reader, the notification is dropped if the channel is full.
+func (m *Manager) notifyPolicyModeMissing(policyID uint64) {
+	select {
+	case m.policyModeMissingChan <- policyID:
+	default:
+	}
+}
@@ -0,0 +1,153 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cilium/ebpf"
+)
+
+// MaxPrefixLength is the maximum length of an allowed prefix, mirroring POLICY_PREFIX_MAX_LEN of the BPF program.
+// The keys of the LPM trie storing the prefixes are limited to 256 bytes, the policy ID included, so the prefixes
+// are much shorter than the exact paths, limited to MaxStringMapsSize bytes. Only the first MaxPrefixLength bytes
+// of the executed paths are compared with the prefixes.
+const MaxPrefixLength = 248
+
+// PrefixWildcard ends the allowed values matching every path starting with the value before it,
+// e.g. `/opt/app/bin/*` allows `/opt/app/bin/server` and `/opt/app/bin/tools/migrate`.
+const PrefixWildcard = "*"
+
+// policyIDBits is the length in bits of the policy ID leading the keys of the prefix map.
+const policyIDBits = 64
+
+// policyPrefixKey mirrors `struct policy_prefix_key` of the BPF program.
+type policyPrefixKey struct {
+	PrefixLen uint32
+	PolicyID  uint64
+	Path      [MaxPrefixLength]byte
+}
+
+func newPolicyPrefixKey(policyID uint64, prefix string) (policyPrefixKey, error) {
+	key := policyPrefixKey{PolicyID: policyID}
+	if len(prefix) > MaxPrefixLength {
+		return key, fmt.Errorf("prefix %s is longer than %d bytes", prefix, MaxPrefixLength)
+	}
+	copy(key.Path[:], prefix)
+	key.PrefixLen = uint32(policyIDBits + 8*len(prefix)) //nolint:gosec // the prefix length is bounded above
+	return key, nil
+}
+
+// prefix returns the allowed prefix stored in the key.
+func (k policyPrefixKey) prefix() string {
+	return string(k.Path[:(int(k.PrefixLen)-policyIDBits)/8])
+}
+
+// splitPrefixes separates the exact paths, written into the string maps, from the prefixes,
+// returned without their PrefixWildcard and written into the prefix map.
+func splitPrefixes(values []string) ([]string, []string) {
+	var exact, prefixes []string
+	for _, v := range values {
+		if prefix, ok := strings.CutSuffix(v, PrefixWildcard); ok {
+			prefixes = append(prefixes, prefix)
+			continue
+		}
+		exact = append(exact, v)
+	}
+	return exact, prefixes
+}
+
+// policyPrefixKeys returns the keys of the prefix map matching keep.
+func (m *Manager) policyPrefixKeys(keep func(policyPrefixKey) bool) ([]policyPrefixKey, error) {
+	var keys []policyPrefixKey
+	var key, next policyPrefixKey
+	err := m.objs.PolicyPrefixMap.NextKey(nil, &next)
+	for err == nil {
+		if keep(next) {
+			keys = append(keys, next)
+		}
+		key = next
+		err = m.objs.PolicyPrefixMap.NextKey(&key, &next)
+	}
+	if !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return nil, fmt.Errorf("failed to iterate map %s: %w", m.objs.PolicyPrefixMap.String(), err)
+	}
+	return keys, nil
+}
+
+func (m *Manager) deletePolicyPrefixKeys(keys []policyPrefixKey) error {
+	for _, key := range keys {
+		if err := m.objs.PolicyPrefixMap.Delete(&key); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf(
+				"failed to delete policy (id=%d) prefix %s from map %s: %w",
+				key.PolicyID,
+				key.prefix(),
+				m.objs.PolicyPrefixMap.String(),
+				err,
+			)
+		}
+	}
+	return nil
+}
+
+// replacePolicyPrefixes writes the new prefixes before deleting the stale ones,
+// so that the executables allowed by both the old and the new prefixes are never denied meanwhile.
+func (m *Manager) replacePolicyPrefixes(policyID uint64, prefixes []string) error {
+	entries := make(map[policyPrefixKey]struct{}, len(prefixes))
+	for _, prefix := range prefixes {
+		key, err := newPolicyPrefixKey(policyID, prefix)
+		if err != nil {
+			return err
+		}
+		if err = m.objs.PolicyPrefixMap.Update(&key, policyValueAllowed, ebpf.UpdateAny); err != nil {
+			return fmt.Errorf(
+				"failed to update policy (id=%d) in map %s with prefix %s: %w",
+				policyID,
+				m.objs.PolicyPrefixMap.String(),
+				prefix,
+				wrapMapFullErr(err),
+			)
+		}
+		entries[key] = struct{}{}
+	}
+	stale, err := m.policyPrefixKeys(func(key policyPrefixKey) bool {
+		_, ok := entries[key]
+		return key.PolicyID == policyID && !ok
+	})
+	if err != nil {
+		return err
+	}
+	return m.deletePolicyPrefixKeys(stale)
+}
+
+func (m *Manager) deletePolicyPrefixes(policyID uint64) error {
+	keys, err := m.policyPrefixKeys(func(key policyPrefixKey) bool { return key.PolicyID == policyID })
+	if err != nil {
+		return err
+	}
+	return m.deletePolicyPrefixKeys(keys)
+}
+
+// listPolicyPrefixes returns the prefixes of the policy whose value matches keep,
+// with their PrefixWildcard, as they were allowed.
+func (m *Manager) listPolicyPrefixes(policyID uint64, keep func(value uint8) bool) ([]string, error) {
+	keys, err := m.policyPrefixKeys(func(key policyPrefixKey) bool { return key.PolicyID == policyID })
+	if err != nil {
+		return nil, err
+	}
+	var prefixes []string
+	for _, key := range keys {
+		var value uint8
+		if err = m.objs.PolicyPrefixMap.Lookup(&key, &value); err != nil {
+			if errors.Is(err, ebpf.ErrKeyNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to lookup policy (id=%d) prefix %s in map %s: %w",
+				policyID, key.prefix(), m.objs.PolicyPrefixMap.String(), err)
+		}
+		if keep(value) {
+			prefixes = append(prefixes, key.prefix()+PrefixWildcard)
+		}
+	}
+	return prefixes, nil
+}
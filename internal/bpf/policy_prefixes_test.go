@@ -0,0 +1,29 @@
+package bpf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPrefixes(t *testing.T) {
+	exact, prefixes := splitPrefixes([]string{"/usr/bin/ls", "/opt/app/bin/*", "/usr/lib/jvm*", "/bin/sh"})
+	require.Equal(t, []string{"/usr/bin/ls", "/bin/sh"}, exact)
+	require.Equal(t, []string{"/opt/app/bin/", "/usr/lib/jvm"}, prefixes)
+}
+
+func TestNewPolicyPrefixKey(t *testing.T) {
+	key, err := newPolicyPrefixKey(7, "/opt/app/")
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), key.PolicyID)
+	require.Equal(t, uint32(64+8*len("/opt/app/")), key.PrefixLen)
+	require.Equal(t, "/opt/app/", key.prefix())
+
+	key, err = newPolicyPrefixKey(7, "/"+strings.Repeat("a", MaxPrefixLength-1))
+	require.NoError(t, err)
+	require.Len(t, key.prefix(), MaxPrefixLength)
+
+	_, err = newPolicyPrefixKey(7, "/"+strings.Repeat("a", MaxPrefixLength))
+	require.ErrorContains(t, err, "longer than 248 bytes")
+}
@@ -0,0 +1,66 @@
+package bpf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+)
+
+// listPolicyValues returns the exact paths of the policy whose value matches keep.
+func (m *Manager) listPolicyValues(policyID uint64, keep func(value uint8) bool) ([]string, error) {
+	var values []string
+	for i, policyMap := range m.policyStringMaps {
+		var inner *ebpf.Map
+		if err := policyMap.Lookup(policyID, &inner); err != nil {
+			if errors.Is(err, ebpf.ErrKeyNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to lookup policy (id=%d) in map %s: %w", policyID, policyMap.String(), err)
+		}
+
+		key := make([]byte, stringMapsSizes[i])
+		var value uint8
+		iter := inner.Iterate()
+		for iter.Next(key, &value) {
+			if keep(value) {
+				// the values are padded with NUL bytes up to the key size of the map.
+				values = append(values, string(bytes.TrimRight(key, "\x00")))
+			}
+		}
+		err := iter.Err()
+		inner.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate inner map of policy (id=%d): %w", policyID, err)
+		}
+	}
+	return values, nil
+}
+
+func isSeenValue(value uint8) bool {
+	return value == policyValueSeen
+}
+
+// listSeenPolicyValues returns the allowed executables of the policy that have been run at least once
+// since they were written into the BPF maps, and its prefixes matched by at least one execution.
+// Replacing the values of a policy resets them.
+func (m *Manager) listSeenPolicyValues(policyID uint64) ([]string, error) {
+	seen, err := m.listPolicyValues(policyID, isSeenValue)
+	if err != nil {
+		return nil, err
+	}
+	seenPrefixes, err := m.listPolicyPrefixes(policyID, isSeenValue)
+	if err != nil {
+		return nil, err
+	}
+	return append(seen, seenPrefixes...), nil
+}
+
+// GetPolicySeenValuesFunc exposes a function used to list the allowed executables of a policy that have been run.
+func (m *Manager) GetPolicySeenValuesFunc() func(policyID uint64) ([]string, error) {
+	return func(policyID uint64) ([]string, error) {
+		seen, err := m.listSeenPolicyValues(policyID)
+		return seen, m.handleErrOnShutdown(err)
+	}
+}
@@ -1,13 +1,27 @@
 package bpf
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
 	"github.com/cilium/ebpf"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/kernels"
+	"golang.org/x/sys/unix"
 )
 
+// ErrPolicyMapFull is returned when the allowed executables of a policy don't fit in the BPF maps,
+// either because the maximum number of policies has been reached or because an inner map is full.
+var ErrPolicyMapFull = errors.New("the BPF maps of the allowed executables are full")
+
+// wrapMapFullErr marks the errors returned by the kernel when a map is full with ErrPolicyMapFull.
+func wrapMapFullErr(err error) error {
+	if errors.Is(err, unix.E2BIG) {
+		return fmt.Errorf("%w: %w", ErrPolicyMapFull, err)
+	}
+	return err
+}
+
 type PolicyValuesOperation int
 
 const (
@@ -20,8 +34,10 @@ const (
 const (
 	StringMapsNumSubMapsSmall = 8
 	StringMapsNumSubMaps      = 11
-	MaxStringMapsSize         = 4096
-	stringMapsKeyIncSize      = 24
+	// MaxStringMapsSize is the maximum length of the exact paths. The prefixes are not written into
+	// the string maps, they are limited to MaxPrefixLength, see policy_prefixes.go.
+	MaxStringMapsSize    = 4096
+	stringMapsKeyIncSize = 24
 
 	stringMapSize0  = 1 * stringMapsKeyIncSize
 	stringMapSize1  = 2 * stringMapsKeyIncSize
@@ -39,6 +55,13 @@ const (
 	fixedMaxEntriesPre5_9 = 500
 )
 
+// Values of the entries of the policy string maps, they must match the POLICY_VALUE_* values in bpf/main.c.
+const (
+	policyValueAllowed uint8 = 1
+	// policyValueSeen is set by BPF once the allowed executable has been run.
+	policyValueSeen uint8 = 2
+)
+
 const (
 	// BPFFNoPrealloc is the flag for BPF_MAP_CREATE that disables preallocation. Must match values from linux/bpf.h.
 	BPFFNoPrealloc = 1 << 0
@@ -161,6 +184,37 @@ func convertValuesToBPFStringMaps(values []string) (SelectorStringMaps, error) {
 	return maps, nil
 }
 
+// stringMapKeys are the keys of an inner string map, each one truncated to the key size of the map.
+// They are marshaled back to back, as expected by the batch operations.
+type stringMapKeys [][]byte
+
+func (k stringMapKeys) MarshalBinary() ([]byte, error) {
+	return bytes.Join(k, nil), nil
+}
+
+// fillInnerMap writes the values of subMap into a new inner map with a single batch update, instead of
+// one syscall per value, so that the large allow lists are written quickly. The kernels without the batch
+// operations (before 5.6) fall back to one update per value.
+func fillInnerMap(inner *ebpf.Map, name string, keySize int, subMap map[[MaxStringMapsSize]byte]struct{}) error {
+	keys := make(stringMapKeys, 0, len(subMap))
+	for rawVal := range subMap {
+		keys = append(keys, bytes.Clone(rawVal[:keySize]))
+	}
+	values := bytes.Repeat([]byte{policyValueAllowed}, len(keys))
+	_, err := inner.BatchUpdate(keys, values, nil)
+	if errors.Is(err, ebpf.ErrNotSupported) {
+		for _, key := range keys {
+			if err = inner.Update(key, policyValueAllowed, 0); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert values into %s: %w", name, wrapMapFullErr(err))
+	}
+	return nil
+}
+
 func (m *Manager) generateInnerBPFMaps(policyID uint64,
 	index int, isPre5_9 bool, subMap map[[MaxStringMapsSize]byte]struct{}) error {
 	mapKeySize := stringMapsSizes[index]
@@ -186,15 +240,9 @@ func (m *Manager) generateInnerBPFMaps(policyID uint64,
 	}
 	defer inner.Close()
 
-	// update values
-	// todo: ideally we should rollback if any of these fail
-	one := uint8(1)
-	for rawVal := range subMap {
-		val := rawVal[:mapKeySize]
-		err = inner.Update(val, one, 0)
-		if err != nil {
-			return fmt.Errorf("failed to insert value into %s: %w", name, err)
-		}
+	// todo: ideally we should rollback if this fails
+	if err = fillInnerMap(inner, name, mapKeySize, subMap); err != nil {
+		return err
 	}
 
 	err = m.policyStringMaps[index].Update(policyID, inner, ebpf.UpdateNoExist)
@@ -203,7 +251,7 @@ func (m *Manager) generateInnerBPFMaps(policyID uint64,
 		err = m.policyStringMaps[index].Update(policyID, inner, 0)
 	}
 	if err != nil {
-		return fmt.Errorf("failed to insert inner policy (id=%d) map: %w", policyID, err)
+		return fmt.Errorf("failed to insert inner policy (id=%d) map: %w", policyID, wrapMapFullErr(err))
 	}
 	m.logger.Debug("handler: add new inner map inside policy str", "name", name)
 	return nil
@@ -291,37 +339,45 @@ func (m *Manager) replaceInnerBPFMap(policyID uint64,
 	}
 	defer inner.Close()
 
-	one := uint8(1)
-	for rawVal := range subMap {
-		val := rawVal[:mapKeySize]
-		err = inner.Update(val, one, 0)
-		if err != nil {
-			return fmt.Errorf("failed to insert value into %s: %w", name, err)
-		}
+	if err = fillInnerMap(inner, name, mapKeySize, subMap); err != nil {
+		return err
 	}
 
 	// Use UpdateAny to replace the old inner map or create a new one
 	// if a policy update needs it.
 	err = m.policyStringMaps[index].Update(policyID, inner, ebpf.UpdateAny)
 	if err != nil {
-		return fmt.Errorf("failed to update inner policy (id=%d) map: %w", policyID, err)
+		return fmt.Errorf("failed to update inner policy (id=%d) map: %w", policyID, wrapMapFullErr(err))
 	}
 	m.logger.Info("handler: replaced inner map inside policy str", "name", name)
 	return nil
 }
 
 // GetPolicyUpdateBinariesFunc exposes a function used to interact with BPF maps storing the list of allowed binaries.
+// The values ending with PrefixWildcard are prefixes, written into the prefix map instead of the string maps.
 func (m *Manager) GetPolicyUpdateBinariesFunc() func(policyID uint64, values []string, op PolicyValuesOperation) error {
 	return func(policyID uint64, values []string, op PolicyValuesOperation) error {
+		exact, prefixes := splitPrefixes(values)
 		switch op {
 		case AddValuesToPolicy:
-			return m.handleErrOnShutdown(m.generateBPFMaps(policyID, values))
+			return m.handleErrOnShutdown(errors.Join(
+				m.generateBPFMaps(policyID, exact),
+				m.replacePolicyPrefixes(policyID, prefixes),
+			))
 		case RemoveValuesFromPolicy:
-			return m.handleErrOnShutdown(m.removeBPFMaps(policyID))
+			return m.handleErrOnShutdown(errors.Join(m.removeBPFMaps(policyID), m.deletePolicyPrefixes(policyID)))
 		case ReplaceValuesInPolicy:
-			return m.handleErrOnShutdown(m.replaceBPFMaps(policyID, values))
+			return m.handleErrOnShutdown(errors.Join(
+				m.replaceBPFMaps(policyID, exact),
+				m.replacePolicyPrefixes(policyID, prefixes),
+			))
 		default:
 			panic("unhandled operation")
 		}
 	}
 }
+
+// GetPolicyMapCapacity returns the maximum number of policy IDs the BPF maps of the allowed executables can hold.
+func (m *Manager) GetPolicyMapCapacity() uint32 {
+	return m.policyStringMaps[0].MaxEntries()
+}
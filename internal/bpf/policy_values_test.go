@@ -99,3 +99,21 @@ func TestArgStringSelectorValue(t *testing.T) {
 		})
 	}
 }
+
+func TestStringMapKeysMarshal(t *testing.T) {
+	subMaps, err := convertValuesToBPFStringMaps([]string{"/bin/ls", "/bin/cat"})
+	require.NoError(t, err)
+	require.Len(t, subMaps[0], 2)
+
+	keys := make(stringMapKeys, 0, len(subMaps[0]))
+	for rawVal := range subMaps[0] {
+		keys = append(keys, rawVal[:stringMapSize0])
+	}
+	// the batch update expects the keys back to back, each one padded to the key size of the map.
+	buf, err := keys.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, buf, 2*stringMapSize0)
+	for _, key := range keys {
+		require.Contains(t, string(buf), string(key))
+	}
+}
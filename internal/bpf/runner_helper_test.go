@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"syscall"
 	"testing"
 	"time"
@@ -47,11 +48,11 @@ type cgroupRunner struct {
 	cgInfo         cgroupInfo
 }
 
-func startManager(ctx context.Context, logger *slog.Logger) (*Manager, func(), error) {
+func startManager(ctx context.Context, logger *slog.Logger, opts ...ManagerOption) (*Manager, func(), error) {
 	// We always enable learning in tests for now so that we can wait for the first event to come
 	// and understand that BPF programs are loaded and running
 	enableLearning := true
-	manager, err := NewManager(logger, enableLearning)
+	manager, err := NewManager(logger, enableLearning, opts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create BPF manager: %w", err)
 	}
@@ -86,7 +87,9 @@ func checkManagerIsStarted(m *Manager, cgInfo *cgroupInfo) error {
 	}
 }
 
-func (m *Manager) findEventInChannel(ty ChannelType, cgID uint64, expectedPath string) error {
+// findEventInChannel waits for the event of the given cgroup and path,
+// with the given arguments when expectedArgs is not nil.
+func (m *Manager) findEventInChannel(ty ChannelType, cgID uint64, expectedPath string, expectedArgs []string) error {
 	// We chose the channel to extract events from based on the learning flag
 	var channel <-chan ProcessEvent
 	switch ty {
@@ -103,7 +106,8 @@ func (m *Manager) findEventInChannel(ty ChannelType, cgID uint64, expectedPath s
 		case event := <-channel:
 			m.logger.Info("Received event", "event", event)
 			if event.CgTrackerID == cgID &&
-				event.ExePath == expectedPath {
+				event.ExePath == expectedPath &&
+				(expectedArgs == nil || slices.Equal(event.Args, expectedArgs)) {
 				m.logger.Info("Found event", "event", event)
 				return nil
 			}
@@ -117,15 +121,18 @@ func (m *Manager) findEventInChannel(ty ChannelType, cgID uint64, expectedPath s
 
 type runCommandArgs struct {
 	command         string
+	args            []string
 	channel         ChannelType
 	shouldEPERM     bool
 	shouldFindEvent bool
 	// use it when command is != from the path we want to find in the buffer.
 	expectedPath string
+	// use it to assert the arguments reported by the event too.
+	expectedArgs []string
 }
 
 func (r *cgroupRunner) runAndFindCommand(args *runCommandArgs) error {
-	err := r.cgInfo.RunInCgroup(args.command, []string{})
+	err := r.cgInfo.RunInCgroup(args.command, args.args)
 	if args.shouldEPERM {
 		if err == nil || !errors.Is(err, syscall.EPERM) {
 			return fmt.Errorf("expected EPERM error, got: %w", err)
@@ -143,7 +150,7 @@ func (r *cgroupRunner) runAndFindCommand(args *runCommandArgs) error {
 	}
 
 	// Get the event
-	err = r.manager.findEventInChannel(args.channel, r.cgInfo.id, matchPath)
+	err = r.manager.findEventInChannel(args.channel, r.cgInfo.id, matchPath, args.expectedArgs)
 	if args.shouldFindEvent {
 		if err != nil {
 			return fmt.Errorf(
@@ -189,9 +196,9 @@ func (r *cgroupRunner) close() {
 	r.managerCleanup()
 }
 
-func newCgroupRunnerWithLogger(t *testing.T, logger *slog.Logger) (*cgroupRunner, error) {
+func newCgroupRunnerWithLogger(t *testing.T, logger *slog.Logger, opts ...ManagerOption) (*cgroupRunner, error) {
 	// Start the manager and wait for it to be ready
-	manager, cleanup, err := startManager(t.Context(), logger)
+	manager, cleanup, err := startManager(t.Context(), logger, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,123 @@
+package bpf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/kernels"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	selfTestCgroupName = "runtime-enforcer-self-test"
+	// selfTestPolicyID is reserved to the self-test, the resolver allocates policy IDs starting from 1.
+	selfTestPolicyID = uint64(math.MaxUint64)
+	// selfTestTimeout bounds the wait for the enforcement program to be attached by Start.
+	selfTestTimeout  = 10 * time.Second
+	selfTestInterval = 250 * time.Millisecond
+)
+
+// SelfTest verifies that enforcement works end to end: it creates a throwaway cgroup,
+// applies a deny-all policy in protect mode to it and execs `binary` with `args` inside it.
+// It returns nil only if the exec is blocked with EPERM.
+// The manager must be started, the exec is retried until the enforcement program is attached.
+// Only cgroup v2 is supported, since the process is spawned directly into the cgroup.
+func (m *Manager) SelfTest(ctx context.Context, binary string, args ...string) error {
+	cgInfo, err := cgroups.GetCgroupInfo()
+	if err != nil {
+		return fmt.Errorf("self-test: failed to get cgroup info: %w", err)
+	}
+	if cgInfo.CgroupFsMagic() != unix.CGROUP2_SUPER_MAGIC {
+		return fmt.Errorf("self-test: %s is not supported, cgroupv2 is required", cgInfo.CgroupFsMagicString())
+	}
+
+	cgroupPath := filepath.Join(cgInfo.CgroupResolutionPrefix(), selfTestCgroupName)
+	// A previous run may have left the cgroup behind, it can be removed since it is empty.
+	if err = os.Remove(cgroupPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("self-test: failed to remove stale cgroup %s: %w", cgroupPath, err)
+	}
+	if err = os.Mkdir(cgroupPath, 0o755); err != nil {
+		return fmt.Errorf("self-test: failed to create cgroup %s: %w", cgroupPath, err)
+	}
+	defer func() {
+		if rmErr := os.Remove(cgroupPath); rmErr != nil {
+			m.logger.WarnContext(ctx, "self-test: failed to remove cgroup", "path", cgroupPath, "error", rmErr)
+		}
+	}()
+
+	cgroupFD, err := syscall.Open(cgroupPath, syscall.O_RDONLY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("self-test: failed to open cgroup %s: %w", cgroupPath, err)
+	}
+	defer syscall.Close(cgroupFD)
+
+	cgroupID, err := cgroups.GetCgroupIDFromPath(cgroupPath)
+	if err != nil {
+		return fmt.Errorf("self-test: failed to get cgroup ID of %s: %w", cgroupPath, err)
+	}
+
+	if err = m.applySelfTestPolicy(cgroupID); err != nil {
+		return fmt.Errorf("self-test: %w", err)
+	}
+	defer func() {
+		if clearErr := m.clearSelfTestPolicy(cgroupID); clearErr != nil {
+			m.logger.WarnContext(ctx, "self-test: failed to clear policy", "error", clearErr)
+		}
+	}()
+
+	diagnostics := fmt.Sprintf("binary=%s cgroup=%s cgroupID=%d kernel=%s",
+		binary, cgroupPath, cgroupID, kernels.GetCurrKernelVersionStr())
+
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+	for {
+		cmd := exec.Command(binary, args...)
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			UseCgroupFD: true,
+			CgroupFD:    cgroupFD,
+		}
+		runErr := cmd.Run()
+		if errors.Is(runErr, syscall.EPERM) {
+			m.logger.InfoContext(ctx, "self-test: exec blocked as expected", "binary", binary)
+			return nil
+		}
+		var exitErr *exec.ExitError
+		if runErr != nil && !errors.As(runErr, &exitErr) {
+			// The exec didn't even reach the enforcement program (e.g. missing binary).
+			return fmt.Errorf("self-test: failed to exec (%s): %w", diagnostics, runErr)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("self-test: exec was not blocked by the deny-all policy (%s)", diagnostics)
+		case <-time.After(selfTestInterval):
+		}
+	}
+}
+
+func (m *Manager) applySelfTestPolicy(cgroupID uint64) error {
+	if err := updateCgTrackerMap(m.logger, m.objs.CgtrackerMap, cgroupID, ""); err != nil {
+		return err
+	}
+	// No executable is added to the policy, so that every exec is denied.
+	if err := m.updatePolicyMode(selfTestPolicyID, policymode.Protect); err != nil {
+		return err
+	}
+	return m.updateCgroupPolicy(selfTestPolicyID, []uint64{cgroupID}, AddPolicyToCgroups)
+}
+
+func (m *Manager) clearSelfTestPolicy(cgroupID uint64) error {
+	return errors.Join(
+		m.updateCgroupPolicy(selfTestPolicyID, []uint64{cgroupID}, RemoveCgroups),
+		m.deletePolicy(selfTestPolicyID),
+	)
+}
@@ -0,0 +1,23 @@
+package bpf
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	manager, cleanup, err := startManager(t.Context(), testutil.NewTestLogger(t))
+	require.NoError(t, err, "Failed to start manager")
+	defer cleanup()
+
+	require.NoError(t, manager.SelfTest(t.Context(), "/usr/bin/true"))
+
+	// The self-test must leave no policy behind.
+	var mode uint8
+	require.Error(t, manager.objs.PolicyModeMap.Lookup(selfTestPolicyID, &mode))
+
+	t.Log("Running the self-test with a missing binary")
+	require.Error(t, manager.SelfTest(t.Context(), "/non/existent/binary"))
+}
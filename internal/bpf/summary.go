@@ -0,0 +1,141 @@
+package bpf
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+
+	"github.com/cilium/ebpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/kernels"
+)
+
+// ProgramSummary describes a BPF program loaded by the manager.
+type ProgramSummary struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	AttachType string `json:"attachType"`
+	AttachTo   string `json:"attachTo"`
+}
+
+// MapSummary describes a BPF map loaded by the manager.
+type MapSummary struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	MaxEntries uint32 `json:"maxEntries"`
+	// Entries is the current number of entries of hash maps, -1 for the other types, whose size is fixed
+	// (arrays) or not made of entries (ring buffers).
+	Entries int `json:"entries"`
+}
+
+// Summary describes the runtime configuration of the manager, see Manager.Summary.
+type Summary struct {
+	KernelVersion          string           `json:"kernelVersion"`
+	CgroupFsMagic          string           `json:"cgroupFsMagic"`
+	CgroupMode             string           `json:"cgroupMode"`
+	CgroupResolutionPrefix string           `json:"cgroupResolutionPrefix"`
+	Programs               []ProgramSummary `json:"programs"`
+	Maps                   []MapSummary     `json:"maps"`
+}
+
+// LogValue groups the summary in a single structured log record.
+func (s Summary) LogValue() slog.Value {
+	programs := make([]any, 0, len(s.Programs))
+	for _, prog := range s.Programs {
+		programs = append(programs, slog.Group(prog.Name,
+			"type", prog.Type,
+			"attachType", prog.AttachType,
+			"attachTo", prog.AttachTo))
+	}
+	maps := make([]any, 0, len(s.Maps))
+	for _, m := range s.Maps {
+		maps = append(maps, slog.Group(m.Name,
+			"type", m.Type,
+			"maxEntries", m.MaxEntries,
+			"entries", m.Entries))
+	}
+	return slog.GroupValue(
+		slog.String("kernelVersion", s.KernelVersion),
+		slog.String("cgroupFsMagic", s.CgroupFsMagic),
+		slog.String("cgroupMode", s.CgroupMode),
+		slog.String("cgroupResolutionPrefix", s.CgroupResolutionPrefix),
+		slog.Group("programs", programs...),
+		slog.Group("maps", maps...),
+	)
+}
+
+// Summary lists the programs and maps loaded by the manager, with the current number of entries of the maps,
+// the kernel version and the cgroup setup of the node.
+func (m *Manager) Summary() (Summary, error) {
+	summary := Summary{KernelVersion: kernels.GetCurrKernelVersionStr()}
+	if cgInfo, err := cgroups.GetCgroupInfo(); err == nil && cgInfo != nil {
+		summary.CgroupFsMagic = cgInfo.CgroupFsMagicString()
+		summary.CgroupMode = cgInfo.CgroupMode().String()
+		summary.CgroupResolutionPrefix = cgInfo.CgroupResolutionPrefix()
+	}
+
+	for name, prog := range objectsByName[*ebpf.Program](m.objs.bpfPrograms) {
+		progSummary := ProgramSummary{Name: name, Type: prog.Type().String()}
+		if spec, ok := m.programSpecs[name]; ok {
+			progSummary.AttachType = spec.AttachType.String()
+			progSummary.AttachTo = spec.AttachTo
+		}
+		summary.Programs = append(summary.Programs, progSummary)
+	}
+	for name, bpfMap := range objectsByName[*ebpf.Map](m.objs.bpfMaps) {
+		entries, err := countEntries(bpfMap)
+		if err != nil {
+			return Summary{}, err
+		}
+		summary.Maps = append(summary.Maps, MapSummary{
+			Name:       name,
+			Type:       bpfMap.Type().String(),
+			MaxEntries: bpfMap.MaxEntries(),
+			Entries:    entries,
+		})
+	}
+	slices.SortFunc(summary.Programs, func(a, b ProgramSummary) int { return cmp.Compare(a.Name, b.Name) })
+	slices.SortFunc(summary.Maps, func(a, b MapSummary) int { return cmp.Compare(a.Name, b.Name) })
+	return summary, nil
+}
+
+// objectsByName returns the objects of a struct generated by bpf2go, by the name of their `ebpf` tag,
+// so that the programs and maps added to the BPF code are listed without changes here.
+func objectsByName[T any](objs any) map[string]T {
+	value := reflect.ValueOf(objs)
+	byName := make(map[string]T, value.NumField())
+	for i := range value.NumField() {
+		name := value.Type().Field(i).Tag.Get("ebpf")
+		if obj, ok := value.Field(i).Interface().(T); ok && name != "" {
+			byName[name] = obj
+		}
+	}
+	return byName
+}
+
+// countEntries returns the number of entries of hash maps and -1 for the other map types.
+func countEntries(bpfMap *ebpf.Map) (int, error) {
+	switch bpfMap.Type() {
+	case ebpf.Hash, ebpf.LRUHash, ebpf.HashOfMaps:
+	default:
+		return -1, nil
+	}
+
+	key := make([]byte, bpfMap.KeySize())
+	next := make([]byte, bpfMap.KeySize())
+	count := 0
+	err := bpfMap.NextKey(nil, next)
+	// The walk restarts when the current key is deleted meanwhile, so it is bounded by the capacity.
+	for err == nil && count < int(bpfMap.MaxEntries()) {
+		count++
+		copy(key, next)
+		err = bpfMap.NextKey(key, next)
+	}
+	if err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return 0, fmt.Errorf("failed to iterate map %s: %w", bpfMap.String(), err)
+	}
+	return count, nil
+}
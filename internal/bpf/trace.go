@@ -0,0 +1,44 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+)
+
+type TraceOperation uint8
+
+const (
+	_ TraceOperation = iota
+	StartTrace
+	StopTrace
+)
+
+func (m *Manager) startTrace(cgID uint64) error {
+	if err := m.objs.TraceCgroupsMap.Update(&cgID, uint8(0), ebpf.UpdateAny); err != nil {
+		return fmt.Errorf("failed to add cgroup %d to map %s: %w", cgID, m.objs.TraceCgroupsMap.String(), err)
+	}
+	return nil
+}
+
+func (m *Manager) stopTrace(cgID uint64) error {
+	if err := m.objs.TraceCgroupsMap.Delete(&cgID); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return fmt.Errorf("failed to delete cgroup %d from map %s: %w", cgID, m.objs.TraceCgroupsMap.String(), err)
+	}
+	return nil
+}
+
+// GetTraceUpdateFunc returns the function enabling or disabling the report of the allowed execs of a cgroup.
+func (m *Manager) GetTraceUpdateFunc() func(cgID uint64, op TraceOperation) error {
+	return func(cgID uint64, op TraceOperation) error {
+		switch op {
+		case StartTrace:
+			return m.handleErrOnShutdown(m.startTrace(cgID))
+		case StopTrace:
+			return m.handleErrOnShutdown(m.stopTrace(cgID))
+		default:
+			panic("unhandled trace operation")
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"golang.org/x/sys/unix"
 )
@@ -27,27 +28,100 @@ const (
 
 	// memoryControllerName is the memory controller name.
 	memoryControllerName = "memory"
+
+	// unifiedHierarchyName is the directory of the cgroupv2 hierarchy mounted by systemd in hybrid mode.
+	unifiedHierarchyName = "unified"
 )
 
+// Mode is the layout of the cgroup hierarchies of the host.
+type Mode int
+
+const (
+	// ModeUnified is a pure cgroupv2 layout.
+	ModeUnified Mode = iota
+	// ModeLegacy is a pure cgroupv1 layout, one hierarchy per controller.
+	ModeLegacy
+	// ModeHybrid is a cgroupv1 layout with the cgroupv2 hierarchy mounted under `unified`, without controllers.
+	ModeHybrid
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeUnified:
+		return "unified"
+	case ModeLegacy:
+		return "legacy"
+	case ModeHybrid:
+		return "hybrid"
+	default:
+		return "unknown"
+	}
+}
+
 type CgroupInfo struct {
 	cgroupResolutionPrefix string
 	fsMagic                uint64
 	subsysV1Idx            uint32
+	mode                   Mode
 }
 
 var (
 	cgroupInfoDetectionOnce sync.Once   //nolint:gochecknoglobals // we want it global for a global function.
 	cgroupInfo              *CgroupInfo //nolint:gochecknoglobals // we want it global for a global function.
 	errCgroupInfo           error
+
+	// cgroupDriver is detected from the first container reported by the runtime.
+	cgroupDriver atomic.Pointer[string] //nolint:gochecknoglobals // we want it global for a global function.
+
+	// cgroupV1ControllerIdx is the cgroupv1 controller index set by the operator, bypassing the detection.
+	cgroupV1ControllerIdx atomic.Pointer[uint32] //nolint:gochecknoglobals // we want it global for a global function.
+
+	// cgroupMountPoint is the cgroup mount point set by the operator, replacing defaultCgroupMountPoint.
+	cgroupMountPoint atomic.Pointer[string] //nolint:gochecknoglobals // we want it global for a global function.
 )
 
 func GetCgroupInfo() (*CgroupInfo, error) {
 	cgroupInfoDetectionOnce.Do(func() {
-		cgroupInfo, errCgroupInfo = getCgroupInfo()
+		mountPoint := defaultCgroupMountPoint
+		if custom := cgroupMountPoint.Load(); custom != nil {
+			mountPoint = *custom
+		}
+		cgroupInfo, errCgroupInfo = getCgroupInfo(mountPoint, cgroupV1ControllerIdx.Load())
 	})
 	return cgroupInfo, errCgroupInfo
 }
 
+// RecordCgroupDriver records the cgroup driver of the node from the cgroupsPath of a container.
+// Only the first call is taken into account: all the containers of a node share the same driver.
+func RecordCgroupDriver(cgroupPath string) {
+	driver := CgroupDriverFromPath(cgroupPath)
+	cgroupDriver.CompareAndSwap(nil, &driver)
+}
+
+// GetCgroupDriver returns the cgroup driver recorded by RecordCgroupDriver,
+// or an empty string if no container has been reported yet.
+func GetCgroupDriver() string {
+	if driver := cgroupDriver.Load(); driver != nil {
+		return *driver
+	}
+	return ""
+}
+
+// SetCgroupV1ControllerIdx makes the cgroupv1 detection use the controller at the given index under /proc/cgroups
+// instead of the memory controller, for the hosts where the detection fails.
+// It must be called before the first call to GetCgroupInfo and has no effect on cgroupv2.
+// In hybrid mode it selects the cgroupv1 hierarchy of the controller instead of the unified one.
+func SetCgroupV1ControllerIdx(idx uint32) {
+	cgroupV1ControllerIdx.Store(&idx)
+}
+
+// SetCgroupMountPoint makes the detection look for the cgroup filesystem at the given path
+// instead of defaultCgroupMountPoint, for the hosts where the root of PID 1 is not visible that way.
+// It must be called before the first call to GetCgroupInfo.
+func SetCgroupMountPoint(path string) {
+	cgroupMountPoint.Store(&path)
+}
+
 // GetCgroupResolutionPrefix returns the prefix used for cgroupID resolution.
 // For cgroupv2 it is the cgroup mount point path. (e.g. /sys/fs/cgroup)
 // For cgroupv1 it is the cgroup mount point path + the memory controller name. (e.g. /sys/fs/cgroup/memory).
@@ -82,14 +156,53 @@ func (c *CgroupInfo) CgroupResolutionPrefix() string {
 	return c.cgroupResolutionPrefix
 }
 
+// CgroupMode returns the layout of the cgroup hierarchies of the host.
+// In hybrid mode the cgroups are resolved on the unified hierarchy, so CgroupFsMagic is the cgroupv2 one,
+// unless the cgroupv1 controller is set with SetCgroupV1ControllerIdx.
+func (c *CgroupInfo) CgroupMode() Mode {
+	return c.mode
+}
+
 // findMemoryController returns the index of the memory controller under /proc/cgroups.
 // If we don't find it we return an error.
 // In cgroupv1, k8s containers could share the same cgroup under some controllers (e.g cpuset),
 // but usually under the memory controller each container has its own cgroup.
 func findMemoryController(path string) (uint32, error) {
+	allControllersNames, err := readControllers(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// we want to find the index for the memory controller
+	for i, name := range allControllersNames {
+		if name == memoryControllerName {
+			return uint32(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no '%s' controller among: %v", memoryControllerName, allControllersNames)
+}
+
+// findControllerByIdx validates the controller index provided by the operator against /proc/cgroups
+// and returns the name of the controller.
+func findControllerByIdx(path string, idx uint32) (string, error) {
+	allControllersNames, err := readControllers(path)
+	if err != nil {
+		return "", err
+	}
+
+	if idx >= uint32(len(allControllersNames)) {
+		return "", fmt.Errorf("no controller at index %d among: %v", idx, allControllersNames)
+	}
+	return allControllersNames[idx], nil
+}
+
+// readControllers returns the names of the cgroupv1 controllers under /proc/cgroups, in order.
+// Only the first CgroupSubsysCount controllers are returned.
+func readControllers(path string) ([]string, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
 	}
 	defer file.Close()
 
@@ -124,7 +237,7 @@ func findMemoryController(path string) (uint32, error) {
 		line := scanner.Text()
 		fields := strings.Fields(line)
 		if len(fields) == 0 {
-			return 0, fmt.Errorf("failed to parse cgroupv1 controllers: line has no fields: %s", line)
+			return nil, fmt.Errorf("failed to parse cgroupv1 controllers: line has no fields: %s", line)
 		}
 		allControllersNames = append(allControllersNames, fields[0])
 		idx++
@@ -133,15 +246,25 @@ func findMemoryController(path string) (uint32, error) {
 			break
 		}
 	}
+	return allControllersNames, nil
+}
 
-	// we want to find the index for the memory controller
-	for i, name := range allControllersNames {
-		if name == memoryControllerName {
-			return uint32(i), nil
+// findV1Controller returns the index and the name of the cgroupv1 controller used to resolve the cgroups:
+// the one set by the operator with SetCgroupV1ControllerIdx, if any, otherwise the memory controller.
+func findV1Controller(path string, explicitIdx *uint32) (uint32, string, error) {
+	if explicitIdx != nil {
+		name, err := findControllerByIdx(path, *explicitIdx)
+		if err != nil {
+			return 0, "", fmt.Errorf("invalid cgroupv1 controller index: %w", err)
 		}
+		return *explicitIdx, name, nil
 	}
 
-	return 0, fmt.Errorf("no '%s' controller among: %v", memoryControllerName, allControllersNames)
+	idx, err := findMemoryController(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("%w, set the cgroupv1 controller index explicitly", err)
+	}
+	return idx, memoryControllerName, nil
 }
 
 // getMountPointType returns error if the provided path is not a mount point. If it is a mount point, it returns the filesystem type.
@@ -168,33 +291,52 @@ func getMountPointType(path string) (int64, error) {
 	return fst.Type, nil
 }
 
-// GetCgroupInfo retrieves cgroup information such as cgroup root, fs magic and subsys index.
-func getCgroupInfo() (*CgroupInfo, error) {
-	// Today we don't let the user to specify a custom mount point, we just use the default one.
-	// Both in cgroupv1 and cgroupv2 we should have a mount point in `defaultCgroupMountPoint`.
+// getCgroupInfo retrieves cgroup information such as cgroup root, fs magic and subsys index.
+// explicitV1Idx is the cgroupv1 controller index set by the operator, nil to detect it.
+func getCgroupInfo(mountPoint string, explicitV1Idx *uint32) (*CgroupInfo, error) {
+	// Both in cgroupv1 and cgroupv2 we should have a mount point in `mountPoint`.
 	// What changes is the type of the filesystem.
-	fsType, err := getMountPointType(defaultCgroupMountPoint)
+	fsType, err := getMountPointType(mountPoint)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get mount point type for '%s': %w", defaultCgroupMountPoint, err)
+		return nil, fmt.Errorf("cannot get mount point type for '%s': %w", mountPoint, err)
 	}
 
 	switch fsType {
 	// for cgroupv2 the fs type is CGROUP2_SUPER_MAGIC
 	case unix.CGROUP2_SUPER_MAGIC:
 		return &CgroupInfo{
-			cgroupResolutionPrefix: defaultCgroupMountPoint,
+			cgroupResolutionPrefix: mountPoint,
 			fsMagic:                unix.CGROUP2_SUPER_MAGIC,
 			subsysV1Idx:            0, // we are in v2 we don't need the index ebpf side.
+			mode:                   ModeUnified,
 		}, nil
 	// for cgroupv1 or hybrid setup the fs type is TMPFS_MAGIC
 	case unix.TMPFS_MAGIC:
+		// In hybrid mode the cgroupv2 hierarchy is the default one of the kernel: ebpf resolves the cgroup
+		// of the task on it, like the bpf_get_current_cgroup_id helper, with no controller index involved.
+		// The cgroupv1 controller set explicitly by the operator still selects its cgroupv1 hierarchy.
+		mode := ModeLegacy
+		unifiedPath := filepath.Join(mountPoint, unifiedHierarchyName)
+		if unifiedType, unifiedErr := getMountPointType(unifiedPath); unifiedErr == nil &&
+			unifiedType == unix.CGROUP2_SUPER_MAGIC {
+			mode = ModeHybrid
+		}
+		if mode == ModeHybrid && explicitV1Idx == nil {
+			return &CgroupInfo{
+				cgroupResolutionPrefix: unifiedPath,
+				fsMagic:                unix.CGROUP2_SUPER_MAGIC,
+				subsysV1Idx:            0,
+				mode:                   ModeHybrid,
+			}, nil
+		}
 		// If we use Cgroupv1, we need the subsys idx for ebpf.
 		var idx uint32
-		idx, err = findMemoryController(procCgroupPath)
+		var controllerName string
+		idx, controllerName, err = findV1Controller(procCgroupPath, explicitV1Idx)
 		if err != nil {
 			return nil, err
 		}
-		controllerPath := filepath.Join(defaultCgroupMountPoint, memoryControllerName)
+		controllerPath := filepath.Join(mountPoint, controllerName)
 		// we should have a mount point under this controller
 		_, err = getMountPointType(controllerPath)
 		if err != nil {
@@ -204,9 +346,10 @@ func getCgroupInfo() (*CgroupInfo, error) {
 			cgroupResolutionPrefix: controllerPath,
 			fsMagic:                unix.CGROUP_SUPER_MAGIC,
 			subsysV1Idx:            idx,
+			mode:                   mode,
 		}, nil
 	default:
 		// we don't support other fs types
-		return nil, fmt.Errorf("unsupported cgroup filesystem type: %d", fsType)
+		return nil, fmt.Errorf("unsupported cgroup filesystem type %d for '%s'", fsType, mountPoint)
 	}
 }
@@ -67,3 +67,52 @@ pids 3 3 1
 		})
 	}
 }
+
+func TestFindV1Controller(t *testing.T) {
+	tmpfile, err := os.CreateTemp(t.TempDir(), "cgroups_test")
+	require.NoError(t, err)
+	_, err = tmpfile.WriteString(`#subsys_name	hierarchy	num_cgroups	enabled
+cpuset 2 5 1
+pids 9 17 1
+`)
+	require.NoError(t, err)
+	tmpfile.Close()
+
+	// the detection fails: there is no memory controller.
+	_, _, err = findV1Controller(tmpfile.Name(), nil)
+	require.Error(t, err)
+
+	// the controller provided explicitly is accepted.
+	pidsIdx := uint32(1)
+	idx, name, err := findV1Controller(tmpfile.Name(), &pidsIdx)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), idx)
+	require.Equal(t, "pids", name)
+
+	// the controller provided explicitly must exist under /proc/cgroups.
+	unknownIdx := uint32(2)
+	_, _, err = findV1Controller(tmpfile.Name(), &unknownIdx)
+	require.Error(t, err)
+}
+
+func TestGetCgroupInfoMountPoint(t *testing.T) {
+	// a custom mount point which is not a mount point is rejected.
+	dir := t.TempDir()
+	_, err := getCgroupInfo(dir, nil)
+	require.ErrorContains(t, err, "cannot get mount point type for '"+dir+"'")
+	require.ErrorContains(t, err, "does not appear to be a mount point")
+
+	// a mount point which is not a cgroup filesystem is rejected.
+	if _, err = getMountPointType("/proc"); err != nil {
+		t.Skipf("/proc is not a mount point: %v", err)
+	}
+	_, err = getCgroupInfo("/proc", nil)
+	require.ErrorContains(t, err, "unsupported cgroup filesystem type")
+}
+
+func TestModeString(t *testing.T) {
+	require.Equal(t, "unified", ModeUnified.String())
+	require.Equal(t, "legacy", ModeLegacy.String())
+	require.Equal(t, "hybrid", ModeHybrid.String())
+	require.Equal(t, "unknown", Mode(42).String())
+}
@@ -81,6 +81,23 @@ func SystemdExpandSlice(slice string) (string, error) {
 	return pathBuilder.String(), nil
 }
 
+const (
+	// CgroupDriverSystemd is the driver of the runtimes delegating the cgroup management to systemd.
+	CgroupDriverSystemd = "systemd"
+	// CgroupDriverCgroupfs is the driver of the runtimes managing the cgroup filesystem directly.
+	CgroupDriverCgroupfs = "cgroupfs"
+)
+
+// CgroupDriverFromPath returns the cgroup driver of the container runtime from the cgroupsPath of a container.
+// With the systemd driver the path is either in the "slice:prefix:name" form or made of slices,
+// e.g. /kubepods.slice/kubepods-besteffort.slice/..., while with cgroupfs it is a plain path, e.g. /kubepods/besteffort/...
+func CgroupDriverFromPath(cgroupPath string) string {
+	if !strings.Contains(cgroupPath, "/") || strings.Contains(cgroupPath, ".slice") {
+		return CgroupDriverSystemd
+	}
+	return CgroupDriverCgroupfs
+}
+
 // ParseCgroupsPath parses the cgroup path from the CRI response.
 //
 // Example input: kubelet-kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice:cri-containerd:18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240
@@ -50,3 +50,28 @@ func TestSystemdExpandSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestCgroupDriverFromPath(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{
+			in:       "kubelet-kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice:cri-containerd:18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240",
+			expected: CgroupDriverSystemd,
+		},
+		{
+			in:       "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice/cri-containerd-18b2adc8.scope",
+			expected: CgroupDriverSystemd,
+		},
+		{
+			in:       "/kubepods/besteffort/pod83b090de-9676-407c-99aa-d33dc6aa0c0d/18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240",
+			expected: CgroupDriverCgroupfs,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			require.Equal(t, tt.expected, CgroupDriverFromPath(tt.in))
+		})
+	}
+}
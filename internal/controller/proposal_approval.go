@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// approvalAnnotationKeys are the annotations recording the approval of a proposal.
+var approvalAnnotationKeys = []string{
+	securityv1alpha1.ApprovedByAnnotationKey,
+	securityv1alpha1.ApprovedAtAnnotationKey,
+}
+
+// recordApproval records on an approved proposal the user who approved it, as authenticated by the API server,
+// and when. The values set by the client are overwritten, and the ones recorded when the proposal was approved
+// are kept on the later updates, so that the approval copied to the WorkloadPolicy can be trusted.
+func recordApproval(ctx context.Context, proposal *securityv1alpha1.WorkloadPolicyProposal, now time.Time) error {
+	if proposal.Labels[securityv1alpha1.ApprovalLabelKey] != "true" {
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the admission request: %w", err)
+	}
+
+	if req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		var old securityv1alpha1.WorkloadPolicyProposal
+		if err = json.Unmarshal(req.OldObject.Raw, &old); err != nil {
+			return fmt.Errorf("failed to decode the previous WorkloadPolicyProposal: %w", err)
+		}
+		if old.Labels[securityv1alpha1.ApprovalLabelKey] == "true" {
+			for _, key := range approvalAnnotationKeys {
+				if value, ok := old.Annotations[key]; ok {
+					metav1.SetMetaDataAnnotation(&proposal.ObjectMeta, key, value)
+				} else {
+					delete(proposal.Annotations, key)
+				}
+			}
+			return nil
+		}
+	}
+
+	metav1.SetMetaDataAnnotation(
+		&proposal.ObjectMeta, securityv1alpha1.ApprovedByAnnotationKey, req.UserInfo.Username)
+	metav1.SetMetaDataAnnotation(
+		&proposal.ObjectMeta, securityv1alpha1.ApprovedAtAnnotationKey, now.UTC().Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestRecordApproval(t *testing.T) {
+	approvedAt := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	newProposal := func(approved bool, annotations map[string]string) *v1alpha1.WorkloadPolicyProposal {
+		proposal := &v1alpha1.WorkloadPolicyProposal{
+			ObjectMeta: metav1.ObjectMeta{Name: "deploy-api", Namespace: "test", Annotations: annotations},
+		}
+		if approved {
+			proposal.Labels = map[string]string{v1alpha1.ApprovalLabelKey: "true"}
+		}
+		return proposal
+	}
+	requestContext := func(
+		operation admissionv1.Operation,
+		user string,
+		old *v1alpha1.WorkloadPolicyProposal,
+	) context.Context {
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			UserInfo:  authenticationv1.UserInfo{Username: user},
+		}}
+		if old != nil {
+			raw, err := json.Marshal(old)
+			require.NoError(t, err)
+			req.OldObject = runtime.RawExtension{Raw: raw}
+		}
+		return admission.NewContextWithRequest(context.Background(), req)
+	}
+
+	// the proposals not approved are left untouched, without needing the admission request.
+	proposal := newProposal(false, nil)
+	require.NoError(t, recordApproval(context.Background(), proposal, approvedAt))
+	require.Empty(t, proposal.Annotations)
+
+	// the approver set by the client is replaced by the authenticated user.
+	proposal = newProposal(true, map[string]string{v1alpha1.ApprovedByAnnotationKey: "someone-else"})
+	ctx := requestContext(admissionv1.Update, "alice@example.com", newProposal(false, nil))
+	require.NoError(t, recordApproval(ctx, proposal, approvedAt))
+	require.Equal(t, map[string]string{
+		v1alpha1.ApprovedByAnnotationKey: "alice@example.com",
+		v1alpha1.ApprovedAtAnnotationKey: "2026-10-16T08:00:00Z",
+	}, proposal.Annotations)
+
+	// the approval is kept on the later updates of the approved proposal.
+	previous := proposal.DeepCopy()
+	proposal.Annotations[v1alpha1.ApprovedByAnnotationKey] = "mallory@example.com"
+	ctx = requestContext(admissionv1.Update, "mallory@example.com", previous)
+	require.NoError(t, recordApproval(ctx, proposal, approvedAt.Add(time.Hour)))
+	require.Equal(t, previous.Annotations, proposal.Annotations)
+
+	// a proposal created approved is recorded too.
+	proposal = newProposal(true, nil)
+	ctx = requestContext(admissionv1.Create, "bob@example.com", nil)
+	require.NoError(t, recordApproval(ctx, proposal, approvedAt))
+	require.Equal(t, "bob@example.com", proposal.Annotations[v1alpha1.ApprovedByAnnotationKey])
+}
+
+func TestDefaultApprovedProposal(t *testing.T) {
+	webhook := &ProposalWebhook{}
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "alice@example.com"},
+		},
+	})
+
+	// an approved proposal without owner reference can still be updated, its approval is recorded.
+	proposal := &v1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "manual",
+			Namespace: "test",
+			Labels:    map[string]string{v1alpha1.ApprovalLabelKey: "true"},
+		},
+	}
+	require.NoError(t, webhook.Default(ctx, proposal))
+	require.Equal(t, "alice@example.com", proposal.Annotations[v1alpha1.ApprovedByAnnotationKey])
+	require.Empty(t, proposal.OwnerReferences)
+
+	// and so can an approved proposal whose owner reference is complete.
+	proposal = &v1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "deploy-api",
+			Namespace:       "test",
+			Labels:          map[string]string{v1alpha1.ApprovalLabelKey: "true"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "api", UID: "1234"}},
+		},
+	}
+	require.NoError(t, webhook.Default(ctx, proposal))
+	require.Equal(t, []metav1.OwnerReference{{Kind: "Deployment", Name: "api", UID: "1234"}}, proposal.OwnerReferences)
+
+	// the proposals not approved still need a single owner reference.
+	proposal = &v1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{Name: "manual", Namespace: "test"},
+	}
+	require.ErrorContains(t, webhook.Default(ctx, proposal), "only one owner reference is expected")
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/grpcexporter"
+)
+
+// observedPolicy tracks the allowed executables of a single policy and the ones run on any node.
+type observedPolicy struct {
+	// listedSince maps container names to the allowed executables and when they were first found in the spec.
+	listedSince map[string]map[string]time.Time
+	// observed maps container names to the executables run on any node, folded with case-insensitive matching.
+	observed map[string]map[string]struct{}
+}
+
+func newObservedPolicy() *observedPolicy {
+	return &observedPolicy{
+		listedSince: make(map[string]map[string]time.Time),
+		observed:    make(map[string]map[string]struct{}),
+	}
+}
+
+func (p *observedPolicy) addObserved(container, exe string) {
+	exes, ok := p.observed[container]
+	if !ok {
+		exes = make(map[string]struct{})
+		p.observed[container] = exes
+	}
+	exes[exe] = struct{}{}
+}
+
+// syncListed starts the observation of the executables newly allowed by the spec
+// and forgets the ones no longer allowed, so that they start over if they are allowed again.
+func (p *observedPolicy) syncListed(wp *v1alpha1.WorkloadPolicy, now time.Time) {
+	for container := range p.listedSince {
+		if rules := wp.Spec.RulesByContainer[container]; rules == nil {
+			delete(p.listedSince, container)
+		}
+	}
+	for container, rules := range wp.Spec.RulesByContainer {
+		if rules == nil {
+			continue
+		}
+		listed, ok := p.listedSince[container]
+		if !ok {
+			listed = make(map[string]time.Time, len(rules.Executables.Allowed))
+			p.listedSince[container] = listed
+		}
+		for exe := range listed {
+			if !slices.Contains(rules.Executables.Allowed, exe) {
+				delete(listed, exe)
+			}
+		}
+		for _, exe := range rules.Executables.Allowed {
+			if _, ok = listed[exe]; !ok {
+				listed[exe] = now
+			}
+		}
+	}
+}
+
+// executableObserver reports the allowed executables of each policy that have not been run on any node
+// for at least a period. They are likely typos or dead entries of the allow lists.
+// It is not safe for concurrent use, the status sync is single-threaded.
+type executableObserver struct {
+	period   time.Duration
+	policies map[string]*observedPolicy
+}
+
+func newExecutableObserver(period time.Duration) *executableObserver {
+	return &executableObserver{
+		period:   period,
+		policies: make(map[string]*observedPolicy),
+	}
+}
+
+// collect accounts the executables run with the policy on the nodes, as reported by the agents,
+// and returns the sorted allowed executables of each container never run since they have been
+// allowed for at least the period. It returns nil if there are none.
+func (o *executableObserver) collect(
+	wp *v1alpha1.WorkloadPolicy,
+	nodesInfo nodesInfoMap,
+	now time.Time,
+) map[string][]string {
+	policy := wp.NamespacedName()
+	p, ok := o.policies[policy]
+	if !ok {
+		p = newObservedPolicy()
+		o.policies[policy] = p
+	}
+
+	for _, info := range nodesInfo {
+		status := info.policies[policy]
+		if status == nil {
+			continue
+		}
+		for _, entry := range status.GetObservedExecutables() {
+			container, exe, valid := grpcexporter.ParseObservedExecutable(entry)
+			if !valid {
+				continue
+			}
+			p.addObserved(container, exe)
+		}
+	}
+	p.syncListed(wp, now)
+
+	var unobserved map[string][]string
+	for container, listed := range p.listedSince {
+		for exe, since := range listed {
+			if now.Sub(since) < o.period {
+				continue
+			}
+			observedExe := exe
+			if wp.Spec.CaseInsensitiveMatching {
+				// the agents report the executables as they are written into BPF.
+				observedExe = foldPathCase(exe)
+			}
+			if _, seen := p.observed[container][observedExe]; seen {
+				continue
+			}
+			if unobserved == nil {
+				unobserved = make(map[string][]string)
+			}
+			unobserved[container] = append(unobserved[container], exe)
+		}
+	}
+	for _, exes := range unobserved {
+		slices.Sort(exes)
+	}
+	return unobserved
+}
+
+// retain drops the observations of the policies that no longer exist.
+func (o *executableObserver) retain(policies map[string]struct{}) {
+	for policy := range o.policies {
+		if _, ok := policies[policy]; !ok {
+			delete(o.policies, policy)
+		}
+	}
+}
+
+// foldPathCase lowercases the ASCII letters of path, as the agent does for case-insensitive policies.
+// It mirrors bpf.FoldPathCase, which is not imported to keep the BPF objects out of the controller.
+func foldPathCase(path string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, path)
+}
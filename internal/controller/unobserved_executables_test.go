@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExecutableObserver(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newPolicy := func(allowed ...string) *v1alpha1.WorkloadPolicy {
+		return &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"c": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: allowed}},
+				},
+			},
+		}
+	}
+	nodesObserving := func(entries ...string) nodesInfoMap {
+		return nodesInfoMap{
+			"node-1": {policies: map[string]*pb.PolicyStatus{
+				"ns/policy": {ObservedExecutables: entries},
+			}},
+		}
+	}
+
+	t.Run("reports the executables never run once the period is elapsed", func(t *testing.T) {
+		o := newExecutableObserver(time.Hour)
+		wp := newPolicy("/usr/bin/sleep", "/usr/bin/sleeep", "/usr/bin/true")
+
+		require.Nil(t, o.collect(wp, nodesObserving("c:/usr/bin/sleep"), start))
+		require.Nil(t, o.collect(wp, nodesObserving(), start.Add(59*time.Minute)))
+
+		// The executables observed in a previous sync are remembered, other containers don't count.
+		unobserved := o.collect(wp, nodesObserving("other:/usr/bin/true"), start.Add(time.Hour))
+		require.Equal(t, map[string][]string{"c": {"/usr/bin/sleeep", "/usr/bin/true"}}, unobserved)
+
+		unobserved = o.collect(wp, nodesObserving("c:/usr/bin/true"), start.Add(2*time.Hour))
+		require.Equal(t, map[string][]string{"c": {"/usr/bin/sleeep"}}, unobserved)
+	})
+
+	t.Run("executables allowed later start their own period", func(t *testing.T) {
+		o := newExecutableObserver(time.Hour)
+
+		require.Nil(t, o.collect(newPolicy("/usr/bin/a"), nil, start))
+		wp := newPolicy("/usr/bin/a", "/usr/bin/b")
+		unobserved := o.collect(wp, nil, start.Add(30*time.Minute))
+		require.Nil(t, unobserved)
+		unobserved = o.collect(wp, nil, start.Add(time.Hour))
+		require.Equal(t, map[string][]string{"c": {"/usr/bin/a"}}, unobserved)
+
+		// Removing an executable and allowing it again restarts its period.
+		unobserved = o.collect(newPolicy("/usr/bin/b"), nil, start.Add(90*time.Minute))
+		require.Equal(t, map[string][]string{"c": {"/usr/bin/b"}}, unobserved)
+		unobserved = o.collect(wp, nil, start.Add(2*time.Hour))
+		require.Equal(t, map[string][]string{"c": {"/usr/bin/b"}}, unobserved)
+	})
+
+	t.Run("case-insensitive policies match the folded executables", func(t *testing.T) {
+		o := newExecutableObserver(time.Hour)
+		wp := newPolicy("/App/Run", "/App/Stop")
+		wp.Spec.CaseInsensitiveMatching = true
+
+		unobserved := o.collect(wp, nodesObserving("c:/app/run"), start.Add(time.Hour))
+		require.Nil(t, unobserved)
+		unobserved = o.collect(wp, nil, start.Add(2*time.Hour))
+		require.Equal(t, map[string][]string{"c": {"/App/Stop"}}, unobserved)
+	})
+
+	t.Run("drops the observations of deleted policies", func(t *testing.T) {
+		o := newExecutableObserver(time.Hour)
+		o.collect(newPolicy("/usr/bin/a"), nil, start)
+		require.Len(t, o.policies, 1)
+
+		o.retain(map[string]struct{}{"ns/policy": {}})
+		require.Len(t, o.policies, 1)
+		o.retain(map[string]struct{}{})
+		require.Empty(t, o.policies)
+	})
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxTrackedExecutables bounds the memory used by the window of a single policy.
+// Violations of executables beyond this limit are only accounted in the total count.
+const maxTrackedExecutables = 1000
+
+// violationWindow aggregates the violations of a single policy during the current reporting window.
+type violationWindow struct {
+	start        time.Time
+	total        int64
+	byExecutable map[string]*v1alpha1.ExecutableViolationSummary
+}
+
+func newViolationWindow(start time.Time) *violationWindow {
+	return &violationWindow{
+		start:        start,
+		byExecutable: make(map[string]*v1alpha1.ExecutableViolationSummary),
+	}
+}
+
+func (w *violationWindow) add(rec v1alpha1.ViolationRecord) {
+	w.total++
+
+	exe, ok := w.byExecutable[rec.ExecutablePath]
+	if !ok {
+		if len(w.byExecutable) >= maxTrackedExecutables {
+			return
+		}
+		exe = &v1alpha1.ExecutableViolationSummary{
+			ExecutablePath: rec.ExecutablePath,
+			FirstSeen:      rec.Timestamp,
+			LastSeen:       rec.Timestamp,
+		}
+		w.byExecutable[rec.ExecutablePath] = exe
+	}
+
+	exe.Count++
+	if rec.Action == policymode.ProtectString {
+		exe.BlockedCount++
+	}
+	if rec.Timestamp.Before(&exe.FirstSeen) {
+		exe.FirstSeen = rec.Timestamp
+	}
+	if exe.LastSeen.Before(&rec.Timestamp) {
+		exe.LastSeen = rec.Timestamp
+	}
+}
+
+// summary returns the top MaxSummaryExecutables executables sorted by count, ties are sorted by path.
+func (w *violationWindow) summary(end time.Time) *v1alpha1.ViolationSummary {
+	top := make([]v1alpha1.ExecutableViolationSummary, 0, len(w.byExecutable))
+	for _, exe := range w.byExecutable {
+		top = append(top, *exe)
+	}
+	slices.SortFunc(top, func(a, b v1alpha1.ExecutableViolationSummary) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.ExecutablePath, b.ExecutablePath))
+	})
+	if len(top) > v1alpha1.MaxSummaryExecutables {
+		top = top[:v1alpha1.MaxSummaryExecutables]
+	}
+	if len(top) == 0 {
+		top = nil
+	}
+
+	return &v1alpha1.ViolationSummary{
+		WindowStart:    metav1.NewTime(w.start),
+		WindowEnd:      metav1.NewTime(end),
+		TotalCount:     w.total,
+		TopExecutables: top,
+	}
+}
+
+// violationAggregator aggregates the scraped violations of each policy into a summary produced every interval.
+// It is not safe for concurrent use, the status sync is single-threaded.
+type violationAggregator struct {
+	interval time.Duration
+	windows  map[string]*violationWindow
+}
+
+func newViolationAggregator(interval time.Duration) *violationAggregator {
+	return &violationAggregator{
+		interval: interval,
+		windows:  make(map[string]*violationWindow),
+	}
+}
+
+// collect accounts the violations of the policy in its current window.
+// When the window lasted at least the interval, it returns its summary and opens a new window,
+// otherwise it returns nil.
+func (a *violationAggregator) collect(
+	policy string,
+	records []v1alpha1.ViolationRecord,
+	now time.Time,
+) *v1alpha1.ViolationSummary {
+	w, ok := a.windows[policy]
+	if !ok {
+		w = newViolationWindow(now)
+		a.windows[policy] = w
+	}
+	for _, rec := range records {
+		w.add(rec)
+	}
+
+	if now.Sub(w.start) < a.interval {
+		return nil
+	}
+	a.windows[policy] = newViolationWindow(now)
+	return w.summary(now)
+}
+
+// retain drops the windows of the policies that no longer exist.
+func (a *violationAggregator) retain(policies map[string]struct{}) {
+	for policy := range a.windows {
+		if _, ok := policies[policy]; !ok {
+			delete(a.windows, policy)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestViolationAggregator(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rec := func(path string, sec int, action string) v1alpha1.ViolationRecord {
+		return v1alpha1.ViolationRecord{
+			Timestamp:      metav1.NewTime(start.Add(time.Duration(sec) * time.Second)),
+			PodName:        "pod",
+			ContainerName:  "c",
+			ExecutablePath: path,
+			NodeName:       "node-1",
+			Action:         action,
+		}
+	}
+	const policy = "ns/policy"
+
+	t.Run("produces top-N and counts once the window is completed", func(t *testing.T) {
+		a := newViolationAggregator(time.Hour)
+
+		// Synthetic stream: executable i is violated i+1 times, more executables than MaxSummaryExecutables.
+		var stream []v1alpha1.ViolationRecord
+		for i := range v1alpha1.MaxSummaryExecutables + 5 {
+			for j := range i + 1 {
+				stream = append(stream, rec(fmt.Sprintf("/bin/exe-%02d", i), j, policymode.MonitorString))
+			}
+		}
+		half := len(stream) / 2
+		require.Nil(t, a.collect(policy, stream[:half], start))
+		require.Nil(t, a.collect(policy, stream[half:], start.Add(30*time.Minute)))
+		// Blocked executions are accounted too, events may come out of order.
+		require.Nil(t, a.collect(policy, []v1alpha1.ViolationRecord{
+			rec("/bin/exe-14", -10, policymode.ProtectString),
+		}, start.Add(59*time.Minute)))
+
+		end := start.Add(time.Hour)
+		summary := a.collect(policy, nil, end)
+		require.NotNil(t, summary)
+		require.Equal(t, metav1.NewTime(start), summary.WindowStart)
+		require.Equal(t, metav1.NewTime(end), summary.WindowEnd)
+		require.Equal(t, int64(len(stream)+1), summary.TotalCount)
+		require.Len(t, summary.TopExecutables, v1alpha1.MaxSummaryExecutables)
+		require.Equal(t, v1alpha1.ExecutableViolationSummary{
+			ExecutablePath: "/bin/exe-14",
+			Count:          16,
+			BlockedCount:   1,
+			FirstSeen:      metav1.NewTime(start.Add(-10 * time.Second)),
+			LastSeen:       metav1.NewTime(start.Add(14 * time.Second)),
+		}, summary.TopExecutables[0])
+		for i, exe := range summary.TopExecutables[1:] {
+			require.Equal(t, fmt.Sprintf("/bin/exe-%02d", 13-i), exe.ExecutablePath)
+			require.Equal(t, int64(14-i), exe.Count)
+			require.Zero(t, exe.BlockedCount)
+		}
+
+		// A new empty window is opened.
+		summary = a.collect(policy, nil, end.Add(time.Hour))
+		require.NotNil(t, summary)
+		require.Zero(t, summary.TotalCount)
+		require.Nil(t, summary.TopExecutables)
+	})
+
+	t.Run("ties are sorted by path", func(t *testing.T) {
+		a := newViolationAggregator(time.Minute)
+		summary := a.collect(policy, []v1alpha1.ViolationRecord{
+			rec("/bin/b", 0, policymode.MonitorString),
+			rec("/bin/a", 1, policymode.MonitorString),
+		}, start)
+		require.Nil(t, summary)
+		summary = a.collect(policy, nil, start.Add(time.Minute))
+		require.Equal(t, "/bin/a", summary.TopExecutables[0].ExecutablePath)
+		require.Equal(t, "/bin/b", summary.TopExecutables[1].ExecutablePath)
+	})
+
+	t.Run("memory is bounded", func(t *testing.T) {
+		a := newViolationAggregator(time.Minute)
+		var stream []v1alpha1.ViolationRecord
+		for i := range maxTrackedExecutables + 10 {
+			stream = append(stream, rec(fmt.Sprintf("/bin/exe-%d", i), 0, policymode.MonitorString))
+		}
+		require.Nil(t, a.collect(policy, stream, start))
+		require.Len(t, a.windows[policy].byExecutable, maxTrackedExecutables)
+
+		summary := a.collect(policy, nil, start.Add(time.Minute))
+		require.Equal(t, int64(maxTrackedExecutables+10), summary.TotalCount)
+	})
+
+	t.Run("windows of deleted policies are dropped", func(t *testing.T) {
+		a := newViolationAggregator(time.Minute)
+		a.collect("ns/a", nil, start)
+		a.collect("ns/b", nil, start)
+		a.retain(map[string]struct{}{"ns/a": {}})
+		require.Contains(t, a.windows, "ns/a")
+		require.NotContains(t, a.windows, "ns/b")
+	})
+}
+
+func TestBuildPolicyStatusKeepsViolationSummary(t *testing.T) {
+	summary := &v1alpha1.ViolationSummary{TotalCount: 3}
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec:       v1alpha1.WorkloadPolicySpec{Mode: policymode.MonitorString},
+		Status:     v1alpha1.WorkloadPolicyStatus{ViolationSummary: summary},
+	}
+
+	status, err := buildPolicyStatus(wp, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, summary, status.ViolationSummary)
+}
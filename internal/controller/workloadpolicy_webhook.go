@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -17,7 +19,7 @@ import (
 // maxPodNames avoids oversized response.
 const maxPodNames = 10
 
-// +kubebuilder:webhook:path=/validate-security-rancher-io-v1alpha1-workloadpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=security.rancher.io,resources=workloadpolicies,verbs=delete,versions=v1alpha1,name=validate-workloadpolicies.rancher.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-security-rancher-io-v1alpha1-workloadpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=security.rancher.io,resources=workloadpolicies,verbs=create;update;delete,versions=v1alpha1,name=validate-workloadpolicies.rancher.io,admissionReviewVersions=v1
 
 type PolicyCustomValidator struct {
 	Client client.Client
@@ -31,7 +33,7 @@ func (v *PolicyCustomValidator) ValidateCreate(
 ) (admission.Warnings, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Validation for WorkloadPolicy upon creation", "name", policy.GetName())
-	return nil, nil
+	return validatePolicySpec(policy)
 }
 
 func (v *PolicyCustomValidator) ValidateUpdate(
@@ -40,7 +42,37 @@ func (v *PolicyCustomValidator) ValidateUpdate(
 ) (admission.Warnings, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Validation for WorkloadPolicy upon update", "name", newPolicy.GetName())
-	return nil, nil
+	return validatePolicySpec(newPolicy)
+}
+
+// validatePolicySpec rejects the policy with the errors of v1alpha1.ValidateWorkloadPolicy,
+// its warnings are returned to the user.
+func validatePolicySpec(policy *v1alpha1.WorkloadPolicy) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var invalid field.ErrorList
+	for _, err := range v1alpha1.ValidateWorkloadPolicy(&policy.Spec) {
+		var validationErr *v1alpha1.ValidationError
+		switch {
+		case !errors.As(err, &validationErr):
+			invalid = append(invalid, field.InternalError(field.NewPath("spec"), err))
+		case validationErr.Warning:
+			warnings = append(warnings, validationErr.Error())
+		default:
+			invalid = append(invalid, &field.Error{
+				Type:   field.ErrorTypeInvalid,
+				Field:  validationErr.Field,
+				Detail: validationErr.Message,
+			})
+		}
+	}
+	if len(invalid) > 0 {
+		return warnings, apierrors.NewInvalid(
+			schema.GroupKind{Group: "security.rancher.io", Kind: "WorkloadPolicy"},
+			policy.Name,
+			invalid,
+		)
+	}
+	return warnings, nil
 }
 
 func (v *PolicyCustomValidator) ValidateDelete(
@@ -56,6 +56,27 @@ var _ = Describe("WorkloadPolicy Webhook", func() {
 		}))).To(Succeed())
 	})
 
+	Context("ValidateCreate", func() {
+		It("allows a valid policy and warns about the duplicate executables", func() {
+			policy.Spec.RulesByContainer[containerName].Executables.Allowed = []string{
+				"/usr/bin/sleep",
+				"/usr/bin/sleep",
+			}
+			warns, err := validator.ValidateCreate(ctx, policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warns).To(HaveLen(1))
+			Expect(warns[0]).To(ContainSubstring("duplicate"))
+		})
+
+		It("denies a policy with a relative executable path", func() {
+			policy.Spec.RulesByContainer[containerName].Executables.Allowed = []string{"sleep"}
+			_, err := validator.ValidateCreate(ctx, policy)
+			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.rulesByContainer[test-container].executables.allowed[0]"))
+		})
+	})
+
 	Context("ValidateDelete", func() {
 		It("allows deletion when no pods reference the policy", func() {
 			warns, err := validator.ValidateDelete(ctx, policy)
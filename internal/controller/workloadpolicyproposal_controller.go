@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
@@ -67,6 +69,10 @@ func (r *WorkloadPolicyProposalReconciler) Reconcile(
 		return ctrl.Result{}, nil
 	}
 
+	if err = r.updatePolicyDiff(ctx, &policyProposal); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	labels := policyProposal.GetLabels()
 	approved := labels[securityv1alpha1.ApprovalLabelKey] == "true"
 
@@ -84,6 +90,12 @@ func (r *WorkloadPolicyProposalReconciler) Reconcile(
 		},
 		Spec: policyProposal.Spec.IntoWorkloadPolicySpec(),
 	}
+	// the approval annotations are kept on the policy to audit who approved it and when.
+	for _, key := range []string{securityv1alpha1.ApprovedByAnnotationKey, securityv1alpha1.ApprovedAtAnnotationKey} {
+		if value, ok := policyProposal.Annotations[key]; ok {
+			metav1.SetMetaDataAnnotation(&policy.ObjectMeta, key, value)
+		}
+	}
 
 	if err = r.Create(ctx, &policy); err != nil {
 		if apierrors.IsAlreadyExists(err) {
@@ -92,6 +104,9 @@ func (r *WorkloadPolicyProposalReconciler) Reconcile(
 		}
 		return ctrl.Result{}, fmt.Errorf("failed to create WorkloadPolicy: %w", err)
 	}
+	log.Info("WorkloadPolicyProposal promoted",
+		"policy", policy.NamespacedName(),
+		"approvedBy", policyProposal.Annotations[securityv1alpha1.ApprovedByAnnotationKey])
 
 	// Once we successfully promote the proposal into a policy, we no longer
 	// need the proposal to remain in the cluster.
@@ -102,10 +117,45 @@ func (r *WorkloadPolicyProposalReconciler) Reconcile(
 	return ctrl.Result{}, nil
 }
 
+// updatePolicyDiff records in the proposal status the executables added and removed compared to
+// the WorkloadPolicy with the same name, so that the approval is an informed decision.
+func (r *WorkloadPolicyProposalReconciler) updatePolicyDiff(
+	ctx context.Context,
+	policyProposal *securityv1alpha1.WorkloadPolicyProposal,
+) error {
+	var status securityv1alpha1.WorkloadPolicyProposalStatus
+
+	var policy securityv1alpha1.WorkloadPolicy
+	err := r.Get(ctx, client.ObjectKeyFromObject(policyProposal), &policy)
+	switch {
+	case apierrors.IsNotFound(err):
+		// no enforced policy to compare with.
+	case err != nil:
+		return fmt.Errorf("failed to get WorkloadPolicy '%s': %w", policyProposal.NamespacedName(), err)
+	default:
+		status.PolicyGeneration = policy.Generation
+		status.DiffByContainer = securityv1alpha1.DiffRulesByContainer(
+			policyProposal.Spec.RulesByContainer,
+			policy.Spec.RulesByContainer,
+		)
+	}
+
+	if equality.Semantic.DeepEqual(policyProposal.Status, status) {
+		return nil
+	}
+	policyProposal.Status = status
+	if err = r.Status().Update(ctx, policyProposal); err != nil {
+		return fmt.Errorf("failed to update WorkloadPolicyProposal status: %w", err)
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *WorkloadPolicyProposalReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&securityv1alpha1.WorkloadPolicyProposal{}).
+		// a change of the WorkloadPolicy refreshes the diff of the proposal with the same name.
+		Watches(&securityv1alpha1.WorkloadPolicy{}, &handler.EnqueueRequestForObject{}).
 		Named("workloadpolicyproposal").
 		Complete(r)
 }
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileApprovedProposals(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	newProposal := func(name string, approved bool) *v1alpha1.WorkloadPolicyProposal {
+		proposal := &v1alpha1.WorkloadPolicyProposal{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+				Labels:    map[string]string{"team": "payments"},
+			},
+		}
+		if approved {
+			proposal.Labels[v1alpha1.ApprovalLabelKey] = "true"
+			proposal.Annotations = map[string]string{
+				v1alpha1.ApprovedByAnnotationKey: "alice@example.com",
+				v1alpha1.ApprovedAtAnnotationKey: "2026-01-01T00:00:00Z",
+			}
+		}
+		return proposal
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newProposal("deploy-api", true),
+			newProposal("deploy-worker", true),
+			newProposal("deploy-frontend", false),
+		).
+		WithStatusSubresource(&v1alpha1.WorkloadPolicyProposal{}).
+		Build()
+	r := &WorkloadPolicyProposalReconciler{Client: cl, Scheme: scheme}
+
+	for _, name := range []string{"deploy-api", "deploy-worker", "deploy-frontend"} {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "test", Name: name}})
+		require.NoError(t, err)
+	}
+
+	// each approved proposal is promoted, with its approval recorded on the policy.
+	for _, name := range []string{"deploy-api", "deploy-worker"} {
+		var policy v1alpha1.WorkloadPolicy
+		require.NoError(t, cl.Get(ctx, types.NamespacedName{Namespace: "test", Name: name}, &policy))
+		require.Equal(t, name, policy.Labels[v1alpha1.PromotedFromLabelKey])
+		require.Equal(t, "alice@example.com", policy.Annotations[v1alpha1.ApprovedByAnnotationKey])
+		require.Equal(t, "2026-01-01T00:00:00Z", policy.Annotations[v1alpha1.ApprovedAtAnnotationKey])
+
+		var proposal v1alpha1.WorkloadPolicyProposal
+		err := cl.Get(ctx, types.NamespacedName{Namespace: "test", Name: name}, &proposal)
+		require.True(t, apierrors.IsNotFound(err))
+	}
+
+	var policy v1alpha1.WorkloadPolicy
+	err := cl.Get(ctx, types.NamespacedName{Namespace: "test", Name: "deploy-frontend"}, &policy)
+	require.True(t, apierrors.IsNotFound(err))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -114,10 +115,21 @@ func (p *ProposalWebhook) updateResource(
 
 // Default filling ownerReferences and selectors fields based on the high level resource defined
 // in its ownerReferences, where caller still need to specify its kind and name.
+// It also records who approved the proposal, see recordApproval.
 func (p *ProposalWebhook) Default(ctx context.Context, proposal *securityv1alpha1.WorkloadPolicyProposal) error {
 	logger := log.FromContext(ctx)
 	logger.Info("mutating resource")
 
+	if err := recordApproval(ctx, proposal, time.Now()); err != nil {
+		return err
+	}
+
+	// The approved proposals also reach the webhook to record their approval, the owner reference is only
+	// completed for the ones created by the agents with a partial owner reference.
+	if proposal.Labels[securityv1alpha1.ApprovalLabelKey] == "true" && !hasPartialOwnerReference(proposal) {
+		return nil
+	}
+
 	if len(proposal.OwnerReferences) != 1 {
 		return &ProposalValidatorError{
 			status: metav1.Status{
@@ -155,3 +167,9 @@ func (p *ProposalWebhook) Default(ctx context.Context, proposal *securityv1alpha
 
 	return p.updateResource(ctx, proposal)
 }
+
+// hasPartialOwnerReference returns true if the proposal has a single owner reference without UID, as created
+// by the agents.
+func hasPartialOwnerReference(proposal *securityv1alpha1.WorkloadPolicyProposal) bool {
+	return len(proposal.OwnerReferences) == 1 && proposal.OwnerReferences[0].UID == ""
+}
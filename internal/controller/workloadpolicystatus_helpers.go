@@ -3,11 +3,13 @@ package controller
 import (
 	"context"
 	"fmt"
+	"maps"
 	"slices"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/loglevel"
 	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func convertToPolicyMode(mode string) pb.PolicyMode {
@@ -16,6 +18,8 @@ func convertToPolicyMode(mode string) pb.PolicyMode {
 		return pb.PolicyMode_POLICY_MODE_PROTECT
 	case "monitor":
 		return pb.PolicyMode_POLICY_MODE_MONITOR
+	case "audit":
+		return pb.PolicyMode_POLICY_MODE_AUDIT
 	default:
 		panic(fmt.Sprintf("unhandled policy mode: %v", mode))
 	}
@@ -30,7 +34,9 @@ func computeWpStatus(
 		TotalNodes: len(nodesInfo),
 	}
 
-	for nodeName, nodeInfo := range nodesInfo {
+	// we visit the nodes in order, so that the truncated lists of nodes don't change at each sync.
+	for _, nodeName := range slices.Sorted(maps.Keys(nodesInfo)) {
+		nodeInfo := nodesInfo[nodeName]
 		// If we previously detected that the policy is not deployed on this node, we can skip it.
 		if nodeInfo.issue.Code != v1alpha1.NodeIssueNone {
 			status.AddNodeIssue(nodeName, nodeInfo.issue)
@@ -54,7 +60,12 @@ func computeWpStatus(
 
 		switch policyStatus.GetState() {
 		case pb.PolicyState_POLICY_STATE_READY:
-			if policyStatus.GetMode() == expectedMode {
+			status.AddEnforcingNode(nodeName, policyStatus.GetEnforcerVersion())
+			for _, impact := range policyStatus.GetDryRunImpact() {
+				status.AddDryRunImpact(impact.GetContainerName(), impact.GetCount(), impact.GetExecutables())
+			}
+			// an agent forcing the monitor mode never enforces the declared mode, it is not transitioning.
+			if policyStatus.GetMode() == expectedMode || policyStatus.GetModeForced() {
 				status.SuccessfulNodes++
 				break
 			}
@@ -99,7 +110,7 @@ func buildPolicyStatus(
 	nodesInfo nodesInfoMap,
 	scrapedViolations []v1alpha1.ViolationRecord,
 ) (v1alpha1.WorkloadPolicyStatus, error) {
-	newStatus, err := computeWpStatus(nodesInfo, convertToPolicyMode(wp.Spec.Mode), wp.NamespacedName())
+	newStatus, err := computeWpStatus(nodesInfo, convertToPolicyMode(wp.EffectiveMode()), wp.NamespacedName())
 	if err != nil {
 		return v1alpha1.WorkloadPolicyStatus{}, fmt.Errorf(
 			"failed to compute status for policy %s: %w",
@@ -113,6 +124,9 @@ func buildPolicyStatus(
 	// then trim to the most recent MaxViolationRecords entries.
 	newStatus.Violations = mergeViolations(wp.Status.Violations, scrapedViolations)
 	newStatus.ViolationCount = wp.Status.ViolationCount + int64(len(scrapedViolations))
+	newStatus.LastViolationTime, newStatus.LastViolationPath = lastViolation(wp.Status, scrapedViolations)
+	// The summary is only replaced when a reporting window is completed.
+	newStatus.ViolationSummary = wp.Status.ViolationSummary
 	return newStatus, nil
 }
 
@@ -121,11 +135,17 @@ func (r *WorkloadPolicyStatusSync) processWorkloadPolicy(
 	wp *v1alpha1.WorkloadPolicy,
 	nodesInfo nodesInfoMap,
 	scrapedViolations []v1alpha1.ViolationRecord,
+	summary *v1alpha1.ViolationSummary,
+	unobserved map[string][]string,
 ) error {
 	status, err := buildPolicyStatus(wp, nodesInfo, scrapedViolations)
 	if err != nil {
 		return err
 	}
+	if summary != nil {
+		status.ViolationSummary = summary
+	}
+	status.UnobservedExecutables = unobserved
 	newPolicy := wp.DeepCopy()
 	newPolicy.Status = status
 
@@ -135,6 +155,25 @@ func (r *WorkloadPolicyStatusSync) processWorkloadPolicy(
 	return r.Status().Update(ctx, newPolicy)
 }
 
+// lastViolation returns the time and the executable of the most recent violation among the ones
+// already reported in the status and the scraped ones. The scraped violations are not sorted:
+// each agent reports its own.
+func lastViolation(
+	status v1alpha1.WorkloadPolicyStatus,
+	scraped []v1alpha1.ViolationRecord,
+) (*metav1.Time, string) {
+	lastTime, lastPath := status.LastViolationTime, status.LastViolationPath
+	for i := range scraped {
+		if lastTime == nil || lastTime.Before(&scraped[i].Timestamp) {
+			lastTime, lastPath = &scraped[i].Timestamp, scraped[i].ExecutablePath
+		}
+	}
+	if lastTime == nil {
+		return nil, ""
+	}
+	return lastTime.DeepCopy(), lastPath
+}
+
 // mergeViolations prepends scraped violations to the existing list,
 // trimming the tail (oldest) to keep only the most recent MaxViolationRecords.
 // The resulting list is ordered newest-to-oldest.
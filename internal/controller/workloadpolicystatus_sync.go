@@ -36,13 +36,23 @@ type WorkloadPolicyStatusSync struct {
 
 	agentClientPool *grpcexporter.AgentClientPool
 	updateInterval  time.Duration
-	logger          logr.Logger
+	// violationAggregator is nil when the periodic violation summary is disabled.
+	violationAggregator *violationAggregator
+	// executableObserver is nil when the report of the unobserved executables is disabled.
+	executableObserver *executableObserver
+	logger             logr.Logger
 }
 
 // WorkloadPolicyStatusSyncConfig holds the configuration for the WorkloadPolicyStatusSync.
 type WorkloadPolicyStatusSyncConfig struct {
 	AgentPoolConf  grpcexporter.AgentClientPoolConfig
 	UpdateInterval time.Duration
+	// ViolationSummaryInterval is the length of the window summarized in the status of each policy.
+	// 0 disables the summary.
+	ViolationSummaryInterval time.Duration
+	// UnobservedExecutablesPeriod is how long an allowed executable must not be run on any node
+	// before it is reported in the status of its policy. 0 disables the report.
+	UnobservedExecutablesPeriod time.Duration
 }
 
 func NewWorkloadPolicyStatusSync(
@@ -52,16 +62,34 @@ func NewWorkloadPolicyStatusSync(
 	if config.UpdateInterval <= 0 {
 		return nil, fmt.Errorf("invalid update interval: %v", config.UpdateInterval)
 	}
+	if config.ViolationSummaryInterval < 0 {
+		return nil, fmt.Errorf("invalid violation summary interval: %v", config.ViolationSummaryInterval)
+	}
+	if config.UnobservedExecutablesPeriod < 0 {
+		return nil, fmt.Errorf("invalid unobserved executables period: %v", config.UnobservedExecutablesPeriod)
+	}
 
 	agentClientPool, err := grpcexporter.NewAgentClientPool(config.AgentPoolConf)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create agent client pool: %w", err)
 	}
 
+	var aggregator *violationAggregator
+	if config.ViolationSummaryInterval > 0 {
+		aggregator = newViolationAggregator(config.ViolationSummaryInterval)
+	}
+
+	var observer *executableObserver
+	if config.UnobservedExecutablesPeriod > 0 {
+		observer = newExecutableObserver(config.UnobservedExecutablesPeriod)
+	}
+
 	return &WorkloadPolicyStatusSync{
-		Client:          c,
-		agentClientPool: agentClientPool,
-		updateInterval:  config.UpdateInterval,
+		Client:              c,
+		agentClientPool:     agentClientPool,
+		updateInterval:      config.UpdateInterval,
+		violationAggregator: aggregator,
+		executableObserver:  observer,
 	}, nil
 }
 
@@ -148,8 +176,18 @@ func (r *WorkloadPolicyStatusSync) sync(
 	violationsByPolicy := r.getViolationsByPolicy(ctx, clients)
 
 	// Now we iterate over all WSPs and update their status based on the collected policies status from the agents
+	now := time.Now()
 	for _, wp := range wpList.Items {
-		if err = r.processWorkloadPolicy(ctx, &wp, nodesInfo, violationsByPolicy[wp.NamespacedName()]); err != nil {
+		violations := violationsByPolicy[wp.NamespacedName()]
+		var summary *v1alpha1.ViolationSummary
+		if r.violationAggregator != nil {
+			summary = r.violationAggregator.collect(wp.NamespacedName(), violations, now)
+		}
+		var unobserved map[string][]string
+		if r.executableObserver != nil {
+			unobserved = r.executableObserver.collect(&wp, nodesInfo, now)
+		}
+		if err = r.processWorkloadPolicy(ctx, &wp, nodesInfo, violations, summary, unobserved); err != nil {
 			r.logger.Error(
 				err,
 				"failed to process workload policy",
@@ -158,6 +196,17 @@ func (r *WorkloadPolicyStatusSync) sync(
 		}
 	}
 
+	policies := make(map[string]struct{}, len(wpList.Items))
+	for _, wp := range wpList.Items {
+		policies[wp.NamespacedName()] = struct{}{}
+	}
+	if r.violationAggregator != nil {
+		r.violationAggregator.retain(policies)
+	}
+	if r.executableObserver != nil {
+		r.executableObserver.retain(policies)
+	}
+
 	return nil
 }
 
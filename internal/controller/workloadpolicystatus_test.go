@@ -17,6 +17,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -24,7 +25,10 @@ func createTestWPStatusSync(t *testing.T) *WorkloadPolicyStatusSync {
 	scheme := runtime.NewScheme()
 	corev1.AddToScheme(scheme)
 	v1alpha1.AddToScheme(scheme)
-	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects().Build()
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.WorkloadPolicy{}).
+		Build()
 	config := &WorkloadPolicyStatusSyncConfig{
 		AgentPoolConf: grpcexporter.AgentClientPoolConfig{
 			AgentFactoryConfig: grpcexporter.AgentFactoryConfig{
@@ -112,7 +116,12 @@ func TestComputeWpStatus(t *testing.T) {
 				FailedNodes:        1,
 				TransitioningNodes: 1,
 				NodesTransitioning: []string{node3},
-				Phase:              v1alpha1.Failed,
+				EnforcedBy: map[string]v1alpha1.NodeEnforcement{
+					node2: {EnforcerVersion: v1alpha1.UnknownEnforcerVersion},
+					node3: {EnforcerVersion: v1alpha1.UnknownEnforcerVersion},
+				},
+				EnforcerVersions: map[string]int{v1alpha1.UnknownEnforcerVersion: 2},
+				Phase:            v1alpha1.Failed,
 			},
 		},
 		{
@@ -125,8 +134,9 @@ func TestComputeWpStatus(t *testing.T) {
 					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
 					policies: map[string]*pb.PolicyStatus{
 						policyName: {
-							State: pb.PolicyState_POLICY_STATE_READY,
-							Mode:  expectedMode,
+							State:           pb.PolicyState_POLICY_STATE_READY,
+							Mode:            expectedMode,
+							EnforcerVersion: "v1.3.0",
 						},
 					},
 				},
@@ -134,8 +144,9 @@ func TestComputeWpStatus(t *testing.T) {
 					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
 					policies: map[string]*pb.PolicyStatus{
 						policyName: {
-							State: pb.PolicyState_POLICY_STATE_READY,
-							Mode:  wrongMode,
+							State:           pb.PolicyState_POLICY_STATE_READY,
+							Mode:            wrongMode,
+							EnforcerVersion: "v1.2.0",
 						},
 					},
 				},
@@ -143,8 +154,9 @@ func TestComputeWpStatus(t *testing.T) {
 					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
 					policies: map[string]*pb.PolicyStatus{
 						policyName: {
-							State: pb.PolicyState_POLICY_STATE_READY,
-							Mode:  wrongMode,
+							State:           pb.PolicyState_POLICY_STATE_READY,
+							Mode:            wrongMode,
+							EnforcerVersion: "v1.2.0",
 						},
 					},
 				},
@@ -156,7 +168,13 @@ func TestComputeWpStatus(t *testing.T) {
 				FailedNodes:        0,
 				TransitioningNodes: 2,
 				NodesTransitioning: []string{node2, node3},
-				Phase:              v1alpha1.Transitioning,
+				EnforcedBy: map[string]v1alpha1.NodeEnforcement{
+					node1: {EnforcerVersion: "v1.3.0"},
+					node2: {EnforcerVersion: "v1.2.0"},
+					node3: {EnforcerVersion: "v1.2.0"},
+				},
+				EnforcerVersions: map[string]int{"v1.2.0": 2, "v1.3.0": 1},
+				Phase:            v1alpha1.Transitioning,
 			},
 		},
 		{
@@ -169,8 +187,9 @@ func TestComputeWpStatus(t *testing.T) {
 					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
 					policies: map[string]*pb.PolicyStatus{
 						policyName: {
-							State: pb.PolicyState_POLICY_STATE_READY,
-							Mode:  expectedMode,
+							State:           pb.PolicyState_POLICY_STATE_READY,
+							Mode:            expectedMode,
+							EnforcerVersion: "v1.3.0",
 						},
 					},
 				},
@@ -178,8 +197,9 @@ func TestComputeWpStatus(t *testing.T) {
 					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
 					policies: map[string]*pb.PolicyStatus{
 						policyName: {
-							State: pb.PolicyState_POLICY_STATE_READY,
-							Mode:  expectedMode,
+							State:           pb.PolicyState_POLICY_STATE_READY,
+							Mode:            expectedMode,
+							EnforcerVersion: "v1.3.0",
 						},
 					},
 				},
@@ -187,8 +207,9 @@ func TestComputeWpStatus(t *testing.T) {
 					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
 					policies: map[string]*pb.PolicyStatus{
 						policyName: {
-							State: pb.PolicyState_POLICY_STATE_READY,
-							Mode:  expectedMode,
+							State:           pb.PolicyState_POLICY_STATE_READY,
+							Mode:            expectedMode,
+							EnforcerVersion: "v1.3.0",
 						},
 					},
 				},
@@ -200,7 +221,81 @@ func TestComputeWpStatus(t *testing.T) {
 				FailedNodes:        0,
 				TransitioningNodes: 0,
 				NodesTransitioning: nil,
-				Phase:              v1alpha1.Ready,
+				EnforcedBy: map[string]v1alpha1.NodeEnforcement{
+					node1: {EnforcerVersion: "v1.3.0"},
+					node2: {EnforcerVersion: "v1.3.0"},
+					node3: {EnforcerVersion: "v1.3.0"},
+				},
+				EnforcerVersions: map[string]int{"v1.3.0": 3},
+				Phase:            v1alpha1.Ready,
+			},
+		},
+		{
+			// - node1 and node2 report the executions the policy in dry run would have blocked.
+			name: "policy in dry run",
+			nodes: nodesInfoMap{
+				node1: nodeInfo{
+					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+					policies: map[string]*pb.PolicyStatus{
+						policyName: {
+							State: pb.PolicyState_POLICY_STATE_READY,
+							Mode:  expectedMode,
+							DryRunImpact: []*pb.DryRunImpact{
+								{ContainerName: "main", Count: 3, Executables: []string{"/bin/curl", "/bin/sh"}},
+							},
+						},
+					},
+				},
+				node2: nodeInfo{
+					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+					policies: map[string]*pb.PolicyStatus{
+						policyName: {
+							State: pb.PolicyState_POLICY_STATE_READY,
+							Mode:  expectedMode,
+							DryRunImpact: []*pb.DryRunImpact{
+								{ContainerName: "main", Count: 2, Executables: []string{"/bin/bash", "/bin/sh"}},
+								{ContainerName: "sidecar", Count: 1, Executables: []string{"/bin/wget"}},
+							},
+						},
+					},
+				},
+			},
+			expected: v1alpha1.WorkloadPolicyStatus{
+				TotalNodes:      2,
+				SuccessfulNodes: 2,
+				EnforcedBy: map[string]v1alpha1.NodeEnforcement{
+					node1: {EnforcerVersion: v1alpha1.UnknownEnforcerVersion},
+					node2: {EnforcerVersion: v1alpha1.UnknownEnforcerVersion},
+				},
+				EnforcerVersions: map[string]int{v1alpha1.UnknownEnforcerVersion: 2},
+				DryRunImpact: map[string]v1alpha1.DryRunImpact{
+					"main":    {Count: 5, Executables: []string{"/bin/bash", "/bin/curl", "/bin/sh"}},
+					"sidecar": {Count: 1, Executables: []string{"/bin/wget"}},
+				},
+				Phase: v1alpha1.Ready,
+			},
+		},
+		{
+			// - node1 forces the monitor mode, it won't enforce the declared mode.
+			name: "mode forced by the agent",
+			nodes: nodesInfoMap{
+				node1: nodeInfo{
+					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+					policies: map[string]*pb.PolicyStatus{
+						policyName: {
+							State:      pb.PolicyState_POLICY_STATE_READY,
+							Mode:       pb.PolicyMode_POLICY_MODE_MONITOR,
+							ModeForced: true,
+						},
+					},
+				},
+			},
+			expected: v1alpha1.WorkloadPolicyStatus{
+				TotalNodes:       1,
+				SuccessfulNodes:  1,
+				EnforcedBy:       map[string]v1alpha1.NodeEnforcement{node1: {EnforcerVersion: v1alpha1.UnknownEnforcerVersion}},
+				EnforcerVersions: map[string]int{v1alpha1.UnknownEnforcerVersion: 1},
+				Phase:            v1alpha1.Ready,
 			},
 		},
 	}
@@ -309,6 +404,50 @@ func TestWorkloadPolicyViolationCount(t *testing.T) {
 	require.Len(t, status.Violations, v1alpha1.MaxViolationRecords)
 }
 
+func TestWorkloadPolicyLastViolation(t *testing.T) {
+	ctx := context.Background()
+	r := createTestWPStatusSync(t)
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "policy",
+			Namespace: "ns",
+		},
+		Spec: v1alpha1.WorkloadPolicySpec{Mode: policymode.MonitorString},
+	}
+	require.NoError(t, r.Create(ctx, wp))
+
+	flush := func(scraped ...v1alpha1.ViolationRecord) *v1alpha1.WorkloadPolicy {
+		var current v1alpha1.WorkloadPolicy
+		require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(wp), &current))
+		require.NoError(t, r.processWorkloadPolicy(ctx, &current, nil, scraped, nil, nil))
+		var updated v1alpha1.WorkloadPolicy
+		require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(wp), &updated))
+		return &updated
+	}
+
+	// no violation yet.
+	updated := flush()
+	require.Nil(t, updated.Status.LastViolationTime)
+	require.Empty(t, updated.Status.LastViolationPath)
+
+	// the agents report their violations independently: the newest one wins.
+	newest := makeRecord(5)
+	newest.ExecutablePath = "/usr/bin/newest"
+	updated = flush(makeRecord(3), newest, makeRecord(4))
+	require.True(t, newest.Timestamp.Equal(updated.Status.LastViolationTime))
+	require.Equal(t, "/usr/bin/newest", updated.Status.LastViolationPath)
+
+	// a violation scraped late from a node does not move the last violation back.
+	updated = flush(makeRecord(1))
+	require.True(t, newest.Timestamp.Equal(updated.Status.LastViolationTime))
+	require.Equal(t, "/usr/bin/newest", updated.Status.LastViolationPath)
+
+	// without new violations the last one is kept.
+	updated = flush()
+	require.True(t, newest.Timestamp.Equal(updated.Status.LastViolationTime))
+	require.Equal(t, "/usr/bin/newest", updated.Status.LastViolationPath)
+}
+
 func TestGetViolationsByPolicy(t *testing.T) {
 	ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
 
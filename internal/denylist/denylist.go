@@ -0,0 +1,164 @@
+// Package denylist loads the global deny list: the executables denied in every policy, whatever its allow list,
+// e.g. the known crypto miners sourced from a threat intelligence feed.
+//
+// The file contains one entry per line, the blank lines and the lines starting with `#` are ignored.
+// An absolute path denies that executable, a name with no `/` denies the executables with that name in any directory:
+//
+//	# crypto miners
+//	xmrig
+//	/usr/bin/nc.openbsd
+//
+// The file is meant to be mounted from a ConfigMap, so a change of the ConfigMap is applied without restarting
+// the agent. A missing file is an empty deny list.
+package denylist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// UpdateFunc receives the entries of the deny list each time they change.
+type UpdateFunc func(entries []string) error
+
+// Parse parses the content of a deny list, see the package documentation for its syntax.
+// The entries are sorted and deduplicated.
+func Parse(r io.Reader) ([]string, error) {
+	var entries []string
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if err := validateEntry(entry); err != nil {
+			return nil, fmt.Errorf("line %d: invalid entry %q: %w", line, entry, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	slices.Sort(entries)
+	return slices.Compact(entries), nil
+}
+
+func validateEntry(entry string) error {
+	if len(entry) > bpf.MaxGlobalDenyLength {
+		return fmt.Errorf("longer than %d bytes", bpf.MaxGlobalDenyLength)
+	}
+	if !strings.Contains(entry, "/") {
+		if entry == "." || entry == ".." {
+			return errors.New("not an executable name")
+		}
+		return nil
+	}
+	if !strings.HasPrefix(entry, "/") {
+		return errors.New("expected an absolute path or a name with no '/'")
+	}
+	if path.Clean(entry) != entry {
+		return errors.New("the path is not clean")
+	}
+	return nil
+}
+
+// Load reads the deny list from a file, it returns no entries with no error when the file is missing.
+func Load(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the deny list %s: %w", file, err)
+	}
+	entries, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the deny list %s: %w", file, err)
+	}
+	return entries, nil
+}
+
+// Watcher applies the deny list of a file each time it changes.
+type Watcher struct {
+	logger *slog.Logger
+	file   string
+	update UpdateFunc
+	// applied is the deny list last applied, nil before the first one.
+	applied []string
+}
+
+// NewWatcher returns a watcher calling update with the entries of file.
+func NewWatcher(logger *slog.Logger, file string, update UpdateFunc) *Watcher {
+	return &Watcher{
+		logger: logger.With("component", "deny-list-watcher"),
+		file:   file,
+		update: update,
+	}
+}
+
+// Run applies the deny list, then applies it again each time it changes until ctx is done.
+// The whole directory of the file is watched, since a ConfigMap volume replaces its files by swapping
+// a symbolic link: the file itself receives no event.
+func (w *Watcher) Run(ctx context.Context) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create the file watcher: %w", err)
+	}
+	defer fw.Close()
+	if err = fw.Add(filepath.Dir(w.file)); err != nil {
+		return fmt.Errorf("failed to watch the directory of %s: %w", w.file, err)
+	}
+
+	w.apply(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			w.apply(ctx)
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			w.logger.WarnContext(ctx, "file watcher error", "error", err)
+		}
+	}
+}
+
+// apply applies the deny list when it differs from the one last applied.
+// An invalid deny list is not applied, the previous one is kept.
+func (w *Watcher) apply(ctx context.Context) {
+	entries, err := Load(w.file)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "invalid deny list, keeping the previous one", "error", err)
+		return
+	}
+	if entries == nil {
+		entries = []string{}
+	}
+	if w.applied != nil && slices.Equal(w.applied, entries) {
+		return
+	}
+	if err = w.update(entries); err != nil {
+		w.logger.ErrorContext(ctx, "failed to apply the deny list", "file", w.file, "error", err)
+		return
+	}
+	w.applied = entries
+	w.logger.InfoContext(ctx, "deny list applied", "file", w.file, "entries", len(entries))
+}
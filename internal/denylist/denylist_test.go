@@ -0,0 +1,90 @@
+package denylist_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/denylist"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	entries, err := denylist.Parse(strings.NewReader("# miners\nxmrig\n\n  /usr/bin/nc  \nxmrig\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"/usr/bin/nc", "xmrig"}, entries)
+
+	for _, content := range []string{
+		"bin/nc",
+		"/usr/bin/../bin/nc",
+		"/usr/bin/",
+		"..",
+		"/" + strings.Repeat("a", 251),
+	} {
+		_, err = denylist.Parse(strings.NewReader(content))
+		require.Error(t, err, content)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deny-list")
+	entries, err := denylist.Load(file)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	require.NoError(t, os.WriteFile(file, []byte("xmrig\n"), 0o600))
+	entries, err = denylist.Load(file)
+	require.NoError(t, err)
+	require.Equal(t, []string{"xmrig"}, entries)
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "deny-list")
+	require.NoError(t, os.WriteFile(file, []byte("xmrig\n"), 0o600))
+
+	updates := make(chan []string, 16)
+	w := denylist.NewWatcher(slog.Default(), file, func(entries []string) error {
+		updates <- entries
+		return nil
+	})
+	done := make(chan error)
+	ctx, cancel := context.WithCancel(t.Context())
+	go func() { done <- w.Run(ctx) }()
+
+	waitFor := func(expected []string) {
+		t.Helper()
+		for {
+			select {
+			case entries := <-updates:
+				// the intermediate content of the file can be received before the expected one.
+				if slices.Equal(entries, expected) {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "no update received", "expected %v", expected)
+			}
+		}
+	}
+	// the deny list is applied at start.
+	waitFor([]string{"xmrig"})
+
+	require.NoError(t, os.WriteFile(file, []byte("xmrig\n/usr/bin/nc\n"), 0o600))
+	waitFor([]string{"/usr/bin/nc", "xmrig"})
+
+	// a ConfigMap volume swaps a symbolic link to a new directory, the file itself is never written.
+	dataDir := filepath.Join(dir, "..2026_10_16")
+	require.NoError(t, os.Mkdir(dataDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "deny-list"), []byte("minerd\n"), 0o600))
+	require.NoError(t, os.Remove(file))
+	require.NoError(t, os.Symlink(filepath.Join(dataDir, "deny-list"), file))
+	waitFor([]string{"minerd"})
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,188 @@
+// Package devpolicy backs the allowed executables of policies with local files, for a rapid iteration on the
+// allow lists during the development: a change to a file is applied right away, with no round-trip through the
+// API server. It must never be used in production.
+//
+// The mapping is a `,`-separated list of `namespace/policy/container=path` items, each binding a container of
+// a policy to a file:
+//
+//	default/my-policy/main=/tmp/main.allowed,default/my-policy/sidecar=/tmp/sidecar.allowed
+//
+// A file contains one executable per line, the blank lines and the lines starting with `#` are ignored.
+// A missing file restores the executables declared in the policy.
+package devpolicy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Binding binds a container of a policy to the file containing its allowed executables.
+type Binding struct {
+	// Policy is the `namespace/name` of the policy.
+	Policy    string
+	Container string
+	Path      string
+}
+
+// UpdateFunc receives the allowed executables read from the file of a binding, nil when the file is missing.
+type UpdateFunc func(policy, container string, allowed []string) error
+
+// Parse parses a mapping, see the package documentation for its syntax.
+func Parse(spec string) ([]Binding, error) {
+	var bindings []Binding
+	seen := make(map[string]struct{})
+	for item := range strings.SplitSeq(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		b, err := parseBinding(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy file %q: %w", item, err)
+		}
+		key := b.Policy + "/" + b.Container
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("container %s of policy %s is bound to more than one file", b.Container, b.Policy)
+		}
+		seen[key] = struct{}{}
+		bindings = append(bindings, b)
+	}
+	return bindings, nil
+}
+
+func parseBinding(item string) (Binding, error) {
+	target, path, found := strings.Cut(item, "=")
+	if !found {
+		return Binding{}, errors.New("expected namespace/policy/container=path")
+	}
+	parts := strings.Split(target, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return Binding{}, fmt.Errorf("invalid target %q, expected namespace/policy/container", target)
+	}
+	if path == "" {
+		return Binding{}, errors.New("missing file path")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Binding{}, fmt.Errorf("invalid file path %q: %w", path, err)
+	}
+	return Binding{Policy: parts[0] + "/" + parts[1], Container: parts[2], Path: abs}, nil
+}
+
+// ReadFile reads the allowed executables from a file, it returns nil with no error when the file is missing.
+func ReadFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	allowed := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowed = append(allowed, line)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return allowed, nil
+}
+
+// Watcher applies the content of the bound files when they change.
+type Watcher struct {
+	logger   *slog.Logger
+	bindings []Binding
+	update   UpdateFunc
+}
+
+// NewWatcher returns a watcher calling update with the content of the bound files.
+func NewWatcher(logger *slog.Logger, bindings []Binding, update UpdateFunc) *Watcher {
+	return &Watcher{
+		logger:   logger.With("component", "dev-policy-watcher"),
+		bindings: bindings,
+		update:   update,
+	}
+}
+
+// Run applies every file, then applies again the files changed until ctx is done.
+// The parent directories are watched rather than the files, since the editors often replace a file
+// by renaming a new one in its place.
+func (w *Watcher) Run(ctx context.Context) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create the file watcher: %w", err)
+	}
+	defer fw.Close()
+
+	byPath := make(map[string][]Binding, len(w.bindings))
+	for _, b := range w.bindings {
+		if _, ok := byPath[b.Path]; !ok {
+			if err = fw.Add(filepath.Dir(b.Path)); err != nil {
+				return fmt.Errorf("failed to watch the directory of %s: %w", b.Path, err)
+			}
+		}
+		byPath[b.Path] = append(byPath[b.Path], b)
+	}
+	for path := range byPath {
+		w.apply(path, byPath[path])
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			if bindings, found := byPath[filepath.Clean(event.Name)]; found &&
+				event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) {
+				w.apply(event.Name, bindings)
+			}
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			w.logger.WarnContext(ctx, "file watcher error", "error", err)
+		}
+	}
+}
+
+func (w *Watcher) apply(path string, bindings []Binding) {
+	allowed, err := ReadFile(path)
+	if err != nil {
+		w.logger.Error("failed to read the allowed executables", "path", path, "error", err)
+		return
+	}
+	for _, b := range bindings {
+		if err = w.update(b.Policy, b.Container, allowed); err != nil {
+			w.logger.Error("failed to apply the allowed executables",
+				"path", path,
+				"wp", b.Policy,
+				"container", b.Container,
+				"error", err)
+			continue
+		}
+		w.logger.Info("allowed executables applied",
+			"path", path,
+			"wp", b.Policy,
+			"container", b.Container,
+			"executables", len(allowed))
+	}
+}
@@ -0,0 +1,111 @@
+package devpolicy_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/devpolicy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	bindings, err := devpolicy.Parse(" ns/wp/main=/tmp/main.allowed, ns/wp/sidecar=/tmp/sidecar.allowed ,")
+	require.NoError(t, err)
+	require.Equal(t, []devpolicy.Binding{
+		{Policy: "ns/wp", Container: "main", Path: "/tmp/main.allowed"},
+		{Policy: "ns/wp", Container: "sidecar", Path: "/tmp/sidecar.allowed"},
+	}, bindings)
+
+	bindings, err = devpolicy.Parse("")
+	require.NoError(t, err)
+	require.Empty(t, bindings)
+
+	for _, spec := range []string{
+		"ns/wp/main",
+		"ns/wp=/tmp/main.allowed",
+		"ns//main=/tmp/main.allowed",
+		"ns/wp/main/extra=/tmp/main.allowed",
+		"ns/wp/main=",
+		"ns/wp/main=/tmp/a,ns/wp/main=/tmp/b",
+	} {
+		_, err = devpolicy.Parse(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allowed")
+	allowed, err := devpolicy.ReadFile(path)
+	require.NoError(t, err)
+	require.Nil(t, allowed)
+
+	require.NoError(t, os.WriteFile(path, []byte("# comment\n/bin/sh\n\n  /bin/ls  \n"), 0o600))
+	allowed, err = devpolicy.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/bin/sh", "/bin/ls"}, allowed)
+
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	allowed, err = devpolicy.ReadFile(path)
+	require.NoError(t, err)
+	require.NotNil(t, allowed)
+	require.Empty(t, allowed)
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.allowed")
+	require.NoError(t, os.WriteFile(path, []byte("/bin/sh\n"), 0o600))
+
+	updates := make(chan []string, 16)
+	w := devpolicy.NewWatcher(slog.Default(), []devpolicy.Binding{{Policy: "ns/wp", Container: "main", Path: path}},
+		func(policy, container string, allowed []string) error {
+			if policy != "ns/wp" || container != "main" {
+				return fmt.Errorf("unexpected container %s of policy %s", container, policy)
+			}
+			updates <- allowed
+			return nil
+		})
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	waitFor := func(expected []string) {
+		t.Helper()
+		for {
+			select {
+			case allowed := <-updates:
+				// the intermediate content of the file can be received before the expected one.
+				if slices.Equal(allowed, expected) && (allowed == nil) == (expected == nil) {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "no update received", "expected %v", expected)
+			}
+		}
+	}
+	// the file is applied at start.
+	waitFor([]string{"/bin/sh"})
+
+	// a change of the file is applied.
+	require.NoError(t, os.WriteFile(path, []byte("/bin/sh\n/bin/ls\n"), 0o600))
+	waitFor([]string{"/bin/sh", "/bin/ls"})
+
+	// a file renamed in place, like the editors do, is applied.
+	tmp := filepath.Join(dir, ".main.allowed.swp")
+	require.NoError(t, os.WriteFile(tmp, []byte("/bin/cat\n"), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+	waitFor([]string{"/bin/cat"})
+
+	// a removed file restores the declared executables.
+	require.NoError(t, os.Remove(path))
+	waitFor(nil)
+
+	cancel()
+	require.NoError(t, <-done)
+}
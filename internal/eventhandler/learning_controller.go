@@ -279,6 +279,15 @@ func (r *LearningReconciler) reconcile(
 		return ctrl.Result{}, err
 	}
 
+	// The replicas of a workload mostly run the same executables: most events are already in the proposal
+	// and don't need another round trip to the API server.
+	if policyProposal.HasProcess(req.ContainerName, req.ExecutablePath) {
+		return ctrl.Result{}, nil
+	}
+
+	// CreateOrUpdate gets the proposal again and merges the executable into the ones learned by the other
+	// replicas, it only updates the proposal when the executable is new. When another agent updated the
+	// proposal meanwhile the update fails with a conflict and is retried by Reconcile.
 	if _, err = controllerutil.CreateOrUpdate(ctx, r.Client, policyProposal, func() error {
 		// We don't learn any new process if the policy proposal was promoted
 		// to an actual policy
@@ -287,6 +296,10 @@ func (r *LearningReconciler) reconcile(
 			return nil
 		}
 
+		if policyProposal.HasProcess(req.ContainerName, req.ExecutablePath) {
+			return nil
+		}
+
 		if policyProposal.IsFull() {
 			logger.Info("proposal is full, cannot add new executables",
 				"proposal", policyProposal.NamespacedName(),
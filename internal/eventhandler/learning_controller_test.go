@@ -3,6 +3,7 @@ package eventhandler_test
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/eventhandler"
@@ -189,6 +190,19 @@ var _ = Describe("Learning", func() {
 
 			Expect(rules.Executables.Allowed).To(HaveLen(eventsToProcessNum))
 			Expect(rules.Executables.Allowed).To(ContainElements(expectedAllowList))
+			Expect(slices.IsSorted(rules.Executables.Allowed)).To(BeTrue())
+
+			// the executables learned by another replica don't update the proposal again.
+			reconciler := newTestLearningReconciler(k8sClient, defaultNamespaceSelector)
+			ret, err := reconciler.Reconcile(ctx, eventsToProcess[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ret).To(Equal(ctrl.Result{}))
+			unchanged := securityv1alpha1.WorkloadPolicyProposal{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: proposalResult.Namespace,
+				Name:      proposalResult.Name,
+			}, &unchanged)).To(Succeed())
+			Expect(unchanged.ResourceVersion).To(Equal(proposalResult.ResourceVersion))
 		})
 
 		It("should correctly learn process behavior", func() {
@@ -8,10 +8,12 @@ import (
 	"strings"
 
 	"github.com/rancher-sandbox/runtime-enforcer/internal/tlsutil"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	otellog "go.opentelemetry.io/otel/log"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
 	"google.golang.org/grpc/credentials"
 )
 
@@ -22,6 +24,29 @@ const (
 	protocolHTTPProtobuf protocol = "http/protobuf"
 )
 
+// ClusterInfo identifies the cluster the events come from, when they are aggregated from several clusters.
+type ClusterInfo struct {
+	Name   string
+	Region string
+}
+
+// resource returns the static resource attached to every exported event: the default resource
+// of the SDK with the cluster attributes, the empty fields are omitted.
+func (c ClusterInfo) resource() (*resource.Resource, error) {
+	var attrs []attribute.KeyValue
+	if c.Name != "" {
+		attrs = append(attrs, attribute.String("k8s.cluster.name", c.Name))
+	}
+	if c.Region != "" {
+		attrs = append(attrs, attribute.String("cloud.region", c.Region))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the OTEL resource: %w", err)
+	}
+	return res, nil
+}
+
 func stringToProtocol(s string) (protocol, error) {
 	switch s {
 	case "grpc":
@@ -121,9 +146,11 @@ func createHTTPExporter(ctx context.Context,
 // certificate against the provided CA; otherwise insecure mode is used.
 // When clientCertPath and clientKeyPath are both non-empty, the client
 // presents a TLS certificate for mTLS authentication.
+// The cluster info is attached to every event as resource attributes.
 func Init(
 	ctx context.Context,
 	endpoint, caCertPath, clientCertPath, clientKeyPath, protocol string,
+	cluster ClusterInfo,
 ) (otellog.Logger, func(context.Context) error, error) {
 	var exporter sdklog.Exporter
 	proto, err := stringToProtocol(protocol)
@@ -140,10 +167,21 @@ func Init(
 		return nil, nil, fmt.Errorf("failed to create OTLP log exporter: %w", err)
 	}
 
-	provider := sdklog.NewLoggerProvider(
-		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
-	)
-
+	provider, err := newLoggerProvider(sdklog.NewBatchProcessor(exporter), cluster)
+	if err != nil {
+		return nil, nil, err
+	}
 	logger := provider.Logger("violation-reporter")
 	return logger, provider.Shutdown, nil
 }
+
+func newLoggerProvider(processor sdklog.Processor, cluster ClusterInfo) (*sdklog.LoggerProvider, error) {
+	res, err := cluster.resource()
+	if err != nil {
+		return nil, err
+	}
+	return sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(processor),
+	), nil
+}
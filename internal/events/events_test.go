@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+type recordingExporter struct {
+	records []sdklog.Record
+}
+
+func (e *recordingExporter) Export(_ context.Context, records []sdklog.Record) error {
+	for _, rec := range records {
+		e.records = append(e.records, rec.Clone())
+	}
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error { return nil }
+
+func (e *recordingExporter) ForceFlush(context.Context) error { return nil }
+
+func exportViolation(t *testing.T, cluster ClusterInfo) *attribute.Set {
+	t.Helper()
+	exporter := &recordingExporter{}
+	provider, err := newLoggerProvider(sdklog.NewSimpleProcessor(exporter), cluster)
+	require.NoError(t, err)
+
+	var rec otellog.Record
+	rec.SetEventName("policy_violation")
+	provider.Logger("violation-reporter").Emit(t.Context(), rec)
+	require.NoError(t, provider.Shutdown(t.Context()))
+
+	require.Len(t, exporter.records, 1)
+	return exporter.records[0].Resource().Set()
+}
+
+func TestClusterInfoInExportedEvents(t *testing.T) {
+	attrs := exportViolation(t, ClusterInfo{Name: "prod-eu-1", Region: "eu-west-1"})
+	name, ok := attrs.Value("k8s.cluster.name")
+	require.True(t, ok)
+	require.Equal(t, "prod-eu-1", name.AsString())
+	region, ok := attrs.Value("cloud.region")
+	require.True(t, ok)
+	require.Equal(t, "eu-west-1", region.AsString())
+	_, ok = attrs.Value("service.name")
+	require.True(t, ok, "the default resource attributes are preserved")
+
+	attrs = exportViolation(t, ClusterInfo{})
+	_, ok = attrs.Value("k8s.cluster.name")
+	require.False(t, ok)
+	_, ok = attrs.Value("cloud.region")
+	require.False(t, ok)
+}
@@ -0,0 +1,30 @@
+package eventscraper
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// newEventID returns the ID identifying a violation across the OTLP event, the violation buffer
+// and the exemplar of the violation counter.
+// The ID is derived from the content of the event: the cgroup, the executable, the process, the start time of
+// the container and the sequence number of the event. An event retried, e.g. while its cgroup is unresolved,
+// keeps the same ID, so that the downstream consumers can deduplicate the events they receive twice.
+func newEventID(info *KubeProcessInfo, event *bpf.ProcessEvent) string {
+	hash := sha256.New()
+	var buf [8]byte
+	writeUint64 := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf[:], v)
+		hash.Write(buf[:])
+	}
+	writeUint64(event.CgTrackerID)
+	writeUint64(uint64(len(event.ExePath)))
+	hash.Write([]byte(event.ExePath))
+	writeUint64(uint64(event.Tgid))
+	writeUint64(uint64(info.ContainerStartTime.UnixNano())) //nolint:gosec // only the bits are hashed
+	writeUint64(event.Sequence)
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
@@ -2,15 +2,23 @@ package eventscraper
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/portscope"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/violationbuf"
 	otellog "go.opentelemetry.io/otel/log"
 	"golang.org/x/time/rate"
+	"k8s.io/client-go/tools/events"
 )
 
 const (
@@ -20,6 +28,19 @@ const (
 	bufferFullMsg         = "violation buffer full, oldest entry dropped"
 )
 
+// Decisions reported for the execs of traced containers.
+const (
+	execDecisionAllowed   = "allowed"
+	execDecisionMonitored = "monitored"
+	execDecisionBlocked   = "blocked"
+)
+
+// Decisions reported for the execs of the containers whose policy is in audit mode.
+const (
+	auditDecisionAllowed       = "allowed"
+	auditDecisionWouldBeDenied = "would-be-denied"
+)
+
 type logRateLimiter struct {
 	limiter    *rate.Limiter
 	suppressed int64
@@ -51,8 +72,19 @@ type EventScraper struct {
 	learningEnqueueFunc func(evt KubeProcessInfo)
 	violationLogger     otellog.Logger
 	violationBuffer     *violationbuf.Buffer
+	violationHistory    *violationbuf.History
 	nodeName            string
 	bufferFullLimiter   *logRateLimiter
+	portClassifier      *portscope.Classifier
+	cgroupVersion       string
+	cgroupDriverFunc    func() string
+	unresolvedCgroup    UnresolvedCgroupConfig
+	podLabelKeys        []string
+	violationExemplars  bool
+	severityMapping     SeverityMapping
+	pendingEvents       []pendingEvent
+	eventRecorder       events.EventRecorder
+	podEventLimiter     *podEventLimiter
 }
 
 type KubeProcessInfo struct {
@@ -64,6 +96,139 @@ type KubeProcessInfo struct {
 	PodName        string `json:"podName"`
 	ContainerID    string `json:"containerID"`
 	PolicyName     string `json:"policyName,omitempty"`
+	Image          string `json:"image,omitempty"`
+	ImageDigest    string `json:"imageDigest,omitempty"`
+	// ContainerStartTime tells apart the instances of a container restarted with the same name,
+	// it is zero when the runtime doesn't report it.
+	ContainerStartTime time.Time `json:"containerStartTime,omitzero"`
+	// PodUID is required by `kubectl describe pod` to list the events recorded on the pod.
+	PodUID string `json:"podUID,omitempty"`
+}
+
+// ViolationReason is a machine-readable code describing why an exec, or a file open, was reported as a violation.
+// The blocked process only receives EPERM, this code is meant for the tooling consuming violation events.
+type ViolationReason string
+
+const (
+	// ViolationReasonExecNotAllowed is reported when the executable is not in the allowlist of the container.
+	ViolationReasonExecNotAllowed ViolationReason = "EXEC_NOT_ALLOWED"
+	// ViolationReasonSuidExec is reported when a setuid/setgid executable is run under a policy blocking them.
+	ViolationReasonSuidExec ViolationReason = "SUID_EXEC"
+	// ViolationReasonScriptExec is reported when a script is run under a policy blocking them.
+	ViolationReasonScriptExec ViolationReason = "SCRIPT_EXEC"
+	// ViolationReasonExecLimit is reported when a container runs more distinct executables than allowed by its policy.
+	ViolationReasonExecLimit ViolationReason = "EXEC_LIMIT_EXCEEDED"
+	// ViolationReasonUnlinkedExec is reported when a deleted, memfd or unresolvable file is run under a policy
+	// blocking them.
+	ViolationReasonUnlinkedExec ViolationReason = "UNLINKED_EXEC"
+	// ViolationReasonCommandNotApproved is reported when an executable restricted to its approved commands
+	// is run with other arguments.
+	ViolationReasonCommandNotApproved ViolationReason = "COMMAND_NOT_APPROVED"
+	// ViolationReasonFsNotAllowed is reported when the executable is stored on a filesystem type not allowed
+	// by the policy.
+	ViolationReasonFsNotAllowed ViolationReason = "FS_NOT_ALLOWED"
+	// ViolationReasonFileReadNotAllowed is reported when a file not allowed for reading is opened for reading.
+	ViolationReasonFileReadNotAllowed ViolationReason = "FILE_READ_NOT_ALLOWED"
+	// ViolationReasonFileWriteNotAllowed is reported when a file not allowed for writing is opened for writing.
+	ViolationReasonFileWriteNotAllowed ViolationReason = "FILE_WRITE_NOT_ALLOWED"
+	// ViolationReasonFileWriteDenied is reported when a file denied for writing is opened for writing.
+	ViolationReasonFileWriteDenied ViolationReason = "FILE_WRITE_DENIED"
+	// ViolationReasonEgressNotAllowed is reported when a process connects to a destination not allowed
+	// by the policy.
+	ViolationReasonEgressNotAllowed ViolationReason = "EGRESS_NOT_ALLOWED"
+)
+
+const (
+	// ruleTypeExecutablesAllowed identifies the `executables.allowed` rule of a policy.
+	ruleTypeExecutablesAllowed = "executables.allowed"
+	// ruleTypeBlockSuidExec identifies the `blockSuidExec` rule of a policy.
+	ruleTypeBlockSuidExec = "blockSuidExec"
+	// ruleTypeBlockScripts identifies the `blockScripts` rule of a policy.
+	ruleTypeBlockScripts = "blockScripts"
+	// ruleTypeMaxDistinctExecutables identifies the `maxDistinctExecutables` rule of a policy.
+	ruleTypeMaxDistinctExecutables = "maxDistinctExecutables"
+	// ruleTypeBlockUnlinkedExec identifies the `blockUnlinkedExec` rule of a policy.
+	ruleTypeBlockUnlinkedExec = "blockUnlinkedExec"
+	// ruleTypeApprovedCommands identifies the `executables.approvedCommands` rule of a policy.
+	ruleTypeApprovedCommands = "executables.approvedCommands"
+	// ruleTypeAllowedFilesystems identifies the `allowedFilesystems` rule of a policy.
+	ruleTypeAllowedFilesystems = "allowedFilesystems"
+	// ruleTypeFilesAllowedRead identifies the `files.allowedRead` rule of a policy.
+	ruleTypeFilesAllowedRead = "files.allowedRead"
+	// ruleTypeFilesAllowedWrite identifies the `files.allowedWrite` rule of a policy.
+	ruleTypeFilesAllowedWrite = "files.allowedWrite"
+	// ruleTypeFilesDeniedWrite identifies the `files.deniedWrite` rule of a policy.
+	ruleTypeFilesDeniedWrite = "files.deniedWrite"
+	// ruleTypeNetworkAllowedEgress identifies the `network.allowedEgress` rule of a policy.
+	ruleTypeNetworkAllowedEgress = "network.allowedEgress"
+)
+
+// violationReasonAndRule converts the reason reported by BPF into the reason code and the rule type of the event.
+func violationReasonAndRule(reason bpf.ViolationReason) (ViolationReason, string) {
+	switch reason {
+	case bpf.ViolationReasonSuidExec:
+		return ViolationReasonSuidExec, ruleTypeBlockSuidExec
+	case bpf.ViolationReasonScriptExec:
+		return ViolationReasonScriptExec, ruleTypeBlockScripts
+	case bpf.ViolationReasonExecLimit:
+		return ViolationReasonExecLimit, ruleTypeMaxDistinctExecutables
+	case bpf.ViolationReasonUnlinkedExec:
+		return ViolationReasonUnlinkedExec, ruleTypeBlockUnlinkedExec
+	case bpf.ViolationReasonCommandNotApproved:
+		return ViolationReasonCommandNotApproved, ruleTypeApprovedCommands
+	case bpf.ViolationReasonFsNotAllowed:
+		return ViolationReasonFsNotAllowed, ruleTypeAllowedFilesystems
+	case bpf.ViolationReasonFileReadNotAllowed:
+		return ViolationReasonFileReadNotAllowed, ruleTypeFilesAllowedRead
+	case bpf.ViolationReasonFileWriteNotAllowed:
+		return ViolationReasonFileWriteNotAllowed, ruleTypeFilesAllowedWrite
+	case bpf.ViolationReasonFileWriteDenied:
+		return ViolationReasonFileWriteDenied, ruleTypeFilesDeniedWrite
+	case bpf.ViolationReasonEgressNotAllowed:
+		return ViolationReasonEgressNotAllowed, ruleTypeNetworkAllowedEgress
+	default:
+		return ViolationReasonExecNotAllowed, ruleTypeExecutablesAllowed
+	}
+}
+
+// isFileViolation returns true for the violations of the file rules, whose path is the one of the opened file
+// instead of the one of the executable.
+func isFileViolation(reason bpf.ViolationReason) bool {
+	switch reason {
+	case bpf.ViolationReasonFileReadNotAllowed, bpf.ViolationReasonFileWriteNotAllowed,
+		bpf.ViolationReasonFileWriteDenied:
+		return true
+	default:
+		return false
+	}
+}
+
+// isExecEvent returns true for the events reported on an exec, as opposed to the violations of the file rules
+// and of the egress rules, reported on a file open and on a connection.
+func isExecEvent(reason bpf.ViolationReason) bool {
+	return !isFileViolation(reason) && reason != bpf.ViolationReasonEgressNotAllowed
+}
+
+// hostRootfsPrefixes match the host path of a container rootfs.
+// The kernel resolves the executable path against the root of the process, so we expect container-absolute paths,
+// but on some runtimes/setups the path can be reported with the host rootfs prefix.
+var hostRootfsPrefixes = []*regexp.Regexp{
+	// containerd (and k3s/rke2 embedded containerd): /run/containerd/io.containerd.runtime.v2.task/<namespace>/<container-id>/rootfs
+	regexp.MustCompile(`^/run/(?:k3s/)?containerd/io\.containerd\.runtime\.v2\.task/[^/]+/[^/]+/rootfs/`),
+	// CRI-O and other containers/storage based runtimes: /var/lib/containers/storage/overlay/<layer-id>/merged
+	regexp.MustCompile(`^/var/lib/containers/storage/overlay/[^/]+/merged/`),
+}
+
+// normalizeExecPath converts a host-prefixed executable path to the container-absolute one,
+// so that it is consistent with the paths used in policies.
+func normalizeExecPath(path string) string {
+	for _, re := range hostRootfsPrefixes {
+		if loc := re.FindStringIndex(path); loc != nil {
+			// keep the trailing `/` of the prefix as the root of the container.
+			return path[loc[1]-1:]
+		}
+	}
+	return path
 }
 
 type Option func(*EventScraper)
@@ -85,6 +250,48 @@ func WithViolationBuffer(buf *violationbuf.Buffer, nodeName string) Option {
 	}
 }
 
+// WithViolationHistory sets the History retaining the recent violations for debugging purposes.
+func WithViolationHistory(history *violationbuf.History) Option {
+	return func(es *EventScraper) {
+		es.violationHistory = history
+	}
+}
+
+// WithPortClassifier enables the classification of the violations against the listening ports
+// declared by the policies.
+func WithPortClassifier(classifier *portscope.Classifier) Option {
+	return func(es *EventScraper) {
+		es.portClassifier = classifier
+	}
+}
+
+// WithCgroupInfo adds the cgroup version and driver of the node to the violation events,
+// so that issues can be correlated across nodes with different setups.
+// The driver is only known once the runtime reported a container, so it is retrieved at each event.
+func WithCgroupInfo(version string, driverFunc func() string) Option {
+	return func(es *EventScraper) {
+		es.cgroupVersion = version
+		es.cgroupDriverFunc = driverFunc
+	}
+}
+
+// WithPodLabelAttributes adds the value of the given pod labels to the violation events, as
+// `k8s.pod.label.<key>` attributes, so that the consumers can attribute them, e.g. to a team or a cost center,
+// without looking the pod up. The labels missing from the pod are omitted.
+func WithPodLabelAttributes(keys []string) Option {
+	return func(es *EventScraper) {
+		es.podLabelKeys = keys
+	}
+}
+
+// WithViolationExemplars attaches the ID of the violation event as exemplar to the increments of the
+// violation counter, so that a metric spike can be correlated to the exported events and the buffered records.
+func WithViolationExemplars() Option {
+	return func(es *EventScraper) {
+		es.violationExemplars = true
+	}
+}
+
 func NewEventScraper(
 	learningChannel <-chan bpf.ProcessEvent,
 	monitoringChannel <-chan bpf.ProcessEvent,
@@ -102,6 +309,11 @@ func NewEventScraper(
 		bufferFullLimiter: &logRateLimiter{
 			limiter: rate.NewLimiter(rate.Every(1*time.Second), 1),
 		},
+		unresolvedCgroup: UnresolvedCgroupConfig{
+			Strategy:     UnresolvedCgroupLog,
+			LogLevel:     slog.LevelError,
+			RetryTimeout: DefaultUnresolvedCgroupRetryTimeout,
+		},
 	}
 	for _, option := range opts {
 		option(es)
@@ -109,18 +321,24 @@ func NewEventScraper(
 	return es
 }
 
-func (es *EventScraper) getKubeProcessInfo(event *bpf.ProcessEvent) *KubeProcessInfo {
+// getKubeProcessInfo enriches the event with its pod info. It also returns the pod labels configured with
+// WithPodLabelAttributes: they are not part of KubeProcessInfo, which must stay comparable to be queued for learning.
+func (es *EventScraper) getKubeProcessInfo(event *bpf.ProcessEvent) (*KubeProcessInfo, map[string]string, error) {
 	// trackerID is the ID of the container cgroup where the process is running.
 	// NRI will populate cgroup tracker map before we will start to generate learning/monitor events from ebpf.
 	containerView, err := es.resolver.GetContainerView(event.CgTrackerID)
 	if err != nil {
-		es.logger.Error("failed to get pod info",
-			"cgTrackerID", event.CgTrackerID,
-			"exe", event.ExePath,
-			"error", err)
-		return nil
+		return nil, nil, err
 	}
+	kubeInfo, podLabels := es.kubeProcessInfo(containerView, event)
+	return kubeInfo, podLabels, nil
+}
 
+// kubeProcessInfo enriches the event with the info of the container view resolved from its cgroup.
+func (es *EventScraper) kubeProcessInfo(
+	containerView *resolver.ContainerView,
+	event *bpf.ProcessEvent,
+) (*KubeProcessInfo, map[string]string) {
 	podMeta := containerView.PodMeta
 	containerMeta := containerView.Meta
 	policyName := ""
@@ -129,15 +347,19 @@ func (es *EventScraper) getKubeProcessInfo(event *bpf.ProcessEvent) *KubeProcess
 	}
 
 	return &KubeProcessInfo{
-		Namespace:      podMeta.Namespace,
-		Workload:       podMeta.WorkloadName,
-		WorkloadKind:   podMeta.WorkloadType,
-		ContainerName:  containerMeta.Name,
-		ExecutablePath: event.ExePath,
-		PodName:        podMeta.Name,
-		ContainerID:    containerMeta.ID,
-		PolicyName:     policyName,
-	}
+		Namespace:          podMeta.Namespace,
+		Workload:           podMeta.WorkloadName,
+		WorkloadKind:       podMeta.WorkloadType,
+		ContainerName:      containerMeta.Name,
+		ExecutablePath:     normalizeExecPath(event.ExePath),
+		PodName:            podMeta.Name,
+		ContainerID:        containerMeta.ID,
+		PolicyName:         policyName,
+		Image:              containerMeta.Image,
+		ImageDigest:        containerMeta.ImageDigest,
+		ContainerStartTime: containerMeta.StartTime,
+		PodUID:             podMeta.ID,
+	}, es.projectPodLabels(podMeta.Labels)
 }
 
 // Start begins the event scraping process.
@@ -146,46 +368,272 @@ func (es *EventScraper) Start(ctx context.Context) error {
 		es.logger.InfoContext(ctx, "event scraper has stopped")
 	}()
 
+	// the ticker is only needed to retry the unresolved events.
+	var retryTick <-chan time.Time
+	if es.unresolvedCgroup.Strategy == UnresolvedCgroupRetry {
+		ticker := time.NewTicker(unresolvedRetryInterval)
+		defer ticker.Stop()
+		retryTick = ticker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			// Handle context cancellation
 			return nil
 		case event := <-es.learningChannel:
-			kubeInfo := es.getKubeProcessInfo(&event)
-			if kubeInfo == nil {
-				continue
-			}
-			es.learningEnqueueFunc(*kubeInfo)
+			es.handleEvent(ctx, &event, true)
 		case event := <-es.monitoringChannel:
-			kubeInfo := es.getKubeProcessInfo(&event)
-			if kubeInfo == nil {
-				continue
-			}
-
-			action := event.Mode
-
-			policyName := kubeInfo.PolicyName
-			if policyName == "" {
-				es.logger.ErrorContext(ctx, "missing policy label for",
-					"pod", kubeInfo.PodName,
-					"namespace", kubeInfo.Namespace)
-			}
-
-			es.emitViolationEvent(ctx, kubeInfo, action)
-			es.reportViolation(kubeInfo, action)
+			es.handleEvent(ctx, &event, false)
+		case now := <-retryTick:
+			es.retryUnresolvedEvents(ctx, now)
 		}
 	}
 }
 
-func (es *EventScraper) emitViolationEvent(ctx context.Context, info *KubeProcessInfo, action string) {
+func (es *EventScraper) handleEvent(ctx context.Context, event *bpf.ProcessEvent, learning bool) {
+	kubeInfo, podLabels, err := es.getKubeProcessInfo(event)
+	if err != nil {
+		es.handleUnresolvedEvent(ctx, event, learning, err)
+		return
+	}
+	es.processEvent(ctx, kubeInfo, podLabels, event, learning)
+}
+
+// processEvent processes an event enriched with its pod info.
+func (es *EventScraper) processEvent(
+	ctx context.Context,
+	kubeInfo *KubeProcessInfo,
+	podLabels map[string]string,
+	event *bpf.ProcessEvent,
+	learning bool,
+) {
+	if learning {
+		es.learningEnqueueFunc(*kubeInfo)
+		return
+	}
+
+	if policyID, traced := es.resolver.GetTracedPolicyID(event.CgTrackerID); traced {
+		es.logExecDecision(ctx, kubeInfo, event, policyID)
+	}
+	if event.Mode == policymode.AuditString && isExecEvent(event.Reason) {
+		es.logAuditedExec(ctx, kubeInfo, event)
+	}
+	if event.Reason == bpf.ViolationReasonNone {
+		// allowed exec of a traced container or of a policy in audit mode, it is not a violation.
+		return
+	}
+
+	action := event.Mode
+
+	policyName := kubeInfo.PolicyName
+	if policyName == "" {
+		es.logger.ErrorContext(ctx, "missing policy label for",
+			"pod", kubeInfo.PodName,
+			"namespace", kubeInfo.Namespace)
+	}
+
+	if event.Reason == bpf.ViolationReasonSuidExec {
+		es.logger.InfoContext(ctx, "setuid/setgid executable reported",
+			"pod", kubeInfo.PodName,
+			"namespace", kubeInfo.Namespace,
+			"exe", kubeInfo.ExecutablePath,
+			"fileMode", fmt.Sprintf("%#o", event.FileMode),
+			"action", action)
+	}
+	if event.Reason == bpf.ViolationReasonCommandNotApproved {
+		es.logger.InfoContext(ctx, "command not approved",
+			"pod", kubeInfo.PodName,
+			"namespace", kubeInfo.Namespace,
+			"exe", kubeInfo.ExecutablePath,
+			"args", event.Args,
+			"action", action)
+	}
+	if event.Reason == bpf.ViolationReasonFsNotAllowed {
+		es.logger.InfoContext(ctx, "executable of a filesystem not allowed",
+			"pod", kubeInfo.PodName,
+			"namespace", kubeInfo.Namespace,
+			"exe", kubeInfo.ExecutablePath,
+			"fsType", bpf.FilesystemName(event.FsMagic),
+			"action", action)
+	}
+	if isFileViolation(event.Reason) {
+		es.logger.InfoContext(ctx, "file access not allowed",
+			"pod", kubeInfo.PodName,
+			"namespace", kubeInfo.Namespace,
+			"file", kubeInfo.ExecutablePath,
+			"fileMode", fmt.Sprintf("%#o", event.FileMode),
+			"action", action)
+	}
+	if event.Reason == bpf.ViolationReasonEgressNotAllowed {
+		es.logger.InfoContext(ctx, "connection not allowed",
+			"pod", kubeInfo.PodName,
+			"namespace", kubeInfo.Namespace,
+			"exe", kubeInfo.ExecutablePath,
+			"destination", event.Destination.String(),
+			"action", action)
+	}
+
+	portScope := es.classifyPortScope(ctx, kubeInfo, event)
+	eventID := newEventID(kubeInfo, event)
+	es.emitViolationEvent(ctx, kubeInfo, podLabels, event, portScope, eventID)
+	es.reportViolation(kubeInfo, action, eventID)
+	es.countViolation(kubeInfo, action, eventID)
+	es.recordViolationEvent(kubeInfo, event, time.Now())
+	// the dry run impact only counts the executions the declared mode would have blocked.
+	if action == policymode.MonitorString && isExecEvent(event.Reason) {
+		es.resolver.RecordDryRunViolation(
+			kubeInfo.Namespace+"/"+kubeInfo.PolicyName, kubeInfo.ContainerName, kubeInfo.ExecutablePath)
+	}
+}
+
+// execDecision returns how the exec reported by the event has been handled.
+func execDecision(event *bpf.ProcessEvent) string {
+	switch {
+	case event.Reason == bpf.ViolationReasonNone:
+		return execDecisionAllowed
+	case event.Mode == policymode.ProtectString:
+		return execDecisionBlocked
+	default:
+		return execDecisionMonitored
+	}
+}
+
+// logExecDecision logs the decision taken on an exec of a traced container.
+// Traces are bounded in time, so these logs are never rate limited.
+func (es *EventScraper) logExecDecision(
+	ctx context.Context,
+	info *KubeProcessInfo,
+	event *bpf.ProcessEvent,
+	policyID resolver.PolicyID,
+) {
+	decision := execDecision(event)
+	reason, rule := violationReasonAndRule(event.Reason)
+	if decision == execDecisionAllowed {
+		// the executable matched the allow list.
+		reason = ""
+	}
+	es.logger.DebugContext(ctx, "exec decision",
+		"pod", info.PodName,
+		"namespace", info.Namespace,
+		"container", info.ContainerName,
+		"exe", info.ExecutablePath,
+		"decision", decision,
+		"reason", string(reason),
+		"rule", rule,
+		"policy", info.PolicyName,
+		"policyID", policyID,
+		"mode", event.Mode)
+}
+
+// logAuditedExec logs an exec of a container whose policy is in audit mode, with whether the allow list
+// would have denied it, so that the observed behavior can be compared with the policy before protecting it.
+func (es *EventScraper) logAuditedExec(ctx context.Context, info *KubeProcessInfo, event *bpf.ProcessEvent) {
+	decision := auditDecisionWouldBeDenied
+	reason, rule := violationReasonAndRule(event.Reason)
+	if event.Reason == bpf.ViolationReasonNone {
+		decision = auditDecisionAllowed
+		reason = ""
+	}
+	es.logger.InfoContext(ctx, "exec audited",
+		"pod", info.PodName,
+		"namespace", info.Namespace,
+		"container", info.ContainerName,
+		"exe", info.ExecutablePath,
+		"decision", decision,
+		"reason", string(reason),
+		"rule", rule,
+		"policy", info.PolicyName)
+}
+
+// classifyPortScope returns whether the process was listening on one of the ports declared for its container,
+// it returns an empty scope when the policy declares no port or the classification is disabled.
+func (es *EventScraper) classifyPortScope(
+	ctx context.Context,
+	info *KubeProcessInfo,
+	event *bpf.ProcessEvent,
+) portscope.Scope {
+	if es.portClassifier == nil || info.PolicyName == "" {
+		return ""
+	}
+	ports := es.resolver.GetListeningPorts(info.Namespace+"/"+info.PolicyName, info.ContainerName)
+	if len(ports) == 0 {
+		return ""
+	}
+	scope, err := es.portClassifier.Classify(event.Tgid, ports)
+	if err != nil {
+		es.logger.DebugContext(ctx, "failed to classify the listening ports of the process",
+			"pod", info.PodName,
+			"namespace", info.Namespace,
+			"pid", event.Tgid,
+			"error", err)
+	}
+	return scope
+}
+
+func (es *EventScraper) emitViolationEvent(
+	ctx context.Context,
+	info *KubeProcessInfo,
+	podLabels map[string]string,
+	event *bpf.ProcessEvent,
+	portScope portscope.Scope,
+	eventID string,
+) {
 	if es.violationLogger == nil {
 		return
 	}
+	rec := es.newViolationRecord(info, event, portScope)
+	rec.AddAttributes(otellog.String("event.id", eventID))
+	addPodLabelAttributes(&rec, podLabels)
+	es.violationLogger.Emit(ctx, rec)
+}
+
+// projectPodLabels returns the pod labels configured with WithPodLabelAttributes, nil when none is configured.
+// The labels are copied since the map of the pod is shared with the resolver cache.
+func (es *EventScraper) projectPodLabels(labels resolver.Labels) map[string]string {
+	if len(es.podLabelKeys) == 0 {
+		return nil
+	}
+	projected := make(map[string]string, len(es.podLabelKeys))
+	for _, key := range es.podLabelKeys {
+		if value, ok := labels[key]; ok {
+			projected[key] = value
+		}
+	}
+	return projected
+}
+
+// addPodLabelAttributes adds the projected pod labels to the violation record, sorted by key.
+func addPodLabelAttributes(rec *otellog.Record, podLabels map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(podLabels)) {
+		rec.AddAttributes(otellog.String("k8s.pod.label."+key, podLabels[key]))
+	}
+}
+
+// violationSeverity returns the severity of a violation: blocked execs are more severe than monitored ones.
+func violationSeverity(action string) otellog.Severity {
+	if action == policymode.ProtectString {
+		return otellog.SeverityError
+	}
+	return otellog.SeverityWarn
+}
+
+func (es *EventScraper) newViolationRecord(
+	info *KubeProcessInfo,
+	event *bpf.ProcessEvent,
+	portScope portscope.Scope,
+) otellog.Record {
+	action := event.Mode
+	reason, rule := violationReasonAndRule(event.Reason)
+	pathKey := "proc.exepath"
+	if isFileViolation(event.Reason) {
+		// the event carries the opened file, not the executable of the process opening it.
+		pathKey = "file.path"
+	}
 
 	var rec otellog.Record
 	rec.SetEventName("policy_violation")
-	rec.SetSeverity(otellog.SeverityWarn)
+	rec.SetSeverity(es.severityMapping.violationSeverity(rule, info.ExecutablePath, action))
 	rec.SetBody(otellog.StringValue("policy_violation"))
 	rec.SetTimestamp(time.Now())
 	rec.AddAttributes(
@@ -193,16 +641,47 @@ func (es *EventScraper) emitViolationEvent(ctx context.Context, info *KubeProces
 		otellog.String("k8s.namespace.name", info.Namespace),
 		otellog.String("k8s.pod.name", info.PodName),
 		otellog.String("container.name", info.ContainerName),
-		otellog.String("proc.exepath", info.ExecutablePath),
+		otellog.String("container.image.name", info.Image),
+		otellog.String("container.image.digest", info.ImageDigest),
+		otellog.String(pathKey, info.ExecutablePath),
 		otellog.String("node.name", es.nodeName),
 		otellog.String("action", action),
+		otellog.String("violation.reason", string(reason)),
+		otellog.String("violation.rule", rule),
+		otellog.String("proc.file_mode", fmt.Sprintf("%#o", event.FileMode)),
 	)
-
-	es.violationLogger.Emit(ctx, rec)
+	if es.cgroupVersion != "" {
+		rec.AddAttributes(otellog.String("node.cgroup.version", es.cgroupVersion))
+	}
+	if es.cgroupDriverFunc != nil {
+		if driver := es.cgroupDriverFunc(); driver != "" {
+			rec.AddAttributes(otellog.String("node.cgroup.driver", driver))
+		}
+	}
+	if portScope != "" {
+		rec.AddAttributes(otellog.String("proc.listening_port_scope", string(portScope)))
+	}
+	if event.Reason == bpf.ViolationReasonCommandNotApproved {
+		// the arguments are quoted, so that the ones containing spaces are told apart.
+		rec.AddAttributes(otellog.String("proc.args", fmt.Sprintf("%q", event.Args)))
+	}
+	if event.Reason == bpf.ViolationReasonFsNotAllowed {
+		rec.AddAttributes(otellog.String("proc.fs_type", bpf.FilesystemName(event.FsMagic)))
+	}
+	if event.Reason == bpf.ViolationReasonEgressNotAllowed && event.Destination.IsValid() {
+		rec.AddAttributes(
+			otellog.String("destination.address", event.Destination.Addr().String()),
+			otellog.String("destination.port", strconv.Itoa(int(event.Destination.Port()))),
+		)
+	}
+	if !info.ContainerStartTime.IsZero() {
+		rec.AddAttributes(otellog.String("container.start_time", info.ContainerStartTime.Format(time.RFC3339Nano)))
+	}
+	return rec
 }
 
-func (es *EventScraper) reportViolation(info *KubeProcessInfo, action string) {
-	dropped := es.violationBuffer.Record(violationbuf.ViolationRecord{
+func (es *EventScraper) reportViolation(info *KubeProcessInfo, action, eventID string) {
+	rec := violationbuf.ViolationRecord{
 		Timestamp:     time.Now(),
 		PolicyName:    info.PolicyName,
 		Namespace:     info.Namespace,
@@ -211,7 +690,12 @@ func (es *EventScraper) reportViolation(info *KubeProcessInfo, action string) {
 		ExePath:       info.ExecutablePath,
 		NodeName:      es.nodeName,
 		Action:        action,
-	})
+		EventID:       eventID,
+	}
+	if es.violationHistory != nil {
+		es.violationHistory.Add(rec)
+	}
+	dropped := es.violationBuffer.Record(rec)
 	if dropped {
 		if es.bufferFullLimiter.shouldLog() {
 			es.bufferFullLimiter.flushSuppressed(es.logger, bufferFullMsg)
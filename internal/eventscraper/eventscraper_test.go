@@ -0,0 +1,612 @@
+package eventscraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/portscope"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/violationbuf"
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func recordAttributes(rec otellog.Record) map[string]string {
+	attrs := make(map[string]string)
+	rec.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.AsString()
+		return true
+	})
+	return attrs
+}
+
+func TestNewViolationRecordReason(t *testing.T) {
+	es := &EventScraper{nodeName: "node-1"}
+	info := &KubeProcessInfo{
+		Namespace:      "test-ns",
+		ContainerName:  "app",
+		ExecutablePath: "/usr/bin/curl",
+		PodName:        "test-pod",
+		PolicyName:     "example",
+	}
+
+	rec := es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:   policymode.ProtectString,
+		Reason: bpf.ViolationReasonExecNotAllowed,
+	}, "")
+	require.Equal(t, otellog.SeverityError, rec.Severity())
+	attrs := recordAttributes(rec)
+	require.Equal(t, string(ViolationReasonExecNotAllowed), attrs["violation.reason"])
+	require.Equal(t, ruleTypeExecutablesAllowed, attrs["violation.rule"])
+	require.Equal(t, "example", attrs["policy.name"])
+	require.Equal(t, policymode.ProtectString, attrs["action"])
+
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:   policymode.MonitorString,
+		Reason: bpf.ViolationReasonExecNotAllowed,
+	}, "")
+	require.Equal(t, otellog.SeverityWarn, rec.Severity())
+	require.Equal(t, string(ViolationReasonExecNotAllowed), recordAttributes(rec)["violation.reason"])
+
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:     policymode.ProtectString,
+		Reason:   bpf.ViolationReasonSuidExec,
+		FileMode: 0o104755,
+	}, "")
+	attrs = recordAttributes(rec)
+	require.Equal(t, string(ViolationReasonSuidExec), attrs["violation.reason"])
+	require.Equal(t, ruleTypeBlockSuidExec, attrs["violation.rule"])
+	require.Equal(t, "0104755", attrs["proc.file_mode"])
+	require.NotContains(t, attrs, "proc.listening_port_scope")
+
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:   policymode.ProtectString,
+		Reason: bpf.ViolationReasonScriptExec,
+	}, "")
+	attrs = recordAttributes(rec)
+	require.Equal(t, string(ViolationReasonScriptExec), attrs["violation.reason"])
+	require.Equal(t, ruleTypeBlockScripts, attrs["violation.rule"])
+
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:   policymode.ProtectString,
+		Reason: bpf.ViolationReasonExecLimit,
+	}, "")
+	attrs = recordAttributes(rec)
+	require.Equal(t, string(ViolationReasonExecLimit), attrs["violation.reason"])
+	require.Equal(t, ruleTypeMaxDistinctExecutables, attrs["violation.rule"])
+
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:   policymode.ProtectString,
+		Reason: bpf.ViolationReasonUnlinkedExec,
+	}, "")
+	attrs = recordAttributes(rec)
+	require.Equal(t, string(ViolationReasonUnlinkedExec), attrs["violation.reason"])
+	require.Equal(t, ruleTypeBlockUnlinkedExec, attrs["violation.rule"])
+	require.NotContains(t, attrs, "proc.args")
+
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:   policymode.ProtectString,
+		Reason: bpf.ViolationReasonCommandNotApproved,
+		Args:   []string{"-c", "curl example.com"},
+	}, "")
+	attrs = recordAttributes(rec)
+	require.Equal(t, string(ViolationReasonCommandNotApproved), attrs["violation.reason"])
+	require.Equal(t, ruleTypeApprovedCommands, attrs["violation.rule"])
+	require.Equal(t, `["-c" "curl example.com"]`, attrs["proc.args"])
+	require.NotContains(t, attrs, "proc.fs_type")
+
+	tmpfs, _ := bpf.FilesystemMagic("tmpfs")
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:    policymode.ProtectString,
+		Reason:  bpf.ViolationReasonFsNotAllowed,
+		FsMagic: tmpfs,
+	}, "")
+	attrs = recordAttributes(rec)
+	require.Equal(t, string(ViolationReasonFsNotAllowed), attrs["violation.reason"])
+	require.Equal(t, ruleTypeAllowedFilesystems, attrs["violation.rule"])
+	require.Equal(t, "tmpfs", attrs["proc.fs_type"])
+
+	// the magics of the filesystem types a policy cannot allow are reported as is.
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:    policymode.ProtectString,
+		Reason:  bpf.ViolationReasonFsNotAllowed,
+		FsMagic: 0x1234,
+	}, "")
+	require.Equal(t, "0x1234", recordAttributes(rec)["proc.fs_type"])
+
+	fileInfo := *info
+	fileInfo.ExecutablePath = "/etc/passwd"
+	for reason, expected := range map[bpf.ViolationReason][2]string{
+		bpf.ViolationReasonFileReadNotAllowed:  {string(ViolationReasonFileReadNotAllowed), ruleTypeFilesAllowedRead},
+		bpf.ViolationReasonFileWriteNotAllowed: {string(ViolationReasonFileWriteNotAllowed), ruleTypeFilesAllowedWrite},
+		bpf.ViolationReasonFileWriteDenied:     {string(ViolationReasonFileWriteDenied), ruleTypeFilesDeniedWrite},
+	} {
+		rec = es.newViolationRecord(&fileInfo, &bpf.ProcessEvent{
+			Mode:   policymode.ProtectString,
+			Reason: reason,
+		}, "")
+		attrs = recordAttributes(rec)
+		require.Equal(t, expected[0], attrs["violation.reason"])
+		require.Equal(t, expected[1], attrs["violation.rule"])
+		// the path of the event is the opened file.
+		require.Equal(t, "/etc/passwd", attrs["file.path"])
+		require.NotContains(t, attrs, "proc.exepath")
+	}
+
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:        policymode.ProtectString,
+		Reason:      bpf.ViolationReasonEgressNotAllowed,
+		Destination: netip.MustParseAddrPort("[2001:db8::1]:443"),
+	}, "")
+	attrs = recordAttributes(rec)
+	require.Equal(t, string(ViolationReasonEgressNotAllowed), attrs["violation.reason"])
+	require.Equal(t, ruleTypeNetworkAllowedEgress, attrs["violation.rule"])
+	require.Equal(t, "2001:db8::1", attrs["destination.address"])
+	require.Equal(t, "443", attrs["destination.port"])
+	// the path of the event is the executable of the process connecting.
+	require.Equal(t, "/usr/bin/curl", attrs["proc.exepath"])
+
+	rec = es.newViolationRecord(info, &bpf.ProcessEvent{
+		Mode:   policymode.MonitorString,
+		Reason: bpf.ViolationReasonExecNotAllowed,
+		Tgid:   42,
+	}, portscope.ScopeOutOfPorts)
+	require.Equal(t, string(portscope.ScopeOutOfPorts), recordAttributes(rec)["proc.listening_port_scope"])
+}
+
+func TestNewViolationRecordCgroupInfo(t *testing.T) {
+	info := &KubeProcessInfo{Namespace: "test-ns", PodName: "test-pod", PolicyName: "example"}
+	event := &bpf.ProcessEvent{Mode: policymode.MonitorString, Reason: bpf.ViolationReasonExecNotAllowed}
+
+	es := &EventScraper{nodeName: "node-1"}
+	attrs := recordAttributes(es.newViolationRecord(info, event, ""))
+	require.NotContains(t, attrs, "node.cgroup.version")
+	require.NotContains(t, attrs, "node.cgroup.driver")
+
+	driver := ""
+	WithCgroupInfo("cgroupv2", func() string { return driver })(es)
+	attrs = recordAttributes(es.newViolationRecord(info, event, ""))
+	require.Equal(t, "cgroupv2", attrs["node.cgroup.version"])
+	require.NotContains(t, attrs, "node.cgroup.driver", "the driver is unknown until a container is reported")
+
+	driver = cgroups.CgroupDriverSystemd
+	attrs = recordAttributes(es.newViolationRecord(info, event, ""))
+	require.Equal(t, "cgroupv2", attrs["node.cgroup.version"])
+	require.Equal(t, "systemd", attrs["node.cgroup.driver"])
+}
+
+func TestContainerStartTime(t *testing.T) {
+	startTime := time.Date(2026, 4, 24, 15, 36, 7, 617127725, time.UTC)
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{ID: "test-pod-uid", Namespace: "test-ns", Name: "test-pod"},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"cid1": {
+				ContainerMeta: resolver.ContainerMeta{ID: "cid1", Name: "app", CgroupID: 100, StartTime: startTime},
+			},
+			"cid2": {ContainerMeta: resolver.ContainerMeta{ID: "cid2", Name: "sidecar", CgroupID: 101}},
+		},
+	}))
+	es := &EventScraper{resolver: r, nodeName: "node-1"}
+	event := &bpf.ProcessEvent{Mode: policymode.MonitorString, Reason: bpf.ViolationReasonExecNotAllowed}
+
+	event.CgTrackerID = 100
+	info, _, err := es.getKubeProcessInfo(event)
+	require.NoError(t, err)
+	require.Equal(t, startTime, info.ContainerStartTime)
+	attrs := recordAttributes(es.newViolationRecord(info, event, ""))
+	require.Equal(t, "2026-04-24T15:36:07.617127725Z", attrs["container.start_time"])
+
+	// the start time is optional, the runtime may not report it.
+	event.CgTrackerID = 101
+	info, _, err = es.getKubeProcessInfo(event)
+	require.NoError(t, err)
+	require.True(t, info.ContainerStartTime.IsZero())
+	require.NotContains(t, recordAttributes(es.newViolationRecord(info, event, "")), "container.start_time")
+	data, err := json.Marshal(info)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "containerStartTime")
+}
+
+func TestPodLabelAttributes(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.MonitorString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/usr/bin/true"}}},
+			},
+		},
+	}))
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels: map[string]string{
+				v1alpha1.PolicyLabelKey: "example",
+				"team":                  "payments",
+				"app":                   "checkout",
+			},
+		},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"cid1": {ContainerMeta: resolver.ContainerMeta{ID: "cid1", Name: "app", CgroupID: 100}},
+		},
+	}))
+	event := &bpf.ProcessEvent{CgTrackerID: 100, Mode: policymode.MonitorString}
+
+	es := &EventScraper{resolver: r, nodeName: "node-1"}
+	_, podLabels, err := es.getKubeProcessInfo(event)
+	require.NoError(t, err)
+	require.Nil(t, podLabels, "no label is projected by default")
+
+	// the labels missing from the pod are omitted.
+	WithPodLabelAttributes([]string{"team", "cost-center"})(es)
+	info, podLabels, err := es.getKubeProcessInfo(event)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"team": "payments"}, podLabels)
+
+	rec := es.newViolationRecord(info, event, "")
+	addPodLabelAttributes(&rec, podLabels)
+	attrs := recordAttributes(rec)
+	require.Equal(t, "payments", attrs["k8s.pod.label.team"])
+	require.NotContains(t, attrs, "k8s.pod.label.cost-center")
+	require.NotContains(t, attrs, "k8s.pod.label.app")
+	require.NotContains(t, attrs, "k8s.pod.label."+v1alpha1.PolicyLabelKey)
+}
+
+func TestViolationExemplars(t *testing.T) {
+	es := NewEventScraper(
+		nil,
+		nil,
+		slog.New(slog.DiscardHandler),
+		resolver.NewTestResolver(t),
+		nil,
+		WithViolationBuffer(violationbuf.NewBuffer(), "node-1"),
+		WithViolationExemplars(),
+	)
+	info := &KubeProcessInfo{
+		Namespace:      "exemplar-ns",
+		ContainerName:  "app",
+		ExecutablePath: "/usr/bin/curl",
+		PodName:        "test-pod",
+		PolicyName:     "example",
+	}
+	event := &bpf.ProcessEvent{
+		ExePath: "/usr/bin/curl",
+		Mode:    policymode.ProtectString,
+		Reason:  bpf.ViolationReasonExecNotAllowed,
+	}
+	es.processEvent(t.Context(), info, nil, event, false)
+
+	records := es.violationBuffer.Drain()
+	require.Len(t, records, 1)
+	require.Regexp(t, "^[0-9a-f]{32}$", records[0].EventID)
+
+	var metric dto.Metric
+	counter := violationsTotal.WithLabelValues("exemplar-ns", "example", policymode.ProtectString)
+	require.NoError(t, counter.Write(&metric))
+	require.InDelta(t, 1, metric.GetCounter().GetValue(), 0)
+	exemplar := metric.GetCounter().GetExemplar()
+	require.NotNil(t, exemplar, "the increment must carry the event ID as exemplar")
+	require.Len(t, exemplar.GetLabel(), 1)
+	require.Equal(t, exemplarEventIDLabel, exemplar.GetLabel()[0].GetName())
+	require.Equal(t, records[0].EventID, exemplar.GetLabel()[0].GetValue())
+}
+
+func TestStableEventID(t *testing.T) {
+	es := NewEventScraper(
+		nil,
+		nil,
+		slog.New(slog.DiscardHandler),
+		resolver.NewTestResolver(t),
+		nil,
+		WithViolationBuffer(violationbuf.NewBuffer(), "node-1"),
+	)
+	info := &KubeProcessInfo{
+		Namespace:          "event-id-ns",
+		ContainerName:      "app",
+		ExecutablePath:     "/usr/bin/curl",
+		PodName:            "test-pod",
+		PolicyName:         "example",
+		ContainerStartTime: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	event := &bpf.ProcessEvent{
+		CgTrackerID: 42,
+		ExePath:     "/usr/bin/curl",
+		Mode:        policymode.ProtectString,
+		Reason:      bpf.ViolationReasonExecNotAllowed,
+		Tgid:        1234,
+		Sequence:    7,
+	}
+	// the same event exported twice, e.g. when it is retried.
+	es.processEvent(t.Context(), info, nil, event, false)
+	es.processEvent(t.Context(), info, nil, event, false)
+	// the next event of the same process.
+	next := *event
+	next.Sequence++
+	es.processEvent(t.Context(), info, nil, &next, false)
+	// the same event in a restarted container.
+	restarted := *info
+	restarted.ContainerStartTime = restarted.ContainerStartTime.Add(time.Minute)
+	es.processEvent(t.Context(), &restarted, nil, event, false)
+
+	records := es.violationBuffer.Drain()
+	// the records are drained from the most recent one.
+	require.Len(t, records, 4)
+	require.Regexp(t, "^[0-9a-f]{32}$", records[3].EventID)
+	require.Equal(t, records[3].EventID, records[2].EventID)
+	require.NotEqual(t, records[3].EventID, records[1].EventID)
+	require.NotEqual(t, records[3].EventID, records[0].EventID)
+}
+
+func TestNormalizeExecPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "container-absolute path is unchanged",
+			path:     "/usr/bin/sleep",
+			expected: "/usr/bin/sleep",
+		},
+		{
+			name:     "containerd host-prefixed path",
+			path:     "/run/containerd/io.containerd.runtime.v2.task/k8s.io/0123abcd/rootfs/usr/bin/sleep",
+			expected: "/usr/bin/sleep",
+		},
+		{
+			name:     "k3s containerd host-prefixed path",
+			path:     "/run/k3s/containerd/io.containerd.runtime.v2.task/k8s.io/0123abcd/rootfs/bin/sh",
+			expected: "/bin/sh",
+		},
+		{
+			name:     "CRI-O host-prefixed path",
+			path:     "/var/lib/containers/storage/overlay/4567efgh/merged/usr/local/bin/app",
+			expected: "/usr/local/bin/app",
+		},
+		{
+			name:     "a container path that only looks like a rootfs is unchanged",
+			path:     "/opt/run/containerd/rootfs/bin/sh",
+			expected: "/opt/run/containerd/rootfs/bin/sh",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, normalizeExecPath(tt.path))
+		})
+	}
+}
+
+func TestTraceExecDecisions(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/usr/bin/true"}}},
+			},
+		},
+	}))
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"cid1": {ContainerMeta: resolver.ContainerMeta{ID: "cid1", Name: "app", CgroupID: 100}},
+			"cid2": {ContainerMeta: resolver.ContainerMeta{ID: "cid2", Name: "sidecar", CgroupID: 101}},
+		},
+	}))
+
+	var logs bytes.Buffer
+	monitoringChannel := make(chan bpf.ProcessEvent)
+	es := NewEventScraper(
+		nil,
+		monitoringChannel,
+		slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		r,
+		nil,
+		WithViolationBuffer(violationbuf.NewBuffer(), "node-1"),
+	)
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() { done <- es.Start(ctx) }()
+
+	// far more events than any rate limiter of the scraper would let through.
+	const allowedExecs = 100
+	allowed := bpf.ProcessEvent{CgTrackerID: 100, ExePath: "/usr/bin/true", Mode: policymode.ProtectString}
+	blocked := bpf.ProcessEvent{
+		CgTrackerID: 100,
+		ExePath:     "/usr/bin/curl",
+		Mode:        policymode.ProtectString,
+		Reason:      bpf.ViolationReasonExecNotAllowed,
+	}
+
+	_, err := r.StartTrace("test-ns", "test-pod", "app", time.Minute)
+	require.NoError(t, err)
+
+	for range allowedExecs {
+		monitoringChannel <- allowed
+	}
+	monitoringChannel <- blocked
+	// the violations of the containers that are not traced are not logged.
+	untraced := blocked
+	untraced.CgTrackerID = 101
+	monitoringChannel <- untraced
+
+	cancel()
+	require.NoError(t, <-done)
+
+	var decisions []map[string]any
+	for line := range strings.Lines(logs.String()) {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "exec decision" {
+			decisions = append(decisions, entry)
+		}
+	}
+	require.Len(t, decisions, allowedExecs+1, "every decision of the trace window must be logged")
+	for _, entry := range decisions[:allowedExecs] {
+		require.Equal(t, "DEBUG", entry["level"])
+		require.Equal(t, execDecisionAllowed, entry["decision"])
+		require.Equal(t, "/usr/bin/true", entry["exe"])
+		require.Equal(t, ruleTypeExecutablesAllowed, entry["rule"])
+		require.Empty(t, entry["reason"])
+		require.Equal(t, "example", entry["policy"])
+		require.NotZero(t, entry["policyID"])
+	}
+	last := decisions[allowedExecs]
+	require.Equal(t, execDecisionBlocked, last["decision"])
+	require.Equal(t, "/usr/bin/curl", last["exe"])
+	require.Equal(t, string(ViolationReasonExecNotAllowed), last["reason"])
+}
+
+func TestAuditedExecs(t *testing.T) {
+	var logs bytes.Buffer
+	es := NewEventScraper(
+		nil,
+		nil,
+		slog.New(slog.NewJSONHandler(&logs, nil)),
+		resolver.NewTestResolver(t),
+		nil,
+		WithViolationBuffer(violationbuf.NewBuffer(), "node-1"),
+	)
+	info := &KubeProcessInfo{
+		Namespace:      "audit-ns",
+		ContainerName:  "app",
+		ExecutablePath: "/usr/bin/true",
+		PodName:        "test-pod",
+		PolicyName:     "example",
+	}
+	es.processEvent(t.Context(), info, nil, &bpf.ProcessEvent{Mode: policymode.AuditString}, false)
+	denied := *info
+	denied.ExecutablePath = "/usr/bin/curl"
+	es.processEvent(t.Context(), &denied, nil, &bpf.ProcessEvent{
+		Mode:   policymode.AuditString,
+		Reason: bpf.ViolationReasonExecNotAllowed,
+	}, false)
+	// the execs of the policies in monitor mode are not audited.
+	es.processEvent(t.Context(), &denied, nil, &bpf.ProcessEvent{
+		Mode:   policymode.MonitorString,
+		Reason: bpf.ViolationReasonExecNotAllowed,
+	}, false)
+
+	var audited []map[string]any
+	for line := range strings.Lines(logs.String()) {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "exec audited" {
+			audited = append(audited, entry)
+		}
+	}
+	require.Len(t, audited, 2)
+	require.Equal(t, auditDecisionAllowed, audited[0]["decision"])
+	require.Equal(t, "/usr/bin/true", audited[0]["exe"])
+	require.Empty(t, audited[0]["reason"])
+	require.Equal(t, auditDecisionWouldBeDenied, audited[1]["decision"])
+	require.Equal(t, "/usr/bin/curl", audited[1]["exe"])
+	require.Equal(t, string(ViolationReasonExecNotAllowed), audited[1]["reason"])
+	require.Equal(t, ruleTypeExecutablesAllowed, audited[1]["rule"])
+
+	// only the denied execs are reported as violations.
+	records := es.violationBuffer.Drain()
+	require.Len(t, records, 2)
+	require.ElementsMatch(t,
+		[]string{policymode.AuditString, policymode.MonitorString},
+		[]string{records[0].Action, records[1].Action})
+}
+
+func TestParseUnresolvedCgroupStrategy(t *testing.T) {
+	for _, s := range []string{"drop", "log", "retry"} {
+		strategy, err := ParseUnresolvedCgroupStrategy(s)
+		require.NoError(t, err)
+		require.Equal(t, UnresolvedCgroupStrategy(s), strategy)
+	}
+	_, err := ParseUnresolvedCgroupStrategy("ignore")
+	require.ErrorContains(t, err, "invalid unresolved cgroup strategy")
+}
+
+func TestRetryUnresolvedCgroup(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.MonitorString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/usr/bin/true"}}},
+			},
+		},
+	}))
+
+	var logs bytes.Buffer
+	monitoringChannel := make(chan bpf.ProcessEvent)
+	history := violationbuf.NewHistory(10)
+	es := NewEventScraper(
+		nil,
+		monitoringChannel,
+		slog.New(slog.NewJSONHandler(&logs, nil)),
+		r,
+		nil,
+		WithViolationBuffer(violationbuf.NewBuffer(), "node-1"),
+		WithViolationHistory(history),
+		WithUnresolvedCgroupConfig(UnresolvedCgroupConfig{
+			Strategy:     UnresolvedCgroupRetry,
+			LogLevel:     slog.LevelWarn,
+			RetryTimeout: time.Minute,
+		}),
+	)
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() { done <- es.Start(ctx) }()
+
+	// the exec is reported before the runtime reported the container.
+	monitoringChannel <- bpf.ProcessEvent{
+		CgTrackerID: 100,
+		ExePath:     "/usr/bin/curl",
+		Mode:        policymode.MonitorString,
+		Reason:      bpf.ViolationReasonExecNotAllowed,
+	}
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"cid1": {ContainerMeta: resolver.ContainerMeta{ID: "cid1", Name: "app", CgroupID: 100}},
+		},
+	}))
+
+	require.Eventually(t, func() bool {
+		return len(history.Query(violationbuf.HistoryFilter{})) == 1
+	}, 5*time.Second, 10*time.Millisecond, "the event should be resolved once the pod is known")
+	cancel()
+	require.NoError(t, <-done)
+
+	rec := history.Query(violationbuf.HistoryFilter{})[0]
+	require.Equal(t, "test-ns", rec.Namespace)
+	require.Equal(t, "test-pod", rec.PodName)
+	require.Equal(t, "app", rec.ContainerName)
+	require.Equal(t, "example", rec.PolicyName)
+	require.Equal(t, "/usr/bin/curl", rec.ExePath)
+	require.NotContains(t, logs.String(), unresolvedCgroupMsg)
+}
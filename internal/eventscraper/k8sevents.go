@@ -0,0 +1,121 @@
+package eventscraper
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+const (
+	// PolicyViolationEventReason is the reason of the events reporting a violation on the pod.
+	PolicyViolationEventReason = "PolicyViolation"
+
+	violationEventActionBlock   = "Block"
+	violationEventActionMonitor = "Monitor"
+	// maxEventNoteLength is the maximum length of the note of an event accepted by the API server.
+	maxEventNoteLength = 1024
+)
+
+// podEventLimiter limits the number of events recorded for each pod, so that a pod repeatedly violating
+// its policy doesn't flood the API server. Like the log rate limiters, the events beyond the limit are dropped.
+type podEventLimiter struct {
+	interval  time.Duration
+	limiters  map[types.NamespacedName]*rate.Limiter
+	lastPrune time.Time
+}
+
+func newPodEventLimiter(interval time.Duration) *podEventLimiter {
+	return &podEventLimiter{
+		interval: interval,
+		limiters: make(map[types.NamespacedName]*rate.Limiter),
+	}
+}
+
+// allow reports whether an event can be recorded at now for the pod.
+func (l *podEventLimiter) allow(pod types.NamespacedName, now time.Time) bool {
+	l.prune(now)
+	limiter, ok := l.limiters[pod]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(l.interval), 1)
+		l.limiters[pod] = limiter
+	}
+	return limiter.AllowN(now, 1)
+}
+
+// prune forgets the limiters that have refilled their token, they behave as new ones,
+// so that the limiters of the removed pods are not retained.
+func (l *podEventLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.interval {
+		return
+	}
+	l.lastPrune = now
+	for pod, limiter := range l.limiters {
+		if limiter.TokensAt(now) >= 1 {
+			delete(l.limiters, pod)
+		}
+	}
+}
+
+// WithViolationEvents records the violations as Kubernetes events on the pods, so that they are listed by
+// `kubectl describe pod` and can be used by the event-based alerting.
+// At most one event is recorded for each pod every interval, the following violations are dropped.
+func WithViolationEvents(recorder events.EventRecorder, interval time.Duration) Option {
+	return func(es *EventScraper) {
+		es.eventRecorder = recorder
+		es.podEventLimiter = newPodEventLimiter(interval)
+	}
+}
+
+// violationEventNote returns the note of the event reporting the violation.
+func violationEventNote(info *KubeProcessInfo, event *bpf.ProcessEvent) string {
+	reason, rule := violationReasonAndRule(event.Reason)
+	subject := "executable " + info.ExecutablePath
+	switch {
+	case isFileViolation(event.Reason):
+		subject = "file " + info.ExecutablePath
+	case event.Reason == bpf.ViolationReasonEgressNotAllowed:
+		subject = fmt.Sprintf("connection to %s by executable %s", event.Destination, info.ExecutablePath)
+	}
+	note := fmt.Sprintf("%s violation in container %s: %s, rule %s of policy %s",
+		reason, info.ContainerName, subject, rule, info.PolicyName)
+	if len(note) <= maxEventNoteLength {
+		return note
+	}
+	// cut on a rune boundary, so that the note stays valid UTF-8.
+	end := maxEventNoteLength
+	for end > 0 && !utf8.RuneStart(note[end]) {
+		end--
+	}
+	return note[:end]
+}
+
+// recordViolationEvent records the violation as an event on the pod, unless the pod exceeded its rate limit.
+func (es *EventScraper) recordViolationEvent(info *KubeProcessInfo, event *bpf.ProcessEvent, now time.Time) {
+	if es.eventRecorder == nil {
+		return
+	}
+	if !es.podEventLimiter.allow(types.NamespacedName{Namespace: info.Namespace, Name: info.PodName}, now) {
+		return
+	}
+	pod := &corev1.ObjectReference{
+		Kind:       "Pod",
+		APIVersion: "v1",
+		Namespace:  info.Namespace,
+		Name:       info.PodName,
+		UID:        types.UID(info.PodUID),
+	}
+	action := violationEventActionMonitor
+	if event.Mode == policymode.ProtectString {
+		action = violationEventActionBlock
+	}
+	es.eventRecorder.Eventf(pod, nil, corev1.EventTypeWarning, PolicyViolationEventReason, action,
+		"%s", violationEventNote(info, event))
+}
@@ -0,0 +1,131 @@
+package eventscraper
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type recordedEvent struct {
+	regarding *corev1.ObjectReference
+	eventType string
+	reason    string
+	action    string
+	note      string
+}
+
+// fakeRecorder keeps the recorded events, unlike events.FakeRecorder it also keeps their object and action.
+type fakeRecorder struct {
+	events []recordedEvent
+}
+
+func (f *fakeRecorder) Eventf(regarding, _ runtime.Object, eventType, reason, action, note string, args ...any) {
+	ref, _ := regarding.(*corev1.ObjectReference)
+	f.events = append(f.events, recordedEvent{
+		regarding: ref,
+		eventType: eventType,
+		reason:    reason,
+		action:    action,
+		note:      fmt.Sprintf(note, args...),
+	})
+}
+
+func TestViolationEvents(t *testing.T) {
+	recorder := &fakeRecorder{}
+	es := &EventScraper{}
+	WithViolationEvents(recorder, time.Minute)(es)
+
+	info := &KubeProcessInfo{
+		Namespace:      "test-ns",
+		ContainerName:  "app",
+		ExecutablePath: "/usr/bin/curl",
+		PodName:        "test-pod",
+		PodUID:         "test-pod-uid",
+		PolicyName:     "example",
+	}
+	event := &bpf.ProcessEvent{
+		CgTrackerID: 100,
+		Mode:        policymode.ProtectString,
+		Reason:      bpf.ViolationReasonExecNotAllowed,
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	es.recordViolationEvent(info, event, now)
+	require.Len(t, recorder.events, 1)
+	recorded := recorder.events[0]
+	require.Equal(t, &corev1.ObjectReference{
+		Kind:       "Pod",
+		APIVersion: "v1",
+		Namespace:  "test-ns",
+		Name:       "test-pod",
+		UID:        types.UID("test-pod-uid"),
+	}, recorded.regarding)
+	require.Equal(t, corev1.EventTypeWarning, recorded.eventType)
+	require.Equal(t, PolicyViolationEventReason, recorded.reason)
+	require.Equal(t, violationEventActionBlock, recorded.action)
+	require.Equal(t,
+		"EXEC_NOT_ALLOWED violation in container app: executable /usr/bin/curl, rule executables.allowed of policy example",
+		recorded.note)
+
+	// the following violations of the pod are dropped until the interval elapsed.
+	event.Mode = policymode.MonitorString
+	es.recordViolationEvent(info, event, now.Add(30*time.Second))
+	require.Len(t, recorder.events, 1)
+
+	// the other pods are limited separately.
+	other := *info
+	other.PodName = "other-pod"
+	other.PodUID = "other-pod-uid"
+	es.recordViolationEvent(&other, event, now.Add(30*time.Second))
+	require.Len(t, recorder.events, 2)
+	require.Equal(t, "other-pod", recorder.events[1].regarding.Name)
+	require.Equal(t, types.UID("other-pod-uid"), recorder.events[1].regarding.UID)
+
+	es.recordViolationEvent(info, event, now.Add(time.Minute))
+	require.Len(t, recorder.events, 3)
+	require.Equal(t, violationEventActionMonitor, recorder.events[2].action)
+
+	// the limiters of the pods without recent violation are forgotten.
+	es.recordViolationEvent(info, event, now.Add(3*time.Minute))
+	require.Len(t, recorder.events, 4)
+	require.Len(t, es.podEventLimiter.limiters, 1)
+}
+
+func TestViolationEventNote(t *testing.T) {
+	info := &KubeProcessInfo{ContainerName: "app", ExecutablePath: "/etc/shadow", PolicyName: "example"}
+	note := violationEventNote(info, &bpf.ProcessEvent{Reason: bpf.ViolationReasonFileReadNotAllowed})
+	require.Equal(t,
+		"FILE_READ_NOT_ALLOWED violation in container app: file /etc/shadow, rule files.allowedRead of policy example",
+		note)
+
+	info.ExecutablePath = "/usr/bin/curl"
+	note = violationEventNote(info, &bpf.ProcessEvent{
+		Reason:      bpf.ViolationReasonEgressNotAllowed,
+		Destination: netip.MustParseAddrPort("192.0.2.1:443"),
+	})
+	require.Equal(t,
+		"EGRESS_NOT_ALLOWED violation in container app: connection to 192.0.2.1:443 by executable /usr/bin/curl, "+
+			"rule network.allowedEgress of policy example",
+		note)
+
+	// the note is truncated to the length accepted by the API server.
+	info.ExecutablePath = "/" + strings.Repeat("a", 4095)
+	note = violationEventNote(info, &bpf.ProcessEvent{Reason: bpf.ViolationReasonExecNotAllowed})
+	require.Len(t, note, maxEventNoteLength)
+
+	// a multi-byte rune crossing the limit is dropped as a whole.
+	info.ExecutablePath = "/" + strings.Repeat("é", 2048)
+	note = violationEventNote(info, &bpf.ProcessEvent{Reason: bpf.ViolationReasonExecNotAllowed})
+	require.True(t, utf8.ValidString(note))
+	require.LessOrEqual(t, len(note), maxEventNoteLength)
+	require.Greater(t, len(note), maxEventNoteLength-utf8.UTFMax)
+}
@@ -0,0 +1,35 @@
+package eventscraper
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// exemplarEventIDLabel is the exemplar label carrying the ID of the violation event.
+const exemplarEventIDLabel = "event_id"
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var violationsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "runtime_enforcer_violations_total",
+		Help: "Number of policy violations reported by the agent, by namespace, policy and action.",
+	},
+	[]string{"namespace", "policy", "action"},
+)
+
+// RegisterMetrics registers the event scraper metrics in the given registry.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	return registerer.Register(violationsTotal)
+}
+
+// countViolation increments the violation counter. With WithViolationExemplars, the increment carries the ID
+// of the violation event as exemplar, which is only exposed by the OpenMetrics format.
+func (es *EventScraper) countViolation(info *KubeProcessInfo, action, eventID string) {
+	counter := violationsTotal.WithLabelValues(info.Namespace, info.PolicyName, action)
+	if es.violationExemplars {
+		if adder, ok := counter.(prometheus.ExemplarAdder); ok {
+			adder.AddWithExemplar(1, prometheus.Labels{exemplarEventIDLabel: eventID})
+			return
+		}
+	}
+	counter.Inc()
+}
@@ -0,0 +1,103 @@
+package eventscraper
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+// ruleTypes are the rule types reported in the `violation.rule` attribute of the violation events.
+//
+//nolint:gochecknoglobals // read-only list of the rule types.
+var ruleTypes = []string{
+	ruleTypeExecutablesAllowed,
+	ruleTypeBlockSuidExec,
+	ruleTypeBlockScripts,
+	ruleTypeMaxDistinctExecutables,
+	ruleTypeBlockUnlinkedExec,
+	ruleTypeApprovedCommands,
+	ruleTypeAllowedFilesystems,
+	ruleTypeFilesAllowedRead,
+	ruleTypeFilesAllowedWrite,
+	ruleTypeFilesDeniedWrite,
+	ruleTypeNetworkAllowedEgress,
+}
+
+// severityNames are the severities accepted in a severity mapping.
+//
+//nolint:gochecknoglobals // read-only table of the severity names.
+var severityNames = map[string]otellog.Severity{
+	"debug": otellog.SeverityDebug,
+	"info":  otellog.SeverityInfo,
+	"warn":  otellog.SeverityWarn,
+	"error": otellog.SeverityError,
+	"fatal": otellog.SeverityFatal,
+}
+
+// SeverityMapping overrides the severity of the violation events by rule type. A key is a rule type
+// (e.g. blockScripts), or a rule type and an executable separated by `:` (e.g. executables.allowed:/usr/bin/curl)
+// for the violations of that executable only, which takes precedence over the rule type alone.
+type SeverityMapping map[string]otellog.Severity
+
+// ParseSeverityMapping parses a `,`-separated list of `rule=severity` items, see SeverityMapping for the rules.
+// The severity is one of debug, info, warn, error and fatal.
+func ParseSeverityMapping(spec string) (SeverityMapping, error) {
+	mapping := make(SeverityMapping)
+	for item := range strings.SplitSeq(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		rule, name, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid severity mapping %q, expected rule=severity", item)
+		}
+		if err := validateSeverityRule(rule); err != nil {
+			return nil, fmt.Errorf("invalid severity mapping %q: %w", item, err)
+		}
+		severity, ok := severityNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid severity mapping %q: unknown severity %q, must be one of: "+
+				"debug|info|warn|error|fatal", item, name)
+		}
+		if _, dup := mapping[rule]; dup {
+			return nil, fmt.Errorf("invalid severity mapping %q: rule %s is mapped more than once", item, rule)
+		}
+		mapping[rule] = severity
+	}
+	return mapping, nil
+}
+
+func validateSeverityRule(rule string) error {
+	ruleType, executable, specific := strings.Cut(rule, ":")
+	if !slices.Contains(ruleTypes, ruleType) {
+		return fmt.Errorf("unknown rule type %q, must be one of: %s", ruleType, strings.Join(ruleTypes, "|"))
+	}
+	if specific && !strings.HasPrefix(executable, "/") {
+		return errors.New("the executable of a rule must be an absolute path")
+	}
+	return nil
+}
+
+// WithSeverityMapping overrides the severity of the violation events matching the mapping.
+// The other events keep the default severity: error when the exec is blocked, warn otherwise.
+func WithSeverityMapping(mapping SeverityMapping) Option {
+	return func(es *EventScraper) {
+		es.severityMapping = mapping
+	}
+}
+
+// violationSeverity returns the severity of a violation of the rule type by the executable:
+// the one of the mapping when it has one, else blocked execs are more severe than monitored ones.
+func (m SeverityMapping) violationSeverity(ruleType, executable, action string) otellog.Severity {
+	if severity, ok := m[ruleType+":"+executable]; ok {
+		return severity
+	}
+	if severity, ok := m[ruleType]; ok {
+		return severity
+	}
+	return violationSeverity(action)
+}
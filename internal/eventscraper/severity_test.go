@@ -0,0 +1,58 @@
+package eventscraper
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+func TestSeverityMapping(t *testing.T) {
+	mapping, err := ParseSeverityMapping(
+		"blockScripts=fatal, executables.allowed=info,executables.allowed:/usr/bin/curl=ERROR")
+	require.NoError(t, err)
+	es := &EventScraper{nodeName: "node-1"}
+	WithSeverityMapping(mapping)(es)
+
+	for _, tc := range []struct {
+		name     string
+		exe      string
+		mode     string
+		reason   bpf.ViolationReason
+		expected otellog.Severity
+	}{
+		{"rule type", "/bin/sh", policymode.ProtectString, bpf.ViolationReasonScriptExec, otellog.SeverityFatal},
+		{"rule type in monitor mode", "/bin/sh", policymode.MonitorString, bpf.ViolationReasonScriptExec,
+			otellog.SeverityFatal},
+		{"rule type lowered", "/usr/bin/wget", policymode.ProtectString, bpf.ViolationReasonExecNotAllowed,
+			otellog.SeverityInfo},
+		{"specific executable", "/usr/bin/curl", policymode.MonitorString, bpf.ViolationReasonExecNotAllowed,
+			otellog.SeverityError},
+		{"unmapped blocked", "/bin/su", policymode.ProtectString, bpf.ViolationReasonSuidExec, otellog.SeverityError},
+		{"unmapped monitored", "/bin/su", policymode.MonitorString, bpf.ViolationReasonSuidExec, otellog.SeverityWarn},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &KubeProcessInfo{Namespace: "test-ns", PodName: "test-pod", ExecutablePath: tc.exe}
+			rec := es.newViolationRecord(info, &bpf.ProcessEvent{Mode: tc.mode, Reason: tc.reason}, "")
+			require.Equal(t, tc.expected, rec.Severity())
+		})
+	}
+}
+
+func TestParseSeverityMappingErrors(t *testing.T) {
+	for spec, expected := range map[string]string{
+		"blockScripts":                         "expected rule=severity",
+		"blockSockets=error":                   "unknown rule type",
+		"executables.allowed:curl=error":       "must be an absolute path",
+		"blockScripts=critical":                "unknown severity",
+		"blockScripts=error,blockScripts=info": "mapped more than once",
+	} {
+		_, err := ParseSeverityMapping(spec)
+		require.ErrorContains(t, err, expected, spec)
+	}
+	mapping, err := ParseSeverityMapping("")
+	require.NoError(t, err)
+	require.Empty(t, mapping)
+}
@@ -0,0 +1,144 @@
+package eventscraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+// UnresolvedCgroupStrategy defines how the events whose cgroup is not associated with any pod are handled.
+type UnresolvedCgroupStrategy string
+
+const (
+	// UnresolvedCgroupDrop drops the events silently.
+	UnresolvedCgroupDrop UnresolvedCgroupStrategy = "drop"
+	// UnresolvedCgroupLog logs the events with their raw cgroup ID.
+	UnresolvedCgroupLog UnresolvedCgroupStrategy = "log"
+	// UnresolvedCgroupRetry buffers the events and retries to resolve them until a timeout,
+	// because the runtime may report a container slightly after its first execs.
+	// The events still unresolved at the timeout are logged as with UnresolvedCgroupLog.
+	UnresolvedCgroupRetry UnresolvedCgroupStrategy = "retry"
+)
+
+const (
+	// DefaultUnresolvedCgroupRetryTimeout is the default time an unresolved event is retried for.
+	DefaultUnresolvedCgroupRetryTimeout = 2 * time.Second
+	// unresolvedRetryInterval is the interval between two resolution attempts of the buffered events.
+	unresolvedRetryInterval = 100 * time.Millisecond
+	// maxPendingUnresolvedEvents bounds the buffered events, the oldest one is given up when it is full.
+	maxPendingUnresolvedEvents = 1024
+	unresolvedCgroupMsg        = "failed to resolve the pod of the event cgroup"
+)
+
+// UnresolvedCgroupConfig configures the handling of the events whose cgroup is not associated with any pod.
+type UnresolvedCgroupConfig struct {
+	Strategy UnresolvedCgroupStrategy
+	// LogLevel is the level of the logs of the unresolved events.
+	LogLevel slog.Level
+	// RetryTimeout is the time an event is retried for with UnresolvedCgroupRetry.
+	RetryTimeout time.Duration
+}
+
+// ParseUnresolvedCgroupStrategy parses the name of an UnresolvedCgroupStrategy.
+func ParseUnresolvedCgroupStrategy(s string) (UnresolvedCgroupStrategy, error) {
+	switch strategy := UnresolvedCgroupStrategy(s); strategy {
+	case UnresolvedCgroupDrop, UnresolvedCgroupLog, UnresolvedCgroupRetry:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid unresolved cgroup strategy %q, must be one of: drop|log|retry", s)
+	}
+}
+
+// WithUnresolvedCgroupConfig sets how the events whose cgroup is not associated with any pod are handled.
+// By default, they are logged at the error level.
+func WithUnresolvedCgroupConfig(cfg UnresolvedCgroupConfig) Option {
+	return func(es *EventScraper) {
+		if cfg.RetryTimeout <= 0 {
+			cfg.RetryTimeout = DefaultUnresolvedCgroupRetryTimeout
+		}
+		es.unresolvedCgroup = cfg
+	}
+}
+
+// pendingEvent is an unresolved event buffered until deadline.
+type pendingEvent struct {
+	event    bpf.ProcessEvent
+	learning bool
+	deadline time.Time
+}
+
+// handleUnresolvedEvent handles an event that could not be enriched with its pod info.
+func (es *EventScraper) handleUnresolvedEvent(
+	ctx context.Context,
+	event *bpf.ProcessEvent,
+	learning bool,
+	err error,
+) {
+	if !errors.Is(err, resolver.ErrMissingPodUID) {
+		// the cgroup is associated with a pod but the cache is inconsistent, retrying won't help.
+		es.logger.ErrorContext(ctx, "failed to get pod info",
+			"cgTrackerID", event.CgTrackerID,
+			"exe", event.ExePath,
+			"error", err)
+		return
+	}
+
+	switch es.unresolvedCgroup.Strategy {
+	case UnresolvedCgroupDrop:
+		// the event is dropped silently.
+	case UnresolvedCgroupRetry:
+		if len(es.pendingEvents) >= maxPendingUnresolvedEvents {
+			es.logUnresolvedEvent(ctx, &es.pendingEvents[0].event, err)
+			es.pendingEvents = es.pendingEvents[1:]
+		}
+		es.pendingEvents = append(es.pendingEvents, pendingEvent{
+			event:    *event,
+			learning: learning,
+			deadline: time.Now().Add(es.unresolvedCgroup.RetryTimeout),
+		})
+	default:
+		es.logUnresolvedEvent(ctx, event, err)
+	}
+}
+
+// retryUnresolvedEvents tries again to resolve the buffered events, the resolved ones are processed
+// and the ones that expired are logged.
+// The whole batch is resolved under a single acquisition of the resolver lock, so that a burst of
+// unresolved events doesn't compete with the reconciliation of the policies.
+func (es *EventScraper) retryUnresolvedEvents(ctx context.Context, now time.Time) {
+	if len(es.pendingEvents) == 0 {
+		return
+	}
+	cgIDs := make([]resolver.CgroupID, 0, len(es.pendingEvents))
+	for _, pending := range es.pendingEvents {
+		cgIDs = append(cgIDs, pending.event.CgTrackerID)
+	}
+	views, errs := es.resolver.GetContainerViews(cgIDs)
+
+	remaining := es.pendingEvents[:0]
+	for i, pending := range es.pendingEvents {
+		switch err := errs[i]; {
+		case err == nil:
+			kubeInfo, podLabels := es.kubeProcessInfo(views[i], &pending.event)
+			es.processEvent(ctx, kubeInfo, podLabels, &pending.event, pending.learning)
+		case errors.Is(err, resolver.ErrMissingPodUID) && now.Before(pending.deadline):
+			remaining = append(remaining, pending)
+		default:
+			es.logUnresolvedEvent(ctx, &pending.event, err)
+		}
+	}
+	clear(es.pendingEvents[len(remaining):])
+	es.pendingEvents = remaining
+}
+
+func (es *EventScraper) logUnresolvedEvent(ctx context.Context, event *bpf.ProcessEvent, err error) {
+	es.logger.Log(ctx, es.unresolvedCgroup.LogLevel, unresolvedCgroupMsg,
+		"cgTrackerID", event.CgTrackerID,
+		"exe", event.ExePath,
+		"error", err)
+}
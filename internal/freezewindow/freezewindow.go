@@ -0,0 +1,181 @@
+// Package freezewindow parses the maintenance freeze windows, during which the policy changes are deferred.
+//
+// A schedule is a `;`-separated list of windows. Each window is made of a day-of-week field, with the cron syntax
+// (`*`, `1-5`, `Mon-Fri`, `Sat,Sun`, with 0 and 7 both meaning Sunday), and a `HH:MM-HH:MM` time range, in UTC.
+// The range starts on the listed days, and ends on the next day when its end is not after its start:
+//
+//	Mon-Fri 08:00-18:00; Fri 22:00-06:00
+package freezewindow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// maxFreeze bounds the search of the end of a freeze made of chained windows, so that a schedule covering
+// the whole week still returns.
+const maxFreeze = 2 * 7 * day
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type window struct {
+	days [7]bool
+	// start and end are the offsets of the range from the midnight of the day it starts.
+	start time.Duration
+	end   time.Duration
+}
+
+// Schedule is a set of weekly freeze windows.
+type Schedule struct {
+	windows []window
+}
+
+// Parse parses a schedule, see the package documentation for its syntax.
+// An empty spec returns a nil schedule, which is never active.
+func Parse(spec string) (*Schedule, error) {
+	var s Schedule
+	for item := range strings.SplitSeq(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		w, err := parseWindow(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid freeze window %q: %w", item, err)
+		}
+		s.windows = append(s.windows, w)
+	}
+	if len(s.windows) == 0 {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func parseWindow(item string) (window, error) {
+	fields := strings.Fields(item)
+	if len(fields) != 2 {
+		return window{}, fmt.Errorf("expected a day-of-week field and a time range, got %d fields", len(fields))
+	}
+
+	var w window
+	var err error
+	if w.days, err = parseDays(fields[0]); err != nil {
+		return window{}, err
+	}
+	startStr, endStr, found := strings.Cut(fields[1], "-")
+	if !found {
+		return window{}, fmt.Errorf("invalid time range %q, expected HH:MM-HH:MM", fields[1])
+	}
+	if w.start, err = parseTimeOfDay(startStr); err != nil {
+		return window{}, err
+	}
+	if w.end, err = parseTimeOfDay(endStr); err != nil {
+		return window{}, err
+	}
+	if w.end == w.start {
+		return window{}, fmt.Errorf("empty time range %q", fields[1])
+	}
+	if w.end < w.start {
+		w.end += day
+	}
+	return w, nil
+}
+
+func parseDays(field string) ([7]bool, error) {
+	var days [7]bool
+	for item := range strings.SplitSeq(field, ",") {
+		if item == "*" {
+			for i := range days {
+				days[i] = true
+			}
+			continue
+		}
+		firstStr, lastStr, isRange := strings.Cut(item, "-")
+		first, err := parseDay(firstStr)
+		if err != nil {
+			return days, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseDay(lastStr); err != nil {
+				return days, err
+			}
+			// 7 is Sunday too, so that `Fri-7` ends the range on Sunday as in cron.
+			if lastStr == "7" {
+				last = 7
+			}
+		}
+		if last < first {
+			return days, fmt.Errorf("invalid day range %q, the first day must not be after the last one", item)
+		}
+		for d := first; d <= last; d++ {
+			days[d%7] = true
+		}
+	}
+	return days, nil
+}
+
+func parseDay(s string) (int, error) {
+	if d, ok := dayNames[strings.ToLower(s)]; ok {
+		return d, nil
+	}
+	d, err := strconv.Atoi(s)
+	if err != nil || d < 0 || d > 7 {
+		return 0, fmt.Errorf("invalid day of week %q, expected 0-7 or Sun-Sat", s)
+	}
+	return d % 7, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM: %w", s, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// windowEnd returns the latest end of the windows containing t.
+func (s *Schedule) windowEnd(t time.Time) (time.Time, bool) {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	var end time.Time
+	for _, w := range s.windows {
+		// a window started the day before may still be running.
+		for _, start := range []time.Time{midnight, midnight.Add(-day)} {
+			if !w.days[start.Weekday()] {
+				continue
+			}
+			if wEnd := start.Add(w.end); !t.Before(start.Add(w.start)) && t.Before(wEnd) && wEnd.After(end) {
+				end = wEnd
+			}
+		}
+	}
+	return end, !end.IsZero()
+}
+
+// ActiveUntil returns whether t is within a freeze window and, if so, the time the freeze ends.
+// Overlapping and contiguous windows are chained, so the returned time is never within a window.
+// A nil schedule is never active.
+func (s *Schedule) ActiveUntil(t time.Time) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+	t = t.UTC()
+	end, active := s.windowEnd(t)
+	if !active {
+		return time.Time{}, false
+	}
+	for end.Sub(t) < maxFreeze {
+		next, chained := s.windowEnd(end)
+		if !chained {
+			break
+		}
+		end = next
+	}
+	return end, true
+}
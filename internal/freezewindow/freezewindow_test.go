@@ -0,0 +1,88 @@
+package freezewindow_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/freezewindow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{
+		"* 00:00-06:00",
+		"Mon-Fri 08:00-18:00",
+		"1-5 08:00-18:00; sat,SUN 10:00-12:00",
+		"Fri-7 22:00-06:00",
+		" Mon 08:00-09:00 ; ",
+	} {
+		schedule, err := freezewindow.Parse(spec)
+		require.NoError(t, err, spec)
+		require.NotNil(t, schedule, spec)
+	}
+
+	schedule, err := freezewindow.Parse(" ")
+	require.NoError(t, err)
+	require.Nil(t, schedule)
+
+	for _, spec := range []string{
+		"08:00-18:00",
+		"Mon 08:00",
+		"Mon 08:00-08:00",
+		"Mon 25:00-26:00",
+		"Funday 08:00-18:00",
+		"8 08:00-18:00",
+		"Fri-Mon 08:00-18:00",
+		"Mon 08:00-18:00 extra",
+	} {
+		_, err = freezewindow.Parse(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestActiveUntil(t *testing.T) {
+	schedule, err := freezewindow.Parse("Mon-Fri 08:00-18:00; Fri 22:00-06:00; Sat 06:00-12:00")
+	require.NoError(t, err)
+
+	// 2026-10-12 is a Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name   string
+		t      time.Time
+		active bool
+		until  time.Time
+	}{
+		{name: "before the window", t: at(12, 7, 59)},
+		{name: "start of the window", t: at(12, 8, 0), active: true, until: at(12, 18, 0)},
+		{name: "end of the window", t: at(12, 18, 0)},
+		{name: "weekend outside the windows", t: at(18, 9, 0)},
+		{name: "overnight window", t: at(16, 23, 0), active: true, until: at(17, 12, 0)},
+		{name: "overnight window after midnight", t: at(17, 5, 0), active: true, until: at(17, 12, 0)},
+		{name: "chained window", t: at(17, 11, 0), active: true, until: at(17, 12, 0)},
+		{
+			name:   "other time zone",
+			t:      time.Date(2026, time.October, 12, 11, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)),
+			active: true,
+			until:  at(12, 18, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, active := schedule.ActiveUntil(tt.t)
+			require.Equal(t, tt.active, active)
+			require.True(t, tt.until.Equal(until), "expected %s, got %s", tt.until, until)
+		})
+	}
+
+	always, err := freezewindow.Parse("* 00:00-12:00; * 12:00-00:00")
+	require.NoError(t, err)
+	until, active := always.ActiveUntil(at(12, 10, 0))
+	require.True(t, active)
+	require.False(t, until.Before(at(26, 10, 0)))
+
+	var none *freezewindow.Schedule
+	_, active = none.ActiveUntil(at(12, 10, 0))
+	require.False(t, active)
+}
@@ -2,6 +2,9 @@ package grpcexporter
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"strings"
 
 	"log/slog"
 
@@ -18,17 +21,20 @@ type agentObserver struct {
 	logger          *slog.Logger
 	resolver        *resolver.Resolver
 	violationBuffer *violationbuf.Buffer
+	enforcerVersion string
 }
 
 func newAgentObserver(
 	logger *slog.Logger,
 	resolver *resolver.Resolver,
 	violationBuffer *violationbuf.Buffer,
+	enforcerVersion string,
 ) *agentObserver {
 	return &agentObserver{
 		logger:          logger.With("component", "agent_observer"),
 		resolver:        resolver,
 		violationBuffer: violationBuffer,
+		enforcerVersion: enforcerVersion,
 	}
 }
 
@@ -44,9 +50,13 @@ func (s *agentObserver) ListPoliciesStatus(
 	statuses := s.resolver.GetPolicyStatuses()
 	for policyName, ps := range statuses {
 		out.Policies[policyName] = &pb.PolicyStatus{
-			State:   ps.State,
-			Mode:    ps.Mode,
-			Message: ps.Message,
+			State:               ps.State,
+			Mode:                ps.Mode,
+			Message:             ps.Message,
+			ModeForced:          ps.ModeForced,
+			ObservedExecutables: observedExecutablesToProto(ps.ObservedExecutables),
+			EnforcerVersion:     s.enforcerVersion,
+			DryRunImpact:        dryRunImpactToProto(ps.DryRunImpact),
 		}
 	}
 
@@ -54,6 +64,37 @@ func (s *agentObserver) ListPoliciesStatus(
 	return out, nil
 }
 
+// observedExecutableSeparator separates the container name from the executable path in the observed executables.
+// Container names cannot contain it, so the entries are parsed unambiguously.
+const observedExecutableSeparator = ":"
+
+func observedExecutablesToProto(observed map[resolver.ContainerName][]string) []string {
+	var out []string
+	for _, containerName := range slices.Sorted(maps.Keys(observed)) {
+		for _, exe := range observed[containerName] {
+			out = append(out, containerName+observedExecutableSeparator+exe)
+		}
+	}
+	return out
+}
+
+func dryRunImpactToProto(impact map[resolver.ContainerName]resolver.DryRunImpact) []*pb.DryRunImpact {
+	var out []*pb.DryRunImpact
+	for _, containerName := range slices.Sorted(maps.Keys(impact)) {
+		out = append(out, &pb.DryRunImpact{
+			ContainerName: containerName,
+			Count:         int64(impact[containerName].Count),
+			Executables:   impact[containerName].Executables,
+		})
+	}
+	return out
+}
+
+// ParseObservedExecutable splits an observed executable reported by an agent into its container name and path.
+func ParseObservedExecutable(entry string) (string, string, bool) {
+	return strings.Cut(entry, observedExecutableSeparator)
+}
+
 func podViewToProto(podView *resolver.PodView) *pb.PodView {
 	view := &pb.PodView{
 		Meta: &pb.PodMeta{
@@ -23,6 +23,8 @@ type Config struct {
 	MTLSEnabled bool
 	CertDirPath string
 	Port        int
+	// EnforcerVersion is the version of the agent, reported in the status of the policies it applies.
+	EnforcerVersion string
 }
 
 type Server struct {
@@ -106,7 +108,10 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
 	grpcServer := grpc.NewServer(s.getConnCredentials())
-	pb.RegisterAgentObserverServer(grpcServer, newAgentObserver(s.logger, s.resolver, s.violationBuffer))
+	pb.RegisterAgentObserverServer(
+		grpcServer,
+		newAgentObserver(s.logger, s.resolver, s.violationBuffer, s.conf.EnforcerVersion),
+	)
 	s.logger.InfoContext(ctx, "Starting gRPC exporter", "addr", addr, "mTLS", s.conf.MTLSEnabled)
 
 	serveErrCh := make(chan error, 1)
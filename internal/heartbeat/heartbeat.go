@@ -0,0 +1,72 @@
+// Package heartbeat periodically emits an event per node through the event export pipeline, so that the central
+// monitoring can detect the nodes whose agent went silent without scraping every node.
+package heartbeat
+
+import (
+	"context"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+// Info is the static information of the node attached to every heartbeat.
+type Info struct {
+	NodeName        string
+	EnforcerVersion string
+	CgroupVersion   string
+}
+
+// Emitter emits the heartbeat events.
+type Emitter struct {
+	logger      otellog.Logger
+	info        Info
+	policyCount func() int
+	startTime   time.Time
+}
+
+// NewEmitter returns an emitter of heartbeats into logger. policyCount returns the number of active policies,
+// the uptime is computed from the creation of the emitter.
+func NewEmitter(logger otellog.Logger, info Info, policyCount func() int) *Emitter {
+	return &Emitter{
+		logger:      logger,
+		info:        info,
+		policyCount: policyCount,
+		startTime:   time.Now(),
+	}
+}
+
+// newRecord returns the heartbeat event at now.
+func (e *Emitter) newRecord(now time.Time) otellog.Record {
+	var rec otellog.Record
+	rec.SetEventName("heartbeat")
+	rec.SetSeverity(otellog.SeverityInfo)
+	rec.SetBody(otellog.StringValue("heartbeat"))
+	rec.SetTimestamp(now)
+	rec.AddAttributes(
+		otellog.String("node.name", e.info.NodeName),
+		otellog.String("enforcer.version", e.info.EnforcerVersion),
+		otellog.String("node.cgroup.version", e.info.CgroupVersion),
+		otellog.Int("enforcer.active_policies", e.policyCount()),
+		otellog.Int64("enforcer.uptime_seconds", int64(now.Sub(e.startTime).Seconds())),
+	)
+	return rec
+}
+
+// Emit emits a heartbeat.
+func (e *Emitter) Emit(ctx context.Context) {
+	e.logger.Emit(ctx, e.newRecord(time.Now()))
+}
+
+// Run emits a heartbeat every interval until ctx is done.
+func (e *Emitter) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			e.Emit(ctx)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package heartbeat_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/heartbeat"
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/noop"
+)
+
+// recordingLogger sends the emitted records to a channel.
+type recordingLogger struct {
+	noop.Logger
+
+	records chan otellog.Record
+}
+
+func (l *recordingLogger) Emit(_ context.Context, rec otellog.Record) {
+	l.records <- rec.Clone()
+}
+
+func TestRun(t *testing.T) {
+	logger := &recordingLogger{records: make(chan otellog.Record, 10)}
+	emitter := heartbeat.NewEmitter(logger, heartbeat.Info{
+		NodeName:        "node-1",
+		EnforcerVersion: "v1.2.3",
+		CgroupVersion:   "cgroupv2",
+	}, func() int { return 3 })
+
+	ctx, cancel := context.WithCancel(t.Context())
+	start := time.Now()
+	done := make(chan error)
+	go func() {
+		done <- emitter.Run(ctx, 10*time.Millisecond)
+	}()
+
+	var first, second otellog.Record
+	for _, rec := range []*otellog.Record{&first, &second} {
+		select {
+		case *rec = <-logger.records:
+		case <-time.After(time.Second):
+			require.FailNow(t, "no heartbeat emitted")
+		}
+	}
+	cancel()
+	require.NoError(t, <-done)
+
+	require.Equal(t, "heartbeat", first.EventName())
+	attrs := map[string]otellog.Value{}
+	first.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	require.Equal(t, "node-1", attrs["node.name"].AsString())
+	require.Equal(t, "v1.2.3", attrs["enforcer.version"].AsString())
+	require.Equal(t, "cgroupv2", attrs["node.cgroup.version"].AsString())
+	require.Equal(t, int64(3), attrs["enforcer.active_policies"].AsInt64())
+	require.Contains(t, attrs, "enforcer.uptime_seconds")
+
+	// the heartbeats are emitted at the configured interval.
+	require.GreaterOrEqual(t, first.Timestamp().Sub(start), 10*time.Millisecond)
+	require.GreaterOrEqual(t, second.Timestamp().Sub(start), 20*time.Millisecond)
+}
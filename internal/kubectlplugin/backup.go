@@ -0,0 +1,97 @@
+package kubectlplugin
+
+import (
+	"fmt"
+
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// backupFormatVersion is the version of the backup bundle format.
+// It must be increased on every change that older plugins can't import.
+const backupFormatVersion = 1
+
+// backupBundle is the portable document written by "backup export" and read by "backup import".
+// Objects only keep the fields that can be recreated in another cluster: server-populated metadata
+// (uid, resourceVersion, generation, managedFields, ...) is dropped.
+type backupBundle struct {
+	FormatVersion           int                                       `json:"formatVersion"`
+	ExportedAt              metav1.Time                               `json:"exportedAt"`
+	WorkloadPolicies        []securityv1alpha1.WorkloadPolicy         `json:"workloadPolicies"`
+	WorkloadPolicyProposals []securityv1alpha1.WorkloadPolicyProposal `json:"workloadPolicyProposals"`
+}
+
+func newBackupCmd(deps commonCmdDeps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Export and import the WorkloadPolicy and WorkloadPolicyProposal objects",
+	}
+
+	cmd.SetUsageTemplate(groupUsageTemplate)
+
+	cmd.AddCommand(newBackupExportCmd(deps))
+	cmd.AddCommand(newBackupImportCmd(deps))
+
+	return cmd
+}
+
+// portableObjectMeta returns the metadata of obj that can be recreated in another cluster.
+// Finalizers are dropped too: they are added again by the controllers.
+func portableObjectMeta(obj metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        obj.Name,
+		Namespace:   obj.Namespace,
+		Labels:      obj.Labels,
+		Annotations: obj.Annotations,
+	}
+}
+
+func portableWorkloadPolicy(policy *securityv1alpha1.WorkloadPolicy) securityv1alpha1.WorkloadPolicy {
+	return securityv1alpha1.WorkloadPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: securityv1alpha1.GroupVersion.String(), Kind: "WorkloadPolicy"},
+		ObjectMeta: portableObjectMeta(policy.ObjectMeta),
+		Spec:       policy.Spec,
+		Status:     policy.Status,
+	}
+}
+
+func portableWorkloadPolicyProposal(
+	proposal *securityv1alpha1.WorkloadPolicyProposal,
+) securityv1alpha1.WorkloadPolicyProposal {
+	portable := securityv1alpha1.WorkloadPolicyProposal{
+		TypeMeta:   metav1.TypeMeta{APIVersion: securityv1alpha1.GroupVersion.String(), Kind: "WorkloadPolicyProposal"},
+		ObjectMeta: portableObjectMeta(proposal.ObjectMeta),
+		Spec:       proposal.Spec,
+		Status:     proposal.Status,
+	}
+	// Only the kind and the name of the workload are kept: the proposal webhook resolves
+	// the owner again in the target cluster, where its UID is different.
+	for _, ref := range proposal.OwnerReferences {
+		portable.OwnerReferences = append(portable.OwnerReferences, metav1.OwnerReference{
+			Kind: ref.Kind,
+			Name: ref.Name,
+		})
+	}
+	return portable
+}
+
+func validateBackupBundle(bundle *backupBundle) error {
+	if bundle.FormatVersion != backupFormatVersion {
+		return fmt.Errorf("unsupported backup format version %d, expected %d",
+			bundle.FormatVersion, backupFormatVersion)
+	}
+	for _, policy := range bundle.WorkloadPolicies {
+		if policy.Name == "" || policy.Namespace == "" {
+			return fmt.Errorf("WorkloadPolicy %q in namespace %q: name and namespace are required",
+				policy.Name, policy.Namespace)
+		}
+	}
+	for _, proposal := range bundle.WorkloadPolicyProposals {
+		if proposal.Name == "" || proposal.Namespace == "" {
+			return fmt.Errorf("WorkloadPolicyProposal %q in namespace %q: name and namespace are required",
+				proposal.Name, proposal.Namespace)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package kubectlplugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	securityclient "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/typed/api/v1alpha1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+type backupExportOptions struct {
+	commonOptions
+
+	AllNamespaces bool
+}
+
+func newBackupExportCmd(deps commonCmdDeps) *cobra.Command {
+	opts := &backupExportOptions{
+		commonOptions: newCommonOptions(deps),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the WorkloadPolicy and WorkloadPolicyProposal objects into a backup bundle",
+		Long: "Export the WorkloadPolicy and WorkloadPolicyProposal objects, with their status, " +
+			"into a single YAML backup bundle printed on stdout. The bundle can be restored with 'backup import'.",
+		Args: cobra.NoArgs,
+		RunE: runBackupExportCmd(opts),
+	}
+
+	cmd.SetUsageTemplate(subcommandUsageTemplate)
+
+	// Plugin-specific flags
+	cmd.Flags().BoolVarP(&opts.AllNamespaces, "all-namespaces", "A", false,
+		"Export the objects of all namespaces instead of the current one")
+
+	return cmd
+}
+
+func runBackupExportCmd(opts *backupExportOptions) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		return withRuntimeEnforcerClient(cmd, &opts.commonOptions, func(
+			ctx context.Context,
+			client securityclient.SecurityV1alpha1Interface,
+		) error {
+			return runBackupExport(ctx, client, opts, opts.ioStreams.Out)
+		})
+	}
+}
+
+func runBackupExport(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	opts *backupExportOptions,
+	out io.Writer,
+) error {
+	bundle, err := buildBackupBundle(ctx, client, opts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to encode backup bundle: %w", err)
+	}
+	if _, err = out.Write(data); err != nil {
+		return fmt.Errorf("failed to write backup bundle: %w", err)
+	}
+	return nil
+}
+
+func buildBackupBundle(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	opts *backupExportOptions,
+	now time.Time,
+) (*backupBundle, error) {
+	namespace := opts.Namespace
+	if opts.AllNamespaces {
+		namespace = metav1.NamespaceAll
+	}
+
+	policies, err := client.WorkloadPolicies(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WorkloadPolicies: %w", err)
+	}
+	proposals, err := client.WorkloadPolicyProposals(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WorkloadPolicyProposals: %w", err)
+	}
+
+	bundle := &backupBundle{
+		FormatVersion: backupFormatVersion,
+		ExportedAt:    metav1.NewTime(now.UTC().Truncate(time.Second)),
+	}
+	for i := range policies.Items {
+		bundle.WorkloadPolicies = append(bundle.WorkloadPolicies, portableWorkloadPolicy(&policies.Items[i]))
+	}
+	for i := range proposals.Items {
+		bundle.WorkloadPolicyProposals = append(bundle.WorkloadPolicyProposals,
+			portableWorkloadPolicyProposal(&proposals.Items[i]))
+	}
+	return bundle, nil
+}
@@ -0,0 +1,266 @@
+package kubectlplugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	securityclient "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/typed/api/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// backupConflictSkip leaves the existing objects untouched.
+	backupConflictSkip = "skip"
+	// backupConflictOverwrite replaces the labels, annotations, spec and status of the existing objects.
+	backupConflictOverwrite = "overwrite"
+	// backupConflictFail aborts the import before any change if an object already exists.
+	backupConflictFail = "fail"
+)
+
+type backupImportOptions struct {
+	commonOptions
+
+	BundlePath string
+	OnConflict string
+}
+
+func newBackupImportCmd(deps commonCmdDeps) *cobra.Command {
+	opts := &backupImportOptions{
+		commonOptions: newCommonOptions(deps),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "import BUNDLE_FILE",
+		Short: "Recreate the WorkloadPolicy and WorkloadPolicyProposal objects of a backup bundle",
+		Long: "Recreate the WorkloadPolicy and WorkloadPolicyProposal objects of a backup bundle written by " +
+			"'backup export', in their original namespaces, and restore their status. " +
+			"Use '-' to read the bundle from stdin.",
+		Args: cobra.ExactArgs(1),
+		RunE: runBackupImportCmd(opts),
+	}
+
+	cmd.SetUsageTemplate(subcommandUsageTemplate)
+
+	// Plugin-specific flags
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would happen without making any changes")
+	cmd.Flags().StringVar(&opts.OnConflict, "on-conflict", backupConflictSkip,
+		"What to do with the objects that already exist. One of: skip|overwrite|fail")
+
+	return cmd
+}
+
+func runBackupImportCmd(opts *backupImportOptions) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		opts.BundlePath = args[0]
+
+		bundle, err := readBackupBundle(opts.BundlePath, opts.ioStreams.In)
+		if err != nil {
+			return err
+		}
+
+		return withRuntimeEnforcerClient(cmd, &opts.commonOptions, func(
+			ctx context.Context,
+			client securityclient.SecurityV1alpha1Interface,
+		) error {
+			return runBackupImport(ctx, client, bundle, opts, opts.ioStreams.Out)
+		})
+	}
+}
+
+func readBackupBundle(path string, stdin io.Reader) (*backupBundle, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup bundle: %w", err)
+	}
+
+	var bundle backupBundle
+	if err = yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode backup bundle: %w", err)
+	}
+	if err = validateBackupBundle(&bundle); err != nil {
+		return nil, fmt.Errorf("invalid backup bundle: %w", err)
+	}
+	return &bundle, nil
+}
+
+func runBackupImport(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	bundle *backupBundle,
+	opts *backupImportOptions,
+	out io.Writer,
+) error {
+	switch opts.OnConflict {
+	case backupConflictSkip, backupConflictOverwrite:
+	case backupConflictFail:
+		if err := checkBackupConflicts(ctx, client, bundle); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid conflict handling %q, must be one of: skip|overwrite|fail", opts.OnConflict)
+	}
+
+	// Policies are imported first: an approved proposal whose policy already exists
+	// is then cleaned up by the controller instead of being promoted again.
+	for i := range bundle.WorkloadPolicies {
+		result, err := importWorkloadPolicy(ctx, client, &bundle.WorkloadPolicies[i], opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "WorkloadPolicy %q in namespace %q %s.\n",
+			bundle.WorkloadPolicies[i].Name, bundle.WorkloadPolicies[i].Namespace, result)
+	}
+	for i := range bundle.WorkloadPolicyProposals {
+		result, err := importWorkloadPolicyProposal(ctx, client, &bundle.WorkloadPolicyProposals[i], opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "WorkloadPolicyProposal %q in namespace %q %s.\n",
+			bundle.WorkloadPolicyProposals[i].Name, bundle.WorkloadPolicyProposals[i].Namespace, result)
+	}
+
+	if opts.DryRun {
+		fmt.Fprintln(out, "Rerun without '--dry-run' to apply the changes.")
+	}
+	return nil
+}
+
+// checkBackupConflicts returns an error listing the objects of bundle that already exist.
+func checkBackupConflicts(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	bundle *backupBundle,
+) error {
+	var conflicts []string
+	for _, policy := range bundle.WorkloadPolicies {
+		_, err := client.WorkloadPolicies(policy.Namespace).Get(ctx, policy.Name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			conflicts = append(conflicts, fmt.Sprintf("WorkloadPolicy %s/%s", policy.Namespace, policy.Name))
+		case !apierrors.IsNotFound(err):
+			return fmt.Errorf("failed to get WorkloadPolicy %q in namespace %q: %w",
+				policy.Name, policy.Namespace, err)
+		}
+	}
+	for _, proposal := range bundle.WorkloadPolicyProposals {
+		_, err := client.WorkloadPolicyProposals(proposal.Namespace).Get(ctx, proposal.Name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			conflicts = append(conflicts,
+				fmt.Sprintf("WorkloadPolicyProposal %s/%s", proposal.Namespace, proposal.Name))
+		case !apierrors.IsNotFound(err):
+			return fmt.Errorf("failed to get WorkloadPolicyProposal %q in namespace %q: %w",
+				proposal.Name, proposal.Namespace, err)
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("nothing imported, the following objects already exist: %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+func importWorkloadPolicy(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	saved *securityv1alpha1.WorkloadPolicy,
+	opts *backupImportOptions,
+) (string, error) {
+	policies := client.WorkloadPolicies(saved.Namespace)
+	createOptions := metav1.CreateOptions{}
+	updateOptions := metav1.UpdateOptions{}
+	if opts.DryRun {
+		createOptions.DryRun = []string{metav1.DryRunAll}
+		updateOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	action := "created"
+	policy, err := policies.Create(ctx, saved.DeepCopy(), createOptions)
+	if apierrors.IsAlreadyExists(err) {
+		if opts.OnConflict != backupConflictOverwrite {
+			return "skipped, it already exists", nil
+		}
+		if policy, err = policies.Get(ctx, saved.Name, metav1.GetOptions{}); err != nil {
+			return "", fmt.Errorf("failed to get WorkloadPolicy %q in namespace %q: %w",
+				saved.Name, saved.Namespace, err)
+		}
+		policy.Labels = saved.Labels
+		policy.Annotations = saved.Annotations
+		policy.Spec = *saved.Spec.DeepCopy()
+		action = "overwritten"
+		policy, err = policies.Update(ctx, policy, updateOptions)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to import WorkloadPolicy %q in namespace %q: %w",
+			saved.Name, saved.Namespace, err)
+	}
+	if opts.DryRun {
+		return "would be " + action, nil
+	}
+
+	policy.Status = *saved.Status.DeepCopy()
+	if _, err = policies.UpdateStatus(ctx, policy, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to restore the status of WorkloadPolicy %q in namespace %q: %w",
+			saved.Name, saved.Namespace, err)
+	}
+	return action, nil
+}
+
+func importWorkloadPolicyProposal(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	saved *securityv1alpha1.WorkloadPolicyProposal,
+	opts *backupImportOptions,
+) (string, error) {
+	proposals := client.WorkloadPolicyProposals(saved.Namespace)
+	createOptions := metav1.CreateOptions{}
+	updateOptions := metav1.UpdateOptions{}
+	if opts.DryRun {
+		createOptions.DryRun = []string{metav1.DryRunAll}
+		updateOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	action := "created"
+	proposal, err := proposals.Create(ctx, saved.DeepCopy(), createOptions)
+	if apierrors.IsAlreadyExists(err) {
+		if opts.OnConflict != backupConflictOverwrite {
+			return "skipped, it already exists", nil
+		}
+		if proposal, err = proposals.Get(ctx, saved.Name, metav1.GetOptions{}); err != nil {
+			return "", fmt.Errorf("failed to get WorkloadPolicyProposal %q in namespace %q: %w",
+				saved.Name, saved.Namespace, err)
+		}
+		// The owner of the existing proposal is kept, it is already resolved in this cluster.
+		proposal.Labels = saved.Labels
+		proposal.Annotations = saved.Annotations
+		proposal.Spec = *saved.Spec.DeepCopy()
+		action = "overwritten"
+		proposal, err = proposals.Update(ctx, proposal, updateOptions)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to import WorkloadPolicyProposal %q in namespace %q: %w",
+			saved.Name, saved.Namespace, err)
+	}
+	if opts.DryRun {
+		return "would be " + action, nil
+	}
+
+	proposal.Status = *saved.Status.DeepCopy()
+	if _, err = proposals.UpdateStatus(ctx, proposal, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to restore the status of WorkloadPolicyProposal %q in namespace %q: %w",
+			saved.Name, saved.Namespace, err)
+	}
+	return action, nil
+}
@@ -0,0 +1,173 @@
+package kubectlplugin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	fakeclient "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newBackupTestObjects() (*securityv1alpha1.WorkloadPolicy, *securityv1alpha1.WorkloadPolicyProposal) {
+	policy := &securityv1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "deploy-web",
+			Namespace:       "apps",
+			UID:             "policy-uid",
+			ResourceVersion: "42",
+			Generation:      3,
+			Labels:          map[string]string{securityv1alpha1.PromotedFromLabelKey: "deploy-web"},
+			Finalizers:      []string{"security.rancher.io/finalizer"},
+		},
+		Spec: securityv1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*securityv1alpha1.WorkloadPolicyRules{
+				"web": {Executables: securityv1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/usr/sbin/nginx"}}},
+			},
+		},
+		Status: securityv1alpha1.WorkloadPolicyStatus{
+			Phase:          securityv1alpha1.Ready,
+			TotalNodes:     2,
+			ViolationCount: 1,
+			Violations: []securityv1alpha1.ViolationRecord{
+				{PodName: "web-1", ContainerName: "web", ExecutablePath: "/bin/sh", Action: "protect"},
+			},
+		},
+	}
+	proposal := &securityv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deploy-api",
+			Namespace: "apps",
+			UID:       "proposal-uid",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", UID: "deployment-uid"},
+			},
+		},
+		Spec: securityv1alpha1.WorkloadPolicyProposalSpec{
+			RulesByContainer: map[string]*securityv1alpha1.WorkloadPolicyRules{
+				"api": {Executables: securityv1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/app/api"}}},
+			},
+		},
+		Status: securityv1alpha1.WorkloadPolicyProposalStatus{
+			DiffByContainer: map[string]securityv1alpha1.ContainerRulesDiff{"api": {Added: []string{"/app/api"}}},
+		},
+	}
+	return policy, proposal
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	policy, proposal := newBackupTestObjects()
+	source := fakeclient.NewClientset(policy, proposal).SecurityV1alpha1()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultOperationTimeout)
+	defer cancel()
+
+	var exported bytes.Buffer
+	exportOpts := &backupExportOptions{commonOptions: commonOptions{Namespace: "default"}, AllNamespaces: true}
+	require.NoError(t, runBackupExport(ctx, source, exportOpts, &exported))
+	// the owner references keep an empty uid, which is not omitted when they are serialized.
+	for _, uid := range []string{"policy-uid", "proposal-uid", "deployment-uid"} {
+		require.NotContains(t, exported.String(), uid)
+	}
+	require.NotContains(t, exported.String(), "resourceVersion")
+
+	bundle, err := readBackupBundle("-", &exported)
+	require.NoError(t, err)
+
+	target := fakeclient.NewClientset().SecurityV1alpha1()
+	var out bytes.Buffer
+	importOpts := &backupImportOptions{OnConflict: backupConflictSkip}
+	require.NoError(t, runBackupImport(ctx, target, bundle, importOpts, &out))
+	require.Equal(t, `WorkloadPolicy "deploy-web" in namespace "apps" created.
+WorkloadPolicyProposal "deploy-api" in namespace "apps" created.
+`, out.String())
+
+	imported, err := target.WorkloadPolicies("apps").Get(ctx, policy.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, policy.Labels, imported.Labels)
+	require.Empty(t, imported.Finalizers)
+	require.Equal(t, policy.Spec, imported.Spec)
+	require.Equal(t, policy.Status, imported.Status)
+
+	importedProposal, err := target.WorkloadPolicyProposals("apps").Get(ctx, proposal.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, proposal.Spec, importedProposal.Spec)
+	require.Equal(t, proposal.Status, importedProposal.Status)
+	// the owner is resolved again by the proposal webhook of the target cluster.
+	require.Equal(t, []metav1.OwnerReference{{Kind: "Deployment", Name: "api"}}, importedProposal.OwnerReferences)
+}
+
+func TestBackupImportConflicts(t *testing.T) {
+	t.Parallel()
+
+	policy, proposal := newBackupTestObjects()
+	bundle := &backupBundle{
+		FormatVersion:           backupFormatVersion,
+		WorkloadPolicies:        []securityv1alpha1.WorkloadPolicy{portableWorkloadPolicy(policy)},
+		WorkloadPolicyProposals: []securityv1alpha1.WorkloadPolicyProposal{portableWorkloadPolicyProposal(proposal)},
+	}
+	existing := &securityv1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: policy.Name, Namespace: policy.Namespace},
+		Spec:       securityv1alpha1.WorkloadPolicySpec{Mode: "monitor"},
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		t.Parallel()
+
+		client := fakeclient.NewClientset(existing.DeepCopy()).SecurityV1alpha1()
+		var out bytes.Buffer
+		require.NoError(t, runBackupImport(t.Context(), client, bundle,
+			&backupImportOptions{OnConflict: backupConflictSkip}, &out))
+		require.Contains(t, out.String(), `WorkloadPolicy "deploy-web" in namespace "apps" skipped, it already exists.`)
+
+		got, err := client.WorkloadPolicies("apps").Get(t.Context(), policy.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, existing.Spec, got.Spec)
+		_, err = client.WorkloadPolicyProposals("apps").Get(t.Context(), proposal.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		t.Parallel()
+
+		client := fakeclient.NewClientset(existing.DeepCopy()).SecurityV1alpha1()
+		var out bytes.Buffer
+		require.NoError(t, runBackupImport(t.Context(), client, bundle,
+			&backupImportOptions{OnConflict: backupConflictOverwrite}, &out))
+		require.Contains(t, out.String(), `WorkloadPolicy "deploy-web" in namespace "apps" overwritten.`)
+
+		got, err := client.WorkloadPolicies("apps").Get(t.Context(), policy.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, policy.Spec, got.Spec)
+		require.Equal(t, policy.Status, got.Status)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		t.Parallel()
+
+		client := fakeclient.NewClientset(existing.DeepCopy()).SecurityV1alpha1()
+		var out bytes.Buffer
+		err := runBackupImport(t.Context(), client, bundle, &backupImportOptions{OnConflict: backupConflictFail}, &out)
+		require.ErrorContains(t, err,
+			"nothing imported, the following objects already exist: WorkloadPolicy apps/deploy-web")
+
+		// nothing is imported, not even the objects without conflicts.
+		_, err = client.WorkloadPolicyProposals("apps").Get(t.Context(), proposal.Name, metav1.GetOptions{})
+		require.Error(t, err)
+	})
+}
+
+func TestReadBackupBundle(t *testing.T) {
+	t.Parallel()
+
+	_, err := readBackupBundle("-", bytes.NewBufferString("formatVersion: 2\n"))
+	require.ErrorContains(t, err, "unsupported backup format version 2")
+
+	_, err = readBackupBundle("-",
+		bytes.NewBufferString("formatVersion: 1\nworkloadPolicies:\n- metadata:\n    name: a\n"))
+	require.ErrorContains(t, err, "name and namespace are required")
+}
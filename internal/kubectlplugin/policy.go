@@ -14,9 +14,11 @@ func newPolicyCmd(deps commonCmdDeps) *cobra.Command {
 
 	cmd.AddCommand(newPolicyModeProtectCmd(deps))
 	cmd.AddCommand(newPolicyModeMonitorCmd(deps))
+	cmd.AddCommand(newPolicyModeAuditCmd(deps))
 	cmd.AddCommand(newPolicyShowCmd(deps))
 	cmd.AddCommand(newPolicyExecAllowCmd(deps))
 	cmd.AddCommand(newPolicyExecDenyCmd(deps))
+	cmd.AddCommand(newPolicyImportAppArmorCmd(deps))
 
 	return cmd
 }
@@ -0,0 +1,103 @@
+package kubectlplugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/apparmor"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/printers"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+type policyImportAppArmorOptions struct {
+	commonOptions
+
+	ProfilePath   string
+	Profile       string
+	PolicyName    string
+	ContainerName string
+	Mode          string
+}
+
+func newPolicyImportAppArmorCmd(deps commonCmdDeps) *cobra.Command {
+	opts := &policyImportAppArmorOptions{
+		commonOptions: newCommonOptions(deps),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "import-apparmor PROFILE_FILE --name POLICY_NAME --container CONTAINER_NAME",
+		Short: "Generate a WorkloadPolicy from the exec rules of an AppArmor profile",
+		Long: "Generate a WorkloadPolicy from the exec rules of an AppArmor profile. " +
+			"The policy is printed as YAML, the rules that cannot be converted are reported on stderr.",
+		Args: cobra.ExactArgs(1),
+		RunE: runPolicyImportAppArmorCmd(opts),
+	}
+
+	cmd.SetUsageTemplate(subcommandUsageTemplate)
+
+	// Plugin-specific flags
+	cmd.Flags().StringVar(&opts.PolicyName, "name", "", "Name of the generated WorkloadPolicy")
+	cmd.Flags().StringVar(&opts.ContainerName, "container", "", "Name of the container the profile is applied to")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "",
+		"Name of the profile to convert, required if the file contains several profiles")
+	cmd.Flags().StringVar(&opts.Mode, "mode", policymode.MonitorString,
+		"Mode of the generated WorkloadPolicy. One of: monitor|protect")
+	cmdutil.CheckErr(cmd.MarkFlagRequired("name"))
+	cmdutil.CheckErr(cmd.MarkFlagRequired("container"))
+
+	return cmd
+}
+
+func runPolicyImportAppArmorCmd(opts *policyImportAppArmorOptions) func(cmd *cobra.Command, args []string) error {
+	return func(_ *cobra.Command, args []string) error {
+		opts.ProfilePath = args[0]
+
+		namespace, _, err := opts.Factory.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return fmt.Errorf("failed to determine namespace: %w", err)
+		}
+		opts.Namespace = namespace
+
+		profile, err := os.Open(opts.ProfilePath)
+		if err != nil {
+			return fmt.Errorf("failed to open AppArmor profile: %w", err)
+		}
+		defer profile.Close()
+
+		return runPolicyImportAppArmor(profile, opts, opts.ioStreams.Out, opts.ioStreams.ErrOut)
+	}
+}
+
+func runPolicyImportAppArmor(
+	profile io.Reader,
+	opts *policyImportAppArmorOptions,
+	out io.Writer,
+	errOut io.Writer,
+) error {
+	if opts.Mode != policymode.MonitorString && opts.Mode != policymode.ProtectString {
+		return fmt.Errorf("invalid mode %q, must be one of: monitor|protect", opts.Mode)
+	}
+
+	res, err := apparmor.Convert(profile, apparmor.Options{
+		Profile:       opts.Profile,
+		Name:          opts.PolicyName,
+		Namespace:     opts.Namespace,
+		ContainerName: opts.ContainerName,
+		Mode:          opts.Mode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to convert AppArmor profile %q: %w", opts.ProfilePath, err)
+	}
+
+	for _, skipped := range res.Skipped {
+		fmt.Fprintf(errOut, "Skipped rule at line %d %q: %s\n", skipped.Line, skipped.Rule, skipped.Reason)
+	}
+
+	if err = (&printers.YAMLPrinter{}).PrintObj(res.Policy, out); err != nil {
+		return fmt.Errorf("failed to print WorkloadPolicy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package kubectlplugin
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPolicyImportAppArmor(t *testing.T) {
+	t.Parallel()
+
+	const profile = `
+profile app /usr/bin/app {
+  /usr/bin/{cat,ls} ix,
+  /usr/local/bin/* ix,
+  /usr/lib/*/bin/tool ix,
+}
+`
+	opts := &policyImportAppArmorOptions{
+		commonOptions: commonOptions{Namespace: "test"},
+		ProfilePath:   "app.profile",
+		PolicyName:    "app",
+		ContainerName: "main",
+		Mode:          "protect",
+	}
+
+	var out, errOut bytes.Buffer
+	require.NoError(t, runPolicyImportAppArmor(strings.NewReader(profile), opts, &out, &errOut))
+	output := out.String()
+	require.Contains(t, output, "kind: WorkloadPolicy")
+	require.Contains(t, output, "  name: app\n  namespace: test\n")
+	require.Contains(t, output, "  mode: protect\n")
+	require.Contains(t, output, `    main:
+      executables:
+        allowed:
+        - /usr/bin/app
+        - /usr/bin/cat
+        - /usr/bin/ls
+        - /usr/local/bin/*
+`)
+	require.Equal(t,
+		"Skipped rule at line 5 \"/usr/lib/*/bin/tool ix\": "+
+			"globs are only supported at the end of a directory, as in /dir/*\n",
+		errOut.String())
+
+	opts.Mode = "learn"
+	require.ErrorContains(t, runPolicyImportAppArmor(strings.NewReader(profile), opts, &out, &errOut), "invalid mode")
+}
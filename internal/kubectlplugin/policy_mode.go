@@ -68,6 +68,9 @@ func newPolicyModeProtectCmd(deps commonCmdDeps) *cobra.Command {
 func newPolicyModeMonitorCmd(deps commonCmdDeps) *cobra.Command {
 	return newPolicyModeCmd(deps, policymode.MonitorString)
 }
+func newPolicyModeAuditCmd(deps commonCmdDeps) *cobra.Command {
+	return newPolicyModeCmd(deps, policymode.AuditString)
+}
 
 func runPolicyModeSetCmd(opts *policyModeOptions) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
@@ -193,6 +193,8 @@ func modeToUpper(mode string) string {
 		return "Monitor"
 	case policymode.ProtectString:
 		return "Protect"
+	case policymode.AuditString:
+		return "Audit"
 	default:
 		panic(fmt.Sprintf("unknown mode %q", mode))
 	}
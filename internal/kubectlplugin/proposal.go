@@ -13,6 +13,7 @@ func newProposalCmd(deps commonCmdDeps) *cobra.Command {
 	cmd.SetUsageTemplate(groupUsageTemplate)
 
 	cmd.AddCommand(newProposalPromoteCmd(deps))
+	cmd.AddCommand(newProposalReplayCmd(deps))
 
 	return cmd
 }
@@ -2,6 +2,7 @@ package kubectlplugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -18,6 +19,7 @@ type proposalPromoteOptions struct {
 	commonOptions
 
 	ProposalName string
+	Selector     string
 }
 
 func newProposalPromoteCmdValidArgsFunction(
@@ -43,10 +45,12 @@ func newProposalPromoteCmd(deps commonCmdDeps) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:               "promote PROPOSAL_NAME",
-		Short:             "Promote WorkloadPolicyProposal to WorkloadPolicy",
-		Long:              "Promote WorkloadPolicyProposal to WorkloadPolicy. This will trigger the creation of a WorkloadPolicy.",
-		Args:              cobra.ExactArgs(1),
+		Use:   "promote (PROPOSAL_NAME | -l SELECTOR)",
+		Short: "Promote WorkloadPolicyProposal to WorkloadPolicy",
+		Long: "Promote WorkloadPolicyProposal to WorkloadPolicy. This will trigger the creation of a WorkloadPolicy.\n" +
+			"With --selector, the WorkloadPolicyProposals of the namespace matching the label selector are promoted.\n" +
+			"The controller records the authenticated user as the approver on each WorkloadPolicy.",
+		Args:              cobra.MaximumNArgs(1),
 		ValidArgsFunction: newProposalPromoteCmdValidArgsFunction(deps),
 		RunE:              runProposalPromoteCmd(opts),
 	}
@@ -55,13 +59,27 @@ func newProposalPromoteCmd(deps commonCmdDeps) *cobra.Command {
 
 	// Plugin-specific flags
 	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would happen without making any changes")
+	cmd.Flags().StringVarP(
+		&opts.Selector,
+		"selector",
+		"l",
+		"",
+		"Promote all the WorkloadPolicyProposals matching the label selector (e.g. team=payments)",
+	)
 
 	return cmd
 }
 
 func runProposalPromoteCmd(opts *proposalPromoteOptions) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		opts.ProposalName = args[0]
+		switch {
+		case len(args) == 1 && opts.Selector != "":
+			return errors.New("a proposal name and --selector cannot be used together")
+		case len(args) == 0 && opts.Selector == "":
+			return errors.New("either a proposal name or --selector is required")
+		case len(args) == 1:
+			opts.ProposalName = args[0]
+		}
 
 		return withRuntimeEnforcerClient(cmd, &opts.commonOptions, func(
 			ctx context.Context,
@@ -78,19 +96,98 @@ func runProposalPromote(
 	opts *proposalPromoteOptions,
 	out io.Writer,
 ) error {
+	proposals, err := getProposalsToPromote(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+
+	if len(proposals) == 0 {
+		fmt.Fprintf(
+			out,
+			"No WorkloadPolicyProposal matches selector %q in namespace %q.\n",
+			opts.Selector,
+			opts.Namespace,
+		)
+		return nil
+	}
+
+	var promoted []string
+	for i := range proposals {
+		var approved bool
+		approved, err = approveProposal(ctx, client, opts, &proposals[i], out)
+		if err != nil {
+			return err
+		}
+		if approved {
+			promoted = append(promoted, proposals[i].Name)
+		}
+	}
+
+	if opts.DryRun {
+		// We need to return here because we cannot wait for the resource to be created in --dry-run mode
+		return nil
+	}
+
+	for _, name := range promoted {
+		policy, waitErr := waitForWorkloadPolicy(ctx, client, opts.Namespace, name)
+		if waitErr != nil {
+			return fmt.Errorf("policy promotion did not complete successfully: %w", waitErr)
+		}
+
+		fmt.Fprintf(out, "WorkloadPolicy %q in namespace %q has been created.\n", policy.Name, policy.Namespace)
+	}
+
+	return nil
+}
+
+// getProposalsToPromote returns the proposal named in the options, or the proposals matching the selector.
+func getProposalsToPromote(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	opts *proposalPromoteOptions,
+) ([]apiv1alpha1.WorkloadPolicyProposal, error) {
+	if opts.Selector != "" {
+		list, err := client.WorkloadPolicyProposals(opts.Namespace).
+			List(ctx, metav1.ListOptions{LabelSelector: opts.Selector})
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to list WorkloadPolicyProposals matching selector %q in namespace %q: %w",
+				opts.Selector,
+				opts.Namespace,
+				err,
+			)
+		}
+		return list.Items, nil
+	}
+
 	proposal, err := client.WorkloadPolicyProposals(opts.Namespace).Get(ctx, opts.ProposalName, metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			return fmt.Errorf("workloadpolicyproposal %q not found in namespace %q", opts.ProposalName, opts.Namespace)
+			return nil, fmt.Errorf(
+				"workloadpolicyproposal %q not found in namespace %q",
+				opts.ProposalName,
+				opts.Namespace,
+			)
 		}
-		return fmt.Errorf(
+		return nil, fmt.Errorf(
 			"failed to get WorkloadPolicyProposal %q in namespace %q: %w",
 			opts.ProposalName,
 			opts.Namespace,
 			err,
 		)
 	}
+	return []apiv1alpha1.WorkloadPolicyProposal{*proposal}, nil
+}
 
+// approveProposal sets the approval label on the proposal, so that the controller promotes it.
+// It returns false when the proposal is already approved.
+func approveProposal(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	opts *proposalPromoteOptions,
+	proposal *apiv1alpha1.WorkloadPolicyProposal,
+	out io.Writer,
+) (bool, error) {
 	labels := proposal.GetLabels()
 	if labels == nil {
 		labels = map[string]string{}
@@ -103,7 +200,7 @@ func runProposalPromote(
 			proposal.Name,
 			proposal.Namespace,
 		)
-		return nil
+		return false, nil
 	}
 
 	updateOptions := metav1.UpdateOptions{}
@@ -114,16 +211,16 @@ func runProposalPromote(
 	labels[apiv1alpha1.ApprovalLabelKey] = "true"
 	proposal.SetLabels(labels)
 
-	if _, err = client.WorkloadPolicyProposals(opts.Namespace).
+	if _, err := client.WorkloadPolicyProposals(proposal.Namespace).
 		Update(ctx, proposal, updateOptions); err != nil {
 		if apierrors.IsConflict(err) {
-			return fmt.Errorf(
+			return false, fmt.Errorf(
 				"WorkloadPolicyProposal %q in namespace %q was modified concurrently",
 				proposal.Name,
 				proposal.Namespace,
 			)
 		}
-		return fmt.Errorf(
+		return false, fmt.Errorf(
 			"failed to update WorkloadPolicyProposal %q in namespace %q: %w",
 			proposal.Name,
 			proposal.Namespace,
@@ -138,8 +235,7 @@ func runProposalPromote(
 			proposal.Name,
 			proposal.Namespace,
 		)
-		// We need to return here because we cannot wait for the resource to be created in --dry-run mode
-		return nil
+		return true, nil
 	}
 
 	fmt.Fprintf(
@@ -149,14 +245,7 @@ func runProposalPromote(
 		proposal.Namespace,
 	)
 
-	policy, err := waitForWorkloadPolicy(ctx, client, opts.Namespace, opts.ProposalName)
-	if err != nil {
-		return fmt.Errorf("policy promotion did not complete successfully: %w", err)
-	}
-
-	fmt.Fprintf(out, "WorkloadPolicy %q in namespace %q has been created.\n", policy.Name, policy.Namespace)
-
-	return nil
+	return true, nil
 }
 
 func waitForWorkloadPolicy(
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestRunProposalPromote(t *testing.T) {
@@ -149,3 +150,80 @@ func TestCompleteProposalPromoteArgs(t *testing.T) {
 	assert.Equal(t, []string{proposalName}, completes)
 	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
 }
+
+func TestRunProposalPromoteSelector(t *testing.T) {
+	t.Parallel()
+
+	const ns = "test"
+
+	newProposal := func(name, team string) *securityv1alpha1.WorkloadPolicyProposal {
+		return &securityv1alpha1.WorkloadPolicyProposal{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+				Labels:    map[string]string{"team": team},
+			},
+		}
+	}
+	fakeClient := fakeclient.NewClientset(
+		newProposal("deploy-api", "payments"),
+		newProposal("deploy-worker", "payments"),
+		newProposal("deploy-frontend", "web"),
+	)
+	// The controller promotes the approved proposals: the fake client creates their policies instead.
+	fakeClient.PrependReactor(
+		"update",
+		"workloadpolicyproposals",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			proposal, ok := action.(k8stesting.UpdateAction).GetObject().(*securityv1alpha1.WorkloadPolicyProposal)
+			if !ok || proposal.Labels[securityv1alpha1.ApprovalLabelKey] != "true" {
+				return false, nil, nil
+			}
+			policy := &securityv1alpha1.WorkloadPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        proposal.Name,
+					Namespace:   proposal.Namespace,
+					Annotations: proposal.Annotations,
+				},
+			}
+			gvr := securityv1alpha1.SchemeGroupVersion.WithResource("workloadpolicies")
+			return false, nil, fakeClient.Tracker().Create(gvr, policy, proposal.Namespace)
+		},
+	)
+	securityClient := fakeClient.SecurityV1alpha1()
+
+	var out bytes.Buffer
+	opts := &proposalPromoteOptions{
+		commonOptions: commonOptions{Namespace: ns},
+		Selector:      "team=payments",
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultOperationTimeout)
+	defer cancel()
+
+	require.NoError(t, runProposalPromote(ctx, securityClient, opts, &out))
+
+	for _, name := range []string{"deploy-api", "deploy-worker"} {
+		proposal, err := securityClient.WorkloadPolicyProposals(ns).Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "true", proposal.Labels[securityv1alpha1.ApprovalLabelKey])
+		// the approval annotations are recorded by the controller webhook, not by the client.
+		require.NotContains(t, proposal.Annotations, securityv1alpha1.ApprovedByAnnotationKey)
+
+		_, err = securityClient.WorkloadPolicies(ns).Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Contains(t, out.String(), fmt.Sprintf("WorkloadPolicy %q in namespace %q has been created.", name, ns))
+	}
+
+	// the proposals not matching the selector are left untouched.
+	proposal, err := securityClient.WorkloadPolicyProposals(ns).Get(ctx, "deploy-frontend", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotContains(t, proposal.Labels, securityv1alpha1.ApprovalLabelKey)
+	_, err = securityClient.WorkloadPolicies(ns).Get(ctx, "deploy-frontend", metav1.GetOptions{})
+	require.Error(t, err)
+
+	// a selector matching no proposal is reported.
+	out.Reset()
+	opts.Selector = "team=unknown"
+	require.NoError(t, runProposalPromote(ctx, securityClient, opts, &out))
+	require.Contains(t, out.String(), "No WorkloadPolicyProposal matches selector")
+}
@@ -0,0 +1,451 @@
+package kubectlplugin
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/workloadkind"
+	securityclient "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/typed/api/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultReplayDuration = 2 * time.Minute
+	// defaultReplaySettleTime is above the default status update interval of the controller,
+	// so that the violations of the end of the replay are reported in the policy status.
+	defaultReplaySettleTime = 45 * time.Second
+	replayPolicySuffix      = "-replay"
+)
+
+// errReplayBlocked is returned when the replay blocked at least one execution,
+// so that the command exits with a non-zero code.
+var errReplayBlocked = errors.New("the proposal blocked executions during the replay")
+
+type proposalReplayOptions struct {
+	commonOptions
+
+	ProposalName string
+	Duration     time.Duration
+	SettleTime   time.Duration
+	Keep         bool
+}
+
+// replayBlock aggregates the executions of an executable blocked in a container of the sandbox pod.
+type replayBlock struct {
+	Container  string
+	Executable string
+	Count      int
+}
+
+// replayContainerFailure describes a container of the sandbox pod that exited with an error,
+// usually because an executable it needs was blocked.
+type replayContainerFailure struct {
+	Container string
+	ExitCode  int32
+	Reason    string
+}
+
+// replayReport is the outcome of the replay of a proposal in protect mode.
+type replayReport struct {
+	Proposal          string
+	Policy            string
+	Pod               string
+	Blocks            []replayBlock
+	FailedContainers  []replayContainerFailure
+	ViolationsDropped bool
+}
+
+// Passed reports whether no execution was blocked during the replay.
+func (r *replayReport) Passed() bool {
+	return len(r.Blocks) == 0
+}
+
+func newProposalReplayCmd(deps commonCmdDeps) *cobra.Command {
+	opts := &proposalReplayOptions{
+		commonOptions: newCommonOptions(deps),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "replay PROPOSAL_NAME",
+		Short: "Replay a WorkloadPolicyProposal in protect mode in a sandbox pod",
+		Long: "Replay a WorkloadPolicyProposal in protect mode in a sandbox pod. " +
+			"A temporary WorkloadPolicy is created from the proposal in protect mode and enforced on a short-lived pod " +
+			"created from the pod template of the workload. The executions blocked while the pod runs are reported, " +
+			"then the pod and the policy are deleted.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: newProposalPromoteCmdValidArgsFunction(deps),
+		RunE:              runProposalReplayCmd(opts),
+	}
+
+	cmd.SetUsageTemplate(subcommandUsageTemplate)
+
+	// Plugin-specific flags
+	cmd.Flags().DurationVar(&opts.Duration, "duration", defaultReplayDuration,
+		"How long the sandbox pod is exercised")
+	cmd.Flags().DurationVar(&opts.SettleTime, "settle-time", defaultReplaySettleTime,
+		"How long to wait after the exercise for the violations to be reported in the policy status")
+	cmd.Flags().BoolVar(&opts.Keep, "keep", false, "Keep the sandbox pod and the temporary WorkloadPolicy")
+
+	return cmd
+}
+
+func runProposalReplayCmd(opts *proposalReplayOptions) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		opts.ProposalName = args[0]
+
+		securityClient, err := buildSecurityClient(&opts.commonOptions)
+		if err != nil {
+			return err
+		}
+
+		config, err := opts.Factory.ToRESTConfig()
+		if err != nil {
+			return fmt.Errorf("failed to build Kubernetes configuration: %w", err)
+		}
+
+		kubeClient, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		}
+
+		// the replay lasts longer than a single operation: the setup, the exercise and the settle time.
+		ctx, cancel := context.WithTimeout(cmd.Context(), 2*defaultOperationTimeout+opts.Duration+opts.SettleTime)
+		defer cancel()
+
+		return runProposalReplay(ctx, securityClient, kubeClient, opts, opts.ioStreams.Out)
+	}
+}
+
+func runProposalReplay(
+	ctx context.Context,
+	securityClient securityclient.SecurityV1alpha1Interface,
+	kubeClient kubernetes.Interface,
+	opts *proposalReplayOptions,
+	out io.Writer,
+) error {
+	proposal, err := securityClient.WorkloadPolicyProposals(opts.Namespace).
+		Get(ctx, opts.ProposalName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("workloadpolicyproposal %q not found in namespace %q", opts.ProposalName, opts.Namespace)
+		}
+		return fmt.Errorf(
+			"failed to get WorkloadPolicyProposal %q in namespace %q: %w",
+			opts.ProposalName,
+			opts.Namespace,
+			err,
+		)
+	}
+
+	template, err := getWorkloadPodTemplate(ctx, kubeClient, proposal)
+	if err != nil {
+		return err
+	}
+
+	policy, err := securityClient.WorkloadPolicies(opts.Namespace).
+		Create(ctx, newReplayPolicy(proposal), metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create the replay WorkloadPolicy for proposal %q: %w", proposal.Name, err)
+	}
+	fmt.Fprintf(out, "Created WorkloadPolicy %q in protect mode from proposal %q.\n", policy.Name, proposal.Name)
+	if !opts.Keep {
+		defer cleanupReplayResource(out, "WorkloadPolicy", policy.Name, func(ctx context.Context) error {
+			return securityClient.WorkloadPolicies(opts.Namespace).Delete(ctx, policy.Name, metav1.DeleteOptions{})
+		})
+	}
+
+	// the pod must not start before the policy is enforced on every node, otherwise its first
+	// executions would not be checked.
+	if err = waitForWorkloadPolicyReady(ctx, securityClient, opts.Namespace, policy.Name); err != nil {
+		return err
+	}
+
+	pod, err := kubeClient.CoreV1().Pods(opts.Namespace).
+		Create(ctx, newReplayPod(policy.Name, template), metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create the sandbox pod: %w", err)
+	}
+	fmt.Fprintf(out, "Created sandbox pod %q, exercising it for %s.\n", pod.Name, opts.Duration)
+	if !opts.Keep {
+		// the policy can't be deleted while a pod uses it, the pod is deleted first and waited for.
+		defer cleanupReplayResource(out, "Pod", pod.Name, func(ctx context.Context) error {
+			return deletePodAndWait(ctx, kubeClient, opts.Namespace, pod.Name)
+		})
+	}
+
+	if err = sleepWithContext(ctx, opts.Duration+opts.SettleTime); err != nil {
+		return fmt.Errorf("replay interrupted: %w", err)
+	}
+
+	podName, policyName := pod.Name, policy.Name
+	pod, err = kubeClient.CoreV1().Pods(opts.Namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the sandbox pod %q: %w", podName, err)
+	}
+	policy, err = securityClient.WorkloadPolicies(opts.Namespace).Get(ctx, policyName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get WorkloadPolicy %q in namespace %q: %w", policyName, opts.Namespace, err)
+	}
+
+	report := buildReplayReport(proposal.Name, policy, pod)
+	printReplayReport(out, report)
+	if !report.Passed() {
+		return errReplayBlocked
+	}
+	return nil
+}
+
+// getWorkloadPodTemplate returns the pod template of the workload the proposal has been learned from.
+func getWorkloadPodTemplate(
+	ctx context.Context,
+	kubeClient kubernetes.Interface,
+	proposal *apiv1alpha1.WorkloadPolicyProposal,
+) (*corev1.PodTemplateSpec, error) {
+	if len(proposal.OwnerReferences) == 0 {
+		return nil, fmt.Errorf("proposal %q has no workload owner", proposal.Name)
+	}
+	owner := proposal.OwnerReferences[0]
+	namespace := proposal.Namespace
+	getOpts := metav1.GetOptions{}
+	wrapErr := func(err error) error {
+		return fmt.Errorf("failed to get %s %q in namespace %q: %w", owner.Kind, owner.Name, namespace, err)
+	}
+
+	switch workloadkind.Kind(owner.Kind) {
+	case workloadkind.Deployment:
+		deployment, err := kubeClient.AppsV1().Deployments(namespace).Get(ctx, owner.Name, getOpts)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		return &deployment.Spec.Template, nil
+	case workloadkind.StatefulSet:
+		statefulSet, err := kubeClient.AppsV1().StatefulSets(namespace).Get(ctx, owner.Name, getOpts)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		return &statefulSet.Spec.Template, nil
+	case workloadkind.DaemonSet:
+		daemonSet, err := kubeClient.AppsV1().DaemonSets(namespace).Get(ctx, owner.Name, getOpts)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		return &daemonSet.Spec.Template, nil
+	case workloadkind.ReplicaSet:
+		replicaSet, err := kubeClient.AppsV1().ReplicaSets(namespace).Get(ctx, owner.Name, getOpts)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		return &replicaSet.Spec.Template, nil
+	case workloadkind.Job:
+		job, err := kubeClient.BatchV1().Jobs(namespace).Get(ctx, owner.Name, getOpts)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		return &job.Spec.Template, nil
+	case workloadkind.CronJob:
+		cronJob, err := kubeClient.BatchV1().CronJobs(namespace).Get(ctx, owner.Name, getOpts)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		return &cronJob.Spec.JobTemplate.Spec.Template, nil
+	case workloadkind.Pod:
+		pod, err := kubeClient.CoreV1().Pods(namespace).Get(ctx, owner.Name, getOpts)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		return &corev1.PodTemplateSpec{ObjectMeta: pod.ObjectMeta, Spec: pod.Spec}, nil
+	case workloadkind.Unknown:
+		fallthrough
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %q for proposal %q", owner.Kind, proposal.Name)
+	}
+}
+
+// newReplayPolicy returns the temporary WorkloadPolicy enforcing the proposal in protect mode.
+func newReplayPolicy(proposal *apiv1alpha1.WorkloadPolicyProposal) *apiv1alpha1.WorkloadPolicy {
+	spec := proposal.Spec.DeepCopy().IntoWorkloadPolicySpec()
+	spec.Mode = policymode.ProtectString
+	return &apiv1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      proposal.Name + replayPolicySuffix,
+			Namespace: proposal.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// newReplayPod returns the sandbox pod created from the pod template of the workload.
+// The labels of the workload are dropped, so that its controller doesn't adopt the pod
+// and its services don't send traffic to it.
+func newReplayPod(policyName string, template *corev1.PodTemplateSpec) *corev1.Pod {
+	spec := template.Spec.DeepCopy()
+	// the pod is scheduled again and it runs once, a blocked execution must not hide behind restarts.
+	spec.NodeName = ""
+	spec.RestartPolicy = corev1.RestartPolicyNever
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: policyName + "-",
+			Labels: map[string]string{
+				apiv1alpha1.PolicyLabelKey: policyName,
+			},
+			Annotations: maps.Clone(template.Annotations),
+		},
+		Spec: *spec,
+	}
+}
+
+func waitForWorkloadPolicyReady(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	namespace, name string,
+) error {
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"stopped waiting for WorkloadPolicy %q in namespace %q to be ready: %w",
+				name,
+				namespace,
+				ctx.Err(),
+			)
+		case <-ticker.C:
+			policy, err := client.WorkloadPolicies(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to get WorkloadPolicy %q in namespace %q: %w", name, namespace, err)
+			}
+			if policy.Status.ObservedGeneration == policy.Generation && policy.Status.Phase == apiv1alpha1.Ready {
+				return nil
+			}
+		}
+	}
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// deletePodAndWait deletes the pod and waits until it is gone, it returns a NotFound error once it is.
+func deletePodAndWait(ctx context.Context, kubeClient kubernetes.Interface, namespace, name string) error {
+	gracePeriod := int64(0)
+	err := kubeClient.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for the deletion: %w", ctx.Err())
+		case <-ticker.C:
+			if _, err = kubeClient.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// cleanupReplayResource deletes a resource created by the replay, even if the replay context is done.
+func cleanupReplayResource(out io.Writer, kind, name string, deleteFunc func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultOperationTimeout)
+	defer cancel()
+
+	if err := deleteFunc(ctx); err != nil && !apierrors.IsNotFound(err) {
+		fmt.Fprintf(out, "Failed to delete %s %q: %v\n", kind, name, err)
+		return
+	}
+	fmt.Fprintf(out, "Deleted %s %q.\n", kind, name)
+}
+
+// buildReplayReport assembles the report of the replay from the violations blocked in the sandbox pod
+// and the state of its containers.
+func buildReplayReport(proposalName string, policy *apiv1alpha1.WorkloadPolicy, pod *corev1.Pod) *replayReport {
+	report := &replayReport{
+		Proposal: proposalName,
+		Policy:   policy.Name,
+		Pod:      pod.Name,
+		// the status only retains the most recent violations, some blocks may be missing from the report.
+		ViolationsDropped: policy.Status.ViolationCount > int64(len(policy.Status.Violations)),
+	}
+
+	blocks := make(map[replayBlock]int)
+	for _, violation := range policy.Status.Violations {
+		if violation.PodName != pod.Name || violation.Action != policymode.ProtectString {
+			continue
+		}
+		blocks[replayBlock{Container: violation.ContainerName, Executable: violation.ExecutablePath}]++
+	}
+	for block, count := range blocks {
+		block.Count = count
+		report.Blocks = append(report.Blocks, block)
+	}
+	slices.SortFunc(report.Blocks, func(a, b replayBlock) int {
+		return cmp.Or(cmp.Compare(a.Container, b.Container), cmp.Compare(a.Executable, b.Executable))
+	})
+
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		terminated := status.State.Terminated
+		if terminated == nil || terminated.ExitCode == 0 {
+			continue
+		}
+		report.FailedContainers = append(report.FailedContainers, replayContainerFailure{
+			Container: status.Name,
+			ExitCode:  terminated.ExitCode,
+			Reason:    terminated.Reason,
+		})
+	}
+	slices.SortFunc(report.FailedContainers, func(a, b replayContainerFailure) int {
+		return cmp.Compare(a.Container, b.Container)
+	})
+
+	return report
+}
+
+func printReplayReport(out io.Writer, report *replayReport) {
+	if report.Passed() {
+		fmt.Fprintf(out, "PASS: no execution of pod %q was blocked by proposal %q.\n", report.Pod, report.Proposal)
+	} else {
+		fmt.Fprintf(out, "FAIL: proposal %q blocked %d executable(s) of pod %q:\n",
+			report.Proposal, len(report.Blocks), report.Pod)
+		for _, block := range report.Blocks {
+			fmt.Fprintf(out, "  container %q: %s (blocked %d time(s))\n", block.Container, block.Executable, block.Count)
+		}
+	}
+
+	for _, failure := range report.FailedContainers {
+		fmt.Fprintf(out, "Container %q exited with code %d", failure.Container, failure.ExitCode)
+		if failure.Reason != "" {
+			fmt.Fprintf(out, " (%s)", failure.Reason)
+		}
+		fmt.Fprintln(out, ".")
+	}
+	if report.ViolationsDropped {
+		fmt.Fprintf(out, "Warning: WorkloadPolicy %q retained only its most recent violations, "+
+			"some blocked executables may be missing.\n", report.Policy)
+	}
+}
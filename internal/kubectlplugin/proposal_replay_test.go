@@ -0,0 +1,134 @@
+package kubectlplugin
+
+import (
+	"bytes"
+	"testing"
+
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildReplayReport(t *testing.T) {
+	t.Parallel()
+
+	const (
+		proposalName = "deploy-app"
+		policyName   = proposalName + replayPolicySuffix
+		podName      = policyName + "-abcde"
+	)
+
+	violation := func(pod, container, executable, action string) securityv1alpha1.ViolationRecord {
+		return securityv1alpha1.ViolationRecord{
+			PodName:        pod,
+			ContainerName:  container,
+			ExecutablePath: executable,
+			Action:         action,
+		}
+	}
+	newPolicy := func(violations ...securityv1alpha1.ViolationRecord) *securityv1alpha1.WorkloadPolicy {
+		return &securityv1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: policyName},
+			Status: securityv1alpha1.WorkloadPolicyStatus{
+				Violations:     violations,
+				ViolationCount: int64(len(violations)),
+			},
+		}
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName}}
+
+	t.Run("passes without blocks", func(t *testing.T) {
+		t.Parallel()
+
+		report := buildReplayReport(proposalName, newPolicy(
+			// violations of other pods, or not blocked, are not accounted.
+			violation("other-pod", "app", "/bin/sh", policymode.ProtectString),
+			violation(podName, "app", "/bin/sh", policymode.MonitorString),
+		), pod)
+		require.True(t, report.Passed())
+		require.Empty(t, report.Blocks)
+
+		var out bytes.Buffer
+		printReplayReport(&out, report)
+		require.Equal(t, "PASS: no execution of pod \"deploy-app-replay-abcde\" was blocked by proposal \"deploy-app\".\n",
+			out.String())
+	})
+
+	t.Run("fails with the blocks aggregated by container and executable", func(t *testing.T) {
+		t.Parallel()
+
+		failedPod := pod.DeepCopy()
+		failedPod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{
+				Name: "sidecar",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 0},
+				},
+			},
+			{
+				Name: "app",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 126, Reason: "Error"},
+				},
+			},
+		}
+		policy := newPolicy(
+			violation(podName, "sidecar", "/usr/bin/curl", policymode.ProtectString),
+			violation(podName, "app", "/bin/sh", policymode.ProtectString),
+			violation(podName, "app", "/bin/sh", policymode.ProtectString),
+			violation(podName, "app", "/bin/bash", policymode.ProtectString),
+		)
+		// older violations are no longer retained in the status.
+		policy.Status.ViolationCount = securityv1alpha1.MaxViolationRecords + 1
+
+		report := buildReplayReport(proposalName, policy, failedPod)
+		require.False(t, report.Passed())
+		require.Equal(t, []replayBlock{
+			{Container: "app", Executable: "/bin/bash", Count: 1},
+			{Container: "app", Executable: "/bin/sh", Count: 2},
+			{Container: "sidecar", Executable: "/usr/bin/curl", Count: 1},
+		}, report.Blocks)
+		require.Equal(t, []replayContainerFailure{
+			{Container: "app", ExitCode: 126, Reason: "Error"},
+		}, report.FailedContainers)
+		require.True(t, report.ViolationsDropped)
+
+		var out bytes.Buffer
+		printReplayReport(&out, report)
+		require.Equal(t, `FAIL: proposal "deploy-app" blocked 3 executable(s) of pod "deploy-app-replay-abcde":
+  container "app": /bin/bash (blocked 1 time(s))
+  container "app": /bin/sh (blocked 2 time(s))
+  container "sidecar": /usr/bin/curl (blocked 1 time(s))
+Container "app" exited with code 126 (Error).
+Warning: WorkloadPolicy "deploy-app-replay" retained only its most recent violations, some blocked executables may be missing.
+`, out.String())
+	})
+}
+
+func TestNewReplayPod(t *testing.T) {
+	t.Parallel()
+
+	template := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{"note": "kept"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      "node-1",
+			RestartPolicy: corev1.RestartPolicyAlways,
+			Containers:    []corev1.Container{{Name: "app", Image: "nginx"}},
+		},
+	}
+
+	pod := newReplayPod("deploy-web-replay", template)
+	require.Equal(t, "deploy-web-replay-", pod.GenerateName)
+	require.Equal(t, map[string]string{securityv1alpha1.PolicyLabelKey: "deploy-web-replay"}, pod.Labels)
+	require.Equal(t, template.Annotations, pod.Annotations)
+	require.Empty(t, pod.Spec.NodeName)
+	require.Equal(t, corev1.RestartPolicyNever, pod.Spec.RestartPolicy)
+	require.Equal(t, template.Spec.Containers, pod.Spec.Containers)
+	// the template is left untouched.
+	require.Equal(t, corev1.RestartPolicyAlways, template.Spec.RestartPolicy)
+}
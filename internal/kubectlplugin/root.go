@@ -70,6 +70,7 @@ func NewRootCmd() *cobra.Command {
 	deps := commonCmdDeps{f: f, ioStreams: streams}
 	cmd.AddCommand(newProposalCmd(deps))
 	cmd.AddCommand(newPolicyCmd(deps))
+	cmd.AddCommand(newBackupCmd(deps))
 
 	return cmd
 }
@@ -0,0 +1,90 @@
+// Package metricslog periodically logs a snapshot of the key counters of the agent, for the clusters where
+// the metrics are not scraped. The counters are read from the metrics registry, so the snapshot doesn't depend
+// on the metrics endpoint being served.
+package metricslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	metricPrefix  = "runtime_enforcer_"
+	counterSuffix = "_total"
+)
+
+// DefaultCounters are the counters logged in every snapshot.
+//
+//nolint:gochecknoglobals // read-only list of the default counters.
+var DefaultCounters = []string{
+	"runtime_enforcer_violations_total",
+	"runtime_enforcer_bpf_dropped_events_total",
+	"runtime_enforcer_apply_errors_total",
+	"runtime_enforcer_policy_map_full_total",
+}
+
+// Snapshotter logs the value of some counters, summed over all their label values.
+type Snapshotter struct {
+	logger   *slog.Logger
+	gatherer prometheus.Gatherer
+	counters []string
+}
+
+// NewSnapshotter returns a snapshotter logging the given counters read from gatherer.
+func NewSnapshotter(logger *slog.Logger, gatherer prometheus.Gatherer, counters []string) *Snapshotter {
+	return &Snapshotter{
+		logger:   logger.With("component", "metrics-snapshot"),
+		gatherer: gatherer,
+		counters: counters,
+	}
+}
+
+// fieldName returns the log field of a counter: its name without the common prefix and the _total suffix.
+func fieldName(name string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(name, metricPrefix), counterSuffix)
+}
+
+// Snapshot logs the current value of the counters. A counter with no value yet is logged as 0.
+func (s *Snapshotter) Snapshot(ctx context.Context) error {
+	families, err := s.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather the metrics: %w", err)
+	}
+	values := make(map[string]float64, len(s.counters))
+	for _, family := range families {
+		if family.GetType() != dto.MetricType_COUNTER {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			values[family.GetName()] += metric.GetCounter().GetValue()
+		}
+	}
+	attrs := make([]any, 0, 2*len(s.counters))
+	for _, name := range s.counters {
+		attrs = append(attrs, fieldName(name), values[name])
+	}
+	s.logger.InfoContext(ctx, "metrics snapshot", attrs...)
+	return nil
+}
+
+// Run logs a snapshot every interval until ctx is done.
+func (s *Snapshotter) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.Snapshot(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "failed to take the metrics snapshot", "error", err)
+			}
+		}
+	}
+}
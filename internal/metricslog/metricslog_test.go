@@ -0,0 +1,46 @@
+package metricslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/metricslog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	violations := prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "runtime_enforcer_violations_total"},
+		[]string{"action"},
+	)
+	applyErrors := prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "runtime_enforcer_apply_errors_total"},
+		[]string{"phase"},
+	)
+	registry.MustRegister(violations, applyErrors)
+
+	var buf bytes.Buffer
+	snapshotter := metricslog.NewSnapshotter(
+		slog.New(slog.NewJSONHandler(&buf, nil)),
+		registry,
+		metricslog.DefaultCounters,
+	)
+
+	violations.WithLabelValues("monitor").Add(2)
+	violations.WithLabelValues("protect").Inc()
+	applyErrors.WithLabelValues("mode").Inc()
+	require.NoError(t, snapshotter.Snapshot(t.Context()))
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "metrics snapshot", line["msg"])
+	// the values are summed over the labels, the counters with no value yet are logged as 0.
+	require.InDelta(t, 3, line["violations"], 0)
+	require.InDelta(t, 1, line["apply_errors"], 0)
+	require.InDelta(t, 0, line["bpf_dropped_events"], 0)
+	require.InDelta(t, 0, line["policy_map_full"], 0)
+}
@@ -4,12 +4,66 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/containerd/nri/pkg/api"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
 )
 
+const (
+	// containerdImageNameAnnotation is set by containerd with the image reference of the container.
+	containerdImageNameAnnotation = "io.kubernetes.cri.image-name"
+	// crioImageNameAnnotation is set by CRI-O with the image reference of the container.
+	crioImageNameAnnotation = "io.kubernetes.cri-o.ImageName"
+)
+
+// imageFromContainer returns the image reference of the container together with its digest.
+// The digest is available only when the image is pinned (e.g. `nginx@sha256:...`),
+// when the image is referenced only by tag we return an empty digest.
+func imageFromContainer(container *api.Container) (string, string) {
+	annotations := container.GetAnnotations()
+	image := annotations[containerdImageNameAnnotation]
+	if image == "" {
+		image = annotations[crioImageNameAnnotation]
+	}
+	_, digest, found := strings.Cut(image, "@")
+	if !found {
+		return image, ""
+	}
+	return image, digest
+}
+
+// startTimeFromContainer returns the time the container was started. A container reported by the
+// StartContainer hook is not started yet, so we fall back to its creation time, which is as unique
+// to the container instance. The zero time is returned when the runtime reports neither.
+func startTimeFromContainer(container *api.Container) time.Time {
+	if startedAt := container.GetStartedAt(); startedAt != 0 {
+		return time.Unix(0, startedAt).UTC()
+	}
+	if createdAt := container.GetCreatedAt(); createdAt != 0 {
+		return time.Unix(0, createdAt).UTC()
+	}
+	return time.Time{}
+}
+
+// isHostPIDPod returns true if the pod shares the host PID namespace.
+// The runtime doesn't create a new PID namespace for these pods, so it's missing from the sandbox namespaces.
+func isHostPIDPod(pod *api.PodSandbox) bool {
+	namespaces := pod.GetLinux().GetNamespaces()
+	if len(namespaces) == 0 {
+		// the runtime doesn't report the namespaces, we cannot tell, so we assume the default
+		return false
+	}
+	for _, ns := range namespaces {
+		if ns.GetType() == "pid" {
+			return false
+		}
+	}
+	return true
+}
+
 func cgroupFromContainer(container *api.Container) (resolver.CgroupID, string, error) {
 	if container == nil {
 		// safety check, this should never happen
@@ -34,6 +88,8 @@ func cgroupFromContainer(container *api.Container) (resolver.CgroupID, string, e
 		)
 	}
 
+	cgroups.RecordCgroupDriver(container.GetLinux().GetCgroupsPath())
+
 	cgRoot := cgroups.GetCgroupResolutionPrefix()
 	path := filepath.Join(cgRoot, parsedPath)
 
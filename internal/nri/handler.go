@@ -15,14 +15,64 @@ import (
 )
 
 const (
-	maxDelay = time.Minute * 1
+	defaultBaseDelay = time.Second
+	defaultMaxDelay  = time.Minute * 1
 )
 
+// defaultSocketPaths are the known locations of the NRI socket, probed in order when no socket path is configured.
+// The first one is the default of containerd and CRI-O, the others cover hosts where /var/run is not a link to /run
+// and runtimes that keep the socket in their own state directory.
+var defaultSocketPaths = []string{
+	"/var/run/nri/nri.sock",
+	"/run/nri/nri.sock",
+	"/run/containerd/nri/nri.sock",
+}
+
 type Handler struct {
-	socketPath  string
-	pluginIndex string
-	logger      *slog.Logger
-	resolver    *resolver.Resolver
+	socketPath string
+	// socketCandidates are the locations probed again before each reconnection, empty when the socket path
+	// is configured.
+	socketCandidates []string
+	pluginIndex      string
+	logger           *slog.Logger
+	resolver         *resolver.Resolver
+
+	// reconnection settings used when the NRI plugin exits with an error.
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	// maxAttempts is the number of attempts before giving up, 0 means retry forever.
+	maxAttempts uint
+}
+
+type HandlerOption func(*Handler)
+
+// WithReconnectBackoff sets the initial and the maximum delay between reconnection attempts.
+// The delay doubles at each failed attempt until it reaches maxDelay.
+func WithReconnectBackoff(baseDelay, maxDelay time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.baseDelay = baseDelay
+		h.maxDelay = maxDelay
+	}
+}
+
+// WithReconnectMaxAttempts sets the number of failed attempts after which the handler gives up
+// and returns an error, so that the agent is restarted. 0 means retry forever.
+func WithReconnectMaxAttempts(maxAttempts uint) HandlerOption {
+	return func(h *Handler) {
+		h.maxAttempts = maxAttempts
+	}
+}
+
+// backoffDelay returns the delay before the retry n (0 for the first retry).
+func backoffDelay(n uint, baseDelay, maxDelay time.Duration) time.Duration {
+	delay := baseDelay
+	for range n {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return min(delay, maxDelay)
 }
 
 func newNRIPlugin(
@@ -64,16 +114,51 @@ func (p *plugin) Run(ctx context.Context) error {
 	return err
 }
 
+// isSocket reports whether path exists and is a unix socket.
+func isSocket(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Type() == os.ModeSocket
+}
+
+// discoverSocketPath returns the first of candidates that is a socket according to present.
+// When none is present yet, for example because the runtime is still starting, the first candidate is returned
+// and the connection check reports the missing socket.
+func discoverSocketPath(logger *slog.Logger, candidates []string, present func(string) bool) string {
+	for _, path := range candidates {
+		if present(path) {
+			logger.Info("discovered NRI socket", "path", path)
+			return path
+		}
+	}
+	logger.Warn("no NRI socket found in the known locations, using the default one",
+		"candidates", candidates,
+		"path", candidates[0],
+	)
+	return candidates[0]
+}
+
+// NewNRIHandler creates the handler of the NRI plugin. When socketPath is empty, the socket is looked up in
+// the known NRI socket locations.
 func NewNRIHandler(
 	socketPath, pluginIndex string,
 	logger *slog.Logger,
 	r *resolver.Resolver,
+	opts ...HandlerOption,
 ) (*Handler, error) {
 	h := &Handler{
 		socketPath:  socketPath,
 		pluginIndex: pluginIndex,
 		logger:      logger.With("component", "nri-handler"),
 		resolver:    r,
+		baseDelay:   defaultBaseDelay,
+		maxDelay:    defaultMaxDelay,
+	}
+	for _, option := range opts {
+		option(h)
+	}
+	if h.socketPath == "" {
+		h.socketCandidates = defaultSocketPaths
+		h.socketPath = discoverSocketPath(h.logger, h.socketCandidates, isSocket)
 	}
 	if err := h.checkNRISupport(); err != nil {
 		return nil, fmt.Errorf("NRI support check failed: %w", err)
@@ -81,6 +166,21 @@ func NewNRIHandler(
 	return h, nil
 }
 
+// rediscoverSocketPath probes the known locations again before a reconnection: a restarted runtime can
+// listen on another of them. The socket path is kept when it is configured or when no socket is present yet.
+func (h *Handler) rediscoverSocketPath() {
+	for _, path := range h.socketCandidates {
+		if !isSocket(path) {
+			continue
+		}
+		if path != h.socketPath {
+			h.logger.Info("NRI socket moved", "previous", h.socketPath, "path", path)
+			h.socketPath = path
+		}
+		return
+	}
+}
+
 func (h *Handler) checkNRISupport() error {
 	const (
 		connectionTimeout = 3 * time.Second
@@ -88,7 +188,7 @@ func (h *Handler) checkNRISupport() error {
 	)
 
 	tryConnect := func() error {
-		h.logger.Info("connecting to NRI socket")
+		h.logger.Info("connecting to NRI socket", "path", h.socketPath)
 		d := net.Dialer{
 			Timeout: connectionTimeout,
 		}
@@ -134,6 +234,7 @@ func (h *Handler) startNRIPlugin(ctx context.Context) error {
 			// is usually not very helpful, e.g., `ttrpc: server closed`.
 			err = p.lastErr
 		}
+		h.resolver.NRIDisconnected(err)
 		return fmt.Errorf("NRI plugin exited with error: %w", err)
 	}
 	return nil
@@ -155,22 +256,33 @@ func (h *Handler) Start(ctx context.Context) error {
 		return true
 	}
 
-	return retry.Do(
+	attempt := 0
+	err := retry.Do(
 		func() error {
+			if attempt > 0 {
+				h.rediscoverSocketPath()
+			}
+			attempt++
 			return h.startNRIPlugin(ctx)
 		},
 		retry.Context(ctx),
-		retry.Attempts(0), // infinite attempts
-		retry.Delay(time.Second),
-		retry.DelayType(retry.BackOffDelay),
-		retry.MaxDelay(maxDelay),
+		retry.Attempts(h.maxAttempts), // 0 means infinite attempts
+		retry.DelayType(func(n uint, _ error, _ *retry.Config) time.Duration {
+			return backoffDelay(n, h.baseDelay, h.maxDelay)
+		}),
+		retry.LastErrorOnly(true),
 		retry.RetryIf(isRetryable),
 		retry.OnRetry(func(n uint, err error) {
 			// n = 0 for the first retry
 			h.logger.WarnContext(ctx, "error during NRI plugin execution, retrying...",
 				"attempt", n+1,
+				"delay", backoffDelay(n, h.baseDelay, h.maxDelay),
 				"error", err,
 			)
 		}),
 	)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("giving up on NRI plugin after %d attempts: %w", h.maxAttempts, err)
+	}
+	return err
 }
@@ -0,0 +1,115 @@
+package nri
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	const (
+		baseDelay = time.Second
+		maxDelay  = 10 * time.Second
+	)
+
+	expected := []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	}
+	for n, want := range expected {
+		require.Equal(t, want, backoffDelay(uint(n), baseDelay, maxDelay), "retry %d", n)
+	}
+
+	// large retry counts must not overflow and must respect the cap.
+	require.Equal(t, maxDelay, backoffDelay(1000, baseDelay, maxDelay))
+	// a base delay greater than the cap is capped as well.
+	require.Equal(t, maxDelay, backoffDelay(0, time.Minute, maxDelay))
+}
+
+func TestDiscoverSocketPath(t *testing.T) {
+	candidates := []string{"/var/run/nri/nri.sock", "/run/nri/nri.sock", "/run/containerd/nri/nri.sock"}
+	logger := slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name    string
+		present []string
+		want    string
+	}{
+		{
+			name:    "the standard location wins over the fallbacks",
+			present: []string{"/run/containerd/nri/nri.sock", "/var/run/nri/nri.sock"},
+			want:    "/var/run/nri/nri.sock",
+		},
+		{
+			name:    "the fallbacks are probed in order",
+			present: []string{"/run/containerd/nri/nri.sock", "/run/nri/nri.sock"},
+			want:    "/run/nri/nri.sock",
+		},
+		{
+			name:    "the last fallback",
+			present: []string{"/run/containerd/nri/nri.sock"},
+			want:    "/run/containerd/nri/nri.sock",
+		},
+		{
+			name: "no socket falls back to the standard location",
+			want: "/var/run/nri/nri.sock",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			present := func(path string) bool { return slices.Contains(tt.present, path) }
+			require.Equal(t, tt.want, discoverSocketPath(logger, candidates, present))
+		})
+	}
+}
+
+func TestNewNRIHandlerExplicitSocketPath(t *testing.T) {
+	// the explicit path is used as is, even when a socket exists in the known locations.
+	socketPath := filepath.Join(t.TempDir(), "nri.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	h, err := NewNRIHandler(socketPath, "00", slog.New(slog.DiscardHandler), nil)
+	require.NoError(t, err)
+	require.Equal(t, socketPath, h.socketPath)
+	require.True(t, isSocket(socketPath))
+	require.False(t, isSocket(filepath.Dir(socketPath)))
+}
+
+func TestRediscoverSocketPath(t *testing.T) {
+	dir := t.TempDir()
+	candidates := []string{filepath.Join(dir, "nri.sock"), filepath.Join(dir, "containerd", "nri.sock")}
+	require.NoError(t, os.Mkdir(filepath.Dir(candidates[1]), 0o755))
+	h := &Handler{
+		socketPath:       candidates[0],
+		socketCandidates: candidates,
+		logger:           slog.New(slog.DiscardHandler),
+	}
+
+	// no socket is present yet: the path is kept.
+	h.rediscoverSocketPath()
+	require.Equal(t, candidates[0], h.socketPath)
+
+	// the restarted runtime listens on another location.
+	listener, err := net.Listen("unix", candidates[1])
+	require.NoError(t, err)
+	defer listener.Close()
+	h.rediscoverSocketPath()
+	require.Equal(t, candidates[1], h.socketPath)
+
+	// a configured path is never changed.
+	h = &Handler{socketPath: candidates[0], logger: slog.New(slog.DiscardHandler)}
+	h.rediscoverSocketPath()
+	require.Equal(t, candidates[0], h.socketPath)
+}
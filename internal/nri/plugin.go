@@ -2,8 +2,10 @@ package nri
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	retry "github.com/avast/retry-go/v4"
 	"github.com/containerd/nri/pkg/api"
@@ -14,6 +16,13 @@ import (
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/workloadkind"
 )
 
+const (
+	// serviceAccountWaitAttempts and serviceAccountWaitDelay bound the time StartContainer waits for the pod
+	// informer to report the service account of the pod, well below the NRI request timeout.
+	serviceAccountWaitAttempts = 20
+	serviceAccountWaitDelay    = 50 * time.Millisecond
+)
+
 type plugin struct {
 	stub            stub.Stub
 	logger          *slog.Logger
@@ -84,6 +93,7 @@ func podSandboxToPodMeta(pod *api.PodSandbox, workloadName string, workloadKind
 		WorkloadName: workloadName,
 		WorkloadType: string(workloadKind),
 		Labels:       pod.GetLabels(),
+		HostPID:      isHostPIDPod(pod),
 	}
 }
 
@@ -117,15 +127,20 @@ func (p *plugin) Synchronize(
 			return nil, p.lastErr
 		}
 
+		image, imageDigest := imageFromContainer(container)
+
 		// Populate the sandbox map
 		if _, exists := tmpSandboxes[container.GetPodSandboxId()]; !exists {
 			tmpSandboxes[container.GetPodSandboxId()] = make(map[resolver.ContainerID]resolver.ContainerInput)
 		}
 		tmpSandboxes[container.GetPodSandboxId()][container.GetId()] = resolver.ContainerInput{
 			ContainerMeta: resolver.ContainerMeta{
-				CgroupID: cgroupID,
-				Name:     container.GetName(),
-				ID:       container.GetId(),
+				CgroupID:    cgroupID,
+				Name:        container.GetName(),
+				ID:          container.GetId(),
+				Image:       image,
+				ImageDigest: imageDigest,
+				StartTime:   startTimeFromContainer(container),
 			},
 			CgroupPath: cgroupPath,
 		}
@@ -161,7 +176,13 @@ func (p *plugin) Synchronize(
 		podLogger.DebugContext(ctx, "Synchronize pod with containers",
 			"containers", containers,
 		)
-		if err := p.resolver.AddPodContainerFromNri(podData); err != nil {
+		err := p.resolver.AddPodContainerFromNri(podData)
+		if errors.Is(err, resolver.ErrServiceAccountUnknown) {
+			// The containers are already running, they are enforced once the pod informer reports the service account.
+			podLogger.WarnContext(ctx, "pod not enforced until its service account is reported", "error", err)
+			continue
+		}
+		if err != nil {
 			// This could be recoverable. Returning an error so we can retry.
 			podLogger.ErrorContext(ctx, "failed to add pod container from NRI", "error", err)
 			return nil, fmt.Errorf("failed to add pod container from NRI: %w", err)
@@ -209,21 +230,36 @@ func (p *plugin) StartContainer(
 	}
 
 	workloadName, workloadKind := p.getWorkloadInfoAndLog(ctx, pod)
+	image, imageDigest := imageFromContainer(container)
 	podData := resolver.PodInput{
 		Meta: podSandboxToPodMeta(pod, workloadName, workloadKind),
 		Containers: map[resolver.ContainerID]resolver.ContainerInput{
 			container.GetId(): {
 				ContainerMeta: resolver.ContainerMeta{
-					CgroupID: cgroupID,
-					Name:     container.GetName(),
-					ID:       container.GetId(),
+					CgroupID:    cgroupID,
+					Name:        container.GetName(),
+					ID:          container.GetId(),
+					Image:       image,
+					ImageDigest: imageDigest,
+					StartTime:   startTimeFromContainer(container),
 				},
 				CgroupPath: "",
 			},
 		},
 	}
 
-	if err = p.resolver.AddPodContainerFromNri(podData); err != nil {
+	// NRI doesn't carry the service account of the pod, it is reported by the pod informer, which may not have seen
+	// the pod yet. Wait for it rather than starting a container its policy doesn't enforce.
+	err = retry.Do(
+		func() error { return p.resolver.AddPodContainerFromNri(podData) },
+		retry.Context(ctx),
+		retry.RetryIf(func(err error) bool { return errors.Is(err, resolver.ErrServiceAccountUnknown) }),
+		retry.Attempts(serviceAccountWaitAttempts),
+		retry.Delay(serviceAccountWaitDelay),
+		retry.DelayType(retry.FixedDelay),
+		retry.LastErrorOnly(true),
+	)
+	if err != nil {
 		return handleError("failed to add pod container from NRI", err)
 	}
 	return nil
@@ -3,12 +3,15 @@ package nri
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/containerd/nri/pkg/api"
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/testutil"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/workloadkind"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func newTestPlugin(
@@ -52,6 +55,19 @@ func testContainer() *api.Container {
 	}
 }
 
+func restrictedPolicy(namespace string) *v1alpha1.WorkloadPolicy {
+	return &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "restricted", Namespace: namespace},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:                   "protect",
+			AllowedServiceAccounts: []string{"team-a"},
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+}
+
 func TestPluginStartContainer(t *testing.T) {
 	t.Run("adds container to resolver on success", func(t *testing.T) {
 		pod := testPodSandbox()
@@ -81,6 +97,89 @@ func TestPluginStartContainer(t *testing.T) {
 		}, containerView)
 	})
 
+	t.Run("records the image digest of a digest-pinned container", func(t *testing.T) {
+		pod := testPodSandbox()
+		container := testContainer()
+		container.Annotations = map[string]string{
+			containerdImageNameAnnotation: "registry.example.com/app@sha256:0123456789abcdef",
+		}
+
+		p := newTestPlugin(t, false, 100)
+		require.NoError(t, p.StartContainer(t.Context(), pod, container))
+
+		containerView, err := p.resolver.GetContainerView(100)
+		require.NoError(t, err)
+		require.Equal(t, "registry.example.com/app@sha256:0123456789abcdef", containerView.Meta.Image)
+		require.Equal(t, "sha256:0123456789abcdef", containerView.Meta.ImageDigest)
+	})
+
+	t.Run("leaves the image digest empty for a tag-only image", func(t *testing.T) {
+		pod := testPodSandbox()
+		container := testContainer()
+		container.Annotations = map[string]string{
+			crioImageNameAnnotation: "registry.example.com/app:1.0",
+		}
+
+		p := newTestPlugin(t, false, 100)
+		require.NoError(t, p.StartContainer(t.Context(), pod, container))
+
+		containerView, err := p.resolver.GetContainerView(100)
+		require.NoError(t, err)
+		require.Equal(t, "registry.example.com/app:1.0", containerView.Meta.Image)
+		require.Empty(t, containerView.Meta.ImageDigest)
+	})
+
+	t.Run("records the start time of the container", func(t *testing.T) {
+		pod := testPodSandbox()
+		container := testContainer()
+		createdAt := time.Date(2026, 4, 24, 15, 36, 7, 0, time.UTC)
+		container.CreatedAt = createdAt.UnixNano()
+
+		p := newTestPlugin(t, false, 100)
+		require.NoError(t, p.StartContainer(t.Context(), pod, container))
+
+		// the container is not started yet when the hook runs, its creation time is used instead.
+		containerView, err := p.resolver.GetContainerView(100)
+		require.NoError(t, err)
+		require.Equal(t, createdAt, containerView.Meta.StartTime)
+
+		startedAt := createdAt.Add(time.Second)
+		container.StartedAt = startedAt.UnixNano()
+		require.Equal(t, startedAt, startTimeFromContainer(container))
+	})
+
+	t.Run("waits for the service account of a pod with a restricted policy", func(t *testing.T) {
+		pod := testPodSandbox()
+		pod.Labels = map[string]string{v1alpha1.PolicyLabelKey: "restricted"}
+		container := testContainer()
+
+		p := newTestPlugin(t, false, 100)
+		p.resolver.SetServiceAccountChecks(true)
+		require.NoError(t, p.resolver.ReconcileWP(restrictedPolicy(pod.GetNamespace())))
+
+		go func() {
+			time.Sleep(2 * serviceAccountWaitDelay)
+			_ = p.resolver.SetPodServiceAccount(pod.GetUid(), "team-a")
+		}()
+		require.NoError(t, p.StartContainer(t.Context(), pod, container))
+		wc, ok := p.resolver.GetWorkloadContext(100)
+		require.True(t, ok)
+		require.Equal(t, "restricted", wc.Policy)
+	})
+
+	t.Run("returns wrapped error in fail-closed mode when the service account is never reported", func(t *testing.T) {
+		pod := testPodSandbox()
+		pod.Labels = map[string]string{v1alpha1.PolicyLabelKey: "restricted"}
+		container := testContainer()
+
+		p := newTestPlugin(t, false, 100)
+		p.resolver.SetServiceAccountChecks(true)
+		require.NoError(t, p.resolver.ReconcileWP(restrictedPolicy(pod.GetNamespace())))
+
+		err := p.StartContainer(t.Context(), pod, container)
+		require.ErrorContains(t, err, "runtime-enforcer has prevented the container 'demo-pod/app' from starting")
+	})
+
 	t.Run("returns nil in fail-open mode when cgroup lookup fails", func(t *testing.T) {
 		p := newTestPlugin(t, true, 0)
 		pod := testPodSandbox()
@@ -103,3 +202,53 @@ func TestPluginStartContainer(t *testing.T) {
 		require.Empty(t, p.resolver.PodCacheSnapshot())
 	})
 }
+
+func TestPluginHostPIDPod(t *testing.T) {
+	t.Run("detects hostPID pods and attributes only their container cgroups", func(t *testing.T) {
+		pod := testPodSandbox()
+		// hostPID pods don't get a dedicated pid namespace.
+		pod.Linux = &api.LinuxPodSandbox{
+			Namespaces: []*api.LinuxNamespace{{Type: "network"}, {Type: "ipc"}},
+		}
+		container := testContainer()
+
+		p := newTestPlugin(t, false, 100)
+		require.NoError(t, p.StartContainer(t.Context(), pod, container))
+
+		containerView, err := p.resolver.GetContainerView(100)
+		require.NoError(t, err)
+		require.True(t, containerView.PodMeta.HostPID)
+
+		// A host process runs in a cgroup that doesn't belong to the pod, so it must not be attributed to it.
+		_, err = p.resolver.GetContainerView(200)
+		require.Error(t, err)
+	})
+
+	t.Run("pods with their own pid namespace are not hostPID", func(t *testing.T) {
+		pod := testPodSandbox()
+		pod.Linux = &api.LinuxPodSandbox{
+			Namespaces: []*api.LinuxNamespace{{Type: "network"}, {Type: "pid"}},
+		}
+		container := testContainer()
+
+		p := newTestPlugin(t, false, 100)
+		require.NoError(t, p.StartContainer(t.Context(), pod, container))
+
+		containerView, err := p.resolver.GetContainerView(100)
+		require.NoError(t, err)
+		require.False(t, containerView.PodMeta.HostPID)
+	})
+
+	t.Run("pods whose namespaces are not reported are not hostPID", func(t *testing.T) {
+		pod := testPodSandbox()
+		pod.Linux = &api.LinuxPodSandbox{}
+		container := testContainer()
+
+		p := newTestPlugin(t, false, 100)
+		require.NoError(t, p.StartContainer(t.Context(), pod, container))
+
+		containerView, err := p.resolver.GetContainerView(100)
+		require.NoError(t, err)
+		require.False(t, containerView.PodMeta.HostPID)
+	})
+}
@@ -0,0 +1,194 @@
+package podreadinesshandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+// mirrorPodAnnotation contains the UID assigned by the kubelet to static pods,
+// which is the one reported by NRI.
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// PolicyBindingDeniedReason is the reason of the events reporting the pods whose service account
+// is not allowed to use their policy.
+const PolicyBindingDeniedReason = "PolicyBindingDenied"
+
+// PodReadinessHandler reports to the resolver the pods of the node reaching the Ready condition,
+// so that their policy is enforced in the declared mode only after the readiness,
+// the containers reported as running, so that they leave their startup phase,
+// the terminating pods, so that their policy can be relaxed during their graceful shutdown,
+// and the service accounts of the pods, so that the policies restricted to some service accounts can be enforced.
+type PodReadinessHandler struct {
+	client.Client
+
+	logger   *slog.Logger
+	resolver *resolver.Resolver
+	recorder events.EventRecorder
+
+	mu sync.Mutex
+	// podIDs keeps the resolver pod ID of the known pods, since it cannot be recovered once the pod is deleted.
+	podIDs map[types.NamespacedName]resolver.PodID
+	// deniedPods contains the pods whose denied policy binding has already been reported with an event.
+	deniedPods map[types.NamespacedName]struct{}
+}
+
+func NewPodReadinessHandler(
+	client client.Client,
+	logger *slog.Logger,
+	r *resolver.Resolver,
+	recorder events.EventRecorder,
+) *PodReadinessHandler {
+	return &PodReadinessHandler{
+		Client:     client,
+		logger:     logger,
+		resolver:   r,
+		recorder:   recorder,
+		podIDs:     make(map[types.NamespacedName]resolver.PodID),
+		deniedPods: make(map[types.NamespacedName]struct{}),
+	}
+}
+
+// podID returns the pod ID used by the resolver, matching the one reported by NRI.
+func podID(pod *corev1.Pod) resolver.PodID {
+	if uid, ok := pod.Annotations[mirrorPodAnnotation]; ok && uid != "" {
+		return uid
+	}
+	return string(pod.UID)
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// startedContainerIDs returns the runtime IDs of the containers of the pod reported as running.
+// The kubelet reports a container as running only once its postStart hook completed.
+func startedContainerIDs(pod *corev1.Pod) []resolver.ContainerID {
+	var ids []resolver.ContainerID
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		if status.State.Running == nil || status.ContainerID == "" {
+			continue
+		}
+		// the ID is reported as <runtime>://<id>, NRI reports only the id.
+		_, id, found := strings.Cut(status.ContainerID, "://")
+		if !found {
+			id = status.ContainerID
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// serviceAccountName returns the service account the pod runs with, the default one when it is not set.
+func serviceAccountName(pod *corev1.Pod) string {
+	if pod.Spec.ServiceAccountName == "" {
+		return "default"
+	}
+	return pod.Spec.ServiceAccountName
+}
+
+// reportDeniedBinding reports once with an event on the pod that its service account is not allowed
+// to use its policy, so that the policy is not enforced on it.
+func (r *PodReadinessHandler) reportDeniedBinding(name types.NamespacedName, pod *corev1.Pod, err error) {
+	r.mu.Lock()
+	_, reported := r.deniedPods[name]
+	r.deniedPods[name] = struct{}{}
+	r.mu.Unlock()
+	if reported {
+		return
+	}
+	r.logger.Warn("policy binding denied", "pod", name, "error", err)
+	r.recorder.Eventf(pod, nil, corev1.EventTypeWarning, PolicyBindingDeniedReason, "EnforcePolicy",
+		"the policy is not enforced: %s", err)
+}
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+
+func (r *PodReadinessHandler) Reconcile(
+	ctx context.Context,
+	req ctrl.Request,
+) (ctrl.Result, error) {
+	var pod corev1.Pod
+	if err := r.Get(ctx, req.NamespacedName, &pod); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get Pod '%s': %w", req.NamespacedName, err)
+		}
+		// The pod has been removed.
+		r.mu.Lock()
+		id, ok := r.podIDs[req.NamespacedName]
+		delete(r.podIDs, req.NamespacedName)
+		delete(r.deniedPods, req.NamespacedName)
+		r.mu.Unlock()
+		if ok {
+			r.resolver.ForgetPodReadiness(id)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	id := podID(&pod)
+	r.mu.Lock()
+	r.podIDs[req.NamespacedName] = id
+	r.mu.Unlock()
+
+	if err := r.resolver.SetPodServiceAccount(id, serviceAccountName(&pod)); err != nil {
+		if !errors.Is(err, resolver.ErrPolicyBindingDenied) {
+			return ctrl.Result{}, fmt.Errorf("failed to enforce policy on Pod '%s': %w", req.NamespacedName, err)
+		}
+		r.reportDeniedBinding(req.NamespacedName, &pod, err)
+	}
+
+	for _, containerID := range startedContainerIDs(&pod) {
+		if err := r.resolver.MarkContainerStarted(id, containerID); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to enforce policy on started container of Pod '%s': %w",
+				req.NamespacedName, err)
+		}
+	}
+
+	// the deletion timestamp is set as soon as the deletion is requested, before the preStop hooks run.
+	if pod.DeletionTimestamp != nil {
+		if err := r.resolver.MarkPodTerminating(id); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to relax policy on terminating Pod '%s': %w",
+				req.NamespacedName, err)
+		}
+	}
+
+	if !isPodReady(&pod) {
+		return ctrl.Result{}, nil
+	}
+	if err := r.resolver.MarkPodReady(id); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to enforce policy on ready Pod '%s': %w", req.NamespacedName, err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// The manager cache is expected to contain only the pods of the node.
+func (r *PodReadinessHandler) SetupWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Pod{}).
+		Named("podreadiness").
+		Complete(r)
+	if err != nil {
+		return fmt.Errorf("unable to set up Pod readiness handler: %w", err)
+	}
+	return nil
+}
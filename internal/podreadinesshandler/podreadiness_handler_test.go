@@ -0,0 +1,76 @@
+package podreadinesshandler
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+func TestPodID(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "api-server-uid"}}
+	require.Equal(t, "api-server-uid", podID(pod))
+
+	// static pods are reported by NRI with the UID assigned by the kubelet.
+	pod.Annotations = map[string]string{mirrorPodAnnotation: "kubelet-uid"}
+	require.Equal(t, "kubelet-uid", podID(pod))
+}
+
+func TestIsPodReady(t *testing.T) {
+	pod := &corev1.Pod{}
+	require.False(t, isPodReady(pod))
+
+	pod.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+		{Type: corev1.PodReady, Status: corev1.ConditionFalse},
+	}
+	require.False(t, isPodReady(pod))
+
+	pod.Status.Conditions[1].Status = corev1.ConditionTrue
+	require.True(t, isPodReady(pod))
+}
+
+func TestStartedContainerIDs(t *testing.T) {
+	pod := &corev1.Pod{}
+	require.Empty(t, startedContainerIDs(pod))
+
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{ContainerID: "containerd://sidecar", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+	}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		// the postStart hook of the container is still running.
+		{ContainerID: "containerd://app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+		{ContainerID: "cri-o://worker", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+	}
+	require.Equal(t, []string{"sidecar", "worker"}, startedContainerIDs(pod))
+}
+
+func TestServiceAccountName(t *testing.T) {
+	pod := &corev1.Pod{}
+	require.Equal(t, "default", serviceAccountName(pod))
+	pod.Spec.ServiceAccountName = "builder"
+	require.Equal(t, "builder", serviceAccountName(pod))
+}
+
+func TestReportDeniedBinding(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	h := NewPodReadinessHandler(nil, slog.Default(), nil, recorder)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-b"}}
+	name := types.NamespacedName{Namespace: "team-b", Name: "app"}
+	err := fmt.Errorf("%w: service account %q", resolver.ErrPolicyBindingDenied, "builder")
+
+	h.reportDeniedBinding(name, pod, err)
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events, "Warning "+PolicyBindingDeniedReason)
+
+	// the denial is reported once per pod.
+	h.reportDeniedBinding(name, pod, err)
+	require.Empty(t, recorder.Events)
+}
@@ -0,0 +1,110 @@
+// Package policybindings publishes the policies enforced by the agent on the containers of its node
+// in a ConfigMap per node, so that the in-memory state of the resolver can be audited through the Kubernetes API.
+package policybindings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+const (
+	// ConfigMapPrefix is the prefix of the name of the ConfigMaps, followed by the node name.
+	ConfigMapPrefix = "runtime-enforcer-bindings-"
+	// BindingsKey is the key of the ConfigMap data containing the bindings as a JSON array of resolver.PolicyBinding.
+	BindingsKey = "bindings.json"
+	// NodeLabelKey is the label of the ConfigMaps containing the node name, to list the ConfigMaps of every node.
+	NodeLabelKey = "security.rancher.io/bindings-node"
+)
+
+// Publisher writes the bindings of the node into its ConfigMap.
+type Publisher struct {
+	client    client.Client
+	logger    *slog.Logger
+	bindings  func() []resolver.PolicyBinding
+	namespace string
+	nodeName  string
+
+	// published is the last content written into the ConfigMap.
+	published []resolver.PolicyBinding
+}
+
+// NewPublisher returns a publisher of the bindings returned by bindings into the ConfigMap of nodeName in namespace.
+func NewPublisher(
+	c client.Client,
+	logger *slog.Logger,
+	bindings func() []resolver.PolicyBinding,
+	namespace string,
+	nodeName string,
+) *Publisher {
+	return &Publisher{
+		client:    c,
+		logger:    logger,
+		bindings:  bindings,
+		namespace: namespace,
+		nodeName:  nodeName,
+	}
+}
+
+// ConfigMapName returns the name of the ConfigMap of the node.
+func ConfigMapName(nodeName string) string {
+	return ConfigMapPrefix + nodeName
+}
+
+// Publish writes the current bindings into the ConfigMap, it is a no-op when they did not change
+// since the last successful write.
+func (p *Publisher) Publish(ctx context.Context) error {
+	bindings := p.bindings()
+	if p.published != nil && reflect.DeepEqual(bindings, p.published) {
+		return nil
+	}
+	data, err := json.Marshal(bindings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the policy bindings: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName(p.nodeName), Namespace: p.namespace},
+	}
+	if _, err = controllerutil.CreateOrUpdate(ctx, p.client, cm, func() error {
+		if cm.Labels == nil {
+			cm.Labels = make(map[string]string)
+		}
+		cm.Labels[NodeLabelKey] = p.nodeName
+		cm.Data = map[string]string{BindingsKey: string(data)}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to write the policy bindings into ConfigMap '%s/%s': %w",
+			cm.Namespace, cm.Name, err)
+	}
+	p.published = bindings
+	return nil
+}
+
+// Run publishes the bindings right away and then every interval until ctx is done.
+// The changes of the bindings within an interval are written at once, which avoids rewriting
+// the ConfigMap for each container of a pod being started.
+func (p *Publisher) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Publish(ctx); err != nil {
+			p.logger.ErrorContext(ctx, "failed to publish the policy bindings", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
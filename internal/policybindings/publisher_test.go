@@ -0,0 +1,73 @@
+package policybindings
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+func readBindings(t *testing.T, p *Publisher) []resolver.PolicyBinding {
+	t.Helper()
+	var cm corev1.ConfigMap
+	key := types.NamespacedName{Namespace: "runtime-enforcer", Name: "runtime-enforcer-bindings-node-1"}
+	require.NoError(t, p.client.Get(t.Context(), key, &cm))
+	require.Equal(t, "node-1", cm.Labels[NodeLabelKey])
+	var bindings []resolver.PolicyBinding
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[BindingsKey]), &bindings))
+	return bindings
+}
+
+func TestPublish(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"main":    {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				"sidecar": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}}},
+			},
+		},
+	}))
+	p := NewPublisher(fake.NewClientBuilder().Build(), slog.Default(), r.PolicyBindings, "runtime-enforcer", "node-1")
+
+	require.NoError(t, p.Publish(t.Context()))
+	require.Equal(t, []resolver.PolicyBinding{
+		{Policy: "test-ns/example", Mode: "protect", Containers: []resolver.ContainerBinding{}},
+	}, readBindings(t, p))
+
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{
+			ID:        "pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"cid-main":    {ContainerMeta: resolver.ContainerMeta{ID: "cid-main", Name: "main", CgroupID: 100}},
+			"cid-sidecar": {ContainerMeta: resolver.ContainerMeta{ID: "cid-sidecar", Name: "sidecar", CgroupID: 101}},
+		},
+	}))
+	require.NoError(t, p.Publish(t.Context()))
+	bindings := readBindings(t, p)
+	require.Len(t, bindings, 1)
+	require.Len(t, bindings[0].Containers, 2)
+	require.Equal(t, "test-ns/test-pod", bindings[0].Containers[0].Pod)
+	require.Equal(t, "main", bindings[0].Containers[0].Container)
+	require.Equal(t, "sidecar", bindings[0].Containers[1].Container)
+
+	require.NoError(t, r.RemovePodContainerFromNri("pod-uid", "cid-sidecar"))
+	require.NoError(t, p.Publish(t.Context()))
+	bindings = readBindings(t, p)
+	require.Len(t, bindings[0].Containers, 1)
+	require.Equal(t, resolver.ContainerID("cid-main"), bindings[0].Containers[0].ContainerID)
+}
@@ -0,0 +1,140 @@
+// Package portscope classifies processes by the TCP ports they are listening on,
+// so that a violation can be correlated with the listening ports declared by a policy.
+//
+// The classification relies on the socket information exposed by procfs: the socket inodes
+// referenced by the file descriptors of the process are matched against the listening sockets
+// of its network namespace. It is best effort, the process may have exited or closed its sockets
+// by the time the violation is processed.
+package portscope
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Scope tells whether an exec came from a process listening on one of the ports declared by its policy.
+type Scope string
+
+const (
+	// ScopeInPorts is reported when the process is listening on at least one of the declared ports.
+	ScopeInPorts Scope = "in_ports"
+	// ScopeOutOfPorts is reported when the process is not listening on any of the declared ports.
+	ScopeOutOfPorts Scope = "out_of_ports"
+	// ScopeUnknown is reported when the sockets of the process could not be inspected.
+	ScopeUnknown Scope = "unknown"
+)
+
+const (
+	// tcpListenState is the hex value of TCP_LISTEN in /proc/net/tcp.
+	tcpListenState = "0A"
+	// socketLinkPrefix is the prefix of the fd symlinks referencing a socket, e.g. `socket:[12345]`.
+	socketLinkPrefix = "socket:["
+)
+
+// Classifier inspects the processes through the proc filesystem mounted at procRoot.
+// The agent runs in the host pid namespace, so the pid reported by BPF can be used as is.
+type Classifier struct {
+	procRoot string
+}
+
+func NewClassifier(procRoot string) *Classifier {
+	return &Classifier{procRoot: procRoot}
+}
+
+// Classify returns the scope of the process with the given pid against the declared ports.
+func (c *Classifier) Classify(pid uint32, ports []int32) (Scope, error) {
+	listening, err := c.ListeningPorts(pid)
+	if err != nil {
+		return ScopeUnknown, err
+	}
+	for _, port := range ports {
+		if _, ok := listening[uint16(port)]; ok { //nolint:gosec // ports are validated in [1, 65535]
+			return ScopeInPorts, nil
+		}
+	}
+	return ScopeOutOfPorts, nil
+}
+
+// ListeningPorts returns the TCP ports (IPv4 and IPv6) the process with the given pid is listening on.
+func (c *Classifier) ListeningPorts(pid uint32) (map[uint16]struct{}, error) {
+	procDir := filepath.Join(c.procRoot, strconv.FormatUint(uint64(pid), 10))
+	inodes, err := socketInodes(filepath.Join(procDir, "fd"))
+	if err != nil {
+		return nil, err
+	}
+
+	ports := make(map[uint16]struct{})
+	if len(inodes) == 0 {
+		return ports, nil
+	}
+	for _, table := range []string{"tcp", "tcp6"} {
+		err = parseListeningSockets(filepath.Join(procDir, "net", table), inodes, ports)
+		// tcp6 is missing when IPv6 is disabled.
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return ports, nil
+}
+
+// socketInodes returns the inodes of the sockets referenced by the file descriptors in fdDir.
+func socketInodes(fdDir string) (map[string]struct{}, error) {
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fdDir, err)
+	}
+	inodes := make(map[string]struct{})
+	for _, entry := range entries {
+		link, linkErr := os.Readlink(filepath.Join(fdDir, entry.Name()))
+		if linkErr != nil {
+			// The fd has been closed in the meantime.
+			continue
+		}
+		if inode, ok := strings.CutPrefix(link, socketLinkPrefix); ok {
+			inodes[strings.TrimSuffix(inode, "]")] = struct{}{}
+		}
+	}
+	return inodes, nil
+}
+
+// parseListeningSockets adds to ports the local port of the listening sockets of the table
+// (in the /proc/net/tcp format) whose inode is in inodes.
+func parseListeningSockets(path string, inodes map[string]struct{}, ports map[uint16]struct{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+		if _, ok := inodes[fields[9]]; !ok {
+			continue
+		}
+		_, hexPort, found := strings.Cut(fields[1], ":")
+		if !found {
+			continue
+		}
+		port, parseErr := strconv.ParseUint(hexPort, 16, 16)
+		if parseErr != nil {
+			return fmt.Errorf("failed to parse local address %q in %s: %w", fields[1], path, parseErr)
+		}
+		ports[uint16(port)] = struct{}{}
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
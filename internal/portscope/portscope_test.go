@@ -0,0 +1,74 @@
+package portscope
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tcpHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	// 0.0.0.0:8080 LISTEN inode 1001, 127.0.0.1:9090 LISTEN inode 2002,
+	// 10.0.0.1:8080 -> 10.0.0.2:40000 ESTABLISHED inode 1003.
+	tcpTable = tcpHeader +
+		"   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0\n" +
+		"   1: 0100007F:2382 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 2002 1 0000000000000000 100 0 0 10 0\n" +
+		"   2: 0100000A:1F90 0200000A:9C40 01 00000000:00000000 00:00000000 00000000     0        0 1003 1 0000000000000000 20 4 30 10 -1\n"
+	// [::]:443 LISTEN inode 1004.
+	tcp6Table = tcpHeader +
+		"   0: 00000000000000000000000000000000:01BB 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1004 1 0000000000000000 100 0 0 10 0\n"
+)
+
+// fakeProc creates a proc filesystem where the process 42 has the given fd links.
+func fakeProc(t *testing.T, withTCP6 bool, links ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	procDir := filepath.Join(root, "42")
+	require.NoError(t, os.MkdirAll(filepath.Join(procDir, "fd"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(procDir, "net"), 0o755))
+	for i, link := range links {
+		require.NoError(t, os.Symlink(link, filepath.Join(procDir, "fd", string(rune('0'+i)))))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(procDir, "net", "tcp"), []byte(tcpTable), 0o600))
+	if withTCP6 {
+		require.NoError(t, os.WriteFile(filepath.Join(procDir, "net", "tcp6"), []byte(tcp6Table), 0o600))
+	}
+	return root
+}
+
+func TestListeningPorts(t *testing.T) {
+	root := fakeProc(t, true, "/dev/null", "socket:[1001]", "socket:[1003]", "socket:[1004]", "pipe:[5]")
+	ports, err := NewClassifier(root).ListeningPorts(42)
+	require.NoError(t, err)
+	// 1003 is an established connection on 8080 and 2002 belongs to another process.
+	require.Equal(t, map[uint16]struct{}{8080: {}, 443: {}}, ports)
+
+	root = fakeProc(t, false, "socket:[2002]")
+	ports, err = NewClassifier(root).ListeningPorts(42)
+	require.NoError(t, err, "a missing tcp6 table is not an error")
+	require.Equal(t, map[uint16]struct{}{9090: {}}, ports)
+}
+
+func TestClassify(t *testing.T) {
+	c := NewClassifier(fakeProc(t, true, "socket:[1001]"))
+
+	scope, err := c.Classify(42, []int32{80, 8080})
+	require.NoError(t, err)
+	require.Equal(t, ScopeInPorts, scope)
+
+	scope, err = c.Classify(42, []int32{9090})
+	require.NoError(t, err)
+	require.Equal(t, ScopeOutOfPorts, scope)
+
+	// A process without sockets is never in scope.
+	scope, err = NewClassifier(fakeProc(t, true, "/dev/null")).Classify(42, []int32{8080})
+	require.NoError(t, err)
+	require.Equal(t, ScopeOutOfPorts, scope)
+
+	// The process exited before the classification.
+	scope, err = c.Classify(43, []int32{8080})
+	require.Error(t, err)
+	require.Equal(t, ScopeUnknown, scope)
+}
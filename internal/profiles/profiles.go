@@ -0,0 +1,140 @@
+// Package profiles provides the curated profiles of allowed executables bundled with the enforcer.
+//
+// A profile lists the executables run by a well-known image, e.g. the official nginx image, so
+// that a policy can reference it by name instead of repeating the list. Profiles are versioned:
+// the executables of a published version never change, a new version is added instead, so that
+// upgrading the enforcer never changes what the existing policies allow.
+package profiles
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// bundledFS contains one file per profile version, named `<name>-<version>.yaml`.
+//
+//go:embed bundled/*.yaml
+var bundledFS embed.FS
+
+// ErrUnknownProfile is returned when a policy references a profile that is not in the library.
+var ErrUnknownProfile = errors.New("unknown executable profile")
+
+// Profile is a versioned list of allowed executables.
+type Profile struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description,omitempty"`
+	Executables []string `json:"executables"`
+}
+
+type profileKey struct {
+	name    string
+	version string
+}
+
+// Library contains the profiles that can be referenced by the policies.
+type Library struct {
+	profiles map[profileKey]*Profile
+}
+
+// Bundled returns the library of the profiles embedded in the binary.
+// They are parsed only once, the following calls return the same library.
+var Bundled = sync.OnceValues(func() (*Library, error) {
+	sub, err := fs.Sub(bundledFS, "bundled")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+})
+
+// Load parses the profiles from the YAML files at the root of fsys.
+func Load(fsys fs.FS) (*Library, error) {
+	files, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	lib := &Library{profiles: make(map[profileKey]*Profile, len(files))}
+	for _, file := range files {
+		profile, err := loadProfile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load profile %s: %w", file, err)
+		}
+		lib.profiles[profileKey{name: profile.Name, version: profile.Version}] = profile
+	}
+	return lib, nil
+}
+
+func loadProfile(fsys fs.FS, file string) (*Profile, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	var profile Profile
+	if err = yaml.UnmarshalStrict(data, &profile); err != nil {
+		return nil, err
+	}
+	if profile.Name == "" || profile.Version == "" {
+		return nil, errors.New("name and version are required")
+	}
+	if expected := profile.Name + "-" + profile.Version + ".yaml"; path.Base(file) != expected {
+		return nil, fmt.Errorf("file must be named %s", expected)
+	}
+	if len(profile.Executables) == 0 {
+		return nil, errors.New("no executables")
+	}
+	seen := make(map[string]struct{}, len(profile.Executables))
+	for _, executable := range profile.Executables {
+		if !strings.HasPrefix(executable, "/") {
+			return nil, fmt.Errorf("executable %q is not an absolute path", executable)
+		}
+		if _, ok := seen[executable]; ok {
+			return nil, fmt.Errorf("duplicated executable %q", executable)
+		}
+		seen[executable] = struct{}{}
+	}
+	return &profile, nil
+}
+
+// Get returns the profile with the given name and version.
+func (l *Library) Get(name, version string) (*Profile, bool) {
+	profile, ok := l.profiles[profileKey{name: name, version: version}]
+	return profile, ok
+}
+
+// List returns the profiles sorted by name and version.
+func (l *Library) List() []*Profile {
+	list := make([]*Profile, 0, len(l.profiles))
+	for _, profile := range l.profiles {
+		list = append(list, profile)
+	}
+	slices.SortFunc(list, func(a, b *Profile) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Version, b.Version)
+	})
+	return list
+}
+
+// Expand returns the executables of the referenced profiles, sorted and deduplicated.
+func (l *Library) Expand(refs []v1alpha1.ExecutableProfileReference) ([]string, error) {
+	var executables []string
+	for _, ref := range refs {
+		profile, ok := l.Get(ref.Name, ref.Version)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s version %s", ErrUnknownProfile, ref.Name, ref.Version)
+		}
+		executables = append(executables, profile.Executables...)
+	}
+	slices.Sort(executables)
+	return slices.Compact(executables), nil
+}
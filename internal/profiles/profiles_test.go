@@ -0,0 +1,69 @@
+package profiles
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundled(t *testing.T) {
+	lib, err := Bundled()
+	require.NoError(t, err)
+	require.NotEmpty(t, lib.List())
+
+	nginx, ok := lib.Get("nginx", "1")
+	require.True(t, ok)
+	require.Contains(t, nginx.Executables, "/usr/sbin/nginx")
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("rejects invalid profiles", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			file    string
+			content string
+		}{
+			"mismatched file name": {
+				file:    "app-2.yaml",
+				content: "name: app\nversion: \"1\"\nexecutables: [/bin/app]\n",
+			},
+			"relative path": {
+				file:    "app-1.yaml",
+				content: "name: app\nversion: \"1\"\nexecutables: [bin/app]\n",
+			},
+			"duplicated executable": {
+				file:    "app-1.yaml",
+				content: "name: app\nversion: \"1\"\nexecutables: [/bin/app, /bin/app]\n",
+			},
+			"unknown field": {
+				file:    "app-1.yaml",
+				content: "name: app\nversion: \"1\"\nexecutable: [/bin/app]\n",
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := Load(fstest.MapFS{tc.file: {Data: []byte(tc.content)}})
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestExpand(t *testing.T) {
+	lib, err := Load(fstest.MapFS{
+		"app-1.yaml":    {Data: []byte("name: app\nversion: \"1\"\nexecutables: [/bin/sh, /bin/app]\n")},
+		"app-2.yaml":    {Data: []byte("name: app\nversion: \"2\"\nexecutables: [/bin/sh, /bin/app2]\n")},
+		"helper-1.yaml": {Data: []byte("name: helper\nversion: \"1\"\nexecutables: [/bin/sh, /bin/helper]\n")},
+	})
+	require.NoError(t, err)
+
+	executables, err := lib.Expand([]v1alpha1.ExecutableProfileReference{
+		{Name: "app", Version: "1"},
+		{Name: "helper", Version: "1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/bin/app", "/bin/helper", "/bin/sh"}, executables)
+
+	_, err = lib.Expand([]v1alpha1.ExecutableProfileReference{{Name: "app", Version: "3"}})
+	require.ErrorIs(t, err, ErrUnknownProfile)
+}
@@ -0,0 +1,63 @@
+package resolver
+
+import (
+	"log/slog"
+	"time"
+)
+
+const (
+	// largePolicyContainers is the number of containers from which a policy is considered large:
+	// the progress of its apply is logged.
+	largePolicyContainers = 32
+	// applyProgressStep is the number of containers applied between two progress logs of a large policy.
+	applyProgressStep = 16
+)
+
+// applyProgress logs the progress of the apply of a large policy, whose BPF writes hold the resolver lock
+// for a noticeable time. It is nil, and does nothing, for the other policies.
+type applyProgress struct {
+	logger  *slog.Logger
+	wpKey   NamespacedPolicyName
+	total   int
+	applied int
+	start   time.Time
+}
+
+func (r *Resolver) newApplyProgress(wpKey NamespacedPolicyName, containers int) *applyProgress {
+	if containers < largePolicyContainers {
+		return nil
+	}
+	r.logger.Info("applying a large policy", "wp", wpKey, "containers", containers)
+	return &applyProgress{
+		logger: r.logger,
+		wpKey:  wpKey,
+		total:  containers,
+		start:  time.Now(),
+	}
+}
+
+// containerApplied records that the executables of one more container have been written into BPF.
+func (p *applyProgress) containerApplied() {
+	if p == nil {
+		return
+	}
+	p.applied++
+	if p.applied%applyProgressStep == 0 && p.applied < p.total {
+		p.logger.Info("large policy apply in progress",
+			"wp", p.wpKey,
+			"applied", p.applied,
+			"containers", p.total,
+			"elapsed", time.Since(p.start))
+	}
+}
+
+// done records that every container of the policy has been written into BPF.
+func (p *applyProgress) done() {
+	if p == nil {
+		return
+	}
+	p.logger.Info("applied a large policy",
+		"wp", p.wpKey,
+		"containers", p.total,
+		"elapsed", time.Since(p.start))
+}
@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+// newLargePolicy returns a policy with the given number of containers, each one allowing its own executables.
+func newLargePolicy(name string, containers, executables int) *v1alpha1.WorkloadPolicy {
+	wp := newTestPolicy("test-ns", name)
+	for c := range containers {
+		allowed := make([]string, 0, executables)
+		for e := range executables {
+			allowed = append(allowed, fmt.Sprintf("/usr/local/bin/container-%d/tool-%d", c, e))
+		}
+		wp.Spec.RulesByContainer[fmt.Sprintf("c%d", c)] = &v1alpha1.WorkloadPolicyRules{
+			Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: allowed},
+		}
+	}
+	return wp
+}
+
+func TestReconcileWP_LargePolicy(t *testing.T) {
+	const (
+		containers     = 64
+		executables    = 256
+		lockHoldBudget = 2 * time.Second
+	)
+
+	r := NewTestResolver(t)
+	var logs bytes.Buffer
+	r.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	fake := newFakeBPFMaps(r)
+
+	countBefore, sumBefore := lockHoldSample(t, lockOpReconcilePolicy)
+	require.NoError(t, r.ReconcileWP(newLargePolicy("large", containers, executables)))
+	count, sum := lockHoldSample(t, lockOpReconcilePolicy)
+
+	require.Equal(t, countBefore+1, count)
+	require.Less(t, sum-sumBefore, lockHoldBudget.Seconds())
+	require.Len(t, fake.values, containers)
+	for _, values := range fake.values {
+		require.Len(t, values, executables)
+	}
+	require.Contains(t, logs.String(), "large policy apply in progress")
+	require.Contains(t, logs.String(), "applied a large policy")
+
+	// the progress of the small policies is not logged.
+	logs.Reset()
+	require.NoError(t, r.ReconcileWP(newTestPolicy("test-ns", "small", c1)))
+	require.NotContains(t, logs.String(), "large policy")
+}
+
+func BenchmarkReconcileWP_LargePolicy(b *testing.B) {
+	r := NewTestResolver(b)
+	r.logger = slog.New(slog.DiscardHandler)
+	wp := newLargePolicy("large", 64, 256)
+
+	for b.Loop() {
+		require.NoError(b, r.ReconcileWP(wp))
+		require.NoError(b, r.HandleWPDelete(wp))
+	}
+}
@@ -0,0 +1,45 @@
+package resolver
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// approvedCommandPaths returns the executables of the approved commands, they are allowed implicitly.
+func approvedCommandPaths(commands []v1alpha1.ApprovedCommand) []string {
+	paths := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		paths = append(paths, cmd.Path)
+	}
+	return paths
+}
+
+// commandsForBPF returns the approved commands as they must be written into BPF, sorted so that they can be
+// compared with the ones last written. With case-insensitive matching the paths are folded like the allowed
+// executables, the arguments are always compared as they are.
+func commandsForBPF(wp *v1alpha1.WorkloadPolicy, commands []v1alpha1.ApprovedCommand) []bpf.Command {
+	if len(commands) == 0 {
+		return nil
+	}
+	out := make([]bpf.Command, 0, len(commands))
+	for _, cmd := range commands {
+		path := cmd.Path
+		if wp.Spec.CaseInsensitiveMatching {
+			path = bpf.FoldPathCase(path)
+		}
+		out = append(out, bpf.Command{Path: path, Args: slices.Clone(cmd.Args)})
+	}
+	slices.SortFunc(out, compareCommands)
+	return slices.CompactFunc(out, commandsEqual)
+}
+
+func compareCommands(a, b bpf.Command) int {
+	return cmp.Or(cmp.Compare(a.Path, b.Path), slices.Compare(a.Args, b.Args))
+}
+
+func commandsEqual(a, b bpf.Command) bool {
+	return compareCommands(a, b) == 0
+}
@@ -0,0 +1,50 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovedCommands(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	wp := newTestPolicy("test-ns", "approved-commands", c1)
+	wp.Spec.RulesByContainer[c1].Executables.ApprovedCommands = []v1alpha1.ApprovedCommand{
+		{Path: "/bin/sh", Args: []string{"-c", "/app/healthcheck"}},
+		{Path: "/bin/sh", Args: []string{"-c", "/app/backup"}},
+	}
+
+	// the executables of the approved commands are allowed implicitly.
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+	require.Equal(t, []string{"/bin/sh", "/bin/sleep"}, f.values[polID])
+	require.Equal(t, []bpf.Command{
+		{Path: "/bin/sh", Args: []string{"-c", "/app/backup"}},
+		{Path: "/bin/sh", Args: []string{"-c", "/app/healthcheck"}},
+	}, f.commands[polID])
+
+	// a change of the commands only is written too.
+	wp.Spec.RulesByContainer[c1].Executables.ApprovedCommands = []v1alpha1.ApprovedCommand{
+		{Path: "/bin/sh", Args: []string{"-c", "/app/healthcheck"}},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []bpf.Command{{Path: "/bin/sh", Args: []string{"-c", "/app/healthcheck"}}}, f.commands[polID])
+
+	// the paths are folded with case-insensitive matching, the arguments are not.
+	wp.Spec.CaseInsensitiveMatching = true
+	wp.Spec.RulesByContainer[c1].Executables.ApprovedCommands = []v1alpha1.ApprovedCommand{
+		{Path: "/BIN/SH", Args: []string{"-C"}},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []bpf.Command{{Path: "/bin/sh", Args: []string{"-C"}}}, f.commands[polID])
+
+	// the commands survive a rebuild of the maps.
+	require.NoError(t, r.RebuildBPFMaps(f))
+	require.Equal(t, []bpf.Command{{Path: "/bin/sh", Args: []string{"-C"}}}, f.commands[polID])
+
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, f.commands)
+}
@@ -0,0 +1,94 @@
+package resolver
+
+import (
+	"cmp"
+	"slices"
+)
+
+// PolicyBinding lists the containers of the node a policy is applied to.
+type PolicyBinding struct {
+	// Policy is the namespaced name of the policy.
+	Policy NamespacedPolicyName `json:"policy"`
+	// Mode is the mode last written into BPF for the policy IDs of the policy,
+	// the containers overriding it report their own mode.
+	Mode string `json:"mode"`
+	// Containers is sorted by pod and container name.
+	Containers []ContainerBinding `json:"containers"`
+}
+
+// ContainerBinding is a container of the node and the policy ID its cgroup is attached to.
+type ContainerBinding struct {
+	Pod         string      `json:"pod"`
+	Container   string      `json:"container"`
+	ContainerID ContainerID `json:"containerID"`
+	PolicyID    PolicyID    `json:"policyID"`
+	// Mode is the mode of the container when it overrides the mode of the policy.
+	Mode string `json:"mode,omitempty"`
+	// Deferred is true when the container runs with the grace policy ID in monitor mode,
+	// e.g. until the pod is Ready or because it is out of the canary rollout.
+	Deferred bool `json:"deferred,omitempty"`
+}
+
+// PolicyBindings returns the policies known by the resolver with the containers they are enforced on,
+// sorted by policy name. The policies with no container on the node are returned with no containers.
+func (r *Resolver) PolicyBindings() []PolicyBinding {
+	defer r.lockTimed(lockOpBindings)()
+
+	byPolicy := make(map[NamespacedPolicyName]*PolicyBinding, len(r.wpState))
+	for name, info := range r.wpState {
+		byPolicy[name] = &PolicyBinding{Policy: name, Mode: info.mode.String(), Containers: []ContainerBinding{}}
+	}
+	r.forEachBoundContainer(func(name NamespacedPolicyName, pod *podEntry, containerID ContainerID,
+		meta *ContainerMeta, policyID PolicyID, deferred bool) {
+		binding := ContainerBinding{
+			Pod:         pod.podNamespace() + "/" + pod.podName(),
+			Container:   meta.Name,
+			ContainerID: containerID,
+			PolicyID:    policyID,
+			Deferred:    deferred,
+		}
+		if mode, ok := r.wpState[name].modeByContainer[meta.Name]; ok {
+			binding.Mode = mode.String()
+		}
+		byPolicy[name].Containers = append(byPolicy[name].Containers, binding)
+	})
+
+	bindings := make([]PolicyBinding, 0, len(byPolicy))
+	for _, binding := range byPolicy {
+		slices.SortFunc(binding.Containers, func(a, b ContainerBinding) int {
+			return cmp.Or(cmp.Compare(a.Pod, b.Pod), cmp.Compare(a.Container, b.Container),
+				cmp.Compare(a.ContainerID, b.ContainerID))
+		})
+		bindings = append(bindings, *binding)
+	}
+	slices.SortFunc(bindings, func(a, b PolicyBinding) int { return cmp.Compare(a.Policy, b.Policy) })
+	return bindings
+}
+
+// forEachBoundContainer calls fn for each container of podCache attached to the policy ID of its policy,
+// or to the grace policy ID when the enforcement of the pod is deferred.
+// This must be called with the resolver lock held.
+func (r *Resolver) forEachBoundContainer(fn func(name NamespacedPolicyName, pod *podEntry, containerID ContainerID,
+	meta *ContainerMeta, policyID PolicyID, deferred bool)) {
+	for _, pod := range r.podCache {
+		policyName := pod.policyName()
+		if policyName == "" || r.isNamespaceExcluded(pod.podNamespace()) {
+			continue
+		}
+		name := pod.podNamespace() + "/" + policyName
+		info := r.wpState[name]
+		if info == nil || !r.serviceAccountAllowed(pod, info) {
+			continue
+		}
+		polByContainer := info.polByContainer
+		deferred := r.enforcementDeferred(pod)
+		if deferred {
+			polByContainer = info.gracePolByContainer
+		}
+		for containerID, meta := range pod.containers {
+			if policyID, ok := polByContainer[r.enforcedPolicyKey(info, meta)]; ok {
+				fn(name, pod, containerID, meta, policyID, deferred)
+			}
+		}
+	}
+}
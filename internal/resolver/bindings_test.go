@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyBindings(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	require.NoError(t, r.ReconcileWP(newTestPolicy("test-ns", "example", c1)))
+	require.NoError(t, r.ReconcileWP(newTestPolicy("test-ns", "unused", c1)))
+
+	// the policies with no container on the node are listed too.
+	require.Equal(t, []PolicyBinding{
+		{Policy: "test-ns/example", Mode: "protect", Containers: []ContainerBinding{}},
+		{Policy: "test-ns/unused", Mode: "protect", Containers: []ContainerBinding{}},
+	}, r.PolicyBindings())
+
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{
+			ID:        "pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{ID: cid1, Name: c1, CgroupID: 100}},
+			// c2 has no rules in the policy, so it is not bound.
+			cid2: {ContainerMeta: ContainerMeta{ID: cid2, Name: c2, CgroupID: 101}},
+		},
+	}))
+	policyID := f.cgroups[100]
+	require.NotEqual(t, PolicyIDNone, policyID)
+	require.Equal(t, []ContainerBinding{
+		{Pod: "test-ns/test-pod", Container: c1, ContainerID: cid1, PolicyID: policyID},
+	}, r.PolicyBindings()[0].Containers)
+
+	require.NoError(t, r.RemovePodContainerFromNri("pod-uid", cid1))
+	require.Empty(t, r.PolicyBindings()[0].Containers)
+}
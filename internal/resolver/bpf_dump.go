@@ -0,0 +1,90 @@
+package resolver
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrPodNotFound is returned by DumpPodBPF when no pod of the resolver has the requested name.
+var ErrPodNotFound = errors.New("pod not found")
+
+// BPFMapsReader reads the content of the BPF maps, it is implemented by bpf.Manager.
+type BPFMapsReader interface {
+	DumpCgroupToPolicy() (map[uint64]uint64, error)
+	DumpPolicyBinaries(policyID uint64) ([]string, error)
+}
+
+// ContainerBPFDump reports the policy enforced on a container, both as known by the resolver
+// and as programmed in the BPF maps.
+type ContainerBPFDump struct {
+	Container ContainerName `json:"container"`
+	CgroupID  CgroupID      `json:"cgroupID"`
+	// Policy and PolicyID are the policy the resolver enforces on the container, they are empty when none.
+	Policy   string   `json:"policy,omitempty"`
+	PolicyID PolicyID `json:"policyID,omitempty"`
+	// InBPF is true when the cgroup is in the cgroup-to-policy BPF map, attached to BPFPolicyID.
+	InBPF       bool     `json:"inBPF"`
+	BPFPolicyID PolicyID `json:"bpfPolicyID,omitempty"`
+	// Binaries are the allowed executables of BPFPolicyID in the BPF maps.
+	Binaries []string `json:"binaries,omitempty"`
+}
+
+// DumpPodBPF returns, for each container of the pod, the policy ID the resolver enforces on it,
+// the one its cgroup is attached to in the BPF maps and the allowed executables of the latter.
+// The BPF maps are read once the resolver lock is released, so the dump doesn't block the updates,
+// but it can be inconsistent with the resolver if an update runs meanwhile.
+func (r *Resolver) DumpPodBPF(namespace, podName string, reader BPFMapsReader) ([]ContainerBPFDump, error) {
+	dumps, err := r.podContainerDumps(namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	cgroups, err := reader.DumpCgroupToPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the cgroups from BPF: %w", err)
+	}
+	for i := range dumps {
+		dump := &dumps[i]
+		dump.BPFPolicyID, dump.InBPF = cgroups[dump.CgroupID]
+		if !dump.InBPF {
+			continue
+		}
+		if dump.Binaries, err = reader.DumpPolicyBinaries(dump.BPFPolicyID); err != nil {
+			return nil, fmt.Errorf("failed to read the executables of policy ID %d from BPF: %w", dump.BPFPolicyID, err)
+		}
+	}
+	return dumps, nil
+}
+
+// podContainerDumps returns the containers of the pod with the policy the resolver enforces on them.
+// A pod recreated with the same name has a new UID: the containers of both pods are returned until the old
+// ones are removed.
+func (r *Resolver) podContainerDumps(namespace, podName string) ([]ContainerBPFDump, error) {
+	defer r.lockTimed(lockOpDumpBPF)()
+
+	var dumps []ContainerBPFDump
+	podFound := false
+	for _, pod := range r.podCache {
+		if pod.podNamespace() != namespace || pod.podName() != podName {
+			continue
+		}
+		podFound = true
+		for _, container := range pod.containers {
+			dump := ContainerBPFDump{Container: container.Name, CgroupID: container.CgroupID}
+			if wc, ok := r.workloadContext(container.CgroupID); ok {
+				dump.Policy, dump.PolicyID = wc.Policy, wc.PolicyID
+			}
+			dumps = append(dumps, dump)
+		}
+	}
+	if !podFound {
+		return nil, fmt.Errorf("%w: %s/%s", ErrPodNotFound, namespace, podName)
+	}
+	slices.SortFunc(dumps, func(a, b ContainerBPFDump) int {
+		return cmp.Or(strings.Compare(a.Container, b.Container), cmp.Compare(a.CgroupID, b.CgroupID))
+	})
+	return dumps, nil
+}
@@ -0,0 +1,76 @@
+package resolver
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (f *fakeBPFMaps) DumpCgroupToPolicy() (map[uint64]uint64, error) {
+	return maps.Clone(f.cgroups), nil
+}
+
+func (f *fakeBPFMaps) DumpPolicyBinaries(policyID uint64) ([]string, error) {
+	return slices.Clone(f.values[policyID]), nil
+}
+
+func TestDumpPodBPF(t *testing.T) {
+	r := NewTestResolver(t)
+	fake := newFakeBPFMaps(r)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				c2: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/ls", "/bin/cat"}}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{ID: cid1, Name: c1, CgroupID: 100}},
+			cid2: {ContainerMeta: ContainerMeta{ID: cid2, Name: c2, CgroupID: 101}},
+		},
+	}))
+	state := r.wpState[wp.NamespacedName()]
+
+	// the cgroup of c2 is missing from the BPF maps.
+	delete(fake.cgroups, 101)
+
+	dumps, err := r.DumpPodBPF("test-ns", "test-pod", fake)
+	require.NoError(t, err)
+	require.Equal(t, []ContainerBPFDump{
+		{
+			Container:   c1,
+			CgroupID:    100,
+			Policy:      "example",
+			PolicyID:    state.polByContainer[c1],
+			InBPF:       true,
+			BPFPolicyID: state.polByContainer[c1],
+			Binaries:    []string{"/bin/sleep"},
+		},
+		{
+			Container: c2,
+			CgroupID:  101,
+			Policy:    "example",
+			PolicyID:  state.polByContainer[c2],
+		},
+	}, dumps)
+
+	_, err = r.DumpPodBPF("test-ns", "missing-pod", fake)
+	require.ErrorIs(t, err, ErrPodNotFound)
+}
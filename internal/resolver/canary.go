@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"cmp"
+	"hash/fnv"
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+// canaryMaxPercent is the percentage at which the canary rollout is over.
+const canaryMaxPercent = 100
+
+// canaryPercent returns the percentage of the pods the declared mode of the policy is enforced on,
+// 0 when the policy is enforced on every pod.
+func canaryPercent(wp *v1alpha1.WorkloadPolicy) int32 {
+	if wp.Spec.CanaryPercent <= 0 || wp.Spec.CanaryPercent >= canaryMaxPercent {
+		return 0
+	}
+	return wp.Spec.CanaryPercent
+}
+
+// canaryRank returns the rank of the pod in the canary selection, derived from its UID.
+// The rank of a pod never changes, so the same pods stay selected when the policy is applied again,
+// and raising the percentage only adds pods to the selection.
+func canaryRank(podID PodID) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(podID))
+	return h.Sum32()
+}
+
+// canarySelected returns the number of pods of a workload enforced by the canary rollout: the percentage
+// rounded up, but never all the pods, so that a missing executable never breaks the whole workload.
+func canarySelected(pods int, percent int32) int {
+	selected := (pods*int(percent) + canaryMaxPercent - 1) / canaryMaxPercent
+	return min(selected, pods-1)
+}
+
+// canaryPeers returns the pods of the node running the same workload as the pod with the same policy,
+// the pod included, in the order of the canary selection.
+// This must be called with the resolver lock held.
+func (r *Resolver) canaryPeers(state *podEntry) []PodID {
+	var peers []PodID
+	for podID, other := range r.podCache {
+		if other.podNamespace() == state.podNamespace() && other.policyName() == state.policyName() &&
+			other.meta.WorkloadType == state.meta.WorkloadType && other.meta.WorkloadName == state.meta.WorkloadName {
+			peers = append(peers, podID)
+		}
+	}
+	slices.SortFunc(peers, func(a, b PodID) int {
+		return cmp.Or(cmp.Compare(canaryRank(a), canaryRank(b)), cmp.Compare(a, b))
+	})
+	return peers
+}
+
+// inCanary returns true when the declared mode of the policy must be enforced on the pod.
+// Each agent selects among the pods of the workload running on its node, the ones with the lowest rank.
+// This must be called with the resolver lock held.
+func (r *Resolver) inCanary(info *wpInfo, state *podEntry) bool {
+	if info.canaryPercent == 0 {
+		return true
+	}
+	peers := r.canaryPeers(state)
+	return slices.Index(peers, state.meta.ID) < canarySelected(len(peers), info.canaryPercent)
+}
+
+// applyPolicyToCanaryPeers applies the policy again to the other pods of the workload of the pod, since adding
+// or removing a pod changes the number of pods the canary rollout selects.
+// This must be called with the resolver lock held.
+func (r *Resolver) applyPolicyToCanaryPeers(state *podEntry) {
+	info := r.wpState[state.podNamespace()+"/"+state.policyName()]
+	if info == nil || info.canaryPercent == 0 {
+		return
+	}
+	for _, podID := range r.canaryPeers(state) {
+		peer := r.podCache[podID]
+		if podID == state.meta.ID || peer == nil {
+			continue
+		}
+		if err := r.applyPolicyToPodIfPresent(peer); err != nil {
+			r.logger.Error("failed to update the canary selection of a pod",
+				"pod", peer.podName(),
+				"namespace", peer.podNamespace(),
+				"policy", peer.policyName(),
+				"error", err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package resolver
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+func newCanaryPolicy(percent int32) *v1alpha1.WorkloadPolicy {
+	wp := newTestPolicy("test-ns", "example", c1)
+	wp.Spec.CanaryPercent = percent
+	return wp
+}
+
+// addCanaryPods adds count pods of the api Deployment with arbitrary UIDs, numbered from first:
+// the cgroup of the i-th pod is 100+i.
+func addCanaryPods(t *testing.T, r *Resolver, first, count int) []PodID {
+	podIDs := make([]PodID, 0, count)
+	for i := first; i < first+count; i++ {
+		podID := PodID(uuid.NewUUID())
+		containerID := ContainerID(fmt.Sprintf("container-%d", i))
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{
+				ID:           podID,
+				Namespace:    "test-ns",
+				Name:         fmt.Sprintf("api-%d", i),
+				WorkloadName: "api",
+				WorkloadType: "Deployment",
+				Labels:       map[string]string{v1alpha1.PolicyLabelKey: "example"},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				containerID: {ContainerMeta: ContainerMeta{ID: containerID, Name: c1, CgroupID: CgroupID(100 + i)}},
+			},
+		}))
+		podIDs = append(podIDs, podID)
+	}
+	return podIDs
+}
+
+// enforcedCanaryPods returns the pods attached to a policy ID in protect mode.
+func enforcedCanaryPods(t *testing.T, f *fakeBPFMaps, podIDs []PodID) []PodID {
+	var pods []PodID
+	for i, podID := range podIDs {
+		polID, ok := f.cgroups[CgroupID(100+i)]
+		if !ok {
+			continue
+		}
+		if f.modes[polID] == policymode.Protect {
+			pods = append(pods, podID)
+		}
+	}
+	return pods
+}
+
+func TestCanaryRollout(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+
+	wp := newCanaryPolicy(50)
+	require.NoError(t, r.ReconcileWP(wp))
+	podIDs := addCanaryPods(t, r, 0, 4)
+	for i := range podIDs {
+		require.Contains(t, f.cgroups, CgroupID(100+i), "every pod must be attached to a policy")
+	}
+	info := r.wpState[wp.NamespacedName()]
+
+	// half of the pods are enforced, the others run in monitor mode with the same executables.
+	selected := enforcedCanaryPods(t, f, podIDs)
+	require.Len(t, selected, 2)
+	gracePolID := info.gracePolByContainer[c1]
+	require.Equal(t, policymode.Monitor, f.modes[gracePolID])
+	require.Equal(t, f.values[info.polByContainer[c1]], f.values[gracePolID])
+	require.Contains(t, r.GetPolicyStatuses()[wp.NamespacedName()].Message, "50%")
+
+	// the selection is stable: applying the policy again keeps the same pods.
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, selected, enforcedCanaryPods(t, f, podIDs))
+
+	// raising the percentage only adds pods.
+	wp.Spec.CanaryPercent = 75
+	require.NoError(t, r.ReconcileWP(wp))
+	raised := enforcedCanaryPods(t, f, podIDs)
+	require.Len(t, raised, 3)
+	for _, podID := range selected {
+		require.Contains(t, raised, podID)
+	}
+
+	// below 100%, a pod of the workload is never enforced.
+	wp.Spec.CanaryPercent = 99
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Len(t, enforcedCanaryPods(t, f, podIDs), 3)
+
+	// the rollout is over: every pod is enforced and the grace policy is released.
+	wp.Spec.CanaryPercent = 0
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, podIDs, enforcedCanaryPods(t, f, podIDs))
+	require.Empty(t, info.gracePolByContainer)
+	require.NotContains(t, f.modes, gracePolID)
+	require.Empty(t, r.GetPolicyStatuses()[wp.NamespacedName()].Message)
+}
+
+func TestCanarySmallWorkloads(t *testing.T) {
+	for _, tt := range []struct {
+		pods     int
+		percent  int32
+		enforced int
+	}{
+		{pods: 1, percent: 50, enforced: 0},
+		{pods: 2, percent: 50, enforced: 1},
+		{pods: 2, percent: 90, enforced: 1},
+		{pods: 3, percent: 10, enforced: 1},
+		{pods: 5, percent: 50, enforced: 3},
+	} {
+		t.Run(fmt.Sprintf("%d pods at %d%%", tt.pods, tt.percent), func(t *testing.T) {
+			r := NewTestResolver(t)
+			f := newFakeBPFMaps(r)
+			require.NoError(t, r.ReconcileWP(newCanaryPolicy(tt.percent)))
+			podIDs := addCanaryPods(t, r, 0, tt.pods)
+			require.Len(t, enforcedCanaryPods(t, f, podIDs), tt.enforced)
+		})
+	}
+}
+
+func TestCanaryPodChanges(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	require.NoError(t, r.ReconcileWP(newCanaryPolicy(50)))
+
+	// the single pod of the workload is not enforced, the second one makes room for the canary.
+	podIDs := addCanaryPods(t, r, 0, 1)
+	require.Empty(t, enforcedCanaryPods(t, f, podIDs))
+	podIDs = append(podIDs, addCanaryPods(t, r, 1, 1)...)
+	require.Len(t, enforcedCanaryPods(t, f, podIDs), 1)
+
+	// removing the enforced pod leaves a single pod of the workload, which is no longer enforced.
+	enforced := enforcedCanaryPods(t, f, podIDs)[0]
+	idx := slices.Index(podIDs, enforced)
+	require.NoError(t, r.RemovePodContainerFromNri(enforced, ContainerID(fmt.Sprintf("container-%d", idx))))
+	require.Empty(t, enforcedCanaryPods(t, f, podIDs))
+}
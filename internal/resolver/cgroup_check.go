@@ -0,0 +1,80 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// ErrInvalidCgroupPath is returned by CheckCgroupPath when the ID of the cgroup cannot be resolved from its path.
+var ErrInvalidCgroupPath = errors.New("invalid cgroup path")
+
+// CgroupCheck reports how a cgroup is enforced, both as known by the resolver and as written into the BPF maps.
+type CgroupCheck struct {
+	Path     string   `json:"path"`
+	CgroupID CgroupID `json:"cgroupID"`
+	// Namespace, Pod and Container identify the container of the cgroup, they are empty when it is unknown.
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Container string `json:"container,omitempty"`
+	// Policy and ExpectedPolicyID are the policy the resolver enforces on the container, they are empty when none.
+	Policy           string   `json:"policy,omitempty"`
+	ExpectedPolicyID PolicyID `json:"expectedPolicyID,omitempty"`
+	// InBPF is true when the cgroup is in the cgroup-to-policy BPF map, attached to BPFPolicyID.
+	InBPF       bool     `json:"inBPF"`
+	BPFPolicyID PolicyID `json:"bpfPolicyID,omitempty"`
+	// Consistent is true when the BPF map agrees with the resolver.
+	Consistent bool   `json:"consistent"`
+	Message    string `json:"message"`
+}
+
+// CheckCgroupPath resolves the ID of the cgroup at path, an absolute path in the cgroup filesystem of the agent,
+// and reports the policy it is attached to in the BPF map against the one the resolver enforces on its container.
+// It answers "is this container enforced, and by which policy" when the enforcement of a container looks off.
+func (r *Resolver) CheckCgroupPath(path string) (CgroupCheck, error) {
+	if !filepath.IsAbs(path) {
+		return CgroupCheck{}, fmt.Errorf("%w: %s is not an absolute path", ErrInvalidCgroupPath, path)
+	}
+	path = filepath.Clean(path)
+	cgID, err := r.cgroupIDFromPathFunc(path)
+	if err != nil {
+		return CgroupCheck{}, fmt.Errorf("%w: %w", ErrInvalidCgroupPath, err)
+	}
+
+	defer r.lockTimed(lockOpCheckCgroup)()
+	check := CgroupCheck{Path: path, CgroupID: cgID}
+	check.BPFPolicyID, check.InBPF, err = r.cgroupPolicyLookupFunc(cgID)
+	if err != nil {
+		return CgroupCheck{}, fmt.Errorf("failed to read the policy of cgroup %d from BPF: %w", cgID, err)
+	}
+	wc, known := r.workloadContext(cgID)
+	if known {
+		check.Namespace, check.Pod, check.Container = wc.Namespace, wc.Pod, wc.Container
+		check.Policy, check.ExpectedPolicyID = wc.Policy, wc.PolicyID
+	}
+	check.Consistent = check.ExpectedPolicyID == check.BPFPolicyID
+	check.Message = check.message(known)
+	return check, nil
+}
+
+func (c *CgroupCheck) message(known bool) string {
+	switch {
+	case !known && !c.InBPF:
+		return "the cgroup is not a container known by the agent, it is not enforced"
+	case !known:
+		return fmt.Sprintf("the cgroup is not a container known by the agent, but it is attached to policy ID %d",
+			c.BPFPolicyID)
+	case c.ExpectedPolicyID == PolicyIDNone && !c.InBPF:
+		return "no policy is enforced on the container"
+	case c.ExpectedPolicyID == PolicyIDNone:
+		return fmt.Sprintf("no policy is enforced on the container, but it is attached to policy ID %d", c.BPFPolicyID)
+	case !c.InBPF:
+		return fmt.Sprintf("the container must be enforced by policy %s (ID %d), but it is missing from the BPF map",
+			c.Policy, c.ExpectedPolicyID)
+	case !c.Consistent:
+		return fmt.Sprintf("the container must be enforced by policy %s (ID %d), but it is attached to policy ID %d",
+			c.Policy, c.ExpectedPolicyID, c.BPFPolicyID)
+	default:
+		return fmt.Sprintf("the container is enforced by policy %s (ID %d)", c.Policy, c.ExpectedPolicyID)
+	}
+}
@@ -0,0 +1,77 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+func TestCheckCgroupPath(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	cgroupIDs := map[string]CgroupID{
+		"/sys/fs/cgroup/kubepods/pod-uid/c1": 100,
+		"/sys/fs/cgroup/system.slice/sshd":   200,
+	}
+	r.cgroupIDFromPathFunc = func(path string) (CgroupID, error) {
+		if cgID, ok := cgroupIDs[path]; ok {
+			return cgID, nil
+		}
+		return 0, errors.New("no such file or directory")
+	}
+	wp := newTestPolicy("test-ns", "check", c1)
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "check"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{ID: cid1, Name: c1, CgroupID: 100}},
+		},
+	}))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+
+	check, err := r.CheckCgroupPath("/sys/fs/cgroup/kubepods/pod-uid/c1/")
+	require.NoError(t, err)
+	require.Equal(t, CgroupCheck{
+		Path:             "/sys/fs/cgroup/kubepods/pod-uid/c1",
+		CgroupID:         100,
+		Namespace:        "test-ns",
+		Pod:              "test-pod",
+		Container:        c1,
+		Policy:           "check",
+		ExpectedPolicyID: polID,
+		InBPF:            true,
+		BPFPolicyID:      polID,
+		Consistent:       true,
+		Message:          "the container is enforced by policy check (ID 1)",
+	}, check)
+
+	// the container is missing from the BPF map, e.g. after a failed update.
+	delete(f.cgroups, CgroupID(100))
+	check, err = r.CheckCgroupPath("/sys/fs/cgroup/kubepods/pod-uid/c1")
+	require.NoError(t, err)
+	require.False(t, check.InBPF)
+	require.False(t, check.Consistent)
+	require.Equal(t, "the container must be enforced by policy check (ID 1), but it is missing from the BPF map",
+		check.Message)
+
+	// a cgroup that is not a container is neither known nor enforced.
+	check, err = r.CheckCgroupPath("/sys/fs/cgroup/system.slice/sshd")
+	require.NoError(t, err)
+	require.Empty(t, check.Container)
+	require.False(t, check.InBPF)
+	require.True(t, check.Consistent)
+	require.Equal(t, "the cgroup is not a container known by the agent, it is not enforced", check.Message)
+
+	_, err = r.CheckCgroupPath("/sys/fs/cgroup/missing")
+	require.ErrorIs(t, err, ErrInvalidCgroupPath)
+	_, err = r.CheckCgroupPath("kubepods/pod-uid/c1")
+	require.ErrorIs(t, err, ErrInvalidCgroupPath)
+}
@@ -0,0 +1,46 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+func TestContainerModeOverride(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+
+	wp := newTestPolicy("test-ns", "sidecar", c1)
+	wp.Spec.RulesByContainer[c2] = &v1alpha1.WorkloadPolicyRules{
+		Mode:        "monitor",
+		Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	info := r.wpState[wp.NamespacedName()]
+	require.Equal(t, policymode.Protect, f.modes[info.polByContainer[c1]])
+	require.Equal(t, policymode.Monitor, f.modes[info.polByContainer[c2]])
+	require.Equal(t, policymode.Monitor, info.keyMode(lifecycleKey(imageScopedKey(c2, "busybox"))))
+
+	// the mode of the policy applies again once the override is removed.
+	wp.Spec.RulesByContainer[c2].Mode = ""
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, policymode.Protect, f.modes[info.polByContainer[c2]])
+	require.Empty(t, info.modeByContainer)
+
+	// a container can be audited while the other ones are protected.
+	wp.Spec.RulesByContainer[c2].Mode = "audit"
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, policymode.Protect, f.modes[info.polByContainer[c1]])
+	require.Equal(t, policymode.Audit, f.modes[info.polByContainer[c2]])
+
+	// the global switch wins over the protect override, while the audited container keeps its mode.
+	wp.Spec.Mode = "monitor"
+	wp.Spec.RulesByContainer[c1].Mode = "protect"
+	r.SetForceMonitorMode(true)
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, policymode.Monitor, f.modes[info.polByContainer[c1]])
+	require.Equal(t, policymode.Audit, f.modes[info.polByContainer[c2]])
+}
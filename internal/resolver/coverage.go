@@ -0,0 +1,105 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+// podCoverage counts the pods of the node by enforcement coverage.
+type podCoverage struct {
+	// tracked is the number of pods with at least one container.
+	tracked int
+	// covered is the number of pods whose containers are all enforced.
+	covered int
+	// partiallyCovered is the number of pods with some of their containers enforced, but not all.
+	partiallyCovered int
+}
+
+func (c podCoverage) ratio() float64 {
+	if c.tracked == 0 {
+		return 0
+	}
+	return float64(c.covered) / float64(c.tracked)
+}
+
+// containerEnforced reports whether the container runs with a policy ID of its policy in protect mode.
+// The pods whose enforcement is deferred (not Ready yet or out of the canary rollout) run with the grace
+// policy IDs in monitor mode, so they are not enforced.
+// This must be called with the resolver lock held.
+func (r *Resolver) containerEnforced(state *podEntry, info *wpInfo, container *ContainerMeta) bool {
+	key := info.policyKey(container)
+	if _, ok := info.polByContainer[key]; !ok || info.keyMode(key) != policymode.Protect {
+		return false
+	}
+	_, hasGrace := info.gracePolByContainer[key]
+	return !hasGrace || !r.enforcementDeferred(state)
+}
+
+// computePodCoverage counts the pods of podCache by enforcement coverage.
+// This must be called with the resolver lock held.
+func (r *Resolver) computePodCoverage() podCoverage {
+	var c podCoverage
+	for _, state := range r.podCache {
+		if len(state.containers) == 0 {
+			continue
+		}
+		c.tracked++
+		policyName := state.policyName()
+		if policyName == "" || r.isNamespaceExcluded(state.podNamespace()) {
+			continue
+		}
+		info := r.wpState[state.podNamespace()+"/"+policyName]
+		if info == nil || !r.serviceAccountAllowed(state, info) {
+			continue
+		}
+		enforced := 0
+		for _, container := range state.containers {
+			if r.containerEnforced(state, info, container) {
+				enforced++
+			}
+		}
+		switch enforced {
+		case 0:
+		case len(state.containers):
+			c.covered++
+		default:
+			c.partiallyCovered++
+		}
+	}
+	return c
+}
+
+// UpdateCoverageMetrics updates the metrics of the enforcement coverage of the pods of the node,
+// along with the number of loaded policies and of cgroups attached to them.
+func (r *Resolver) UpdateCoverageMetrics() {
+	defer r.lockTimed(lockOpCoverage)()
+	c := r.computePodCoverage()
+	trackedPods.Set(float64(c.tracked))
+	coveredPods.Set(float64(c.covered))
+	partiallyCoveredPods.Set(float64(c.partiallyCovered))
+	coveredPodsRatio.Set(c.ratio())
+
+	programmed := 0
+	r.forEachBoundContainer(func(NamespacedPolicyName, *podEntry, ContainerID, *ContainerMeta, PolicyID, bool) {
+		programmed++
+	})
+	policiesLoaded.Set(float64(len(r.wpState)))
+	cgroupsProgrammed.Set(float64(programmed))
+}
+
+// RunCoverageMetrics updates the coverage metrics right away and then every interval until ctx is done.
+func (r *Resolver) RunCoverageMetrics(ctx context.Context, interval time.Duration) error {
+	r.UpdateCoverageMetrics()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.UpdateCoverageMetrics()
+		}
+	}
+}
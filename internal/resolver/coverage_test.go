@@ -0,0 +1,65 @@
+package resolver
+
+import (
+	"testing"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func newCoveragePod(id PodID, policyName string, containerNames ...ContainerName) *podEntry {
+	labels := map[string]string{}
+	if policyName != "" {
+		labels[v1alpha1.PolicyLabelKey] = policyName
+	}
+	pod := &podEntry{
+		meta:       &PodMeta{ID: id, Namespace: "test-ns", Name: id, Labels: labels},
+		containers: make(map[ContainerID]*ContainerMeta),
+	}
+	for i, name := range containerNames {
+		containerID := id + "-" + name
+		pod.containers[containerID] = &ContainerMeta{ID: containerID, Name: name, CgroupID: CgroupID(i + 1)}
+	}
+	return pod
+}
+
+func TestPodCoverage(t *testing.T) {
+	r := NewTestResolver(t)
+	newFakeBPFMaps(r)
+	require.NoError(t, r.ReconcileWP(newTestPolicy("test-ns", "protected", c1)))
+	monitored := newTestPolicy("test-ns", "monitored", c1)
+	monitored.Spec.Mode = "monitor"
+	require.NoError(t, r.ReconcileWP(monitored))
+
+	for _, pod := range []*podEntry{
+		// all the containers are enforced.
+		newCoveragePod("covered", "protected", c1),
+		// c2 has no rules in the policy.
+		newCoveragePod("partial", "protected", c1, c2),
+		newCoveragePod("no-label", "", c1),
+		newCoveragePod("unknown-policy", "missing", c1),
+		// a policy in monitor mode never blocks.
+		newCoveragePod("monitored", "monitored", c1),
+		// the pods with no containers yet are not tracked.
+		newCoveragePod("no-containers", "protected"),
+	} {
+		r.podCache[pod.meta.ID] = pod
+	}
+
+	require.Equal(t, podCoverage{tracked: 5, covered: 1, partiallyCovered: 1}, r.computePodCoverage())
+
+	r.UpdateCoverageMetrics()
+	require.InDelta(t, 5, promtestutil.ToFloat64(trackedPods), 0)
+	require.InDelta(t, 1, promtestutil.ToFloat64(coveredPods), 0)
+	require.InDelta(t, 1, promtestutil.ToFloat64(partiallyCoveredPods), 0)
+	require.InDelta(t, 0.2, promtestutil.ToFloat64(coveredPodsRatio), 1e-9)
+	require.InDelta(t, 2, promtestutil.ToFloat64(policiesLoaded), 0)
+	// the containers with rules in their policy, in monitor mode too.
+	require.InDelta(t, 3, promtestutil.ToFloat64(cgroupsProgrammed), 0)
+
+	// the pods of an excluded namespace are never covered.
+	r.SetExcludedNamespaces([]string{"test-ns"})
+	require.Equal(t, podCoverage{tracked: 5}, r.computePodCoverage())
+	require.Zero(t, podCoverage{}.ratio())
+}
@@ -0,0 +1,77 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+// SetDevMode enables the developer mode, where the allowed executables of a policy can be replaced
+// with SetDevAllowedExecutables. It must be called before the policies are applied.
+func (r *Resolver) SetDevMode(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !enabled {
+		r.devAllowed = nil
+		return
+	}
+	if r.devAllowed == nil {
+		r.devAllowed = make(map[NamespacedPolicyName]map[ContainerName][]string)
+	}
+}
+
+// SetDevAllowedExecutables replaces the allowed executables declared in the spec for a container of a policy,
+// nil restores the declared ones. It is meant for the developer mode only: the policy is applied again right away
+// when it is already known, so that the allow list is replaced in BPF without a round-trip through the API server.
+// The other rules of the policy (profiles, temporary executables, ...) still come from its spec.
+func (r *Resolver) SetDevAllowedExecutables(
+	wpKey NamespacedPolicyName,
+	containerName ContainerName,
+	allowed []string,
+) error {
+	defer r.lockTimed(lockOpReconcilePolicy)()
+
+	if r.devAllowed == nil {
+		return errors.New("the developer mode is not enabled")
+	}
+	if allowed == nil {
+		delete(r.devAllowed[wpKey], containerName)
+		if len(r.devAllowed[wpKey]) == 0 {
+			delete(r.devAllowed, wpKey)
+		}
+	} else {
+		if r.devAllowed[wpKey] == nil {
+			r.devAllowed[wpKey] = make(map[ContainerName][]string)
+		}
+		r.devAllowed[wpKey][containerName] = slices.Clone(allowed)
+	}
+
+	info := r.wpState[wpKey]
+	if info == nil || info.devSpec == nil {
+		// the list is used as soon as the policy is applied.
+		return nil
+	}
+	r.logger.Info("allowed executables changed in developer mode, applying the policy again",
+		"wp", wpKey,
+		"container", containerName,
+		"executables", len(allowed))
+	if err := r.reconcileWP(info.devSpec); err != nil {
+		return fmt.Errorf("failed to apply the allowed executables of wp %s, container %s: %w",
+			wpKey, containerName, err)
+	}
+	return nil
+}
+
+// keepDevSpec keeps a copy of the policy in developer mode, to apply it again when its allowed executables change.
+// This must be called with the resolver lock held.
+func (r *Resolver) keepDevSpec(wp *v1alpha1.WorkloadPolicy, info *wpInfo) {
+	if r.devAllowed == nil {
+		info.devSpec = nil
+		return
+	}
+	if info.devSpec != wp {
+		info.devSpec = wp.DeepCopy()
+	}
+}
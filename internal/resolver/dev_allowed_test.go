@@ -0,0 +1,48 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevAllowedExecutables(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	wp := newTestPolicy("test-ns", "dev", c1)
+	wpKey := wp.NamespacedName()
+
+	require.Error(t, r.SetDevAllowedExecutables(wpKey, c1, []string{"/bin/ls"}))
+	r.SetDevMode(true)
+
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[wpKey].polByContainer[c1]
+	require.Equal(t, []string{"/bin/sleep"}, f.values[polID])
+
+	var ops []bpf.PolicyValuesOperation
+	replace := r.policyUpdateBinariesFunc
+	r.policyUpdateBinariesFunc = func(id PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		ops = append(ops, op)
+		return replace(id, values, op)
+	}
+
+	// the new list replaces the declared one right away, without a new reconcile of the policy.
+	require.NoError(t, r.SetDevAllowedExecutables(wpKey, c1, []string{"/bin/ls", "/bin/cat"}))
+	require.Equal(t, []bpf.PolicyValuesOperation{bpf.ReplaceValuesInPolicy}, ops)
+	require.Equal(t, []string{"/bin/cat", "/bin/ls"}, f.values[polID])
+
+	// the list is kept when the policy is reconciled again.
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/bin/cat", "/bin/ls"}, f.values[polID])
+
+	// nil restores the declared list.
+	require.NoError(t, r.SetDevAllowedExecutables(wpKey, c1, nil))
+	require.Equal(t, []string{"/bin/sleep"}, f.values[polID])
+
+	// a list set before the policy is applied is used as soon as it is.
+	other := newTestPolicy("test-ns", "dev-other", c1)
+	require.NoError(t, r.SetDevAllowedExecutables(other.NamespacedName(), c1, []string{"/bin/true"}))
+	require.NoError(t, r.ReconcileWP(other))
+	require.Equal(t, []string{"/bin/true"}, f.values[r.wpState[other.NamespacedName()].polByContainer[c1]])
+}
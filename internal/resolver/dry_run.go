@@ -0,0 +1,107 @@
+package resolver
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+// maxDryRunExecutables bounds the executables reported for each container in the impact of a dry run,
+// the executions of the others are still counted.
+const maxDryRunExecutables = v1alpha1.MaxDryRunExecutables
+
+const dryRunMsgFormat = "dry run: %d executions would have been blocked since %s"
+
+// DryRunImpact reports the executions of a container that the declared mode of a policy in dry run
+// would have blocked.
+type DryRunImpact struct {
+	// Count is the number of executions that would have been blocked.
+	Count int
+	// Executables contains the sorted executables that would have been blocked, at most maxDryRunExecutables.
+	Executables []string
+}
+
+type dryRunContainer struct {
+	count       int
+	executables map[string]struct{}
+}
+
+// inDryRun returns whether the policy is applied in dry run.
+// Only the policies declared in protect mode block executions, so the others have nothing to report.
+func inDryRun(wp *v1alpha1.WorkloadPolicy) bool {
+	return wp.IsDryRun() && wp.Spec.Mode == policymode.ProtectString
+}
+
+// updateDryRun starts or ends the dry run of the policy according to its annotation.
+// The impact is only collected since the start of the dry run on this agent.
+// This must be called with the resolver lock held.
+func (r *Resolver) updateDryRun(wp *v1alpha1.WorkloadPolicy, info *wpInfo, now time.Time) {
+	dryRun := inDryRun(wp)
+	switch {
+	case dryRun && info.dryRunSince.IsZero():
+		r.logger.Info("dry run started, applying the policy in monitor mode",
+			"wp", wp.NamespacedName(),
+			"declaredMode", wp.Spec.Mode)
+		info.dryRunSince = now
+		info.dryRunImpact = make(map[ContainerName]*dryRunContainer)
+	case !dryRun && !info.dryRunSince.IsZero():
+		r.logger.Info("dry run ended, restoring the declared mode",
+			"wp", wp.NamespacedName(),
+			"declaredMode", wp.Spec.Mode,
+			"wouldBeBlocked", info.dryRunCount())
+		info.dryRunSince = time.Time{}
+		info.dryRunImpact = nil
+	}
+}
+
+// RecordDryRunViolation records an execution reported by a policy in monitor mode.
+// It is counted in the impact of the policy when it is in dry run, since its declared mode would have blocked it.
+func (r *Resolver) RecordDryRunViolation(wpKey NamespacedPolicyName, containerName ContainerName, exe string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info := r.wpState[wpKey]
+	if info == nil || info.dryRunSince.IsZero() {
+		return
+	}
+	impact, ok := info.dryRunImpact[containerName]
+	if !ok {
+		impact = &dryRunContainer{executables: make(map[string]struct{})}
+		info.dryRunImpact[containerName] = impact
+	}
+	impact.count++
+	if len(impact.executables) < maxDryRunExecutables {
+		impact.executables[exe] = struct{}{}
+	}
+}
+
+func (i *wpInfo) dryRunCount() int {
+	count := 0
+	for _, impact := range i.dryRunImpact {
+		count += impact.count
+	}
+	return count
+}
+
+// dryRunImpactByContainer returns the impact of the dry run of the policy, nil when it is not in dry run.
+func (i *wpInfo) dryRunImpactByContainer() map[ContainerName]DryRunImpact {
+	if i.dryRunSince.IsZero() {
+		return nil
+	}
+	impactByContainer := make(map[ContainerName]DryRunImpact, len(i.dryRunImpact))
+	for containerName, impact := range i.dryRunImpact {
+		impactByContainer[containerName] = DryRunImpact{
+			Count:       impact.count,
+			Executables: slices.Sorted(maps.Keys(impact.executables)),
+		}
+	}
+	return impactByContainer
+}
+
+func (i *wpInfo) dryRunMessage() string {
+	return fmt.Sprintf(dryRunMsgFormat, i.dryRunCount(), i.dryRunSince.UTC().Format(time.RFC3339))
+}
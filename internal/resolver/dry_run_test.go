@@ -0,0 +1,57 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	wp := newTestPolicy("test-ns", "dry-run", c1)
+	wp.Annotations = map[string]string{v1alpha1.DryRunAnnotationKey: "true"}
+	key := wp.NamespacedName()
+
+	// a protect policy in dry run is applied in monitor mode: it blocks nothing.
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[key].polByContainer[c1]
+	require.Equal(t, policymode.Monitor, f.modes[polID])
+	require.Equal(t, "protect", wp.Spec.Mode)
+
+	// the violations it reports are counted in its impact.
+	r.RecordDryRunViolation(key, c1, "/usr/bin/curl")
+	r.RecordDryRunViolation(key, c1, "/usr/bin/curl")
+	r.RecordDryRunViolation(key, c1, "/bin/sh")
+	status := r.GetPolicyStatuses()[key]
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_READY, status.State)
+	require.Equal(t, agentv1.PolicyMode_POLICY_MODE_MONITOR, status.Mode)
+	require.Equal(t, map[ContainerName]DryRunImpact{
+		c1: {Count: 3, Executables: []string{"/bin/sh", "/usr/bin/curl"}},
+	}, status.DryRunImpact)
+	require.Contains(t, status.Message, "dry run: 3 executions would have been blocked since ")
+
+	// removing the annotation goes live: the declared mode is enforced and the impact is dropped.
+	delete(wp.Annotations, v1alpha1.DryRunAnnotationKey)
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, policymode.Protect, f.modes[polID])
+	r.RecordDryRunViolation(key, c1, "/usr/bin/curl")
+	status = r.GetPolicyStatuses()[key]
+	require.Nil(t, status.DryRunImpact)
+	require.Empty(t, status.Message)
+}
+
+func TestDryRunMonitorPolicy(t *testing.T) {
+	r := NewTestResolver(t)
+	wp := newTestPolicy("test-ns", "dry-run", c1)
+	wp.Spec.Mode = "monitor"
+	wp.Annotations = map[string]string{v1alpha1.DryRunAnnotationKey: "true"}
+
+	// a monitor policy blocks nothing anyway, so it has no impact to report.
+	require.NoError(t, r.ReconcileWP(wp))
+	r.RecordDryRunViolation(wp.NamespacedName(), c1, "/usr/bin/curl")
+	require.Nil(t, r.GetPolicyStatuses()[wp.NamespacedName()].DryRunImpact)
+}
@@ -0,0 +1,36 @@
+package resolver
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// egressForBPF returns the egress rules as they must be written into BPF, sorted so that they can be compared
+// with the ones last written. The destinations are validated by the admission webhook, the invalid ones
+// are skipped.
+func egressForBPF(network v1alpha1.WorkloadPolicyNetwork) bpf.Egress {
+	var rules []bpf.EgressRule
+	for _, entry := range network.AllowedEgress {
+		prefix, port, err := v1alpha1.ParseEgressDestination(entry)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, bpf.EgressRule{Prefix: prefix, Port: port})
+	}
+	compare := func(a, b bpf.EgressRule) int {
+		return cmp.Or(
+			a.Prefix.Addr().Compare(b.Prefix.Addr()),
+			cmp.Compare(a.Prefix.Bits(), b.Prefix.Bits()),
+			cmp.Compare(a.Port, b.Port),
+		)
+	}
+	slices.SortFunc(rules, compare)
+	return bpf.Egress{Allowed: slices.Compact(rules)}
+}
+
+func egressEqual(a, b bpf.Egress) bool {
+	return slices.Equal(a.Allowed, b.Allowed)
+}
@@ -0,0 +1,46 @@
+package resolver
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+func TestEgressRules(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+
+	wp := newTestPolicy("test-ns", "egress", c1)
+	wp.Spec.RulesByContainer[c1].Network = v1alpha1.WorkloadPolicyNetwork{
+		AllowedEgress: []string{"10.96.0.10:53", "[fd00::/8]:443", "10.0.0.0/8:443", "10.96.0.10:53"},
+	}
+	wp.Spec.RulesByContainer[c2] = &v1alpha1.WorkloadPolicyRules{
+		Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	info := r.wpState[wp.NamespacedName()]
+	polID := info.polByContainer[c1]
+	require.Equal(t, bpf.Egress{Allowed: []bpf.EgressRule{
+		{Prefix: netip.MustParsePrefix("10.0.0.0/8"), Port: 443},
+		{Prefix: netip.MustParsePrefix("10.96.0.10/32"), Port: 53},
+		{Prefix: netip.MustParsePrefix("fd00::/8"), Port: 443},
+	}}, f.egress[polID])
+	require.Equal(t, bpf.PolicyFlagRestrictEgress, f.flags[polID])
+	// the egress rules are scoped to their container.
+	require.NotContains(t, f.egress, info.polByContainer[c2])
+	require.Equal(t, bpf.PolicyFlags(0), f.flags[info.polByContainer[c2]])
+
+	// the rebuild writes the egress rules and their flags again.
+	require.NoError(t, r.RebuildBPFMaps(f))
+	require.Contains(t, f.egress, polID)
+	require.Equal(t, bpf.PolicyFlagRestrictEgress, f.flags[polID])
+
+	wp.Spec.RulesByContainer[c1].Network = v1alpha1.WorkloadPolicyNetwork{}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NotContains(t, f.egress, polID)
+	require.Equal(t, bpf.PolicyFlags(0), f.flags[polID])
+}
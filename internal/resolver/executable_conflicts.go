@@ -0,0 +1,152 @@
+package resolver
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+const (
+	// VerdictAllowed is reported when the executable is in the allow list of the policy container.
+	VerdictAllowed = "allowed"
+	// VerdictReported is reported when the executable would be reported as a violation by a policy in monitor mode.
+	VerdictReported = "reported"
+	// VerdictBlocked is reported when the executable would be blocked by a policy in protect mode.
+	VerdictBlocked = "blocked"
+)
+
+// PolicyVerdict is the verdict of the rules of a policy container on an executable.
+type PolicyVerdict struct {
+	Namespace string `json:"namespace"`
+	Policy    string `json:"policy"`
+	Container string `json:"container"`
+	Mode      string `json:"mode"`
+	Verdict   string `json:"verdict"`
+}
+
+// ExecutableConflict is an executable of an image that is allowed by some of the policies enforced on
+// the containers running the image, and reported or blocked by others.
+type ExecutableConflict struct {
+	Image      string          `json:"image"`
+	Executable string          `json:"executable"`
+	Verdicts   []PolicyVerdict `json:"verdicts"`
+}
+
+// policyContainer identifies the rules of a container in a policy.
+type policyContainer struct {
+	wpKey     NamespacedPolicyName
+	namespace string
+	policy    string
+	container ContainerName
+	// key is the key of the rules in the maps of wpInfo, see wpInfo.policyKey.
+	key ContainerName
+}
+
+// imageKey identifies the image of a container: the digest when the runtime reports it, the reference otherwise.
+func imageKey(meta *ContainerMeta) string {
+	if meta.ImageDigest != "" {
+		return meta.ImageDigest
+	}
+	return meta.Image
+}
+
+// ExecutableConflicts returns, for every image run by containers of the node enforced by different policies,
+// the executables on which those policies disagree, with the verdict of each policy container.
+// The same binary allowed in a workload and blocked in another running the same image is usually the sign
+// of a policy learned from an incomplete run rather than a deliberate choice.
+// The executables are compared as written into BPF, i.e. lowercased for case-insensitive policies.
+func (r *Resolver) ExecutableConflicts() []ExecutableConflict {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rulesByImage := make(map[string]map[policyContainer]struct{})
+	for _, pod := range r.podCache {
+		policyName := pod.policyName()
+		if policyName == "" || r.isNamespaceExcluded(pod.podNamespace()) {
+			continue
+		}
+		wpKey := pod.podNamespace() + "/" + policyName
+		info, ok := r.wpState[wpKey]
+		if !ok || info == nil {
+			continue
+		}
+		for _, meta := range pod.containers {
+			key := info.policyKey(meta)
+			if _, enforced := info.polByContainer[key]; !enforced {
+				continue
+			}
+			image := imageKey(meta)
+			if rulesByImage[image] == nil {
+				rulesByImage[image] = make(map[policyContainer]struct{})
+			}
+			rulesByImage[image][policyContainer{
+				wpKey:     wpKey,
+				namespace: pod.podNamespace(),
+				policy:    policyName,
+				container: meta.Name,
+				key:       key,
+			}] = struct{}{}
+		}
+	}
+
+	// never nil, so that no conflict is encoded as an empty list.
+	conflicts := make([]ExecutableConflict, 0)
+	for image, rules := range rulesByImage {
+		if len(rules) < 2 {
+			continue
+		}
+		conflicts = append(conflicts, r.imageExecutableConflicts(image, rules)...)
+	}
+	slices.SortFunc(conflicts, func(a, b ExecutableConflict) int {
+		return cmp.Or(cmp.Compare(a.Image, b.Image), cmp.Compare(a.Executable, b.Executable))
+	})
+	return conflicts
+}
+
+// imageExecutableConflicts must be called with the resolver lock held.
+func (r *Resolver) imageExecutableConflicts(image string, rules map[policyContainer]struct{}) []ExecutableConflict {
+	allowedBy := make(map[string]map[policyContainer]struct{})
+	for rule := range rules {
+		for _, executable := range r.wpState[rule.wpKey].allowedByContainer[rule.key] {
+			if allowedBy[executable] == nil {
+				allowedBy[executable] = make(map[policyContainer]struct{})
+			}
+			allowedBy[executable][rule] = struct{}{}
+		}
+	}
+
+	var conflicts []ExecutableConflict
+	for executable, allowing := range allowedBy {
+		if len(allowing) == len(rules) {
+			continue
+		}
+		conflict := ExecutableConflict{Image: image, Executable: executable}
+		for rule := range rules {
+			mode := r.wpState[rule.wpKey].keyMode(rule.key)
+			verdict := VerdictAllowed
+			if _, ok := allowing[rule]; !ok {
+				verdict = VerdictReported
+				if mode == policymode.Protect {
+					verdict = VerdictBlocked
+				}
+			}
+			conflict.Verdicts = append(conflict.Verdicts, PolicyVerdict{
+				Namespace: rule.namespace,
+				Policy:    rule.policy,
+				Container: rule.container,
+				Mode:      mode.String(),
+				Verdict:   verdict,
+			})
+		}
+		slices.SortFunc(conflict.Verdicts, func(a, b PolicyVerdict) int {
+			return cmp.Or(
+				cmp.Compare(a.Namespace, b.Namespace),
+				cmp.Compare(a.Policy, b.Policy),
+				cmp.Compare(a.Container, b.Container),
+			)
+		})
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
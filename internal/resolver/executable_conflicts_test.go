@@ -0,0 +1,90 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExecutableConflicts(t *testing.T) {
+	r := NewTestResolver(t)
+
+	newPolicy := func(name, mode string, allowed ...string) *v1alpha1.WorkloadPolicy {
+		return &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode: mode,
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: allowed}},
+				},
+			},
+		}
+	}
+	addPod := func(id PodID, policy string, cgID CgroupID, image string) {
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{
+				ID:        id,
+				Namespace: "test-ns",
+				Name:      string(id),
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: policy},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				ContainerID("cid-" + id): {ContainerMeta: ContainerMeta{
+					ID: ContainerID("cid-" + id), Name: c1, CgroupID: cgID, Image: image,
+				}},
+			},
+		}))
+	}
+
+	require.NoError(t, r.ReconcileWP(newPolicy("web-a", policymode.ProtectString, "/usr/sbin/nginx", "/bin/sh")))
+	require.NoError(t, r.ReconcileWP(newPolicy("web-b", policymode.MonitorString, "/usr/sbin/nginx")))
+	require.NoError(t, r.ReconcileWP(newPolicy("web-c", policymode.ProtectString, "/usr/sbin/nginx", "/bin/cat")))
+	require.NoError(t, r.ReconcileWP(newPolicy("other", policymode.ProtectString, "/bin/app")))
+
+	// Without pods, the policies don't apply to any image.
+	require.Empty(t, r.ExecutableConflicts())
+
+	addPod("pod-a", "web-a", 100, "nginx:1.27")
+	addPod("pod-b", "web-b", 101, "nginx:1.27")
+	addPod("pod-c", "web-c", 102, "nginx:1.27")
+	// A single policy on an image never conflicts with itself, even with several pods.
+	addPod("pod-d", "other", 103, "app:1")
+	addPod("pod-e", "other", 104, "app:1")
+
+	verdict := func(policy, mode, verdict string) PolicyVerdict {
+		return PolicyVerdict{Namespace: "test-ns", Policy: policy, Container: c1, Mode: mode, Verdict: verdict}
+	}
+	require.Equal(t, []ExecutableConflict{
+		{
+			Image:      "nginx:1.27",
+			Executable: "/bin/cat",
+			Verdicts: []PolicyVerdict{
+				verdict("web-a", policymode.ProtectString, VerdictBlocked),
+				verdict("web-b", policymode.MonitorString, VerdictReported),
+				verdict("web-c", policymode.ProtectString, VerdictAllowed),
+			},
+		},
+		{
+			Image:      "nginx:1.27",
+			Executable: "/bin/sh",
+			Verdicts: []PolicyVerdict{
+				verdict("web-a", policymode.ProtectString, VerdictAllowed),
+				verdict("web-b", policymode.MonitorString, VerdictReported),
+				verdict("web-c", policymode.ProtectString, VerdictBlocked),
+			},
+		},
+	}, r.ExecutableConflicts())
+
+	// Once the policies agree, the conflicts are gone.
+	for name, mode := range map[string]string{
+		"web-a": policymode.ProtectString,
+		"web-b": policymode.MonitorString,
+		"web-c": policymode.ProtectString,
+	} {
+		require.NoError(t, r.ReconcileWP(newPolicy(name, mode, "/usr/sbin/nginx", "/bin/sh", "/bin/cat")))
+	}
+	require.Empty(t, r.ExecutableConflicts())
+}
@@ -1,16 +1,42 @@
 package resolver
 
 import (
+	"errors"
 	"fmt"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
 )
 
+// ErrMissingPodUID is returned when a cgroup ID is not associated with any pod,
+// e.g. because the runtime has not reported its container yet.
+var ErrMissingPodUID = errors.New("no pod UID associated with cgroup ID")
+
 func (r *Resolver) GetContainerView(cgID CgroupID) (*ContainerView, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	return r.containerView(cgID)
+}
+
+// GetContainerViews resolves a batch of cgroup IDs under a single acquisition of the resolver lock,
+// the view and the error of each cgroup ID are returned at its index.
+func (r *Resolver) GetContainerViews(cgIDs []CgroupID) ([]*ContainerView, []error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
+	views := make([]*ContainerView, len(cgIDs))
+	errs := make([]error, len(cgIDs))
+	for i, cgID := range cgIDs {
+		views[i], errs[i] = r.containerView(cgID)
+	}
+	return views, errs
+}
+
+// containerView resolves the container running in the cgroup.
+// This must be called with the resolver lock held.
+func (r *Resolver) containerView(cgID CgroupID) (*ContainerView, error) {
 	podID, ok := r.cgroupIDToPodID[cgID]
 	if !ok {
-		return nil, fmt.Errorf("no pod UID associated with cgroup ID: %d", cgID)
+		return nil, fmt.Errorf("%w: %d", ErrMissingPodUID, cgID)
 	}
 
 	pod, ok := r.podCache[podID]
@@ -24,9 +50,12 @@ func (r *Resolver) GetContainerView(cgID CgroupID) (*ContainerView, error) {
 			return &ContainerView{
 				PodMeta: *pod.meta,
 				Meta: ContainerMeta{
-					ID:       containerID,
-					Name:     meta.Name,
-					CgroupID: cgID,
+					ID:          containerID,
+					Name:        meta.Name,
+					CgroupID:    cgID,
+					Image:       meta.Image,
+					ImageDigest: meta.ImageDigest,
+					StartTime:   meta.StartTime,
 				},
 			}, nil
 		}
@@ -35,6 +64,86 @@ func (r *Resolver) GetContainerView(cgID CgroupID) (*ContainerView, error) {
 	return nil, fmt.Errorf("no container associated with cgroup ID: %d in pod ID: %s", cgID, podID)
 }
 
+// GetContainerViewByContainerID resolves a container by its runtime ID,
+// useful to match events of other tools that carry only the container ID.
+func (r *Resolver) GetContainerViewByContainerID(containerID ContainerID) (*ContainerView, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	podID, ok := r.containerIDToPodID[containerID]
+	if !ok {
+		return nil, fmt.Errorf("no pod UID associated with container ID: %s", containerID)
+	}
+
+	pod, ok := r.podCache[podID]
+	if !ok {
+		return nil, fmt.Errorf("no pod entry associated with pod ID: %s (container ID %s)", podID, containerID)
+	}
+
+	meta, ok := pod.containers[containerID]
+	if !ok {
+		return nil, fmt.Errorf("no container associated with container ID: %s in pod ID: %s", containerID, podID)
+	}
+
+	return &ContainerView{
+		PodMeta: *pod.meta,
+		Meta: ContainerMeta{
+			ID:          containerID,
+			Name:        meta.Name,
+			CgroupID:    meta.CgroupID,
+			Image:       meta.Image,
+			ImageDigest: meta.ImageDigest,
+			StartTime:   meta.StartTime,
+		},
+	}, nil
+}
+
+// GetWorkloadContext resolves a cgroup tracker ID to its container and to the policy ID enforced on it,
+// so that the log events of the BPF programs carry the workload context.
+func (r *Resolver) GetWorkloadContext(cgID CgroupID) (bpf.WorkloadContext, bool) {
+	defer r.lockTimed(lockOpResolveEvent)()
+	return r.workloadContext(cgID)
+}
+
+// workloadContext must be called with the resolver lock held.
+func (r *Resolver) workloadContext(cgID CgroupID) (bpf.WorkloadContext, bool) {
+	podID, ok := r.cgroupIDToPodID[cgID]
+	if !ok {
+		return bpf.WorkloadContext{}, false
+	}
+	pod, ok := r.podCache[podID]
+	if !ok {
+		return bpf.WorkloadContext{}, false
+	}
+	for _, meta := range pod.containers {
+		if meta.CgroupID != cgID {
+			continue
+		}
+		wc := bpf.WorkloadContext{
+			Namespace: pod.podNamespace(),
+			Pod:       pod.podName(),
+			Container: meta.Name,
+		}
+		policyName := pod.policyName()
+		if policyName == "" || r.isNamespaceExcluded(wc.Namespace) {
+			return wc, true
+		}
+		info := r.wpState[fmt.Sprintf("%s/%s", wc.Namespace, policyName)]
+		if info != nil && r.serviceAccountAllowed(pod, info) {
+			polByContainer := info.polByContainer
+			if r.enforcementDeferred(pod) {
+				polByContainer = info.gracePolByContainer
+			}
+			if policyID, enforced := polByContainer[r.enforcedPolicyKey(info, meta)]; enforced {
+				wc.Policy = policyName
+				wc.PolicyID = policyID
+			}
+		}
+		return wc, true
+	}
+	return bpf.WorkloadContext{}, false
+}
+
 func (r *Resolver) PodCacheSnapshot() map[PodID]PodView {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -5,7 +5,10 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func generateMockPodEntry(n int) (PodID, *podEntry) {
@@ -55,3 +58,120 @@ func TestPodCacheSnapshot(t *testing.T) {
 	require.NotEqual(t, "updated-env", snapshot[podID1].Meta.Labels["env"])
 	require.NotEqual(t, "updated-container2", snapshot[podID2].Containers[ContainerID("2")].Name)
 }
+
+func TestGetContainerViewByContainerID(t *testing.T) {
+	r := NewTestResolver(t)
+
+	pod := PodInput{
+		Meta: PodMeta{
+			ID:        "pod1",
+			Name:      "pod1",
+			Namespace: "default",
+		},
+		Containers: map[ContainerID]ContainerInput{
+			"container-id-1": {
+				ContainerMeta: ContainerMeta{
+					ID:       "container-id-1",
+					Name:     "container1",
+					CgroupID: CgroupID(42),
+					Image:    "nginx:1.27",
+				},
+				CgroupPath: "/pod1/container1",
+			},
+		},
+	}
+	require.NoError(t, r.AddPodContainerFromNri(pod))
+
+	view, err := r.GetContainerViewByContainerID("container-id-1")
+	require.NoError(t, err)
+	require.Equal(t, pod.Meta, view.PodMeta)
+	require.Equal(t, pod.Containers["container-id-1"].ContainerMeta, view.Meta)
+
+	// the lookup by container ID must be consistent with the one by cgroup ID.
+	byCgroup, err := r.GetContainerView(CgroupID(42))
+	require.NoError(t, err)
+	require.Equal(t, byCgroup, view)
+
+	_, err = r.GetContainerViewByContainerID("unknown")
+	require.ErrorContains(t, err, "no pod UID associated with container ID: unknown")
+
+	// once the container is removed it can no longer be resolved.
+	require.NoError(t, r.RemovePodContainerFromNri("pod1", "container-id-1"))
+	_, err = r.GetContainerViewByContainerID("container-id-1")
+	require.Error(t, err)
+}
+
+func TestGetContainerViews(t *testing.T) {
+	r := NewTestResolver(t)
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{ID: "pod1", Name: "pod1", Namespace: "default"},
+		Containers: map[ContainerID]ContainerInput{
+			"container-id-1": {ContainerMeta: ContainerMeta{ID: "container-id-1", Name: "container1", CgroupID: 42}},
+		},
+	}))
+
+	// the batch lookup matches the single ones, index by index.
+	views, errs := r.GetContainerViews([]CgroupID{42, 43})
+	require.Len(t, views, 2)
+	require.Len(t, errs, 2)
+	view, err := r.GetContainerView(42)
+	require.NoError(t, err)
+	require.NoError(t, errs[0])
+	require.Equal(t, view, views[0])
+	require.ErrorIs(t, errs[1], ErrMissingPodUID)
+	require.Nil(t, views[1])
+}
+
+func TestGetWorkloadContext(t *testing.T) {
+	r := NewTestResolver(t)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "monitor",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"main": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/usr/bin/sleep"}}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+
+	pod := PodInput{
+		Meta: PodMeta{
+			ID:        "pod1",
+			Name:      "pod1",
+			Namespace: "default",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			"main-id": {
+				ContainerMeta: ContainerMeta{ID: "main-id", Name: "main", CgroupID: CgroupID(42)},
+				CgroupPath:    "/pod1/main",
+			},
+			"sidecar-id": {
+				ContainerMeta: ContainerMeta{ID: "sidecar-id", Name: "sidecar", CgroupID: CgroupID(43)},
+				CgroupPath:    "/pod1/sidecar",
+			},
+		},
+	}
+	require.NoError(t, r.AddPodContainerFromNri(pod))
+
+	wc, ok := r.GetWorkloadContext(CgroupID(42))
+	require.True(t, ok)
+	require.Equal(t, bpf.WorkloadContext{
+		Namespace: "default",
+		Pod:       "pod1",
+		Container: "main",
+		Policy:    "example",
+		PolicyID:  r.wpState[wp.NamespacedName()].polByContainer["main"],
+	}, wc)
+	require.NotEqual(t, PolicyIDNone, wc.PolicyID)
+
+	// a container without rules is not enforced, only its pod is reported.
+	wc, ok = r.GetWorkloadContext(CgroupID(43))
+	require.True(t, ok)
+	require.Equal(t, bpf.WorkloadContext{Namespace: "default", Pod: "pod1", Container: "sidecar"}, wc)
+
+	_, ok = r.GetWorkloadContext(CgroupID(44))
+	require.False(t, ok)
+}
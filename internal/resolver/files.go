@@ -0,0 +1,39 @@
+package resolver
+
+import (
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// filesForBPF returns the file rules as they must be written into BPF, sorted so that they can be compared
+// with the ones last written. The paths are never folded, the case-insensitive matching only applies to
+// the executables.
+func filesForBPF(files v1alpha1.WorkloadPolicyFiles) bpf.Files {
+	sorted := func(paths []string) []string {
+		if len(paths) == 0 {
+			return nil
+		}
+		out := slices.Clone(paths)
+		slices.Sort(out)
+		return slices.Compact(out)
+	}
+	return bpf.Files{
+		AllowedRead:  sorted(files.AllowedRead),
+		AllowedWrite: sorted(files.AllowedWrite),
+		DeniedWrite:  sorted(files.DeniedWrite),
+	}
+}
+
+func filesEqual(a, b bpf.Files) bool {
+	return slices.Equal(a.AllowedRead, b.AllowedRead) &&
+		slices.Equal(a.AllowedWrite, b.AllowedWrite) &&
+		slices.Equal(a.DeniedWrite, b.DeniedWrite)
+}
+
+// keyFlags returns the flags last written into BPF for the policy IDs of a policy key,
+// the flags of the policy along with the ones enabling the file and egress rules of the key.
+func (i *wpInfo) keyFlags(key ContainerName) bpf.PolicyFlags {
+	return i.flags | i.filesByContainer[key].Flags() | i.egressByContainer[key].Flags()
+}
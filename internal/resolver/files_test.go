@@ -0,0 +1,46 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+func TestFileRules(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+
+	wp := newTestPolicy("test-ns", "files", c1)
+	wp.Spec.BlockScripts = true
+	wp.Spec.RulesByContainer[c1].Files = v1alpha1.WorkloadPolicyFiles{
+		AllowedWrite: []string{"/tmp/*", "/etc/app/cache/*", "/tmp/*"},
+		DeniedWrite:  []string{"/etc/*"},
+	}
+	wp.Spec.RulesByContainer[c2] = &v1alpha1.WorkloadPolicyRules{
+		Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	info := r.wpState[wp.NamespacedName()]
+	polID := info.polByContainer[c1]
+	require.Equal(t, bpf.Files{
+		AllowedWrite: []string{"/etc/app/cache/*", "/tmp/*"},
+		DeniedWrite:  []string{"/etc/*"},
+	}, f.files[polID])
+	require.Equal(t, bpf.PolicyFlagBlockScripts|bpf.PolicyFlagRestrictWrite|bpf.PolicyFlagDenyWrite, f.flags[polID])
+	// the file rules are scoped to their container.
+	require.NotContains(t, f.files, info.polByContainer[c2])
+	require.Equal(t, bpf.PolicyFlagBlockScripts, f.flags[info.polByContainer[c2]])
+
+	// the rebuild writes the file rules and their flags again.
+	require.NoError(t, r.RebuildBPFMaps(f))
+	require.Contains(t, f.files, polID)
+	require.Equal(t, bpf.PolicyFlagBlockScripts|bpf.PolicyFlagRestrictWrite|bpf.PolicyFlagDenyWrite, f.flags[polID])
+
+	wp.Spec.RulesByContainer[c1].Files = v1alpha1.WorkloadPolicyFiles{}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NotContains(t, f.files, polID)
+	require.Equal(t, bpf.PolicyFlagBlockScripts, f.flags[polID])
+}
@@ -0,0 +1,131 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+// globalDenyList contains the executables denied in every policy, whatever its allow list.
+// An entry starting with `/` denies that path, any other entry denies the executables with that name in any directory.
+type globalDenyList struct {
+	paths map[string]struct{}
+	names map[string]struct{}
+	// foldedPaths and foldedNames are the entries folded with bpf.FoldPathCase,
+	// they are matched against the executables of the policies ignoring the case.
+	foldedPaths map[string]struct{}
+	foldedNames map[string]struct{}
+}
+
+func newGlobalDenyList(entries []string) *globalDenyList {
+	if len(entries) == 0 {
+		return nil
+	}
+	d := &globalDenyList{
+		paths:       make(map[string]struct{}),
+		names:       make(map[string]struct{}),
+		foldedPaths: make(map[string]struct{}),
+		foldedNames: make(map[string]struct{}),
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry, "/") {
+			d.paths[entry] = struct{}{}
+			d.foldedPaths[bpf.FoldPathCase(entry)] = struct{}{}
+		} else {
+			d.names[entry] = struct{}{}
+			d.foldedNames[bpf.FoldPathCase(entry)] = struct{}{}
+		}
+	}
+	return d
+}
+
+// denies reports whether the executable is denied. With caseInsensitive the executable is already folded.
+func (d *globalDenyList) denies(executable string, caseInsensitive bool) bool {
+	if d == nil {
+		return false
+	}
+	paths, names := d.paths, d.names
+	if caseInsensitive {
+		paths, names = d.foldedPaths, d.foldedNames
+	}
+	if _, ok := paths[executable]; ok {
+		return true
+	}
+	_, ok := names[path.Base(executable)]
+	return ok
+}
+
+// withoutGloballyDenied returns the allowed executables without the ones of the global deny list.
+// Only the exact entries are left out, the BPF program checks the global deny list before the allowed prefixes.
+// This must be called with the resolver lock held.
+func (r *Resolver) withoutGloballyDenied(allowed []string, caseInsensitive bool) []string {
+	if !slices.ContainsFunc(allowed, func(executable string) bool {
+		return r.globalDeny.denies(executable, caseInsensitive)
+	}) {
+		return allowed
+	}
+	return slices.DeleteFunc(slices.Clone(allowed), func(executable string) bool {
+		return r.globalDeny.denies(executable, caseInsensitive)
+	})
+}
+
+// SetGlobalDenyList sets the executables denied in every policy, see globalDenyList for the syntax of the entries.
+// The global deny list takes precedence over every rule of the policies: it is written into BPF, where it is
+// checked before the allowed executables and prefixes, and a denied executable is removed from their allow list,
+// so it is blocked in protect mode and reported in monitor mode.
+// The policies already applied are updated right away, the errors don't stop the update of the other ones.
+func (r *Resolver) SetGlobalDenyList(entries []string) error {
+	defer r.lockTimed(lockOpReconcilePolicy)()
+
+	r.globalDeny = newGlobalDenyList(entries)
+	var errs error
+	if err := r.globalDenyUpdateFunc(entries); err != nil {
+		errs = fmt.Errorf("failed to write the global deny list into BPF: %w", err)
+	}
+	for wpKey, info := range r.wpState {
+		for containerName, candidates := range info.candidateByContainer {
+			allowed := r.withoutGloballyDenied(candidates, info.caseInsensitive)
+			if slices.Equal(info.allowedByContainer[containerName], allowed) {
+				continue
+			}
+			if err := r.replaceAllowedInBPF(info, containerName, allowed); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("failed to apply the global deny list to wp %s, container %s: %w",
+					wpKey, containerName, err))
+				continue
+			}
+			info.allowedByContainer[containerName] = slices.Clone(allowed)
+		}
+	}
+	return errs
+}
+
+// replaceAllowedInBPF replaces the allowed executables of the policy IDs of a container, the grace one included.
+// This must be called with the resolver lock held.
+func (r *Resolver) replaceAllowedInBPF(info *wpInfo, containerName ContainerName, allowed []string) error {
+	commands := info.commandsByContainer[containerName]
+	files := info.filesByContainer[containerName]
+	egress := info.egressByContainer[containerName]
+	flags := info.keyFlags(containerName)
+	if polID, ok := info.polByContainer[containerName]; ok {
+		if err := r.upsertPolicyIDInBPF(
+			polID, allowed, commands, files, egress, info.keyMode(containerName), flags,
+			info.execLimit, info.fsMagics, bpf.ReplaceValuesInPolicy,
+		); err != nil {
+			return err
+		}
+	}
+	if polID, ok := info.gracePolByContainer[containerName]; ok {
+		if err := r.upsertPolicyIDInBPF(
+			polID, allowed, commands, files, egress, policymode.Monitor, flags,
+			info.execLimit, info.fsMagics, bpf.ReplaceValuesInPolicy,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalDenyList(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	wp := newTestPolicy("test-ns", "global-deny", c1)
+	wp.Spec.RulesByContainer[c1].Executables.Allowed = []string{"/bin/sleep", "/usr/bin/nc", "/opt/xmrig"}
+
+	require.NoError(t, r.SetGlobalDenyList([]string{"xmrig"}))
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+	// the globally denied executable is left out of the allow list, so the BPF program blocks it.
+	require.Equal(t, []string{"/bin/sleep", "/usr/bin/nc"}, f.values[polID])
+
+	// an allowed prefix doesn't allow a denied name: the deny list is written into BPF, where it is checked first.
+	wp.Spec.RulesByContainer[c1].Executables.Allowed = []string{"/bin/sleep", "/opt/*"}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/bin/sleep", "/opt/*"}, f.values[polID])
+	require.Equal(t, []string{"xmrig"}, f.globalDeny)
+	wp.Spec.RulesByContainer[c1].Executables.Allowed = []string{"/bin/sleep", "/usr/bin/nc", "/opt/xmrig"}
+	require.NoError(t, r.ReconcileWP(wp))
+
+	// a change of the deny list is applied to the policies already applied.
+	require.NoError(t, r.SetGlobalDenyList([]string{"/usr/bin/nc", "xmrig"}))
+	require.Equal(t, []string{"/bin/sleep"}, f.values[polID])
+	require.Equal(t, []string{"/usr/bin/nc", "xmrig"}, f.globalDeny)
+
+	// the deny list takes precedence over the always allowed executables too.
+	r.SetAlwaysAllowedExecutables([]string{"/usr/bin/nc"})
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/bin/sleep"}, f.values[polID])
+
+	// the deny list matches ignoring the case when the policy does.
+	wp.Spec.CaseInsensitiveMatching = true
+	wp.Spec.RulesByContainer[c1].Executables.Allowed = []string{"/bin/sleep", "/opt/XMRig"}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/bin/sleep"}, f.values[polID])
+
+	// an empty deny list restores the allow list of the policy.
+	require.NoError(t, r.SetGlobalDenyList(nil))
+	require.Empty(t, f.globalDeny)
+	require.Equal(t, []string{"/bin/sleep", "/opt/xmrig", "/usr/bin/nc"}, f.values[polID])
+}
@@ -0,0 +1,102 @@
+package resolver
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+// imageScopeSeparator separates the container name from the image in the key of an image scoped policy.
+// The container names are DNS labels, so they never contain it.
+const imageScopeSeparator = "@"
+
+// imageScopedKey returns the key, in the policy ID maps of wpInfo, of the policy enforced on the containers
+// running the image. It is enforced instead of the policy of the container, with the executables of the image
+// scoped entry on top of the ones of the container.
+func imageScopedKey(containerName ContainerName, image string) ContainerName {
+	return containerName + imageScopeSeparator + image
+}
+
+// splitPolicyKey returns the container name and the image of a policy key, scoped is false
+// for the policy of the container. The lifecycle keys are split like their policy key.
+func splitPolicyKey(key ContainerName) (ContainerName, string, bool) {
+	return strings.Cut(trimLifecycleKey(key), imageScopeSeparator)
+}
+
+// uniqueImageScopes returns the image scoped entries, without the ones whose image is already selected
+// by a previous entry: the first entry matching an image wins, so they would never be enforced.
+func uniqueImageScopes(scopes []v1alpha1.ImageScopedExecutables) []v1alpha1.ImageScopedExecutables {
+	unique := make([]v1alpha1.ImageScopedExecutables, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.ContainsFunc(unique, func(s v1alpha1.ImageScopedExecutables) bool { return s.Image == scope.Image }) {
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
+
+// imageScopesByContainer returns the images of the image scoped entries of each container of the policy,
+// in the order they are matched.
+func imageScopesByContainer(wp *v1alpha1.WorkloadPolicy) map[ContainerName][]string {
+	scopes := make(map[ContainerName][]string)
+	for containerName, rules := range wp.Spec.RulesByContainer {
+		for _, scope := range uniqueImageScopes(rules.Executables.ImageScoped) {
+			scopes[containerName] = append(scopes[containerName], scope.Image)
+		}
+	}
+	return scopes
+}
+
+// imageScopeMatches reports whether the image of an image scoped entry selects the container,
+// see v1alpha1.ImageScopedExecutables for the syntax.
+func imageScopeMatches(image string, container *ContainerMeta) bool {
+	reference, digest, _ := strings.Cut(container.Image, "@")
+	switch {
+	case strings.HasPrefix(image, "sha256:"):
+		return image == container.ImageDigest || image == digest
+	case strings.ContainsAny(image, "/:"):
+		return image == container.Image || image == reference
+	default:
+		return image == imageTag(reference)
+	}
+}
+
+// imageTag returns the tag of an image reference with no digest, empty if it has none.
+func imageTag(reference string) string {
+	name := reference[strings.LastIndex(reference, "/")+1:]
+	_, tag, _ := strings.Cut(name, ":")
+	return tag
+}
+
+// policyKey returns the key of the policy enforced on the container: the one of the first image scoped entry
+// matching its image, or its name when none matches. The executables of the image scoped entries are then not
+// allowed in the container.
+func (i *wpInfo) policyKey(container *ContainerMeta) ContainerName {
+	for _, image := range i.imageScopesByContainer[container.Name] {
+		if imageScopeMatches(image, container) {
+			return imageScopedKey(container.Name, image)
+		}
+	}
+	return container.Name
+}
+
+// specHasPolicyKey reports whether the policy key still refers to a container of the spec,
+// and to one of its image scoped entries for an image scoped key.
+func specHasPolicyKey(wp *v1alpha1.WorkloadPolicy, key ContainerName) bool {
+	containerName, image, scoped := splitPolicyKey(key)
+	rules, ok := wp.Spec.RulesByContainer[containerName]
+	if !ok || !scoped {
+		return ok
+	}
+	return slices.ContainsFunc(rules.Executables.ImageScoped, func(s v1alpha1.ImageScopedExecutables) bool {
+		return s.Image == image
+	})
+}
+
+// policyKeyEnforcedOn reports whether the policy of the key may be enforced on the container:
+// the key is the one of the container or of an image scoped entry matching its image.
+func policyKeyEnforcedOn(key ContainerName, container *ContainerMeta) bool {
+	containerName, image, scoped := splitPolicyKey(key)
+	return containerName == container.Name && (!scoped || imageScopeMatches(image, container))
+}
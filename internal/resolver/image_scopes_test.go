@@ -0,0 +1,79 @@
+package resolver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+func TestImageScopedExecutables(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	wp := newTestPolicy("test-ns", "image-scoped", c1)
+	wp.Spec.RulesByContainer[c1].Executables.ImageScoped = []v1alpha1.ImageScopedExecutables{
+		{Image: "v1", Allowed: []string{"/usr/bin/legacy-migrate"}},
+		{Image: "registry.example.com/app:v2", Allowed: []string{"/usr/bin/migrate"}},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+
+	// the pods of a rolling upgrade: the old version, the new one and an unknown one.
+	for i, image := range []string{
+		"registry.example.com/app:v1",
+		"registry.example.com/app:v2@sha256:4f0c",
+		"registry.example.com/app:v3",
+	} {
+		containerID := ContainerID(fmt.Sprintf("container-%d", i))
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{
+				ID:        PodID(fmt.Sprintf("pod-uid-%d", i)),
+				Namespace: "test-ns",
+				Name:      fmt.Sprintf("test-pod-%d", i),
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: "image-scoped"},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				containerID: {ContainerMeta: ContainerMeta{
+					ID: containerID, Name: c1, Image: image, CgroupID: CgroupID(100 + i),
+				}},
+			},
+		}))
+	}
+	allowedIn := func(cgroupID CgroupID) []string {
+		polID, ok := f.cgroups[cgroupID]
+		require.True(t, ok, "every pod must be attached to a policy")
+		return f.values[polID]
+	}
+
+	// each version gets the executables of its image scoped entry on top of the ones of the container.
+	require.Equal(t, []string{"/bin/sleep", "/usr/bin/legacy-migrate"}, allowedIn(100))
+	require.Equal(t, []string{"/bin/sleep", "/usr/bin/migrate"}, allowedIn(101))
+	// no entry matches the unknown version, the executables of the entries are not allowed.
+	require.Equal(t, []string{"/bin/sleep"}, allowedIn(102))
+
+	// the pods of a removed entry are moved back to the policy of the container.
+	wp.Spec.RulesByContainer[c1].Executables.ImageScoped = wp.Spec.RulesByContainer[c1].Executables.ImageScoped[1:]
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/bin/sleep"}, allowedIn(100))
+	require.Equal(t, []string{"/bin/sleep", "/usr/bin/migrate"}, allowedIn(101))
+	require.Len(t, f.values, 2, "the policy ID of the removed entry must be released")
+}
+
+func TestImageScopeMatches(t *testing.T) {
+	container := &ContainerMeta{
+		Image:       "registry.example.com/team/app:v2.1.0@sha256:4f0c",
+		ImageDigest: "sha256:4f0c",
+	}
+	for image, expected := range map[string]bool{
+		"sha256:4f0c":                          true,
+		"sha256:9e1b":                          false,
+		"registry.example.com/team/app:v2.1.0": true,
+		"registry.example.com/team/app:v2.1.0@sha256:4f0c": true,
+		"registry.example.com/team/app:v2.0.0":             false,
+		"v2.1.0":                                           true,
+		"v2":                                               false,
+	} {
+		require.Equal(t, expected, imageScopeMatches(image, container), image)
+	}
+}
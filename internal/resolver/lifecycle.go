@@ -0,0 +1,94 @@
+package resolver
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// lifecycleKeySuffix ends the key, in the policy ID maps of wpInfo, of the policy enforced on a container
+// during its startup phase. The container names and the images never contain it.
+const lifecycleKeySuffix = "#lifecycle"
+
+// lifecycleKey returns the key of the policy enforced on the containers of a policy key during their startup phase:
+// the lifecycle executables are allowed on top of the executables of the key.
+func lifecycleKey(key ContainerName) ContainerName {
+	return key + lifecycleKeySuffix
+}
+
+// trimLifecycleKey returns the policy key of a lifecycle key, the key itself for the other keys.
+func trimLifecycleKey(key ContainerName) ContainerName {
+	return strings.TrimSuffix(key, lifecycleKeySuffix)
+}
+
+// SetLifecycleExecutables sets the executables allowed in the containers during their startup phase,
+// e.g. the binaries run by their postStart hook. The startup phase of a container lasts until
+// MarkContainerStarted reports it as started, no executable disables it.
+// It must be called before the policies are reconciled.
+func (r *Resolver) SetLifecycleExecutables(executables []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lifecycleExecutables = slices.Clone(executables)
+	slices.Sort(r.lifecycleExecutables)
+	r.lifecycleExecutables = slices.Compact(r.lifecycleExecutables)
+}
+
+// MarkContainerStarted records that the kubelet reported a container as running. The kubelet does it only once
+// the postStart hook of the container completed: the container leaves its startup phase and the lifecycle
+// executables are not allowed anymore. A restarted container gets a new ID, so it goes through the startup
+// phase again.
+func (r *Resolver) MarkContainerStarted(podID PodID, containerID ContainerID) error {
+	defer r.lockTimed(lockOpMarkStarted)()
+
+	if len(r.lifecycleExecutables) == 0 {
+		return nil
+	}
+	if _, ok := r.startedContainers[containerID]; ok {
+		return nil
+	}
+	// the container is recorded also when NRI has not reported it yet, e.g. on the restart of the agent.
+	r.startedContainers[containerID] = podID
+
+	state, ok := r.podCache[podID]
+	if !ok {
+		return nil
+	}
+	container, ok := state.containers[containerID]
+	if !ok {
+		return nil
+	}
+	r.logger.Info("container started, the lifecycle executables are not allowed anymore",
+		"pod", state.podName(),
+		"namespace", state.podNamespace(),
+		"container", container.Name)
+	return r.applyPolicyToPodIfPresent(state)
+}
+
+// forgetStartedContainers drops the started containers of a pod deleted from the cluster.
+// This must be called with the resolver lock held.
+func (r *Resolver) forgetStartedContainers(podID PodID) {
+	maps.DeleteFunc(r.startedContainers, func(_ ContainerID, id PodID) bool {
+		return id == podID
+	})
+}
+
+// inStartupPhase reports whether the container runs with the policy of its lifecycle key.
+// This must be called with the resolver lock held.
+func (r *Resolver) inStartupPhase(container *ContainerMeta) bool {
+	if len(r.lifecycleExecutables) == 0 {
+		return false
+	}
+	_, started := r.startedContainers[container.ID]
+	return !started
+}
+
+// enforcedPolicyKey returns the key of the policy enforced on the container, see wpInfo.policyKey,
+// or its lifecycle key during the startup phase of the container.
+// This must be called with the resolver lock held.
+func (r *Resolver) enforcedPolicyKey(info *wpInfo, container *ContainerMeta) ContainerName {
+	key := info.policyKey(container)
+	if r.inStartupPhase(container) {
+		return lifecycleKey(key)
+	}
+	return key
+}
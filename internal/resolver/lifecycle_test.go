@@ -0,0 +1,77 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+func TestLifecycleExecutables(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	r.SetLifecycleExecutables([]string{"/usr/local/bin/post-start-hook"})
+	wp := newTestPolicy("test-ns", "lifecycle", c1)
+	require.NoError(t, r.ReconcileWP(wp))
+
+	const cgID = CgroupID(100)
+	addContainer := func(containerID ContainerID, cgroupID CgroupID) {
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{
+				ID:        "test-pod-uid",
+				Namespace: "test-ns",
+				Name:      "test-pod",
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: "lifecycle"},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				containerID: {ContainerMeta: ContainerMeta{ID: containerID, Name: c1, CgroupID: cgroupID}},
+			},
+		}))
+	}
+	allowedIn := func(cgroupID CgroupID) []string {
+		polID, ok := f.cgroups[cgroupID]
+		require.True(t, ok, "the container must be attached to a policy")
+		return f.values[polID]
+	}
+	addContainer(cid1, cgID)
+
+	// the postStart hook runs while the container is starting: its binary is allowed, in the declared mode.
+	require.Equal(t, []string{"/bin/sleep", "/usr/local/bin/post-start-hook"}, allowedIn(cgID))
+	require.Equal(t, policymode.Protect, f.modes[f.cgroups[cgID]])
+
+	// once the kubelet reports the container as running, the hook binary is not allowed anymore.
+	require.NoError(t, r.MarkContainerStarted("test-pod-uid", cid1))
+	require.Equal(t, []string{"/bin/sleep"}, allowedIn(cgID))
+
+	// a policy update keeps the started container on its policy.
+	wp.Spec.RulesByContainer[c1].Executables.Allowed = []string{"/bin/sleep", "/bin/cat"}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/bin/cat", "/bin/sleep"}, allowedIn(cgID))
+
+	// the restarted container gets a new ID: it goes through the startup phase again.
+	require.NoError(t, r.RemovePodContainerFromNri("test-pod-uid", cid1))
+	addContainer(cid2, cgID+1)
+	require.Equal(t, []string{"/bin/cat", "/bin/sleep", "/usr/local/bin/post-start-hook"}, allowedIn(cgID+1))
+
+	// the start reported before the NRI event of the container is kept.
+	require.NoError(t, r.MarkContainerStarted("test-pod-uid", "container-3"))
+	addContainer("container-3", cgID+2)
+	require.Equal(t, []string{"/bin/cat", "/bin/sleep"}, allowedIn(cgID+2))
+
+	// the lifecycle policy ID is released with the container policy.
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, f.values)
+}
+
+func TestLifecycleExecutablesDisabled(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	require.NoError(t, r.ReconcileWP(newTestPolicy("test-ns", "no-lifecycle", c1)))
+
+	// without lifecycle executables the containers have no startup phase.
+	require.Len(t, f.values, 1)
+	require.NoError(t, r.MarkContainerStarted("test-pod-uid", cid1))
+	require.Empty(t, r.startedContainers)
+}
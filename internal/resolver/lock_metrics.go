@@ -0,0 +1,68 @@
+package resolver
+
+import "time"
+
+// Operations reported in the lock metrics, one per hot path taking the resolver lock.
+const (
+	lockOpAddPodContainer    = "add-pod-container"
+	lockOpRemovePodContainer = "remove-pod-container"
+	lockOpMarkPodReady       = "mark-pod-ready"
+	lockOpMarkStarted        = "mark-container-started"
+	lockOpMarkTerminating    = "mark-pod-terminating"
+	lockOpSetServiceAccount  = "set-pod-service-account"
+	lockOpReconcilePolicy    = "reconcile-policy"
+	lockOpDeletePolicy       = "delete-policy"
+	lockOpApplyPolicyChanges = "apply-policy-changes"
+	lockOpResolveEvent       = "resolve-event"
+	lockOpRebuildMaps        = "rebuild-maps"
+	lockOpCoverage           = "coverage"
+	lockOpCheckCgroup        = "check-cgroup"
+	lockOpDumpBPF            = "dump-bpf"
+	lockOpBindings           = "bindings"
+)
+
+// SetLockHoldWarnThreshold logs a warning each time an operation holds the resolver lock longer than threshold,
+// 0 disables the warning. It must be called before the resolver is used.
+func (r *Resolver) SetLockHoldWarnThreshold(threshold time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lockHoldWarnThreshold = threshold
+}
+
+// writesMaps reports whether the operation can write into the BPF maps.
+func writesMaps(operation string) bool {
+	switch operation {
+	case lockOpResolveEvent, lockOpCoverage, lockOpCheckCgroup, lockOpDumpBPF, lockOpBindings:
+		return false
+	default:
+		return true
+	}
+}
+
+// lockTimed acquires the resolver lock for operation and returns the function releasing it.
+// The operations writing into the BPF maps wait for the map update rate limit first, see SetMapUpdateRateLimit.
+// The time spent waiting for the lock and holding it are recorded in the lock metrics: while an operation holds
+// the lock, the pods being added are not enforced yet, so long holds show up as enforcement latency.
+func (r *Resolver) lockTimed(operation string) func() {
+	if r.mapUpdateThrottle != nil && writesMaps(operation) {
+		r.mapUpdateThrottle.wait()
+	}
+	start := time.Now()
+	r.mu.Lock()
+	acquired := time.Now()
+	lockWaitSeconds.WithLabelValues(operation).Observe(acquired.Sub(start).Seconds())
+
+	return func() {
+		held := time.Since(acquired)
+		threshold := r.lockHoldWarnThreshold
+		r.mu.Unlock()
+
+		lockHoldSeconds.WithLabelValues(operation).Observe(held.Seconds())
+		if threshold > 0 && held > threshold {
+			r.logger.Warn("resolver lock held longer than the threshold",
+				"operation", operation,
+				"held", held,
+				"threshold", threshold)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package resolver
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+)
+
+func lockHoldSample(t *testing.T, operation string) (uint64, float64) {
+	t.Helper()
+	var m dto.Metric
+	observer, err := lockHoldSeconds.GetMetricWithLabelValues(operation)
+	require.NoError(t, err)
+	require.NoError(t, observer.(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+func TestLockHoldMetrics(t *testing.T) {
+	const slowWrite = 50 * time.Millisecond
+
+	r := NewTestResolver(t)
+	var logs bytes.Buffer
+	r.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	r.SetLockHoldWarnThreshold(10 * time.Millisecond)
+
+	// a slow BPF syscall keeps the lock held while the policy is applied.
+	update := r.policyUpdateBinariesFunc
+	r.policyUpdateBinariesFunc = func(id PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		time.Sleep(slowWrite)
+		return update(id, values, op)
+	}
+
+	countBefore, sumBefore := lockHoldSample(t, lockOpReconcilePolicy)
+	require.NoError(t, r.ReconcileWP(newTestPolicy("test-ns", "slow", c1)))
+	count, sum := lockHoldSample(t, lockOpReconcilePolicy)
+
+	require.Equal(t, countBefore+1, count)
+	require.GreaterOrEqual(t, sum-sumBefore, slowWrite.Seconds())
+	require.Contains(t, logs.String(), "resolver lock held longer than the threshold")
+	require.Contains(t, logs.String(), "operation="+lockOpReconcilePolicy)
+
+	// the batched changes are reported under their own operation.
+	countBefore, _ = lockHoldSample(t, lockOpApplyPolicyChanges)
+	require.Empty(t, r.ApplyWPChanges([]WPChange{{Policy: newTestPolicy("test-ns", "batched", c1)}}))
+	count, _ = lockHoldSample(t, lockOpApplyPolicyChanges)
+	require.Equal(t, countBefore+1, count)
+}
@@ -0,0 +1,94 @@
+package resolver
+
+import (
+	"fmt"
+
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+)
+
+// MapFullAction defines how a policy is handled when its allowed executables don't fit in the BPF maps.
+type MapFullAction string
+
+const (
+	// MapFullReject rejects the policy: its policy IDs are released, so that it is never partially enforced,
+	// and its status reports the capacity error.
+	MapFullReject MapFullAction = "reject"
+	// MapFullEvictLRU releases the policy IDs of the least recently applied policy to make room, then retries once.
+	// It is not generally safe: the pods of the evicted policy are no longer enforced until it is applied again.
+	MapFullEvictLRU MapFullAction = "evict-lru"
+)
+
+const evictedMsg = "evicted from the BPF maps to make room for another policy, the policy is not enforced"
+
+// ParseMapFullAction parses the name of a MapFullAction.
+func ParseMapFullAction(s string) (MapFullAction, error) {
+	switch action := MapFullAction(s); action {
+	case MapFullReject, MapFullEvictLRU:
+		return action, nil
+	default:
+		return "", fmt.Errorf("invalid map full action %q, must be one of: reject|evict-lru", s)
+	}
+}
+
+// SetMapFullAction sets how a policy is handled when its allowed executables don't fit in the BPF maps.
+// It must be called before the policies are reconciled.
+func (r *Resolver) SetMapFullAction(action MapFullAction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mapFullAction = action
+}
+
+// SetPolicyMapCapacity sets the maximum number of policy IDs the BPF maps can hold, used to report their utilization.
+func (r *Resolver) SetPolicyMapCapacity(capacity uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policyMapCapacity = capacity
+	r.updatePolicyMapUtilization()
+}
+
+// updatePolicyMapUtilization reports the fraction of the BPF map capacity used by the allocated policy IDs.
+// A policy ID takes at most one entry in each map, so this is an upper bound of the utilization of the fullest one.
+// This must be called with the resolver lock held.
+func (r *Resolver) updatePolicyMapUtilization() {
+	if r.policyMapCapacity == 0 {
+		return
+	}
+	var used int
+	for _, info := range r.wpState {
+		used += len(info.polByContainer) + len(info.gracePolByContainer)
+	}
+	policyMapUtilization.Set(float64(used) / float64(r.policyMapCapacity))
+}
+
+// evictLeastRecentlyApplied releases the policy IDs of the policy applied least recently, other than wpKey.
+// It returns false when there is no policy to evict.
+// This must be called with the resolver lock held.
+func (r *Resolver) evictLeastRecentlyApplied(wpKey NamespacedPolicyName) (bool, error) {
+	var victimKey NamespacedPolicyName
+	var victim *wpInfo
+	for key, info := range r.wpState {
+		if key == wpKey || len(info.polByContainer) == 0 {
+			continue
+		}
+		if victim == nil || info.lastApplied.Before(victim.lastApplied) {
+			victimKey, victim = key, info
+		}
+	}
+	if victim == nil {
+		return false, nil
+	}
+	r.logger.Warn("BPF maps full, evicting the least recently applied policy",
+		"wp", wpKey,
+		"evicted", victimKey)
+	if err := r.releasePolicyIDs(victimKey, victim); err != nil {
+		return false, fmt.Errorf("failed to evict wp %s: %w", victimKey, err)
+	}
+	clear(victim.allowedByContainer)
+	clear(victim.candidateByContainer)
+	clear(victim.commandsByContainer)
+	clear(victim.filesByContainer)
+	clear(victim.egressByContainer)
+	victim.setPolicyStatus(agentv1.PolicyState_POLICY_STATE_ERROR, victim.status.Mode, evictedMsg)
+	victim.status.Rejected = true
+	return true, nil
+}
@@ -0,0 +1,118 @@
+package resolver
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// newMapFullResolver returns a resolver whose BPF maps hold at most capacity policy IDs.
+func newMapFullResolver(t *testing.T, capacity uint32) (*Resolver, *fakeBPFMaps) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	update := r.policyUpdateBinariesFunc
+	r.policyUpdateBinariesFunc = func(id PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		if op == bpf.AddValuesToPolicy && len(f.values) >= int(capacity) {
+			return fmt.Errorf("failed to insert inner policy (id=%d) map: %w", id, bpf.ErrPolicyMapFull)
+		}
+		return update(id, values, op)
+	}
+	r.SetPolicyMapCapacity(capacity)
+	return r, f
+}
+
+func TestParseMapFullAction(t *testing.T) {
+	action, err := ParseMapFullAction("evict-lru")
+	require.NoError(t, err)
+	require.Equal(t, MapFullEvictLRU, action)
+	_, err = ParseMapFullAction("drop")
+	require.ErrorContains(t, err, "must be one of: reject|evict-lru")
+}
+
+func TestMapFullReject(t *testing.T) {
+	r, f := newMapFullResolver(t, 1)
+	first := newTestPolicy("test-ns", "first", c1)
+	second := newTestPolicy("test-ns", "second", c1)
+
+	require.NoError(t, r.ReconcileWP(first))
+	require.InDelta(t, 1, promtestutil.ToFloat64(policyMapUtilization), 0)
+	before := promtestutil.ToFloat64(policyMapFullTotal)
+
+	err := r.ReconcileWP(second)
+	require.ErrorIs(t, err, bpf.ErrPolicyMapFull)
+	require.InDelta(t, before+1, promtestutil.ToFloat64(policyMapFullTotal), 0)
+
+	// the capacity error is surfaced in the status and the rejected policy holds no policy ID.
+	status := r.GetPolicyStatuses()[second.NamespacedName()]
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_ERROR, status.State)
+	require.Contains(t, status.Message, "policy rejected")
+	require.Contains(t, status.Message, bpf.ErrPolicyMapFull.Error())
+	require.True(t, status.Rejected)
+	require.Empty(t, r.wpState[second.NamespacedName()].polByContainer)
+	require.Len(t, f.values, 1)
+	require.Len(t, f.modes, 1)
+
+	// the policies already applied are untouched.
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_READY, r.GetPolicyStatuses()[first.NamespacedName()].State)
+	require.Contains(t, f.values, r.wpState[first.NamespacedName()].polByContainer[c1])
+}
+
+func TestMapFullRejectReleasesCreatedPolicyIDs(t *testing.T) {
+	r, f := newMapFullResolver(t, 1)
+	wp := newTestPolicy("test-ns", "two-containers", c1)
+	wp.Spec.RulesByContainer[c2] = &v1alpha1.WorkloadPolicyRules{
+		Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/ls"}},
+	}
+
+	// the policy ID of the first container fits, the one of the second doesn't.
+	err := r.ReconcileWP(wp)
+	require.ErrorIs(t, err, bpf.ErrPolicyMapFull)
+
+	// the policy ID created for the first container is released with the rejected policy.
+	info := r.wpState[wp.NamespacedName()]
+	require.Empty(t, info.polByContainer)
+	require.Empty(t, info.allowedByContainer)
+	require.Empty(t, f.values)
+	require.Empty(t, f.modes)
+	require.Zero(t, r.namespacePolicyIDs[wp.Namespace])
+}
+
+func TestMapFullEvictLRU(t *testing.T) {
+	r, f := newMapFullResolver(t, 1)
+	r.SetMapFullAction(MapFullEvictLRU)
+	first := newTestPolicy("test-ns", "first", c1)
+	second := newTestPolicy("test-ns", "second", c1)
+
+	require.NoError(t, r.ReconcileWP(first))
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "first"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{ID: cid1, Name: c1, CgroupID: 100}},
+		},
+	}))
+	require.Contains(t, f.cgroups, CgroupID(100))
+
+	// the least recently applied policy is evicted to make room.
+	require.NoError(t, r.ReconcileWP(second))
+	secondPolID := r.wpState[second.NamespacedName()].polByContainer[c1]
+	require.Equal(t, []PolicyID{secondPolID}, slices.Collect(maps.Keys(f.values)))
+	require.NotContains(t, f.cgroups, CgroupID(100), "the pods of the evicted policy are no longer enforced")
+
+	status := r.GetPolicyStatuses()[first.NamespacedName()]
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_ERROR, status.State)
+	require.Equal(t, evictedMsg, status.Message)
+	require.True(t, status.Rejected)
+	require.Empty(t, r.wpState[first.NamespacedName()].polByContainer)
+}
@@ -0,0 +1,189 @@
+package resolver
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+const (
+	applyPhaseBinaries    = "binaries"
+	applyPhaseMode        = "mode"
+	applyPhaseCgroupAssoc = "cgroup-assoc"
+	applyPhaseFlags       = "flags"
+	applyPhaseExecLimit   = "exec-limit"
+	applyPhaseFsTypes     = "fs-types"
+	applyPhaseCommands    = "commands"
+	applyPhaseFiles       = "files"
+	applyPhaseEgress      = "egress"
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var applyErrorsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "runtime_enforcer_apply_errors_total",
+		Help: "Number of failures while applying policies to the BPF maps, by phase.",
+	},
+	[]string{"phase"},
+)
+
+// policyInfo exposes the policies enforced by the agent following the info pattern:
+// each series is always 1, its labels describe the policy of a container.
+//
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var policyInfo = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "runtime_enforcer_policy_info",
+		Help: "Policies enforced by the agent, one series per policy and container, always set to 1.",
+	},
+	[]string{"namespace", "policy", "container", "mode", "executables"},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var mapUpdateThrottleSeconds = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "runtime_enforcer_map_update_throttle_seconds_total",
+		Help: "Total time the operations writing into the BPF maps have waited for the map update rate limit.",
+	},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var policyMapFullTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "runtime_enforcer_policy_map_full_total",
+		Help: "Number of policies that didn't fit in the BPF maps of the allowed executables.",
+	},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var policyMapUtilization = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "runtime_enforcer_policy_map_utilization_ratio",
+		Help: "Fraction of the capacity of the BPF maps of the allowed executables used by the policy IDs.",
+	},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var trackedPods = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "runtime_enforcer_tracked_pods",
+		Help: "Number of pods of the node with at least one container.",
+	},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var coveredPods = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "runtime_enforcer_covered_pods",
+		Help: "Number of pods of the node whose containers are all enforced by a policy in protect mode.",
+	},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var partiallyCoveredPods = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "runtime_enforcer_partially_covered_pods",
+		Help: "Number of pods of the node with some of their containers enforced in protect mode, but not all.",
+	},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var coveredPodsRatio = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "runtime_enforcer_covered_pods_ratio",
+		Help: "Fraction of the pods of the node whose containers are all enforced, 0 when there is no pod.",
+	},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var lockWaitSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "runtime_enforcer_resolver_lock_wait_seconds",
+		Help:    "Time spent waiting for the resolver lock, by operation.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10), // 100µs to ~26s
+	},
+	[]string{"operation"},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var lockHoldSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "runtime_enforcer_resolver_lock_hold_seconds",
+		Help:    "Time the resolver lock has been held, by operation.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10), // 100µs to ~26s
+	},
+	[]string{"operation"},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var policiesLoaded = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "runtime_enforcer_policies_loaded",
+		Help: "Number of policies known by the agent.",
+	},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var cgroupsProgrammed = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "runtime_enforcer_cgroups_programmed",
+		Help: "Number of cgroups of the node attached to the policy ID of their policy in the BPF maps.",
+	},
+)
+
+//nolint:gochecknoglobals // prometheus collectors are meant to be global.
+var nriConnected = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "runtime_enforcer_nri_connected",
+		Help: "1 while the NRI plugin is connected to the container runtime, 0 otherwise.",
+	},
+)
+
+// RegisterMetrics registers the resolver metrics in the given registry.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{
+		applyErrorsTotal,
+		policyInfo,
+		mapUpdateThrottleSeconds,
+		policyMapFullTotal,
+		policyMapUtilization,
+		trackedPods,
+		coveredPods,
+		partiallyCoveredPods,
+		coveredPodsRatio,
+		policiesLoaded,
+		cgroupsProgrammed,
+		nriConnected,
+		lockWaitSeconds,
+		lockHoldSeconds,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func countApplyError(phase string) {
+	applyErrorsTotal.WithLabelValues(phase).Inc()
+}
+
+// setPolicyInfo replaces the info series of a policy with the containers of its spec,
+// labeled with the mode and the executables written into BPF.
+func setPolicyInfo(wp *v1alpha1.WorkloadPolicy, info *wpInfo) {
+	deletePolicyInfo(wp.Namespace, wp.Name)
+	for containerName := range wp.Spec.RulesByContainer {
+		policyInfo.WithLabelValues(
+			wp.Namespace,
+			wp.Name,
+			containerName,
+			info.keyMode(containerName).String(),
+			strconv.Itoa(len(info.allowedByContainer[containerName])),
+		).Set(1)
+	}
+}
+
+func deletePolicyInfo(namespace, name string) {
+	policyInfo.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "policy": name})
+}
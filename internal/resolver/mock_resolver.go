@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"fmt"
 	"log/slog"
 	"testing"
 
@@ -17,10 +18,54 @@ func mockPolicyModeUpdateFunc(_ PolicyID, _ policymode.Mode, _ bpf.PolicyModeOpe
 	return nil
 }
 
+func mockPolicyFlagsUpdateFunc(_ PolicyID, _ bpf.PolicyFlags, _ bpf.PolicyFlagsOperation) error {
+	return nil
+}
+
+func mockPolicyExecLimitUpdateFunc(_ PolicyID, _ uint32, _ bpf.PolicyExecLimitOperation) error {
+	return nil
+}
+
+func mockPolicyFsTypesUpdateFunc(_ PolicyID, _ []uint32, _ bpf.PolicyFsTypesOperation) error {
+	return nil
+}
+
+func mockPolicyCommandsUpdateFunc(_ PolicyID, _ []bpf.Command, _ bpf.PolicyCommandsOperation) error {
+	return nil
+}
+
+func mockPolicyFilesUpdateFunc(_ PolicyID, _ bpf.Files, _ bpf.PolicyFilesOperation) error {
+	return nil
+}
+
+func mockPolicyEgressUpdateFunc(_ PolicyID, _ bpf.Egress, _ bpf.PolicyEgressOperation) error {
+	return nil
+}
+
+func mockGlobalDenyUpdateFunc(_ []string) error {
+	return nil
+}
+
+func mockPolicyIDsListFunc() ([]PolicyID, error) {
+	return nil, nil
+}
+
 func mockCgTrackerUpdateFunc(_ uint64, _ string) error {
 	return nil
 }
 
+func mockTraceUpdateFunc(_ CgroupID, _ bpf.TraceOperation) error {
+	return nil
+}
+
+func mockPolicySeenValuesFunc(_ PolicyID) ([]string, error) {
+	return nil, nil
+}
+
+func mockCgroupPolicyLookupFunc(_ CgroupID) (PolicyID, bool, error) {
+	return PolicyIDNone, false, nil
+}
+
 func mockCgroupToPolicyMapUpdateFunc(_ PolicyID, _ []CgroupID, _ bpf.CgroupPolicyOperation) error {
 	return nil
 }
@@ -43,7 +88,41 @@ func NewTestResolver(t testing.TB) *Resolver {
 		mockCgroupToPolicyMapUpdateFunc,
 		mockPolicyUpdateBinariesFunc,
 		mockPolicyModeUpdateFunc,
+		mockPolicyFlagsUpdateFunc,
+		mockPolicyExecLimitUpdateFunc,
+		mockPolicyFsTypesUpdateFunc,
+		mockPolicyCommandsUpdateFunc,
+		mockPolicyFilesUpdateFunc,
+		mockPolicyEgressUpdateFunc,
+		mockGlobalDenyUpdateFunc,
+		mockPolicyIDsListFunc,
+		mockTraceUpdateFunc,
+		mockPolicySeenValuesFunc,
+		mockCgroupPolicyLookupFunc,
 	)
 	require.NoError(t, err)
 	return r
 }
+
+// NewTestResolverWithMapCapacity returns a test resolver whose BPF maps hold at most capacity policy IDs,
+// the policies that don't fit are rejected with bpf.ErrPolicyMapFull.
+func NewTestResolverWithMapCapacity(t testing.TB, capacity int) *Resolver {
+	t.Helper()
+	r := NewTestResolver(t)
+	written := make(map[PolicyID]struct{})
+	r.policyUpdateBinariesFunc = func(id PolicyID, _ []string, op bpf.PolicyValuesOperation) error {
+		switch op {
+		case bpf.AddValuesToPolicy:
+			if _, ok := written[id]; !ok && len(written) >= capacity {
+				return fmt.Errorf("failed to insert inner policy (id=%d) map: %w", id, bpf.ErrPolicyMapFull)
+			}
+			written[id] = struct{}{}
+		case bpf.RemoveValuesFromPolicy:
+			delete(written, id)
+		case bpf.ReplaceValuesInPolicy:
+		}
+		return nil
+	}
+	r.SetPolicyMapCapacity(uint32(capacity)) //nolint:gosec // test capacities are small
+	return r
+}
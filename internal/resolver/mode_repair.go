@@ -0,0 +1,74 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+// RepairPolicyMode writes again the mode of a policy ID reported by BPF as associated with a cgroup
+// but without a mode, which drops the events of the cgroup. This is not expected to happen, so every
+// repair is logged with the number of repairs of the policy ID to spot the recurring ones.
+// It returns false if the policy ID is not known, e.g. it is stale and left to the stale policy IDs cleanup.
+func (r *Resolver) RepairPolicyMode(policyID PolicyID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for wpKey, info := range r.wpState {
+		if info == nil {
+			continue
+		}
+		var mode policymode.Mode
+		var containerName ContainerName
+		var found bool
+		for name, polID := range info.polByContainer {
+			if polID == policyID {
+				mode, containerName, found = info.keyMode(name), name, true
+				break
+			}
+		}
+		for name, polID := range info.gracePolByContainer {
+			if polID == policyID {
+				// grace policy IDs are always enforced in monitor mode.
+				mode, containerName, found = policymode.Monitor, name, true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		if err := r.policyModeUpdateFunc(policyID, mode, bpf.UpdateMode); err != nil {
+			return false, err
+		}
+		r.modeRepairs[policyID]++
+		r.logger.Warn("repaired missing policy mode",
+			"policy", wpKey,
+			"container", containerName,
+			"id", policyID,
+			"mode", mode.String(),
+			"repairs", r.modeRepairs[policyID])
+		return true, nil
+	}
+	return false, nil
+}
+
+// RunPolicyModeRepair repairs the mode of the policy IDs received from missing until ctx is done.
+func (r *Resolver) RunPolicyModeRepair(ctx context.Context, missing <-chan PolicyID) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case policyID := <-missing:
+			repaired, err := r.RepairPolicyMode(policyID)
+			if err != nil {
+				r.logger.ErrorContext(ctx, "failed to repair missing policy mode", "id", policyID, "error", err)
+				continue
+			}
+			if !repaired {
+				r.logger.DebugContext(ctx, "missing policy mode of an unknown policy ID", "id", policyID)
+			}
+		}
+	}
+}
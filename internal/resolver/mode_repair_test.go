@@ -0,0 +1,67 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRepairPolicyMode(t *testing.T) {
+	r := NewTestResolver(t)
+	fake := newFakeBPFMaps(r)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+	expected := fake.snapshot()
+
+	// Unknown policy IDs are left to the stale policy IDs cleanup.
+	repaired, err := r.RepairPolicyMode(polID + 100)
+	require.NoError(t, err)
+	require.False(t, repaired)
+	require.Equal(t, expected, fake)
+
+	// The BPF program reports the missing mode through the log ringbuf, the repair
+	// loop receives the policy ID and writes the mode again from the resolver state.
+	delete(fake.modes, polID)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	missing := make(chan PolicyID)
+	done := make(chan error)
+	go func() {
+		done <- r.RunPolicyModeRepair(ctx, missing)
+	}()
+	missing <- polID
+
+	require.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.modeRepairs[polID] == 1
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, expected, fake)
+	require.Equal(t, policymode.Protect, fake.modes[polID])
+
+	// Repeated occurrences are counted until the policy ID is removed.
+	repaired, err = r.RepairPolicyMode(polID)
+	require.NoError(t, err)
+	require.True(t, repaired)
+	require.Equal(t, 2, r.modeRepairs[polID])
+
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.NotContains(t, r.modeRepairs, polID)
+}
@@ -0,0 +1,33 @@
+package resolver
+
+import "errors"
+
+// ErrNamespacePolicyIDQuotaExceeded is returned when the policies of a namespace would use more policy IDs
+// than allowed by SetNamespacePolicyIDQuota.
+var ErrNamespacePolicyIDQuotaExceeded = errors.New("policy ID quota of the namespace exceeded")
+
+// SetNamespacePolicyIDQuota limits the number of policy IDs the policies of each namespace can use, 0 for no limit.
+// Each container of a policy uses a policy ID, and a second one when it may run in monitor mode while the policy
+// is enforced on other pods (readiness-gated enforcement or canary rollout).
+// The quota bounds the share of the BPF maps a single namespace can take: a policy that would exceed it
+// is reported in error, the policies already applied are untouched.
+// It must be called before the policies are reconciled.
+func (r *Resolver) SetNamespacePolicyIDQuota(quota int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.namespacePolicyIDQuota = quota
+}
+
+// releaseNamespacePolicyID gives the policy ID back to the quota of its namespace.
+// This must be called with the resolver lock held.
+func (r *Resolver) releaseNamespacePolicyID(policyID PolicyID) {
+	namespace, ok := r.policyIDNamespaces[policyID]
+	if !ok {
+		return
+	}
+	delete(r.policyIDNamespaces, policyID)
+	r.namespacePolicyIDs[namespace]--
+	if r.namespacePolicyIDs[namespace] <= 0 {
+		delete(r.namespacePolicyIDs, namespace)
+	}
+}
@@ -0,0 +1,48 @@
+package resolver
+
+import (
+	"testing"
+
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespacePolicyIDQuota(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	r.SetNamespacePolicyIDQuota(2)
+
+	// the policies of a namespace can use up to the quota.
+	first := newTestPolicy("tenant-a", "first", c1, c2)
+	require.NoError(t, r.ReconcileWP(first))
+	require.Len(t, r.wpState[first.NamespacedName()].polByContainer, 2)
+
+	// beyond the quota, the policy is reported in error and holds no policy ID.
+	second := newTestPolicy("tenant-a", "second", c1)
+	err := r.ReconcileWP(second)
+	require.ErrorIs(t, err, ErrNamespacePolicyIDQuotaExceeded)
+	require.ErrorContains(t, err, "namespace tenant-a already uses 2 policy IDs")
+	status := r.GetPolicyStatuses()[second.NamespacedName()]
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_ERROR, status.State)
+	require.Contains(t, status.Message, ErrNamespacePolicyIDQuotaExceeded.Error())
+	require.True(t, status.Rejected)
+	require.Empty(t, r.wpState[second.NamespacedName()].polByContainer)
+	require.Len(t, f.values, 2)
+	// the policies already applied are untouched.
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_READY, r.GetPolicyStatuses()[first.NamespacedName()].State)
+
+	// the quota is per namespace.
+	other := newTestPolicy("tenant-b", "first", c1, c2)
+	require.NoError(t, r.ReconcileWP(other))
+
+	// removing a container from a policy gives its policy ID back to the namespace.
+	require.NoError(t, r.ReconcileWP(newTestPolicy("tenant-a", "first", c1)))
+	require.NoError(t, r.ReconcileWP(second))
+	require.Len(t, r.wpState[second.NamespacedName()].polByContainer, 1)
+	require.False(t, r.GetPolicyStatuses()[second.NamespacedName()].Rejected)
+
+	// and so does deleting a policy.
+	require.ErrorIs(t, r.ReconcileWP(newTestPolicy("tenant-a", "third", c1)), ErrNamespacePolicyIDQuotaExceeded)
+	require.NoError(t, r.HandleWPDelete(first))
+	require.NoError(t, r.ReconcileWP(newTestPolicy("tenant-a", "third", c1)))
+}
@@ -16,8 +16,7 @@ func convertPodData(pod PodInput) *podEntry {
 }
 
 func (r *Resolver) AddPodContainerFromNri(pod PodInput) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.lockTimed(lockOpAddPodContainer)()
 
 	// NRI provides just one container of a pod, so it's possible we already have some containers for this pod.
 	podID := pod.Meta.ID
@@ -25,6 +24,11 @@ func (r *Resolver) AddPodContainerFromNri(pod PodInput) error {
 	if !ok {
 		// we need to add the pod to the cache from 0
 		state = convertPodData(pod)
+		if pod.Meta.HostPID {
+			r.logger.Info("the pod shares the host PID namespace, only the processes of its containers are enforced",
+				"pod", pod.Meta.Name,
+				"namespace", pod.Meta.Namespace)
+		}
 	}
 
 	for containerID, container := range pod.Containers {
@@ -50,6 +54,7 @@ func (r *Resolver) AddPodContainerFromNri(pod PodInput) error {
 
 		// populate the cgroup cache
 		r.cgroupIDToPodID[container.CgroupID] = podID
+		r.containerIDToPodID[containerID] = podID
 
 		// update the cgtracker map
 		if err := r.cgTrackerUpdateFunc(container.CgroupID, container.CgroupPath); err != nil {
@@ -68,12 +73,19 @@ func (r *Resolver) AddPodContainerFromNri(pod PodInput) error {
 	if err := r.applyPolicyToPodIfPresent(state); err != nil {
 		return fmt.Errorf("failed to apply policy to pod: %w", err)
 	}
+	if !ok {
+		r.applyPolicyToCanaryPeers(state)
+	}
+	// The pod stays in the cache, its containers are enforced by SetPodServiceAccount once it is reported.
+	if r.serviceAccountPending(state) {
+		return fmt.Errorf("%w: pod '%s/%s', policy %q", ErrServiceAccountUnknown,
+			state.podNamespace(), state.podName(), state.policyName())
+	}
 	return nil
 }
 
 func (r *Resolver) RemovePodContainerFromNri(podID PodID, containerID ContainerID) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.lockTimed(lockOpRemovePodContainer)()
 
 	state, ok := r.podCache[podID]
 	if !ok {
@@ -105,14 +117,47 @@ func (r *Resolver) RemovePodContainerFromNri(podID PodID, containerID ContainerI
 
 	// remove the cgroup ID from the cache
 	delete(r.cgroupIDToPodID, container.CgroupID)
+	delete(r.containerIDToPodID, containerID)
+	delete(r.startedContainers, containerID)
+
+	if entry, traced := r.traces[container.CgroupID]; traced {
+		entry.timer.Stop()
+		// the cgroup must leave the BPF maps even if its trace can't be stopped.
+		if err := r.stopTrace(container.CgroupID); err != nil {
+			r.logger.Error("failed to stop the exec trace of the removed container",
+				"containerID", containerID, "podID", podID, "error", err)
+		}
+	}
 
-	return r.cgroupToPolicyMapUpdateFunc(PolicyIDNone, []CgroupID{container.CgroupID}, bpf.RemoveCgroups)
+	err := r.cgroupToPolicyMapUpdateFunc(PolicyIDNone, []CgroupID{container.CgroupID}, bpf.RemoveCgroups)
+	if _, cached := r.podCache[podID]; !cached {
+		r.applyPolicyToCanaryPeers(state)
+	}
+	return err
 }
 
 func (r *Resolver) NRISynchronized() {
+	if !r.nriConnected.Swap(true) && r.nriSynchronized.Load() {
+		r.logger.Info("NRI connection restored")
+	}
+	nriConnected.Set(1)
 	r.nriSynchronized.Store(true)
 }
 
+// NRIDisconnected records that the NRI plugin lost its connection to the runtime, e.g. because the runtime
+// restarted. The connection is restored by the next synchronization.
+func (r *Resolver) NRIDisconnected(err error) {
+	if r.nriConnected.Swap(false) {
+		r.logger.Warn("NRI connection lost", "error", err)
+	}
+	nriConnected.Set(0)
+}
+
+// NRIConnected reports whether the NRI plugin is connected to the runtime.
+func (r *Resolver) NRIConnected() bool {
+	return r.nriConnected.Load()
+}
+
 func (r *Resolver) Ping(_ *http.Request) error {
 	if !r.nriSynchronized.Load() {
 		r.logger.Warn("NRI handler has not yet synchronized")
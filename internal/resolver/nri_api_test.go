@@ -0,0 +1,30 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNRIConnection(t *testing.T) {
+	r := NewTestResolver(t)
+	require.False(t, r.NRIConnected())
+	require.Error(t, r.Ping(nil))
+
+	r.NRISynchronized()
+	require.True(t, r.NRIConnected())
+	require.InDelta(t, 1, promtestutil.ToFloat64(nriConnected), 0)
+	require.NoError(t, r.Ping(nil))
+
+	// the runtime restarted: the agent stays ready, the containers it already knows are still enforced.
+	r.NRIDisconnected(errors.New("ttrpc: closed"))
+	require.False(t, r.NRIConnected())
+	require.InDelta(t, 0, promtestutil.ToFloat64(nriConnected), 0)
+	require.NoError(t, r.Ping(nil))
+
+	r.NRISynchronized()
+	require.True(t, r.NRIConnected())
+	require.InDelta(t, 1, promtestutil.ToFloat64(nriConnected), 0)
+}
@@ -0,0 +1,111 @@
+package resolver
+
+import (
+	"maps"
+	"slices"
+)
+
+// collectObservedExecutables merges the allowed executables run with the policy ID into the ones observed so far.
+// BPF only keeps them until the values of the policy ID are replaced or removed, so it must be called before.
+// This must be called with the resolver lock held.
+func (r *Resolver) collectObservedExecutables(policyID PolicyID) {
+	seen, err := r.policySeenValuesFunc(policyID)
+	if err != nil {
+		r.logger.Warn("failed to list the observed executables of the policy", "policyID", policyID, "error", err)
+		return
+	}
+	r.mergeObservedExecutables(policyID, seen)
+}
+
+// mergeObservedExecutables adds the executables to the ones observed with the policy ID.
+// This must be called with the resolver lock held.
+func (r *Resolver) mergeObservedExecutables(policyID PolicyID, seen []string) {
+	if len(seen) == 0 {
+		return
+	}
+	observed, ok := r.observedExecutables[policyID]
+	if !ok {
+		observed = make(map[string]struct{}, len(seen))
+		r.observedExecutables[policyID] = observed
+	}
+	for _, exe := range seen {
+		observed[exe] = struct{}{}
+	}
+}
+
+// policyIDs returns the policy IDs of every policy, the grace ones included.
+// This must be called with the resolver lock held.
+func (r *Resolver) policyIDs() map[PolicyID]struct{} {
+	ids := make(map[PolicyID]struct{})
+	for _, info := range r.wpState {
+		if info == nil {
+			continue
+		}
+		for _, polID := range info.polByContainer {
+			ids[polID] = struct{}{}
+		}
+		for _, polID := range info.gracePolByContainer {
+			ids[polID] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// collectAllObservedExecutables collects the observed executables of every policy ID.
+// Only the policy IDs are copied with the resolver lock held, the BPF maps are read after releasing it, so that
+// the resolution of the events and the policy updates don't wait for them. The policy IDs released meanwhile
+// are skipped, their observed executables are gone with them.
+// This must be called without the resolver lock held.
+func (r *Resolver) collectAllObservedExecutables() {
+	r.mu.Lock()
+	ids := r.policyIDs()
+	r.mu.Unlock()
+
+	seenByID := make(map[PolicyID][]string, len(ids))
+	for polID := range ids {
+		seen, err := r.policySeenValuesFunc(polID)
+		if err != nil {
+			r.logger.Warn("failed to list the observed executables of the policy", "policyID", polID, "error", err)
+			continue
+		}
+		seenByID[polID] = seen
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	live := r.policyIDs()
+	for polID, seen := range seenByID {
+		if _, ok := live[polID]; ok {
+			r.mergeObservedExecutables(polID, seen)
+		}
+	}
+}
+
+// observedExecutablesByContainer returns the sorted allowed executables observed for each container of the policy,
+// either with its policy IDs or with its grace ones, the ones of its image scoped entries included.
+// It returns nil if none has been observed.
+// This must be called with the resolver lock held.
+func (r *Resolver) observedExecutablesByContainer(info *wpInfo) map[ContainerName][]string {
+	observedSets := make(map[ContainerName]map[string]struct{})
+	for key, polID := range info.polByContainer {
+		containerName, _, _ := splitPolicyKey(key)
+		observed := observedSets[containerName]
+		for _, id := range []PolicyID{polID, info.gracePolByContainer[key]} {
+			for exe := range r.observedExecutables[id] {
+				if observed == nil {
+					observed = make(map[string]struct{})
+					observedSets[containerName] = observed
+				}
+				observed[exe] = struct{}{}
+			}
+		}
+	}
+	var observedByContainer map[ContainerName][]string
+	for containerName, observed := range observedSets {
+		if observedByContainer == nil {
+			observedByContainer = make(map[ContainerName][]string)
+		}
+		observedByContainer[containerName] = slices.Sorted(maps.Keys(observed))
+	}
+	return observedByContainer
+}
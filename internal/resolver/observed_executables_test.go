@@ -0,0 +1,31 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPolicyStatusesReadsBPFWithoutLock(t *testing.T) {
+	r := NewTestResolver(t)
+	wp := newTestPolicy("test-ns", "observed", c1)
+	require.NoError(t, r.ReconcileWP(wp))
+
+	r.policySeenValuesFunc = func(_ PolicyID) ([]string, error) {
+		// the BPF maps are read after the resolver lock is released.
+		require.True(t, r.mu.TryLock())
+		r.mu.Unlock()
+		return []string{"/bin/sleep"}, nil
+	}
+	status := r.GetPolicyStatuses()[wp.NamespacedName()]
+	require.Equal(t, map[ContainerName][]string{c1: {"/bin/sleep"}}, status.ObservedExecutables)
+
+	// the executables read for a policy ID released meanwhile are dropped.
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+	r.policySeenValuesFunc = func(_ PolicyID) ([]string, error) {
+		require.NoError(t, r.HandleWPDelete(wp))
+		return []string{"/bin/sleep"}, nil
+	}
+	require.Empty(t, r.GetPolicyStatuses())
+	require.NotContains(t, r.observedExecutables, polID)
+}
@@ -7,6 +7,8 @@ import (
 )
 
 // podEntry is the internal representation of a pod inside our cache.
+// The cache is keyed by the pod UID: a pod recreated with the same name, e.g. by a StatefulSet, is a distinct entry,
+// so its name must only be used for display and for the lookups made by name on behalf of a user.
 type podEntry struct {
 	meta       *PodMeta
 	containers map[ContainerID]*ContainerMeta
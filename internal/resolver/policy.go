@@ -1,8 +1,12 @@
 package resolver
 
 import (
+	"errors"
 	"fmt"
 	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
@@ -20,23 +24,108 @@ type PolicyStatus struct {
 	State   agentv1.PolicyState
 	Mode    agentv1.PolicyMode
 	Message string
+	// ModeForced is true when the mode is forced to monitor by the agent configuration, whatever the declared mode.
+	ModeForced bool
+	// Rejected is true when the policy is in the Error state because it exceeds a capacity limit of the agent:
+	// the policy ID quota of its namespace or the capacity of the BPF maps, which may also evict it.
+	// The rejection is only reported in the status, so that the policies that don't fit don't hold back
+	// the readiness of the agent.
+	Rejected bool
+	// ObservedExecutables contains the allowed executables run at least once on the node by each container.
+	ObservedExecutables map[ContainerName][]string
+	// DryRunImpact contains the executions the declared mode would have blocked in each container,
+	// it is nil when the policy is not in dry run.
+	DryRunImpact map[ContainerName]DryRunImpact
+	// EnforcedCgroups contains the number of cgroups of the node attached to the policy for each container.
+	// A policy in the Ready state without enforced cgroups has no matching pod on the node yet,
+	// while a failed update of the BPF maps puts the policy in the Error state, where it is not reported.
+	EnforcedCgroups map[ContainerName]int
 }
 
 type wpInfo struct {
+	// polByContainer contains the policy IDs of the containers, and of their image scoped entries under
+	// the keys returned by imageScopedKey. Every key has a lifecycle key, see lifecycleKey, when the lifecycle
+	// executables are set.
 	polByContainer policyByContainer
-	status         PolicyStatus
+	// allowedByContainer keeps the executables last written into BPF for each container,
+	// so that we can skip the map replace when only other fields (e.g. the mode) changed.
+	allowedByContainer map[ContainerName][]string
+	// candidateByContainer keeps the executables allowed by the policy before the global deny list is applied,
+	// so that the allow list can be written again when the global deny list changes.
+	candidateByContainer map[ContainerName][]string
+	// caseInsensitive is true when the executables of the policy are folded with bpf.FoldPathCase.
+	caseInsensitive bool
+	// commandsByContainer keeps the approved commands last written into BPF for each container.
+	commandsByContainer map[ContainerName][]bpf.Command
+	// filesByContainer keeps the file rules last written into BPF for each container, see keyFlags.
+	filesByContainer map[ContainerName]bpf.Files
+	// egressByContainer keeps the egress rules last written into BPF for each container, see keyFlags.
+	egressByContainer map[ContainerName]bpf.Egress
+	// gracePolByContainer contains the policy IDs enforced in monitor mode on pods that are not Ready yet
+	// or that are not selected by the canary rollout.
+	// It is populated only when the readiness-gated enforcement is enabled or the policy has a canary percentage.
+	gracePolByContainer policyByContainer
+	// imageScopesByContainer contains the images of the image scoped entries of each container,
+	// in the order they are matched by policyKey.
+	imageScopesByContainer map[ContainerName][]string
+	// listeningPortsByContainer contains the listening ports declared for each container.
+	// They are only used to classify the violations, they are not written into BPF.
+	listeningPortsByContainer map[ContainerName][]int32
+	// mode, flags, execLimit and fsMagics are the settings last written into BPF for the policy IDs
+	// of polByContainer.
+	mode      policymode.Mode
+	flags     bpf.PolicyFlags
+	execLimit uint32
+	fsMagics  []uint32
+	// modeByContainer contains the mode of the containers overriding the mode of the policy,
+	// see keyMode, the other containers use mode.
+	modeByContainer map[ContainerName]policymode.Mode
+	// canaryPercent is the percentage of the pods the declared mode is enforced on, 0 for every pod.
+	canaryPercent int32
+	// relaxOnTermination is true when the terminating pods of the policy run with the grace (monitor) policies.
+	relaxOnTermination bool
+	// allowedServiceAccounts contains the service accounts of the pods allowed to use the policy, nil for every pod.
+	allowedServiceAccounts []string
+	// paused is true while the enforcement is paused by the policy annotation.
+	// The declared mode is kept in the spec, so it is restored as soon as the annotation is removed.
+	paused bool
+	// dryRunSince is the start of the dry run of the policy, zero when it is not in dry run.
+	dryRunSince time.Time
+	// dryRunImpact contains the executions the declared mode would have blocked during the dry run, by container.
+	dryRunImpact map[ContainerName]*dryRunContainer
+	// devSpec is the policy last applied, kept only in developer mode so that it can be applied again
+	// when its allowed executables are replaced, see SetDevAllowedExecutables.
+	devSpec *v1alpha1.WorkloadPolicy
+	// lastApplied is the last time the policy has been applied successfully, used to pick the policy to evict
+	// when the BPF maps are full.
+	lastApplied time.Time
+	// expiryTimer applies the policy again when its next temporary executable expires, it is nil if there is none.
+	expiryTimer *time.Timer
+	status      PolicyStatus
 }
 
 const (
 	// PolicyIDNone is used to indicate no policy associated with the cgroup.
 	PolicyIDNone PolicyID = 0
+
+	forcedMonitorModeMsg = "mode forced to monitor by the agent configuration"
+	pausedMsg            = "enforcement paused by the " + v1alpha1.PausedAnnotationKey + " annotation"
+	canaryMsgFormat      = "declared mode enforced on the %d%% of the pods selected by the canary rollout"
 )
 
-// this must be called with the resolver lock held.
-func (r *Resolver) allocPolicyID() PolicyID {
+// allocPolicyID allocates a policy ID for the policy, within the quota of its namespace.
+// This must be called with the resolver lock held.
+func (r *Resolver) allocPolicyID(wpKey NamespacedPolicyName) (PolicyID, error) {
+	namespace, _, _ := strings.Cut(wpKey, "/")
+	if r.namespacePolicyIDQuota > 0 && r.namespacePolicyIDs[namespace] >= r.namespacePolicyIDQuota {
+		return PolicyIDNone, fmt.Errorf("%w: namespace %s already uses %d policy IDs",
+			ErrNamespacePolicyIDQuotaExceeded, namespace, r.namespacePolicyIDs[namespace])
+	}
 	id := r.nextPolicyID
 	r.nextPolicyID++
-	return id
+	r.policyIDNamespaces[id] = namespace
+	r.namespacePolicyIDs[namespace]++
+	return id, nil
 }
 
 // upsertPolicyIDInBPF adds or updates all entries for the given policy ID in BPF maps.
@@ -44,13 +133,62 @@ func (r *Resolver) allocPolicyID() PolicyID {
 func (r *Resolver) upsertPolicyIDInBPF(
 	policyID PolicyID,
 	allowedBinaries []string,
+	commands []bpf.Command,
+	files bpf.Files,
+	egress bpf.Egress,
 	mode policymode.Mode,
+	flags bpf.PolicyFlags,
+	execLimit uint32,
+	fsMagics []uint32,
 	valuesOp bpf.PolicyValuesOperation,
 ) error {
+	if valuesOp == bpf.ReplaceValuesInPolicy {
+		// the replaced values lose their observed state.
+		r.collectObservedExecutables(policyID)
+	}
 	if err := r.policyUpdateBinariesFunc(policyID, allowedBinaries, valuesOp); err != nil {
+		countApplyError(applyPhaseBinaries)
+		return err
+	}
+	if err := r.policyCommandsUpdateFunc(policyID, commands, bpf.ReplaceCommands); err != nil {
+		countApplyError(applyPhaseCommands)
+		return err
+	}
+	if err := r.policyFilesUpdateFunc(policyID, files, bpf.ReplaceFiles); err != nil {
+		countApplyError(applyPhaseFiles)
+		return err
+	}
+	if err := r.policyEgressUpdateFunc(policyID, egress, bpf.ReplaceEgress); err != nil {
+		countApplyError(applyPhaseEgress)
 		return err
 	}
+	return r.updatePolicySettingsInBPF(policyID, mode, flags, execLimit, fsMagics)
+}
+
+// updatePolicySettingsInBPF updates the mode, the flags, the exec limit and the allowed filesystems
+// of the given policy ID in BPF maps.
+// This must be called with the resolver lock held.
+func (r *Resolver) updatePolicySettingsInBPF(
+	policyID PolicyID,
+	mode policymode.Mode,
+	flags bpf.PolicyFlags,
+	execLimit uint32,
+	fsMagics []uint32,
+) error {
 	if err := r.policyModeUpdateFunc(policyID, mode, bpf.UpdateMode); err != nil {
+		countApplyError(applyPhaseMode)
+		return err
+	}
+	if err := r.policyFlagsUpdateFunc(policyID, flags, bpf.UpdateFlags); err != nil {
+		countApplyError(applyPhaseFlags)
+		return err
+	}
+	if err := r.policyExecLimitUpdateFunc(policyID, execLimit, bpf.UpdateExecLimit); err != nil {
+		countApplyError(applyPhaseExecLimit)
+		return err
+	}
+	if err := r.policyFsTypesUpdateFunc(policyID, fsMagics, bpf.UpdateFsTypes); err != nil {
+		countApplyError(applyPhaseFsTypes)
 		return err
 	}
 	return nil
@@ -59,6 +197,9 @@ func (r *Resolver) upsertPolicyIDInBPF(
 // clearPolicyIDFromBPF removes all entries for the given policy ID from BPF maps.
 // This must be called with the resolver lock held.
 func (r *Resolver) clearPolicyIDFromBPF(policyID PolicyID) error {
+	delete(r.observedExecutables, policyID)
+	delete(r.modeRepairs, policyID)
+	r.releaseNamespacePolicyID(policyID)
 	// TODO: refactor the PolicyUpdateBinariesFunc to not collapse the add and replace
 	// operations behind the same API. By doing that we will not need to pass a dummy values slice here.
 	if err := r.policyUpdateBinariesFunc(policyID, nil, bpf.RemoveValuesFromPolicy); err != nil {
@@ -69,23 +210,84 @@ func (r *Resolver) clearPolicyIDFromBPF(policyID PolicyID) error {
 	if err := r.policyModeUpdateFunc(policyID, 0, bpf.DeleteMode); err != nil {
 		return err
 	}
+	if err := r.policyFlagsUpdateFunc(policyID, 0, bpf.DeleteFlags); err != nil {
+		return err
+	}
+	if err := r.policyExecLimitUpdateFunc(policyID, 0, bpf.DeleteExecLimit); err != nil {
+		return err
+	}
+	if err := r.policyFsTypesUpdateFunc(policyID, nil, bpf.DeleteFsTypes); err != nil {
+		return err
+	}
+	if err := r.policyCommandsUpdateFunc(policyID, nil, bpf.DeleteCommands); err != nil {
+		return err
+	}
+	if err := r.policyFilesUpdateFunc(policyID, bpf.Files{}, bpf.DeleteFiles); err != nil {
+		return err
+	}
+	if err := r.policyEgressUpdateFunc(policyID, bpf.Egress{}, bpf.DeleteEgress); err != nil {
+		return err
+	}
 	return nil
 }
 
+// enforcementDeferred returns true when the pod must run with the grace (monitor) policies.
+// This must be called with the resolver lock held.
+func (r *Resolver) enforcementDeferred(state *podEntry) bool {
+	info := r.wpState[state.podNamespace()+"/"+state.policyName()]
+	if info != nil && !r.inCanary(info, state) {
+		return true
+	}
+	if info != nil && info.relaxOnTermination && r.isPodTerminating(state.meta.ID) {
+		return true
+	}
+	if !r.enforceAfterReadiness {
+		return false
+	}
+	_, ready := r.readyPods[state.meta.ID]
+	return !ready
+}
+
+// needsGracePolicies returns true when some pods of the policy must run with the grace (monitor) policies.
+// This must be called with the resolver lock held.
+func (r *Resolver) needsGracePolicies(wp *v1alpha1.WorkloadPolicy) bool {
+	return r.enforceAfterReadiness || canaryPercent(wp) > 0 || r.relaxesOnTermination(wp)
+}
+
 // applyPolicyToPod applies the given policy-by-container (add/update) to the pod's cgroups.
+// If the pod is not Ready yet and the readiness-gated enforcement is enabled, or if the pod is not selected by
+// the canary rollout of the policy, the grace policy IDs are used instead.
 // This must be called with the resolver lock held.
-func (r *Resolver) applyPolicyToPod(state *podEntry, applied policyByContainer) error {
+func (r *Resolver) applyPolicyToPod(state *podEntry, applied, grace policyByContainer) error {
+	deferred := r.enforcementDeferred(state)
+	info := r.wpState[state.podNamespace()+"/"+state.policyName()]
+	if info != nil && info.canaryPercent > 0 {
+		r.logger.Info("canary rollout",
+			"pod", state.podName(),
+			"namespace", state.podNamespace(),
+			"policy", state.policyName(),
+			"canaryPercent", info.canaryPercent,
+			"inCanary", r.inCanary(info, state))
+	}
 	for _, container := range state.containers {
-		polID, ok := applied[container.Name]
+		key := container.Name
+		if info != nil {
+			key = r.enforcedPolicyKey(info, container)
+		}
+		polID, ok := applied[key]
 		if !ok {
 			// No entry for this container: either not in policy, or unchanged.
 			continue
 		}
+		if gracePolID, hasGrace := grace[key]; deferred && hasGrace {
+			polID = gracePolID
+		}
 		if err := r.cgroupToPolicyMapUpdateFunc(
 			polID,
 			[]CgroupID{container.CgroupID},
 			bpf.AddPolicyToCgroups,
 		); err != nil {
+			countApplyError(applyPhaseCgroupAssoc)
 			return fmt.Errorf("failed to add policy to cgroups for pod %s, container %s, policy %s: %w",
 				state.podName(), container.Name, state.policyName(), err)
 		}
@@ -94,7 +296,8 @@ func (r *Resolver) applyPolicyToPod(state *podEntry, applied policyByContainer)
 }
 
 // removePolicyFromPod removes cgroup→policyID associations for the given containers in the pod.
-// It is used to remove policy from containers that are no longer in the spec.
+// It is used to remove policy from containers that are no longer in the spec,
+// or whose image scoped entry is no longer in the spec.
 // This must be called with the resolver lock held.
 func (r *Resolver) removePolicyFromPod(
 	wpKey NamespacedPolicyName,
@@ -102,20 +305,21 @@ func (r *Resolver) removePolicyFromPod(
 	wpState, removed policyByContainer,
 ) error {
 	for _, container := range podEntry.containers {
-		policyID, ok := removed[container.Name]
-		if !ok {
-			continue
-		}
-		if err := r.cgroupToPolicyMapUpdateFunc(
-			PolicyIDNone, []CgroupID{container.CgroupID}, bpf.RemoveCgroups,
-		); err != nil {
-			return fmt.Errorf("failed to remove cgroups for pod %s, container %s, policy %s: %w",
-				podEntry.podName(), container.Name, podEntry.policyName(), err)
+		for key, policyID := range removed {
+			if !policyKeyEnforcedOn(key, container) {
+				continue
+			}
+			if err := r.cgroupToPolicyMapUpdateFunc(
+				PolicyIDNone, []CgroupID{container.CgroupID}, bpf.RemoveCgroups,
+			); err != nil {
+				return fmt.Errorf("failed to remove cgroups for pod %s, container %s, policy %s: %w",
+					podEntry.podName(), container.Name, podEntry.policyName(), err)
+			}
+			if err := r.clearPolicyIDFromBPF(policyID); err != nil {
+				return fmt.Errorf("failed to clear policy for wp %s, container %s: %w", wpKey, key, err)
+			}
+			delete(wpState, key)
 		}
-		if err := r.clearPolicyIDFromBPF(policyID); err != nil {
-			return fmt.Errorf("failed to clear policy for wp %s, container %s: %w", wpKey, container.Name, err)
-		}
-		delete(wpState, container.Name)
 	}
 	return nil
 }
@@ -129,6 +333,14 @@ func (r *Resolver) applyPolicyToPodIfPresent(state *podEntry) error {
 		return nil
 	}
 
+	if r.isNamespaceExcluded(state.podNamespace()) {
+		r.logger.Info("namespace excluded from enforcement, skipping policy",
+			"pod", state.podName(),
+			"namespace", state.podNamespace(),
+			"policy", policyName)
+		return nil
+	}
+
 	key := fmt.Sprintf("%s/%s", state.podNamespace(), policyName)
 	info := r.wpState[key]
 	if info == nil {
@@ -148,106 +360,606 @@ func (r *Resolver) applyPolicyToPodIfPresent(state *podEntry) error {
 		)
 	}
 
-	return r.applyPolicyToPod(state, info.polByContainer)
+	if !r.serviceAccountAllowed(state, info) {
+		r.logger.Info("service account not allowed to use the policy, skipping policy",
+			"pod", state.podName(),
+			"namespace", state.podNamespace(),
+			"policy", policyName)
+		return nil
+	}
+
+	return r.applyPolicyToPod(state, info.polByContainer, info.gracePolByContainer)
+}
+
+// ExpectedMode returns the mode the agent enforces for the policy once it is reconciled: the effective mode of
+// the policy, unless the monitor mode is forced by the agent configuration over the protect mode.
+func (r *Resolver) ExpectedMode(wp *v1alpha1.WorkloadPolicy) agentv1.PolicyMode {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.expectedMode(wp)
+}
+
+// expectedMode must be called with the resolver lock held.
+func (r *Resolver) expectedMode(wp *v1alpha1.WorkloadPolicy) agentv1.PolicyMode {
+	if r.modeForced(wp) {
+		return agentv1.PolicyMode_POLICY_MODE_MONITOR
+	}
+	return policymode.ParsePolicyModeToProto(wp.EffectiveMode())
+}
+
+// modeForced returns true if the agent configuration overrides the declared mode of the policy.
+// The audit mode never blocks, so only the protect mode is overridden.
+// This must be called with the resolver lock held.
+func (r *Resolver) modeForced(wp *v1alpha1.WorkloadPolicy) bool {
+	return r.forceMonitorMode && wp.Spec.Mode == policymode.ProtectString
+}
+
+// effectiveMode returns the mode that should be enforced for the policy.
+// This must be called with the resolver lock held.
+func (r *Resolver) effectiveMode(wp *v1alpha1.WorkloadPolicy) policymode.Mode {
+	return r.forcedMode(policymode.ParseMode(wp.EffectiveMode()))
+}
+
+// effectiveContainerMode returns the mode that should be enforced for a container of the policy.
+// This must be called with the resolver lock held.
+func (r *Resolver) effectiveContainerMode(wp *v1alpha1.WorkloadPolicy, containerName ContainerName) policymode.Mode {
+	return r.forcedMode(policymode.ParseMode(wp.EffectiveContainerMode(containerName)))
+}
+
+// forcedMode returns the monitor mode instead of the protect mode when the monitor mode is forced.
+// This must be called with the resolver lock held.
+func (r *Resolver) forcedMode(mode policymode.Mode) policymode.Mode {
+	if r.forceMonitorMode && mode == policymode.Protect {
+		return policymode.Monitor
+	}
+	return mode
+}
+
+// keyMode returns the mode last written into BPF for the policy IDs of a policy key.
+func (i *wpInfo) keyMode(key ContainerName) policymode.Mode {
+	name, _, _ := splitPolicyKey(key)
+	if mode, ok := i.modeByContainer[name]; ok {
+		return mode
+	}
+	return i.mode
+}
+
+// policyFlags returns the BPF flags for the policy.
+func policyFlags(wp *v1alpha1.WorkloadPolicy) bpf.PolicyFlags {
+	var flags bpf.PolicyFlags
+	if wp.Spec.BlockSuidExec {
+		flags |= bpf.PolicyFlagBlockSuidExec
+	}
+	if wp.Spec.BlockScripts {
+		flags |= bpf.PolicyFlagBlockScripts
+	}
+	if wp.Spec.BlockUnlinkedExec {
+		flags |= bpf.PolicyFlagBlockUnlinkedExec
+	}
+	if wp.Spec.CaseInsensitiveMatching {
+		flags |= bpf.PolicyFlagCaseInsensitive
+	}
+	return flags
+}
+
+// filesystemMagics returns the superblock magics of the filesystems allowed by the policy, sorted.
+// The unknown filesystem types are rejected by the CRD validation, they are ignored here.
+func filesystemMagics(wp *v1alpha1.WorkloadPolicy) []uint32 {
+	var magics []uint32
+	for _, fsType := range wp.Spec.AllowedFilesystems {
+		if magic, ok := bpf.FilesystemMagic(fsType); ok {
+			magics = append(magics, magic)
+		}
+	}
+	slices.Sort(magics)
+	return slices.Compact(magics)
+}
+
+// effectiveAllowed merges the executables of the referenced profiles, the always-allowed executables
+// and the executables of the approved commands into the allow list of a container.
+// This must be called with the resolver lock held.
+func (r *Resolver) effectiveAllowed(executables v1alpha1.WorkloadPolicyExecutables, now time.Time) ([]string, error) {
+	var fromProfiles []string
+	if len(executables.Profiles) > 0 {
+		if r.profiles == nil {
+			return nil, errors.New("executable profiles are not available")
+		}
+		var err error
+		if fromProfiles, err = r.profiles.Expand(executables.Profiles); err != nil {
+			return nil, err
+		}
+	}
+	temporary := activeTemporaryExecutables(executables.Temporary, now)
+	commands := approvedCommandPaths(executables.ApprovedCommands)
+	if len(fromProfiles) == 0 && len(r.alwaysAllowed) == 0 && len(temporary) == 0 && len(commands) == 0 {
+		return executables.Allowed, nil
+	}
+	merged := slices.Concat(executables.Allowed, fromProfiles, r.alwaysAllowed, temporary, commands)
+	slices.Sort(merged)
+	return slices.Compact(merged), nil
+}
+
+// executablesForBPF returns the allowed executables as they must be written into BPF.
+// With case-insensitive matching the paths are folded the same way the BPF program folds
+// the executed path, entries that collapse to the same path are deduplicated.
+func executablesForBPF(wp *v1alpha1.WorkloadPolicy, allowed []string) []string {
+	if !wp.Spec.CaseInsensitiveMatching {
+		return allowed
+	}
+	folded := make([]string, 0, len(allowed))
+	for _, path := range allowed {
+		folded = append(folded, bpf.FoldPathCase(path))
+	}
+	slices.Sort(folded)
+	return slices.Compact(folded)
 }
 
 // syncWorkloadPolicy ensures state and BPF maps match wp.Spec.RulesByContainer:
-// allocates a policy ID for new containers, (re)applies binaries and mode for every container in the spec.
-// It returns the container→policyID map for newly created policy IDs.
+// allocates a policy ID for new containers, (re)applies binaries, mode and flags for every container in the spec.
+// Every image scoped entry of a container gets its own policy IDs, with the executables of the entry on top of
+// the ones of the container. The temporary executables expired at now are left out.
+// It returns the container→policyID map for newly created policy IDs, also on error,
+// so that the IDs created before the failure are tracked and released later.
 // This must be called with the resolver lock held.
-func (r *Resolver) syncWorkloadPolicy(wp *v1alpha1.WorkloadPolicy) (policyByContainer, error) {
+func (r *Resolver) syncWorkloadPolicy(wp *v1alpha1.WorkloadPolicy, now time.Time) (policyByContainer, error) {
 	wpKey := wp.NamespacedName()
-	mode := policymode.ParseMode(wp.Spec.Mode)
+	mode := r.effectiveMode(wp)
+	flags := policyFlags(wp)
+	execLimit := uint32(max(wp.Spec.MaxDistinctExecutables, 0))
+	fsMagics := filesystemMagics(wp)
 	// info is not nil. The caller must ensure the policy exists in wpState before calling.
 	info := r.wpState[wpKey]
-	newContainers := make(policyByContainer)
+	newContainers := make(policyByContainer, len(wp.Spec.RulesByContainer))
+	progress := r.newApplyProgress(wpKey, len(wp.Spec.RulesByContainer))
+	modeByContainer := make(map[ContainerName]policymode.Mode)
 
 	for containerName, containerRules := range wp.Spec.RulesByContainer {
-		polID, hadPolicyID := info.polByContainer[containerName]
-		op := bpf.ReplaceValuesInPolicy
-		if !hadPolicyID {
-			polID = r.allocPolicyID()
-			newContainers[containerName] = polID
-			r.logger.Info("create container policy", "id", polID,
-				"wp", wpKey,
-				"container", containerName)
-			op = bpf.AddValuesToPolicy
+		containerMode := r.effectiveContainerMode(wp, containerName)
+		if containerMode != mode {
+			modeByContainer[containerName] = containerMode
+		}
+		executables := containerRules.Executables
+		if devAllowed, ok := r.devAllowed[wpKey][containerName]; ok {
+			executables.Allowed = devAllowed
 		}
-		if err := r.upsertPolicyIDInBPF(polID, containerRules.Executables.Allowed, mode, op); err != nil {
-			return nil, fmt.Errorf("failed to populate policy for wp %s, container %s: %w", wpKey, containerName, err)
+		commands := commandsForBPF(wp, containerRules.Executables.ApprovedCommands)
+		files := filesForBPF(containerRules.Files)
+		egress := egressForBPF(containerRules.Network)
+		containerFlags := flags | files.Flags() | egress.Flags()
+		if err := r.syncPolicyKeys(
+			wp, info, newContainers, containerName, executables, commands, files, egress,
+			containerMode, containerFlags, execLimit, fsMagics, now,
+		); err != nil {
+			return newContainers, err
+		}
+		for _, scope := range uniqueImageScopes(containerRules.Executables.ImageScoped) {
+			scoped := executables
+			scoped.Allowed = slices.Concat(executables.Allowed, scope.Allowed)
+			slices.Sort(scoped.Allowed)
+			scoped.Allowed = slices.Compact(scoped.Allowed)
+			if err := r.syncPolicyKeys(
+				wp, info, newContainers, imageScopedKey(containerName, scope.Image), scoped, commands, files, egress,
+				containerMode, containerFlags, execLimit, fsMagics, now,
+			); err != nil {
+				return newContainers, err
+			}
 		}
+		progress.containerApplied()
 	}
+	progress.done()
+	info.imageScopesByContainer = imageScopesByContainer(wp)
+	info.caseInsensitive = wp.Spec.CaseInsensitiveMatching
+	info.mode = mode
+	info.modeByContainer = modeByContainer
+	info.flags = flags
+	info.execLimit = execLimit
+	info.fsMagics = fsMagics
+	info.canaryPercent = canaryPercent(wp)
+	info.relaxOnTermination = r.relaxesOnTermination(wp)
+	info.allowedServiceAccounts = slices.Clone(wp.Spec.AllowedServiceAccounts)
 
 	return newContainers, nil
 }
 
+// syncPolicyKeys syncs a policy key and, when lifecycle executables are set, its lifecycle key
+// with the lifecycle executables on top of the executables of the key, see lifecycleKey.
+// This must be called with the resolver lock held.
+func (r *Resolver) syncPolicyKeys(
+	wp *v1alpha1.WorkloadPolicy,
+	info *wpInfo,
+	newContainers policyByContainer,
+	key ContainerName,
+	executables v1alpha1.WorkloadPolicyExecutables,
+	commands []bpf.Command,
+	files bpf.Files,
+	egress bpf.Egress,
+	mode policymode.Mode,
+	flags bpf.PolicyFlags,
+	execLimit uint32,
+	fsMagics []uint32,
+	now time.Time,
+) error {
+	if err := r.syncPolicyKey(
+		wp, info, newContainers, key, executables, commands, files, egress, mode, flags, execLimit, fsMagics, now,
+	); err != nil {
+		return err
+	}
+	if len(r.lifecycleExecutables) == 0 {
+		return nil
+	}
+	lifecycle := executables
+	lifecycle.Allowed = slices.Concat(executables.Allowed, r.lifecycleExecutables)
+	slices.Sort(lifecycle.Allowed)
+	lifecycle.Allowed = slices.Compact(lifecycle.Allowed)
+	return r.syncPolicyKey(
+		wp, info, newContainers, lifecycleKey(key), lifecycle, commands, files, egress,
+		mode, flags, execLimit, fsMagics, now,
+	)
+}
+
+// syncPolicyKey writes the executables, the commands, the file rules and the egress rules of a policy key,
+// see imageScopedKey, into its policy IDs.
+// This must be called with the resolver lock held.
+func (r *Resolver) syncPolicyKey(
+	wp *v1alpha1.WorkloadPolicy,
+	info *wpInfo,
+	newContainers policyByContainer,
+	key ContainerName,
+	executables v1alpha1.WorkloadPolicyExecutables,
+	commands []bpf.Command,
+	files bpf.Files,
+	egress bpf.Egress,
+	mode policymode.Mode,
+	flags bpf.PolicyFlags,
+	execLimit uint32,
+	fsMagics []uint32,
+	now time.Time,
+) error {
+	wpKey := wp.NamespacedName()
+	allowed, err := r.effectiveAllowed(executables, now)
+	if err != nil {
+		return fmt.Errorf("container %s: %w", key, err)
+	}
+	candidates := executablesForBPF(wp, allowed)
+	allowed = r.withoutGloballyDenied(candidates, wp.Spec.CaseInsensitiveMatching)
+	unchanged := slices.Equal(info.allowedByContainer[key], allowed) &&
+		slices.EqualFunc(info.commandsByContainer[key], commands, commandsEqual) &&
+		filesEqual(info.filesByContainer[key], files) &&
+		egressEqual(info.egressByContainer[key], egress)
+	if err = r.syncContainerPolicy(
+		wpKey, key, info.polByContainer, newContainers,
+		allowed, commands, files, egress, unchanged, mode, flags, execLimit, fsMagics,
+	); err != nil {
+		return err
+	}
+	if r.needsGracePolicies(wp) {
+		// The grace policy shares the executables of the container policy but it never blocks.
+		if err = r.syncContainerPolicy(
+			wpKey, key, info.gracePolByContainer, info.gracePolByContainer,
+			allowed, commands, files, egress, unchanged, policymode.Monitor, flags, execLimit, fsMagics,
+		); err != nil {
+			return err
+		}
+	}
+	info.allowedByContainer[key] = slices.Clone(allowed)
+	info.candidateByContainer[key] = slices.Clone(candidates)
+	info.commandsByContainer[key] = commands
+	info.filesByContainer[key] = files
+	info.egressByContainer[key] = egress
+	return nil
+}
+
+// syncWorkloadPolicyWithCapacity runs syncWorkloadPolicy and tracks the new policy IDs of the policy.
+// When the BPF maps are full the policy is rejected, unless the map full action is MapFullEvictLRU:
+// the least recently applied policy is then evicted and the policy synced again, once.
+// The policy IDs created for a rejected policy are released.
+// This must be called with the resolver lock held.
+func (r *Resolver) syncWorkloadPolicyWithCapacity(wp *v1alpha1.WorkloadPolicy, info *wpInfo, now time.Time) error {
+	graceBefore := maps.Clone(info.gracePolByContainer)
+	created, err := r.syncWorkloadPolicy(wp, now)
+	maps.Copy(info.polByContainer, created)
+	if !errors.Is(err, bpf.ErrPolicyMapFull) {
+		return err
+	}
+	policyMapFullTotal.Inc()
+	if r.mapFullAction == MapFullEvictLRU {
+		evicted, evictErr := r.evictLeastRecentlyApplied(wp.NamespacedName())
+		if evictErr != nil {
+			r.releaseCreatedPolicyIDs(wp.NamespacedName(), info, created, graceBefore)
+			return errors.Join(err, evictErr)
+		}
+		if evicted {
+			newContainers, retryErr := r.syncWorkloadPolicy(wp, now)
+			maps.Copy(info.polByContainer, newContainers)
+			maps.Copy(created, newContainers)
+			if err = retryErr; !errors.Is(err, bpf.ErrPolicyMapFull) {
+				return err
+			}
+			policyMapFullTotal.Inc()
+		}
+	}
+	r.releaseCreatedPolicyIDs(wp.NamespacedName(), info, created, graceBefore)
+	r.logger.Error("policy rejected, the BPF maps are full", "wp", wp.NamespacedName(), "error", err)
+	return fmt.Errorf("policy rejected: %w", err)
+}
+
+// releaseCreatedPolicyIDs releases the policy IDs created while syncing a rejected policy, the grace ones included,
+// so that they don't stay allocated until the policy is deleted. graceBefore contains the grace policy IDs the
+// policy had before it was synced.
+// This must be called with the resolver lock held.
+func (r *Resolver) releaseCreatedPolicyIDs(
+	wpKey NamespacedPolicyName,
+	info *wpInfo,
+	created, graceBefore policyByContainer,
+) {
+	release := func(key ContainerName, polID PolicyID) {
+		if err := r.clearPolicyIDFromBPF(polID); err != nil {
+			r.logger.Error("failed to release a policy ID of a rejected policy",
+				"id", polID, "wp", wpKey, "container", key, "error", err)
+		}
+	}
+	for key, polID := range created {
+		delete(info.polByContainer, key)
+		delete(info.allowedByContainer, key)
+		delete(info.candidateByContainer, key)
+		delete(info.commandsByContainer, key)
+		delete(info.filesByContainer, key)
+		delete(info.egressByContainer, key)
+		release(key, polID)
+	}
+	for key, polID := range info.gracePolByContainer {
+		if _, ok := graceBefore[key]; ok {
+			continue
+		}
+		delete(info.gracePolByContainer, key)
+		release(key, polID)
+	}
+}
+
+// syncContainerPolicy writes the policy ID of the container found in current, or a newly allocated one stored in created, into BPF.
+// When unchanged is true the executables, the commands, the file rules and the egress rules are already in BPF
+// and only the mode and the flags are refreshed.
+// This must be called with the resolver lock held.
+func (r *Resolver) syncContainerPolicy(
+	wpKey NamespacedPolicyName,
+	containerName ContainerName,
+	current, created policyByContainer,
+	allowed []string,
+	commands []bpf.Command,
+	files bpf.Files,
+	egress bpf.Egress,
+	unchanged bool,
+	mode policymode.Mode,
+	flags bpf.PolicyFlags,
+	execLimit uint32,
+	fsMagics []uint32,
+) error {
+	polID, hadPolicyID := current[containerName]
+	if hadPolicyID && unchanged {
+		// The executables are already in BPF, we just need to refresh the mode and the flags.
+		if err := r.updatePolicySettingsInBPF(polID, mode, flags, execLimit, fsMagics); err != nil {
+			return fmt.Errorf("failed to update mode for wp %s, container %s: %w", wpKey, containerName, err)
+		}
+		return nil
+	}
+	op := bpf.ReplaceValuesInPolicy
+	if !hadPolicyID {
+		var err error
+		if polID, err = r.allocPolicyID(wpKey); err != nil {
+			return fmt.Errorf("failed to allocate a policy ID for wp %s, container %s: %w", wpKey, containerName, err)
+		}
+		created[containerName] = polID
+		r.logger.Info("create container policy", "id", polID,
+			"wp", wpKey,
+			"container", containerName,
+			"mode", mode.String())
+		op = bpf.AddValuesToPolicy
+	}
+	if err := r.upsertPolicyIDInBPF(
+		polID, allowed, commands, files, egress, mode, flags, execLimit, fsMagics, op,
+	); err != nil {
+		if !hadPolicyID {
+			// the new policy ID may have been partially written, it must never be attached to a cgroup.
+			delete(created, containerName)
+			if clearErr := r.clearPolicyIDFromBPF(polID); clearErr != nil {
+				r.logger.Error("failed to clear a partially written policy", "id", polID, "wp", wpKey, "error", clearErr)
+			}
+		}
+		return fmt.Errorf("failed to populate policy for wp %s, container %s: %w", wpKey, containerName, err)
+	}
+	return nil
+}
+
 // ReconcileWP enforces the workload policy from the current spec, removes containers
 // that are no longer in the spec, then applies policy to all matching pods.
 func (r *Resolver) ReconcileWP(wp *v1alpha1.WorkloadPolicy) error {
+	defer r.lockTimed(lockOpReconcilePolicy)()
+	return r.reconcileWP(wp)
+}
+
+// reconcileWP must be called with the resolver lock held.
+func (r *Resolver) reconcileWP(wp *v1alpha1.WorkloadPolicy) error {
 	r.logger.Info(
 		"reconcile wp-policy",
 		"wp", wp.NamespacedName(),
 		"mode", wp.Spec.Mode,
 	)
-	r.mu.Lock()
 
 	var info *wpInfo
 	var err error
-	mode := policymode.ParsePolicyModeToProto(wp.Spec.Mode)
+	mode := r.expectedMode(wp)
+	modeForced := r.modeForced(wp)
+	statusMsg := ""
+	switch {
+	case modeForced:
+		r.logger.Warn("overriding policy mode, monitor mode is forced",
+			"wp", wp.NamespacedName(),
+			"declaredMode", wp.Spec.Mode,
+		)
+		statusMsg = forcedMonitorModeMsg
+	case wp.IsPaused():
+		statusMsg = pausedMsg
+	case inDryRun(wp):
+		// the message reports the impact collected so far, see GetPolicyStatuses.
+	case len(wp.Spec.AllowedServiceAccounts) > 0 && !r.serviceAccountChecks:
+		statusMsg = serviceAccountsUnknownMsg
+	case canaryPercent(wp) > 0:
+		statusMsg = fmt.Sprintf(canaryMsgFormat, canaryPercent(wp))
+	}
 	defer func() {
 		if err != nil && info != nil {
 			info.setPolicyStatus(agentv1.PolicyState_POLICY_STATE_ERROR, mode, err.Error())
+			info.status.Rejected = errors.Is(err, ErrNamespacePolicyIDQuotaExceeded) ||
+				errors.Is(err, bpf.ErrPolicyMapFull)
 		}
-		r.mu.Unlock()
+		r.updatePolicyMapUtilization()
 	}()
 
 	wpKey := wp.NamespacedName()
 	info = r.wpState[wpKey]
 	if info == nil {
-		info = &wpInfo{polByContainer: make(policyByContainer, len(wp.Spec.RulesByContainer))}
+		info = &wpInfo{
+			polByContainer:       make(policyByContainer, len(wp.Spec.RulesByContainer)),
+			allowedByContainer:   make(map[ContainerName][]string, len(wp.Spec.RulesByContainer)),
+			candidateByContainer: make(map[ContainerName][]string, len(wp.Spec.RulesByContainer)),
+			commandsByContainer:  make(map[ContainerName][]bpf.Command, len(wp.Spec.RulesByContainer)),
+			filesByContainer:     make(map[ContainerName]bpf.Files, len(wp.Spec.RulesByContainer)),
+			egressByContainer:    make(map[ContainerName]bpf.Egress, len(wp.Spec.RulesByContainer)),
+			gracePolByContainer:  make(policyByContainer),
+		}
 		r.wpState[wpKey] = info
 	}
+	paused := wp.IsPaused()
+	if paused != info.paused {
+		if paused {
+			r.logger.Info("enforcement paused, applying the policy in monitor mode",
+				"wp", wpKey,
+				"declaredMode", wp.Spec.Mode)
+		} else {
+			r.logger.Info("enforcement resumed, restoring the declared mode",
+				"wp", wpKey,
+				"declaredMode", wp.Spec.Mode)
+		}
+	}
 
-	var newContainers policyByContainer
-	if newContainers, err = r.syncWorkloadPolicy(wp); err != nil {
+	now := time.Now()
+	if err = r.syncWorkloadPolicyWithCapacity(wp, info, now); err != nil {
 		return err
 	}
-	maps.Copy(info.polByContainer, newContainers)
 
 	// Split state into applied (still in spec) vs removed (no longer in spec).
 	appliedMap := make(policyByContainer, len(wp.Spec.RulesByContainer))
 	removedMap := make(policyByContainer, len(info.polByContainer))
-	for containerName := range info.polByContainer {
-		if _, stillPresent := wp.Spec.RulesByContainer[containerName]; stillPresent {
-			appliedMap[containerName] = info.polByContainer[containerName]
+	for key := range info.polByContainer {
+		if specHasPolicyKey(wp, key) {
+			appliedMap[key] = info.polByContainer[key]
 		} else {
-			removedMap[containerName] = info.polByContainer[containerName]
+			removedMap[key] = info.polByContainer[key]
 		}
 	}
 
+	// The policy is still loaded in BPF but it is never attached to the pods of an excluded namespace.
+	excluded := r.isNamespaceExcluded(wp.Namespace)
+	if excluded {
+		r.logger.Info("namespace excluded from enforcement, skipping pods of the policy", "wp", wpKey)
+	}
 	for _, podEntry := range r.podCache {
-		if !podEntry.matchPolicy(wp.Name, wp.Namespace) {
+		if excluded || !podEntry.matchPolicy(wp.Name, wp.Namespace) {
+			continue
+		}
+		// e.g. the service account of the pod has been removed from the allowed ones.
+		if !r.serviceAccountAllowed(podEntry, info) {
+			if err = r.detachPod(podEntry); err != nil {
+				return err
+			}
 			continue
 		}
 		if err = r.removePolicyFromPod(wpKey, podEntry, info.polByContainer, removedMap); err != nil {
 			return err
 		}
-		if err = r.applyPolicyToPod(podEntry, appliedMap); err != nil {
+		if err = r.applyPolicyToPod(podEntry, appliedMap, info.gracePolByContainer); err != nil {
 			return err
 		}
 	}
-	info.setPolicyStatus(agentv1.PolicyState_POLICY_STATE_READY, mode, "")
+	// The cgroups of the removed containers have been detached above, we can release their grace policy IDs,
+	// and their policy IDs when no pod runs them.
+	for containerName, polID := range removedMap {
+		if _, tracked := info.polByContainer[containerName]; tracked {
+			if err = r.clearPolicyIDFromBPF(polID); err != nil {
+				return fmt.Errorf("failed to clear policy for wp %s, container %s: %w", wpKey, containerName, err)
+			}
+			delete(info.polByContainer, containerName)
+		}
+		gracePolID, ok := info.gracePolByContainer[containerName]
+		if !ok {
+			continue
+		}
+		if err = r.clearPolicyIDFromBPF(gracePolID); err != nil {
+			return fmt.Errorf("failed to clear grace policy for wp %s, container %s: %w", wpKey, containerName, err)
+		}
+		delete(info.gracePolByContainer, containerName)
+	}
+	// e.g. the canary rollout is over: the pods have been moved to the container policies above.
+	if !r.needsGracePolicies(wp) {
+		for containerName, gracePolID := range info.gracePolByContainer {
+			if err = r.clearPolicyIDFromBPF(gracePolID); err != nil {
+				return fmt.Errorf("failed to clear grace policy for wp %s, container %s: %w", wpKey, containerName, err)
+			}
+			delete(info.gracePolByContainer, containerName)
+		}
+	}
+	info.listeningPortsByContainer = make(map[ContainerName][]int32)
+	for containerName, rules := range wp.Spec.RulesByContainer {
+		if len(rules.ListeningPorts) > 0 {
+			info.listeningPortsByContainer[containerName] = slices.Clone(rules.ListeningPorts)
+		}
+	}
+	// Forget the cached executables, commands, files and egress rules of containers whose policy ID has been
+	// released.
+	maps.DeleteFunc(info.allowedByContainer, func(containerName ContainerName, _ []string) bool {
+		_, ok := info.polByContainer[containerName]
+		return !ok
+	})
+	maps.DeleteFunc(info.candidateByContainer, func(containerName ContainerName, _ []string) bool {
+		_, ok := info.polByContainer[containerName]
+		return !ok
+	})
+	maps.DeleteFunc(info.commandsByContainer, func(containerName ContainerName, _ []bpf.Command) bool {
+		_, ok := info.polByContainer[containerName]
+		return !ok
+	})
+	maps.DeleteFunc(info.filesByContainer, func(containerName ContainerName, _ bpf.Files) bool {
+		_, ok := info.polByContainer[containerName]
+		return !ok
+	})
+	maps.DeleteFunc(info.egressByContainer, func(containerName ContainerName, _ bpf.Egress) bool {
+		_, ok := info.polByContainer[containerName]
+		return !ok
+	})
+	info.paused = paused
+	r.updateDryRun(wp, info, now)
+	info.lastApplied = now
+	r.scheduleTemporaryExpiry(wp, info, now)
+	r.keepDevSpec(wp, info)
+	info.setPolicyStatus(agentv1.PolicyState_POLICY_STATE_READY, mode, statusMsg)
+	info.status.ModeForced = modeForced
+	setPolicyInfo(wp, info)
 	return nil
 }
 
 // HandleWPDelete removes a workload policy from the resolver cache and updates the BPF maps accordingly.
 func (r *Resolver) HandleWPDelete(wp *v1alpha1.WorkloadPolicy) error {
+	defer r.lockTimed(lockOpDeletePolicy)()
+	return r.handleWPDelete(wp)
+}
+
+// handleWPDelete must be called with the resolver lock held.
+func (r *Resolver) handleWPDelete(wp *v1alpha1.WorkloadPolicy) error {
 	r.logger.Info(
 		"delete-wp-policy",
 		"wp", wp.NamespacedName(),
 	)
-	r.mu.Lock()
-	defer r.mu.Unlock()
 
 	wpKey := wp.NamespacedName()
 	info := r.wpState[wpKey]
@@ -260,7 +972,17 @@ func (r *Resolver) HandleWPDelete(wp *v1alpha1.WorkloadPolicy) error {
 		return nil
 	}
 	delete(r.wpState, wpKey)
+	deletePolicyInfo(wp.Namespace, wp.Name)
+	if info.expiryTimer != nil {
+		info.expiryTimer.Stop()
+	}
+	defer r.updatePolicyMapUtilization()
+	return r.releasePolicyIDs(wpKey, info)
+}
 
+// releasePolicyIDs detaches the policy IDs of the policy from the cgroups and removes them from BPF.
+// This must be called with the resolver lock held.
+func (r *Resolver) releasePolicyIDs(wpKey NamespacedPolicyName, info *wpInfo) error {
 	for containerName, policyID := range info.polByContainer {
 		// First we remove the association cgroupID -> PolicyID and then we will remove the policy values and modes
 
@@ -271,24 +993,147 @@ func (r *Resolver) HandleWPDelete(wp *v1alpha1.WorkloadPolicy) error {
 		if err := r.clearPolicyIDFromBPF(policyID); err != nil {
 			return fmt.Errorf("failed to clear policy for wp %s, container %s: %w", wpKey, containerName, err)
 		}
+		delete(info.polByContainer, containerName)
+	}
+	for containerName, policyID := range info.gracePolByContainer {
+		if err := r.cgroupToPolicyMapUpdateFunc(policyID, []CgroupID{}, bpf.RemovePolicy); err != nil {
+			return fmt.Errorf("failed to remove grace policy from cgroup map: %w", err)
+		}
+		if err := r.clearPolicyIDFromBPF(policyID); err != nil {
+			return fmt.Errorf("failed to clear grace policy for wp %s, container %s: %w", wpKey, containerName, err)
+		}
+		delete(info.gracePolByContainer, containerName)
 	}
 	return nil
 }
 
+// WPChange is a workload policy event to apply with ApplyWPChanges.
+type WPChange struct {
+	Policy *v1alpha1.WorkloadPolicy
+	// Deleted is true when the policy has been removed from the cluster.
+	Deleted bool
+}
+
+// ApplyWPChanges applies several workload policy events under a single lock acquisition,
+// so that related policies are never observed half applied.
+// It returns the errors of the failed changes keyed by policy namespaced name.
+func (r *Resolver) ApplyWPChanges(changes []WPChange) map[NamespacedPolicyName]error {
+	defer r.lockTimed(lockOpApplyPolicyChanges)()
+
+	errs := make(map[NamespacedPolicyName]error)
+	for _, change := range changes {
+		var err error
+		if change.Deleted {
+			err = r.handleWPDelete(change.Policy)
+		} else {
+			err = r.reconcileWP(change.Policy)
+		}
+		if err != nil {
+			errs[change.Policy.NamespacedName()] = err
+		}
+	}
+	return errs
+}
+
 // GetPolicyStatuses returns the current policy statuses keyed by namespaced name (e.g. "namespace/name").
 func (r *Resolver) GetPolicyStatuses() map[NamespacedPolicyName]PolicyStatus {
+	r.collectAllObservedExecutables()
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	statuses := make(map[NamespacedPolicyName]PolicyStatus, len(r.wpState))
+	enforcedCgroups := r.enforcedCgroupsByPolicy()
 	for k, v := range r.wpState {
 		if v != nil {
-			statuses[k] = v.status
+			statuses[k] = r.policyStatus(v, enforcedCgroups[k])
 		}
 	}
 	return statuses
 }
 
+// PolicyStatus returns the last known status of the policy, false if the policy is unknown.
+func (r *Resolver) PolicyStatus(wpKey NamespacedPolicyName) (PolicyStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info := r.wpState[wpKey]
+	if info == nil {
+		return PolicyStatus{}, false
+	}
+	for _, polID := range info.polByContainer {
+		r.collectObservedExecutables(polID)
+	}
+	for _, polID := range info.gracePolByContainer {
+		r.collectObservedExecutables(polID)
+	}
+	return r.policyStatus(info, r.enforcedCgroupsByPolicy()[wpKey]), true
+}
+
+// policyStatus returns the status of the policy with the executables observed so far.
+// This must be called with the resolver lock held.
+func (r *Resolver) policyStatus(info *wpInfo, enforcedCgroups map[ContainerName]int) PolicyStatus {
+	status := info.status
+	status.ObservedExecutables = r.observedExecutablesByContainer(info)
+	status.DryRunImpact = info.dryRunImpactByContainer()
+	if status.DryRunImpact != nil && status.State == agentv1.PolicyState_POLICY_STATE_READY &&
+		status.Message == "" {
+		status.Message = info.dryRunMessage()
+	}
+	if status.State != agentv1.PolicyState_POLICY_STATE_ERROR {
+		status.EnforcedCgroups = enforcedCgroups
+	}
+	return status
+}
+
+// enforcedCgroupsByPolicy counts the cgroups attached to each policy by container name.
+// This must be called with the resolver lock held.
+func (r *Resolver) enforcedCgroupsByPolicy() map[NamespacedPolicyName]map[ContainerName]int {
+	counts := make(map[NamespacedPolicyName]map[ContainerName]int)
+	r.forEachBoundContainer(func(name NamespacedPolicyName, _ *podEntry, _ ContainerID,
+		meta *ContainerMeta, _ PolicyID, _ bool) {
+		if counts[name] == nil {
+			counts[name] = make(map[ContainerName]int)
+		}
+		counts[name][meta.Name]++
+	})
+	return counts
+}
+
+// GetListeningPorts returns the listening ports declared by the policy for the container, nil if there are none.
+func (r *Resolver) GetListeningPorts(wpKey NamespacedPolicyName, containerName ContainerName) []int32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info := r.wpState[wpKey]
+	if info == nil {
+		return nil
+	}
+	return info.listeningPortsByContainer[containerName]
+}
+
+// HasPolicy returns true if the policy has already been applied, even if it failed.
+func (r *Resolver) HasPolicy(wpKey NamespacedPolicyName) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.wpState[wpKey] != nil
+}
+
+// PolicyCount returns the number of policies applied on the node, even if they failed.
+func (r *Resolver) PolicyCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int
+	for _, info := range r.wpState {
+		if info != nil {
+			count++
+		}
+	}
+	return count
+}
+
 func (i *wpInfo) setPolicyStatus(state agentv1.PolicyState, mode agentv1.PolicyMode, message string) {
 	i.status = PolicyStatus{
 		State:   state,
@@ -1,9 +1,19 @@
 package resolver
 
 import (
+	"errors"
+	"maps"
+	"slices"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/profiles"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
 	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -70,9 +80,10 @@ func TestHandleWP_Lifecycle(t *testing.T) {
 	statuses := r.GetPolicyStatuses()
 	require.Contains(t, statuses, key)
 	require.Equal(t, PolicyStatus{
-		State:   agentv1.PolicyState_POLICY_STATE_READY,
-		Mode:    agentv1.PolicyMode_POLICY_MODE_MONITOR,
-		Message: "",
+		State:           agentv1.PolicyState_POLICY_STATE_READY,
+		Mode:            agentv1.PolicyMode_POLICY_MODE_MONITOR,
+		Message:         "",
+		EnforcedCgroups: map[ContainerName]int{c1: 1, c2: 1},
 	}, statuses[key])
 
 	// Update: remove c1, update c2 allowed list, add c3
@@ -96,3 +107,787 @@ func TestHandleWP_Lifecycle(t *testing.T) {
 	statuses = r.GetPolicyStatuses()
 	require.NotContains(t, statuses, key)
 }
+
+func TestReconcileWP_ModeOnlyUpdateSkipsReplace(t *testing.T) {
+	r := NewTestResolver(t)
+	var replaceCalls int
+	r.policyUpdateBinariesFunc = func(_ PolicyID, _ []string, op bpf.PolicyValuesOperation) error {
+		if op == bpf.ReplaceValuesInPolicy {
+			replaceCalls++
+		}
+		return nil
+	}
+	modeUpdates := make(map[PolicyID]policymode.Mode)
+	r.policyModeUpdateFunc = func(id PolicyID, mode policymode.Mode, _ bpf.PolicyModeOperation) error {
+		modeUpdates[id] = mode
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "monitor",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				c2: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/cat"}}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Zero(t, replaceCalls)
+
+	// Only the mode changes: no container should get its values replaced.
+	wp.Spec.Mode = "protect"
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Zero(t, replaceCalls)
+	require.Len(t, modeUpdates, 2)
+	for _, mode := range modeUpdates {
+		require.Equal(t, policymode.Protect, mode)
+	}
+
+	// Changing the executables of one container replaces only that one.
+	wp.Spec.RulesByContainer[c2] = &v1alpha1.WorkloadPolicyRules{
+		Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/cat", "/bin/ls"}},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, 1, replaceCalls)
+}
+
+func TestReconcileWP_ForceMonitorMode(t *testing.T) {
+	r := NewTestResolver(t)
+	modeUpdates := make(map[PolicyID]policymode.Mode)
+	r.policyModeUpdateFunc = func(id PolicyID, mode policymode.Mode, _ bpf.PolicyModeOperation) error {
+		modeUpdates[id] = mode
+		return nil
+	}
+	r.SetForceMonitorMode(true)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+	key := wp.NamespacedName()
+
+	require.Equal(t, agentv1.PolicyMode_POLICY_MODE_MONITOR, r.ExpectedMode(wp))
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID]policymode.Mode{PolicyID(1): policymode.Monitor}, modeUpdates)
+	require.Equal(t, PolicyStatus{
+		State:      agentv1.PolicyState_POLICY_STATE_READY,
+		Mode:       agentv1.PolicyMode_POLICY_MODE_MONITOR,
+		Message:    forcedMonitorModeMsg,
+		ModeForced: true,
+	}, r.GetPolicyStatuses()[key])
+
+	// Flipping the switch off restores the declared mode.
+	r.SetForceMonitorMode(false)
+	require.Equal(t, agentv1.PolicyMode_POLICY_MODE_PROTECT, r.ExpectedMode(wp))
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID]policymode.Mode{PolicyID(1): policymode.Protect}, modeUpdates)
+	require.Equal(t, PolicyStatus{
+		State:   agentv1.PolicyState_POLICY_STATE_READY,
+		Mode:    agentv1.PolicyMode_POLICY_MODE_PROTECT,
+		Message: "",
+	}, r.GetPolicyStatuses()[key])
+
+	// The audit mode never blocks, it is kept when the monitor mode is forced.
+	r.SetForceMonitorMode(true)
+	wp.Spec.Mode = "audit"
+	require.Equal(t, agentv1.PolicyMode_POLICY_MODE_AUDIT, r.ExpectedMode(wp))
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID]policymode.Mode{PolicyID(1): policymode.Audit}, modeUpdates)
+	require.Equal(t, PolicyStatus{
+		State: agentv1.PolicyState_POLICY_STATE_READY,
+		Mode:  agentv1.PolicyMode_POLICY_MODE_AUDIT,
+	}, r.GetPolicyStatuses()[key])
+}
+
+func TestReconcileWP_PauseResume(t *testing.T) {
+	r := NewTestResolver(t)
+	modeUpdates := make(map[PolicyID]policymode.Mode)
+	r.policyModeUpdateFunc = func(id PolicyID, mode policymode.Mode, _ bpf.PolicyModeOperation) error {
+		modeUpdates[id] = mode
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+	key := wp.NamespacedName()
+
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID]policymode.Mode{PolicyID(1): policymode.Protect}, modeUpdates)
+
+	// Pausing the policy switches it to monitor mode without touching the spec.
+	wp.Annotations = map[string]string{v1alpha1.PausedAnnotationKey: "true"}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID]policymode.Mode{PolicyID(1): policymode.Monitor}, modeUpdates)
+	require.Equal(t, "protect", wp.Spec.Mode)
+	require.Equal(t, PolicyStatus{
+		State:   agentv1.PolicyState_POLICY_STATE_READY,
+		Mode:    agentv1.PolicyMode_POLICY_MODE_MONITOR,
+		Message: pausedMsg,
+	}, r.GetPolicyStatuses()[key])
+
+	// Resuming it restores the declared mode.
+	delete(wp.Annotations, v1alpha1.PausedAnnotationKey)
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID]policymode.Mode{PolicyID(1): policymode.Protect}, modeUpdates)
+	require.Equal(t, PolicyStatus{
+		State:   agentv1.PolicyState_POLICY_STATE_READY,
+		Mode:    agentv1.PolicyMode_POLICY_MODE_PROTECT,
+		Message: "",
+	}, r.GetPolicyStatuses()[key])
+
+	// The audit mode never blocks, it is kept when the monitor mode is forced.
+	r.SetForceMonitorMode(true)
+	wp.Spec.Mode = "audit"
+	require.Equal(t, agentv1.PolicyMode_POLICY_MODE_AUDIT, r.ExpectedMode(wp))
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID]policymode.Mode{PolicyID(1): policymode.Audit}, modeUpdates)
+	require.Equal(t, PolicyStatus{
+		State: agentv1.PolicyState_POLICY_STATE_READY,
+		Mode:  agentv1.PolicyMode_POLICY_MODE_AUDIT,
+	}, r.GetPolicyStatuses()[key])
+}
+
+func TestReconcileWP_ApplyErrorsMetric(t *testing.T) {
+	errFailure := errors.New("bpf failure")
+	newPolicy := func() *v1alpha1.WorkloadPolicy {
+		return &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode: "monitor",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		phase string
+		setup func(r *Resolver)
+	}{
+		{
+			phase: applyPhaseBinaries,
+			setup: func(r *Resolver) {
+				r.policyUpdateBinariesFunc = func(PolicyID, []string, bpf.PolicyValuesOperation) error {
+					return errFailure
+				}
+			},
+		},
+		{
+			phase: applyPhaseMode,
+			setup: func(r *Resolver) {
+				r.policyModeUpdateFunc = func(PolicyID, policymode.Mode, bpf.PolicyModeOperation) error {
+					return errFailure
+				}
+			},
+		},
+		{
+			phase: applyPhaseCgroupAssoc,
+			setup: func(r *Resolver) {
+				r.cgroupToPolicyMapUpdateFunc = func(PolicyID, []CgroupID, bpf.CgroupPolicyOperation) error {
+					return errFailure
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phase, func(t *testing.T) {
+			r := NewTestResolver(t)
+			r.podCache["test-pod-uid"] = &podEntry{
+				meta: &PodMeta{
+					ID:        "test-pod-uid",
+					Namespace: "test-ns",
+					Name:      "test-pod",
+					Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+				},
+				containers: map[ContainerID]*ContainerMeta{
+					cid1: {CgroupID: 100, Name: c1, ID: cid1},
+				},
+			}
+			tt.setup(r)
+
+			before := make(map[string]float64)
+			for _, phase := range []string{applyPhaseBinaries, applyPhaseMode, applyPhaseCgroupAssoc} {
+				before[phase] = promtestutil.ToFloat64(applyErrorsTotal.WithLabelValues(phase))
+			}
+
+			require.ErrorIs(t, r.ReconcileWP(newPolicy()), errFailure)
+
+			for phase, value := range before {
+				expected := value
+				if phase == tt.phase {
+					expected++
+				}
+				require.InDelta(t, expected, promtestutil.ToFloat64(applyErrorsTotal.WithLabelValues(phase)), 0, phase)
+			}
+		})
+	}
+}
+
+func TestPolicyStatus(t *testing.T) {
+	r := NewTestResolver(t)
+	wp := newTestPolicy("test-ns", "example", c1)
+	key := wp.NamespacedName()
+
+	_, ok := r.PolicyStatus(key)
+	require.False(t, ok)
+
+	// no pod of the node matches the policy yet.
+	require.NoError(t, r.ReconcileWP(wp))
+	status, ok := r.PolicyStatus(key)
+	require.True(t, ok)
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_READY, status.State)
+	require.Empty(t, status.EnforcedCgroups)
+
+	addPod := func(podID PodID, containerID ContainerID, cgroupID CgroupID) error {
+		return r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{
+				ID:        podID,
+				Namespace: "test-ns",
+				Name:      podID,
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				containerID: {ContainerMeta: ContainerMeta{ID: containerID, Name: c1, CgroupID: cgroupID}},
+			},
+		})
+	}
+	require.NoError(t, addPod("pod-1", cid1, 100))
+	require.NoError(t, addPod("pod-2", cid2, 101))
+	status, ok = r.PolicyStatus(key)
+	require.True(t, ok)
+	require.Equal(t, map[ContainerName]int{c1: 2}, status.EnforcedCgroups)
+	require.Equal(t, r.GetPolicyStatuses()[key], status)
+
+	// a failed map update is reported as an error.
+	errFailure := errors.New("bpf failure")
+	r.cgroupToPolicyMapUpdateFunc = func(PolicyID, []CgroupID, bpf.CgroupPolicyOperation) error {
+		return errFailure
+	}
+	wp.Spec.Mode = "monitor"
+	require.ErrorIs(t, r.ReconcileWP(wp), errFailure)
+	status, ok = r.PolicyStatus(key)
+	require.True(t, ok)
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_ERROR, status.State)
+	require.Contains(t, status.Message, errFailure.Error())
+	require.Nil(t, status.EnforcedCgroups)
+}
+
+func TestReconcileWP_EnforceAfterReadiness(t *testing.T) {
+	r := NewTestResolver(t)
+	modes := make(map[PolicyID]policymode.Mode)
+	r.policyModeUpdateFunc = func(id PolicyID, mode policymode.Mode, _ bpf.PolicyModeOperation) error {
+		modes[id] = mode
+		return nil
+	}
+	cgroupPolicies := make(map[CgroupID]PolicyID)
+	r.cgroupToPolicyMapUpdateFunc = func(id PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error {
+		if op == bpf.AddPolicyToCgroups {
+			for _, cgID := range cgroupIDs {
+				cgroupPolicies[cgID] = id
+			}
+		}
+		return nil
+	}
+	r.SetEnforceAfterReadiness(true)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+
+	const cgID = CgroupID(100)
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{ID: cid1, Name: c1, CgroupID: cgID}},
+		},
+	}))
+
+	// The pod is not Ready yet: the container runs in monitor mode.
+	require.Equal(t, policymode.Monitor, modes[cgroupPolicies[cgID]])
+
+	// Readiness flips the container to the declared mode.
+	require.NoError(t, r.MarkPodReady("test-pod-uid"))
+	require.Equal(t, policymode.Protect, modes[cgroupPolicies[cgID]])
+
+	// The policy status reports the declared mode.
+	require.Equal(t, agentv1.PolicyMode_POLICY_MODE_PROTECT, r.GetPolicyStatuses()[wp.NamespacedName()].Mode)
+
+	// A policy update keeps the Ready pod enforced.
+	wp.Spec.RulesByContainer[c1].Executables.Allowed = []string{"/bin/sleep", "/bin/cat"}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, policymode.Protect, modes[cgroupPolicies[cgID]])
+}
+
+func TestReconcileWP_CaseInsensitiveMatching(t *testing.T) {
+	r := NewTestResolver(t)
+	written := make(map[PolicyID][]string)
+	r.policyUpdateBinariesFunc = func(id PolicyID, values []string, _ bpf.PolicyValuesOperation) error {
+		written[id] = values
+		return nil
+	}
+	flagUpdates := make(map[PolicyID]bpf.PolicyFlags)
+	r.policyFlagsUpdateFunc = func(id PolicyID, flags bpf.PolicyFlags, _ bpf.PolicyFlagsOperation) error {
+		flagUpdates[id] = flags
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/App/Run.EXE", "/app/run.exe", "/usr/bin/Ä"},
+				}},
+			},
+		},
+	}
+
+	// Matching is case-sensitive by default.
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/App/Run.EXE", "/app/run.exe", "/usr/bin/Ä"}, written[PolicyID(1)])
+	require.Equal(t, bpf.PolicyFlags(0), flagUpdates[PolicyID(1)])
+
+	// Enabling the flag must rewrite the folded executables even if the list didn't change.
+	// Only ASCII letters are folded, like in the BPF program.
+	wp.Spec.CaseInsensitiveMatching = true
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/app/run.exe", "/usr/bin/Ä"}, written[PolicyID(1)])
+	require.Equal(t, bpf.PolicyFlagCaseInsensitive, flagUpdates[PolicyID(1)])
+
+	// Disabling it restores the original executables.
+	wp.Spec.CaseInsensitiveMatching = false
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/App/Run.EXE", "/app/run.exe", "/usr/bin/Ä"}, written[PolicyID(1)])
+	require.Equal(t, bpf.PolicyFlags(0), flagUpdates[PolicyID(1)])
+}
+
+func TestReconcileWP_ExcludedNamespace(t *testing.T) {
+	r := NewTestResolver(t)
+	attached := make(map[CgroupID]PolicyID)
+	r.cgroupToPolicyMapUpdateFunc = func(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error {
+		if op == bpf.AddPolicyToCgroups {
+			for _, id := range cgroupIDs {
+				attached[id] = polID
+			}
+		}
+		return nil
+	}
+	r.SetExcludedNamespaces([]string{"debug"})
+
+	newPod := func(id PodID, namespace string, cgroupID CgroupID) *podEntry {
+		return &podEntry{
+			meta: &PodMeta{
+				ID:        id,
+				Namespace: namespace,
+				Name:      string(id),
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+			},
+			containers: map[ContainerID]*ContainerMeta{
+				ContainerID(id): {CgroupID: cgroupID, Name: c1, ID: ContainerID(id)},
+			},
+		}
+	}
+	newPolicy := func(namespace string) *v1alpha1.WorkloadPolicy {
+		return &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: namespace},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode: "protect",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				},
+			},
+		}
+	}
+
+	r.mu.Lock()
+	r.podCache["debug-pod"] = newPod("debug-pod", "debug", 100)
+	r.podCache["test-pod"] = newPod("test-pod", "test-ns", 200)
+	r.mu.Unlock()
+
+	require.NoError(t, r.ReconcileWP(newPolicy("debug")))
+	require.NoError(t, r.ReconcileWP(newPolicy("test-ns")))
+	// Only the pod outside the excluded namespace is enforced.
+	require.NotContains(t, attached, CgroupID(100))
+	require.Contains(t, attached, CgroupID(200))
+
+	// A new labeled pod in the excluded namespace is skipped, even if its policy doesn't exist.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pod := newPod("other-debug-pod", "debug", 300)
+	pod.meta.Labels[v1alpha1.PolicyLabelKey] = "missing"
+	require.NoError(t, r.applyPolicyToPodIfPresent(pod))
+	require.NoError(t, r.applyPolicyToPodIfPresent(newPod("new-debug-pod", "debug", 400)))
+	require.NotContains(t, attached, CgroupID(300))
+	require.NotContains(t, attached, CgroupID(400))
+}
+
+func TestReconcileWP_ListeningPorts(t *testing.T) {
+	r := NewTestResolver(t)
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "monitor",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {
+					Executables:    v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/usr/sbin/nginx"}},
+					ListeningPorts: []int32{80, 443},
+				},
+				c2: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+	key := wp.NamespacedName()
+
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []int32{80, 443}, r.GetListeningPorts(key, c1))
+	require.Nil(t, r.GetListeningPorts(key, c2))
+	require.Nil(t, r.GetListeningPorts("test-ns/missing", c1))
+
+	wp.Spec.RulesByContainer[c1].ListeningPorts = nil
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Nil(t, r.GetListeningPorts(key, c1))
+}
+
+func TestReconcileWP_AlwaysAllowedExecutables(t *testing.T) {
+	r := NewTestResolver(t)
+	written := make(map[PolicyID][]string)
+	r.policyUpdateBinariesFunc = func(id PolicyID, values []string, _ bpf.PolicyValuesOperation) error {
+		written[id] = values
+		return nil
+	}
+	r.SetAlwaysAllowedExecutables([]string{"/pause", "/bin/sleep"})
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep", "/bin/cat"}}},
+				c2: {},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+
+	state := r.wpState[wp.NamespacedName()]
+	require.Equal(t, []string{"/bin/cat", "/bin/sleep", "/pause"}, written[state.polByContainer[c1]])
+	require.Equal(t, []string{"/bin/sleep", "/pause"}, written[state.polByContainer[c2]],
+		"a container without rules gets the always-allowed executables too")
+	require.Equal(t, []string{"/bin/sleep", "/bin/cat"}, wp.Spec.RulesByContainer[c1].Executables.Allowed,
+		"the policy spec is not modified")
+}
+
+func TestReconcileWP_ExecutableProfiles(t *testing.T) {
+	r := NewTestResolver(t)
+	written := make(map[PolicyID][]string)
+	r.policyUpdateBinariesFunc = func(id PolicyID, values []string, _ bpf.PolicyValuesOperation) error {
+		written[id] = values
+		return nil
+	}
+	lib, err := profiles.Load(fstest.MapFS{
+		"app-1.yaml": {Data: []byte("name: app\nversion: \"1\"\nexecutables: [/bin/app, /bin/sh]\n")},
+	})
+	require.NoError(t, err)
+	r.SetExecutableProfiles(lib)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{
+					Allowed:  []string{"/bin/sh", "/bin/cat"},
+					Profiles: []v1alpha1.ExecutableProfileReference{{Name: "app", Version: "1"}},
+				}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	state := r.wpState[wp.NamespacedName()]
+	require.Equal(t, []string{"/bin/app", "/bin/cat", "/bin/sh"}, written[state.polByContainer[c1]])
+
+	wp.Spec.RulesByContainer[c1].Executables.Profiles[0].Version = "2"
+	require.ErrorIs(t, r.ReconcileWP(wp), profiles.ErrUnknownProfile)
+}
+
+func TestReconcileWP_MaxDistinctExecutables(t *testing.T) {
+	r := NewTestResolver(t)
+	fake := newFakeBPFMaps(r)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+
+	// No limit by default.
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+	require.Empty(t, fake.execLimits)
+
+	wp.Spec.MaxDistinctExecutables = 5
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID]uint32{polID: 5}, fake.execLimits)
+
+	wp.Spec.MaxDistinctExecutables = 0
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Empty(t, fake.execLimits)
+
+	wp.Spec.MaxDistinctExecutables = 3
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID]uint32{polID: 3}, fake.execLimits)
+
+	// The limit is removed with the policy.
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, fake.execLimits)
+}
+
+func TestReconcileWP_AllowedFilesystems(t *testing.T) {
+	r := NewTestResolver(t)
+	fake := newFakeBPFMaps(r)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+
+	// Any filesystem by default.
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+	require.Empty(t, fake.fsMagics)
+
+	overlay, _ := bpf.FilesystemMagic("overlay")
+	ext4, _ := bpf.FilesystemMagic("ext4")
+	wp.Spec.AllowedFilesystems = []string{"overlay", "ext4", "overlay"}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID][]uint32{polID: {ext4, overlay}}, fake.fsMagics)
+
+	wp.Spec.AllowedFilesystems = nil
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Empty(t, fake.fsMagics)
+
+	// The allowed filesystems are removed with the policy.
+	wp.Spec.AllowedFilesystems = []string{"overlay"}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, map[PolicyID][]uint32{polID: {overlay}}, fake.fsMagics)
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, fake.fsMagics)
+}
+
+// policyInfoSeries returns the labels of the policy info series of the namespace.
+func policyInfoSeries(t *testing.T, namespace string) []map[string]string {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(policyInfo)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var series []map[string]string
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] != namespace {
+				continue
+			}
+			require.InDelta(t, 1, metric.GetGauge().GetValue(), 0)
+			series = append(series, labels)
+		}
+	}
+	return series
+}
+
+func TestPolicyInfoMetrics(t *testing.T) {
+	r := NewTestResolver(t)
+	const namespace = "policy-info-ns"
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: namespace},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "monitor",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				c2: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/cat", "/bin/ls"}}},
+			},
+		},
+	}
+
+	require.NoError(t, r.ReconcileWP(wp))
+	require.ElementsMatch(t, []map[string]string{
+		{"namespace": namespace, "policy": "example", "container": c1, "mode": "monitor", "executables": "1"},
+		{"namespace": namespace, "policy": "example", "container": c2, "mode": "monitor", "executables": "2"},
+	}, policyInfoSeries(t, namespace))
+
+	// An update replaces the series whose labels changed and removes the ones of the dropped containers.
+	wp.Spec.Mode = "protect"
+	wp.Spec.RulesByContainer = map[string]*v1alpha1.WorkloadPolicyRules{
+		c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep", "/bin/cat"}}},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.ElementsMatch(t, []map[string]string{
+		{"namespace": namespace, "policy": "example", "container": c1, "mode": "protect", "executables": "2"},
+	}, policyInfoSeries(t, namespace))
+
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, policyInfoSeries(t, namespace))
+}
+
+// TestRestartableInitContainer checks that a native sidecar (an init container with `restartPolicy: Always`)
+// stays enforced for the pod lifetime. Each restart creates a new container instance with the same name,
+// and the exited instance is removed while the new one is running.
+func TestRestartableInitContainer(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	const sidecar = "sidecar"
+
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1:      {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				sidecar: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/proxy"}}},
+			},
+		},
+	}))
+	podMeta := PodMeta{
+		ID:        "test-pod-uid",
+		Namespace: "test-ns",
+		Name:      "test-pod",
+		Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+	}
+	addContainer := func(id ContainerID, name ContainerName, cgID CgroupID) {
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: podMeta,
+			Containers: map[ContainerID]ContainerInput{
+				id: {ContainerMeta: ContainerMeta{ID: id, Name: name, CgroupID: cgID}},
+			},
+		}))
+	}
+	sidecarPolID := r.wpState["test-ns/example"].polByContainer[sidecar]
+
+	// the sidecar starts before the main container and keeps running with it.
+	addContainer("sidecar-1", sidecar, 200)
+	addContainer(cid1, c1, 100)
+	require.Equal(t, sidecarPolID, f.cgroups[200])
+
+	// the sidecar restarts: the new instance is enforced as soon as it starts
+	// and removing the exited one leaves it untouched.
+	addContainer("sidecar-2", sidecar, 201)
+	require.Equal(t, sidecarPolID, f.cgroups[201])
+	require.NoError(t, r.RemovePodContainerFromNri("test-pod-uid", "sidecar-1"))
+	require.NotContains(t, f.cgroups, CgroupID(200))
+	require.Equal(t, sidecarPolID, f.cgroups[201])
+	require.Equal(t, sidecarPolID, r.wpState["test-ns/example"].polByContainer[sidecar])
+	require.Contains(t, f.values, sidecarPolID)
+
+	// removing the main container doesn't affect the sidecar either.
+	require.NoError(t, r.RemovePodContainerFromNri("test-pod-uid", cid1))
+	require.Equal(t, sidecarPolID, f.cgroups[201])
+}
+
+func TestPodRecreatedWithSameName(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	recorder := &traceRecorder{traced: make(map[CgroupID]struct{})}
+	r.traceUpdateFunc = recorder.update
+
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}))
+	polID := r.wpState["test-ns/example"].polByContainer[c1]
+	addPod := func(podID PodID, containerID ContainerID, cgID CgroupID, startTime time.Time) {
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{
+				ID:        podID,
+				Namespace: "test-ns",
+				Name:      "test-pod",
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				containerID: {
+					ContainerMeta: ContainerMeta{ID: containerID, Name: c1, CgroupID: cgID, StartTime: startTime},
+				},
+			},
+		}))
+	}
+	start := time.Now()
+
+	// the pod is deleted and recreated with the same name: the new pod has a new UID and new cgroups.
+	addPod("uid-1", "container-1", 100, start)
+	require.Equal(t, polID, f.cgroups[100])
+	require.NoError(t, r.RemovePodContainerFromNri("uid-1", "container-1"))
+	addPod("uid-2", "container-2", 110, start.Add(time.Minute))
+	require.Equal(t, polID, f.cgroups[110])
+	require.NotContains(t, f.cgroups, CgroupID(100))
+	require.NotContains(t, r.PodCacheSnapshot(), PodID("uid-1"))
+	_, err := r.GetContainerView(100)
+	require.Error(t, err, "the cgroup of the old pod must not resolve anymore")
+	view, err := r.GetContainerView(110)
+	require.NoError(t, err)
+	require.Equal(t, PodID("uid-2"), view.PodMeta.ID)
+
+	// the new pod starts before the containers of the old one are removed.
+	addPod("uid-3", "container-3", 120, start.Add(2*time.Minute))
+	require.Equal(t, polID, f.cgroups[110])
+	require.Equal(t, polID, f.cgroups[120])
+	// the lookups by name target the most recent pod.
+	_, err = r.StartTrace("test-ns", "test-pod", c1, time.Minute)
+	require.NoError(t, err)
+	require.True(t, recorder.isTraced(120))
+	require.False(t, recorder.isTraced(110))
+
+	// removing the old pod leaves the new one untouched.
+	require.NoError(t, r.RemovePodContainerFromNri("uid-2", "container-2"))
+	require.NotContains(t, f.cgroups, CgroupID(110))
+	require.Equal(t, polID, f.cgroups[120])
+	require.True(t, recorder.isTraced(120))
+	require.Equal(t, []PodID{"uid-3"}, slices.Collect(maps.Keys(r.PodCacheSnapshot())))
+	view, err = r.GetContainerView(120)
+	require.NoError(t, err)
+	require.Equal(t, PodID("uid-3"), view.PodMeta.ID)
+}
@@ -0,0 +1,138 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+// RebuildBPFMaps writes the policy BPF maps again from the resolver state and removes the entries the resolver
+// doesn't know about. It is meant to be triggered by an operator when the BPF maps are suspected to have drifted
+// from the resolver state.
+// The maps are never flushed: the entries of each policy are replaced in place, the allowed values by swapping
+// their inner maps and the other rules by writing the new entries before deleting the stale ones, so the
+// containers stay enforced during the rebuild. The stale policy IDs and cgroup associations are removed last.
+// The rebuild continues on errors, so that as much state as possible is restored, and returns all of them.
+func (r *Resolver) RebuildBPFMaps(reader BPFMapsReader) error {
+	defer r.lockTimed(lockOpRebuildMaps)()
+
+	var errs error
+	policies := 0
+	for wpKey, info := range r.wpState {
+		for containerName, polID := range info.polByContainer {
+			if err := r.upsertPolicyIDInBPF(
+				polID, info.allowedByContainer[containerName], info.commandsByContainer[containerName],
+				info.filesByContainer[containerName], info.egressByContainer[containerName],
+				info.keyMode(containerName), info.keyFlags(containerName),
+				info.execLimit, info.fsMagics, bpf.ReplaceValuesInPolicy,
+			); err != nil {
+				errs = errors.Join(errs,
+					fmt.Errorf("failed to rebuild policy for wp %s, container %s: %w", wpKey, containerName, err))
+				continue
+			}
+			policies++
+		}
+		for containerName, polID := range info.gracePolByContainer {
+			if err := r.upsertPolicyIDInBPF(
+				polID, info.allowedByContainer[containerName], info.commandsByContainer[containerName],
+				info.filesByContainer[containerName], info.egressByContainer[containerName],
+				policymode.Monitor, info.keyFlags(containerName),
+				info.execLimit, info.fsMagics, bpf.ReplaceValuesInPolicy,
+			); err != nil {
+				errs = errors.Join(errs,
+					fmt.Errorf("failed to rebuild grace policy for wp %s, container %s: %w", wpKey, containerName, err))
+				continue
+			}
+			policies++
+		}
+	}
+
+	pods := 0
+	for _, state := range r.podCache {
+		policyName := state.policyName()
+		if policyName == "" || r.isNamespaceExcluded(state.podNamespace()) {
+			continue
+		}
+		// Pods whose policy is not in the resolver state have no association to restore.
+		info := r.wpState[fmt.Sprintf("%s/%s", state.podNamespace(), policyName)]
+		if info == nil || !r.serviceAccountAllowed(state, info) {
+			continue
+		}
+		if err := r.applyPolicyToPod(state, info.polByContainer, info.gracePolByContainer); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		pods++
+	}
+
+	stalePolicies, err := r.removeStalePolicyIDs()
+	errs = errors.Join(errs, err)
+	staleCgroups, err := r.removeStaleCgroups(reader)
+	errs = errors.Join(errs, err)
+
+	r.logger.Info("rebuilt BPF maps",
+		"policies", policies,
+		"pods", pods,
+		"stalePolicies", stalePolicies,
+		"staleCgroups", staleCgroups,
+		"failed", errs != nil)
+	return errs
+}
+
+// expectedCgroups returns the policy ID each cgroup must be associated with in BPF, as written by
+// applyPolicyToPod.
+// This must be called with the resolver lock held.
+func (r *Resolver) expectedCgroups() map[CgroupID]PolicyID {
+	expected := make(map[CgroupID]PolicyID)
+	for _, state := range r.podCache {
+		policyName := state.policyName()
+		if policyName == "" || r.isNamespaceExcluded(state.podNamespace()) {
+			continue
+		}
+		info := r.wpState[fmt.Sprintf("%s/%s", state.podNamespace(), policyName)]
+		if info == nil || !r.serviceAccountAllowed(state, info) {
+			continue
+		}
+		deferred := r.enforcementDeferred(state)
+		for _, container := range state.containers {
+			key := r.enforcedPolicyKey(info, container)
+			polID, ok := info.polByContainer[key]
+			if !ok {
+				continue
+			}
+			if gracePolID, hasGrace := info.gracePolByContainer[key]; deferred && hasGrace {
+				polID = gracePolID
+			}
+			expected[container.CgroupID] = polID
+		}
+	}
+	return expected
+}
+
+// removeStaleCgroups removes from the cgroup to policy BPF map the cgroups associated with another policy ID
+// than the expected one, e.g. the cgroups of containers whose removal failed. It returns the number of removed
+// cgroups.
+// This must be called with the resolver lock held.
+func (r *Resolver) removeStaleCgroups(reader BPFMapsReader) (int, error) {
+	inBPF, err := reader.DumpCgroupToPolicy()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the cgroups from BPF: %w", err)
+	}
+	expected := r.expectedCgroups()
+	var errs error
+	removed := 0
+	for cgID, polID := range inBPF {
+		if expectedID, ok := expected[cgID]; ok && expectedID == polID {
+			continue
+		}
+		r.logger.Warn("removing stale cgroup from BPF maps", "cgroupID", cgID, "policyID", polID)
+		if err = r.cgroupToPolicyMapUpdateFunc(PolicyIDNone, []CgroupID{cgID}, bpf.RemoveCgroups); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to remove stale cgroup %d: %w", cgID, err))
+			continue
+		}
+		removed++
+	}
+	return removed, errs
+}
@@ -0,0 +1,216 @@
+package resolver
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeBPFMaps mimics the content of the policy BPF maps.
+type fakeBPFMaps struct {
+	values     map[PolicyID][]string
+	modes      map[PolicyID]policymode.Mode
+	flags      map[PolicyID]bpf.PolicyFlags
+	execLimits map[PolicyID]uint32
+	fsMagics   map[PolicyID][]uint32
+	commands   map[PolicyID][]bpf.Command
+	files      map[PolicyID]bpf.Files
+	egress     map[PolicyID]bpf.Egress
+	cgroups    map[CgroupID]PolicyID
+	globalDeny []string
+}
+
+func newFakeBPFMaps(r *Resolver) *fakeBPFMaps {
+	f := &fakeBPFMaps{
+		values:     make(map[PolicyID][]string),
+		modes:      make(map[PolicyID]policymode.Mode),
+		flags:      make(map[PolicyID]bpf.PolicyFlags),
+		execLimits: make(map[PolicyID]uint32),
+		fsMagics:   make(map[PolicyID][]uint32),
+		commands:   make(map[PolicyID][]bpf.Command),
+		files:      make(map[PolicyID]bpf.Files),
+		egress:     make(map[PolicyID]bpf.Egress),
+		cgroups:    make(map[CgroupID]PolicyID),
+	}
+	r.policyUpdateBinariesFunc = func(id PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		if op == bpf.RemoveValuesFromPolicy {
+			delete(f.values, id)
+		} else {
+			f.values[id] = slices.Sorted(slices.Values(values))
+		}
+		return nil
+	}
+	r.policyModeUpdateFunc = func(id PolicyID, mode policymode.Mode, op bpf.PolicyModeOperation) error {
+		if op == bpf.DeleteMode {
+			delete(f.modes, id)
+		} else {
+			f.modes[id] = mode
+		}
+		return nil
+	}
+	r.policyFlagsUpdateFunc = func(id PolicyID, flags bpf.PolicyFlags, op bpf.PolicyFlagsOperation) error {
+		if op == bpf.DeleteFlags {
+			delete(f.flags, id)
+		} else {
+			f.flags[id] = flags
+		}
+		return nil
+	}
+	r.policyExecLimitUpdateFunc = func(id PolicyID, limit uint32, op bpf.PolicyExecLimitOperation) error {
+		if op == bpf.DeleteExecLimit || limit == 0 {
+			delete(f.execLimits, id)
+		} else {
+			f.execLimits[id] = limit
+		}
+		return nil
+	}
+	r.policyFsTypesUpdateFunc = func(id PolicyID, magics []uint32, op bpf.PolicyFsTypesOperation) error {
+		if op == bpf.DeleteFsTypes || len(magics) == 0 {
+			delete(f.fsMagics, id)
+		} else {
+			f.fsMagics[id] = slices.Clone(magics)
+		}
+		return nil
+	}
+	r.policyCommandsUpdateFunc = func(id PolicyID, commands []bpf.Command, op bpf.PolicyCommandsOperation) error {
+		if op == bpf.DeleteCommands || len(commands) == 0 {
+			delete(f.commands, id)
+		} else {
+			f.commands[id] = commands
+		}
+		return nil
+	}
+	r.policyFilesUpdateFunc = func(id PolicyID, files bpf.Files, op bpf.PolicyFilesOperation) error {
+		if op == bpf.DeleteFiles || files.Flags() == 0 {
+			delete(f.files, id)
+		} else {
+			f.files[id] = files
+		}
+		return nil
+	}
+	r.policyEgressUpdateFunc = func(id PolicyID, egress bpf.Egress, op bpf.PolicyEgressOperation) error {
+		if op == bpf.DeleteEgress || egress.Flags() == 0 {
+			delete(f.egress, id)
+		} else {
+			f.egress[id] = egress
+		}
+		return nil
+	}
+	r.cgroupToPolicyMapUpdateFunc = func(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error {
+		switch op {
+		case bpf.AddPolicyToCgroups:
+			for _, cgID := range cgroupIDs {
+				f.cgroups[cgID] = polID
+			}
+		case bpf.RemovePolicy:
+			maps.DeleteFunc(f.cgroups, func(_ CgroupID, id PolicyID) bool { return id == polID })
+		case bpf.RemoveCgroups:
+			for _, cgID := range cgroupIDs {
+				delete(f.cgroups, cgID)
+			}
+		}
+		return nil
+	}
+	r.globalDenyUpdateFunc = func(entries []string) error {
+		f.globalDeny = slices.Clone(entries)
+		return nil
+	}
+	r.cgroupPolicyLookupFunc = func(cgID CgroupID) (PolicyID, bool, error) {
+		polID, ok := f.cgroups[cgID]
+		return polID, ok, nil
+	}
+	r.policyIDsListFunc = func() ([]PolicyID, error) {
+		return slices.Collect(maps.Keys(f.modes)), nil
+	}
+	return f
+}
+
+// newTestPolicy returns a protect policy allowing /bin/sleep in each of the given containers.
+func newTestPolicy(namespace, name string, containers ...ContainerName) *v1alpha1.WorkloadPolicy {
+	rules := make(map[string]*v1alpha1.WorkloadPolicyRules, len(containers))
+	for _, container := range containers {
+		rules[container] = &v1alpha1.WorkloadPolicyRules{
+			Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}},
+		}
+	}
+	return &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       v1alpha1.WorkloadPolicySpec{Mode: "protect", RulesByContainer: rules},
+	}
+}
+
+func (f *fakeBPFMaps) snapshot() *fakeBPFMaps {
+	return &fakeBPFMaps{
+		values:     maps.Clone(f.values),
+		modes:      maps.Clone(f.modes),
+		flags:      maps.Clone(f.flags),
+		execLimits: maps.Clone(f.execLimits),
+		fsMagics:   maps.Clone(f.fsMagics),
+		commands:   maps.Clone(f.commands),
+		files:      maps.Clone(f.files),
+		egress:     maps.Clone(f.egress),
+		cgroups:    maps.Clone(f.cgroups),
+		globalDeny: slices.Clone(f.globalDeny),
+	}
+}
+
+func TestRebuildBPFMaps(t *testing.T) {
+	r := NewTestResolver(t)
+	fake := newFakeBPFMaps(r)
+
+	r.mu.Lock()
+	r.podCache["test-pod-uid"] = &podEntry{
+		meta: &PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		containers: map[ContainerID]*ContainerMeta{
+			cid1: {CgroupID: 100, Name: c1, ID: cid1},
+			cid2: {CgroupID: 101, Name: c2, ID: cid2},
+		},
+	}
+	r.mu.Unlock()
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:                   policymode.ProtectString,
+			BlockSuidExec:          true,
+			MaxDistinctExecutables: 10,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				c2: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/cat", "/bin/ls"}}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+
+	expected := fake.snapshot()
+	state := r.wpState[wp.NamespacedName()]
+	require.Equal(t, map[CgroupID]PolicyID{
+		100: state.polByContainer[c1],
+		101: state.polByContainer[c2],
+	}, expected.cgroups)
+
+	// Corrupt the maps: drop a policy, change a mode, detach a cgroup and add stale entries,
+	// including a cgroup attached to a known policy.
+	delete(fake.values, state.polByContainer[c1])
+	fake.modes[state.polByContainer[c2]] = policymode.Monitor
+	delete(fake.execLimits, state.polByContainer[c2])
+	delete(fake.cgroups, 101)
+	fake.values[42] = []string{"/bin/stale"}
+	fake.modes[42] = policymode.Protect
+	fake.cgroups[200] = 42
+	fake.cgroups[300] = state.polByContainer[c1]
+
+	require.NoError(t, r.RebuildBPFMaps(fake))
+	require.Equal(t, expected, fake)
+}
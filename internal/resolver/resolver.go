@@ -2,10 +2,14 @@ package resolver
 
 import (
 	"log/slog"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/profiles"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
 )
 
@@ -14,16 +18,88 @@ type Resolver struct {
 	mu              sync.Mutex
 	logger          *slog.Logger
 	nriSynchronized atomic.Bool
+	// nriConnected is true while the NRI plugin is connected to the runtime, unlike nriSynchronized
+	// it is reset when the connection is lost.
+	nriConnected atomic.Bool
+	// forceMonitorMode applies the policies in protect mode in monitor mode.
+	forceMonitorMode bool
+	// enforceAfterReadiness keeps the containers of a pod in monitor mode until the pod is Ready.
+	enforceAfterReadiness bool
+	// excludedNamespaces contains the namespaces where no policy is ever applied,
+	// even if their pods carry the policy label.
+	excludedNamespaces map[string]struct{}
+	// alwaysAllowed contains the executables merged into the allow list of every policy.
+	alwaysAllowed []string
+	// globalDeny contains the executables denied in every policy, nil when there is none.
+	globalDeny *globalDenyList
+	// profiles contains the executable profiles that can be referenced by the policies.
+	profiles *profiles.Library
+	// devAllowed contains the allowed executables replacing the ones declared in the spec, by policy and container.
+	// It is nil unless the developer mode is enabled, see SetDevMode.
+	devAllowed map[NamespacedPolicyName]map[ContainerName][]string
+	// readyPods contains the pods reported as Ready by the pod informer.
+	// It is kept apart from podCache because readiness can be received before the NRI events.
+	readyPods map[PodID]struct{}
+	// lifecycleExecutables contains the executables allowed in the containers during their startup phase,
+	// see SetLifecycleExecutables.
+	lifecycleExecutables []string
+	// startedContainers contains the containers reported as started by the pod informer, with their pod.
+	// Like readyPods, it is kept apart from podCache because the start can be received before the NRI events.
+	startedContainers map[ContainerID]PodID
+	// terminationRelaxation selects the policies enforced in monitor mode on the terminating pods.
+	terminationRelaxation TerminationRelaxation
+	// terminatingPods contains the pods reported as terminating by the pod informer.
+	// Like readyPods, it is kept apart from podCache because the termination can be received before the NRI events.
+	terminatingPods map[PodID]struct{}
+	// serviceAccountChecks is true when the pod informer reports the service accounts of the pods.
+	serviceAccountChecks bool
+	// podServiceAccounts contains the service accounts of the pods reported by the pod informer.
+	// Like readyPods, it is kept apart from podCache because NRI doesn't report the service account.
+	podServiceAccounts map[PodID]string
 	// todo!: we should add a cache with deleted pods/containers so that we can resolve also recently deleted ones
 	podCache        map[PodID]*podEntry
 	cgroupIDToPodID map[CgroupID]PodID
+	// containerIDToPodID is the reverse index used to resolve containers by their runtime ID.
+	containerIDToPodID map[ContainerID]PodID
+	// traces contains the containers whose exec decisions are all reported, see StartTrace.
+	traces map[CgroupID]*traceEntry
+	// observedExecutables contains the allowed executables run with each policy ID since the agent started.
+	observedExecutables map[PolicyID]map[string]struct{}
+	// modeRepairs counts the repairs of the mode of each policy ID, see RepairPolicyMode.
+	modeRepairs map[PolicyID]int
+	// mapFullAction defines how a policy that doesn't fit in the BPF maps is handled.
+	mapFullAction MapFullAction
+	// policyMapCapacity is the maximum number of policy IDs of the BPF maps, 0 when unknown.
+	policyMapCapacity uint32
+	// namespacePolicyIDQuota is the maximum number of policy IDs of the policies of a namespace, 0 for no limit.
+	namespacePolicyIDQuota int
+	// namespacePolicyIDs counts the policy IDs in use by namespace, policyIDNamespaces is the reverse index.
+	namespacePolicyIDs map[string]int
+	policyIDNamespaces map[PolicyID]string
+	// lockHoldWarnThreshold is the time an operation can hold mu before a warning is logged, 0 to never warn.
+	lockHoldWarnThreshold time.Duration
+	// mapUpdateThrottle limits the rate of the writes into the BPF maps, nil when they are not limited.
+	mapUpdateThrottle *mapUpdateThrottle
 
 	nextPolicyID                PolicyID
 	wpState                     map[NamespacedPolicyName]*wpInfo
 	policyUpdateBinariesFunc    func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error
 	policyModeUpdateFunc        func(policyID PolicyID, mode policymode.Mode, op bpf.PolicyModeOperation) error
+	policyFlagsUpdateFunc       func(policyID PolicyID, flags bpf.PolicyFlags, op bpf.PolicyFlagsOperation) error
+	policyExecLimitUpdateFunc   func(policyID PolicyID, limit uint32, op bpf.PolicyExecLimitOperation) error
+	policyFsTypesUpdateFunc     func(policyID PolicyID, magics []uint32, op bpf.PolicyFsTypesOperation) error
+	policyCommandsUpdateFunc    func(policyID PolicyID, commands []bpf.Command, op bpf.PolicyCommandsOperation) error
+	policyFilesUpdateFunc       func(policyID PolicyID, files bpf.Files, op bpf.PolicyFilesOperation) error
+	policyEgressUpdateFunc      func(policyID PolicyID, egress bpf.Egress, op bpf.PolicyEgressOperation) error
+	globalDenyUpdateFunc        func(entries []string) error
 	cgTrackerUpdateFunc         func(cgID uint64, cgroupPath string) error
 	cgroupToPolicyMapUpdateFunc func(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error
+	policyIDsListFunc           func() ([]PolicyID, error)
+	traceUpdateFunc             func(cgID CgroupID, op bpf.TraceOperation) error
+	policySeenValuesFunc        func(policyID PolicyID) ([]string, error)
+	cgroupPolicyLookupFunc      func(cgID CgroupID) (PolicyID, bool, error)
+	// cgroupIDFromPathFunc returns the ID of a cgroup from its path, see CheckCgroupPath.
+	cgroupIDFromPathFunc func(cgroupPath string) (CgroupID, error)
 }
 
 func NewResolver(
@@ -32,18 +108,136 @@ func NewResolver(
 	cgroupToPolicyMapUpdateFunc func(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error,
 	policyUpdateBinariesFunc func(policyID uint64, values []string, op bpf.PolicyValuesOperation) error,
 	policyModeUpdateFunc func(policyID uint64, mode policymode.Mode, op bpf.PolicyModeOperation) error,
+	policyFlagsUpdateFunc func(policyID uint64, flags bpf.PolicyFlags, op bpf.PolicyFlagsOperation) error,
+	policyExecLimitUpdateFunc func(policyID uint64, limit uint32, op bpf.PolicyExecLimitOperation) error,
+	policyFsTypesUpdateFunc func(policyID uint64, magics []uint32, op bpf.PolicyFsTypesOperation) error,
+	policyCommandsUpdateFunc func(policyID uint64, commands []bpf.Command, op bpf.PolicyCommandsOperation) error,
+	policyFilesUpdateFunc func(policyID uint64, files bpf.Files, op bpf.PolicyFilesOperation) error,
+	policyEgressUpdateFunc func(policyID uint64, egress bpf.Egress, op bpf.PolicyEgressOperation) error,
+	globalDenyUpdateFunc func(entries []string) error,
+	policyIDsListFunc func() ([]uint64, error),
+	traceUpdateFunc func(cgID uint64, op bpf.TraceOperation) error,
+	policySeenValuesFunc func(policyID uint64) ([]string, error),
+	cgroupPolicyLookupFunc func(cgID uint64) (uint64, bool, error),
 ) (*Resolver, error) {
 	r := &Resolver{
 		logger:                      logger.With("component", "resolver"),
 		podCache:                    make(map[PodID]*podEntry),
 		cgroupIDToPodID:             make(map[CgroupID]PodID),
+		containerIDToPodID:          make(map[ContainerID]PodID),
+		readyPods:                   make(map[PodID]struct{}),
+		startedContainers:           make(map[ContainerID]PodID),
+		terminationRelaxation:       TerminationRelaxationNone,
+		terminatingPods:             make(map[PodID]struct{}),
+		podServiceAccounts:          make(map[PodID]string),
+		traces:                      make(map[CgroupID]*traceEntry),
+		observedExecutables:         make(map[PolicyID]map[string]struct{}),
+		modeRepairs:                 make(map[PolicyID]int),
+		mapFullAction:               MapFullReject,
+		namespacePolicyIDs:          make(map[string]int),
+		policyIDNamespaces:          make(map[PolicyID]string),
 		cgTrackerUpdateFunc:         cgTrackerUpdateFunc,
 		cgroupToPolicyMapUpdateFunc: cgroupToPolicyMapUpdateFunc,
 		policyUpdateBinariesFunc:    policyUpdateBinariesFunc,
 		policyModeUpdateFunc:        policyModeUpdateFunc,
+		policyFlagsUpdateFunc:       policyFlagsUpdateFunc,
+		policyExecLimitUpdateFunc:   policyExecLimitUpdateFunc,
+		policyFsTypesUpdateFunc:     policyFsTypesUpdateFunc,
+		policyCommandsUpdateFunc:    policyCommandsUpdateFunc,
+		policyFilesUpdateFunc:       policyFilesUpdateFunc,
+		policyEgressUpdateFunc:      policyEgressUpdateFunc,
+		globalDenyUpdateFunc:        globalDenyUpdateFunc,
+		policyIDsListFunc:           policyIDsListFunc,
+		traceUpdateFunc:             traceUpdateFunc,
+		policySeenValuesFunc:        policySeenValuesFunc,
+		cgroupPolicyLookupFunc:      cgroupPolicyLookupFunc,
+		cgroupIDFromPathFunc:        cgroups.GetCgroupIDFromPath,
 		wpState:                     make(map[NamespacedPolicyName]*wpInfo),
 		nextPolicyID:                PolicyID(1),
 	}
 
 	return r, nil
 }
+
+// SetForceMonitorMode enables or disables the global switch that forces every policy in protect mode into
+// monitor mode. The policies in audit mode, which never block, are left untouched.
+// It affects the policies reconciled after the call.
+func (r *Resolver) SetForceMonitorMode(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.forceMonitorMode = enabled
+}
+
+// SetEnforceAfterReadiness enables or disables the readiness-gated enforcement:
+// the containers of a pod are enforced in monitor mode until MarkPodReady reports the pod as Ready.
+// It must be called before the policies are reconciled.
+func (r *Resolver) SetEnforceAfterReadiness(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enforceAfterReadiness = enabled
+}
+
+// SetExcludedNamespaces sets the namespaces where no policy is applied to pods.
+// It must be called before the policies are reconciled.
+func (r *Resolver) SetExcludedNamespaces(namespaces []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.excludedNamespaces = make(map[string]struct{}, len(namespaces))
+	for _, ns := range namespaces {
+		r.excludedNamespaces[ns] = struct{}{}
+	}
+}
+
+// SetAlwaysAllowedExecutables sets the executables allowed in every policy, regardless of its rules.
+// It must be called before the policies are reconciled.
+func (r *Resolver) SetAlwaysAllowedExecutables(executables []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alwaysAllowed = slices.Clone(executables)
+}
+
+// SetExecutableProfiles sets the library used to expand the executable profiles referenced by the policies.
+// It must be called before the policies are reconciled.
+func (r *Resolver) SetExecutableProfiles(lib *profiles.Library) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.profiles = lib
+}
+
+// isNamespaceExcluded must be called with the resolver lock held.
+func (r *Resolver) isNamespaceExcluded(namespace string) bool {
+	_, ok := r.excludedNamespaces[namespace]
+	return ok
+}
+
+// MarkPodReady records that a pod reached the Ready condition and, when the readiness-gated enforcement is enabled,
+// switches its containers to the declared mode of their policy.
+// A pod that lost its readiness is not moved back to monitor mode, otherwise a workload could escape the enforcement.
+func (r *Resolver) MarkPodReady(podID PodID) error {
+	defer r.lockTimed(lockOpMarkPodReady)()
+
+	if _, ok := r.readyPods[podID]; ok {
+		return nil
+	}
+	r.readyPods[podID] = struct{}{}
+
+	state, ok := r.podCache[podID]
+	if !r.enforceAfterReadiness || !ok {
+		return nil
+	}
+	r.logger.Info("pod is ready, enforcing the declared policy mode",
+		"pod", state.podName(),
+		"namespace", state.podNamespace())
+	return r.applyPolicyToPodIfPresent(state)
+}
+
+// ForgetPodReadiness drops the readiness of a pod deleted from the cluster, the start of its containers,
+// its termination and its service account.
+func (r *Resolver) ForgetPodReadiness(podID PodID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.readyPods, podID)
+	delete(r.terminatingPods, podID)
+	delete(r.podServiceAccounts, podID)
+	r.forgetStartedContainers(podID)
+}
@@ -0,0 +1,107 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// ErrPolicyBindingDenied is returned by SetPodServiceAccount when the service account of a pod is not allowed
+// to use the policy referenced by its label.
+var ErrPolicyBindingDenied = errors.New("the service account of the pod is not allowed to use the policy")
+
+// ErrServiceAccountUnknown is returned by AddPodContainerFromNri when the policy of the pod is restricted to some
+// service accounts and the pod informer has not reported the service account of the pod yet: the container is
+// not enforced until it is reported.
+var ErrServiceAccountUnknown = errors.New("the service account of the pod has not been reported yet")
+
+// serviceAccountsUnknownMsg is the status of the policies restricted to some service accounts
+// when the agent doesn't watch the pods, so that none of their pods is enforced.
+const serviceAccountsUnknownMsg = "the policy is restricted to some service accounts but the agent doesn't watch " +
+	"the service accounts of the pods, the policy is not enforced"
+
+// SetServiceAccountChecks enables the pod informer reporting the service accounts of the pods
+// with SetPodServiceAccount. When it is disabled, the policies restricted to some service accounts are never
+// enforced. It must be called before the policies are reconciled.
+func (r *Resolver) SetServiceAccountChecks(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.serviceAccountChecks = enabled
+}
+
+// serviceAccountAllowed returns true when the service account of the pod is allowed to use the policy.
+// The pods whose service account has not been reported yet are not allowed to use a restricted policy:
+// NRI doesn't carry the service account, it only arrives through the pod informer, see serviceAccountPending.
+// This must be called with the resolver lock held.
+func (r *Resolver) serviceAccountAllowed(state *podEntry, info *wpInfo) bool {
+	if len(info.allowedServiceAccounts) == 0 {
+		return true
+	}
+	serviceAccount, ok := r.podServiceAccounts[state.meta.ID]
+	return ok && slices.Contains(info.allowedServiceAccounts, serviceAccount)
+}
+
+// serviceAccountPending returns true when the policy of the pod is restricted to some service accounts and
+// the pod informer has not reported the service account of the pod yet, so that its containers are not enforced.
+// This must be called with the resolver lock held.
+func (r *Resolver) serviceAccountPending(state *podEntry) bool {
+	if !r.serviceAccountChecks || state.policyName() == "" || r.isNamespaceExcluded(state.podNamespace()) {
+		return false
+	}
+	info := r.wpState[state.podNamespace()+"/"+state.policyName()]
+	if info == nil || len(info.allowedServiceAccounts) == 0 {
+		return false
+	}
+	_, known := r.podServiceAccounts[state.meta.ID]
+	return !known
+}
+
+// detachPod removes the cgroups of the containers of the pod from the cgroup to policy map.
+// This must be called with the resolver lock held.
+func (r *Resolver) detachPod(state *podEntry) error {
+	cgroups := make([]CgroupID, 0, len(state.containers))
+	for _, container := range state.containers {
+		cgroups = append(cgroups, container.CgroupID)
+	}
+	if len(cgroups) == 0 {
+		return nil
+	}
+	if err := r.cgroupToPolicyMapUpdateFunc(PolicyIDNone, cgroups, bpf.RemoveCgroups); err != nil {
+		return fmt.Errorf("failed to remove cgroups for pod %s: %w", state.podName(), err)
+	}
+	return nil
+}
+
+// SetPodServiceAccount records the service account of a pod, reported by the pod informer, and enforces
+// the policy of the pod once its service account is allowed to use it.
+// It returns an error wrapping ErrPolicyBindingDenied when the pod is known and its service account
+// is not allowed to use its policy: the policy is not enforced on the pod.
+func (r *Resolver) SetPodServiceAccount(podID PodID, serviceAccount string) error {
+	defer r.lockTimed(lockOpSetServiceAccount)()
+
+	previous, known := r.podServiceAccounts[podID]
+	r.podServiceAccounts[podID] = serviceAccount
+
+	state, ok := r.podCache[podID]
+	if !ok || state.policyName() == "" || r.isNamespaceExcluded(state.podNamespace()) {
+		return nil
+	}
+	info := r.wpState[state.podNamespace()+"/"+state.policyName()]
+	if info == nil || len(info.allowedServiceAccounts) == 0 {
+		return nil
+	}
+	if !slices.Contains(info.allowedServiceAccounts, serviceAccount) {
+		return fmt.Errorf("%w: pod '%s/%s', service account %q, policy %q", ErrPolicyBindingDenied,
+			state.podNamespace(), state.podName(), serviceAccount, state.policyName())
+	}
+	if known && previous == serviceAccount {
+		return nil
+	}
+	r.logger.Info("service account allowed to use the policy, enforcing it",
+		"pod", state.podName(),
+		"namespace", state.podNamespace(),
+		"serviceAccount", serviceAccount)
+	return r.applyPolicyToPodIfPresent(state)
+}
@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+)
+
+func TestAllowedServiceAccounts(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	r.SetServiceAccountChecks(true)
+
+	restricted := newTestPolicy("test-ns", "restricted", c1)
+	restricted.Spec.AllowedServiceAccounts = []string{"team-a"}
+	require.NoError(t, r.ReconcileWP(restricted))
+	require.NoError(t, r.ReconcileWP(newTestPolicy("test-ns", "open", c1)))
+
+	addPod := func(podID PodID, policyName string, containerID ContainerID, cgroupID CgroupID) error {
+		return r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{
+				ID:        podID,
+				Namespace: "test-ns",
+				Name:      podID,
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: policyName},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				containerID: {ContainerMeta: ContainerMeta{ID: containerID, Name: c1, CgroupID: cgroupID}},
+			},
+		})
+	}
+	const permittedCg, deniedCg, openCg = CgroupID(100), CgroupID(101), CgroupID(102)
+
+	// the service account of the permitted pod is reported before NRI.
+	require.NoError(t, r.SetPodServiceAccount("permitted-pod", "team-a"))
+	require.NoError(t, addPod("permitted-pod", "restricted", cid1, permittedCg))
+	require.Contains(t, f.cgroups, permittedCg)
+
+	// the pod is not enforced until its service account is known, and then only if it is allowed.
+	err := addPod("denied-pod", "restricted", cid2, deniedCg)
+	require.ErrorIs(t, err, ErrServiceAccountUnknown)
+	require.NotContains(t, f.cgroups, deniedCg)
+	err = r.SetPodServiceAccount("denied-pod", "team-b")
+	require.ErrorIs(t, err, ErrPolicyBindingDenied)
+	require.ErrorContains(t, err, `service account "team-b", policy "restricted"`)
+	require.NotContains(t, f.cgroups, deniedCg)
+	wc, ok := r.GetWorkloadContext(deniedCg)
+	require.True(t, ok)
+	require.Empty(t, wc.Policy)
+
+	// a policy without restriction is enforced on every pod of the namespace.
+	require.NoError(t, r.SetPodServiceAccount("open-pod", "team-b"))
+	require.NoError(t, addPod("open-pod", "open", "container-3", openCg))
+	require.Contains(t, f.cgroups, openCg)
+
+	// allowing the service account enforces the policy on the pod.
+	restricted.Spec.AllowedServiceAccounts = []string{"team-a", "team-b"}
+	require.NoError(t, r.ReconcileWP(restricted))
+	require.Contains(t, f.cgroups, deniedCg)
+	require.NoError(t, r.SetPodServiceAccount("denied-pod", "team-b"))
+
+	// removing it detaches the pod.
+	restricted.Spec.AllowedServiceAccounts = []string{"team-a"}
+	require.NoError(t, r.ReconcileWP(restricted))
+	require.NotContains(t, f.cgroups, deniedCg)
+	require.Contains(t, f.cgroups, permittedCg)
+
+	r.ForgetPodReadiness("denied-pod")
+	require.NotContains(t, r.podServiceAccounts, "denied-pod")
+}
+
+func TestAllowedServiceAccountsNotWatched(t *testing.T) {
+	r := NewTestResolver(t)
+	newFakeBPFMaps(r)
+
+	restricted := newTestPolicy("test-ns", "restricted", c1)
+	restricted.Spec.AllowedServiceAccounts = []string{"team-a"}
+	require.NoError(t, r.ReconcileWP(restricted))
+	status := r.GetPolicyStatuses()[restricted.NamespacedName()]
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_READY, status.State)
+	require.Equal(t, serviceAccountsUnknownMsg, status.Message)
+}
@@ -0,0 +1,90 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// CleanupStalePolicyIDs removes from the BPF maps the policy IDs stored in the mode map
+// that are not referenced by any workload policy anymore, e.g. because a failure interrupted
+// their removal. It returns the number of removed policy IDs.
+func (r *Resolver) CleanupStalePolicyIDs() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.removeStalePolicyIDs()
+}
+
+// removeStalePolicyIDs removes the policy IDs present in BPF but unknown to the resolver state and returns
+// the number of removed policy IDs.
+// This must be called with the resolver lock held.
+func (r *Resolver) removeStalePolicyIDs() (int, error) {
+	ids, err := r.policyIDsListFunc()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list policy IDs: %w", err)
+	}
+
+	known := make(map[PolicyID]struct{})
+	for _, info := range r.wpState {
+		for _, polID := range info.polByContainer {
+			known[polID] = struct{}{}
+		}
+		for _, polID := range info.gracePolByContainer {
+			known[polID] = struct{}{}
+		}
+	}
+
+	var errs error
+	removed := 0
+	for _, polID := range ids {
+		if _, ok := known[polID]; ok {
+			continue
+		}
+		r.logger.Warn("removing stale policy ID from BPF maps", "id", polID)
+		// The cgroups could still be associated to the policy if the removal failed early.
+		if err = r.cgroupToPolicyMapUpdateFunc(polID, []CgroupID{}, bpf.RemovePolicy); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to remove stale policy %d from cgroup map: %w", polID, err))
+			continue
+		}
+		if err = r.clearPolicyIDFromBPF(polID); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to clear stale policy %d: %w", polID, err))
+			continue
+		}
+		removed++
+	}
+	return removed, errs
+}
+
+// RunStalePolicyIDsCleanup removes the stale policy IDs right away and then every interval until ctx is done.
+// A zero interval only runs the startup cleanup.
+func (r *Resolver) RunStalePolicyIDsCleanup(ctx context.Context, interval time.Duration) error {
+	r.cleanupStalePolicyIDs(ctx)
+	if interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.cleanupStalePolicyIDs(ctx)
+		}
+	}
+}
+
+func (r *Resolver) cleanupStalePolicyIDs(ctx context.Context) {
+	removed, err := r.CleanupStalePolicyIDs()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to clean up stale policy IDs", "removed", removed, "error", err)
+		return
+	}
+	if removed > 0 {
+		r.logger.InfoContext(ctx, "cleaned up stale policy IDs", "removed", removed)
+	}
+}
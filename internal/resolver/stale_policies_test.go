@@ -0,0 +1,53 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCleanupStalePolicyIDs(t *testing.T) {
+	r := NewTestResolver(t)
+	fake := newFakeBPFMaps(r)
+	r.policyIDsListFunc = func() ([]PolicyID, error) {
+		ids := make([]PolicyID, 0, len(fake.modes))
+		for id := range fake.modes {
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	expected := fake.snapshot()
+
+	// Nothing to clean up.
+	removed, err := r.CleanupStalePolicyIDs()
+	require.NoError(t, err)
+	require.Zero(t, removed)
+	require.Equal(t, expected, fake)
+
+	// A policy whose deletion has been interrupted after the cgroup detachment.
+	fake.values[42] = []string{"/bin/cat"}
+	fake.modes[42] = policymode.Monitor
+	fake.flags[42] = 0
+	// A policy whose deletion has been interrupted before the cgroup detachment.
+	fake.modes[43] = policymode.Protect
+	fake.cgroups[300] = 43
+
+	removed, err = r.CleanupStalePolicyIDs()
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+	require.Equal(t, expected, fake)
+}
@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+// activeTemporaryExecutables returns the paths of the temporary executables not expired at now.
+func activeTemporaryExecutables(entries []v1alpha1.TemporaryExecutable, now time.Time) []string {
+	var active []string
+	for _, entry := range entries {
+		if entry.ExpiresAt.Time.After(now) {
+			active = append(active, entry.Path)
+		}
+	}
+	return active
+}
+
+// expiredTemporaryExecutables returns the paths of the temporary executables of the policy
+// that expired within (from, to].
+func expiredTemporaryExecutables(wp *v1alpha1.WorkloadPolicy, from, to time.Time) []string {
+	var expired []string
+	for _, rules := range wp.Spec.RulesByContainer {
+		for _, entry := range rules.Executables.Temporary {
+			if entry.ExpiresAt.Time.After(from) && !entry.ExpiresAt.Time.After(to) {
+				expired = append(expired, entry.Path)
+			}
+		}
+	}
+	return expired
+}
+
+// nextTemporaryExpiry returns the earliest expiration after now among the temporary executables of the policy.
+func nextTemporaryExpiry(wp *v1alpha1.WorkloadPolicy, now time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, rules := range wp.Spec.RulesByContainer {
+		for _, entry := range rules.Executables.Temporary {
+			expiresAt := entry.ExpiresAt.Time
+			if expiresAt.After(now) && (next.IsZero() || expiresAt.Before(next)) {
+				next = expiresAt
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// scheduleTemporaryExpiry arms the timer that applies the policy again when its next temporary executable expires,
+// so that the expired executable is removed from BPF. now must be the time the policy has been applied at.
+// Expirations are evaluated again whenever the policy is applied, e.g. when the agent restarts.
+// This must be called with the resolver lock held.
+func (r *Resolver) scheduleTemporaryExpiry(wp *v1alpha1.WorkloadPolicy, info *wpInfo, now time.Time) {
+	if info.expiryTimer != nil {
+		info.expiryTimer.Stop()
+		info.expiryTimer = nil
+	}
+	next, ok := nextTemporaryExpiry(wp, now)
+	if !ok {
+		return
+	}
+	wp = wp.DeepCopy()
+	var timer *time.Timer
+	timer = time.AfterFunc(next.Sub(now), func() { r.expireTemporaryExecutables(wp, timer, now) })
+	info.expiryTimer = timer
+}
+
+// expireTemporaryExecutables applies the policy again without the temporary executables expired since appliedAt.
+func (r *Resolver) expireTemporaryExecutables(wp *v1alpha1.WorkloadPolicy, timer *time.Timer, appliedAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wpKey := wp.NamespacedName()
+	info := r.wpState[wpKey]
+	// the policy has been deleted or applied again in the meantime.
+	if info == nil || info.expiryTimer != timer {
+		return
+	}
+	info.expiryTimer = nil
+	r.logger.Info("temporary executables expired",
+		"wp", wpKey,
+		"executables", expiredTemporaryExecutables(wp, appliedAt, time.Now()))
+	if err := r.reconcileWP(wp); err != nil {
+		r.logger.Error("failed to remove the expired temporary executables", "wp", wpKey, "error", err)
+	}
+}
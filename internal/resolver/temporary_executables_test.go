@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTemporaryExecutablesPolicy returns the test policy also allowing the temporary executables in c1.
+func newTemporaryExecutablesPolicy(temporary ...v1alpha1.TemporaryExecutable) *v1alpha1.WorkloadPolicy {
+	wp := newTestPolicy("test-ns", "example", c1)
+	wp.Spec.RulesByContainer[c1].Executables.Temporary = temporary
+	return wp
+}
+
+func allowedExecutables(r *Resolver, wpKey NamespacedPolicyName, containerName ContainerName) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.wpState[wpKey].allowedByContainer[containerName]
+}
+
+func TestTemporaryExecutableExpires(t *testing.T) {
+	r := NewTestResolver(t)
+
+	wp := newTemporaryExecutablesPolicy(
+		v1alpha1.TemporaryExecutable{Path: "/usr/bin/migrate", ExpiresAt: metav1.NewTime(time.Now().Add(time.Hour))},
+		v1alpha1.TemporaryExecutable{
+			Path:      "/usr/bin/seed",
+			ExpiresAt: metav1.NewTime(time.Now().Add(500 * time.Millisecond)),
+		},
+	)
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t,
+		[]string{"/bin/sleep", "/usr/bin/migrate", "/usr/bin/seed"},
+		allowedExecutables(r, wp.NamespacedName(), c1),
+	)
+
+	// the first expiration removes only the expired executable and the timer is armed for the next one.
+	require.Eventually(t, func() bool {
+		return !slices.Contains(allowedExecutables(r, wp.NamespacedName(), c1), "/usr/bin/seed")
+	}, 5*time.Second, 10*time.Millisecond, "the temporary executable should expire")
+	require.Equal(t, []string{"/bin/sleep", "/usr/bin/migrate"}, allowedExecutables(r, wp.NamespacedName(), c1))
+	r.mu.Lock()
+	require.NotNil(t, r.wpState[wp.NamespacedName()].expiryTimer)
+	r.mu.Unlock()
+
+	// deleting the policy stops the timer.
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.NotContains(t, r.GetPolicyStatuses(), wp.NamespacedName())
+}
+
+func TestTemporaryExecutableExpiredOnLoad(t *testing.T) {
+	r := NewTestResolver(t)
+
+	// e.g. the agent restarted after the expiration: the executable is never written into BPF.
+	wp := newTemporaryExecutablesPolicy(
+		v1alpha1.TemporaryExecutable{Path: "/usr/bin/migrate", ExpiresAt: metav1.NewTime(time.Now().Add(-time.Minute))},
+	)
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/bin/sleep"}, allowedExecutables(r, wp.NamespacedName(), c1))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	require.Nil(t, r.wpState[wp.NamespacedName()].expiryTimer)
+}
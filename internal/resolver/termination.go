@@ -0,0 +1,87 @@
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+// TerminationRelaxation selects the policies enforced in monitor mode on the terminating pods, so that their preStop
+// hooks and cleanup scripts are not blocked during the graceful shutdown.
+type TerminationRelaxation string
+
+const (
+	// TerminationRelaxationNone enforces the declared mode of every policy until the pods are deleted.
+	TerminationRelaxationNone TerminationRelaxation = "none"
+	// TerminationRelaxationAll enforces every policy in monitor mode on the terminating pods.
+	TerminationRelaxationAll TerminationRelaxation = "all"
+	// TerminationRelaxationAnnotated enforces in monitor mode on the terminating pods only the policies
+	// annotated with v1alpha1.RelaxOnTerminationAnnotationKey.
+	TerminationRelaxationAnnotated TerminationRelaxation = "annotated"
+)
+
+// ParseTerminationRelaxation parses the name of a TerminationRelaxation.
+func ParseTerminationRelaxation(s string) (TerminationRelaxation, error) {
+	switch relaxation := TerminationRelaxation(s); relaxation {
+	case TerminationRelaxationNone, TerminationRelaxationAll, TerminationRelaxationAnnotated:
+		return relaxation, nil
+	default:
+		return "", fmt.Errorf("invalid termination relaxation %q, must be one of: none|all|annotated", s)
+	}
+}
+
+// SetTerminationRelaxation sets the policies enforced in monitor mode on the pods reported as terminating
+// by MarkPodTerminating. It must be called before the policies are reconciled.
+func (r *Resolver) SetTerminationRelaxation(relaxation TerminationRelaxation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.terminationRelaxation = relaxation
+}
+
+// relaxesOnTermination returns true when the terminating pods of the policy must run in monitor mode.
+// This must be called with the resolver lock held.
+func (r *Resolver) relaxesOnTermination(wp *v1alpha1.WorkloadPolicy) bool {
+	switch r.terminationRelaxation {
+	case TerminationRelaxationAll:
+		return true
+	case TerminationRelaxationAnnotated:
+		return wp.Annotations[v1alpha1.RelaxOnTerminationAnnotationKey] == "true"
+	default:
+		return false
+	}
+}
+
+// MarkPodTerminating records that a pod is terminating, i.e. its deletion has been requested, and switches
+// its containers to monitor mode when its policy is relaxed on termination. A terminating pod never goes back
+// to running, so the pod stays relaxed until ForgetPodReadiness reports its deletion.
+func (r *Resolver) MarkPodTerminating(podID PodID) error {
+	defer r.lockTimed(lockOpMarkTerminating)()
+
+	if r.terminationRelaxation == TerminationRelaxationNone {
+		return nil
+	}
+	if _, ok := r.terminatingPods[podID]; ok {
+		return nil
+	}
+	r.terminatingPods[podID] = struct{}{}
+
+	state, ok := r.podCache[podID]
+	if !ok {
+		return nil
+	}
+	info := r.wpState[state.podNamespace()+"/"+state.policyName()]
+	if info == nil || !info.relaxOnTermination {
+		return nil
+	}
+	r.logger.Info("pod is terminating, enforcing its policy in monitor mode",
+		"pod", state.podName(),
+		"namespace", state.podNamespace())
+	return r.applyPolicyToPodIfPresent(state)
+}
+
+// isPodTerminating reports whether the pod has been reported as terminating by MarkPodTerminating.
+// This must be called with the resolver lock held.
+func (r *Resolver) isPodTerminating(podID PodID) bool {
+	_, ok := r.terminatingPods[podID]
+	return ok
+}
@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+func TestParseTerminationRelaxation(t *testing.T) {
+	relaxation, err := ParseTerminationRelaxation("annotated")
+	require.NoError(t, err)
+	require.Equal(t, TerminationRelaxationAnnotated, relaxation)
+	_, err = ParseTerminationRelaxation("always")
+	require.ErrorContains(t, err, "must be one of: none|all|annotated")
+}
+
+func TestTerminationRelaxation(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	r.SetTerminationRelaxation(TerminationRelaxationAnnotated)
+
+	relaxed := newTestPolicy("test-ns", "relaxed", c1)
+	relaxed.Annotations = map[string]string{v1alpha1.RelaxOnTerminationAnnotationKey: "true"}
+	require.NoError(t, r.ReconcileWP(relaxed))
+	require.NoError(t, r.ReconcileWP(newTestPolicy("test-ns", "strict", c1)))
+
+	addPod := func(podID PodID, policyName string, containerID ContainerID, cgroupID CgroupID) {
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{
+				ID:        podID,
+				Namespace: "test-ns",
+				Name:      podID,
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: policyName},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				containerID: {ContainerMeta: ContainerMeta{ID: containerID, Name: c1, CgroupID: cgroupID}},
+			},
+		}))
+	}
+	modeOf := func(cgroupID CgroupID) policymode.Mode {
+		polID, ok := f.cgroups[cgroupID]
+		require.True(t, ok, "the container must be attached to a policy")
+		return f.modes[polID]
+	}
+	const terminatingCg, runningCg, strictCg = CgroupID(100), CgroupID(101), CgroupID(102)
+	addPod("terminating-pod", "relaxed", cid1, terminatingCg)
+	addPod("running-pod", "relaxed", cid2, runningCg)
+	addPod("strict-pod", "strict", "container-3", strictCg)
+	require.Equal(t, policymode.Protect, modeOf(terminatingCg))
+
+	// the preStop binary of the terminating pod is only monitored, the running pod is still enforced.
+	require.NoError(t, r.MarkPodTerminating("terminating-pod"))
+	require.Equal(t, policymode.Monitor, modeOf(terminatingCg))
+	require.Equal(t, policymode.Protect, modeOf(runningCg))
+
+	// a policy without the annotation is enforced until the pod is deleted.
+	require.NoError(t, r.MarkPodTerminating("strict-pod"))
+	require.Equal(t, policymode.Protect, modeOf(strictCg))
+
+	// a policy update keeps the terminating pod relaxed.
+	relaxed.Spec.RulesByContainer[c1].Executables.Allowed = []string{"/bin/sleep", "/bin/cat"}
+	require.NoError(t, r.ReconcileWP(relaxed))
+	require.Equal(t, policymode.Monitor, modeOf(terminatingCg))
+	require.Equal(t, policymode.Protect, modeOf(runningCg))
+
+	// the termination is forgotten with the pod.
+	r.ForgetPodReadiness("terminating-pod")
+	require.NotContains(t, r.terminatingPods, "terminating-pod")
+}
+
+func TestTerminationRelaxationDisabled(t *testing.T) {
+	r := NewTestResolver(t)
+	f := newFakeBPFMaps(r)
+	relaxed := newTestPolicy("test-ns", "relaxed", c1)
+	relaxed.Annotations = map[string]string{v1alpha1.RelaxOnTerminationAnnotationKey: "true"}
+	require.NoError(t, r.ReconcileWP(relaxed))
+
+	// without relaxation the policies have no grace policy and the terminations are ignored.
+	require.Len(t, f.values, 1)
+	require.NoError(t, r.MarkPodTerminating("test-pod-uid"))
+	require.Empty(t, r.terminatingPods)
+}
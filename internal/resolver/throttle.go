@@ -0,0 +1,115 @@
+package resolver
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"golang.org/x/time/rate"
+)
+
+// mapUpdateThrottle spreads the writes into the BPF maps over time. The writes never wait while the resolver lock
+// is held: each of them takes its token right away, and the next operation writing into the maps waits, before
+// taking the lock, until the writes done so far fit in the limit.
+type mapUpdateThrottle struct {
+	limiter *rate.Limiter
+
+	mu sync.Mutex
+	// readyAt is the time the writes done so far fit in the limit.
+	readyAt time.Time
+}
+
+// reserve takes the token of a write without waiting for it.
+func (t *mapUpdateThrottle) reserve() {
+	// A single token never exceeds the burst, so the reservation is always ok.
+	delay := t.limiter.Reserve().Delay()
+	if delay <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if readyAt := time.Now().Add(delay); readyAt.After(t.readyAt) {
+		t.readyAt = readyAt
+	}
+}
+
+// wait blocks until the writes done so far fit in the limit, it must be called without the resolver lock held.
+func (t *mapUpdateThrottle) wait() {
+	t.mu.Lock()
+	delay := time.Until(t.readyAt)
+	t.mu.Unlock()
+	if delay > 0 {
+		mapUpdateThrottleSeconds.Add(delay.Seconds())
+		time.Sleep(delay)
+	}
+}
+
+// SetMapUpdateRateLimit throttles the writes into the BPF maps to limit writes per second, with bursts of up to
+// burst writes. No write is dropped, so every update is still applied, in order, only delayed: the operations
+// writing into the maps wait for their turn before taking the resolver lock, so a burst of pod or policy changes
+// is spread over time without delaying the resolution of the events. An operation is never interrupted, the burst
+// should be large enough to apply a whole policy at once.
+// It must be called before the resolver is used, burst must be at least 1.
+func (r *Resolver) SetMapUpdateRateLimit(limit rate.Limit, burst int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	throttle := &mapUpdateThrottle{limiter: rate.NewLimiter(limit, burst)}
+	r.mapUpdateThrottle = throttle
+	cgTrackerUpdate := r.cgTrackerUpdateFunc
+	r.cgTrackerUpdateFunc = func(cgID uint64, cgroupPath string) error {
+		throttle.reserve()
+		return cgTrackerUpdate(cgID, cgroupPath)
+	}
+	cgroupToPolicyMapUpdate := r.cgroupToPolicyMapUpdateFunc
+	r.cgroupToPolicyMapUpdateFunc = func(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error {
+		throttle.reserve()
+		return cgroupToPolicyMapUpdate(polID, cgroupIDs, op)
+	}
+	policyUpdateBinaries := r.policyUpdateBinariesFunc
+	r.policyUpdateBinariesFunc = func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		throttle.reserve()
+		return policyUpdateBinaries(policyID, values, op)
+	}
+	policyModeUpdate := r.policyModeUpdateFunc
+	r.policyModeUpdateFunc = func(policyID PolicyID, mode policymode.Mode, op bpf.PolicyModeOperation) error {
+		throttle.reserve()
+		return policyModeUpdate(policyID, mode, op)
+	}
+	policyFlagsUpdate := r.policyFlagsUpdateFunc
+	r.policyFlagsUpdateFunc = func(policyID PolicyID, flags bpf.PolicyFlags, op bpf.PolicyFlagsOperation) error {
+		throttle.reserve()
+		return policyFlagsUpdate(policyID, flags, op)
+	}
+	policyExecLimitUpdate := r.policyExecLimitUpdateFunc
+	r.policyExecLimitUpdateFunc = func(policyID PolicyID, limit uint32, op bpf.PolicyExecLimitOperation) error {
+		throttle.reserve()
+		return policyExecLimitUpdate(policyID, limit, op)
+	}
+	policyFsTypesUpdate := r.policyFsTypesUpdateFunc
+	r.policyFsTypesUpdateFunc = func(policyID PolicyID, magics []uint32, op bpf.PolicyFsTypesOperation) error {
+		throttle.reserve()
+		return policyFsTypesUpdate(policyID, magics, op)
+	}
+	policyCommandsUpdate := r.policyCommandsUpdateFunc
+	r.policyCommandsUpdateFunc = func(policyID PolicyID, commands []bpf.Command, op bpf.PolicyCommandsOperation) error {
+		throttle.reserve()
+		return policyCommandsUpdate(policyID, commands, op)
+	}
+	policyFilesUpdate := r.policyFilesUpdateFunc
+	r.policyFilesUpdateFunc = func(policyID PolicyID, files bpf.Files, op bpf.PolicyFilesOperation) error {
+		throttle.reserve()
+		return policyFilesUpdate(policyID, files, op)
+	}
+	policyEgressUpdate := r.policyEgressUpdateFunc
+	r.policyEgressUpdateFunc = func(policyID PolicyID, egress bpf.Egress, op bpf.PolicyEgressOperation) error {
+		throttle.reserve()
+		return policyEgressUpdate(policyID, egress, op)
+	}
+	traceUpdate := r.traceUpdateFunc
+	r.traceUpdateFunc = func(cgID CgroupID, op bpf.TraceOperation) error {
+		throttle.reserve()
+		return traceUpdate(cgID, op)
+	}
+}
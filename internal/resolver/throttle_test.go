@@ -0,0 +1,58 @@
+package resolver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetMapUpdateRateLimit(t *testing.T) {
+	r := NewTestResolver(t)
+	fake := newFakeBPFMaps(r)
+	var binariesWrites int
+	updateBinaries := r.policyUpdateBinariesFunc
+	r.policyUpdateBinariesFunc = func(id PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		binariesWrites++
+		return updateBinaries(id, values, op)
+	}
+	const (
+		limit = 200
+		burst = 10
+		edits = 30
+	)
+	r.SetMapUpdateRateLimit(limit, burst)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: {}},
+		},
+	}
+
+	// Every edit of the allow list writes into the maps, the edits beyond the burst wait for their turn.
+	_, heldBefore := lockHoldSample(t, lockOpReconcilePolicy)
+	start := time.Now()
+	for i := range edits {
+		wp.Spec.RulesByContainer[c1].Executables.Allowed = []string{fmt.Sprintf("/bin/app-%d", i)}
+		require.NoError(t, r.ReconcileWP(wp))
+	}
+	elapsed := time.Since(start)
+	_, held := lockHoldSample(t, lockOpReconcilePolicy)
+
+	require.Equal(t, edits, binariesWrites, "no update is lost")
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+	require.Equal(t, []string{fmt.Sprintf("/bin/app-%d", edits-1)}, fake.values[polID])
+	// At least edits-burst writes are delayed by 1/limit each.
+	require.GreaterOrEqual(t, elapsed, time.Duration(edits-burst)*time.Second/limit-20*time.Millisecond)
+	require.Less(t, elapsed, 5*time.Second)
+	require.Positive(t, promtestutil.ToFloat64(mapUpdateThrottleSeconds))
+	// The writes wait before the lock is taken, not while it is held.
+	require.Less(t, held-heldBefore, elapsed.Seconds()/2)
+}
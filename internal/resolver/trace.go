@@ -0,0 +1,157 @@
+package resolver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+const (
+	// DefaultTraceDuration is the duration of a trace when none is requested.
+	DefaultTraceDuration = 5 * time.Minute
+	// MaxTraceDuration bounds the duration of a trace, so that a forgotten trace cannot flood the logs.
+	MaxTraceDuration = 30 * time.Minute
+)
+
+// traceEntry is a container whose exec decisions are traced until expiresAt.
+type traceEntry struct {
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// StartTrace reports every exec decision of a container, allowed ones included, for the given duration.
+// Starting the trace of an already traced container replaces its expiration.
+// It returns the time at which the trace expires.
+func (r *Resolver) StartTrace(
+	namespace, podName string,
+	containerName ContainerName,
+	duration time.Duration,
+) (time.Time, error) {
+	if duration <= 0 || duration > MaxTraceDuration {
+		return time.Time{}, fmt.Errorf("trace duration must be between 0 and %s, got %s", MaxTraceDuration, duration)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cgID, err := r.findContainerCgroupID(namespace, podName, containerName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err = r.traceUpdateFunc(cgID, bpf.StartTrace); err != nil {
+		return time.Time{}, fmt.Errorf("failed to start trace of cgroup %d: %w", cgID, err)
+	}
+	if prev, ok := r.traces[cgID]; ok {
+		prev.timer.Stop()
+	}
+
+	entry := &traceEntry{expiresAt: time.Now().Add(duration)}
+	entry.timer = time.AfterFunc(duration, func() { r.expireTrace(cgID, entry) })
+	r.traces[cgID] = entry
+	r.logger.Info("exec trace started",
+		"namespace", namespace,
+		"pod", podName,
+		"container", containerName,
+		"cgroupID", cgID,
+		"expiresAt", entry.expiresAt)
+	return entry.expiresAt, nil
+}
+
+// StopTrace stops the trace of a container before its expiration.
+func (r *Resolver) StopTrace(namespace, podName string, containerName ContainerName) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cgID, err := r.findContainerCgroupID(namespace, podName, containerName)
+	if err != nil {
+		return err
+	}
+	entry, ok := r.traces[cgID]
+	if !ok {
+		return fmt.Errorf("container %s of pod %s/%s is not traced", containerName, namespace, podName)
+	}
+	entry.timer.Stop()
+	return r.stopTrace(cgID)
+}
+
+func (r *Resolver) expireTrace(cgID CgroupID, entry *traceEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the trace has been stopped or restarted in the meantime.
+	if r.traces[cgID] != entry {
+		return
+	}
+	if err := r.stopTrace(cgID); err != nil {
+		r.logger.Error("failed to stop expired exec trace", "cgroupID", cgID, "error", err)
+		return
+	}
+	r.logger.Info("exec trace expired", "cgroupID", cgID)
+}
+
+// stopTrace must be called with the resolver lock held.
+func (r *Resolver) stopTrace(cgID CgroupID) error {
+	delete(r.traces, cgID)
+	if err := r.traceUpdateFunc(cgID, bpf.StopTrace); err != nil {
+		return fmt.Errorf("failed to stop trace of cgroup %d: %w", cgID, err)
+	}
+	return nil
+}
+
+// findContainerCgroupID must be called with the resolver lock held.
+// A pod recreated with the same name has a new UID, so until the containers of the old pod are removed
+// the cache holds two pods with the name: the most recently started container is the one being looked up.
+func (r *Resolver) findContainerCgroupID(namespace, podName string, containerName ContainerName) (CgroupID, error) {
+	var found *ContainerMeta
+	podFound := false
+	for _, pod := range r.podCache {
+		if pod.podNamespace() != namespace || pod.podName() != podName {
+			continue
+		}
+		podFound = true
+		for _, container := range pod.containers {
+			if container.Name == containerName && (found == nil || container.StartTime.After(found.StartTime)) {
+				found = container
+			}
+		}
+	}
+	switch {
+	case found != nil:
+		return found.CgroupID, nil
+	case podFound:
+		return 0, fmt.Errorf("container %s not found in pod %s/%s", containerName, namespace, podName)
+	default:
+		return 0, fmt.Errorf("pod %s/%s not found", namespace, podName)
+	}
+}
+
+// GetTracedPolicyID returns the ID of the policy applied to the container of the cgroup
+// and whether the container is traced. The ID is PolicyIDNone if no policy is applied.
+func (r *Resolver) GetTracedPolicyID(cgID CgroupID) (PolicyID, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.traces[cgID]; !ok {
+		return PolicyIDNone, false
+	}
+	pod, ok := r.podCache[r.cgroupIDToPodID[cgID]]
+	if !ok {
+		return PolicyIDNone, true
+	}
+	info, ok := r.wpState[pod.podNamespace()+"/"+pod.policyName()]
+	if !ok {
+		return PolicyIDNone, true
+	}
+	for _, container := range pod.containers {
+		if container.CgroupID != cgID {
+			continue
+		}
+		key := r.enforcedPolicyKey(info, container)
+		if gracePolID, hasGrace := info.gracePolByContainer[key]; hasGrace && r.enforcementDeferred(pod) {
+			return gracePolID, true
+		}
+		return info.polByContainer[key], true
+	}
+	return PolicyIDNone, true
+}
@@ -0,0 +1,133 @@
+package resolver
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type traceRecorder struct {
+	mu     sync.Mutex
+	traced map[CgroupID]struct{}
+}
+
+func (tr *traceRecorder) update(cgID CgroupID, op bpf.TraceOperation) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	switch op {
+	case bpf.StartTrace:
+		tr.traced[cgID] = struct{}{}
+	case bpf.StopTrace:
+		delete(tr.traced, cgID)
+	}
+	return nil
+}
+
+func (tr *traceRecorder) isTraced(cgID CgroupID) bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	_, ok := tr.traced[cgID]
+	return ok
+}
+
+func TestTrace(t *testing.T) {
+	r := NewTestResolver(t)
+	recorder := &traceRecorder{traced: make(map[CgroupID]struct{})}
+	r.traceUpdateFunc = recorder.update
+
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}))
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{ID: cid1, Name: c1, CgroupID: 100}},
+			cid2: {ContainerMeta: ContainerMeta{ID: cid2, Name: c2, CgroupID: 101}},
+		},
+	}))
+
+	_, err := r.StartTrace("test-ns", "test-pod", c1, MaxTraceDuration+time.Second)
+	require.ErrorContains(t, err, "trace duration must be between")
+	_, err = r.StartTrace("test-ns", "missing-pod", c1, time.Minute)
+	require.ErrorContains(t, err, "pod test-ns/missing-pod not found")
+	_, err = r.StartTrace("test-ns", "test-pod", c3, time.Minute)
+	require.ErrorContains(t, err, "container c3 not found in pod test-ns/test-pod")
+
+	polID, traced := r.GetTracedPolicyID(100)
+	require.False(t, traced)
+	require.Equal(t, PolicyIDNone, polID)
+
+	// the container with a policy.
+	_, err = r.StartTrace("test-ns", "test-pod", c1, time.Minute)
+	require.NoError(t, err)
+	require.True(t, recorder.isTraced(100))
+	polID, traced = r.GetTracedPolicyID(100)
+	require.True(t, traced)
+	require.Equal(t, r.wpState["test-ns/example"].polByContainer[c1], polID)
+
+	require.NoError(t, r.StopTrace("test-ns", "test-pod", c1))
+	require.False(t, recorder.isTraced(100))
+	_, traced = r.GetTracedPolicyID(100)
+	require.False(t, traced)
+	require.ErrorContains(t, r.StopTrace("test-ns", "test-pod", c1), "is not traced")
+
+	// the container without a policy is traced too, with no policy ID.
+	_, err = r.StartTrace("test-ns", "test-pod", c2, 50*time.Millisecond)
+	require.NoError(t, err)
+	polID, traced = r.GetTracedPolicyID(101)
+	require.True(t, traced)
+	require.Equal(t, PolicyIDNone, polID)
+
+	require.Eventually(t, func() bool {
+		_, traced = r.GetTracedPolicyID(101)
+		return !traced && !recorder.isTraced(101)
+	}, 5*time.Second, 10*time.Millisecond, "the trace should expire")
+
+	// restarting a trace extends it.
+	_, err = r.StartTrace("test-ns", "test-pod", c1, 50*time.Millisecond)
+	require.NoError(t, err)
+	_, err = r.StartTrace("test-ns", "test-pod", c1, time.Minute)
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	_, traced = r.GetTracedPolicyID(100)
+	require.True(t, traced, "the first expiration should have been replaced")
+
+	// removing the container stops its trace.
+	require.NoError(t, r.RemovePodContainerFromNri("test-pod-uid", cid1))
+	require.False(t, recorder.isTraced(100))
+	_, traced = r.GetTracedPolicyID(100)
+	require.False(t, traced)
+
+	// the container is removed from the BPF maps even if its trace can't be stopped.
+	_, err = r.StartTrace("test-ns", "test-pod", c2, time.Minute)
+	require.NoError(t, err)
+	r.traceUpdateFunc = func(CgroupID, bpf.TraceOperation) error { return errors.New("trace map error") }
+	var removed []CgroupID
+	r.cgroupToPolicyMapUpdateFunc = func(_ PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error {
+		if op == bpf.RemoveCgroups {
+			removed = append(removed, cgroupIDs...)
+		}
+		return nil
+	}
+	require.NoError(t, r.RemovePodContainerFromNri("test-pod-uid", cid2))
+	require.Equal(t, []CgroupID{101}, removed)
+	_, traced = r.GetTracedPolicyID(101)
+	require.False(t, traced)
+}
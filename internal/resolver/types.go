@@ -1,5 +1,7 @@
 package resolver
 
+import "time"
+
 type CgroupID = uint64
 type ContainerID = string
 type PodID = string
@@ -13,12 +15,24 @@ type PodMeta struct {
 	WorkloadName string
 	WorkloadType string
 	Labels       Labels
+	// HostPID is true when the pod shares the host PID namespace.
+	// Processes are attributed to the pod only through the cgroup of its containers,
+	// so host processes visible from the pod are never resolved to it.
+	HostPID bool
 }
 
 type ContainerMeta struct {
 	ID       ContainerID
 	Name     ContainerName
 	CgroupID CgroupID
+	// Image is the image reference requested for the container.
+	Image string
+	// ImageDigest is empty when the image is referenced only by tag.
+	ImageDigest string
+	// StartTime tells apart the instances of a container restarted with the same name.
+	// It is the time the runtime started the container, or created it when it is not started yet,
+	// and it is zero when the runtime doesn't report it.
+	StartTime time.Time
 }
 
 type ContainerInput struct {
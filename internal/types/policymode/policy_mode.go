@@ -5,6 +5,7 @@ import agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
 const (
 	MonitorString = "monitor"
 	ProtectString = "protect"
+	AuditString   = "audit"
 )
 
 type Mode uint8
@@ -13,6 +14,8 @@ const (
 	_ Mode = iota
 	Monitor
 	Protect
+	// Audit never blocks, like Monitor, but reports every exec, allowed ones included.
+	Audit
 )
 
 func (pm Mode) String() string {
@@ -21,6 +24,8 @@ func (pm Mode) String() string {
 		return MonitorString
 	case Protect:
 		return ProtectString
+	case Audit:
+		return AuditString
 	default:
 		panic("unknown policy mode")
 	}
@@ -28,7 +33,7 @@ func (pm Mode) String() string {
 
 func FromUint8(v uint8) Mode {
 	switch Mode(v) {
-	case Monitor, Protect:
+	case Monitor, Protect, Audit:
 		return Mode(v)
 	default:
 		panic("unknown uint8 value for policy mode")
@@ -41,6 +46,8 @@ func ParseMode(s string) Mode {
 		return Monitor
 	case ProtectString:
 		return Protect
+	case AuditString:
+		return Audit
 	default:
 		panic("unknown string value for policy mode")
 	}
@@ -52,6 +59,8 @@ func ParsePolicyModeToProto(s string) agentv1.PolicyMode {
 		return agentv1.PolicyMode_POLICY_MODE_MONITOR
 	case ProtectString:
 		return agentv1.PolicyMode_POLICY_MODE_PROTECT
+	case AuditString:
+		return agentv1.PolicyMode_POLICY_MODE_AUDIT
 	default:
 		return agentv1.PolicyMode_POLICY_MODE_UNSPECIFIED
 	}
@@ -7,14 +7,16 @@ import (
 
 // ViolationRecord is a violation record ready for scraping.
 type ViolationRecord struct {
-	Timestamp     time.Time
-	PolicyName    string
-	Namespace     string
-	PodName       string
-	ContainerName string
-	ExePath       string
-	NodeName      string
-	Action        string
+	Timestamp     time.Time `json:"timestamp"`
+	PolicyName    string    `json:"policyName"`
+	Namespace     string    `json:"namespace"`
+	PodName       string    `json:"podName"`
+	ContainerName string    `json:"containerName"`
+	ExePath       string    `json:"exePath"`
+	NodeName      string    `json:"nodeName"`
+	Action        string    `json:"action"`
+	// EventID identifies the violation in the exported events and in the exemplars of the violation counter.
+	EventID string `json:"eventId,omitempty"`
 }
 
 // MaxBufferEntries is the capacity of the ring buffer. When full, the oldest
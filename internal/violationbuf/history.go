@@ -0,0 +1,98 @@
+package violationbuf
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// DefaultHistorySize is the default number of violations retained by the History.
+const DefaultHistorySize = 1000
+
+// HistoryFilter selects the violations returned by History.Query.
+// Empty fields match every record, a zero Limit returns all the matching records.
+type HistoryFilter struct {
+	Namespace  string
+	PodName    string
+	PolicyName string
+	Limit      int
+}
+
+func (f HistoryFilter) match(rec *ViolationRecord) bool {
+	return (f.Namespace == "" || f.Namespace == rec.Namespace) &&
+		(f.PodName == "" || f.PodName == rec.PodName) &&
+		(f.PolicyName == "" || f.PolicyName == rec.PolicyName)
+}
+
+// History retains the last N violations for debugging purposes.
+// Unlike Buffer, reading from it doesn't consume the records.
+type History struct {
+	mtx sync.Mutex
+	buf []ViolationRecord
+	pos int64
+}
+
+// NewHistory creates a History retaining the last size violations.
+func NewHistory(size int) *History {
+	return &History{
+		buf: make([]ViolationRecord, size),
+	}
+}
+
+// Add stores a violation in the history, overwriting the oldest one when full.
+func (h *History) Add(rec ViolationRecord) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	size := int64(len(h.buf))
+	if size == 0 {
+		return
+	}
+	h.buf[h.pos%size] = rec
+	h.pos++
+}
+
+// Query returns the violations matching the filter in reverse chronological order (newest first).
+func (h *History) Query(filter HistoryFilter) []ViolationRecord {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	size := int64(len(h.buf))
+	n := min(h.pos, size)
+	records := []ViolationRecord{}
+	for i := range n {
+		rec := &h.buf[(h.pos-1-i)%size]
+		if !filter.match(rec) {
+			continue
+		}
+		records = append(records, *rec)
+		if filter.Limit > 0 && len(records) == filter.Limit {
+			break
+		}
+	}
+	return records
+}
+
+// ServeHTTP returns the violations as JSON.
+// The `namespace`, `pod`, `policy` and `limit` query parameters map to the HistoryFilter fields.
+func (h *History) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	filter := HistoryFilter{
+		Namespace:  query.Get("namespace"),
+		PodName:    query.Get("pod"),
+		PolicyName: query.Get("policy"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+			http.Error(w, "invalid limit: "+limit, http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.Query(filter)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,83 @@
+package violationbuf_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/violationbuf"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryWrapsAround(t *testing.T) {
+	history := violationbuf.NewHistory(3)
+
+	for i := range 5 {
+		history.Add(violationbuf.ViolationRecord{
+			PolicyName: fmt.Sprintf("pol-%d", i),
+			Namespace:  "ns1",
+		})
+	}
+
+	records := history.Query(violationbuf.HistoryFilter{})
+	require.Len(t, records, 3)
+	// newest first, the two oldest records have been overwritten.
+	require.Equal(t, "pol-4", records[0].PolicyName)
+	require.Equal(t, "pol-3", records[1].PolicyName)
+	require.Equal(t, "pol-2", records[2].PolicyName)
+
+	// Querying doesn't consume the records.
+	require.Len(t, history.Query(violationbuf.HistoryFilter{}), 3)
+}
+
+func TestHistoryFilters(t *testing.T) {
+	history := violationbuf.NewHistory(10)
+	history.Add(violationbuf.ViolationRecord{PolicyName: "pol1", Namespace: "ns1", PodName: "pod1", ExePath: "/bin/a"})
+	history.Add(violationbuf.ViolationRecord{PolicyName: "pol2", Namespace: "ns1", PodName: "pod2", ExePath: "/bin/b"})
+	history.Add(violationbuf.ViolationRecord{PolicyName: "pol1", Namespace: "ns2", PodName: "pod3", ExePath: "/bin/c"})
+	history.Add(violationbuf.ViolationRecord{PolicyName: "pol1", Namespace: "ns1", PodName: "pod1", ExePath: "/bin/d"})
+
+	tests := []struct {
+		name     string
+		filter   violationbuf.HistoryFilter
+		expected []string
+	}{
+		{"no filter", violationbuf.HistoryFilter{}, []string{"/bin/d", "/bin/c", "/bin/b", "/bin/a"}},
+		{"namespace", violationbuf.HistoryFilter{Namespace: "ns1"}, []string{"/bin/d", "/bin/b", "/bin/a"}},
+		{"pod", violationbuf.HistoryFilter{PodName: "pod1"}, []string{"/bin/d", "/bin/a"}},
+		{"policy", violationbuf.HistoryFilter{PolicyName: "pol1"}, []string{"/bin/d", "/bin/c", "/bin/a"}},
+		{"namespace and policy", violationbuf.HistoryFilter{Namespace: "ns1", PolicyName: "pol1"}, []string{"/bin/d", "/bin/a"}},
+		{"limit", violationbuf.HistoryFilter{Namespace: "ns1", Limit: 2}, []string{"/bin/d", "/bin/b"}},
+		{"no match", violationbuf.HistoryFilter{Namespace: "ns3"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exePaths := []string{}
+			for _, rec := range history.Query(tt.filter) {
+				exePaths = append(exePaths, rec.ExePath)
+			}
+			require.Equal(t, tt.expected, exePaths)
+		})
+	}
+}
+
+func TestHistoryServeHTTP(t *testing.T) {
+	history := violationbuf.NewHistory(10)
+	history.Add(violationbuf.ViolationRecord{PolicyName: "pol1", Namespace: "ns1", PodName: "pod1"})
+	history.Add(violationbuf.ViolationRecord{PolicyName: "pol2", Namespace: "ns2", PodName: "pod2"})
+
+	rec := httptest.NewRecorder()
+	history.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/violations?namespace=ns2&limit=50", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var records []violationbuf.ViolationRecord
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &records))
+	require.Len(t, records, 1)
+	require.Equal(t, "pol2", records[0].PolicyName)
+
+	rec = httptest.NewRecorder()
+	history.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/violations?limit=abc", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
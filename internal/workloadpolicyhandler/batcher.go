@@ -0,0 +1,81 @@
+package workloadpolicyhandler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+type pendingChange struct {
+	change resolver.WPChange
+	// waiters are notified with the result of the apply.
+	waiters []chan error
+}
+
+// wpBatcher debounces the workload policy events received within interval
+// and applies them together with a single call to applyFunc.
+// Events for the same policy are coalesced: only the latest one is applied,
+// every caller is notified with its result, so no update is lost.
+type wpBatcher struct {
+	interval  time.Duration
+	applyFunc func(changes []resolver.WPChange) map[resolver.NamespacedPolicyName]error
+
+	mu      sync.Mutex
+	pending map[resolver.NamespacedPolicyName]*pendingChange
+	// order keeps the arrival order of the policies in the current batch.
+	order []resolver.NamespacedPolicyName
+}
+
+func newWPBatcher(
+	interval time.Duration,
+	applyFunc func(changes []resolver.WPChange) map[resolver.NamespacedPolicyName]error,
+) *wpBatcher {
+	return &wpBatcher{
+		interval:  interval,
+		applyFunc: applyFunc,
+		pending:   make(map[resolver.NamespacedPolicyName]*pendingChange),
+	}
+}
+
+// submit adds the change to the current batch and waits until it is applied.
+func (b *wpBatcher) submit(change resolver.WPChange) error {
+	done := make(chan error, 1)
+	key := change.Policy.NamespacedName()
+
+	b.mu.Lock()
+	if len(b.pending) == 0 {
+		// first event of a new batch.
+		time.AfterFunc(b.interval, b.flush)
+	}
+	p, ok := b.pending[key]
+	if !ok {
+		p = &pendingChange{}
+		b.pending[key] = p
+		b.order = append(b.order, key)
+	}
+	p.change = change
+	p.waiters = append(p.waiters, done)
+	b.mu.Unlock()
+
+	return <-done
+}
+
+func (b *wpBatcher) flush() {
+	b.mu.Lock()
+	pending, order := b.pending, b.order
+	b.pending = make(map[resolver.NamespacedPolicyName]*pendingChange)
+	b.order = nil
+	b.mu.Unlock()
+
+	changes := make([]resolver.WPChange, 0, len(order))
+	for _, key := range order {
+		changes = append(changes, pending[key].change)
+	}
+	errs := b.applyFunc(changes)
+	for _, key := range order {
+		for _, waiter := range pending[key].waiters {
+			waiter <- errs[key]
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package workloadpolicyhandler
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestPolicy(name, mode string) *v1alpha1.WorkloadPolicy {
+	return &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+		Spec:       v1alpha1.WorkloadPolicySpec{Mode: mode},
+	}
+}
+
+func TestWPBatcherCoalescesEvents(t *testing.T) {
+	var mu sync.Mutex
+	var applies [][]resolver.WPChange
+	batcher := newWPBatcher(100*time.Millisecond,
+		func(changes []resolver.WPChange) map[resolver.NamespacedPolicyName]error {
+			mu.Lock()
+			defer mu.Unlock()
+			applies = append(applies, changes)
+			return map[resolver.NamespacedPolicyName]error{
+				"test-ns/failing": errors.New("apply failed"),
+			}
+		})
+
+	changes := []resolver.WPChange{
+		{Policy: newTestPolicy("p1", "monitor")},
+		{Policy: newTestPolicy("p2", "monitor")},
+		{Policy: newTestPolicy("failing", "monitor")},
+		{Policy: newTestPolicy("p3", "monitor"), Deleted: true},
+		// a later event for p1 within the window: only the latest spec is applied.
+		{Policy: newTestPolicy("p1", "protect")},
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(changes))
+	for i, change := range changes {
+		wg.Go(func() {
+			errs[i] = batcher.submit(change)
+		})
+		// keep the events ordered but well within the debounce window.
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	require.Len(t, applies, 1)
+	applied := make(map[string]resolver.WPChange)
+	for _, change := range applies[0] {
+		applied[change.Policy.NamespacedName()] = change
+	}
+	require.Len(t, applied, 4)
+	require.Equal(t, "protect", applied["test-ns/p1"].Policy.Spec.Mode)
+	require.True(t, applied["test-ns/p3"].Deleted)
+
+	// every caller receives the result of its policy.
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.ErrorContains(t, errs[2], "apply failed")
+	require.NoError(t, errs[3])
+	require.NoError(t, errs[4])
+}
@@ -0,0 +1,77 @@
+package workloadpolicyhandler
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/freezewindow"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestFreezeWindowDefersUpdates(t *testing.T) {
+	schedule, err := freezewindow.Parse("Sat 08:00-20:00")
+	require.NoError(t, err)
+
+	// Saturday 10:00, within the freeze.
+	now := time.Date(2026, time.October, 17, 10, 0, 0, 0, time.UTC)
+	policy := newTestPolicy("example", "monitor")
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "test-ns", Name: "example"}}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy).Build()
+	r := resolver.NewTestResolver(t)
+	h := NewWorkloadPolicyHandler(
+		fakeClient,
+		slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		r,
+		WithFreezeSchedule(schedule),
+	)
+	h.now = func() time.Time { return now }
+
+	requireMode := func(mode agentv1.PolicyMode) {
+		t.Helper()
+		status, ok := r.GetPolicyStatuses()[policy.NamespacedName()]
+		require.True(t, ok)
+		require.Equal(t, mode, status.Mode)
+	}
+
+	// a new policy is applied during the freeze.
+	res, err := h.Reconcile(t.Context(), req)
+	require.NoError(t, err)
+	require.Zero(t, res.RequeueAfter)
+	requireMode(agentv1.PolicyMode_POLICY_MODE_MONITOR)
+
+	// an update is deferred until the end of the freeze, at 20:00.
+	require.NoError(t, fakeClient.Get(t.Context(), req.NamespacedName, policy))
+	policy.Spec.Mode = "protect"
+	require.NoError(t, fakeClient.Update(t.Context(), policy))
+	res, err = h.Reconcile(t.Context(), req)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Hour, res.RequeueAfter)
+	requireMode(agentv1.PolicyMode_POLICY_MODE_MONITOR)
+
+	// the requeued update is applied once the window has ended.
+	now = now.Add(res.RequeueAfter)
+	res, err = h.Reconcile(t.Context(), req)
+	require.NoError(t, err)
+	require.Zero(t, res.RequeueAfter)
+	requireMode(agentv1.PolicyMode_POLICY_MODE_PROTECT)
+
+	// deletions are honored during the freeze.
+	now = time.Date(2026, time.October, 24, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, fakeClient.Delete(t.Context(), policy))
+	res, err = h.Reconcile(t.Context(), req)
+	require.NoError(t, err)
+	require.Zero(t, res.RequeueAfter)
+	require.NotContains(t, r.GetPolicyStatuses(), policy.NamespacedName())
+}
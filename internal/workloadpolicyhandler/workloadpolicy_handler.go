@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"sync/atomic"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/freezewindow"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
-	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
 	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -25,18 +28,67 @@ type WorkloadPolicyHandler struct {
 	logger    *slog.Logger
 	resolver  *resolver.Resolver
 	hasSynced atomic.Bool
+	// batcher is nil when batching is disabled.
+	batcher *wpBatcher
+	// freeze is nil when no freeze window is configured.
+	freeze *freezewindow.Schedule
+	now    func() time.Time
+}
+
+// batchMaxConcurrentReconciles is the number of policies that can join the same batch.
+// Without concurrent reconciles every event would wait alone for the debounce interval.
+const batchMaxConcurrentReconciles = 16
+
+type Option func(*WorkloadPolicyHandler)
+
+// WithBatchInterval enables the batched apply: the events received within interval
+// are applied together under a single resolver lock acquisition.
+func WithBatchInterval(interval time.Duration) Option {
+	return func(r *WorkloadPolicyHandler) {
+		if interval > 0 {
+			r.batcher = newWPBatcher(interval, r.resolver.ApplyWPChanges)
+		}
+	}
+}
+
+// WithFreezeSchedule defers the updates of the applied policies received during a freeze window
+// until the window ends. New policies and deletions are always applied immediately.
+func WithFreezeSchedule(schedule *freezewindow.Schedule) Option {
+	return func(r *WorkloadPolicyHandler) {
+		r.freeze = schedule
+	}
 }
 
 func NewWorkloadPolicyHandler(
 	client client.Client,
 	logger *slog.Logger,
 	resolver *resolver.Resolver,
+	opts ...Option,
 ) *WorkloadPolicyHandler {
-	return &WorkloadPolicyHandler{
+	r := &WorkloadPolicyHandler{
 		Client:   client,
 		logger:   logger,
 		resolver: resolver,
+		now:      time.Now,
 	}
+	for _, option := range opts {
+		option(r)
+	}
+	return r
+}
+
+func (r *WorkloadPolicyHandler) reconcileWP(wp *v1alpha1.WorkloadPolicy) error {
+	if r.batcher != nil {
+		return r.batcher.submit(resolver.WPChange{Policy: wp})
+	}
+	return r.resolver.ReconcileWP(wp)
+}
+
+func (r *WorkloadPolicyHandler) deleteWP(wp *v1alpha1.WorkloadPolicy) error {
+	if r.batcher != nil {
+		return r.batcher.submit(resolver.WPChange{Policy: wp, Deleted: true})
+	}
+	return r.resolver.HandleWPDelete(wp)
 }
 
 // +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicies,verbs=get;list;watch
@@ -53,7 +105,7 @@ func (r *WorkloadPolicyHandler) Reconcile(
 			return ctrl.Result{}, fmt.Errorf("failed to get WorkloadPolicy '%s': %w", req.NamespacedName, err)
 		}
 		// The item has been removed.
-		if err = r.resolver.HandleWPDelete(&v1alpha1.WorkloadPolicy{
+		if err = r.deleteWP(&v1alpha1.WorkloadPolicy{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      req.Name,
 				Namespace: req.Namespace,
@@ -69,13 +121,38 @@ func (r *WorkloadPolicyHandler) Reconcile(
 		return ctrl.Result{}, nil
 	}
 
-	if err = r.resolver.ReconcileWP(&wp); err != nil {
+	if end, frozen := r.deferredUntil(&wp); frozen {
+		r.logger.InfoContext(ctx, "policy update deferred until the end of the freeze window",
+			"policy", req.NamespacedName,
+			"until", end,
+		)
+		return ctrl.Result{RequeueAfter: end.Sub(r.now())}, nil
+	}
+
+	if err = r.reconcileWP(&wp); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to update WorkloadPolicy '%s': %w", req.NamespacedName, err)
 	}
 
+	if status, ok := r.resolver.PolicyStatus(wp.NamespacedName()); ok {
+		r.logger.DebugContext(ctx, "policy applied",
+			"policy", req.NamespacedName,
+			"state", status.State,
+			"enforcedCgroups", status.EnforcedCgroups,
+		)
+	}
 	return ctrl.Result{}, nil
 }
 
+// deferredUntil returns whether the update of the policy has to wait for the end of the current freeze window.
+// New policies are never deferred, otherwise the pods referencing them would be rejected,
+// and neither is pausing or a dry run, which only stop the blocking.
+func (r *WorkloadPolicyHandler) deferredUntil(wp *v1alpha1.WorkloadPolicy) (time.Time, bool) {
+	if r.freeze == nil || wp.IsPaused() || wp.IsDryRun() || !r.resolver.HasPolicy(wp.NamespacedName()) {
+		return time.Time{}, false
+	}
+	return r.freeze.ActiveUntil(r.now())
+}
+
 // HasSynced returns nil if the handler has reconciled with all existing WorkloadPolicies.
 // Otherwise, it returns the error during the validation.
 // This function is supposed to be used as part of the startup probe, so we know the enforcement is ready for the old pod to stop during the rolling update.
@@ -96,11 +173,14 @@ func (r *WorkloadPolicyHandler) HasSynced(ctx context.Context) error {
 		if !ok {
 			return fmt.Errorf("policy status not found for WorkloadPolicy '%s'", wp.NamespacedName())
 		}
+		if status.Rejected {
+			// the rejection is reported in the status of the policy, it must not block the other policies.
+			continue
+		}
 		if status.State != agentv1.PolicyState_POLICY_STATE_READY {
 			return fmt.Errorf("policy status is not ready for WorkloadPolicy '%s'", wp.NamespacedName())
 		}
-		mode := policymode.ParsePolicyModeToProto(wp.Spec.Mode)
-		if status.Mode != mode {
+		if status.Mode != r.resolver.ExpectedMode(&wp) {
 			return fmt.Errorf("policy status is not ready for WorkloadPolicy '%s'", wp.NamespacedName())
 		}
 	}
@@ -111,12 +191,35 @@ func (r *WorkloadPolicyHandler) HasSynced(ctx context.Context) error {
 	return nil
 }
 
+// pausedChangedPredicate triggers a reconcile when the policy is paused or resumed, or its dry run starts or ends:
+// annotation changes don't bump the generation of the policy.
+func pausedChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldWP, okOld := e.ObjectOld.(*v1alpha1.WorkloadPolicy)
+			newWP, okNew := e.ObjectNew.(*v1alpha1.WorkloadPolicy)
+			if !okOld || !okNew {
+				return false
+			}
+			return oldWP.IsPaused() != newWP.IsPaused() || oldWP.IsDryRun() != newWP.IsDryRun()
+		},
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *WorkloadPolicyHandler) SetupWithManager(mgr ctrl.Manager) error {
+	maxConcurrentReconciles := 1
+	if r.batcher != nil {
+		maxConcurrentReconciles = batchMaxConcurrentReconciles
+	}
 	err := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.WorkloadPolicy{}).
 		Named("workloadpolicy").
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		WithEventFilter(predicate.Or[client.Object](
+			predicate.GenerationChangedPredicate{},
+			pausedChangedPredicate(),
+		)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
 		Complete(r)
 	if err != nil {
 		return fmt.Errorf("unable to set up WorkloadPolicy handler: %w", err)
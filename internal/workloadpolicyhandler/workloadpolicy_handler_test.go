@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/workloadpolicyhandler"
 	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
@@ -94,3 +95,97 @@ func TestWorkloadPolicyHandler(t *testing.T) {
 	_, exists = policyStatus[policy.NamespacedName()]
 	require.False(t, exists)
 }
+
+func newProtectPolicy(name string) *v1alpha1.WorkloadPolicy {
+	return &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"main": {
+					Executables: v1alpha1.WorkloadPolicyExecutables{
+						Allowed: []string{"/usr/bin/sleep"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestHandler(
+	t *testing.T,
+	r *resolver.Resolver,
+	policies ...*v1alpha1.WorkloadPolicy,
+) *workloadpolicyhandler.WorkloadPolicyHandler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, policy := range policies {
+		builder = builder.WithObjects(policy)
+	}
+	return workloadpolicyhandler.NewWorkloadPolicyHandler(
+		builder.Build(),
+		slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		r,
+	)
+}
+
+func reconcilePolicy(
+	t *testing.T,
+	h *workloadpolicyhandler.WorkloadPolicyHandler,
+	policy *v1alpha1.WorkloadPolicy,
+) error {
+	_, err := h.Reconcile(t.Context(), reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace},
+	})
+	return err
+}
+
+func TestHasSyncedForceMonitorMode(t *testing.T) {
+	policy := newProtectPolicy("test-policy")
+	resolver := resolver.NewTestResolver(t)
+	resolver.SetForceMonitorMode(true)
+	wpHandler := newTestHandler(t, resolver, policy)
+
+	require.ErrorContains(t, wpHandler.HasSynced(t.Context()), "policy status not found")
+	require.NoError(t, reconcilePolicy(t, wpHandler, policy))
+
+	// the policy is enforced in monitor mode, as forced by the agent configuration.
+	status := resolver.GetPolicyStatuses()[policy.NamespacedName()]
+	require.Equal(t, agentv1.PolicyMode_POLICY_MODE_MONITOR, status.Mode)
+	require.NoError(t, wpHandler.HasSynced(t.Context()))
+}
+
+func TestHasSyncedNamespaceQuotaExceeded(t *testing.T) {
+	first := newProtectPolicy("first")
+	second := newProtectPolicy("second")
+	r := resolver.NewTestResolver(t)
+	r.SetNamespacePolicyIDQuota(1)
+	wpHandler := newTestHandler(t, r, first, second)
+
+	require.NoError(t, reconcilePolicy(t, wpHandler, first))
+	require.ErrorIs(t, reconcilePolicy(t, wpHandler, second), resolver.ErrNamespacePolicyIDQuotaExceeded)
+
+	// the policy over the quota is only reported in its status.
+	status := r.GetPolicyStatuses()[second.NamespacedName()]
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_ERROR, status.State)
+	require.NoError(t, wpHandler.HasSynced(t.Context()))
+}
+
+func TestHasSyncedPolicyMapFull(t *testing.T) {
+	first := newProtectPolicy("first")
+	second := newProtectPolicy("second")
+	r := resolver.NewTestResolverWithMapCapacity(t, 1)
+	wpHandler := newTestHandler(t, r, first, second)
+
+	require.NoError(t, reconcilePolicy(t, wpHandler, first))
+	require.ErrorIs(t, reconcilePolicy(t, wpHandler, second), bpf.ErrPolicyMapFull)
+
+	// the policy that doesn't fit in the BPF maps is only reported in its status.
+	status := r.GetPolicyStatuses()[second.NamespacedName()]
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_ERROR, status.State)
+	require.NoError(t, wpHandler.HasSynced(t.Context()))
+}
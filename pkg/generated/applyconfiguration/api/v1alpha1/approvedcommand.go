@@ -0,0 +1,39 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ApprovedCommandApplyConfiguration represents a declarative configuration of the ApprovedCommand type for use
+// with apply.
+//
+// ApprovedCommand is a complete command line allowed to run.
+type ApprovedCommandApplyConfiguration struct {
+	// path of the executable, an absolute path inside the container filesystem.
+	Path *string `json:"path,omitempty"`
+	// args are the arguments following the program name (argv[0]), which is not part of the command
+	// since the caller can set it freely. They are compared byte by byte, with no shell parsing.
+	Args []string `json:"args,omitempty"`
+}
+
+// ApprovedCommandApplyConfiguration constructs a declarative configuration of the ApprovedCommand type for use with
+// apply.
+func ApprovedCommand() *ApprovedCommandApplyConfiguration {
+	return &ApprovedCommandApplyConfiguration{}
+}
+
+// WithPath sets the Path field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Path field is set to the value of the last call.
+func (b *ApprovedCommandApplyConfiguration) WithPath(value string) *ApprovedCommandApplyConfiguration {
+	b.Path = &value
+	return b
+}
+
+// WithArgs adds the given value to the Args field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Args field.
+func (b *ApprovedCommandApplyConfiguration) WithArgs(values ...string) *ApprovedCommandApplyConfiguration {
+	for i := range values {
+		b.Args = append(b.Args, values[i])
+	}
+	return b
+}
@@ -0,0 +1,40 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ContainerRulesDiffApplyConfiguration represents a declarative configuration of the ContainerRulesDiff type for use
+// with apply.
+//
+// ContainerRulesDiff describes how the proposal changes the allowed executables of a container.
+type ContainerRulesDiffApplyConfiguration struct {
+	// added contains the executables allowed by the proposal but not by the enforced policy.
+	Added []string `json:"added,omitempty"`
+	// removed contains the executables allowed by the enforced policy but not by the proposal.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// ContainerRulesDiffApplyConfiguration constructs a declarative configuration of the ContainerRulesDiff type for use with
+// apply.
+func ContainerRulesDiff() *ContainerRulesDiffApplyConfiguration {
+	return &ContainerRulesDiffApplyConfiguration{}
+}
+
+// WithAdded adds the given value to the Added field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Added field.
+func (b *ContainerRulesDiffApplyConfiguration) WithAdded(values ...string) *ContainerRulesDiffApplyConfiguration {
+	for i := range values {
+		b.Added = append(b.Added, values[i])
+	}
+	return b
+}
+
+// WithRemoved adds the given value to the Removed field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Removed field.
+func (b *ContainerRulesDiffApplyConfiguration) WithRemoved(values ...string) *ContainerRulesDiffApplyConfiguration {
+	for i := range values {
+		b.Removed = append(b.Removed, values[i])
+	}
+	return b
+}
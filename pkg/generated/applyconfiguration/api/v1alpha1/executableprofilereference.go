@@ -0,0 +1,37 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ExecutableProfileReferenceApplyConfiguration represents a declarative configuration of the ExecutableProfileReference type for use
+// with apply.
+//
+// ExecutableProfileReference references a versioned profile of allowed executables bundled with the enforcer.
+type ExecutableProfileReferenceApplyConfiguration struct {
+	// name of the profile (e.g. nginx).
+	Name *string `json:"name,omitempty"`
+	// version of the profile. The executables of a version never change, so upgrading the enforcer
+	// doesn't change the allowed list of the policies referencing it.
+	Version *string `json:"version,omitempty"`
+}
+
+// ExecutableProfileReferenceApplyConfiguration constructs a declarative configuration of the ExecutableProfileReference type for use with
+// apply.
+func ExecutableProfileReference() *ExecutableProfileReferenceApplyConfiguration {
+	return &ExecutableProfileReferenceApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *ExecutableProfileReferenceApplyConfiguration) WithName(value string) *ExecutableProfileReferenceApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithVersion sets the Version field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Version field is set to the value of the last call.
+func (b *ExecutableProfileReferenceApplyConfiguration) WithVersion(value string) *ExecutableProfileReferenceApplyConfiguration {
+	b.Version = &value
+	return b
+}
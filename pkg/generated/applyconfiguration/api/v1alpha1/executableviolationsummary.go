@@ -0,0 +1,70 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExecutableViolationSummaryApplyConfiguration represents a declarative configuration of the ExecutableViolationSummary type for use
+// with apply.
+//
+// ExecutableViolationSummary aggregates the violations of a single executable.
+type ExecutableViolationSummaryApplyConfiguration struct {
+	// executablePath is the path of the unauthorized executable.
+	ExecutablePath *string `json:"executablePath,omitempty"`
+	// count is the number of violations of the executable.
+	Count *int64 `json:"count,omitempty"`
+	// blockedCount is the number of executions blocked in "protect" mode.
+	BlockedCount *int64 `json:"blockedCount,omitempty"`
+	// firstSeen is when the first violation of the executable occurred.
+	FirstSeen *v1.Time `json:"firstSeen,omitempty"`
+	// lastSeen is when the last violation of the executable occurred.
+	LastSeen *v1.Time `json:"lastSeen,omitempty"`
+}
+
+// ExecutableViolationSummaryApplyConfiguration constructs a declarative configuration of the ExecutableViolationSummary type for use with
+// apply.
+func ExecutableViolationSummary() *ExecutableViolationSummaryApplyConfiguration {
+	return &ExecutableViolationSummaryApplyConfiguration{}
+}
+
+// WithExecutablePath sets the ExecutablePath field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ExecutablePath field is set to the value of the last call.
+func (b *ExecutableViolationSummaryApplyConfiguration) WithExecutablePath(value string) *ExecutableViolationSummaryApplyConfiguration {
+	b.ExecutablePath = &value
+	return b
+}
+
+// WithCount sets the Count field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Count field is set to the value of the last call.
+func (b *ExecutableViolationSummaryApplyConfiguration) WithCount(value int64) *ExecutableViolationSummaryApplyConfiguration {
+	b.Count = &value
+	return b
+}
+
+// WithBlockedCount sets the BlockedCount field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BlockedCount field is set to the value of the last call.
+func (b *ExecutableViolationSummaryApplyConfiguration) WithBlockedCount(value int64) *ExecutableViolationSummaryApplyConfiguration {
+	b.BlockedCount = &value
+	return b
+}
+
+// WithFirstSeen sets the FirstSeen field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FirstSeen field is set to the value of the last call.
+func (b *ExecutableViolationSummaryApplyConfiguration) WithFirstSeen(value v1.Time) *ExecutableViolationSummaryApplyConfiguration {
+	b.FirstSeen = &value
+	return b
+}
+
+// WithLastSeen sets the LastSeen field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastSeen field is set to the value of the last call.
+func (b *ExecutableViolationSummaryApplyConfiguration) WithLastSeen(value v1.Time) *ExecutableViolationSummaryApplyConfiguration {
+	b.LastSeen = &value
+	return b
+}
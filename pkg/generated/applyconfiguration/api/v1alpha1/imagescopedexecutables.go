@@ -0,0 +1,40 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ImageScopedExecutablesApplyConfiguration represents a declarative configuration of the ImageScopedExecutables type for use
+// with apply.
+//
+// ImageScopedExecutables are executables allowed only in the containers running a given image.
+type ImageScopedExecutablesApplyConfiguration struct {
+	// image selects the containers: a digest (e.g. sha256:4f0c...) matches the digest of the image, a reference
+	// with a `/` or a `:` (e.g. registry.example.com/app:v2.1.0) matches the image reference, with or without
+	// its digest, and any other value (e.g. v2.1.0) matches the tag of the image.
+	Image *string `json:"image,omitempty"`
+	// allowed defines the executables allowed in the containers running the image.
+	Allowed []string `json:"allowed,omitempty"`
+}
+
+// ImageScopedExecutablesApplyConfiguration constructs a declarative configuration of the ImageScopedExecutables type for use with
+// apply.
+func ImageScopedExecutables() *ImageScopedExecutablesApplyConfiguration {
+	return &ImageScopedExecutablesApplyConfiguration{}
+}
+
+// WithImage sets the Image field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Image field is set to the value of the last call.
+func (b *ImageScopedExecutablesApplyConfiguration) WithImage(value string) *ImageScopedExecutablesApplyConfiguration {
+	b.Image = &value
+	return b
+}
+
+// WithAllowed adds the given value to the Allowed field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Allowed field.
+func (b *ImageScopedExecutablesApplyConfiguration) WithAllowed(values ...string) *ImageScopedExecutablesApplyConfiguration {
+	for i := range values {
+		b.Allowed = append(b.Allowed, values[i])
+	}
+	return b
+}
@@ -0,0 +1,26 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// NodeEnforcementApplyConfiguration represents a declarative configuration of the NodeEnforcement type for use
+// with apply.
+//
+// NodeEnforcement represents the agent enforcing a policy on a node.
+type NodeEnforcementApplyConfiguration struct {
+	// enforcerVersion is the version of the agent which applied the policy on the node.
+	EnforcerVersion *string `json:"enforcerVersion,omitempty"`
+}
+
+// NodeEnforcementApplyConfiguration constructs a declarative configuration of the NodeEnforcement type for use with
+// apply.
+func NodeEnforcement() *NodeEnforcementApplyConfiguration {
+	return &NodeEnforcementApplyConfiguration{}
+}
+
+// WithEnforcerVersion sets the EnforcerVersion field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EnforcerVersion field is set to the value of the last call.
+func (b *NodeEnforcementApplyConfiguration) WithEnforcerVersion(value string) *NodeEnforcementApplyConfiguration {
+	b.EnforcerVersion = &value
+	return b
+}
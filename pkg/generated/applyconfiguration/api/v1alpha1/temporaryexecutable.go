@@ -0,0 +1,40 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TemporaryExecutableApplyConfiguration represents a declarative configuration of the TemporaryExecutable type for use
+// with apply.
+//
+// TemporaryExecutable is an executable allowed until a given time.
+type TemporaryExecutableApplyConfiguration struct {
+	// path of the executable, an absolute path inside the container filesystem.
+	Path *string `json:"path,omitempty"`
+	// expiresAt is the time after which the executable is no longer allowed.
+	ExpiresAt *v1.Time `json:"expiresAt,omitempty"`
+}
+
+// TemporaryExecutableApplyConfiguration constructs a declarative configuration of the TemporaryExecutable type for use with
+// apply.
+func TemporaryExecutable() *TemporaryExecutableApplyConfiguration {
+	return &TemporaryExecutableApplyConfiguration{}
+}
+
+// WithPath sets the Path field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Path field is set to the value of the last call.
+func (b *TemporaryExecutableApplyConfiguration) WithPath(value string) *TemporaryExecutableApplyConfiguration {
+	b.Path = &value
+	return b
+}
+
+// WithExpiresAt sets the ExpiresAt field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ExpiresAt field is set to the value of the last call.
+func (b *TemporaryExecutableApplyConfiguration) WithExpiresAt(value v1.Time) *TemporaryExecutableApplyConfiguration {
+	b.ExpiresAt = &value
+	return b
+}
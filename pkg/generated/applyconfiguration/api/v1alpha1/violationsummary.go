@@ -0,0 +1,67 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ViolationSummaryApplyConfiguration represents a declarative configuration of the ViolationSummary type for use
+// with apply.
+//
+// ViolationSummary aggregates the violations of a policy over a reporting window.
+type ViolationSummaryApplyConfiguration struct {
+	// windowStart is when the reporting window started.
+	WindowStart *v1.Time `json:"windowStart,omitempty"`
+	// windowEnd is when the reporting window ended.
+	WindowEnd *v1.Time `json:"windowEnd,omitempty"`
+	// totalCount is the number of violations in the window,
+	// including those of executables not listed in topExecutables.
+	TotalCount *int64 `json:"totalCount,omitempty"`
+	// topExecutables lists the executables with the most violations in the window,
+	// sorted by count (max MaxSummaryExecutables).
+	TopExecutables []ExecutableViolationSummaryApplyConfiguration `json:"topExecutables,omitempty"`
+}
+
+// ViolationSummaryApplyConfiguration constructs a declarative configuration of the ViolationSummary type for use with
+// apply.
+func ViolationSummary() *ViolationSummaryApplyConfiguration {
+	return &ViolationSummaryApplyConfiguration{}
+}
+
+// WithWindowStart sets the WindowStart field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WindowStart field is set to the value of the last call.
+func (b *ViolationSummaryApplyConfiguration) WithWindowStart(value v1.Time) *ViolationSummaryApplyConfiguration {
+	b.WindowStart = &value
+	return b
+}
+
+// WithWindowEnd sets the WindowEnd field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WindowEnd field is set to the value of the last call.
+func (b *ViolationSummaryApplyConfiguration) WithWindowEnd(value v1.Time) *ViolationSummaryApplyConfiguration {
+	b.WindowEnd = &value
+	return b
+}
+
+// WithTotalCount sets the TotalCount field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TotalCount field is set to the value of the last call.
+func (b *ViolationSummaryApplyConfiguration) WithTotalCount(value int64) *ViolationSummaryApplyConfiguration {
+	b.TotalCount = &value
+	return b
+}
+
+// WithTopExecutables adds the given value to the TopExecutables field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the TopExecutables field.
+func (b *ViolationSummaryApplyConfiguration) WithTopExecutables(values ...*ExecutableViolationSummaryApplyConfiguration) *ViolationSummaryApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithTopExecutables")
+		}
+		b.TopExecutables = append(b.TopExecutables, *values[i])
+	}
+	return b
+}
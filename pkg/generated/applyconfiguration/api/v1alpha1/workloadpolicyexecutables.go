@@ -5,8 +5,26 @@ package v1alpha1
 // WorkloadPolicyExecutablesApplyConfiguration represents a declarative configuration of the WorkloadPolicyExecutables type for use
 // with apply.
 type WorkloadPolicyExecutablesApplyConfiguration struct {
-	// allowed defines a list of executables that are allowed to run
+	// allowed defines a list of executables that are allowed to run.
+	// Paths are absolute paths inside the container filesystem (e.g. /usr/bin/sleep),
+	// not paths on the host.
 	Allowed []string `json:"allowed,omitempty"`
+	// profiles references curated profiles bundled with the enforcer, whose executables are
+	// allowed on top of the allowed list. They are expanded by the agent when the policy is applied.
+	Profiles []ExecutableProfileReferenceApplyConfiguration `json:"profiles,omitempty"`
+	// temporary defines executables that are allowed until their expiration, for temporary exceptions
+	// (e.g. a migration tool). Expired entries are removed from the allowed list by the agent.
+	Temporary []TemporaryExecutableApplyConfiguration `json:"temporary,omitempty"`
+	// approvedCommands restricts executables to complete command lines: an executable appearing in
+	// approvedCommands can only run with the exact arguments of one of its approved commands, the other
+	// executables are not affected. The executables of the approved commands are allowed too.
+	// The check is best effort: the arguments are read from the memory of the calling process, which
+	// another thread of that process can rewrite while the exec is checked.
+	ApprovedCommands []ApprovedCommandApplyConfiguration `json:"approvedCommands,omitempty"`
+	// imageScoped allows executables only in the containers running a given image, e.g. while the old and
+	// the new versions of a workload coexist during a rolling upgrade. A container gets the executables of
+	// the first entry matching its image on top of the other ones, the entries are ignored when none matches.
+	ImageScoped []ImageScopedExecutablesApplyConfiguration `json:"imageScoped,omitempty"`
 }
 
 // WorkloadPolicyExecutablesApplyConfiguration constructs a declarative configuration of the WorkloadPolicyExecutables type for use with
@@ -24,3 +42,55 @@ func (b *WorkloadPolicyExecutablesApplyConfiguration) WithAllowed(values ...stri
 	}
 	return b
 }
+
+// WithProfiles adds the given value to the Profiles field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Profiles field.
+func (b *WorkloadPolicyExecutablesApplyConfiguration) WithProfiles(values ...*ExecutableProfileReferenceApplyConfiguration) *WorkloadPolicyExecutablesApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithProfiles")
+		}
+		b.Profiles = append(b.Profiles, *values[i])
+	}
+	return b
+}
+
+// WithTemporary adds the given value to the Temporary field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Temporary field.
+func (b *WorkloadPolicyExecutablesApplyConfiguration) WithTemporary(values ...*TemporaryExecutableApplyConfiguration) *WorkloadPolicyExecutablesApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithTemporary")
+		}
+		b.Temporary = append(b.Temporary, *values[i])
+	}
+	return b
+}
+
+// WithApprovedCommands adds the given value to the ApprovedCommands field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ApprovedCommands field.
+func (b *WorkloadPolicyExecutablesApplyConfiguration) WithApprovedCommands(values ...*ApprovedCommandApplyConfiguration) *WorkloadPolicyExecutablesApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithApprovedCommands")
+		}
+		b.ApprovedCommands = append(b.ApprovedCommands, *values[i])
+	}
+	return b
+}
+
+// WithImageScoped adds the given value to the ImageScoped field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ImageScoped field.
+func (b *WorkloadPolicyExecutablesApplyConfiguration) WithImageScoped(values ...*ImageScopedExecutablesApplyConfiguration) *WorkloadPolicyExecutablesApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithImageScoped")
+		}
+		b.ImageScoped = append(b.ImageScoped, *values[i])
+	}
+	return b
+}
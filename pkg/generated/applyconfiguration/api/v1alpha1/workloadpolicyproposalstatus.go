@@ -0,0 +1,44 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// WorkloadPolicyProposalStatusApplyConfiguration represents a declarative configuration of the WorkloadPolicyProposalStatus type for use
+// with apply.
+//
+// WorkloadPolicyProposalStatus defines the observed state of WorkloadPolicyProposal.
+type WorkloadPolicyProposalStatusApplyConfiguration struct {
+	// policyGeneration is the generation of the WorkloadPolicy the diff has been computed against.
+	// It is not set when no WorkloadPolicy with the same name exists.
+	PolicyGeneration *int64 `json:"policyGeneration,omitempty"`
+	// diffByContainer contains, for each container with changes, the difference
+	// between the proposal and the WorkloadPolicy with the same name.
+	DiffByContainer map[string]ContainerRulesDiffApplyConfiguration `json:"diffByContainer,omitempty"`
+}
+
+// WorkloadPolicyProposalStatusApplyConfiguration constructs a declarative configuration of the WorkloadPolicyProposalStatus type for use with
+// apply.
+func WorkloadPolicyProposalStatus() *WorkloadPolicyProposalStatusApplyConfiguration {
+	return &WorkloadPolicyProposalStatusApplyConfiguration{}
+}
+
+// WithPolicyGeneration sets the PolicyGeneration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PolicyGeneration field is set to the value of the last call.
+func (b *WorkloadPolicyProposalStatusApplyConfiguration) WithPolicyGeneration(value int64) *WorkloadPolicyProposalStatusApplyConfiguration {
+	b.PolicyGeneration = &value
+	return b
+}
+
+// WithDiffByContainer puts the entries into the DiffByContainer field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the DiffByContainer field,
+// overwriting an existing map entries in DiffByContainer field with the same key.
+func (b *WorkloadPolicyProposalStatusApplyConfiguration) WithDiffByContainer(entries map[string]ContainerRulesDiffApplyConfiguration) *WorkloadPolicyProposalStatusApplyConfiguration {
+	if b.DiffByContainer == nil && len(entries) > 0 {
+		b.DiffByContainer = make(map[string]ContainerRulesDiffApplyConfiguration, len(entries))
+	}
+	for k, v := range entries {
+		b.DiffByContainer[k] = v
+	}
+	return b
+}
@@ -7,6 +7,21 @@ package v1alpha1
 type WorkloadPolicyRulesApplyConfiguration struct {
 	// executables defines a security policy for executables.
 	Executables *WorkloadPolicyExecutablesApplyConfiguration `json:"executables,omitempty"`
+	// files restricts the files the container can open, on top of the executables it can run.
+	// Nothing is restricted when it is empty.
+	Files *WorkloadPolicyFilesApplyConfiguration `json:"files,omitempty"`
+	// network restricts the destinations the container can connect to.
+	// Nothing is restricted when it is empty.
+	Network *WorkloadPolicyNetworkApplyConfiguration `json:"network,omitempty"`
+	// listeningPorts scopes the rules to the processes listening on one of these TCP ports
+	// (e.g. only the process serving the web traffic).
+	// The scope is not enforced yet: the rules still apply to every process of the container
+	// and the violations are tagged with whether the process was listening on one of the ports.
+	ListeningPorts []int32 `json:"listeningPorts,omitempty"`
+	// mode overrides the mode of the policy for the container, e.g. to keep a sidecar in "monitor" mode
+	// while the main container is in "protect" mode. The mode of the policy applies when it is empty.
+	// Pausing the policy or running it in dry run switches the container to "monitor" mode too.
+	Mode *string `json:"mode,omitempty"`
 }
 
 // WorkloadPolicyRulesApplyConfiguration constructs a declarative configuration of the WorkloadPolicyRules type for use with
@@ -22,3 +37,37 @@ func (b *WorkloadPolicyRulesApplyConfiguration) WithExecutables(value *WorkloadP
 	b.Executables = value
 	return b
 }
+
+// WithFiles sets the Files field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Files field is set to the value of the last call.
+func (b *WorkloadPolicyRulesApplyConfiguration) WithFiles(value *WorkloadPolicyFilesApplyConfiguration) *WorkloadPolicyRulesApplyConfiguration {
+	b.Files = value
+	return b
+}
+
+// WithNetwork sets the Network field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Network field is set to the value of the last call.
+func (b *WorkloadPolicyRulesApplyConfiguration) WithNetwork(value *WorkloadPolicyNetworkApplyConfiguration) *WorkloadPolicyRulesApplyConfiguration {
+	b.Network = value
+	return b
+}
+
+// WithListeningPorts adds the given value to the ListeningPorts field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ListeningPorts field.
+func (b *WorkloadPolicyRulesApplyConfiguration) WithListeningPorts(values ...int32) *WorkloadPolicyRulesApplyConfiguration {
+	for i := range values {
+		b.ListeningPorts = append(b.ListeningPorts, values[i])
+	}
+	return b
+}
+
+// WithMode sets the Mode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Mode field is set to the value of the last call.
+func (b *WorkloadPolicyRulesApplyConfiguration) WithMode(value string) *WorkloadPolicyRulesApplyConfiguration {
+	b.Mode = &value
+	return b
+}
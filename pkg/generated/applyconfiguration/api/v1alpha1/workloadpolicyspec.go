@@ -16,6 +16,43 @@ type WorkloadPolicySpecApplyConfiguration struct {
 	Mode *string `json:"mode,omitempty"`
 	// rulesByContainer specifies for each container the list of rules to apply.
 	RulesByContainer map[string]*apiv1alpha1.WorkloadPolicyRules `json:"rulesByContainer,omitempty"`
+	// blockSuidExec reports the execution of setuid/setgid binaries as a violation,
+	// even if they are in the allowed list. In "protect" mode, the execution is blocked.
+	BlockSuidExec *bool `json:"blockSuidExec,omitempty"`
+	// blockScripts reports the execution of scripts, i.e. files starting with a "#!" shebang line,
+	// as a violation, even if they are in the allowed list. In "protect" mode, the execution is blocked.
+	BlockScripts *bool `json:"blockScripts,omitempty"`
+	// blockUnlinkedExec reports the execution of files with no name left in the filesystem, i.e. deleted binaries
+	// and anonymous memory files created with memfd_create(2), and of the files whose path cannot be resolved,
+	// as a violation, even if their path is in the allowed list. In "protect" mode, the execution is blocked.
+	BlockUnlinkedExec *bool `json:"blockUnlinkedExec,omitempty"`
+	// caseInsensitiveMatching compares the executed paths with the allowed executables ignoring the ASCII case.
+	// Linux paths are case-sensitive: every case variant of an allowed path becomes allowed too,
+	// so enable it only for workloads that really need it.
+	CaseInsensitiveMatching *bool `json:"caseInsensitiveMatching,omitempty"`
+	// maxDistinctExecutables limits the number of distinct executables each container may run,
+	// on top of the allowed list. Every new distinct executable beyond the limit is reported as a violation
+	// and, in "protect" mode, blocked. The count of a container is only reset when the container restarts.
+	MaxDistinctExecutables *int32 `json:"maxDistinctExecutables,omitempty"`
+	// allowedFilesystems restricts the executions to the files stored on one of these filesystem types,
+	// e.g. to stop the binaries dropped on a tmpfs mount. The execution of a file stored on another filesystem type
+	// is reported as a violation, even if its path is in the allowed list. In "protect" mode, the execution is blocked.
+	// The files of a container image are usually on overlay, and ext4 also matches ext2 and ext3.
+	// When unset, the executables may reside on any filesystem.
+	AllowedFilesystems []string `json:"allowedFilesystems,omitempty"`
+	// canaryPercent enforces the declared mode only on the given percentage of the matching pods,
+	// the other pods run in "monitor" mode. On each node, the pods of a workload are ranked by their UID and
+	// the percentage of them is selected, rounded up but never all of them: a workload with a single pod on a node
+	// is not enforced there until the rollout is over. Raising the percentage only adds pods to the selection.
+	// When unset, every matching pod is enforced.
+	CanaryPercent *int32 `json:"canaryPercent,omitempty"`
+	// allowedServiceAccounts restricts the policy to the pods running with one of these service accounts
+	// of the policy namespace, so that a pod cannot inherit the policy of another team by setting the policy label.
+	// The agent refuses to enforce the policy on the other pods and reports it with an event on the pod.
+	// The service account is learned from the API server: a container whose pod service account is not known yet
+	// waits for it briefly and is then prevented from starting, unless agent.nriFailopen is set.
+	// A policy is never enforced on the pods of other namespaces. When unset, every pod of the namespace may use it.
+	AllowedServiceAccounts []string `json:"allowedServiceAccounts,omitempty"`
 }
 
 // WorkloadPolicySpecApplyConfiguration constructs a declarative configuration of the WorkloadPolicySpec type for use with
@@ -45,3 +82,71 @@ func (b *WorkloadPolicySpecApplyConfiguration) WithRulesByContainer(entries map[
 	}
 	return b
 }
+
+// WithBlockSuidExec sets the BlockSuidExec field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BlockSuidExec field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithBlockSuidExec(value bool) *WorkloadPolicySpecApplyConfiguration {
+	b.BlockSuidExec = &value
+	return b
+}
+
+// WithBlockScripts sets the BlockScripts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BlockScripts field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithBlockScripts(value bool) *WorkloadPolicySpecApplyConfiguration {
+	b.BlockScripts = &value
+	return b
+}
+
+// WithBlockUnlinkedExec sets the BlockUnlinkedExec field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BlockUnlinkedExec field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithBlockUnlinkedExec(value bool) *WorkloadPolicySpecApplyConfiguration {
+	b.BlockUnlinkedExec = &value
+	return b
+}
+
+// WithCaseInsensitiveMatching sets the CaseInsensitiveMatching field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CaseInsensitiveMatching field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithCaseInsensitiveMatching(value bool) *WorkloadPolicySpecApplyConfiguration {
+	b.CaseInsensitiveMatching = &value
+	return b
+}
+
+// WithMaxDistinctExecutables sets the MaxDistinctExecutables field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxDistinctExecutables field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithMaxDistinctExecutables(value int32) *WorkloadPolicySpecApplyConfiguration {
+	b.MaxDistinctExecutables = &value
+	return b
+}
+
+// WithAllowedFilesystems adds the given value to the AllowedFilesystems field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedFilesystems field.
+func (b *WorkloadPolicySpecApplyConfiguration) WithAllowedFilesystems(values ...string) *WorkloadPolicySpecApplyConfiguration {
+	for i := range values {
+		b.AllowedFilesystems = append(b.AllowedFilesystems, values[i])
+	}
+	return b
+}
+
+// WithCanaryPercent sets the CanaryPercent field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CanaryPercent field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithCanaryPercent(value int32) *WorkloadPolicySpecApplyConfiguration {
+	b.CanaryPercent = &value
+	return b
+}
+
+// WithAllowedServiceAccounts adds the given value to the AllowedServiceAccounts field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedServiceAccounts field.
+func (b *WorkloadPolicySpecApplyConfiguration) WithAllowedServiceAccounts(values ...string) *WorkloadPolicySpecApplyConfiguration {
+	for i := range values {
+		b.AllowedServiceAccounts = append(b.AllowedServiceAccounts, values[i])
+	}
+	return b
+}
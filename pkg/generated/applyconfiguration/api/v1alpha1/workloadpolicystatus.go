@@ -4,6 +4,7 @@ package v1alpha1
 
 import (
 	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // WorkloadPolicyStatusApplyConfiguration represents a declarative configuration of the WorkloadPolicyStatus type for use
@@ -22,6 +23,12 @@ type WorkloadPolicyStatusApplyConfiguration struct {
 	TransitioningNodes *int `json:"transitioningNodes,omitempty"`
 	// nodesTransitioning contains the names of the nodes that are transitioning.
 	NodesTransitioning []string `json:"nodesTransitioning,omitempty"`
+	// enforcedBy contains, for each node where the policy is applied, the agent which applied it
+	// (max MaxEnforcingNodes nodes).
+	EnforcedBy map[string]NodeEnforcementApplyConfiguration `json:"enforcedBy,omitempty"`
+	// enforcerVersions is the number of nodes where the policy is applied by each version of the agent.
+	// More than one version means that the agents are being upgraded.
+	EnforcerVersions map[string]int `json:"enforcerVersions,omitempty"`
 	// phase indicates the current phase of the workload policy.
 	Phase *apiv1alpha1.Phase `json:"phase,omitempty"`
 	// violationCount is the total number of violation records,
@@ -35,6 +42,21 @@ type WorkloadPolicyStatusApplyConfiguration struct {
 	// violations is the list of the most recent violation records (max MaxViolationRecords).
 	// Oldest entries are dropped when the limit is reached.
 	Violations []ViolationRecordApplyConfiguration `json:"violations,omitempty"`
+	// lastViolationTime is when the most recent violation of the policy happened.
+	// It is updated at most once per status sync interval of the controller.
+	LastViolationTime *v1.Time `json:"lastViolationTime,omitempty"`
+	// lastViolationPath is the executable of the most recent violation of the policy.
+	LastViolationPath *string `json:"lastViolationPath,omitempty"`
+	// violationSummary is the summary of the violations of the last completed reporting window.
+	// It is only reported when the periodic summary is enabled in the controller.
+	ViolationSummary *ViolationSummaryApplyConfiguration `json:"violationSummary,omitempty"`
+	// unobservedExecutables lists, for each container, the allowed executables that have never been run
+	// on any node during the observation period. They are likely typos or dead entries.
+	// It is only reported when the observation is enabled in the controller.
+	UnobservedExecutables map[string][]string `json:"unobservedExecutables,omitempty"`
+	// dryRunImpact reports, for each container, the executions the declared mode would have blocked on all the
+	// nodes since the start of the dry run. It is only reported while the policy is in dry run.
+	DryRunImpact map[string]DryRunImpactApplyConfiguration `json:"dryRunImpact,omitempty"`
 }
 
 // WorkloadPolicyStatusApplyConfiguration constructs a declarative configuration of the WorkloadPolicyStatus type for use with
@@ -107,6 +129,34 @@ func (b *WorkloadPolicyStatusApplyConfiguration) WithNodesTransitioning(values .
 	return b
 }
 
+// WithEnforcedBy puts the entries into the EnforcedBy field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the EnforcedBy field,
+// overwriting an existing map entries in EnforcedBy field with the same key.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithEnforcedBy(entries map[string]NodeEnforcementApplyConfiguration) *WorkloadPolicyStatusApplyConfiguration {
+	if b.EnforcedBy == nil && len(entries) > 0 {
+		b.EnforcedBy = make(map[string]NodeEnforcementApplyConfiguration, len(entries))
+	}
+	for k, v := range entries {
+		b.EnforcedBy[k] = v
+	}
+	return b
+}
+
+// WithEnforcerVersions puts the entries into the EnforcerVersions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the EnforcerVersions field,
+// overwriting an existing map entries in EnforcerVersions field with the same key.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithEnforcerVersions(entries map[string]int) *WorkloadPolicyStatusApplyConfiguration {
+	if b.EnforcerVersions == nil && len(entries) > 0 {
+		b.EnforcerVersions = make(map[string]int, len(entries))
+	}
+	for k, v := range entries {
+		b.EnforcerVersions[k] = v
+	}
+	return b
+}
+
 // WithPhase sets the Phase field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Phase field is set to the value of the last call.
@@ -135,3 +185,55 @@ func (b *WorkloadPolicyStatusApplyConfiguration) WithViolations(values ...*Viola
 	}
 	return b
 }
+
+// WithLastViolationTime sets the LastViolationTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastViolationTime field is set to the value of the last call.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithLastViolationTime(value v1.Time) *WorkloadPolicyStatusApplyConfiguration {
+	b.LastViolationTime = &value
+	return b
+}
+
+// WithLastViolationPath sets the LastViolationPath field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastViolationPath field is set to the value of the last call.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithLastViolationPath(value string) *WorkloadPolicyStatusApplyConfiguration {
+	b.LastViolationPath = &value
+	return b
+}
+
+// WithViolationSummary sets the ViolationSummary field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ViolationSummary field is set to the value of the last call.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithViolationSummary(value *ViolationSummaryApplyConfiguration) *WorkloadPolicyStatusApplyConfiguration {
+	b.ViolationSummary = value
+	return b
+}
+
+// WithUnobservedExecutables puts the entries into the UnobservedExecutables field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the UnobservedExecutables field,
+// overwriting an existing map entries in UnobservedExecutables field with the same key.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithUnobservedExecutables(entries map[string][]string) *WorkloadPolicyStatusApplyConfiguration {
+	if b.UnobservedExecutables == nil && len(entries) > 0 {
+		b.UnobservedExecutables = make(map[string][]string, len(entries))
+	}
+	for k, v := range entries {
+		b.UnobservedExecutables[k] = v
+	}
+	return b
+}
+
+// WithDryRunImpact puts the entries into the DryRunImpact field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the DryRunImpact field,
+// overwriting an existing map entries in DryRunImpact field with the same key.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithDryRunImpact(entries map[string]DryRunImpactApplyConfiguration) *WorkloadPolicyStatusApplyConfiguration {
+	if b.DryRunImpact == nil && len(entries) > 0 {
+		b.DryRunImpact = make(map[string]DryRunImpactApplyConfiguration, len(entries))
+	}
+	for k, v := range entries {
+		b.DryRunImpact[k] = v
+	}
+	return b
+}
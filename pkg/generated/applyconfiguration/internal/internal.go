@@ -23,6 +23,96 @@ func Parser() *typed.Parser {
 var parserOnce sync.Once
 var parser *typed.Parser
 var schemaYAML = typed.YAMLObject(`types:
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ApprovedCommand
+  map:
+    fields:
+    - name: args
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: path
+      type:
+        scalar: string
+      default: ""
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ContainerRulesDiff
+  map:
+    fields:
+    - name: added
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: removed
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.DryRunImpact
+  map:
+    fields:
+    - name: count
+      type:
+        scalar: numeric
+    - name: executables
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableProfileReference
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+      default: ""
+    - name: version
+      type:
+        scalar: string
+      default: ""
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableViolationSummary
+  map:
+    fields:
+    - name: blockedCount
+      type:
+        scalar: numeric
+    - name: count
+      type:
+        scalar: numeric
+      default: 0
+    - name: executablePath
+      type:
+        scalar: string
+      default: ""
+    - name: firstSeen
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: lastSeen
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ImageScopedExecutables
+  map:
+    fields:
+    - name: allowed
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: image
+      type:
+        scalar: string
+      default: ""
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.NodeEnforcement
+  map:
+    fields:
+    - name: enforcerVersion
+      type:
+        scalar: string
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.NodeIssue
   map:
     fields:
@@ -32,6 +122,16 @@ var schemaYAML = typed.YAMLObject(`types:
     - name: message
       type:
         scalar: string
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.TemporaryExecutable
+  map:
+    fields:
+    - name: expiresAt
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: path
+      type:
+        scalar: string
+      default: ""
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ViolationRecord
   map:
     fields:
@@ -58,6 +158,25 @@ var schemaYAML = typed.YAMLObject(`types:
     - name: timestamp
       type:
         namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ViolationSummary
+  map:
+    fields:
+    - name: topExecutables
+      type:
+        list:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableViolationSummary
+          elementRelationship: atomic
+    - name: totalCount
+      type:
+        scalar: numeric
+      default: 0
+    - name: windowEnd
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: windowStart
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicy
   map:
     fields:
@@ -88,6 +207,60 @@ var schemaYAML = typed.YAMLObject(`types:
           elementType:
             scalar: string
           elementRelationship: atomic
+    - name: approvedCommands
+      type:
+        list:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ApprovedCommand
+          elementRelationship: atomic
+    - name: imageScoped
+      type:
+        list:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ImageScopedExecutables
+          elementRelationship: atomic
+    - name: profiles
+      type:
+        list:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableProfileReference
+          elementRelationship: atomic
+    - name: temporary
+      type:
+        list:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.TemporaryExecutable
+          elementRelationship: atomic
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyFiles
+  map:
+    fields:
+    - name: allowedRead
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: allowedWrite
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: deniedWrite
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyNetwork
+  map:
+    fields:
+    - name: allowedEgress
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposal
   map:
     fields:
@@ -105,6 +278,10 @@ var schemaYAML = typed.YAMLObject(`types:
       type:
         namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalSpec
       default: {}
+    - name: status
+      type:
+        namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalStatus
+      default: {}
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalSpec
   map:
     fields:
@@ -113,6 +290,17 @@ var schemaYAML = typed.YAMLObject(`types:
         map:
           elementType:
             namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyRules
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalStatus
+  map:
+    fields:
+    - name: diffByContainer
+      type:
+        map:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ContainerRulesDiff
+    - name: policyGeneration
+      type:
+        scalar: numeric
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyRules
   map:
     fields:
@@ -120,9 +308,56 @@ var schemaYAML = typed.YAMLObject(`types:
       type:
         namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyExecutables
       default: {}
+    - name: files
+      type:
+        namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyFiles
+      default: {}
+    - name: listeningPorts
+      type:
+        list:
+          elementType:
+            scalar: numeric
+          elementRelationship: atomic
+    - name: mode
+      type:
+        scalar: string
+    - name: network
+      type:
+        namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyNetwork
+      default: {}
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicySpec
   map:
     fields:
+    - name: allowedFilesystems
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: allowedServiceAccounts
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: blockScripts
+      type:
+        scalar: boolean
+    - name: blockSuidExec
+      type:
+        scalar: boolean
+    - name: blockUnlinkedExec
+      type:
+        scalar: boolean
+    - name: canaryPercent
+      type:
+        scalar: numeric
+    - name: caseInsensitiveMatching
+      type:
+        scalar: boolean
+    - name: maxDistinctExecutables
+      type:
+        scalar: numeric
     - name: mode
       type:
         scalar: string
@@ -134,9 +369,30 @@ var schemaYAML = typed.YAMLObject(`types:
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyStatus
   map:
     fields:
+    - name: dryRunImpact
+      type:
+        map:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.DryRunImpact
+    - name: enforcedBy
+      type:
+        map:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.NodeEnforcement
+    - name: enforcerVersions
+      type:
+        map:
+          elementType:
+            scalar: numeric
     - name: failedNodes
       type:
         scalar: numeric
+    - name: lastViolationPath
+      type:
+        scalar: string
+    - name: lastViolationTime
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
     - name: nodesTransitioning
       type:
         list:
@@ -163,9 +419,20 @@ var schemaYAML = typed.YAMLObject(`types:
     - name: transitioningNodes
       type:
         scalar: numeric
+    - name: unobservedExecutables
+      type:
+        map:
+          elementType:
+            list:
+              elementType:
+                scalar: string
+              elementRelationship: atomic
     - name: violationCount
       type:
         scalar: numeric
+    - name: violationSummary
+      type:
+        namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ViolationSummary
     - name: violations
       type:
         list:
@@ -16,18 +16,42 @@ import (
 func ForKind(kind schema.GroupVersionKind) interface{} {
 	switch kind {
 	// Group=security.rancher.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("ApprovedCommand"):
+		return &apiv1alpha1.ApprovedCommandApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ContainerRulesDiff"):
+		return &apiv1alpha1.ContainerRulesDiffApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("DryRunImpact"):
+		return &apiv1alpha1.DryRunImpactApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ExecutableProfileReference"):
+		return &apiv1alpha1.ExecutableProfileReferenceApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ExecutableViolationSummary"):
+		return &apiv1alpha1.ExecutableViolationSummaryApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ImageScopedExecutables"):
+		return &apiv1alpha1.ImageScopedExecutablesApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("NodeEnforcement"):
+		return &apiv1alpha1.NodeEnforcementApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("NodeIssue"):
 		return &apiv1alpha1.NodeIssueApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("TemporaryExecutable"):
+		return &apiv1alpha1.TemporaryExecutableApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("ViolationRecord"):
 		return &apiv1alpha1.ViolationRecordApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ViolationSummary"):
+		return &apiv1alpha1.ViolationSummaryApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicy"):
 		return &apiv1alpha1.WorkloadPolicyApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyExecutables"):
 		return &apiv1alpha1.WorkloadPolicyExecutablesApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyFiles"):
+		return &apiv1alpha1.WorkloadPolicyFilesApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyNetwork"):
+		return &apiv1alpha1.WorkloadPolicyNetworkApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyProposal"):
 		return &apiv1alpha1.WorkloadPolicyProposalApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyProposalSpec"):
 		return &apiv1alpha1.WorkloadPolicyProposalSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyProposalStatus"):
+		return &apiv1alpha1.WorkloadPolicyProposalStatusApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyRules"):
 		return &apiv1alpha1.WorkloadPolicyRulesApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicySpec"):
@@ -17,71 +17,336 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		v1alpha1.NodeIssue{}.OpenAPIModelName():                  schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_NodeIssue(ref),
-		v1alpha1.ViolationRecord{}.OpenAPIModelName():            schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ViolationRecord(ref),
-		v1alpha1.WorkloadPolicy{}.OpenAPIModelName():             schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicy(ref),
-		v1alpha1.WorkloadPolicyExecutables{}.OpenAPIModelName():  schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyExecutables(ref),
-		v1alpha1.WorkloadPolicyList{}.OpenAPIModelName():         schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyList(ref),
-		v1alpha1.WorkloadPolicyProposal{}.OpenAPIModelName():     schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposal(ref),
-		v1alpha1.WorkloadPolicyProposalList{}.OpenAPIModelName(): schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalList(ref),
-		v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName(): schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalSpec(ref),
-		v1alpha1.WorkloadPolicyRules{}.OpenAPIModelName():        schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyRules(ref),
-		v1alpha1.WorkloadPolicySpec{}.OpenAPIModelName():         schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicySpec(ref),
-		v1alpha1.WorkloadPolicyStatus{}.OpenAPIModelName():       schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyStatus(ref),
-		resource.Quantity{}.OpenAPIModelName():                   schema_apimachinery_pkg_api_resource_Quantity(ref),
-		v1.APIGroup{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_APIGroup(ref),
-		v1.APIGroupList{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_APIGroupList(ref),
-		v1.APIResource{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_APIResource(ref),
-		v1.APIResourceList{}.OpenAPIModelName():                  schema_pkg_apis_meta_v1_APIResourceList(ref),
-		v1.APIVersions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_APIVersions(ref),
-		v1.ApplyOptions{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_ApplyOptions(ref),
-		v1.Condition{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_Condition(ref),
-		v1.CreateOptions{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_CreateOptions(ref),
-		v1.DeleteOptions{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_DeleteOptions(ref),
-		v1.Duration{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_Duration(ref),
-		v1.FieldSelectorRequirement{}.OpenAPIModelName():         schema_pkg_apis_meta_v1_FieldSelectorRequirement(ref),
-		v1.FieldsV1{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_FieldsV1(ref),
-		v1.GetOptions{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_GetOptions(ref),
-		v1.GroupKind{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_GroupKind(ref),
-		v1.GroupResource{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_GroupResource(ref),
-		v1.GroupVersion{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_GroupVersion(ref),
-		v1.GroupVersionForDiscovery{}.OpenAPIModelName():         schema_pkg_apis_meta_v1_GroupVersionForDiscovery(ref),
-		v1.GroupVersionKind{}.OpenAPIModelName():                 schema_pkg_apis_meta_v1_GroupVersionKind(ref),
-		v1.GroupVersionResource{}.OpenAPIModelName():             schema_pkg_apis_meta_v1_GroupVersionResource(ref),
-		v1.InternalEvent{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_InternalEvent(ref),
-		v1.LabelSelector{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_LabelSelector(ref),
-		v1.LabelSelectorRequirement{}.OpenAPIModelName():         schema_pkg_apis_meta_v1_LabelSelectorRequirement(ref),
-		v1.List{}.OpenAPIModelName():                             schema_pkg_apis_meta_v1_List(ref),
-		v1.ListMeta{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_ListMeta(ref),
-		v1.ListOptions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_ListOptions(ref),
-		v1.ManagedFieldsEntry{}.OpenAPIModelName():               schema_pkg_apis_meta_v1_ManagedFieldsEntry(ref),
-		v1.MicroTime{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_MicroTime(ref),
-		v1.ObjectMeta{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_ObjectMeta(ref),
-		v1.OwnerReference{}.OpenAPIModelName():                   schema_pkg_apis_meta_v1_OwnerReference(ref),
-		v1.PartialObjectMetadata{}.OpenAPIModelName():            schema_pkg_apis_meta_v1_PartialObjectMetadata(ref),
-		v1.PartialObjectMetadataList{}.OpenAPIModelName():        schema_pkg_apis_meta_v1_PartialObjectMetadataList(ref),
-		v1.Patch{}.OpenAPIModelName():                            schema_pkg_apis_meta_v1_Patch(ref),
-		v1.PatchOptions{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_PatchOptions(ref),
-		v1.Preconditions{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_Preconditions(ref),
-		v1.RootPaths{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_RootPaths(ref),
-		v1.ServerAddressByClientCIDR{}.OpenAPIModelName():        schema_pkg_apis_meta_v1_ServerAddressByClientCIDR(ref),
-		v1.Status{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_Status(ref),
-		v1.StatusCause{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_StatusCause(ref),
-		v1.StatusDetails{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_StatusDetails(ref),
-		v1.Table{}.OpenAPIModelName():                            schema_pkg_apis_meta_v1_Table(ref),
-		v1.TableColumnDefinition{}.OpenAPIModelName():            schema_pkg_apis_meta_v1_TableColumnDefinition(ref),
-		v1.TableOptions{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_TableOptions(ref),
-		v1.TableRow{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_TableRow(ref),
-		v1.TableRowCondition{}.OpenAPIModelName():                schema_pkg_apis_meta_v1_TableRowCondition(ref),
-		v1.Time{}.OpenAPIModelName():                             schema_pkg_apis_meta_v1_Time(ref),
-		v1.Timestamp{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_Timestamp(ref),
-		v1.TypeMeta{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_TypeMeta(ref),
-		v1.UpdateOptions{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_UpdateOptions(ref),
-		v1.WatchEvent{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_WatchEvent(ref),
-		runtime.RawExtension{}.OpenAPIModelName():                schema_k8sio_apimachinery_pkg_runtime_RawExtension(ref),
-		runtime.TypeMeta{}.OpenAPIModelName():                    schema_k8sio_apimachinery_pkg_runtime_TypeMeta(ref),
-		runtime.Unknown{}.OpenAPIModelName():                     schema_k8sio_apimachinery_pkg_runtime_Unknown(ref),
-		version.Info{}.OpenAPIModelName():                        schema_k8sio_apimachinery_pkg_version_Info(ref),
+		v1alpha1.ApprovedCommand{}.OpenAPIModelName():              schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ApprovedCommand(ref),
+		v1alpha1.ContainerRulesDiff{}.OpenAPIModelName():           schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ContainerRulesDiff(ref),
+		v1alpha1.DryRunImpact{}.OpenAPIModelName():                 schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_DryRunImpact(ref),
+		v1alpha1.ExecutableProfileReference{}.OpenAPIModelName():   schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ExecutableProfileReference(ref),
+		v1alpha1.ExecutableViolationSummary{}.OpenAPIModelName():   schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ExecutableViolationSummary(ref),
+		v1alpha1.ImageScopedExecutables{}.OpenAPIModelName():       schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ImageScopedExecutables(ref),
+		v1alpha1.NodeEnforcement{}.OpenAPIModelName():              schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_NodeEnforcement(ref),
+		v1alpha1.NodeIssue{}.OpenAPIModelName():                    schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_NodeIssue(ref),
+		v1alpha1.TemporaryExecutable{}.OpenAPIModelName():          schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_TemporaryExecutable(ref),
+		v1alpha1.ViolationRecord{}.OpenAPIModelName():              schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ViolationRecord(ref),
+		v1alpha1.ViolationSummary{}.OpenAPIModelName():             schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ViolationSummary(ref),
+		v1alpha1.WorkloadPolicy{}.OpenAPIModelName():               schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicy(ref),
+		v1alpha1.WorkloadPolicyExecutables{}.OpenAPIModelName():    schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyExecutables(ref),
+		v1alpha1.WorkloadPolicyFiles{}.OpenAPIModelName():          schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyFiles(ref),
+		v1alpha1.WorkloadPolicyList{}.OpenAPIModelName():           schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyList(ref),
+		v1alpha1.WorkloadPolicyNetwork{}.OpenAPIModelName():        schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyNetwork(ref),
+		v1alpha1.WorkloadPolicyProposal{}.OpenAPIModelName():       schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposal(ref),
+		v1alpha1.WorkloadPolicyProposalList{}.OpenAPIModelName():   schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalList(ref),
+		v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName():   schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalSpec(ref),
+		v1alpha1.WorkloadPolicyProposalStatus{}.OpenAPIModelName(): schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalStatus(ref),
+		v1alpha1.WorkloadPolicyRules{}.OpenAPIModelName():          schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyRules(ref),
+		v1alpha1.WorkloadPolicySpec{}.OpenAPIModelName():           schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicySpec(ref),
+		v1alpha1.WorkloadPolicyStatus{}.OpenAPIModelName():         schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyStatus(ref),
+		resource.Quantity{}.OpenAPIModelName():                     schema_apimachinery_pkg_api_resource_Quantity(ref),
+		v1.APIGroup{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_APIGroup(ref),
+		v1.APIGroupList{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_APIGroupList(ref),
+		v1.APIResource{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_APIResource(ref),
+		v1.APIResourceList{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_APIResourceList(ref),
+		v1.APIVersions{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_APIVersions(ref),
+		v1.ApplyOptions{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_ApplyOptions(ref),
+		v1.Condition{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_Condition(ref),
+		v1.CreateOptions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_CreateOptions(ref),
+		v1.DeleteOptions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_DeleteOptions(ref),
+		v1.Duration{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_Duration(ref),
+		v1.FieldSelectorRequirement{}.OpenAPIModelName():           schema_pkg_apis_meta_v1_FieldSelectorRequirement(ref),
+		v1.FieldsV1{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_FieldsV1(ref),
+		v1.GetOptions{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_GetOptions(ref),
+		v1.GroupKind{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_GroupKind(ref),
+		v1.GroupResource{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_GroupResource(ref),
+		v1.GroupVersion{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_GroupVersion(ref),
+		v1.GroupVersionForDiscovery{}.OpenAPIModelName():           schema_pkg_apis_meta_v1_GroupVersionForDiscovery(ref),
+		v1.GroupVersionKind{}.OpenAPIModelName():                   schema_pkg_apis_meta_v1_GroupVersionKind(ref),
+		v1.GroupVersionResource{}.OpenAPIModelName():               schema_pkg_apis_meta_v1_GroupVersionResource(ref),
+		v1.InternalEvent{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_InternalEvent(ref),
+		v1.LabelSelector{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_LabelSelector(ref),
+		v1.LabelSelectorRequirement{}.OpenAPIModelName():           schema_pkg_apis_meta_v1_LabelSelectorRequirement(ref),
+		v1.List{}.OpenAPIModelName():                               schema_pkg_apis_meta_v1_List(ref),
+		v1.ListMeta{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_ListMeta(ref),
+		v1.ListOptions{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_ListOptions(ref),
+		v1.ManagedFieldsEntry{}.OpenAPIModelName():                 schema_pkg_apis_meta_v1_ManagedFieldsEntry(ref),
+		v1.MicroTime{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_MicroTime(ref),
+		v1.ObjectMeta{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_ObjectMeta(ref),
+		v1.OwnerReference{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_OwnerReference(ref),
+		v1.PartialObjectMetadata{}.OpenAPIModelName():              schema_pkg_apis_meta_v1_PartialObjectMetadata(ref),
+		v1.PartialObjectMetadataList{}.OpenAPIModelName():          schema_pkg_apis_meta_v1_PartialObjectMetadataList(ref),
+		v1.Patch{}.OpenAPIModelName():                              schema_pkg_apis_meta_v1_Patch(ref),
+		v1.PatchOptions{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_PatchOptions(ref),
+		v1.Preconditions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_Preconditions(ref),
+		v1.RootPaths{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_RootPaths(ref),
+		v1.ServerAddressByClientCIDR{}.OpenAPIModelName():          schema_pkg_apis_meta_v1_ServerAddressByClientCIDR(ref),
+		v1.Status{}.OpenAPIModelName():                             schema_pkg_apis_meta_v1_Status(ref),
+		v1.StatusCause{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_StatusCause(ref),
+		v1.StatusDetails{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_StatusDetails(ref),
+		v1.Table{}.OpenAPIModelName():                              schema_pkg_apis_meta_v1_Table(ref),
+		v1.TableColumnDefinition{}.OpenAPIModelName():              schema_pkg_apis_meta_v1_TableColumnDefinition(ref),
+		v1.TableOptions{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_TableOptions(ref),
+		v1.TableRow{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_TableRow(ref),
+		v1.TableRowCondition{}.OpenAPIModelName():                  schema_pkg_apis_meta_v1_TableRowCondition(ref),
+		v1.Time{}.OpenAPIModelName():                               schema_pkg_apis_meta_v1_Time(ref),
+		v1.Timestamp{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_Timestamp(ref),
+		v1.TypeMeta{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_TypeMeta(ref),
+		v1.UpdateOptions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_UpdateOptions(ref),
+		v1.WatchEvent{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_WatchEvent(ref),
+		runtime.RawExtension{}.OpenAPIModelName():                  schema_k8sio_apimachinery_pkg_runtime_RawExtension(ref),
+		runtime.TypeMeta{}.OpenAPIModelName():                      schema_k8sio_apimachinery_pkg_runtime_TypeMeta(ref),
+		runtime.Unknown{}.OpenAPIModelName():                       schema_k8sio_apimachinery_pkg_runtime_Unknown(ref),
+		version.Info{}.OpenAPIModelName():                          schema_k8sio_apimachinery_pkg_version_Info(ref),
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ApprovedCommand(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ApprovedCommand is a complete command line allowed to run.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "path of the executable, an absolute path inside the container filesystem.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"args": {
+						SchemaProps: spec.SchemaProps{
+							Description: "args are the arguments following the program name (argv[0]), which is not part of the command since the caller can set it freely. They are compared byte by byte, with no shell parsing.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"path"},
+			},
+		},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ContainerRulesDiff(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ContainerRulesDiff describes how the proposal changes the allowed executables of a container.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"added": {
+						SchemaProps: spec.SchemaProps{
+							Description: "added contains the executables allowed by the proposal but not by the enforced policy.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"removed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "removed contains the executables allowed by the enforced policy but not by the proposal.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_DryRunImpact(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DryRunImpact reports the executions of a container that the declared mode of a policy in dry run would have blocked.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"count": {
+						SchemaProps: spec.SchemaProps{
+							Description: "count is the number of executions that would have been blocked.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"executables": {
+						SchemaProps: spec.SchemaProps{
+							Description: "executables lists the sorted executables that would have been blocked (max MaxDryRunExecutables).",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ExecutableProfileReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExecutableProfileReference references a versioned profile of allowed executables bundled with the enforcer.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "name of the profile (e.g. nginx).",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "version of the profile. The executables of a version never change, so upgrading the enforcer doesn't change the allowed list of the policies referencing it.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "version"},
+			},
+		},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ExecutableViolationSummary(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExecutableViolationSummary aggregates the violations of a single executable.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"executablePath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "executablePath is the path of the unauthorized executable.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"count": {
+						SchemaProps: spec.SchemaProps{
+							Description: "count is the number of violations of the executable.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"blockedCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "blockedCount is the number of executions blocked in \"protect\" mode.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"firstSeen": {
+						SchemaProps: spec.SchemaProps{
+							Description: "firstSeen is when the first violation of the executable occurred.",
+							Ref:         ref(v1.Time{}.OpenAPIModelName()),
+						},
+					},
+					"lastSeen": {
+						SchemaProps: spec.SchemaProps{
+							Description: "lastSeen is when the last violation of the executable occurred.",
+							Ref:         ref(v1.Time{}.OpenAPIModelName()),
+						},
+					},
+				},
+				Required: []string{"executablePath", "count", "firstSeen", "lastSeen"},
+			},
+		},
+		Dependencies: []string{
+			v1.Time{}.OpenAPIModelName()},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ImageScopedExecutables(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ImageScopedExecutables are executables allowed only in the containers running a given image.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "image selects the containers: a digest (e.g. sha256:4f0c...) matches the digest of the image, a reference with a `/` or a `:` (e.g. registry.example.com/app:v2.1.0) matches the image reference, with or without its digest, and any other value (e.g. v2.1.0) matches the tag of the image.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"allowed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowed defines the executables allowed in the containers running the image.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"image"},
+			},
+		},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_NodeEnforcement(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "NodeEnforcement represents the agent enforcing a policy on a node.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enforcerVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "enforcerVersion is the version of the agent which applied the policy on the node.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -112,6 +377,36 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_NodeIssue(ref common.R
 	}
 }
 
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_TemporaryExecutable(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TemporaryExecutable is an executable allowed until a given time.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "path of the executable, an absolute path inside the container filesystem.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"expiresAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "expiresAt is the time after which the executable is no longer allowed.",
+							Ref:         ref(v1.Time{}.OpenAPIModelName()),
+						},
+					},
+				},
+				Required: []string{"path", "expiresAt"},
+			},
+		},
+		Dependencies: []string{
+			v1.Time{}.OpenAPIModelName()},
+	}
+}
+
 func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ViolationRecord(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -174,6 +469,56 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ViolationRecord(ref co
 	}
 }
 
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ViolationSummary(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ViolationSummary aggregates the violations of a policy over a reporting window.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"windowStart": {
+						SchemaProps: spec.SchemaProps{
+							Description: "windowStart is when the reporting window started.",
+							Ref:         ref(v1.Time{}.OpenAPIModelName()),
+						},
+					},
+					"windowEnd": {
+						SchemaProps: spec.SchemaProps{
+							Description: "windowEnd is when the reporting window ended.",
+							Ref:         ref(v1.Time{}.OpenAPIModelName()),
+						},
+					},
+					"totalCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "totalCount is the number of violations in the window, including those of executables not listed in topExecutables.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"topExecutables": {
+						SchemaProps: spec.SchemaProps{
+							Description: "topExecutables lists the executables with the most violations in the window, sorted by count (max MaxSummaryExecutables).",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.ExecutableViolationSummary{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"windowStart", "windowEnd", "totalCount"},
+			},
+		},
+		Dependencies: []string{
+			v1alpha1.ExecutableViolationSummary{}.OpenAPIModelName(), v1.Time{}.OpenAPIModelName()},
+	}
+}
+
 func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -201,35 +546,151 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicy(ref com
 							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
 						},
 					},
-					"spec": {
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1alpha1.WorkloadPolicySpec{}.OpenAPIModelName()),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1alpha1.WorkloadPolicyStatus{}.OpenAPIModelName()),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1alpha1.WorkloadPolicySpec{}.OpenAPIModelName(), v1alpha1.WorkloadPolicyStatus{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyExecutables(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"allowed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowed defines a list of executables that are allowed to run. Paths are absolute paths inside the container filesystem (e.g. /usr/bin/sleep), not paths on the host. An entry ending with `*` (e.g. /opt/app/bin/*) allows every executable whose path starts with the part before `*`, in any subdirectory. The part before `*` is limited to 248 bytes and `*` is only supported at the end. The global deny list of the agent applies to the executables allowed by such an entry too.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"profiles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "profiles references curated profiles bundled with the enforcer, whose executables are allowed on top of the allowed list. They are expanded by the agent when the policy is applied.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.ExecutableProfileReference{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+					"temporary": {
+						SchemaProps: spec.SchemaProps{
+							Description: "temporary defines executables that are allowed until their expiration, for temporary exceptions (e.g. a migration tool). Expired entries are removed from the allowed list by the agent.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.TemporaryExecutable{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+					"approvedCommands": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1alpha1.WorkloadPolicySpec{}.OpenAPIModelName()),
+							Description: "approvedCommands restricts executables to complete command lines: an executable appearing in approvedCommands can only run with the exact arguments of one of its approved commands, the other executables are not affected. The executables of the approved commands are allowed too.\nThe check is best effort: the arguments are read from the memory of the calling process, which\nanother thread of that process can rewrite while the exec is checked.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.ApprovedCommand{}.OpenAPIModelName()),
+									},
+								},
+							},
 						},
 					},
-					"status": {
+					"imageScoped": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1alpha1.WorkloadPolicyStatus{}.OpenAPIModelName()),
+							Description: "imageScoped allows executables only in the containers running a given image, e.g. while the old and the new versions of a workload coexist during a rolling upgrade. A container gets the executables of the first entry matching its image on top of the other ones, the entries are ignored when none matches.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.ImageScopedExecutables{}.OpenAPIModelName()),
+									},
+								},
+							},
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			v1alpha1.WorkloadPolicySpec{}.OpenAPIModelName(), v1alpha1.WorkloadPolicyStatus{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+			v1alpha1.ApprovedCommand{}.OpenAPIModelName(), v1alpha1.ExecutableProfileReference{}.OpenAPIModelName(), v1alpha1.ImageScopedExecutables{}.OpenAPIModelName(), v1alpha1.TemporaryExecutable{}.OpenAPIModelName()},
 	}
 }
 
-func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyExecutables(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyFiles(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "WorkloadPolicyFiles restricts the files the processes of a container can open. The entries are absolute paths inside the container filesystem. An entry ending with `*` (e.g. /etc/*) matches every file whose path starts with the part before `*`, in any subdirectory. The entries, without their trailing `*`, are limited to 247 bytes. The most specific entry matching a file applies, along with the less specific ones.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"allowed": {
+					"allowedRead": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowedRead lists the files the container can open for reading. When it is set, opening any other file for reading is a violation.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"allowedWrite": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowedWrite lists the files the container can open for writing. When it is set, opening any other file for writing is a violation.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"deniedWrite": {
 						SchemaProps: spec.SchemaProps{
-							Description: "allowed defines a list of executables that are allowed to run",
+							Description: "deniedWrite lists the files the container can never open for writing, even if they are allowed by allowedWrite (e.g. /etc/* while /etc/app/cache/* is allowed).",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -297,6 +758,34 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyList(ref
 	}
 }
 
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyNetwork(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WorkloadPolicyNetwork restricts the network connections of the processes of a container.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"allowedEgress": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowedEgress lists the destinations the container can connect to, as `CIDR:port` or `IP:port` (e.g. 10.96.0.10:53, 10.0.0.0/8:443 or [2001:db8::/32]:443). When it is set, connecting to any other IPv4 or IPv6 destination is a violation. The host names are not supported: the connections are checked against the address they are made to, after the name resolution.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposal(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -330,11 +819,17 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposal
 							Ref:     ref(v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName()),
 						},
 					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1alpha1.WorkloadPolicyProposalStatus{}.OpenAPIModelName()),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+			v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName(), v1alpha1.WorkloadPolicyProposalStatus{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
 	}
 }
 
@@ -416,6 +911,43 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposal
 	}
 }
 
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WorkloadPolicyProposalStatus defines the observed state of WorkloadPolicyProposal.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"policyGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "policyGeneration is the generation of the WorkloadPolicy the diff has been computed against. It is not set when no WorkloadPolicy with the same name exists.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"diffByContainer": {
+						SchemaProps: spec.SchemaProps{
+							Description: "diffByContainer contains, for each container with changes, the difference between the proposal and the WorkloadPolicy with the same name.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.ContainerRulesDiff{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1alpha1.ContainerRulesDiff{}.OpenAPIModelName()},
+	}
+}
+
 func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyRules(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -429,11 +961,47 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyRules(re
 							Ref:         ref(v1alpha1.WorkloadPolicyExecutables{}.OpenAPIModelName()),
 						},
 					},
+					"files": {
+						SchemaProps: spec.SchemaProps{
+							Description: "files restricts the files the container can open, on top of the executables it can run. Nothing is restricted when it is empty.",
+							Default:     map[string]interface{}{},
+							Ref:         ref(v1alpha1.WorkloadPolicyFiles{}.OpenAPIModelName()),
+						},
+					},
+					"network": {
+						SchemaProps: spec.SchemaProps{
+							Description: "network restricts the destinations the container can connect to. Nothing is restricted when it is empty.",
+							Default:     map[string]interface{}{},
+							Ref:         ref(v1alpha1.WorkloadPolicyNetwork{}.OpenAPIModelName()),
+						},
+					},
+					"listeningPorts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "listeningPorts scopes the rules to the processes listening on one of these TCP ports (e.g. only the process serving the web traffic). The scope is not enforced yet: the rules still apply to every process of the container and the violations are tagged with whether the process was listening on one of the ports.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
+						},
+					},
+					"mode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "mode overrides the mode of the policy for the container, e.g. to keep a sidecar in \"monitor\" mode while the main container is in \"protect\" mode. The mode of the policy applies when it is empty. Pausing the policy or running it in dry run switches the container to \"monitor\" mode too.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			v1alpha1.WorkloadPolicyExecutables{}.OpenAPIModelName()},
+			v1alpha1.WorkloadPolicyExecutables{}.OpenAPIModelName(), v1alpha1.WorkloadPolicyFiles{}.OpenAPIModelName(), v1alpha1.WorkloadPolicyNetwork{}.OpenAPIModelName()},
 	}
 }
 
@@ -445,7 +1013,7 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicySpec(ref
 				Properties: map[string]spec.Schema{
 					"mode": {
 						SchemaProps: spec.SchemaProps{
-							Description: "mode defines the execution mode of this policy. Can be set to either \"protect\" or \"monitor\". In \"protect\" mode, the policy blocks and reports violations, while in \"monitor\" mode, it only reports violations.",
+							Description: "mode defines the execution mode of this policy. Can be set to \"protect\", \"monitor\" or \"audit\". In \"protect\" mode, the policy blocks and reports violations, while in \"monitor\" mode, it only reports violations. In \"audit\" mode, nothing is blocked and every execution is reported, allowed ones included.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -464,6 +1032,78 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicySpec(ref
 							},
 						},
 					},
+					"blockSuidExec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "blockSuidExec reports the execution of setuid/setgid binaries as a violation, even if they are in the allowed list. In \"protect\" mode, the execution is blocked.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"blockScripts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "blockScripts reports the execution of scripts, i.e. files starting with a \"#!\" shebang line, as a violation, even if they are in the allowed list. In \"protect\" mode, the execution is blocked.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"blockUnlinkedExec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "blockUnlinkedExec reports the execution of files with no name left in the filesystem, i.e. deleted binaries and anonymous memory files created with memfd_create(2), and of the files whose path cannot be resolved, as a violation, even if their path is in the allowed list. In \"protect\" mode, the execution is blocked.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"caseInsensitiveMatching": {
+						SchemaProps: spec.SchemaProps{
+							Description: "caseInsensitiveMatching compares the executed paths with the allowed executables ignoring the ASCII case. Linux paths are case-sensitive: every case variant of an allowed path becomes allowed too, so enable it only for workloads that really need it.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"maxDistinctExecutables": {
+						SchemaProps: spec.SchemaProps{
+							Description: "maxDistinctExecutables limits the number of distinct executables each container may run, on top of the allowed list. Every new distinct executable beyond the limit is reported as a violation and, in \"protect\" mode, blocked. The count of a container is only reset when the container restarts.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"allowedFilesystems": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowedFilesystems restricts the executions to the files stored on one of these filesystem types, e.g. to stop the binaries dropped on a tmpfs mount. The execution of a file stored on another filesystem type is reported as a violation, even if its path is in the allowed list. In \"protect\" mode, the execution is blocked. The files of a container image are usually on overlay, and ext4 also matches ext2 and ext3. When unset, the executables may reside on any filesystem.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"canaryPercent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "canaryPercent enforces the declared mode only on the given percentage of the matching pods, the other pods run in \"monitor\" mode. On each node, the pods of a workload are ranked by their UID and the percentage of them is selected, rounded up but never all of them: a workload with a single pod on a node is not enforced there until the rollout is over. Raising the percentage only adds pods to the selection. When unset, every matching pod is enforced.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"allowedServiceAccounts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowedServiceAccounts restricts the policy to the pods running with one of these service accounts of the policy namespace, so that a pod cannot inherit the policy of another team by setting the policy label. The agent refuses to enforce the policy on the other pods and reports it with an event on the pod. The service account is learned from the API server: a container whose pod service account is not known yet waits for it briefly and is then prevented from starting, unless agent.nriFailopen is set. A policy is never enforced on the pods of other namespaces. When unset, every pod of the namespace may use it.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -542,6 +1182,37 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyStatus(r
 							},
 						},
 					},
+					"enforcedBy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "enforcedBy contains, for each node where the policy is applied, the agent which applied it (max MaxEnforcingNodes nodes).",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.NodeEnforcement{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+					"enforcerVersions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "enforcerVersions is the number of nodes where the policy is applied by each version of the agent. More than one version means that the agents are being upgraded.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
+						},
+					},
 					"phase": {
 						SchemaProps: spec.SchemaProps{
 							Description: "phase indicates the current phase of the workload policy.",
@@ -570,11 +1241,68 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyStatus(r
 							},
 						},
 					},
+					"lastViolationTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "lastViolationTime is when the most recent violation of the policy happened. It is updated at most once per status sync interval of the controller.",
+							Ref:         ref(v1.Time{}.OpenAPIModelName()),
+						},
+					},
+					"lastViolationPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "lastViolationPath is the executable of the most recent violation of the policy.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"violationSummary": {
+						SchemaProps: spec.SchemaProps{
+							Description: "violationSummary is the summary of the violations of the last completed reporting window. It is only reported when the periodic summary is enabled in the controller.",
+							Ref:         ref(v1alpha1.ViolationSummary{}.OpenAPIModelName()),
+						},
+					},
+					"unobservedExecutables": {
+						SchemaProps: spec.SchemaProps{
+							Description: "unobservedExecutables lists, for each container, the allowed executables that have never been run on any node during the observation period. They are likely typos or dead entries. It is only reported when the observation is enabled in the controller.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type: []string{"array"},
+										Items: &spec.SchemaOrArray{
+											Schema: &spec.Schema{
+												SchemaProps: spec.SchemaProps{
+													Default: "",
+													Type:    []string{"string"},
+													Format:  "",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					"dryRunImpact": {
+						SchemaProps: spec.SchemaProps{
+							Description: "dryRunImpact reports, for each container, the executions the declared mode would have blocked on all the nodes since the start of the dry run. It is only reported while the policy is in dry run.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.DryRunImpact{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			v1alpha1.NodeIssue{}.OpenAPIModelName(), v1alpha1.ViolationRecord{}.OpenAPIModelName()},
+			v1alpha1.DryRunImpact{}.OpenAPIModelName(), v1alpha1.NodeEnforcement{}.OpenAPIModelName(), v1alpha1.NodeIssue{}.OpenAPIModelName(), v1alpha1.ViolationRecord{}.OpenAPIModelName(), v1alpha1.ViolationSummary{}.OpenAPIModelName(), v1.Time{}.OpenAPIModelName()},
 	}
 }
 
@@ -83,6 +83,8 @@ const (
 	PolicyMode_POLICY_MODE_MONITOR PolicyMode = 1
 	// Policy protect mode
 	PolicyMode_POLICY_MODE_PROTECT PolicyMode = 2
+	// Policy audit mode
+	PolicyMode_POLICY_MODE_AUDIT PolicyMode = 3
 )
 
 // Enum value maps for PolicyMode.
@@ -91,11 +93,13 @@ var (
 		0: "POLICY_MODE_UNSPECIFIED",
 		1: "POLICY_MODE_MONITOR",
 		2: "POLICY_MODE_PROTECT",
+		3: "POLICY_MODE_AUDIT",
 	}
 	PolicyMode_value = map[string]int32{
 		"POLICY_MODE_UNSPECIFIED": 0,
 		"POLICY_MODE_MONITOR":     1,
 		"POLICY_MODE_PROTECT":     2,
+		"POLICY_MODE_AUDIT":       3,
 	}
 )
 
@@ -441,18 +445,92 @@ func (*ListPoliciesStatusRequest) Descriptor() ([]byte, []int) {
 	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{5}
 }
 
-type PolicyStatus struct {
+// DryRunImpact reports the executions of a container that the declared mode of a policy in dry run
+// would have blocked since the start of the dry run on the node.
+type DryRunImpact struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	State         PolicyState            `protobuf:"varint,1,opt,name=state,proto3,enum=runtimeenforcer.agent.v1.PolicyState" json:"state,omitempty"`
-	Mode          PolicyMode             `protobuf:"varint,2,opt,name=mode,proto3,enum=runtimeenforcer.agent.v1.PolicyMode" json:"mode,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	ContainerName string                 `protobuf:"bytes,1,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	// count is the number of executions that would have been blocked.
+	Count int64 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// executables lists the sorted executables that would have been blocked, at most 50.
+	Executables   []string `protobuf:"bytes,3,rep,name=executables,proto3" json:"executables,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DryRunImpact) Reset() {
+	*x = DryRunImpact{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DryRunImpact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunImpact) ProtoMessage() {}
+
+func (x *DryRunImpact) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunImpact.ProtoReflect.Descriptor instead.
+func (*DryRunImpact) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DryRunImpact) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *DryRunImpact) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *DryRunImpact) GetExecutables() []string {
+	if x != nil {
+		return x.Executables
+	}
+	return nil
+}
+
+type PolicyStatus struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	State   PolicyState            `protobuf:"varint,1,opt,name=state,proto3,enum=runtimeenforcer.agent.v1.PolicyState" json:"state,omitempty"`
+	Mode    PolicyMode             `protobuf:"varint,2,opt,name=mode,proto3,enum=runtimeenforcer.agent.v1.PolicyMode" json:"mode,omitempty"`
+	Message string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// observed_executables lists the allowed executables run at least once on the node,
+	// each entry is formatted as `<container name>:<executable path>`.
+	ObservedExecutables []string `protobuf:"bytes,4,rep,name=observed_executables,json=observedExecutables,proto3" json:"observed_executables,omitempty"`
+	// enforcer_version is the version of the agent which applied the policy on the node.
+	EnforcerVersion string `protobuf:"bytes,5,opt,name=enforcer_version,json=enforcerVersion,proto3" json:"enforcer_version,omitempty"`
+	// dry_run_impact lists, for each container, the executions the declared mode would have blocked.
+	// It is empty when the policy is not in dry run.
+	DryRunImpact []*DryRunImpact `protobuf:"bytes,6,rep,name=dry_run_impact,json=dryRunImpact,proto3" json:"dry_run_impact,omitempty"`
+	// mode_forced is true when the agent configuration forces the mode to monitor, whatever the declared mode.
+	ModeForced    bool `protobuf:"varint,7,opt,name=mode_forced,json=modeForced,proto3" json:"mode_forced,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PolicyStatus) Reset() {
 	*x = PolicyStatus{}
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[6]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -464,7 +542,7 @@ func (x *PolicyStatus) String() string {
 func (*PolicyStatus) ProtoMessage() {}
 
 func (x *PolicyStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[6]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -477,7 +555,7 @@ func (x *PolicyStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PolicyStatus.ProtoReflect.Descriptor instead.
 func (*PolicyStatus) Descriptor() ([]byte, []int) {
-	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{6}
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *PolicyStatus) GetState() PolicyState {
@@ -501,6 +579,34 @@ func (x *PolicyStatus) GetMessage() string {
 	return ""
 }
 
+func (x *PolicyStatus) GetObservedExecutables() []string {
+	if x != nil {
+		return x.ObservedExecutables
+	}
+	return nil
+}
+
+func (x *PolicyStatus) GetEnforcerVersion() string {
+	if x != nil {
+		return x.EnforcerVersion
+	}
+	return ""
+}
+
+func (x *PolicyStatus) GetDryRunImpact() []*DryRunImpact {
+	if x != nil {
+		return x.DryRunImpact
+	}
+	return nil
+}
+
+func (x *PolicyStatus) GetModeForced() bool {
+	if x != nil {
+		return x.ModeForced
+	}
+	return false
+}
+
 type ListPoliciesStatusResponse struct {
 	state         protoimpl.MessageState   `protogen:"open.v1"`
 	Policies      map[string]*PolicyStatus `protobuf:"bytes,1,rep,name=policies,proto3" json:"policies,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
@@ -510,7 +616,7 @@ type ListPoliciesStatusResponse struct {
 
 func (x *ListPoliciesStatusResponse) Reset() {
 	*x = ListPoliciesStatusResponse{}
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[7]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -522,7 +628,7 @@ func (x *ListPoliciesStatusResponse) String() string {
 func (*ListPoliciesStatusResponse) ProtoMessage() {}
 
 func (x *ListPoliciesStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[7]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -535,7 +641,7 @@ func (x *ListPoliciesStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListPoliciesStatusResponse.ProtoReflect.Descriptor instead.
 func (*ListPoliciesStatusResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{7}
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ListPoliciesStatusResponse) GetPolicies() map[string]*PolicyStatus {
@@ -553,7 +659,7 @@ type ScrapeViolationsRequest struct {
 
 func (x *ScrapeViolationsRequest) Reset() {
 	*x = ScrapeViolationsRequest{}
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[8]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -565,7 +671,7 @@ func (x *ScrapeViolationsRequest) String() string {
 func (*ScrapeViolationsRequest) ProtoMessage() {}
 
 func (x *ScrapeViolationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[8]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -578,7 +684,7 @@ func (x *ScrapeViolationsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ScrapeViolationsRequest.ProtoReflect.Descriptor instead.
 func (*ScrapeViolationsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{8}
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{9}
 }
 
 type ViolationRecord struct {
@@ -596,7 +702,7 @@ type ViolationRecord struct {
 
 func (x *ViolationRecord) Reset() {
 	*x = ViolationRecord{}
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[9]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -608,7 +714,7 @@ func (x *ViolationRecord) String() string {
 func (*ViolationRecord) ProtoMessage() {}
 
 func (x *ViolationRecord) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[9]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -621,7 +727,7 @@ func (x *ViolationRecord) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ViolationRecord.ProtoReflect.Descriptor instead.
 func (*ViolationRecord) Descriptor() ([]byte, []int) {
-	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{9}
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ViolationRecord) GetTimestamp() *timestamppb.Timestamp {
@@ -682,7 +788,7 @@ type ScrapeViolationsResponse struct {
 
 func (x *ScrapeViolationsResponse) Reset() {
 	*x = ScrapeViolationsResponse{}
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[10]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -694,7 +800,7 @@ func (x *ScrapeViolationsResponse) String() string {
 func (*ScrapeViolationsResponse) ProtoMessage() {}
 
 func (x *ScrapeViolationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[10]
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -707,7 +813,7 @@ func (x *ScrapeViolationsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ScrapeViolationsResponse.ProtoReflect.Descriptor instead.
 func (*ScrapeViolationsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{10}
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ScrapeViolationsResponse) GetViolations() []*ViolationRecord {
@@ -747,11 +853,20 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\x13ListPodCacheRequest\"M\n" +
 	"\x14ListPodCacheResponse\x125\n" +
 	"\x04pods\x18\x01 \x03(\v2!.runtimeenforcer.agent.v1.PodViewR\x04pods\"\x1b\n" +
-	"\x19ListPoliciesStatusRequest\"\x9f\x01\n" +
+	"\x19ListPoliciesStatusRequest\"m\n" +
+	"\fDryRunImpact\x12%\n" +
+	"\x0econtainer_name\x18\x01 \x01(\tR\rcontainerName\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12 \n" +
+	"\vexecutables\x18\x03 \x03(\tR\vexecutables\"\xec\x02\n" +
 	"\fPolicyStatus\x12;\n" +
 	"\x05state\x18\x01 \x01(\x0e2%.runtimeenforcer.agent.v1.PolicyStateR\x05state\x128\n" +
 	"\x04mode\x18\x02 \x01(\x0e2$.runtimeenforcer.agent.v1.PolicyModeR\x04mode\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"\xe1\x01\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x121\n" +
+	"\x14observed_executables\x18\x04 \x03(\tR\x13observedExecutables\x12)\n" +
+	"\x10enforcer_version\x18\x05 \x01(\tR\x0fenforcerVersion\x12L\n" +
+	"\x0edry_run_impact\x18\x06 \x03(\v2&.runtimeenforcer.agent.v1.DryRunImpactR\fdryRunImpact\x12\x1f\n" +
+	"\vmode_forced\x18\a \x01(\bR\n" +
+	"modeForced\"\xe1\x01\n" +
 	"\x1aListPoliciesStatusResponse\x12^\n" +
 	"\bpolicies\x18\x01 \x03(\v2B.runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntryR\bpolicies\x1ac\n" +
 	"\rPoliciesEntry\x12\x10\n" +
@@ -774,12 +889,13 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\vPolicyState\x12\x1c\n" +
 	"\x18POLICY_STATE_UNSPECIFIED\x10\x00\x12\x16\n" +
 	"\x12POLICY_STATE_READY\x10\x01\x12\x16\n" +
-	"\x12POLICY_STATE_ERROR\x10\x02*[\n" +
+	"\x12POLICY_STATE_ERROR\x10\x02*r\n" +
 	"\n" +
 	"PolicyMode\x12\x1b\n" +
 	"\x17POLICY_MODE_UNSPECIFIED\x10\x00\x12\x17\n" +
 	"\x13POLICY_MODE_MONITOR\x10\x01\x12\x17\n" +
-	"\x13POLICY_MODE_PROTECT\x10\x022\x81\x03\n" +
+	"\x13POLICY_MODE_PROTECT\x10\x02\x12\x15\n" +
+	"\x11POLICY_MODE_AUDIT\x10\x032\x81\x03\n" +
 	"\rAgentObserver\x12\x81\x01\n" +
 	"\x12ListPoliciesStatus\x123.runtimeenforcer.agent.v1.ListPoliciesStatusRequest\x1a4.runtimeenforcer.agent.v1.ListPoliciesStatusResponse\"\x00\x12o\n" +
 	"\fListPodCache\x12-.runtimeenforcer.agent.v1.ListPodCacheRequest\x1a..runtimeenforcer.agent.v1.ListPodCacheResponse\"\x00\x12{\n" +
@@ -798,7 +914,7 @@ func file_proto_agent_v1_agent_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_agent_v1_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_agent_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_proto_agent_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_proto_agent_v1_agent_proto_goTypes = []any{
 	(PolicyState)(0),                   // 0: runtimeenforcer.agent.v1.PolicyState
 	(PolicyMode)(0),                    // 1: runtimeenforcer.agent.v1.PolicyMode
@@ -808,39 +924,41 @@ var file_proto_agent_v1_agent_proto_goTypes = []any{
 	(*ListPodCacheRequest)(nil),        // 5: runtimeenforcer.agent.v1.ListPodCacheRequest
 	(*ListPodCacheResponse)(nil),       // 6: runtimeenforcer.agent.v1.ListPodCacheResponse
 	(*ListPoliciesStatusRequest)(nil),  // 7: runtimeenforcer.agent.v1.ListPoliciesStatusRequest
-	(*PolicyStatus)(nil),               // 8: runtimeenforcer.agent.v1.PolicyStatus
-	(*ListPoliciesStatusResponse)(nil), // 9: runtimeenforcer.agent.v1.ListPoliciesStatusResponse
-	(*ScrapeViolationsRequest)(nil),    // 10: runtimeenforcer.agent.v1.ScrapeViolationsRequest
-	(*ViolationRecord)(nil),            // 11: runtimeenforcer.agent.v1.ViolationRecord
-	(*ScrapeViolationsResponse)(nil),   // 12: runtimeenforcer.agent.v1.ScrapeViolationsResponse
-	nil,                                // 13: runtimeenforcer.agent.v1.PodMeta.LabelsEntry
-	nil,                                // 14: runtimeenforcer.agent.v1.PodView.ContainersEntry
-	nil,                                // 15: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry
-	(*timestamppb.Timestamp)(nil),      // 16: google.protobuf.Timestamp
+	(*DryRunImpact)(nil),               // 8: runtimeenforcer.agent.v1.DryRunImpact
+	(*PolicyStatus)(nil),               // 9: runtimeenforcer.agent.v1.PolicyStatus
+	(*ListPoliciesStatusResponse)(nil), // 10: runtimeenforcer.agent.v1.ListPoliciesStatusResponse
+	(*ScrapeViolationsRequest)(nil),    // 11: runtimeenforcer.agent.v1.ScrapeViolationsRequest
+	(*ViolationRecord)(nil),            // 12: runtimeenforcer.agent.v1.ViolationRecord
+	(*ScrapeViolationsResponse)(nil),   // 13: runtimeenforcer.agent.v1.ScrapeViolationsResponse
+	nil,                                // 14: runtimeenforcer.agent.v1.PodMeta.LabelsEntry
+	nil,                                // 15: runtimeenforcer.agent.v1.PodView.ContainersEntry
+	nil,                                // 16: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry
+	(*timestamppb.Timestamp)(nil),      // 17: google.protobuf.Timestamp
 }
 var file_proto_agent_v1_agent_proto_depIdxs = []int32{
-	13, // 0: runtimeenforcer.agent.v1.PodMeta.labels:type_name -> runtimeenforcer.agent.v1.PodMeta.LabelsEntry
+	14, // 0: runtimeenforcer.agent.v1.PodMeta.labels:type_name -> runtimeenforcer.agent.v1.PodMeta.LabelsEntry
 	3,  // 1: runtimeenforcer.agent.v1.PodView.meta:type_name -> runtimeenforcer.agent.v1.PodMeta
-	14, // 2: runtimeenforcer.agent.v1.PodView.containers:type_name -> runtimeenforcer.agent.v1.PodView.ContainersEntry
+	15, // 2: runtimeenforcer.agent.v1.PodView.containers:type_name -> runtimeenforcer.agent.v1.PodView.ContainersEntry
 	4,  // 3: runtimeenforcer.agent.v1.ListPodCacheResponse.pods:type_name -> runtimeenforcer.agent.v1.PodView
 	0,  // 4: runtimeenforcer.agent.v1.PolicyStatus.state:type_name -> runtimeenforcer.agent.v1.PolicyState
 	1,  // 5: runtimeenforcer.agent.v1.PolicyStatus.mode:type_name -> runtimeenforcer.agent.v1.PolicyMode
-	15, // 6: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.policies:type_name -> runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry
-	16, // 7: runtimeenforcer.agent.v1.ViolationRecord.timestamp:type_name -> google.protobuf.Timestamp
-	11, // 8: runtimeenforcer.agent.v1.ScrapeViolationsResponse.violations:type_name -> runtimeenforcer.agent.v1.ViolationRecord
-	2,  // 9: runtimeenforcer.agent.v1.PodView.ContainersEntry.value:type_name -> runtimeenforcer.agent.v1.ContainerMeta
-	8,  // 10: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry.value:type_name -> runtimeenforcer.agent.v1.PolicyStatus
-	7,  // 11: runtimeenforcer.agent.v1.AgentObserver.ListPoliciesStatus:input_type -> runtimeenforcer.agent.v1.ListPoliciesStatusRequest
-	5,  // 12: runtimeenforcer.agent.v1.AgentObserver.ListPodCache:input_type -> runtimeenforcer.agent.v1.ListPodCacheRequest
-	10, // 13: runtimeenforcer.agent.v1.AgentObserver.ScrapeViolations:input_type -> runtimeenforcer.agent.v1.ScrapeViolationsRequest
-	9,  // 14: runtimeenforcer.agent.v1.AgentObserver.ListPoliciesStatus:output_type -> runtimeenforcer.agent.v1.ListPoliciesStatusResponse
-	6,  // 15: runtimeenforcer.agent.v1.AgentObserver.ListPodCache:output_type -> runtimeenforcer.agent.v1.ListPodCacheResponse
-	12, // 16: runtimeenforcer.agent.v1.AgentObserver.ScrapeViolations:output_type -> runtimeenforcer.agent.v1.ScrapeViolationsResponse
-	14, // [14:17] is the sub-list for method output_type
-	11, // [11:14] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	8,  // 6: runtimeenforcer.agent.v1.PolicyStatus.dry_run_impact:type_name -> runtimeenforcer.agent.v1.DryRunImpact
+	16, // 7: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.policies:type_name -> runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry
+	17, // 8: runtimeenforcer.agent.v1.ViolationRecord.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 9: runtimeenforcer.agent.v1.ScrapeViolationsResponse.violations:type_name -> runtimeenforcer.agent.v1.ViolationRecord
+	2,  // 10: runtimeenforcer.agent.v1.PodView.ContainersEntry.value:type_name -> runtimeenforcer.agent.v1.ContainerMeta
+	9,  // 11: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry.value:type_name -> runtimeenforcer.agent.v1.PolicyStatus
+	7,  // 12: runtimeenforcer.agent.v1.AgentObserver.ListPoliciesStatus:input_type -> runtimeenforcer.agent.v1.ListPoliciesStatusRequest
+	5,  // 13: runtimeenforcer.agent.v1.AgentObserver.ListPodCache:input_type -> runtimeenforcer.agent.v1.ListPodCacheRequest
+	11, // 14: runtimeenforcer.agent.v1.AgentObserver.ScrapeViolations:input_type -> runtimeenforcer.agent.v1.ScrapeViolationsRequest
+	10, // 15: runtimeenforcer.agent.v1.AgentObserver.ListPoliciesStatus:output_type -> runtimeenforcer.agent.v1.ListPoliciesStatusResponse
+	6,  // 16: runtimeenforcer.agent.v1.AgentObserver.ListPodCache:output_type -> runtimeenforcer.agent.v1.ListPodCacheResponse
+	13, // 17: runtimeenforcer.agent.v1.AgentObserver.ScrapeViolations:output_type -> runtimeenforcer.agent.v1.ScrapeViolationsResponse
+	15, // [15:18] is the sub-list for method output_type
+	12, // [12:15] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_proto_agent_v1_agent_proto_init() }
@@ -854,7 +972,7 @@ func file_proto_agent_v1_agent_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_agent_v1_agent_proto_rawDesc), len(file_proto_agent_v1_agent_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   14,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},